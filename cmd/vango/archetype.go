@@ -0,0 +1,145 @@
+package vango
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"vango/internal/util"
+)
+
+// archetypeDir holds user-provided content templates, relative to the site root
+const archetypeDir = "archetypes"
+
+// archetypeData is the data passed to archetype templates
+type archetypeData struct {
+	Title   string
+	Name    string
+	Section string
+	Date    string
+	Draft   bool
+	Author  string
+}
+
+const defaultPageArchetype = `+++
+title = {{ quote .Title }}
+date = "{{ .Date }}"
+description = ""
+draft = {{ .Draft }}
++++
+
+# {{ .Title }}
+
+Page content goes here...
+`
+
+const defaultDocsArchetype = `+++
+title = {{ quote .Title }}
+date = "{{ .Date }}"
+description = ""
+weight = 0
+sidebar = true
+draft = {{ .Draft }}
++++
+
+# {{ .Title }}
+
+Documentation goes here. Set weight to order this page in the sidebar, or
+sidebar = false to leave it out.
+`
+
+// builtinArchetype returns the built-in template for a content type
+func builtinArchetype(contentType string) string {
+	if contentType == "docs" {
+		return defaultDocsArchetype
+	}
+	return defaultPageArchetype
+}
+
+// renderArchetype renders the first of archetypes/<kind>.md for the given
+// kinds, falling back to archetypes/default.md and then to the built-in
+// template
+func renderArchetype(kinds []string, builtin string, data archetypeData) (string, error) {
+	kind := kinds[0]
+	source := builtin
+	names := make([]string, 0, len(kinds)+1)
+	for _, k := range kinds {
+		names = append(names, k+".md")
+	}
+	for _, name := range append(names, "default.md") {
+		raw, err := os.ReadFile(filepath.Join(archetypeDir, name))
+		if err == nil {
+			source = string(raw)
+			break
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read archetype %s: %w", name, err)
+		}
+	}
+
+	tmpl, err := template.New(kind).Funcs(template.FuncMap{
+		"quote": strconv.Quote,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"title": strings.Title,
+	}).Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s archetype: %w", kind, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s archetype: %w", kind, err)
+	}
+	return buf.String(), nil
+}
+
+// splitContentPath splits a "docs/getting-started/install" style argument
+// into its section directories, slugified file name and a display title
+func splitContentPath(arg string) (dirs []string, slug, title string) {
+	arg = strings.TrimSuffix(util.SlashPath(arg), ".md")
+	parts := strings.Split(strings.Trim(arg, "/"), "/")
+
+	for _, part := range parts[:len(parts)-1] {
+		if dir := util.Slugify(part); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	last := parts[len(parts)-1]
+	slug = util.Slugify(last)
+	title = last
+	if last == strings.ToLower(last) {
+		title = strings.Title(strings.NewReplacer("-", " ", "_", " ").Replace(last))
+	}
+	return dirs, slug, title
+}
+
+// openInEditor opens path in $VISUAL or $EDITOR
+func openInEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		return fmt.Errorf("neither $VISUAL nor $EDITOR is set")
+	}
+
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// contentTimestamp is the date format written into new content front matter
+func contentTimestamp() string {
+	return time.Now().Format("2006-01-02T15:04:05Z07:00")
+}
@@ -1,7 +1,15 @@
 package vango
 
 import (
+	"os"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
+	"vango/internal/builder"
+	"vango/internal/config"
+	"vango/internal/logging"
+	"vango/internal/watcher"
 )
 
 var buildCmd = &cobra.Command{
@@ -10,10 +18,15 @@ var buildCmd = &cobra.Command{
 	Long:  `Build your static site using the configuration file.
 
 This command processes all markdown files in the content directory,
-applies templates, and generates a static website in the public directory.`,
+applies templates, and generates a static website in the public directory.
+
+With --watch, the site is rebuilt whenever content, layouts, theme files,
+static files or the configuration change. No server is started, so the
+output can be served by any web server.`,
 	Example: `  vango build                    # Build with default config
   vango build -c custom.toml      # Build with custom config
-  vango build --verbose           # Build with verbose output`,
+  vango build --verbose           # Build with verbose output
+  vango build --watch             # Rebuild on changes without serving`,
 	Run: func(cmd *cobra.Command, args []string) {
 		buildSite(cmd)
 	},
@@ -23,3 +36,47 @@ func init() {
 	rootCmd.AddCommand(buildCmd)
 }
 
+// watchBuild rebuilds the site on every change until interrupted
+func watchBuild(cmd *cobra.Command, cfg *config.Config, b *builder.Builder) {
+	w, err := watcher.New(cfg, configFiles())
+	if err != nil {
+		logging.Errorf("❌ %v", err)
+		os.Exit(1)
+	}
+	defer w.Close()
+	w.SetVerbose(verbose)
+
+	logging.Infof("👀 Watching %d paths for changes (Ctrl+C to stop)", w.WatchCount())
+
+	w.Run(func(change watcher.Change) {
+		start := time.Now()
+
+		if change.ConfigChanged {
+			logging.Infof("⚙️  Configuration changed - reloading")
+			newCfg, err := loadBuildConfig(cmd)
+			if err != nil {
+				logging.Errorf("❌ %v", err)
+				return
+			}
+			cfg = newCfg
+			b = builder.New(cfg)
+			w.SetConfig(cfg)
+			if err := b.Build(); err != nil {
+				logging.Errorf("❌ Build failed: %v", err)
+				return
+			}
+			logging.Infof("✅ Rebuilt %d pages in %v", len(b.GetPages()), time.Since(start).Round(time.Millisecond))
+			return
+		}
+
+		logging.Infof("🔄 %s changed", strings.Join(change.Files, ", "))
+		if err := b.IncrementalBuild(change.Files); err != nil {
+			logging.Warnf("⚠️  Incremental build failed (%v), doing a full rebuild", err)
+			if err := b.Build(); err != nil {
+				logging.Errorf("❌ Build failed: %v", err)
+				return
+			}
+		}
+		logging.Infof("✅ Rebuilt in %v", time.Since(start).Round(time.Millisecond))
+	})
+}
@@ -0,0 +1,70 @@
+package vango
+
+import (
+	"os"
+	"path/filepath"
+
+	"vango/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanCacheOnly  bool
+	cleanOutputOnly bool
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove build output and caches",
+	Long: `Remove generated build artifacts.
+
+By default this removes the public directory (including retained
+previous build outputs) and the performance cache directory.`,
+	Example: `  vango clean                     # Remove output and cache
+  vango clean --cache-only        # Remove only the cache directory
+  vango clean --output-only       # Remove only the public directory`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if cleanCacheOnly && cleanOutputOnly {
+			logging.Errorf("❌ --cache-only and --output-only cannot be used together")
+			os.Exit(1)
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			logging.Errorf("❌ Error loading config: %v", err)
+			os.Exit(1)
+		}
+
+		var targets []string
+		if !cleanCacheOnly {
+			targets = append(targets, cfg.PublicDir)
+			for _, pattern := range []string{".prev-*", ".tmp-*"} {
+				matches, _ := filepath.Glob(filepath.Clean(cfg.PublicDir) + pattern)
+				targets = append(targets, matches...)
+			}
+		}
+		if !cleanOutputOnly && cfg.Performance.CacheDir != "" {
+			targets = append(targets, cfg.Performance.CacheDir)
+		}
+
+		for _, target := range targets {
+			if _, err := os.Stat(target); os.IsNotExist(err) {
+				continue
+			}
+			if err := os.RemoveAll(target); err != nil {
+				logging.Errorf("❌ Failed to remove %s: %v", target, err)
+				os.Exit(1)
+			}
+			logging.Infof("🗑️  Removed %s", target)
+		}
+
+		logging.Infof("✅ Clean complete")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cleanCmd)
+	cleanCmd.Flags().BoolVar(&cleanCacheOnly, "cache-only", false, "Only remove the cache directory")
+	cleanCmd.Flags().BoolVar(&cleanOutputOnly, "output-only", false, "Only remove the public directory")
+}
@@ -0,0 +1,98 @@
+package vango
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// deployTargets lists the supported deployment targets with descriptions
+var deployTargets = []string{
+	"github\tGitHub Pages",
+	"netlify\tNetlify",
+	"vercel\tVercel",
+	"s3\tAWS S3",
+}
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate shell completion scripts",
+	Long: `Generate a shell completion script for vango.
+
+Bash:
+  source <(vango completion bash)
+  # or, to load for every session:
+  vango completion bash > /etc/bash_completion.d/vango
+
+Zsh:
+  vango completion zsh > "${fpath[1]}/_vango"
+
+Fish:
+  vango completion fish > ~/.config/fish/completions/vango.fish
+
+PowerShell:
+  vango completion powershell | Out-String | Invoke-Expression`,
+	DisableFlagsInUseLine: true,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			return rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			return rootCmd.GenFishCompletion(os.Stdout, true)
+		default:
+			return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+	},
+}
+
+// completeThemeNames completes theme directory names. It reads the themes
+// directory directly rather than loading the config, so it works outside a
+// valid site and never creates directories.
+func completeThemeNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	entries, err := os.ReadDir("themes")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeDeployTargets completes deployment target names
+func completeDeployTargets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return deployTargets, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigFiles completes configuration file paths
+func completeConfigFiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"toml", "yaml", "yml"}, cobra.ShellCompDirectiveFilterFileExt
+}
+
+// completeContentFiles completes markdown file paths
+func completeContentFiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"md", "markdown", "mdown"}, cobra.ShellCompDirectiveFilterFileExt
+}
+
+// completeDirs completes directory paths
+func completeDirs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveFilterDirs
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
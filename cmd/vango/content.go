@@ -0,0 +1,285 @@
+package vango
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"vango/internal/content"
+	"vango/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+var contentDryRun bool
+
+var contentCmd = &cobra.Command{
+	Use:   "content",
+	Short: "Edit the front matter of content files in bulk",
+}
+
+var contentSetCmd = &cobra.Command{
+	Use:   "set <glob> key=value [key=value...]",
+	Short: "Set front matter keys in matching content files",
+	Long: `Set top-level front matter keys in every content file matching the glob.
+
+Values are typed like front matter: true and false are booleans, numbers
+are numbers, [a, b] is a list, and anything else is a string. Quote a
+value to keep it a string, as in title='"2024"'. TOML and YAML front
+matter keeps its key order, formatting and comments. Globs are matched
+relative to the working directory, then to the content directory, and a
+directory matches every Markdown file below it.`,
+	Example: `  vango content set 'posts/2019-*.md' categories=[archive]
+  vango content set posts draft=false weight=10
+  vango content set 'posts/*.md' author=Jane --dry-run`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var patterns []string
+		var edits []content.FrontMatterEdit
+		for i, arg := range args {
+			key, value, ok := strings.Cut(arg, "=")
+			if i == 0 || !ok {
+				patterns = append(patterns, arg)
+				continue
+			}
+			edits = append(edits, content.FrontMatterEdit{Key: key, Value: content.ParseValue(value)})
+		}
+		if len(edits) == 0 {
+			logging.Errorf("❌ Nothing to set: give at least one key=value")
+			os.Exit(1)
+		}
+		editContent(patterns, edits)
+	},
+}
+
+var contentUnsetCmd = &cobra.Command{
+	Use:   "unset <glob> key [key...]",
+	Short: "Remove front matter keys from matching content files",
+	Example: `  vango content unset 'posts/*.md' draft
+  vango content unset posts aliases weight --dry-run`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// The shell may have expanded the glob, so leading arguments that
+		// look like paths are all patterns
+		patterns := []string{args[0]}
+		rest := args[1:]
+		for len(rest) > 1 && isPathArg(rest[0]) {
+			patterns = append(patterns, rest[0])
+			rest = rest[1:]
+		}
+		var edits []content.FrontMatterEdit
+		for _, key := range rest {
+			edits = append(edits, content.FrontMatterEdit{Key: key, Remove: true})
+		}
+		editContent(patterns, edits)
+	},
+}
+
+// isPathArg reports whether an argument names a file or pattern rather than
+// a front matter key
+func isPathArg(arg string) bool {
+	if strings.ContainsAny(arg, `/\*?[`) {
+		return true
+	}
+	_, err := os.Stat(arg)
+	return err == nil
+}
+
+// editContent applies edits to the files matching patterns, writing them
+// or, with --dry-run, printing what would change
+func editContent(patterns []string, edits []content.FrontMatterEdit) {
+	cfg, err := loadConfig()
+	if err != nil {
+		logging.Errorf("❌ Error loading config: %v", err)
+		os.Exit(1)
+	}
+
+	files, err := contentFiles(patterns, cfg.ContentDir, cfg.ContentExtensions)
+	if err != nil {
+		logging.Errorf("❌ %v", err)
+		os.Exit(1)
+	}
+	if len(files) == 0 {
+		logging.Errorf("❌ No content files match %s", strings.Join(patterns, " "))
+		os.Exit(1)
+	}
+
+	changed, failed := 0, 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			logging.Errorf("❌ %s: %v", file, err)
+			failed++
+			continue
+		}
+		edited, err := content.EditFrontMatter(data, edits)
+		if err != nil {
+			logging.Errorf("❌ %s: %v", file, err)
+			failed++
+			continue
+		}
+		if string(edited) == string(data) {
+			continue
+		}
+		changed++
+		if contentDryRun {
+			fmt.Print(lineDiff(file, string(data), string(edited)))
+			continue
+		}
+		if err := writeFileAtomic(file, edited); err != nil {
+			logging.Errorf("❌ %s: %v", file, err)
+			failed++
+			continue
+		}
+		logging.Debugf("✏️  Updated %s", file)
+	}
+
+	if contentDryRun {
+		logging.Infof("🔍 %d of %d files would change", changed, len(files))
+	} else {
+		logging.Infof("✏️  Updated %d of %d files", changed, len(files))
+	}
+	if failed > 0 {
+		logging.Errorf("❌ %d files could not be edited", failed)
+		os.Exit(1)
+	}
+}
+
+// contentFiles expands patterns to content files, trying each relative to
+// the working directory and then to contentDir. Directories expand to the
+// files below them with one of the content extensions.
+func contentFiles(patterns []string, contentDir string, extensions []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 && !filepath.IsAbs(pattern) {
+			matches, _ = filepath.Glob(filepath.Join(contentDir, pattern))
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(match)
+				continue
+			}
+			err = filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && content.IsContentFile(path, extensions) {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the
+// same directory, keeping the file's permissions
+func writeFileAtomic(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".vango-edit-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// lineDiff formats the lines that differ between before and after, with
+// the lines they have in common around the changes
+func lineDiff(path, before, after string) string {
+	a := strings.SplitAfter(before, "\n")
+	b := strings.SplitAfter(after, "\n")
+
+	// Only the front matter changes, so diff what lies between the common
+	// start and end of the files
+	start := 0
+	for start < len(a) && start < len(b) && a[start] == b[start] {
+		start++
+	}
+	end := 0
+	for end < len(a)-start && end < len(b)-start && a[len(a)-1-end] == b[len(b)-1-end] {
+		end++
+	}
+	a, b = a[start:len(a)-end], b[start:len(b)-end]
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n@@ line %d @@\n", path, path, start+1)
+	line := func(prefix, text string) {
+		out.WriteString(prefix + strings.TrimRight(text, "\r\n") + "\n")
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			line(" ", a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			line("-", a[i])
+			i++
+		default:
+			line("+", b[j])
+			j++
+		}
+	}
+	return out.String()
+}
+
+func init() {
+	rootCmd.AddCommand(contentCmd)
+	contentCmd.AddCommand(contentSetCmd)
+	contentCmd.AddCommand(contentUnsetCmd)
+	contentCmd.PersistentFlags().BoolVar(&contentDryRun, "dry-run", false, "Show the changes as a diff instead of writing them")
+}
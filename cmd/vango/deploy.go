@@ -0,0 +1,206 @@
+package vango
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"vango/internal/builder"
+	"vango/internal/config"
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// deployManifest maps each output file, relative to the public directory,
+// to its SHA-256 hash
+type deployManifest map[string]string
+
+// maxBrokenLinksShown caps the broken links listed by the pre-flight checks
+const maxBrokenLinksShown = 10
+
+// deployPreflight checks the built site before it is deployed to target,
+// shows a summary and asks for confirmation unless assumeYes is set. Broken
+// internal links stop the deploy unless force is set. It returns the
+// manifest of the site so a successful deploy can record it.
+func deployPreflight(cfg *config.Config, target string, assumeYes, force bool) (deployManifest, error) {
+	manifest, size, err := hashOutput(cfg.PublicDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", cfg.PublicDir, err)
+	}
+	if len(manifest) == 0 {
+		return nil, fmt.Errorf("%s is empty; build the site first", cfg.PublicDir)
+	}
+
+	if isLocalURL(cfg.BaseURL) {
+		logging.Warnf("⚠️  Warning: base URL %s points to this machine; set baseURL or pass --baseURL", cfg.BaseURL)
+	}
+
+	broken, err := builder.CheckLinks(cfg.PublicDir, cfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("link check failed: %w", err)
+	}
+	if len(broken) > 0 {
+		logging.Warnf("⚠️  Found %d broken internal links:", len(broken))
+		for i, link := range broken {
+			if i == maxBrokenLinksShown {
+				logging.Warnf("   ... and %d more", len(broken)-maxBrokenLinksShown)
+				break
+			}
+			logging.Warnf("   %s → %s", link.Page, link.Target)
+		}
+		if !force {
+			return nil, fmt.Errorf("broken internal links; fix them or pass --force")
+		}
+	} else {
+		logging.Infof("🔗 No broken internal links")
+	}
+
+	logging.Infof("📦 %d files (%.1f KB) ready to deploy to %s", len(manifest), float64(size)/1024, target)
+	previous, err := loadDeployManifest(cfg, target)
+	if err != nil {
+		logging.Warnf("⚠️  Warning: could not read the last deploy manifest: %v", err)
+	}
+	if previous == nil {
+		logging.Infof("📦 First deploy to %s: all files will be uploaded", target)
+	} else {
+		added, changed, removed := diffManifests(previous, manifest)
+		logging.Infof("📦 Since the last deploy: %d new, %d changed, %d removed", added, changed, removed)
+	}
+
+	if !assumeYes && !confirm(fmt.Sprintf("Deploy to %s?", target)) {
+		return nil, fmt.Errorf("deploy cancelled")
+	}
+	return manifest, nil
+}
+
+// hashOutput hashes every file under dir and returns the manifest with the
+// total size of the files
+func hashOutput(dir string) (deployManifest, int64, error) {
+	manifest := make(deployManifest)
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := util.RelSlashPath(dir, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		manifest[relPath] = hex.EncodeToString(h.Sum(nil))
+		size += info.Size()
+		return nil
+	})
+	return manifest, size, err
+}
+
+// diffManifests counts the files added, changed and removed between two
+// deploys
+func diffManifests(previous, current deployManifest) (added, changed, removed int) {
+	for path, hash := range current {
+		old, ok := previous[path]
+		switch {
+		case !ok:
+			added++
+		case old != hash:
+			changed++
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			removed++
+		}
+	}
+	return added, changed, removed
+}
+
+// deployManifestPath returns where the manifest of the last deploy to
+// target is kept, or "" when caching is disabled
+func deployManifestPath(cfg *config.Config, target string) string {
+	if cfg.Performance.CacheDir == "" {
+		return ""
+	}
+	return filepath.Join(cfg.Performance.CacheDir, "deploy", target+".json")
+}
+
+// loadDeployManifest reads the manifest of the last deploy to target. It
+// returns nil when there is none.
+func loadDeployManifest(cfg *config.Config, target string) (deployManifest, error) {
+	path := deployManifestPath(cfg, target)
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest deployManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// saveDeployManifest records the manifest of a successful deploy to target
+func saveDeployManifest(cfg *config.Config, target string, manifest deployManifest) error {
+	path := deployManifestPath(cfg, target)
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// isLocalURL reports whether a base URL points to a loopback or unspecified
+// address, which would break links on the deployed site
+func isLocalURL(baseURL string) bool {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return true
+	}
+	host := u.Hostname()
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
+
+// confirm asks a yes/no question on the terminal. It answers no when stdin
+// is not a terminal.
+func confirm(question string) bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		logging.Errorf("❌ Cannot ask for confirmation without a terminal; pass --yes")
+		return false
+	}
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
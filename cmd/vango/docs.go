@@ -0,0 +1,66 @@
+package vango
+
+import (
+	"fmt"
+	"os"
+
+	"vango/internal/logging"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var docsCmd = &cobra.Command{
+	Use:    "docs",
+	Short:  "Generate CLI documentation",
+	Hidden: true,
+}
+
+var docsManCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Generate man pages or markdown docs for every command",
+	Example: `  vango docs man --dir man
+  vango docs man --format markdown --dir docs/cli`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+		format, _ := cmd.Flags().GetString("format")
+
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			logging.Errorf("❌ Failed to create %s: %v", dir, err)
+			os.Exit(1)
+		}
+
+		rootCmd.DisableAutoGenTag = true
+		var err error
+		switch format {
+		case "man":
+			err = doc.GenManTree(rootCmd, &doc.GenManHeader{
+				Title:   "VANGO",
+				Section: "1",
+				Source:  "VanGo " + rootCmd.Version,
+				Manual:  "VanGo Manual",
+			}, dir)
+		case "markdown", "md":
+			err = doc.GenMarkdownTree(rootCmd, dir)
+		default:
+			err = fmt.Errorf("unknown format %q (expected man or markdown)", format)
+		}
+		if err != nil {
+			logging.Errorf("❌ Failed to generate docs: %v", err)
+			os.Exit(1)
+		}
+
+		logging.Infof("✅ Generated %s docs in %s", format, dir)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(docsCmd)
+	docsCmd.AddCommand(docsManCmd)
+
+	docsManCmd.Flags().String("dir", "man", "Output directory")
+	docsManCmd.Flags().String("format", "man", "Output format (man, markdown)")
+	docsManCmd.RegisterFlagCompletionFunc("dir", completeDirs)
+	docsManCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"man", "markdown"}, cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,100 @@
+package vango
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"vango/internal/builder"
+	"vango/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphOutput string
+	graphTags   bool
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the graph of pages and the links between them",
+	Long: `Export the site's content graph: every page with its title, URL,
+section and word count, and an edge for each internal link between pages.
+
+Content is parsed but not rendered, so the graph reflects the links written
+in the pages. Links that resolve to no page or published file are kept as
+edges marked broken. --tags adds an edge between each pair of pages sharing
+tags.
+
+The graph is written as JSON, or in Graphviz DOT format with --format dot
+or an output file ending in .dot or .gv.`,
+	Example: `  vango graph --output graph.json
+  vango graph --format dot | dot -Tsvg > graph.svg
+  vango graph --tags --output graph.dot`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := "json"
+		switch {
+		case cmd.Flags().Changed("format"):
+			format = outputFormat
+		case strings.EqualFold(filepath.Ext(graphOutput), ".dot"), strings.EqualFold(filepath.Ext(graphOutput), ".gv"):
+			format = "dot"
+		}
+		if format != "json" && format != "dot" {
+			logging.Errorf("❌ Unknown format %q (use json or dot)", format)
+			os.Exit(1)
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			logging.Errorf("❌ Error loading config: %v", err)
+			os.Exit(1)
+		}
+		graph, err := builder.New(cfg).ContentGraph(graphTags)
+		if err != nil {
+			logging.Errorf("❌ %v", err)
+			os.Exit(1)
+		}
+
+		var out io.Writer = os.Stdout
+		if graphOutput != "" && graphOutput != "-" {
+			file, err := os.Create(graphOutput)
+			if err != nil {
+				logging.Errorf("❌ %v", err)
+				os.Exit(1)
+			}
+			defer file.Close()
+			out = file
+		}
+
+		if format == "dot" {
+			err = graph.WriteDOT(out)
+		} else {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(graph)
+		}
+		if err != nil {
+			logging.Errorf("❌ Failed to write graph: %v", err)
+			os.Exit(1)
+		}
+
+		broken := graph.BrokenLinks()
+		for _, edge := range broken {
+			logging.Warnf("⚠️  Broken link: %s → %s", edge.From, edge.To)
+		}
+		if graphOutput != "" && graphOutput != "-" {
+			logging.Infof("🕸️  Wrote %d pages, %d edges (%d broken links) to %s", len(graph.Nodes), len(graph.Edges), len(broken), graphOutput)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().StringVarP(&graphOutput, "output", "o", "", "File to write the graph to (default stdout)")
+	graphCmd.Flags().BoolVar(&graphTags, "tags", false, "Add edges between pages sharing tags")
+}
@@ -0,0 +1,105 @@
+package vango
+
+import (
+	"os"
+	"path/filepath"
+
+	"vango/internal/importer"
+	"vango/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	importDest   string
+	importForce  bool
+	importReport string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Migrate a Jekyll or Hugo site",
+	Long: `Migrate an existing Jekyll or Hugo site to VanGo.
+
+Content is copied with its front matter converted, Liquid tags and Hugo
+shortcodes are translated where VanGo has an equivalent and marked with
+TODO(import) comments otherwise, static files are copied and config.toml
+is generated from the site's configuration.
+
+A migration report lists the converted files, the constructs that could not
+be translated and the permalinks that changed, with [[redirects]] entries
+for keeping the old URLs working.`,
+}
+
+var importJekyllCmd = &cobra.Command{
+	Use:   "jekyll <src>",
+	Short: "Migrate a Jekyll site",
+	Example: `  vango import jekyll ../my-jekyll-site
+  vango import jekyll ../blog --dest . --force`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDirs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runImport("jekyll", args[0])
+	},
+}
+
+var importHugoCmd = &cobra.Command{
+	Use:   "hugo <src>",
+	Short: "Migrate a Hugo site",
+	Example: `  vango import hugo ../my-hugo-site
+  vango import hugo ../blog --dest migrated`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDirs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runImport("hugo", args[0])
+	},
+}
+
+// runImport imports the site at src and writes the migration report
+func runImport(kind, src string) {
+	logging.Infof("📦 Importing %s site from %s...", kind, src)
+	report, err := importer.Import(kind, importer.Options{
+		Source: src,
+		Dest:   importDest,
+		Force:  importForce,
+	})
+	if err != nil {
+		logging.Errorf("❌ Import failed: %v", err)
+		os.Exit(1)
+	}
+
+	reportPath := importReport
+	if reportPath == "" {
+		reportPath = filepath.Join(importDest, "import-report.md")
+	}
+	file, err := os.Create(reportPath)
+	if err != nil {
+		logging.Errorf("❌ Failed to create report: %v", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+	if err := report.WriteMarkdown(file); err != nil {
+		logging.Errorf("❌ Failed to write report: %v", err)
+		os.Exit(1)
+	}
+
+	logging.Infof("✅ Imported %d content files and %d other files", len(report.Converted), len(report.Copied))
+	if len(report.Untranslated) > 0 {
+		logging.Warnf("⚠️  %d constructs need converting by hand (marked TODO(import))", len(report.Untranslated))
+	}
+	if len(report.Permalinks) > 0 {
+		logging.Warnf("⚠️  %d permalinks changed; see the report for redirects", len(report.Permalinks))
+	}
+	logging.Infof("📋 Report: %s", reportPath)
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importJekyllCmd)
+	importCmd.AddCommand(importHugoCmd)
+
+	importCmd.PersistentFlags().StringVar(&importDest, "dest", ".", "Directory to write the VanGo site to")
+	importCmd.PersistentFlags().BoolVar(&importForce, "force", false, "Import into a site that already has content or a config.toml")
+	importCmd.PersistentFlags().StringVar(&importReport, "report", "", "Path of the migration report (default <dest>/import-report.md)")
+	importCmd.RegisterFlagCompletionFunc("dest", completeDirs)
+}
@@ -0,0 +1,116 @@
+package vango
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"vango/internal/builder"
+	"vango/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+// progressBarWidth is the number of cells in the progress bar
+const progressBarWidth = 30
+
+// stageTiming is how long one build stage took
+type stageTiming struct {
+	Stage    string
+	Duration time.Duration
+}
+
+// buildProgress draws a build's progress in the terminal's status line and
+// times each stage for the summary table. It is the builder's progress
+// callback, so it is called from worker goroutines.
+type buildProgress struct {
+	mu         sync.Mutex
+	stages     []stageTiming
+	stageStart time.Time
+}
+
+// showProgress reports whether a build draws a progress bar: when logging
+// to a terminal, with text output, and unless --verbose, --quiet or
+// --no-progress asks for something else
+func showProgress(cmd *cobra.Command) bool {
+	if noProgress, _ := cmd.Flags().GetBool("no-progress"); noProgress {
+		return false
+	}
+	return outputFormat != "json" && !verbose && !quiet && logging.StatusEnabled()
+}
+
+// update records a progress report and redraws the bar
+func (p *buildProgress) update(stage string, percent int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if n := len(p.stages); n == 0 || p.stages[n-1].Stage != stage {
+		p.endStage(now)
+		if stage == "done" {
+			logging.SetStatus("")
+			return
+		}
+		if stage == "start" {
+			stage = "setup"
+		}
+		p.stages = append(p.stages, stageTiming{Stage: stage})
+		p.stageStart = now
+	}
+	logging.SetStatus(fmt.Sprintf("🏗️  %s %3d%%  %s", progressBar(percent), percent, p.stages[len(p.stages)-1].Stage))
+}
+
+// endStage closes the running stage, if any. The caller holds p.mu.
+func (p *buildProgress) endStage(now time.Time) {
+	if n := len(p.stages); n > 0 && p.stages[n-1].Duration == 0 {
+		p.stages[n-1].Duration = now.Sub(p.stageStart)
+	}
+}
+
+// finish closes the running stage and clears the bar, for builds that fail
+// before reporting "done"
+func (p *buildProgress) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.endStage(time.Now())
+	logging.SetStatus("")
+}
+
+// timings returns the stages seen so far with their durations
+func (p *buildProgress) timings() []stageTiming {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]stageTiming(nil), p.stages...)
+}
+
+// progressBar draws percent as a bar of progressBarWidth cells
+func progressBar(percent int) string {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	filled := percent * progressBarWidth / 100
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled) + "]"
+}
+
+// logBuildSummary logs a table of the stage timings and what the build
+// produced
+func logBuildSummary(stages []stageTiming, b *builder.Builder, total time.Duration) {
+	report := b.GetReport()
+	logging.Infof("📊 Build summary:")
+	for _, s := range stages {
+		logging.Infof("   %-12s %10v", s.Stage, s.Duration.Round(time.Millisecond))
+	}
+	logging.Infof("   %-12s %10v", "total", total.Round(time.Millisecond))
+	logging.Infof("   %-12s %10d", "pages", len(b.GetPages()))
+	logging.Infof("   %-12s %10d", "files", len(report.OutputFiles))
+	if report.Assets != nil {
+		logging.Infof("   %-12s %10d", "assets", len(report.Assets.Files))
+	}
+	if len(report.PrunedFiles) > 0 {
+		logging.Infof("   %-12s %10d", "pruned", len(report.PrunedFiles))
+	}
+}
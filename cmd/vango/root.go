@@ -1,26 +1,36 @@
 package vango
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
 	"vango/internal/builder"
 	"vango/internal/config"
+	"vango/internal/content"
+	"vango/internal/logging"
+	"vango/internal/theme"
+	"vango/internal/version"
 
 	"github.com/spf13/cobra"
 )
 
 // Global flags
 var (
-	configPath    string
+	configPaths   []string
+	noLocalConfig bool
 	verbose       bool
 	environment   string
 	workers       int
 	outputFormat  string
 	profile       bool
+	logLevel      string
+	logFormat     string
+	quiet         bool
 )
 
 var rootCmd = &cobra.Command{
@@ -47,7 +57,15 @@ Examples:
   vango theme list                # List available themes
   vango new site myblog           # Create new site
   vango new post "My New Post"    # Create new post`,
-	Version: "2.0.0",
+	Version: version.Get().Version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return logging.Setup(logging.Options{
+			Level:   logLevel,
+			Format:  logFormat,
+			Quiet:   quiet,
+			Verbose: verbose,
+		})
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// Default behavior: build the site
 		buildSite(cmd)
@@ -57,19 +75,26 @@ Examples:
 // Execute runs the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		logging.Errorf("❌ Error: %v", err)
 		os.Exit(1)
 	}
 }
 
 func init() {
 	// Global flags available to all commands
-	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Path to configuration file")
+	rootCmd.PersistentFlags().StringSliceVarP(&configPaths, "config", "c", nil, "Configuration files, merged in order (repeatable or comma-separated)")
+	rootCmd.PersistentFlags().BoolVar(&noLocalConfig, "no-local-config", false, "Don't merge the local overrides file, e.g. config.local.toml")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
 	rootCmd.PersistentFlags().StringVarP(&environment, "environment", "e", "", "Environment (development, production, etc.)")
 	rootCmd.PersistentFlags().IntVarP(&workers, "workers", "w", 0, "Number of parallel workers (0 = auto)")
 	rootCmd.PersistentFlags().StringVar(&outputFormat, "format", "text", "Output format (text, json, yaml)")
-	rootCmd.PersistentFlags().BoolVar(&profile, "profile", false, "Enable performance profiling")
+	rootCmd.PersistentFlags().BoolVar(&profile, "profile", false, "Record template and page render times and report the slowest")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logging.FormatText, "Log format (text, json)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only log errors")
+	rootCmd.RegisterFlagCompletionFunc("config", completeConfigFiles)
+	rootCmd.RegisterFlagCompletionFunc("log-level", cobra.FixedCompletions([]string{"debug", "info", "warn", "error"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions([]string{logging.FormatText, logging.FormatJSON}, cobra.ShellCompDirectiveNoFileComp))
 
 	// Add all subcommands
 	rootCmd.AddCommand(buildCmd)
@@ -77,7 +102,6 @@ func init() {
 	rootCmd.AddCommand(newCmd)
 	// themeCmd is added in theme.go
 	rootCmd.AddCommand(configCmd)
-	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(benchmarkCmd)
 	rootCmd.AddCommand(validateCmd)
 	rootCmd.AddCommand(deployCmd)
@@ -88,29 +112,56 @@ func init() {
 	buildCmd.Flags().Bool("future", false, "Include future-dated content")
 	buildCmd.Flags().Bool("expired", false, "Include expired content")
 	buildCmd.Flags().Bool("minify", false, "Minify output")
+	buildCmd.Flags().String("baseURL", "", "Override the configured base URL (e.g. for preview deploys)")
+	buildCmd.Flags().Bool("preview", false, "Mark the build as a preview and inject the preview banner")
+	buildCmd.Flags().Int("keep-previous", 0, "Number of previous build outputs to retain for rollback")
+	buildCmd.Flags().Bool("prune", false, "Remove output files not produced by this build")
+	buildCmd.Flags().Bool("dry-run", false, "With --prune, only list orphaned files")
+	buildCmd.Flags().Bool("no-progress", false, "Don't draw a progress bar on the terminal")
+	buildCmd.Flags().Bool("watch", false, "Rebuild when files change, without starting the server")
+	buildCmd.Flags().Bool("reproducible", false, "Produce byte-identical output for the same source, with the build time pinned to SOURCE_DATE_EPOCH or the latest content date")
+	buildCmd.Flags().Bool("force", false, "Overwrite generated files edited since the last build without warning")
+	buildCmd.Flags().Bool("strict-budget", false, "Fail the build when files or pages are over the performance budget")
 
 	// Serve command flags will be defined in serve.go
 
+	// Validate command flags
+	validateCmd.Flags().Bool("strict", false, "Fail on warnings, including accessibility issues")
+	validateCmd.Flags().Bool("skip-a11y", false, "Skip building the site and the accessibility checks")
+	validateCmd.Flags().Bool("html", false, "Also check the generated pages for malformed HTML")
+
 	// New command structure
 	newCmd.AddCommand(newSiteCmd)
 	newCmd.AddCommand(newPostCmd)
 	newCmd.AddCommand(newPageCmd)
+	newPageCmd.Flags().String("title", "", "Page title (defaults to the last path segment)")
+	newPageCmd.Flags().Bool("draft", false, "Mark the page as a draft")
+	newPageCmd.Flags().Bool("open", false, "Open the new page in $EDITOR")
 
 	// Theme command structure is handled in theme.go
 
 	// Config command structure
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configValidateCmd)
+	configValidateCmd.Flags().Bool("strict", false, "Exit with status 1 on warnings")
+	configValidateCmd.Flags().Bool("fix", false, "Create missing directories and add the baseURL trailing slash")
 
 	// Benchmark flags
 	benchmarkCmd.Flags().Int("iterations", 10, "Number of benchmark iterations")
 	benchmarkCmd.Flags().Bool("memory", false, "Include memory profiling")
+	benchmarkCmd.Flags().Bool("scaling", false, "Compare worker counts on a synthetic site")
+	benchmarkCmd.Flags().Int("pages", 1000, "Number of synthetic pages for --scaling")
 
 	// Deploy flags
 	deployCmd.Flags().String("target", "", "Deployment target")
 	deployCmd.Flags().String("branch", "gh-pages", "Git branch for deployment")
 	deployCmd.Flags().String("message", "", "Deployment commit message")
-	deployCmd.Flags().Bool("force", false, "Force deployment")
+	deployCmd.Flags().Bool("force", false, "Force deployment, even with broken internal links")
+	deployCmd.Flags().Bool("skip-build", false, "Deploy the existing public directory without building")
+	deployCmd.Flags().BoolP("yes", "y", false, "Deploy without asking for confirmation")
+	deployCmd.Flags().String("baseURL", "", "Override the configured base URL (e.g. for preview deploys)")
+	deployCmd.Flags().Bool("preview", false, "Mark the build as a preview and inject the preview banner")
+	deployCmd.RegisterFlagCompletionFunc("target", completeDeployTargets)
 }
 
 // Build and serve commands are defined in their respective files
@@ -148,11 +199,26 @@ var newPostCmd = &cobra.Command{
 }
 
 var newPageCmd = &cobra.Command{
-	Use:   "page [title]",
+	Use:   "page [path]",
 	Short: "Create a new page",
-	Args:  cobra.ExactArgs(1),
+	Long: `Create a new page from the page archetype.
+
+The path may include directories, which are created under the content
+directory. The page is rendered from the archetype of its content type,
+which is the section's type or else its name, e.g. archetypes/docs.md,
+falling back to archetypes/page.md, archetypes/default.md and then to the
+built-in template. Pages under a docs section get a built-in template with
+weight and sidebar fields.`,
+	Example: `  vango new page about
+  vango new page docs/getting-started/install
+  vango new page "C++ Tips & Tricks" --draft
+  vango new page docs/faq --title "Frequently Asked Questions" --open`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		createNewPage(args[0])
+		title, _ := cmd.Flags().GetString("title")
+		draft, _ := cmd.Flags().GetBool("draft")
+		open, _ := cmd.Flags().GetBool("open")
+		createNewPage(args[0], title, draft, open)
 	},
 }
 
@@ -177,17 +243,29 @@ var configShowCmd = &cobra.Command{
 var configValidateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate configuration",
+	Long: `Validate the site configuration, environment configuration files and
+the active theme's theme.json and config.json.
+
+Besides parsing, it checks that the settings work together: the content,
+layout, static and data directories and each language's content directory
+exist, the theme is installed, publicDir and the source directories aren't
+nested in each other, and baseURL matches the development server's HTTPS
+settings. Unknown keys, which are otherwise silently ignored, are reported
+with a suggestion for the setting that was probably meant.
+
+--fix creates missing directories and adds the trailing slash to baseURL in
+the configuration file that sets it.
+
+Exit status is 0 when the configuration is clean, 2 on errors, and 1 on
+warnings with --strict.`,
+	Example: `  vango config validate
+  vango config validate --strict
+  vango config validate --fix
+  vango config validate --format json`,
 	Run: func(cmd *cobra.Command, args []string) {
-		validateConfig()
-	},
-}
-
-// Version command
-var versionCmd = &cobra.Command{
-	Use:   "version",
-	Short: "Show version information",
-	Run: func(cmd *cobra.Command, args []string) {
-		showVersion()
+		strict, _ := cmd.Flags().GetBool("strict")
+		fix, _ := cmd.Flags().GetBool("fix")
+		validateConfig(strict, fix)
 	},
 }
 
@@ -197,8 +275,17 @@ var benchmarkCmd = &cobra.Command{
 	Short: "Run performance benchmarks",
 	Long: `Run performance benchmarks to measure build speed and optimization.
 
-This helps identify performance bottlenecks and optimize your site.`,
+This helps identify performance bottlenecks and optimize your site.
+
+With --scaling, builds a synthetic site with 1, 2, 4 and 8 workers (and
+more, up to the number of CPUs) and reports the speedup of each.`,
+	Example: `  vango benchmark --iterations 5
+  vango benchmark --scaling --pages 1000`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if scaling, _ := cmd.Flags().GetBool("scaling"); scaling {
+			runScalingBenchmark(cmd)
+			return
+		}
 		runBenchmark(cmd)
 	},
 }
@@ -213,11 +300,19 @@ var validateCmd = &cobra.Command{
 This command checks for:
   • Configuration errors
   • Invalid front matter
-  • Broken internal links
-  • Missing images
-  • SEO issues`,
+  • Pages sharing a URL, URLs that differ only in case, and aliases or
+    redirects landing on a page or on each other, as the build checks them
+  • Accessibility issues in the generated pages: images without alt text,
+    links and buttons without a name, a missing html lang attribute and
+    duplicate ids, reported with the content file when they come from it
+  • Theme colors with too little contrast (below WCAG AA's 4.5:1)
+  • Pages listed in the sitemap but marked noindex
+
+The site is built into a temporary directory for the accessibility checks.
+Accessibility findings are warnings; pass --strict to fail on them too.
+Use --format json for a machine-readable report.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		validateSite()
+		validateSite(cmd)
 	},
 }
 
@@ -232,37 +327,136 @@ Supports deployment to:
   • Netlify
   • Vercel
   • AWS S3
-  • FTP/SFTP servers`,
+  • FTP/SFTP servers
+
+The site is built for production unless --environment is given. Before
+deploying, vango checks the public directory and the base URL, looks for
+broken internal links and asks for confirmation unless --yes is set.`,
 	Example: `  vango deploy github             # Deploy to GitHub Pages
   vango deploy netlify            # Deploy to Netlify
-  vango deploy s3                 # Deploy to AWS S3`,
+  vango deploy s3                 # Deploy to AWS S3
+  vango deploy netlify --skip-build --yes  # Deploy a site CI already built`,
+	ValidArgsFunction: completeDeployTargets,
 	Run: func(cmd *cobra.Command, args []string) {
-		deploySite(args)
+		deploySite(cmd, args)
 	},
 }
 // Command implementations
 func buildSite(cmd *cobra.Command) {
 	start := time.Now()
 	
-	if verbose {
-		fmt.Println("🏗️  Loading configuration...")
-	}
+	logging.Debugf("🏗️  Loading configuration...")
 	
-	cfg, err := loadConfig()
+	cfg, err := loadBuildConfig(cmd)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error loading config: %v\n", err)
+		logging.Errorf("❌ %v", err)
 		os.Exit(1)
 	}
 
-	if verbose {
-		fmt.Printf("📖 Building site '%s'...\n", cfg.Title)
-		fmt.Printf("🌍 Environment: %s\n", cfg.Environment)
-		fmt.Printf("👷 Workers: %d\n", cfg.Workers)
+	logging.Debugf("📖 Building site '%s'...", cfg.Title)
+	logging.Debugf("🌍 Environment: %s", cfg.Environment)
+	logging.Debugf("👷 Workers: %d", cfg.Workers)
+
+	if profile {
+		cfg.Features.ProfileMode = true
+	}
+	if cfg.Features.ProfileMode {
+		logging.Infof("📊 Performance profiling enabled")
+	}
+	b := builder.New(cfg)
+	var progress *buildProgress
+	if showProgress(cmd) {
+		progress = &buildProgress{}
+		b.SetProgressFunc(progress.update)
+	}
+	
+	watch, _ := cmd.Flags().GetBool("watch")
+	err = b.Build()
+	if progress != nil {
+		progress.finish()
+		b.SetProgressFunc(nil)
+	}
+	if err != nil {
+		logging.Errorf("❌ Build failed: %v", err)
+		if !watch {
+			os.Exit(1)
+		}
+		// Keep watching so the next save can fix the build
+		watchBuild(cmd, cfg, b)
+		return
+	}
+
+	duration := time.Since(start)
+	pages := b.GetPages()
+	
+	logging.Infof("✅ Site built successfully!")
+	logging.Infof("📁 Output directory: %s", cfg.PublicDir)
+	logging.Infof("📄 Generated %d pages in %v", len(pages), duration)
+	
+	if report := b.GetReport(); len(report.PrunedFiles) > 0 && !cfg.PruneDryRun {
+		logging.Infof("🧹 Pruned %d orphaned files", len(report.PrunedFiles))
+	}
+	if compression := b.GetReport().Compression; compression != nil && compression.Files > 0 {
+		logCompression(compression, cfg.Performance.Compression.Algorithms)
+	}
+	
+	if progress != nil {
+		logBuildSummary(progress.timings(), b, duration)
+	}
+	
+	logging.Debugf("⚡ Average: %.2f pages/second", float64(len(pages))/duration.Seconds())
+	logging.Debugf("🗂️  Output files: %d", len(b.GetReport().OutputFiles))
+	if metrics := b.GetReport().Metrics; metrics != nil {
+		logBuildMetrics(metrics)
+	}
+
+	if watch {
+		watchBuild(cmd, cfg, b)
+	}
+}
+
+// logCompression logs the compressed size of the precompressed files as a
+// percentage of their original size, per algorithm
+func logCompression(report *builder.CompressionReport, algorithms []string) {
+	ratios := make([]string, 0, len(algorithms))
+	for _, algorithm := range algorithms {
+		ratios = append(ratios, fmt.Sprintf("%s %.1f%%", algorithm, report.Ratio(algorithm)*100))
+	}
+	logging.Infof("🗜️  Precompressed %d files (%.1f KB): %s of original size", report.Files, float64(report.Original)/1024, strings.Join(ratios, ", "))
+}
+
+// logBuildMetrics logs the phase times and the slowest templates and pages
+func logBuildMetrics(metrics *builder.BuildMetrics) {
+	logging.Infof("📊 Parsing: %v, rendering: %v", metrics.ParseTime.Round(time.Microsecond), metrics.RenderTime.Round(time.Microsecond))
+	if len(metrics.Templates) > 0 {
+		logging.Infof("📊 Slowest templates:")
+		for _, t := range metrics.Templates {
+			logging.Infof("   %-30s %10v total  %5d calls  %10v avg", t.Name, t.Total.Round(time.Microsecond), t.Count, t.Average.Round(time.Microsecond))
+		}
+	}
+	if len(metrics.SlowestPages) > 0 {
+		logging.Infof("📊 Slowest pages:")
+		for _, p := range metrics.SlowestPages {
+			logging.Infof("   %-30s %10v", p.URL, p.RenderTime.Round(time.Microsecond))
+		}
+	}
+}
+
+// loadBuildConfig loads the configuration and applies the build flags
+func loadBuildConfig(cmd *cobra.Command) (*config.Config, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("Error loading config: %w", err)
 	}
 
 	// Apply build flags
 	if buildClean, _ := cmd.Flags().GetBool("clean"); buildClean {
+		// Asking for a clean build is asking to drop whatever is in the output
 		cfg.CleanBuild = true
+		cfg.ForceOverwrite = true
+	}
+	if force, _ := cmd.Flags().GetBool("force"); force {
+		cfg.ForceOverwrite = true
 	}
 	if buildDrafts, _ := cmd.Flags().GetBool("drafts"); buildDrafts {
 		cfg.BuildDrafts = true
@@ -270,38 +464,37 @@ func buildSite(cmd *cobra.Command) {
 	if buildFuture, _ := cmd.Flags().GetBool("future"); buildFuture {
 		cfg.BuildFuture = true
 	}
-
-	b := builder.New(cfg)
-	
-	if profile {
-		// Enable profiling
-		fmt.Println("📊 Performance profiling enabled")
+	if minify, _ := cmd.Flags().GetBool("minify"); minify {
+		cfg.Performance.EnableMinification = true
 	}
-	
-	if err := b.Build(); err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Build failed: %v\n", err)
-		os.Exit(1)
+	if cmd.Flags().Changed("keep-previous") {
+		cfg.KeepPrevious, _ = cmd.Flags().GetInt("keep-previous")
 	}
-
-	duration := time.Since(start)
-	pages := b.GetPages()
-	
-	fmt.Printf("✅ Site built successfully!\n")
-	fmt.Printf("📁 Output directory: %s\n", cfg.PublicDir)
-	fmt.Printf("📄 Generated %d pages in %v\n", len(pages), duration)
-	
-	if verbose {
-		fmt.Printf("⚡ Average: %.2f pages/second\n", float64(len(pages))/duration.Seconds())
+	if prune, _ := cmd.Flags().GetBool("prune"); prune {
+		cfg.CleanOrphans = true
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		cfg.PruneDryRun = true
+	}
+	if reproducible, _ := cmd.Flags().GetBool("reproducible"); reproducible {
+		cfg.Reproducible = true
+	}
+	if strictBudget, _ := cmd.Flags().GetBool("strict-budget"); strictBudget {
+		cfg.Budget.Strict = true
+	}
+	if err := applyPreviewFlags(cmd, cfg); err != nil {
+		return nil, err
 	}
+	return cfg, nil
 }
 
 // serveServer function is moved to serve.go file
 
 func createNewSite(name string) {
-	fmt.Printf("🏗️  Creating new site: %s\n", name)
+	logging.Infof("🏗️  Creating new site: %s", name)
 	
 	if err := os.MkdirAll(name, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Failed to create directory: %v\n", err)
+		logging.Errorf("❌ Failed to create directory: %v", err)
 		os.Exit(1)
 	}
 
@@ -317,7 +510,7 @@ func createNewSite(name string) {
 	for _, dir := range dirs {
 		path := filepath.Join(name, dir)
 		if err := os.MkdirAll(path, 0755); err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Failed to create directory %s: %v\n", path, err)
+			logging.Errorf("❌ Failed to create directory %s: %v", path, err)
 			os.Exit(1)
 		}
 	}
@@ -352,7 +545,7 @@ liveReload = true
 
 	configPath := filepath.Join(name, "config.toml")
 	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Failed to create config file: %v\n", err)
+		logging.Errorf("❌ Failed to create config file: %v", err)
 		os.Exit(1)
 	}
 
@@ -384,7 +577,7 @@ liveReload = true
 
 	postPath := filepath.Join(name, "content", "welcome.md")
 	if err := os.WriteFile(postPath, []byte(samplePost), 0644); err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Failed to create sample post: %v\n", err)
+		logging.Errorf("❌ Failed to create sample post: %v", err)
 		os.Exit(1)
 	}
 
@@ -396,13 +589,20 @@ liveReload = true
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>{{ .Page.Title }} | {{ .Site.Title }}</title>
     <meta name="description" content="{{ default .Site.Description .Page.Description }}">
+    {{ metaKeywords .Page }}
+    {{ with .Page.Robots }}<meta name="robots" content="{{ . }}">{{ end }}
+    <style>
+        .skip-link { position: absolute; top: -3rem; left: 1rem; }
+        .skip-link:focus { top: 1rem; }
+    </style>
 </head>
 <body>
+    <a class="skip-link" href="#main-content">{{ i18n "skipToContent" }}</a>
     <header>
-        <h1><a href="/">{{ .Site.Title }}</a></h1>
+        <h1><a href="{{ relURL "/" }}">{{ .Site.Title }}</a></h1>
     </header>
     
-    <main>
+    <main id="main-content">
         <article>
             <h1>{{ .Page.Title }}</h1>
             <time>{{ humanizeDate .Page.ParsedDate }}</time>
@@ -418,7 +618,7 @@ liveReload = true
 
 	templatePath := filepath.Join(name, "layouts", "_default", "single.html")
 	if err := os.WriteFile(templatePath, []byte(templateContent), 0644); err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Failed to create template: %v\n", err)
+		logging.Errorf("❌ Failed to create template: %v", err)
 		os.Exit(1)
 	}
 
@@ -452,22 +652,18 @@ liveReload = true
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>{{ block "title" . }}{{ .Page.Title }} | {{ .Site.Title }}{{ end }}</title>
     <meta name="description" content="{{ block "description" . }}{{ default .Site.Description .Page.Description }}{{ end }}">
+    {{ with .Page }}{{ metaKeywords . }}{{ with .Robots }}<meta name="robots" content="{{ . }}">{{ end }}{{ end }}
     <meta name="author" content="{{ default .Site.Author .Page.Author }}">
     
     <!-- Open Graph / Facebook -->
-    <meta property="og:type" content="{{ block "og_type" . }}article{{ end }}">
-    <meta property="og:url" content="{{ .Site.BaseURL }}{{ .Page.URL }}">
-    <meta property="og:title" content="{{ .Page.Title }}">
-    <meta property="og:description" content="{{ default .Site.Description .Page.Description }}">
+    {{ openGraph .Page }}
     
     <!-- Twitter -->
-    <meta property="twitter:card" content="summary">
-    <meta property="twitter:url" content="{{ .Site.BaseURL }}{{ .Page.URL }}">
-    <meta property="twitter:title" content="{{ .Page.Title }}">
-    <meta property="twitter:description" content="{{ default .Site.Description .Page.Description }}">
+    {{ twitterCard .Page }}
     
     <link rel="stylesheet" href="{{ themeAsset "css/style.css" }}">
-    <link rel="canonical" href="{{ .Site.BaseURL }}{{ .Page.URL }}">
+    <link rel="canonical" href="{{ .Page.CanonicalURL }}">
+    {{ prefetchNext .Page }}
     
     {{ block "head" . }}{{ end }}
     
@@ -478,21 +674,22 @@ liveReload = true
     {{ end }}
 </head>
 <body class="{{ block "body_class" . }}modern-theme{{ end }}">
-    <nav class="navbar">
+    <a class="skip-link" href="#main-content">{{ i18n "skipToContent" }}</a>
+    <nav class="navbar" aria-label="{{ i18n "mainNavigation" }}">
         <div class="nav-container">
-            <a href="" class="nav-logo">{{ .Site.Title }}</a>
+            <a href="{{ relURL "/" }}" class="nav-logo">{{ .Site.Title }}</a>
             <ul class="nav-menu">
-                <li><a href="" class="nav-link">Home</a></li>
-                <li><a href="/about/" class="nav-link">About</a></li>
+                <li><a href="{{ relURL "/" }}" class="nav-link">Home</a></li>
+                <li><a href="{{ relURL "/about/" }}" class="nav-link">About</a></li>
             </ul>
             {{ if hasFeature "dark_mode" }}
-            <button class="theme-toggle" onclick="toggleTheme()">🌙</button>
+            <button type="button" class="theme-toggle" onclick="toggleTheme()" aria-label="Dark mode" aria-pressed="false">🌙</button>
             {{ end }}
         </div>
     </nav>
 
     {{ block "main" . }}
-    <main class="main-content">
+    <main id="main-content" class="main-content">
         {{ block "content" . }}{{ end }}
     </main>
     {{ end }}
@@ -520,7 +717,9 @@ liveReload = true
             document.body.classList.toggle('dark-theme');
             const isDark = document.body.classList.contains('dark-theme');
             sessionStorage.setItem('theme', isDark ? 'dark' : 'light');
-            document.querySelector('.theme-toggle').textContent = isDark ? '☀️' : '🌙';
+            const toggle = document.querySelector('.theme-toggle');
+            toggle.textContent = isDark ? '☀️' : '🌙';
+            toggle.setAttribute('aria-pressed', isDark);
         }
         
         // Load saved theme from sessionStorage
@@ -529,6 +728,7 @@ liveReload = true
             document.body.classList.add('dark-theme');
             if (document.querySelector('.theme-toggle')) {
                 document.querySelector('.theme-toggle').textContent = '☀️';
+                document.querySelector('.theme-toggle').setAttribute('aria-pressed', 'true');
             }
         }
     </script>
@@ -554,7 +754,7 @@ liveReload = true
         <article class="post-card">
             <div class="post-card-content">
                 <h3 class="post-card-title">
-                    <a href="{{ .URL }}" class="post-link">{{ .Title }}</a>
+                    <a href="{{ .RelPermalink }}" class="post-link">{{ .Title }}</a>
                 </h3>
                 <div class="post-card-meta">
                     <time datetime="{{ dateFormat "2006-01-02" .ParsedDate }}">
@@ -562,7 +762,7 @@ liveReload = true
                     </time>
                     {{ if hasFeature "reading_time" }}
                         {{ if gt .ReadingTime 0 }}
-                        • {{ .ReadingTime }} min read
+                        • {{ readingTimeText . }}
                         {{ end }}
                     {{ end }}
                 </div>
@@ -595,7 +795,7 @@ liveReload = true
             {{ end }}
             {{ if hasFeature "reading_time" }}
                 {{ if gt .Page.ReadingTime 0 }}
-                • <span class="reading-time">{{ .Page.ReadingTime }} min read</span>
+                • <span class="reading-time">{{ readingTimeText .Page }}</span>
                 {{ end }}
             {{ end }}
             {{ if .Page.WordCount }}
@@ -622,7 +822,7 @@ liveReload = true
             <strong>Categories:</strong>
             {{ range $i, $cat := .Page.Categories }}
                 {{ if $i }}, {{ end }}
-                <a href="/categories/{{ lower $cat }}/" class="category-link">{{ $cat }}</a>
+                <a href="{{ relURL (printf "/categories/%s/" (lower $cat)) }}" class="category-link">{{ $cat }}</a>
             {{ end }}
         </div>
     </footer>
@@ -927,6 +1127,21 @@ a:hover {
   background-color: var(--color-surface);
 }
 
+.skip-link {
+  position: absolute;
+  top: -3rem;
+  left: var(--spacing-md);
+  z-index: 1000;
+  padding: var(--spacing-sm) var(--spacing-md);
+  background: var(--color-text);
+  color: var(--color-background);
+  border-radius: var(--radius-md);
+}
+
+.skip-link:focus {
+  top: var(--spacing-md);
+}
+
 /* Main content */
 .main-content {
   flex: 1;
@@ -1344,27 +1559,27 @@ a:hover {
 	for path, content := range themeFiles {
 		fullPath := filepath.Join(name, path)
 		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Failed to create directory for %s: %v\n", fullPath, err)
+			logging.Errorf("❌ Failed to create directory for %s: %v", fullPath, err)
 			os.Exit(1)
 		}
 		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Failed to create file %s: %v\n", fullPath, err)
+			logging.Errorf("❌ Failed to create file %s: %v", fullPath, err)
 			os.Exit(1)
 		}
 	}
 
-	fmt.Printf("✅ Site created successfully!\n")
-	fmt.Printf("📁 Location: %s\n", name)
-	fmt.Printf("🚀 Next steps:\n")
-	fmt.Printf("   cd %s\n", name)
-	fmt.Printf("   vango serve\n")
+	logging.Infof("✅ Site created successfully!")
+	logging.Infof("📁 Location: %s", name)
+	logging.Infof("🚀 Next steps:")
+	logging.Infof("   cd %s", name)
+	logging.Infof("   vango serve")
 }
 
 
 func createNewPost(title string) {
 	cfg, err := loadConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error loading config: %v\n", err)
+		logging.Errorf("❌ Error loading config: %v", err)
 		os.Exit(1)
 	}
 
@@ -1391,43 +1606,73 @@ Write your post content here...
 
 	postPath := filepath.Join(cfg.ContentDir, filename)
 	if err := os.WriteFile(postPath, []byte(postContent), 0644); err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Failed to create post: %v\n", err)
+		logging.Errorf("❌ Failed to create post: %v", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("✅ Post created: %s\n", postPath)
+	logging.Infof("✅ Post created: %s", postPath)
 }
 
-func createNewPage(title string) {
+func createNewPage(path, title string, draft, open bool) {
 	cfg, err := loadConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error loading config: %v\n", err)
+		logging.Errorf("❌ Error loading config: %v", err)
 		os.Exit(1)
 	}
 
-	filename := strings.ToLower(title)
-	filename = strings.ReplaceAll(filename, " ", "-")
-	filename += ".md"
-
-	pageContent := fmt.Sprintf(`+++
-title = "%s"
-date = "%s"
-description = ""
-draft = false
-+++
+	dirs, slug, defaultTitle := splitContentPath(path)
+	if slug == "" {
+		logging.Errorf("❌ Cannot derive a file name from %q", path)
+		os.Exit(1)
+	}
+	if title == "" {
+		title = defaultTitle
+	}
 
-# %s
+	pageDir := filepath.Join(append([]string{cfg.ContentDir}, dirs...)...)
+	pagePath := filepath.Join(pageDir, slug+".md")
+	if _, err := os.Stat(pagePath); err == nil {
+		logging.Errorf("❌ Page already exists: %s", pagePath)
+		os.Exit(1)
+	}
 
-Page content goes here...
-`, title, time.Now().Format("2006-01-02T15:04:05Z07:00"), title)
+	// Pages in a section use its content type's archetype when there is one
+	contentType := "page"
+	if len(dirs) > 0 {
+		contentType = dirs[0]
+		if section, ok := cfg.Sections[dirs[0]]; ok && section.DefaultType != "" {
+			contentType = section.DefaultType
+		}
+	}
+	pageContent, err := renderArchetype([]string{contentType, "page"}, builtinArchetype(contentType), archetypeData{
+		Title:   title,
+		Name:    slug,
+		Section: strings.Join(dirs, "/"),
+		Date:    contentTimestamp(),
+		Draft:   draft,
+		Author:  cfg.Author,
+	})
+	if err != nil {
+		logging.Errorf("❌ %v", err)
+		os.Exit(1)
+	}
 
-	pagePath := filepath.Join(cfg.ContentDir, filename)
+	if err := os.MkdirAll(pageDir, 0755); err != nil {
+		logging.Errorf("❌ Failed to create directory %s: %v", pageDir, err)
+		os.Exit(1)
+	}
 	if err := os.WriteFile(pagePath, []byte(pageContent), 0644); err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Failed to create page: %v\n", err)
+		logging.Errorf("❌ Failed to create page: %v", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("✅ Page created: %s\n", pagePath)
+	logging.Infof("✅ Page created: %s", pagePath)
+
+	if open {
+		if err := openInEditor(pagePath); err != nil {
+			logging.Warnf("⚠️  Could not open editor: %v", err)
+		}
+	}
 }
 
 // Theme functions are now in theme.go
@@ -1435,7 +1680,7 @@ Page content goes here...
 func showConfig() {
 	cfg, err := loadConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error loading config: %v\n", err)
+		logging.Errorf("❌ Error loading config: %v", err)
 		os.Exit(1)
 	}
 
@@ -1456,37 +1701,158 @@ func showConfig() {
 		fmt.Printf("Environment: %s\n", cfg.Environment)
 		fmt.Printf("Content Dir: %s\n", cfg.ContentDir)
 		fmt.Printf("Output Dir: %s\n", cfg.PublicDir)
+		if len(cfg.ConfigFiles) == 0 {
+			fmt.Printf("Files: none, using defaults\n")
+		} else {
+			fmt.Printf("Files (merged in order):\n")
+			for _, file := range cfg.ConfigFiles {
+				fmt.Printf("  %s\n", file)
+			}
+		}
 	}
 }
 
-func validateConfig() {
-	cfg, err := loadConfig()
+// configValidation is the report of config validate --format json
+type configValidation struct {
+	Errors   []config.Issue `json:"errors"`
+	Warnings []config.Issue `json:"warnings"`
+	Fixed    []config.Issue `json:"fixed"`
+}
+
+// Exit codes of config validate, for CI to gate on
+const (
+	exitConfigWarnings = 1 // Warnings, with --strict
+	exitConfigErrors   = 2
+)
+
+func validateConfig(strict, fix bool) {
+	if outputFormat != "text" && outputFormat != "json" {
+		logging.Errorf("❌ Unknown format %q (use text or json)", outputFormat)
+		os.Exit(exitConfigErrors)
+	}
+
+	cfg, issues, err := checkConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Configuration validation failed: %v\n", err)
-		os.Exit(1)
+		logging.Errorf("❌ Configuration validation failed: %v", err)
+		os.Exit(exitConfigErrors)
 	}
 
-	fmt.Println("✅ Configuration is valid")
-	fmt.Printf("📊 Settings validated for environment: %s\n", cfg.Environment)
+	report := configValidation{Errors: []config.Issue{}, Warnings: []config.Issue{}, Fixed: []config.Issue{}}
+	if fix {
+		for _, issue := range issues {
+			if !issue.Fixable() {
+				continue
+			}
+			if err := issue.Apply(); err != nil {
+				logging.Errorf("❌ Could not fix %s: %v", issue.Setting, err)
+				continue
+			}
+			report.Fixed = append(report.Fixed, issue)
+		}
+		if len(report.Fixed) > 0 {
+			// Check again what the fixes left
+			if cfg, issues, err = checkConfig(); err != nil {
+				logging.Errorf("❌ Configuration validation failed: %v", err)
+				os.Exit(exitConfigErrors)
+			}
+		}
+	}
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			report.Errors = append(report.Errors, issue)
+		} else {
+			report.Warnings = append(report.Warnings, issue)
+		}
+	}
+
+	if outputFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		for _, issue := range report.Fixed {
+			fmt.Printf("🔧 %s: %s\n", issue.Setting, issue.Fix)
+		}
+		if len(report.Errors) > 0 {
+			fmt.Printf("❌ %d errors:\n", len(report.Errors))
+			for _, issue := range report.Errors {
+				fmt.Printf("   %s\n", issue)
+			}
+		}
+		if len(report.Warnings) > 0 {
+			fmt.Printf("⚠️  %d warnings:\n", len(report.Warnings))
+			for _, issue := range report.Warnings {
+				fmt.Printf("   %s\n", issue)
+			}
+		}
+		if fixable := countFixable(issues); fixable > 0 && !fix {
+			fmt.Printf("🔧 %d of these can be fixed with --fix\n", fixable)
+		}
+		if len(report.Errors) == 0 && len(report.Warnings) == 0 {
+			fmt.Println("✅ Configuration is valid")
+		}
+		fmt.Printf("📊 Settings validated for environment: %s\n", cfg.Environment)
+	}
+
+	switch {
+	case len(report.Errors) > 0:
+		os.Exit(exitConfigErrors)
+	case strict && len(report.Warnings) > 0:
+		os.Exit(exitConfigWarnings)
+	}
+}
+
+// checkConfig loads the configuration without failing on the problems
+// config.Check reports, and returns it with those and the unknown keys of
+// the site's and the theme's configuration
+func checkConfig() (*config.Config, []config.Issue, error) {
+	loader := config.NewConfigLoader()
+	loader.SetDeferChecks(true)
+	cfg, err := loader.LoadConfig(configFiles()...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if environment != "" {
+		cfg.Environment = environment
+	}
+
+	issues := config.Check(cfg)
+	for _, key := range loader.UnknownKeys() {
+		file, message, _ := strings.Cut(key, ": ")
+		issues = append(issues, config.Issue{Severity: config.SeverityWarning, Setting: file, Message: message})
+	}
+	if cfg.Theme != "" {
+		// A missing theme is already reported
+		if unknown, err := theme.NewThemeManager(cfg).UnknownConfigKeys(cfg.Theme); err == nil {
+			for _, key := range unknown {
+				issues = append(issues, config.Issue{Severity: config.SeverityWarning, Setting: "theme " + cfg.Theme, Message: key})
+			}
+		}
+	}
+	return cfg, issues, nil
 }
 
-func showVersion() {
-	version := rootCmd.Version
-	fmt.Printf("VanGo v%s\n", version)
-	fmt.Printf("Built with Go\n")
-	fmt.Printf("https://github.com/vango/vango\n")
+// countFixable counts the issues --fix can repair
+func countFixable(issues []config.Issue) int {
+	count := 0
+	for _, issue := range issues {
+		if issue.Fixable() {
+			count++
+		}
+	}
+	return count
 }
 
 func runBenchmark(cmd *cobra.Command) {
 	iterations, _ := cmd.Flags().GetInt("iterations")
 	includeMemory, _ := cmd.Flags().GetBool("memory")
 
-	fmt.Printf("🏃 Running benchmark (%d iterations)...\n", iterations)
+	logging.Infof("🏃 Running benchmark (%d iterations)...", iterations)
 	
 	var totalDuration time.Duration
 	cfg, err := loadConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error loading config: %v\n", err)
+		logging.Errorf("❌ Error loading config: %v", err)
 		os.Exit(1)
 	}
 
@@ -1495,7 +1861,7 @@ func runBenchmark(cmd *cobra.Command) {
 	for i := 0; i < iterations; i++ {
 		start := time.Now()
 		if err := b.Build(); err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Build failed on iteration %d: %v\n", i+1, err)
+			logging.Errorf("❌ Build failed on iteration %d: %v", i+1, err)
 			os.Exit(1)
 		}
 		duration := time.Since(start)
@@ -1522,71 +1888,405 @@ func runBenchmark(cmd *cobra.Command) {
 	}
 }
 
-func validateSite() {
-	fmt.Println("🔍 Validating site...")
+// runScalingBenchmark builds a synthetic site with different worker counts
+func runScalingBenchmark(cmd *cobra.Command) {
+	iterations, _ := cmd.Flags().GetInt("iterations")
+	pageCount, _ := cmd.Flags().GetInt("pages")
+	if iterations < 1 {
+		iterations = 1
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		logging.Errorf("❌ Error loading config: %v", err)
+		os.Exit(1)
+	}
+
+	fixture, err := os.MkdirTemp("", "vango-bench-")
+	if err != nil {
+		logging.Errorf("❌ Failed to create fixture directory: %v", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(fixture)
+
+	contentDir := filepath.Join(fixture, "content")
+	if err := writeSyntheticContent(contentDir, pageCount); err != nil {
+		logging.Errorf("❌ Failed to write synthetic content: %v", err)
+		os.Exit(1)
+	}
+
+	counts := []int{1, 2, 4, 8}
+	for n := 16; n < runtime.NumCPU(); n *= 2 {
+		counts = append(counts, n)
+	}
+	if runtime.NumCPU() > 8 {
+		counts = append(counts, runtime.NumCPU())
+	}
+
+	logging.Infof("🏃 Scaling benchmark: %d pages, %d iterations, %d CPUs (default workers: %d)",
+		pageCount, iterations, runtime.NumCPU(), config.DefaultWorkers())
+
+	type result struct {
+		workers int
+		avg     time.Duration
+	}
+	var results []result
+
+	for _, n := range counts {
+		benchCfg := *cfg
+		benchCfg.Workers = n
+		benchCfg.ContentDir = contentDir
+		benchCfg.PublicDir = filepath.Join(fixture, "public")
+		benchCfg.CleanBuild = false
+		benchCfg.CleanOrphans = false
+		benchCfg.Redirects = nil
+
+		var total time.Duration
+		for i := 0; i < iterations; i++ {
+			// A fresh builder per run so its modification cache doesn't skip pages
+			b := builder.New(&benchCfg)
+			start := time.Now()
+			if err := b.Build(); err != nil {
+				logging.Errorf("❌ Build failed with %d workers: %v", n, err)
+				os.Exit(1)
+			}
+			total += time.Since(start)
+		}
+		results = append(results, result{workers: n, avg: total / time.Duration(iterations)})
+	}
+
+	fmt.Printf("📊 Scaling Results:\n")
+	fmt.Printf("  %-8s %-14s %-12s %s\n", "Workers", "Average", "Pages/sec", "Speedup")
+	for _, r := range results {
+		fmt.Printf("  %-8d %-14v %-12.0f %.2fx\n", r.workers, r.avg.Round(time.Millisecond),
+			float64(pageCount)/r.avg.Seconds(), results[0].avg.Seconds()/r.avg.Seconds())
+	}
+}
+
+// writeSyntheticContent writes count generated pages into dir
+func writeSyntheticContent(dir string, count int) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	body := strings.Repeat("Lorem ipsum dolor sit amet, **consectetur** adipiscing elit. "+
+		"Sed do eiusmod tempor [incididunt](https://example.com) ut labore.\n\n", 20)
+	for i := 0; i < count; i++ {
+		page := fmt.Sprintf(`+++
+title = "Synthetic Page %d"
+date = "2024-01-01T00:00:00Z"
+tags = ["bench", "tag-%d"]
++++
+
+## Section %d
+
+%s
+`+"```go\nfunc main() {}\n```\n", i, i%10, i, body)
+		path := filepath.Join(dir, fmt.Sprintf("page-%04d.md", i))
+		if err := os.WriteFile(path, []byte(page), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// siteIssue is a problem reported by vango validate
+type siteIssue struct {
+	Check   string `json:"check"`
+	File    string `json:"file,omitempty"` // source file to fix, when known
+	Page    string `json:"page,omitempty"` // generated page, relative to the output directory
+	Message string `json:"message"`
+}
+
+// siteValidation collects validate results, printing them as they come in
+// text mode and as a single report in JSON mode
+type siteValidation struct {
+	json     bool
+	Errors   []siteIssue `json:"errors"`
+	Warnings []siteIssue `json:"warnings"`
+}
+
+func (v *siteValidation) pass(format string, args ...interface{}) {
+	if !v.json {
+		fmt.Printf("✅ "+format+"\n", args...)
+	}
+}
+
+func (v *siteValidation) fail(issue siteIssue) {
+	v.Errors = append(v.Errors, issue)
+	if !v.json {
+		fmt.Printf("❌ %s\n", issue)
+	}
+}
+
+func (v *siteValidation) warn(issue siteIssue) {
+	v.Warnings = append(v.Warnings, issue)
+	if !v.json {
+		fmt.Printf("⚠️  %s\n", issue)
+	}
+}
+
+// String formats an issue with its file and page references for text output
+func (i siteIssue) String() string {
+	var where []string
+	if i.File != "" {
+		where = append(where, i.File)
+	}
+	if i.Page != "" {
+		where = append(where, i.Page)
+	}
+	if len(where) == 0 {
+		return i.Message
+	}
+	return strings.Join(where, " → ") + ": " + i.Message
+}
+
+func validateSite(cmd *cobra.Command) {
+	strict, _ := cmd.Flags().GetBool("strict")
+	skipA11y, _ := cmd.Flags().GetBool("skip-a11y")
+	checkHTML, _ := cmd.Flags().GetBool("html")
+	if outputFormat != "text" && outputFormat != "json" {
+		logging.Errorf("❌ Unknown format %q (use text or json)", outputFormat)
+		os.Exit(1)
+	}
+
+	logging.Infof("🔍 Validating site...")
 	
 	cfg, err := loadConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Configuration error: %v\n", err)
+		logging.Errorf("❌ Configuration error: %v", err)
 		os.Exit(1)
 	}
 
-	issues := 0
+	v := &siteValidation{json: outputFormat == "json", Errors: []siteIssue{}, Warnings: []siteIssue{}}
 	
 	// Validate configuration
-	fmt.Printf("✅ Configuration valid\n")
+	v.pass("Configuration valid")
 	
 	// Check content directory
 	if _, err := os.Stat(cfg.ContentDir); os.IsNotExist(err) {
-		fmt.Printf("❌ Content directory missing: %s\n", cfg.ContentDir)
-		issues++
+		v.fail(siteIssue{Check: "structure", File: cfg.ContentDir, Message: "content directory missing"})
 	} else {
-		fmt.Printf("✅ Content directory exists\n")
+		v.pass("Content directory exists")
 	}
 	
 	// Check layout directory
 	if _, err := os.Stat(cfg.LayoutDir); os.IsNotExist(err) {
-		fmt.Printf("❌ Layout directory missing: %s\n", cfg.LayoutDir)
-		issues++
+		v.fail(siteIssue{Check: "structure", File: cfg.LayoutDir, Message: "layout directory missing"})
 	} else {
-		fmt.Printf("✅ Layout directory exists\n")
+		v.pass("Layout directory exists")
 	}
 
 	// Validate content files
-	// Implementation would check for valid front matter, broken links, etc.
-	
-	if issues == 0 {
+	validateContentDates(cfg, v)
+	validateURLs(cfg, v)
+
+	// Check the generated pages, once the site is known to be buildable
+	if !skipA11y && len(v.Errors) == 0 {
+		if checkHTML {
+			cfg.HTMLValidation.Enable = true
+		}
+		validateAccessibility(cfg, v)
+	}
+
+	failed := len(v.Errors) > 0 || (strict && len(v.Warnings) > 0)
+	if v.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(v)
+	} else if len(v.Errors) == 0 && len(v.Warnings) == 0 {
 		fmt.Printf("✅ Site validation completed - no issues found\n")
 	} else {
-		fmt.Printf("⚠️  Site validation completed - %d issues found\n", issues)
+		fmt.Printf("⚠️  Site validation completed - %d errors, %d warnings\n", len(v.Errors), len(v.Warnings))
+	}
+	if failed {
 		os.Exit(1)
 	}
 }
 
-func deploySite(args []string) {
+// validateContentDates parses every content page, reporting front matter
+// errors and warning about pages with no date or no title
+func validateContentDates(cfg *config.Config, v *siteValidation) {
+	parser := content.NewParser()
+	parser.SetDateFromFilename(cfg.DateFromFilename, cfg.KeepFilenameDate)
+	parser.SetFrontMatterExpander(builder.FrontMatterExpander(cfg))
+	parser.SetTitleCaseStyle(cfg.TitleCaseStyle)
+	parser.SetExtensions(cfg.ContentExtensions)
+	if cfg.TimeZone != "" {
+		parser.SetLocation(cfg.GetLocation())
+	}
+
+	errors := len(v.Errors)
+	filepath.Walk(cfg.ContentDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !content.IsContentFile(path, cfg.ContentExtensions) || content.IsSectionIndex(path, cfg.ContentExtensions) {
+			return nil
+		}
+		page, err := parser.ParseFile(path, cfg.ContentDir)
+		if err != nil {
+			v.fail(siteIssue{Check: "front-matter", Message: err.Error()})
+			return nil
+		}
+		switch page.DateSource {
+		case "":
+			if cfg.DateFromFilename {
+				v.warn(siteIssue{Check: "date", File: path, Message: "no date in front matter or file name"})
+			} else {
+				v.warn(siteIssue{Check: "date", File: path, Message: "no date in front matter"})
+			}
+		case "filename":
+			if verbose && !v.json {
+				fmt.Printf("📅 %s: dated %s from its file name\n", path, page.ParsedDate.Format("2006-01-02"))
+			}
+		}
+		switch page.Params["titleSource"] {
+		case content.TitleFromHeading:
+			v.warn(siteIssue{Check: "title", File: path, Message: fmt.Sprintf("no title in front matter; using %q from its first heading", page.Title)})
+		case content.TitleFromFilename:
+			v.warn(siteIssue{Check: "title", File: path, Message: fmt.Sprintf("no title in front matter; using %q from its file name", page.Title)})
+		}
+		return nil
+	})
+	if len(v.Errors) == errors {
+		v.pass("Content front matter valid")
+	}
+}
+
+// validateURLs reports pages, aliases and redirects claiming the same URL,
+// the check a build fails on, without building
+func validateURLs(cfg *config.Config, v *siteValidation) {
+	conflicts, err := builder.New(cfg).CheckURLs()
+	if err != nil {
+		v.fail(siteIssue{Check: "urls", Message: err.Error()})
+		return
+	}
+	for _, conflict := range conflicts {
+		issue := siteIssue{Check: "urls/" + conflict.Kind, File: conflict.Files[0], Page: conflict.URL, Message: conflict.Message}
+		if conflict.Kind == builder.URLConflictCase && cfg.CaseCollisionLevel == "warning" {
+			v.warn(issue)
+		} else {
+			v.fail(issue)
+		}
+	}
+	if len(conflicts) == 0 {
+		v.pass("No conflicting URLs")
+	}
+}
+
+// validateAccessibility builds the site into a temporary directory and
+// audits the generated pages, the theme's colors and the sitemap, and with
+// htmlValidation their markup
+func validateAccessibility(cfg *config.Config, v *siteValidation) {
+	tmp, err := os.MkdirTemp("", "vango-validate-*")
+	if err != nil {
+		v.fail(siteIssue{Check: "build", Message: err.Error()})
+		return
+	}
+	defer os.RemoveAll(tmp)
+	cfg.PublicDir = tmp
+
+	b := builder.New(cfg)
+	if err := b.Build(); err != nil {
+		v.fail(siteIssue{Check: "build", Message: fmt.Sprintf("build failed: %v", err)})
+		return
+	}
+
+	issues, err := b.CheckAccessibility()
+	if err != nil {
+		v.fail(siteIssue{Check: "a11y", Message: fmt.Sprintf("accessibility check failed: %v", err)})
+		return
+	}
+	for _, issue := range issues {
+		v.warn(siteIssue{Check: "a11y/" + issue.Rule, File: issue.Source, Page: issue.Page, Message: issue.Message})
+	}
+
+	contrast, err := b.CheckContrast()
+	if err != nil {
+		v.fail(siteIssue{Check: "contrast", Message: err.Error()})
+		return
+	}
+	for _, issue := range contrast {
+		v.warn(siteIssue{Check: "a11y/contrast", File: issue.File, Message: issue.Message})
+	}
+
+	if len(issues) == 0 && len(contrast) == 0 {
+		v.pass("No accessibility issues")
+	}
+
+	seo, err := b.CheckSEO()
+	if err != nil {
+		v.fail(siteIssue{Check: "seo", Message: fmt.Sprintf("SEO check failed: %v", err)})
+		return
+	}
+	for _, issue := range seo {
+		v.warn(siteIssue{Check: "seo/noindex", File: issue.Source, Page: issue.Page, Message: issue.Message})
+	}
+	if len(seo) == 0 {
+		v.pass("Sitemap agrees with robots directives")
+	}
+
+	if cfg.HTMLValidation.Enable {
+		htmlIssues := b.HTMLIssues()
+		for _, issue := range htmlIssues {
+			message := fmt.Sprintf("line %d: %s", issue.Line, issue.Message)
+			if issue.Heading != "" {
+				message = fmt.Sprintf("line %d (under %q): %s", issue.Line, issue.Heading, issue.Message)
+			}
+			v.warn(siteIssue{Check: "html/" + issue.Rule, Page: issue.Page, Message: message})
+		}
+		if len(htmlIssues) == 0 {
+			v.pass("No HTML problems")
+		}
+	}
+}
+
+func deploySite(cmd *cobra.Command, args []string) {
 	if len(args) == 0 {
-		fmt.Println("❌ Deployment target required")
-		fmt.Println("Available targets: github, netlify, vercel, s3, ftp")
+		logging.Errorf("❌ Deployment target required")
+		logging.Infof("Available targets: github, netlify, vercel, s3, ftp")
 		os.Exit(1)
 	}
 
 	target := args[0]
-	fmt.Printf("🚀 Deploying to %s...\n", target)
+	switch target {
+	case "github", "netlify", "vercel", "s3":
+	default:
+		logging.Errorf("❌ Unknown deployment target: %s", target)
+		os.Exit(1)
+	}
+	logging.Infof("🚀 Deploying to %s...", target)
 	
-	// Build site first
+	// Deploys build for production unless --environment says otherwise
+	if environment == "" {
+		environment = "production"
+	}
 	cfg, err := loadConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error loading config: %v\n", err)
+		logging.Errorf("❌ Error loading config: %v", err)
+		os.Exit(1)
+	}
+	if cfg.IsProduction() {
+		cfg.Performance.EnableMinification = true
+	}
+	if err := applyPreviewFlags(cmd, cfg); err != nil {
+		logging.Errorf("❌ %v", err)
 		os.Exit(1)
 	}
-
-	// Set production environment
-	cfg.Environment = "production"
-	cfg.Performance.EnableMinification = true
 	
-	b := builder.New(cfg)
-	if err := b.Build(); err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Build failed: %v\n", err)
+	if skipBuild, _ := cmd.Flags().GetBool("skip-build"); skipBuild {
+		logging.Infof("⏭️  Skipping build, deploying %s as is", cfg.PublicDir)
+	} else {
+		b := builder.New(cfg)
+		if err := b.Build(); err != nil {
+			logging.Errorf("❌ Build failed: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	assumeYes, _ := cmd.Flags().GetBool("yes")
+	force, _ := cmd.Flags().GetBool("force")
+	manifest, err := deployPreflight(cfg, target, assumeYes, force)
+	if err != nil {
+		logging.Errorf("❌ Pre-flight check failed: %v", err)
 		os.Exit(1)
 	}
 
@@ -1599,39 +2299,45 @@ func deploySite(args []string) {
 		deployToVercel(cfg)
 	case "s3":
 		deployToS3(cfg)
-	default:
-		fmt.Printf("❌ Unknown deployment target: %s\n", target)
-		os.Exit(1)
+	}
+	if err := saveDeployManifest(cfg, target, manifest); err != nil {
+		logging.Warnf("⚠️  Warning: could not record the deploy manifest: %v", err)
 	}
 }
 
 func deployToGitHub(cfg *config.Config) {
-	fmt.Println("📤 Deploying to GitHub Pages...")
+	logging.Infof("📤 Deploying to GitHub Pages...")
 	// Implementation for GitHub Pages deployment
-	fmt.Println("✅ Deployed to GitHub Pages!")
+	logging.Infof("✅ Deployed to GitHub Pages!")
 }
 
 func deployToNetlify(cfg *config.Config) {
-	fmt.Println("📤 Deploying to Netlify...")
+	logging.Infof("📤 Deploying to Netlify...")
 	// Implementation for Netlify deployment
-	fmt.Println("✅ Deployed to Netlify!")
+	logging.Infof("✅ Deployed to Netlify!")
 }
 
 func deployToVercel(cfg *config.Config) {
-	fmt.Println("📤 Deploying to Vercel...")
+	logging.Infof("📤 Deploying to Vercel...")
 	// Implementation for Vercel deployment
-	fmt.Println("✅ Deployed to Vercel!")
+	logging.Infof("✅ Deployed to Vercel!")
 }
 
 func deployToS3(cfg *config.Config) {
-	fmt.Println("📤 Deploying to AWS S3...")
+	logging.Infof("📤 Deploying to AWS S3...")
 	// Implementation for S3 deployment
-	fmt.Println("✅ Deployed to S3!")
+	logging.Infof("✅ Deployed to S3!")
+}
+
+// configFiles returns the configuration files to merge, from --config and
+// the local overrides file unless --no-local-config is set
+func configFiles() []string {
+	return config.ConfigFiles(configPaths, !noLocalConfig)
 }
 
 // Helper function to load configuration
 func loadConfig() (*config.Config, error) {
-	cfg, err := config.Load(configPath)
+	cfg, err := config.Load(configFiles()...)
 	if err != nil {
 		return nil, err
 	}
@@ -1645,4 +2351,19 @@ func loadConfig() (*config.Config, error) {
 	}
 
 	return cfg, nil
-}
\ No newline at end of file
+}
+
+// applyPreviewFlags applies the --baseURL and --preview flags shared by build and deploy
+func applyPreviewFlags(cmd *cobra.Command, cfg *config.Config) error {
+	if baseURL, _ := cmd.Flags().GetString("baseURL"); baseURL != "" {
+		if err := cfg.OverrideBaseURL(baseURL); err != nil {
+			return err
+		}
+		logging.Debugf("🔗 Base URL: %s", cfg.BaseURL)
+	}
+	if preview, _ := cmd.Flags().GetBool("preview"); preview {
+		cfg.IsPreview = true
+		logging.Debugf("👀 Preview build: banner will be injected into every page")
+	}
+	return nil
+}
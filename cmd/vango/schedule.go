@@ -0,0 +1,110 @@
+package vango
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"vango/internal/builder"
+	"vango/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+var scheduleStaleDays int
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Show scheduled, expired and stale draft pages",
+	Long: `Show the content calendar: pages with a future publish date and how
+long until they go live, pages past their expiry date, and drafts nobody
+has touched for --stale-after days, going by their lastmod date, else their
+date, else the file's modification time.
+
+Dates are read and shown in the configured timeZone. --format json gives
+the same lists with next_publish_at, the time the site next needs
+rebuilding, for a cron job or CI workflow.`,
+	Example: `  vango schedule
+  vango schedule --stale-after 14
+  vango schedule --format json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if outputFormat != "text" && outputFormat != "json" {
+			logging.Errorf("❌ Unknown format %q (use text or json)", outputFormat)
+			os.Exit(1)
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			logging.Errorf("❌ Error loading config: %v", err)
+			os.Exit(1)
+		}
+		schedule, err := builder.New(cfg).Schedule(time.Duration(scheduleStaleDays) * 24 * time.Hour)
+		if err != nil {
+			logging.Errorf("❌ %v", err)
+			os.Exit(1)
+		}
+
+		if outputFormat == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			enc.Encode(schedule)
+			return
+		}
+
+		loc := cfg.GetLocation()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		section := func(title string, pages []builder.ScheduledPage, when func(time.Time) string) {
+			if len(pages) == 0 {
+				return
+			}
+			fmt.Fprintf(w, "%s (%d)\n", title, len(pages))
+			for _, page := range pages {
+				name := page.Title
+				if page.Draft && title != "📝 Stale drafts" {
+					name += " [draft]"
+				}
+				fmt.Fprintf(w, "   %s\t%s\t%s\t%s\n", page.Date.In(loc).Format("2006-01-02 15:04 MST"), when(page.Date), name, page.File)
+			}
+		}
+		section("📅 Scheduled", schedule.Scheduled, func(t time.Time) string {
+			return "in " + roughDuration(t.Sub(schedule.Now))
+		})
+		section("⌛ Expired", schedule.Expired, func(t time.Time) string {
+			return roughDuration(schedule.Now.Sub(t)) + " ago"
+		})
+		section("📝 Stale drafts", schedule.StaleDrafts, func(t time.Time) string {
+			return roughDuration(schedule.Now.Sub(t)) + " old"
+		})
+		w.Flush()
+
+		switch {
+		case schedule.NextPublishAt != nil:
+			fmt.Printf("⏰ Next page goes live at %s\n", schedule.NextPublishAt.In(loc).Format("2006-01-02 15:04 MST"))
+		case len(schedule.Scheduled)+len(schedule.Expired)+len(schedule.StaleDrafts) == 0:
+			fmt.Println("✅ Nothing scheduled, expired or stale")
+		}
+	},
+}
+
+// roughDuration formats d to the nearest minute with its two largest units,
+// e.g. "3d 4h"
+func roughDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	days, hours := minutes/(24*60), minutes/60%24
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes%60)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return "<1m"
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.Flags().IntVar(&scheduleStaleDays, "stale-after", 30, "Days without changes after which a draft is stale (0 to skip)")
+}
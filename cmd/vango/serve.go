@@ -1,17 +1,26 @@
 package vango
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 	"vango/internal/config"
+	"vango/internal/logging"
 	"vango/internal/server"
 )
 
 var (
-	servePort int
-	serveHost string
+	servePort   int
+	serveHost   string
+	serveLazy   bool
+	serveNotify bool
+
+	serveAccessLog       string
+	serveAccessLogFormat string
+
+	serveTLS     bool
+	serveTLSCert string
+	serveTLSKey  string
 )
 
 var serveCmd = &cobra.Command{
@@ -26,19 +35,34 @@ Server provides:
   • Live preview at http://localhost:1313
   • Automatic rebuilding on file changes  
   • API endpoints for debugging
-  • Hot reload support`,
+  • Hot reload support
+
+With --tls (or [security.https] enable = true) the server uses HTTPS and
+HTTP/2. Without --cert and --key a self-signed localhost certificate is
+generated in the user cache directory; trust it to avoid browser warnings.
+Setting redirectHTTP in [security.https] redirects httpPort to HTTPS.
+
+With --notify (or notify = true in the config) a desktop notification
+shows the first line of the error when a build fails, and another when
+the next build succeeds. It uses notify-send, or osascript on macOS;
+set notifyCommand to run your own command as "command title body".
+The admin panel's Download Build Log button saves recent build errors
+and builds as JSON for bug reports.`,
 	Example: `  vango serve                     # Start server on default port (1313)
   vango serve -p 8080             # Start server on port 8080
   vango serve --host 0.0.0.0      # Bind to all interfaces
+  vango serve --lazy              # Render pages on first request
+  vango serve --notify            # Desktop notification when a build fails
+  vango serve --access-log access.log --access-log-format json
+  vango serve --tls               # Serve HTTPS with a generated certificate
+  vango serve --tls --cert localhost.pem --key localhost-key.pem
   vango serve -v                  # Start with verbose output`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if verbose {
-			fmt.Println("🚀 Starting development server...")
-		}
+		logging.Debugf("🚀 Starting development server...")
 		
-		cfg, err := config.Load(configPath)
+		cfg, err := config.Load(configFiles()...)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Error loading config: %v\n", err)
+			logging.Errorf("❌ Error loading config: %v", err)
 			os.Exit(1)
 		}
 
@@ -50,20 +74,47 @@ Server provides:
 			cfg.Host = serveHost
 		}
 
-		if verbose {
-			fmt.Printf("🏠 Site: %s\n", cfg.Title)
-			fmt.Printf("🌐 Host: %s\n", cfg.Host)
-			fmt.Printf("🔌 Port: %d\n", cfg.Port)
-			fmt.Printf("🔄 Live Reload: %v\n", cfg.LiveReload)
-		}
+		logging.Debugf("🏠 Site: %s", cfg.Title)
+		logging.Debugf("🌐 Host: %s", cfg.Host)
+		logging.Debugf("🔌 Port: %d", cfg.Port)
+		logging.Debugf("🔄 Live Reload: %v", cfg.LiveReload)
 
+		if profile {
+			cfg.Features.ProfileMode = true
+		}
 		s := server.New(cfg, cfg.Port)
 		s.SetVerbose(verbose) // Pass verbose flag to server
-		fmt.Printf("🎨 Development server starting...\n")
-		fmt.Printf("🔗 Local: http://%s:%d\n", cfg.Host, cfg.Port)
-		fmt.Println("📝 Press Ctrl+C to stop")
+		s.SetConfigFiles(configFiles())
+		s.SetLazy(serveLazy)
+		s.SetNotify(serveNotify || cfg.Notify, cfg.NotifyCommand)
+		if serveAccessLog != "" {
+			if err := s.SetAccessLog(serveAccessLog, serveAccessLogFormat); err != nil {
+				logging.Errorf("❌ %v", err)
+				os.Exit(1)
+			}
+		}
+		https := cfg.Security.HTTPS
+		if serveTLSCert != "" || serveTLSKey != "" {
+			if serveTLSCert == "" || serveTLSKey == "" {
+				logging.Errorf("❌ --cert and --key must be used together")
+				os.Exit(1)
+			}
+			https.CertFile, https.KeyFile = serveTLSCert, serveTLSKey
+			serveTLS = true
+		}
+		scheme := "http"
+		if serveTLS || https.Enable {
+			s.SetTLS(https.CertFile, https.KeyFile)
+			if https.RedirectHTTP {
+				s.SetHTTPRedirect(https.HTTPPort)
+			}
+			scheme = "https"
+		}
+		logging.Infof("🎨 Development server starting...")
+		logging.Infof("🔗 Local: %s://%s:%d", scheme, cfg.Host, cfg.Port)
+		logging.Infof("📝 Press Ctrl+C to stop")
 		if err := s.Start(); err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Server failed: %v\n", err)
+			logging.Errorf("❌ Server failed: %v", err)
 			os.Exit(1)
 		}
 	},
@@ -73,5 +124,13 @@ func init() {
 	rootCmd.AddCommand(serveCmd)
 	serveCmd.Flags().IntVarP(&servePort, "port", "p", 1313, "Port for development server")
 	serveCmd.Flags().StringVar(&serveHost, "host", "localhost", "Host to bind to")
+	serveCmd.Flags().BoolVar(&serveLazy, "lazy", false, "Start immediately and render pages on first request")
+	serveCmd.Flags().BoolVar(&serveNotify, "notify", false, "Show a desktop notification when a build fails or recovers")
+	serveCmd.Flags().StringVar(&serveAccessLog, "access-log", "", "Append an access log for every request to this file")
+	serveCmd.Flags().StringVar(&serveAccessLogFormat, "access-log-format", "combined", "Access log format (combined, json)")
+	serveCmd.Flags().BoolVar(&serveTLS, "tls", false, "Serve HTTPS with HTTP/2, generating a localhost certificate if needed")
+	serveCmd.Flags().StringVar(&serveTLSCert, "cert", "", "TLS certificate file (implies --tls)")
+	serveCmd.Flags().StringVar(&serveTLSKey, "key", "", "TLS private key file (implies --tls)")
+	serveCmd.RegisterFlagCompletionFunc("access-log-format", cobra.FixedCompletions([]string{"combined", "json"}, cobra.ShellCompDirectiveNoFileComp))
 }
 
@@ -0,0 +1,96 @@
+package vango
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"vango/internal/builder"
+	"vango/internal/content"
+	"vango/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+var statsSection string
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show word counts, reading times and posts per period and tag",
+	Long: `Show statistics about the site's content: total words, total and
+average reading time, pages per year and month, pages per tag and the
+longest and shortest pages.
+
+Content is parsed but not rendered, and only regular pages count, not
+section or home pages. --section limits the statistics to one section.
+--format json gives the same figures as templates get in .Site.Stats.`,
+	Example: `  vango stats
+  vango stats --section posts
+  vango stats --format json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if outputFormat != "text" && outputFormat != "json" {
+			logging.Errorf("❌ Unknown format %q (use text or json)", outputFormat)
+			os.Exit(1)
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			logging.Errorf("❌ Error loading config: %v", err)
+			os.Exit(1)
+		}
+		stats, err := builder.New(cfg).ContentStats(statsSection)
+		if err != nil {
+			logging.Errorf("❌ %v", err)
+			os.Exit(1)
+		}
+
+		if outputFormat == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			enc.Encode(stats)
+			return
+		}
+		if stats.Pages == 0 {
+			fmt.Println("📭 No pages")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "📊 Pages\t%d\n", stats.Pages)
+		fmt.Fprintf(w, "   Words\t%d\n", stats.Words)
+		fmt.Fprintf(w, "   Reading time\t%d min\n", stats.ReadingTime)
+		fmt.Fprintf(w, "   Average\t%d words, %d min\n", stats.AverageWords, stats.AverageReadingTime)
+		periods := func(title string, list []content.PeriodStats) {
+			if len(list) == 0 {
+				return
+			}
+			fmt.Fprintf(w, "\n%s\tPages\tWords\n", title)
+			for _, p := range list {
+				fmt.Fprintf(w, "   %s\t%d\t%d\n", p.Period, p.Pages, p.Words)
+			}
+		}
+		periods("📅 Years", stats.Years)
+		periods("🗓️  Months", stats.Months)
+		if len(stats.Tags) > 0 {
+			fmt.Fprintf(w, "\n🏷️  Tags\tPages\tWords\n")
+			for _, t := range stats.Tags {
+				fmt.Fprintf(w, "   %s\t%d\t%d\n", t.Tag, t.Pages, t.Words)
+			}
+		}
+		extremes := func(title string, list []content.PageStats) {
+			fmt.Fprintf(w, "\n%s\tWords\tFile\n", title)
+			for _, p := range list {
+				fmt.Fprintf(w, "   %s\t%d\t%s\n", p.Title, p.Words, p.File)
+			}
+		}
+		extremes("📏 Longest", stats.Longest)
+		extremes("✂️  Shortest", stats.Shortest)
+		w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().StringVar(&statsSection, "section", "", "Only count the pages of this section")
+}
@@ -1,11 +1,16 @@
 package vango
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"text/tabwriter"
 
+	"vango/internal/builder"
 	"vango/internal/config"
+	"vango/internal/logging"
 	"vango/internal/theme"
 
 	"github.com/spf13/cobra"
@@ -20,13 +25,47 @@ var themeCmd = &cobra.Command{
 var themeListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all available themes",
+	Long: `List the installed themes, sorted by name or by author.
+
+--detailed adds each theme's tags, features and license. --tag lists only
+the themes with that tag, and can be given more than once to require
+several. --format json prints every theme's full theme.json metadata.`,
+	Example: `  vango theme list --detailed
+  vango theme list --sort author --tag dark-mode
+  vango theme list --format json`,
 	Run: func(cmd *cobra.Command, args []string) {
+		detailed, _ := cmd.Flags().GetBool("detailed")
+		sortBy, _ := cmd.Flags().GetString("sort")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		if outputFormat != "text" && outputFormat != "json" {
+			logging.Errorf("❌ Unknown format %q (use text or json)", outputFormat)
+			os.Exit(1)
+		}
+
 		cfg, _ := config.Load("config.toml")
 		themeManager := theme.NewThemeManager(cfg)
 		themeManager.LoadThemes()
-		themes := themeManager.ListThemes()
+		themes, err := themeManager.SortedThemes(sortBy, tags)
+		if err != nil {
+			logging.Errorf("❌ %v", err)
+			os.Exit(1)
+		}
+
+		if outputFormat == "json" {
+			if themes == nil {
+				themes = []*theme.Theme{}
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			enc.Encode(themes)
+			return
+		}
 
 		if len(themes) == 0 {
+			if len(tags) > 0 {
+				fmt.Printf("No themes tagged %s\n", strings.Join(tags, ", "))
+				return
+			}
 			fmt.Println("No themes found. Create a theme with 'vango theme create <name>'")
 			return
 		}
@@ -35,8 +74,13 @@ var themeListCmd = &cobra.Command{
 		fmt.Println("")
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "NAME\tVERSION\tAUTHOR\tDESCRIPTION")
-		fmt.Fprintln(w, "----\t-------\t------\t-----------")
+		if detailed {
+			fmt.Fprintln(w, "NAME\tVERSION\tAUTHOR\tLICENSE\tTAGS\tFEATURES\tDESCRIPTION")
+			fmt.Fprintln(w, "----\t-------\t------\t-------\t----\t--------\t-----------")
+		} else {
+			fmt.Fprintln(w, "NAME\tVERSION\tAUTHOR\tDESCRIPTION")
+			fmt.Fprintln(w, "----\t-------\t------\t-----------")
+		}
 
 		for _, theme := range themes {
 			active := ""
@@ -49,6 +93,12 @@ var themeListCmd = &cobra.Command{
 				description = description[:47] + "..."
 			}
 
+			if detailed {
+				fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					theme.Name, active, theme.Version, theme.Author, orDash(theme.License),
+					orDash(strings.Join(theme.Tags, ", ")), orDash(strings.Join(theme.Features, ", ")), description)
+				continue
+			}
 			fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\n",
 				theme.Name, active, theme.Version, theme.Author, description)
 		}
@@ -57,20 +107,96 @@ var themeListCmd = &cobra.Command{
 	},
 }
 
+var themeInfoCmd = &cobra.Command{
+	Use:   "info [name]",
+	Short: "Show a theme's metadata",
+	Long: `Show a theme's metadata from its theme.json: version, author, license,
+homepage, tags, features and screenshot.
+
+A theme whose theme.json names no screenshot uses screenshot.png at its
+root, which 'vango theme package' requires. Without a name, the site's
+configured theme is shown.`,
+	Example: `  vango theme info
+  vango theme info mytheme --format json`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeThemeNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		if outputFormat != "text" && outputFormat != "json" {
+			logging.Errorf("❌ Unknown format %q (use text or json)", outputFormat)
+			os.Exit(1)
+		}
+		cfg, _ := config.Load("config.toml")
+		themeManager := theme.NewThemeManager(cfg)
+		themeManager.LoadThemes()
+
+		name := cfg.Theme
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if name == "" {
+			logging.Errorf("❌ No theme given and none configured")
+			os.Exit(1)
+		}
+		t, ok := themeManager.ListThemes()[name]
+		if !ok {
+			logging.Errorf("❌ Theme '%s' not found", name)
+			os.Exit(1)
+		}
+
+		if outputFormat == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			enc.Encode(t)
+			return
+		}
+
+		fmt.Printf("🎨 Theme '%s' (%s)\n", t.Name, t.Path)
+		fmt.Printf("   Version:     %s\n", orDash(t.Version))
+		fmt.Printf("   Author:      %s\n", orDash(t.Author))
+		fmt.Printf("   Description: %s\n", orDash(t.Description))
+		fmt.Printf("   License:     %s\n", orDash(t.License))
+		fmt.Printf("   Homepage:    %s\n", orDash(t.Homepage))
+		fmt.Printf("   Min VanGo:   %s\n", orDash(t.MinVersion))
+		fmt.Printf("   Tags:        %s\n", orDash(strings.Join(t.Tags, ", ")))
+		fmt.Printf("   Features:    %s\n", orDash(strings.Join(t.Features, ", ")))
+		screenshot := filepath.Join(t.Path, filepath.FromSlash(t.Screenshot))
+		if t.Screenshot == "" {
+			fmt.Printf("   Screenshot:  - (add %s to the theme)\n", theme.ScreenshotFile)
+		} else if _, err := os.Stat(screenshot); err != nil {
+			fmt.Printf("   Screenshot:  %s (missing)\n", t.Screenshot)
+		} else {
+			fmt.Printf("   Screenshot:  %s\n", screenshot)
+		}
+	},
+}
+
 var themeInstallCmd = &cobra.Command{
-	Use:   "install [name]",
+	Use:   "install [name|archive]",
     Short: "Install a theme from the theme repository",
+    Long: `Install a theme from the theme repository, or from a local .tar.gz
+archive made by 'vango theme package'. An archive is checked against the
+.sha256 file next to it when there is one.`,
+    Example: `  vango theme install ./mytheme-1.0.0.tar.gz`,
     Args:  cobra.ExactArgs(1),
     Run: func(cmd *cobra.Command, args []string) {
         cfg, _ := config.Load("config.toml")
         themeManager := theme.NewThemeManager(cfg)
         
+        if isThemeArchive(args[0]) {
+            name, err := themeManager.InstallThemeArchive(args[0])
+            if err != nil {
+                logging.Errorf("❌ Failed to install theme: %v", err)
+                os.Exit(1)
+            }
+            logging.Infof("✅ Theme '%s' installed successfully!", name)
+            return
+        }
         if err := themeManager.InstallTheme(args[0]); err != nil {
-            fmt.Fprintf(os.Stderr, "❌ Failed to install theme: %v\n", err)
+            logging.Errorf("❌ Failed to install theme: %v", err)
             os.Exit(1)
         }
         
-        fmt.Printf("✅ Theme '%s' installed successfully!\n", args[0])
+        logging.Infof("✅ Theme '%s' installed successfully!", args[0])
     },
         
 }
@@ -79,19 +205,20 @@ var themeUseCmd = &cobra.Command{
 	Use:   "use [name]",
 	Short: "Set active theme",
 	Args:  cobra.ExactArgs(1),
+	ValidArgsFunction: completeThemeNames,
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg, _ := config.Load("config.toml")
 		themeManager := theme.NewThemeManager(cfg)
 		themeManager.LoadThemes()
 
 		if err := themeManager.SetActiveTheme(args[0]); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			logging.Errorf("Error: %v", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("Successfully set active theme to: %s\n", args[0])
-		fmt.Println("Don't forget to update your config.toml file with:")
-		fmt.Printf("theme = \"%s\"\n", args[0])
+		logging.Infof("Successfully set active theme to: %s", args[0])
+		logging.Infof("Don't forget to update your config.toml file with:")
+		logging.Infof("theme = \"%s\"\n", args[0])
 	},
 }
 
@@ -104,32 +231,200 @@ var themeCreateCmd = &cobra.Command{
 		cfg, _ := config.Load("config.toml")
 		themeManager := theme.NewThemeManager(cfg)
 
-		fmt.Printf("Creating theme '%s' with template '%s'\n", args[0], template)
+		logging.Infof("Creating theme '%s' with template '%s'", args[0], template)
 
 		if err := themeManager.CreateTheme(args[0], template); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			logging.Errorf("Error: %v", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("Theme '%s' created successfully!\n", args[0])
-		fmt.Printf("Theme files are located in: themes/%s/\n", args[0])
-		fmt.Println("")
-		fmt.Println("Next steps:")
-		fmt.Printf("1. Edit themes/%s/theme.json to customize theme metadata\n", args[0])
-		fmt.Printf("2. Modify templates in themes/%s/layouts/\n", args[0])
-		fmt.Printf("3. Add styles to themes/%s/static/css/style.css\n", args[0])
-		fmt.Printf("4. Use the theme with: vango theme use %s\n", args[0])
+		logging.Infof("Theme '%s' created successfully!", args[0])
+		logging.Infof("Theme files are located in: themes/%s/", args[0])
+		logging.Infof("Next steps:")
+		logging.Infof("1. Edit themes/%s/theme.json to customize theme metadata", args[0])
+		logging.Infof("2. Modify templates in themes/%s/layouts/", args[0])
+		logging.Infof("3. Add styles to themes/%s/static/css/style.css", args[0])
+		logging.Infof("4. Use the theme with: vango theme use %s", args[0])
 	},
 }
 
+var themeValidateCmd = &cobra.Command{
+	Use:   "validate [name]",
+	Short: "Validate a theme",
+	Long: `Validate a theme's templates, assets and metadata.
+
+Errors (missing required templates, templates that don't parse, unknown
+template functions, missing assets, an unmet min_vango_version) make the
+command exit with status 1. Warnings (features used but not declared in
+theme.json, unreferenced assets, templates expected from the site) are
+reported but don't fail validation.
+
+Without a name, the site's configured theme is validated.`,
+	Example: `  vango theme validate
+  vango theme validate mytheme --format json`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeThemeNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		cfg, _ := config.Load("config.toml")
+		themeManager := theme.NewThemeManager(cfg)
 
+		name := cfg.Theme
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if name == "" {
+			logging.Errorf("❌ No theme given and none configured")
+			os.Exit(1)
+		}
+
+		funcs := templateFuncNames(cfg)
+		report, err := themeManager.ValidateTheme(name, rootCmd.Version, funcs)
+		if err != nil {
+			logging.Errorf("❌ Failed to validate theme '%s': %v", name, err)
+			os.Exit(1)
+		}
+
+		switch format {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			enc.Encode(report)
+		case "text":
+			printThemeReport(report)
+		default:
+			logging.Errorf("❌ Unknown format %q (use text or json)", format)
+			os.Exit(1)
+		}
+
+		if !report.Valid() {
+			os.Exit(1)
+		}
+	},
+}
+
+var themePackageCmd = &cobra.Command{
+	Use:   "package [name]",
+	Short: "Package a theme for distribution",
+	Long: `Package a theme as <name>-<version>.tar.gz with a .sha256 checksum file.
+
+The theme must pass 'vango theme validate', have a description, license,
+version and a PNG or JPEG screenshot (theme.json's "screenshot", or
+screenshot.png at the theme's root), and render a set of sample
+pages without errors. Development files such as .git, node_modules and
+.DS_Store are left out of the archive.`,
+	Example: `  vango theme package mytheme
+  vango theme package mytheme --output dist`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeThemeNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		outDir, _ := cmd.Flags().GetString("output")
+		cfg, _ := config.Load("config.toml")
+		themeManager := theme.NewThemeManager(cfg)
+
+		funcs := templateFuncNames(cfg)
+		report, err := themeManager.ValidateTheme(name, rootCmd.Version, funcs)
+		if err != nil {
+			logging.Errorf("❌ Failed to validate theme '%s': %v", name, err)
+			os.Exit(1)
+		}
+		metadata, err := themeManager.CheckPackageMetadata(name)
+		if err != nil {
+			logging.Errorf("❌ Failed to read theme '%s': %v", name, err)
+			os.Exit(1)
+		}
+		report.Errors = append(report.Errors, metadata...)
+		if !report.Valid() {
+			printThemeReport(report)
+			os.Exit(1)
+		}
+		logging.Infof("✅ Theme '%s' is valid (%d warnings)", name, len(report.Warnings))
+
+		logging.Infof("🧪 Rendering sample pages...")
+		pages, err := builder.SampleTheme(report.Path)
+		if err != nil {
+			logging.Errorf("❌ Sample pages failed to render: %v", err)
+			os.Exit(1)
+		}
+		logging.Infof("✅ Rendered %d sample pages", pages)
+
+		archive, err := themeManager.PackageTheme(name, outDir)
+		if err != nil {
+			logging.Errorf("❌ Failed to package theme: %v", err)
+			os.Exit(1)
+		}
+		logging.Infof("📦 Packaged %s", archive)
+		logging.Infof("🔒 Checksum in %s.sha256", archive)
+	},
+}
+
+// orDash returns s, or "-" when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// isThemeArchive reports whether an install argument names a local archive
+func isThemeArchive(source string) bool {
+	if !strings.HasSuffix(source, ".tar.gz") && !strings.HasSuffix(source, ".tgz") {
+		return false
+	}
+	_, err := os.Stat(source)
+	return err == nil
+}
+
+// printThemeReport prints a theme validation report for humans
+// templateFuncNames lists the template functions themes may call, the
+// builder's own such as scss and socialImage included
+func templateFuncNames(cfg *config.Config) []string {
+	var names []string
+	for _, fn := range builder.New(cfg).TemplateFuncs() {
+		names = append(names, fn.Name)
+	}
+	return names
+}
+
+func printThemeReport(report *theme.ValidationReport) {
+	fmt.Printf("🎨 Theme '%s' (%s)\n", report.Theme, report.Path)
+	fmt.Printf("   Functions: %s\n", strings.Join(report.Functions, ", "))
+	fmt.Printf("   Features:  %s\n", strings.Join(report.Features, ", "))
+	fmt.Printf("   Templates: %s\n", strings.Join(report.Templates, ", "))
+	fmt.Printf("   Assets:    %s\n", strings.Join(report.Assets, ", "))
+	fmt.Println()
+
+	for _, issue := range report.Errors {
+		fmt.Printf("❌ %s: %s\n", issue.File, issue.Message)
+	}
+	for _, issue := range report.Warnings {
+		fmt.Printf("⚠️  %s: %s\n", issue.File, issue.Message)
+	}
+
+	if report.Valid() {
+		fmt.Printf("✅ Theme is valid (%d warnings)\n", len(report.Warnings))
+	} else {
+		fmt.Printf("❌ %d errors, %d warnings\n", len(report.Errors), len(report.Warnings))
+	}
+}
 
 func init() {
 	rootCmd.AddCommand(themeCmd)
 	themeCmd.AddCommand(themeListCmd)
+	themeCmd.AddCommand(themeInfoCmd)
 	themeCmd.AddCommand(themeInstallCmd)
 	themeCmd.AddCommand(themeUseCmd)
 	themeCmd.AddCommand(themeCreateCmd)
+	themeCmd.AddCommand(themeValidateCmd)
+	themeCmd.AddCommand(themePackageCmd)
 
+	themeListCmd.Flags().Bool("detailed", false, "Show tags, features and license")
+	themeListCmd.Flags().String("sort", "name", "Sort by name or author")
+	themeListCmd.Flags().StringSlice("tag", nil, "Only list themes with this tag (repeatable)")
+	themeListCmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions(theme.ThemeSorts, cobra.ShellCompDirectiveNoFileComp))
 	themeCreateCmd.Flags().StringP("template", "t", "basic", "Theme template to use (basic, blog, portfolio, docs)")
+	themeValidateCmd.Flags().String("format", "text", "Output format (text, json)")
+	themeValidateCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	themePackageCmd.Flags().StringP("output", "o", ".", "Directory to write the archive to")
 }
@@ -0,0 +1,96 @@
+package vango
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"vango/internal/builder"
+	"vango/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+var tplCmd = &cobra.Command{
+	Use:   "tpl",
+	Short: "Inspect and try out template functions",
+}
+
+var tplListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the template functions with their signatures",
+	Example: `  vango tpl list
+  vango tpl list --format json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			logging.Errorf("❌ Error loading config: %v", err)
+			os.Exit(1)
+		}
+		funcs := builder.New(cfg).TemplateFuncs()
+
+		switch outputFormat {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			enc.Encode(funcs)
+		case "text":
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, fn := range funcs {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", fn.Name, fn.Signature, fn.Description)
+			}
+			w.Flush()
+		default:
+			logging.Errorf("❌ Unknown format %q (use text or json)", outputFormat)
+			os.Exit(1)
+		}
+	},
+}
+
+var tplExecCmd = &cobra.Command{
+	Use:   "exec [template]",
+	Short: "Execute a template snippet and print the output",
+	Long: `Execute a template snippet with the site's functions and templates and
+print the output. The snippet sees .Site and .Page as page templates do;
+.Page is empty unless --page names a content file.`,
+	Example: `  vango tpl exec '{{ slugify "Hello World" }}'
+  vango tpl exec '{{ .Page.Title }} ({{ .Page.ReadingTime }} min)' --page content/posts/foo.md
+  vango tpl exec '{{ template "partials/header" . }}'`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		page, _ := cmd.Flags().GetString("page")
+
+		cfg, err := loadConfig()
+		if err != nil {
+			logging.Errorf("❌ Error loading config: %v", err)
+			os.Exit(1)
+		}
+		b := builder.New(cfg)
+		if err := b.PrepareTemplates(); err != nil {
+			logging.Errorf("❌ %v", err)
+			os.Exit(1)
+		}
+
+		output, err := b.ExecTemplate(args[0], page)
+		if err != nil {
+			logging.Errorf("❌ %v", err)
+			os.Exit(1)
+		}
+		fmt.Print(output)
+		if !strings.HasSuffix(output, "\n") {
+			fmt.Println()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tplCmd)
+	tplCmd.AddCommand(tplListCmd)
+	tplCmd.AddCommand(tplExecCmd)
+
+	tplExecCmd.Flags().String("page", "", "Content file to expose as .Page")
+	tplExecCmd.RegisterFlagCompletionFunc("page", completeContentFiles)
+}
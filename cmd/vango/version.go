@@ -0,0 +1,107 @@
+package vango
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"vango/internal/logging"
+	"vango/internal/version"
+
+	"github.com/spf13/cobra"
+)
+
+var checkUpdate bool
+
+// updateCheckTimeout bounds the release lookup so an offline machine
+// doesn't hang the command
+const updateCheckTimeout = 3 * time.Second
+
+// versionReport is the JSON output of vango version
+type versionReport struct {
+	version.Info
+	Latest          string `json:"latest,omitempty"`
+	UpdateAvailable bool   `json:"update_available,omitempty"`
+	ReleaseURL      string `json:"release_url,omitempty"`
+	UpdateError     string `json:"update_error,omitempty"`
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show version information",
+	Long: `Show the VanGo version, the commit and date it was built from, the Go
+version and the platform.
+
+--check-update asks GitHub for the latest release and says how to upgrade
+if there is a newer one; VanGo never updates itself. Setting
+VANGO_NO_UPDATE_CHECK turns the check off, e.g. in CI.`,
+	Example: `  vango version
+  vango version --check-update
+  vango version --format json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if outputFormat != "text" && outputFormat != "json" {
+			logging.Errorf("❌ Unknown format %q (use text or json)", outputFormat)
+			os.Exit(1)
+		}
+		report := versionReport{Info: version.Get()}
+		var skipped bool
+		if checkUpdate {
+			if os.Getenv(version.NoUpdateCheckEnv) != "" {
+				skipped = true
+			} else if release, err := version.Latest(version.ReleasesURL, updateCheckTimeout); err != nil {
+				report.UpdateError = err.Error()
+			} else {
+				report.Latest = release.Tag
+				report.ReleaseURL = release.URL
+				report.UpdateAvailable = version.Compare(report.Version, release.Tag) < 0
+			}
+		}
+
+		if outputFormat == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			enc.Encode(report)
+			return
+		}
+		showVersion(report.Info)
+		switch {
+		case !checkUpdate:
+		case skipped:
+			fmt.Printf("⏭️  Update check skipped (%s is set)\n", version.NoUpdateCheckEnv)
+		case report.UpdateError != "":
+			logging.Warnf("⚠️  Couldn't check for updates: %s", report.UpdateError)
+		case report.UpdateAvailable:
+			fmt.Printf("⬆️  VanGo %s is available (you have v%s)\n", report.Latest, report.Version)
+			fmt.Printf("   Release notes: %s\n", report.ReleaseURL)
+			fmt.Printf("   Upgrade with: go install github.com/vango/vango@%s\n", report.Latest)
+			fmt.Printf("   or download a binary from the release page\n")
+		default:
+			fmt.Printf("✅ VanGo v%s is the latest release\n", report.Version)
+		}
+	},
+}
+
+// showVersion prints the binary's version details
+func showVersion(info version.Info) {
+	fmt.Printf("VanGo v%s\n", info.Version)
+	if info.Commit != "" {
+		commit := info.Commit
+		if info.Modified {
+			commit += " (modified)"
+		}
+		fmt.Printf("   Commit:   %s\n", commit)
+	}
+	if info.Date != "" {
+		fmt.Printf("   Built:    %s\n", info.Date)
+	}
+	fmt.Printf("   Go:       %s\n", info.GoVersion)
+	fmt.Printf("   Platform: %s\n", info.Platform)
+	fmt.Printf("https://github.com/vango/vango\n")
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().BoolVar(&checkUpdate, "check-update", false, "Check GitHub for a newer release")
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"vango/internal/config"
+	vangoTemplate "vango/internal/template"
 )
 
 // Example of how to extend VanGo with custom template functions
@@ -132,15 +133,16 @@ func CustomFunctions() template.FuncMap {
 	}
 }
 
-// Example of how to create a custom template engine with additional functions
-//func NewCustomEngine(cfg *config.Config) *vangoTemplate.Engine {
-	//engine := vangoTemplate.NewEngine(cfg)
-	
-	// In a real implementation, you would need to modify the template engine
-	// to accept custom functions. This is just an example structure.
-	
-	//return engine
-//}
+// NewCustomEngine creates a template engine with the custom functions added
+// to the baseline ones. Without a theme manager the theme functions return
+// neutral values.
+func NewCustomEngine(cfg *config.Config) *vangoTemplate.Engine {
+	engine := vangoTemplate.NewEngine(cfg, nil)
+	for name, fn := range CustomFunctions() {
+		engine.SetFunc(name, fn)
+	}
+	return engine
+}
 
 // Example usage in a custom build script
 func ExampleCustomBuild() {
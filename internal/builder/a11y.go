@@ -0,0 +1,230 @@
+package builder
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"vango/internal/content"
+	"vango/internal/theme"
+	"vango/internal/util"
+)
+
+// Accessibility rules reported by CheckAccessibility
+const (
+	RuleHTMLLang    = "html-lang"
+	RuleImageAlt    = "image-alt"
+	RuleEmptyLink   = "empty-link"
+	RuleEmptyButton = "empty-button"
+	RuleDuplicateID = "duplicate-id"
+)
+
+var (
+	// commentPattern matches HTML comments, which may hold markup that is
+	// never rendered
+	commentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// stylePattern matches inline style sheets
+	stylePattern = regexp.MustCompile(`(?is)<style\b[^>]*>.*?</style>`)
+	// htmlTagPattern matches the document's opening html tag
+	htmlTagPattern = regexp.MustCompile(`(?is)<html\b([^>]*)>`)
+	// imgTagPattern matches img tags
+	imgTagPattern = regexp.MustCompile(`(?is)<img\b([^>]*)>`)
+	// controlPattern matches links and buttons with their content
+	controlPattern = regexp.MustCompile(`(?is)<(a|button)\b([^>]*)>(.*?)</(?:a|button)\s*>`)
+	// anyTagPattern matches any tag, to strip markup from element content
+	anyTagPattern = regexp.MustCompile(`(?s)<[^>]*>`)
+	// attrPattern matches an attribute and its value, quoted or not
+	attrPattern = regexp.MustCompile(`(?s)([^\s"'>/=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+)))?`)
+	// startTagPattern matches start tags with their attributes
+	startTagPattern = regexp.MustCompile(`(?s)<[a-zA-Z][a-zA-Z0-9-]*([^>]*)>`)
+)
+
+// A11yIssue is an accessibility problem found in a generated page
+type A11yIssue struct {
+	Page    string `json:"page"`             // HTML file, relative to the output directory
+	Source  string `json:"source,omitempty"` // content file the markup came from, when known
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+
+	element string // offending markup, to trace it back to page content
+}
+
+// CheckAccessibility audits the HTML files under dir for images without
+// alt text, links and buttons without an accessible name, a missing html
+// lang attribute and ids used more than once in a page
+func CheckAccessibility(dir string) ([]A11yIssue, error) {
+	var issues []A11yIssue
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(filePath) != ".html" {
+			return nil
+		}
+		relPath, err := util.RelSlashPath(dir, filePath)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, auditPage(relPath, string(data))...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Page < issues[j].Page
+	})
+	return issues, nil
+}
+
+// auditPage runs the accessibility rules on a single page
+func auditPage(page, doc string) []A11yIssue {
+	doc = commentPattern.ReplaceAllString(doc, "")
+	doc = scriptPattern.ReplaceAllString(doc, "")
+	doc = stylePattern.ReplaceAllString(doc, "")
+
+	var issues []A11yIssue
+	add := func(rule, element, format string, args ...interface{}) {
+		issues = append(issues, A11yIssue{Page: page, Rule: rule, Message: fmt.Sprintf(format, args...), element: element})
+	}
+
+	// Redirect stubs and fragments have no html element to check
+	if match := htmlTagPattern.FindStringSubmatch(doc); match != nil {
+		if lang, _ := htmlAttr(match[1], "lang"); strings.TrimSpace(lang) == "" {
+			add(RuleHTMLLang, match[0], "<html> has no lang attribute")
+		}
+	}
+
+	for _, match := range imgTagPattern.FindAllStringSubmatch(doc, -1) {
+		if _, ok := htmlAttr(match[1], "alt"); !ok && !ariaHidden(match[1]) {
+			src, _ := htmlAttr(match[1], "src")
+			add(RuleImageAlt, match[0], "image %q has no alt attribute (use alt=\"\" for decorative images)", src)
+		}
+	}
+
+	for _, match := range controlPattern.FindAllStringSubmatch(doc, -1) {
+		tag, attrs, inner := strings.ToLower(match[1]), match[2], match[3]
+		if tag == "a" {
+			if _, ok := htmlAttr(attrs, "href"); !ok {
+				// Anchors without href are targets, not links
+				continue
+			}
+		}
+		if ariaHidden(attrs) || hasAccessibleName(attrs, inner) {
+			continue
+		}
+		if tag == "a" {
+			href, _ := htmlAttr(attrs, "href")
+			add(RuleEmptyLink, match[0], "link to %q has no text or aria-label", href)
+		} else {
+			add(RuleEmptyButton, match[0], "button has no text or aria-label")
+		}
+	}
+
+	counts := make(map[string]int)
+	var order []string
+	for _, match := range startTagPattern.FindAllStringSubmatch(doc, -1) {
+		id, _ := htmlAttr(match[1], "id")
+		if id == "" {
+			continue
+		}
+		if counts[id] == 0 {
+			order = append(order, id)
+		}
+		counts[id]++
+	}
+	for _, id := range order {
+		if counts[id] > 1 {
+			add(RuleDuplicateID, fmt.Sprintf("id=%q", id), "id %q is used %d times", id, counts[id])
+		}
+	}
+	return issues
+}
+
+// hasAccessibleName reports whether a link or button has text, a label or
+// an image with alt text that screen readers can announce
+func hasAccessibleName(attrs, inner string) bool {
+	for _, name := range []string{"aria-label", "aria-labelledby", "title"} {
+		if value, _ := htmlAttr(attrs, name); strings.TrimSpace(value) != "" {
+			return true
+		}
+	}
+	for _, match := range imgTagPattern.FindAllStringSubmatch(inner, -1) {
+		if alt, _ := htmlAttr(match[1], "alt"); strings.TrimSpace(alt) != "" {
+			return true
+		}
+	}
+	text := html.UnescapeString(anyTagPattern.ReplaceAllString(inner, ""))
+	return strings.TrimSpace(text) != ""
+}
+
+// ariaHidden reports whether an element is hidden from assistive technology
+func ariaHidden(attrs string) bool {
+	value, _ := htmlAttr(attrs, "aria-hidden")
+	return strings.EqualFold(strings.TrimSpace(value), "true")
+}
+
+// htmlAttr returns the value of an attribute in a tag's attribute text and
+// whether it's present at all
+func htmlAttr(attrs, name string) (string, bool) {
+	for _, match := range attrPattern.FindAllStringSubmatch(attrs, -1) {
+		if strings.EqualFold(match[1], name) {
+			return html.UnescapeString(match[2] + match[3] + match[4]), true
+		}
+	}
+	return "", false
+}
+
+// CheckAccessibility audits the output of the last build and attributes
+// issues whose markup comes from a page's content to its content file
+func (b *Builder) CheckAccessibility() ([]A11yIssue, error) {
+	issues, err := CheckAccessibility(b.config.PublicDir)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make(map[string]*content.Page)
+	for _, page := range b.GetPages() {
+		pages[path.Join(page.Slug, "index.html")] = page
+	}
+	for i := range issues {
+		page, ok := pages[issues[i].Page]
+		if !ok || issues[i].element == "" {
+			continue
+		}
+		if strings.Contains(string(page.Content), issues[i].element) || pageHasImage(page, issues[i]) {
+			issues[i].Source = page.FilePath
+		}
+	}
+	return issues, nil
+}
+
+// CheckContrast reports theme color pairs with too little contrast
+func (b *Builder) CheckContrast() ([]theme.ValidationIssue, error) {
+	return b.themeManager.CheckContrast()
+}
+
+// pageHasImage reports whether an image-alt issue is about one of the
+// images the page's content references
+func pageHasImage(page *content.Page, issue A11yIssue) bool {
+	if issue.Rule != RuleImageAlt {
+		return false
+	}
+	src, _ := htmlAttr(strings.TrimSuffix(strings.TrimPrefix(issue.element, "<img"), ">"), "src")
+	for _, image := range page.Images {
+		if image.Src == src {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package builder
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"net/url"
+	"strings"
+	texttemplate "text/template"
+)
+
+// doNotTrackLoader runs the scripts in the <template> before it unless the
+// browser sends Do Not Track. Scripts cloned from a template don't run, so
+// each is recreated.
+const doNotTrackLoader = `<script>(function(){` +
+	`if(navigator.doNotTrack==="1"||window.doNotTrack==="1"||navigator.msDoNotTrack==="1")return;` +
+	`var t=document.currentScript.previousElementSibling;` +
+	`t.content.querySelectorAll("script").forEach(function(s){` +
+	`var n=document.createElement("script");` +
+	`for(var i=0;i<s.attributes.length;i++)n.setAttribute(s.attributes[i].name,s.attributes[i].value);` +
+	`n.text=s.text;document.head.appendChild(n);});` +
+	`})();</script>`
+
+// analyticsSnippet is the "analyticsSnippet" template function: the script
+// tags of the configured analytics provider, or nothing when analytics is
+// off for this build
+func (b *Builder) analyticsSnippet() (template.HTML, error) {
+	if !b.config.AnalyticsEnabled() {
+		return "", nil
+	}
+	snippet, err := b.providerSnippet()
+	if err != nil {
+		return "", err
+	}
+	if b.config.Analytics.RespectDoNotTrack {
+		snippet = "<template data-vango-analytics>" + snippet + "</template>" + doNotTrackLoader
+	}
+	return template.HTML(snippet), nil
+}
+
+// providerSnippet returns the provider's script tags
+func (b *Builder) providerSnippet() (string, error) {
+	cfg := b.config.Analytics
+	attr := html.EscapeString
+	switch cfg.Provider {
+	case "plausible":
+		domain := cfg.SiteID
+		if domain == "" {
+			if u, err := url.Parse(b.config.BaseURL); err == nil {
+				domain = u.Hostname()
+			}
+		}
+		src := withDefault(cfg.URL, "https://plausible.io/js/script.js")
+		return fmt.Sprintf(`<script defer data-domain="%s" src="%s"></script>`, attr(domain), attr(src)), nil
+	case "goatcounter":
+		endpoint := withDefault(cfg.URL, "https://"+cfg.SiteID+".goatcounter.com/count")
+		return fmt.Sprintf(`<script data-goatcounter="%s" async src="//gc.zgo.at/count.js"></script>`, attr(endpoint)), nil
+	case "umami":
+		src := withDefault(cfg.URL, "https://cloud.umami.is/script.js")
+		return fmt.Sprintf(`<script defer src="%s" data-website-id="%s"></script>`, attr(src), attr(cfg.SiteID)), nil
+	case "gtag":
+		src := withDefault(cfg.URL, "https://www.googletagmanager.com/gtag/js") + "?id=" + url.QueryEscape(cfg.SiteID)
+		id := texttemplate.JSEscapeString(cfg.SiteID)
+		return fmt.Sprintf(`<script async src="%s"></script>`+
+			`<script>window.dataLayer=window.dataLayer||[];function gtag(){dataLayer.push(arguments);}gtag("js",new Date());gtag("config","%s");</script>`,
+			attr(src), id), nil
+	case "custom":
+		tmpl, err := texttemplate.New("analytics").Parse(cfg.Template)
+		if err != nil {
+			return "", fmt.Errorf("analytics.template: %w", err)
+		}
+		var out strings.Builder
+		if err := tmpl.Execute(&out, struct{ SiteID, URL string }{cfg.SiteID, cfg.URL}); err != nil {
+			return "", fmt.Errorf("analytics.template: %w", err)
+		}
+		return out.String(), nil
+	}
+	return "", fmt.Errorf("unknown analytics provider %q", cfg.Provider)
+}
+
+// withDefault returns s, or fallback when s is empty
+func withDefault(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// injectAnalytics adds the analytics snippet before </body>, where live
+// reload goes, unless analytics.inject is off or the templates already
+// placed it with analyticsSnippet
+func (b *Builder) injectAnalytics(page string) (string, error) {
+	if !b.config.Analytics.Inject || !strings.Contains(page, "</body>") {
+		return page, nil
+	}
+	snippet, err := b.analyticsSnippet()
+	if err != nil || snippet == "" || strings.Contains(page, string(snippet)) {
+		return page, err
+	}
+	return strings.Replace(page, "</body>", string(snippet)+"\n</body>", 1), nil
+}
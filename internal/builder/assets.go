@@ -0,0 +1,96 @@
+package builder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"vango/internal/content"
+	"vango/internal/util"
+)
+
+// resetAssetFiles forgets the asset files referenced so far, so a full
+// build only publishes those its pages still use
+func (b *Builder) resetAssetFiles() {
+	b.assetFilesMu.Lock()
+	b.assetFiles = make(map[string]*content.Resource)
+	b.assetFilesMu.Unlock()
+}
+
+// assetResource is the "resource" template function. It looks name up in
+// the asset directories, site first, and registers the file to be published
+// at the same path, fingerprinted when fingerprinting is enabled.
+func (b *Builder) assetResource(name string) (*content.Resource, error) {
+	name = strings.TrimPrefix(util.SlashPath(name), "/")
+
+	b.assetFilesMu.Lock()
+	defer b.assetFilesMu.Unlock()
+	if resource, ok := b.assetFiles[name]; ok {
+		return resource, nil
+	}
+
+	dirs := b.assetDirs()
+	if name == "" || path.Clean(name) == ".." || strings.HasPrefix(path.Clean(name), "../") {
+		return nil, fmt.Errorf("resource: invalid file name %q", name)
+	}
+	var src string
+	for i := len(dirs) - 1; i >= 0; i-- {
+		candidate := util.OutputPath(dirs[i], name)
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() && util.IsWithin(candidate, dirs[i]) {
+			src = candidate
+			break
+		}
+	}
+	if src == "" {
+		return nil, fmt.Errorf("resource: file %q not found in %s", name, strings.Join(dirs, ", "))
+	}
+
+	out := name
+	if b.config.Performance.AssetBundling.Fingerprinting {
+		sum, err := fileHash(src)
+		if err != nil {
+			return nil, fmt.Errorf("resource: %w", err)
+		}
+		ext := path.Ext(name)
+		out = strings.TrimSuffix(name, ext) + "." + sum[:8] + ext
+	}
+
+	resource := content.NewResource(out, src)
+	resource.RelPermalink = b.config.RelURL("/" + out)
+	resource.Permalink = b.config.AbsURL("/" + out)
+	resource.PublishPath = out
+	resource.SetImageProcessor(b.images)
+	b.assetFiles[name] = resource
+	return resource, nil
+}
+
+// isReferencedAsset reports whether file is an asset file a page references
+func (b *Builder) isReferencedAsset(file string) bool {
+	b.assetFilesMu.Lock()
+	defer b.assetFilesMu.Unlock()
+
+	for _, resource := range b.assetFiles {
+		if absPath(resource.SourcePath) == absPath(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileHash returns the hex SHA-256 of a file's content
+func fileHash(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,452 @@
+package builder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"vango/internal/content"
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// themeVariablesFile is where the theme's generated CSS variables are
+// published, unless the theme ships a file of that name itself
+const themeVariablesFile = "theme/variables.css"
+
+// AssetFile is a file published by the asset stage
+type AssetFile struct {
+	Path   string `json:"path"`   // Relative to the output directory
+	Source string `json:"source"` // Source file, or a description of generated content
+	Size   int64  `json:"size"`
+	Hash   string `json:"hash"` // Hex SHA-256 of the content
+}
+
+// AssetManifest lists the files an asset stage published, sorted by path
+type AssetManifest struct {
+	Files []AssetFile `json:"files"`
+}
+
+// Get returns the manifest entry for an output path, or nil
+func (m *AssetManifest) Get(relPath string) *AssetFile {
+	if m == nil {
+		return nil
+	}
+	relPath = util.SlashPath(relPath)
+	i := sort.Search(len(m.Files), func(i int) bool { return m.Files[i].Path >= relPath })
+	if i < len(m.Files) && m.Files[i].Path == relPath {
+		return &m.Files[i]
+	}
+	return nil
+}
+
+// TotalSize returns the combined size of the published files
+func (m *AssetManifest) TotalSize() int64 {
+	if m == nil {
+		return 0
+	}
+	var total int64
+	for _, file := range m.Files {
+		total += file.Size
+	}
+	return total
+}
+
+// assetSource is a file for the asset stage to publish: a copy of a source
+// file, or generated data
+type assetSource struct {
+	dst  string // Output path, relative to the output directory
+	src  string // Source file
+	data []byte // Generated content, when there's no source file
+	desc string // Names generated content in the manifest and errors
+}
+
+// origin names where the asset comes from
+func (s assetSource) origin() string {
+	if s.src != "" {
+		return s.src
+	}
+	return s.desc
+}
+
+// queueResources hands a rendered page's bundle files to the asset stage,
+// which publishes them next to the page. Markdown resources are only
+// exposed to templates.
+func (b *Builder) queueResources(page *content.Page) {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+	for _, resource := range page.Resources {
+		if resource.ResourceType == "page" {
+			continue
+		}
+		if b.pendingResources == nil {
+			b.pendingResources = make(map[string]*content.Resource)
+		}
+		b.pendingResources[resource.PublishPath] = resource
+	}
+}
+
+// resetPendingResources forgets bundle files queued by an earlier build
+func (b *Builder) resetPendingResources() {
+	b.pendingMu.Lock()
+	b.pendingResources = nil
+	b.pendingMu.Unlock()
+}
+
+// staticSources lists the site's and the active theme's static files, and
+// the rootFiles published at the site root as well
+func (b *Builder) staticSources() ([]assetSource, error) {
+	sources, err := treeSources(b.config.StaticDir, b.config.StaticPrefix())
+	if err != nil {
+		return nil, err
+	}
+	if !b.config.StaticAtRoot {
+		for _, name := range b.config.RootFiles {
+			name = path.Clean(util.SlashPath(name))
+			if src := filepath.Join(b.config.StaticDir, filepath.FromSlash(name)); isFile(src) {
+				sources = append(sources, assetSource{dst: name, src: src})
+			}
+		}
+	}
+	if b.themeManager.GetActiveTheme() != nil {
+		themeSources, err := treeSources(b.themeManager.GetThemeStaticPath(), "theme")
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, themeSources...)
+	}
+	return sources, nil
+}
+
+// staticRoot is a directory of static files and the output path they are
+// published under
+type staticRoot struct {
+	dir, prefix string
+}
+
+// staticRoots lists the site's static directory and the active theme's
+func (b *Builder) staticRoots() []staticRoot {
+	roots := []staticRoot{{b.config.StaticDir, b.config.StaticPrefix()}}
+	if b.themeManager.GetActiveTheme() != nil {
+		roots = append(roots, staticRoot{b.themeManager.GetThemeStaticPath(), "theme"})
+	}
+	return roots
+}
+
+// copyStaticFile publishes a single changed file of the site's or the
+// theme's static directory, without walking the rest, or removes its
+// published copies when it no longer exists. A new directory is published
+// with everything in it.
+func (b *Builder) copyStaticFile(file string) error {
+	for i, root := range b.staticRoots() {
+		if !inDir(root.dir, file) {
+			continue
+		}
+		rel, err := util.RelSlashPath(absPath(root.dir), absPath(file))
+		if err != nil {
+			return err
+		}
+		outs := []string{path.Join(root.prefix, rel)}
+		if i == 0 && !b.config.StaticAtRoot && b.isRootFile(rel) {
+			outs = append(outs, rel)
+		}
+
+		info, err := os.Stat(file)
+		if os.IsNotExist(err) {
+			for _, out := range outs {
+				if err := b.removeOutput(out); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var sources []assetSource
+		for _, out := range outs {
+			if !info.IsDir() {
+				sources = append(sources, assetSource{dst: out, src: file})
+				continue
+			}
+			tree, err := treeSources(file, out)
+			if err != nil {
+				return err
+			}
+			sources = append(sources, tree...)
+		}
+		// Files published before already passed the collision check
+		var fresh []assetSource
+		for _, source := range sources {
+			if !b.hasOutput(source.dst) {
+				fresh = append(fresh, source)
+			}
+		}
+		if err := b.checkPageCollisions(fresh); err != nil {
+			return err
+		}
+		_, err = b.runAssetStage(sources)
+		return err
+	}
+	return nil
+}
+
+// isRootFile reports whether rel, relative to the static directory, is one
+// of the rootFiles also published at the site root
+func (b *Builder) isRootFile(rel string) bool {
+	for _, name := range b.config.RootFiles {
+		if path.Clean(util.SlashPath(name)) == rel {
+			return true
+		}
+	}
+	return false
+}
+
+// treeSources lists every file under dir, published below prefix
+func treeSources(dir, prefix string) ([]assetSource, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		logging.Debugf("Directory %s does not exist, skipping", dir)
+		return nil, nil
+	}
+	var sources []assetSource
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, assetSource{dst: path.Join(prefix, util.SlashPath(rel)), src: p})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	return sources, nil
+}
+
+// checkPageCollisions fails when a static file would overwrite a page or
+// another file the build generated before the asset stage
+func (b *Builder) checkPageCollisions(sources []assetSource) error {
+	b.outputsMu.Lock()
+	generated := make(map[string]bool, len(b.outputs))
+	for out := range b.outputs {
+		generated[out] = true
+	}
+	b.outputsMu.Unlock()
+	for _, page := range b.renderedPages() {
+		generated[path.Join(page.Slug, "index.html")] = true
+	}
+
+	var conflicts []string
+	for _, source := range sources {
+		if generated[source.dst] {
+			conflicts = append(conflicts, fmt.Sprintf("%s from %s would overwrite a generated page", source.dst, source.origin()))
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("conflicting static files: %s", strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// themeCSSSource returns the active theme's CSS variables as a stylesheet,
+// unless the theme ships one of that name
+func (b *Builder) themeCSSSource() ([]assetSource, error) {
+	if b.themeManager.GetActiveTheme() == nil {
+		return nil, nil
+	}
+	shipped := filepath.Join(b.themeManager.GetThemeStaticPath(), strings.TrimPrefix(themeVariablesFile, "theme/"))
+	if _, err := os.Stat(shipped); err == nil {
+		return nil, nil
+	}
+	css, err := b.themeManager.GenerateThemeCSS()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate theme CSS: %w", err)
+	}
+	return []assetSource{{dst: themeVariablesFile, data: []byte(css), desc: "theme CSS variables"}}, nil
+}
+
+// referencedSources lists the asset files pages reference and the bundle
+// files of the pages rendered since the last asset stage
+func (b *Builder) referencedSources() []assetSource {
+	var sources []assetSource
+	b.assetFilesMu.Lock()
+	for _, resource := range b.assetFiles {
+		sources = append(sources, assetSource{dst: resource.PublishPath, src: resource.SourcePath})
+	}
+	b.assetFilesMu.Unlock()
+
+	b.pendingMu.Lock()
+	for _, resource := range b.pendingResources {
+		sources = append(sources, assetSource{dst: resource.PublishPath, src: resource.SourcePath})
+	}
+	b.pendingResources = nil
+	b.pendingMu.Unlock()
+	return sources
+}
+
+// publishAssets runs the asset stage for a full build: the static files,
+// the theme's CSS variables, the favicons and every file the pages
+// reference
+func (b *Builder) publishAssets() (*AssetManifest, error) {
+	sources, err := b.staticSources()
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkPageCollisions(sources); err != nil {
+		return nil, err
+	}
+	themeCSS, err := b.themeCSSSource()
+	if err != nil {
+		return nil, err
+	}
+	sources = append(sources, themeCSS...)
+	favicons, err := b.faviconSources()
+	if err != nil {
+		return nil, err
+	}
+	sources = append(sources, favicons...)
+	sources = append(sources, b.referencedSources()...)
+	return b.runAssetStage(sources)
+}
+
+// publishReferenced runs the asset stage for the files pages referenced
+// since the last one
+func (b *Builder) publishReferenced() error {
+	_, err := b.runAssetStage(b.referencedSources())
+	return err
+}
+
+// runAssetStage publishes sources with a pool of workers and returns what
+// was written. Two different sources for one output path are an error,
+// reported before anything is written.
+func (b *Builder) runAssetStage(sources []assetSource) (*AssetManifest, error) {
+	b.stageMu.Lock()
+	defer b.stageMu.Unlock()
+
+	sources, err := dedupeAssetSources(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &AssetManifest{Files: make([]AssetFile, len(sources))}
+	jobs := make(chan int)
+	errs := make([]error, len(sources))
+	var wg sync.WaitGroup
+	for w := 0; w < min(b.workers, len(sources)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				manifest.Files[i], errs[i] = b.writeAsset(sources[i])
+			}
+		}()
+	}
+	for i := range sources {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	for _, file := range manifest.Files {
+		b.recordOutput(file.Path)
+	}
+	logging.Debugf("📦 Published %d asset files (%d bytes)", len(manifest.Files), manifest.TotalSize())
+	return manifest, nil
+}
+
+// dedupeAssetSources sorts sources by output path, dropping repeats of the
+// same source and failing on output paths claimed by different ones
+func dedupeAssetSources(sources []assetSource) ([]assetSource, error) {
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].dst < sources[j].dst })
+
+	var unique []assetSource
+	var conflicts []string
+	for _, source := range sources {
+		if n := len(unique); n > 0 && unique[n-1].dst == source.dst {
+			if last := unique[n-1]; absPath(last.origin()) != absPath(source.origin()) {
+				conflicts = append(conflicts, fmt.Sprintf("%s is published from both %s and %s", source.dst, last.origin(), source.origin()))
+			}
+			continue
+		}
+		unique = append(unique, source)
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("conflicting asset files: %s", strings.Join(conflicts, "; "))
+	}
+	return unique, nil
+}
+
+// writeAsset writes one asset to the output directory, hashing it on the way
+func (b *Builder) writeAsset(source assetSource) (AssetFile, error) {
+	file := AssetFile{Path: source.dst, Source: source.origin()}
+	dst := util.OutputPath(b.outputDir, source.dst)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return file, fmt.Errorf("failed to create directory for %s: %w", source.dst, err)
+	}
+
+	hash := sha256.New()
+	var err error
+	if source.src == "" {
+		err = writeHashed(dst, hash, source.data)
+	} else {
+		err = copyHashed(source.src, dst, hash)
+	}
+	if err != nil {
+		return file, fmt.Errorf("failed to publish %s: %w", source.origin(), err)
+	}
+
+	info, err := os.Stat(dst)
+	if err != nil {
+		return file, err
+	}
+	file.Size = info.Size()
+	file.Hash = hex.EncodeToString(hash.Sum(nil))
+	return file, nil
+}
+
+// writeHashed writes generated data to dst
+func writeHashed(dst string, hash hash.Hash, data []byte) error {
+	hash.Write(data)
+	return os.WriteFile(dst, data, 0644)
+}
+
+// copyHashed copies src to dst with its permissions
+func copyHashed(src, dst string, hash hash.Hash) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if _, err := io.Copy(io.MultiWriter(out, hash), in); err != nil {
+		return err
+	}
+	return out.Chmod(info.Mode())
+}
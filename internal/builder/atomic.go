@@ -0,0 +1,149 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"vango/internal/util"
+)
+
+// createStagingDir creates the public.tmp-<timestamp> directory a clean build
+// renders into. It lives next to the public directory so the final rename
+// stays on the same filesystem.
+func (b *Builder) createStagingDir() (string, error) {
+	publicDir := filepath.Clean(b.config.PublicDir)
+	staging := fmt.Sprintf("%s.tmp-%d", publicDir, time.Now().UnixNano())
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return "", err
+	}
+	return staging, nil
+}
+
+// swapOutput moves a finished staging directory into place as the public
+// directory. The previous output is kept as public.prev-<timestamp> when
+// KeepPrevious is set and removed otherwise.
+func (b *Builder) swapOutput(staging string) error {
+	publicDir := filepath.Clean(b.config.PublicDir)
+
+	if _, err := os.Stat(publicDir); os.IsNotExist(err) {
+		return os.Rename(staging, publicDir)
+	}
+
+	previous := fmt.Sprintf("%s.prev-%d", publicDir, time.Now().UnixNano())
+	if err := os.Rename(publicDir, previous); err != nil {
+		// The public directory can't be moved (mount point, webroot owned by
+		// another process, ...), so replace its contents instead
+		if b.config.KeepPrevious > 0 {
+			if err := b.copyTree(publicDir, previous); err != nil {
+				return fmt.Errorf("failed to keep previous output: %w", err)
+			}
+		}
+		if err := b.replaceContents(publicDir, staging); err != nil {
+			return err
+		}
+		return b.pruneOldOutputs()
+	}
+
+	if err := os.Rename(staging, publicDir); err != nil {
+		// Put the previous output back so the site is never left missing
+		if restoreErr := os.Rename(previous, publicDir); restoreErr != nil {
+			return fmt.Errorf("failed to swap output (%v) and to restore previous output: %w", err, restoreErr)
+		}
+		return err
+	}
+
+	if b.config.KeepPrevious <= 0 {
+		return os.RemoveAll(previous)
+	}
+	return b.pruneOldOutputs()
+}
+
+// pruneOldOutputs removes retained build outputs beyond KeepPrevious
+func (b *Builder) pruneOldOutputs() error {
+	previous, err := b.PreviousOutputs()
+	if err != nil {
+		return err
+	}
+	keep := b.config.KeepPrevious
+	if keep < 0 {
+		keep = 0
+	}
+	for i := keep; i < len(previous); i++ {
+		if err := os.RemoveAll(previous[i]); err != nil {
+			return fmt.Errorf("failed to remove old output %s: %w", previous[i], err)
+		}
+	}
+	return nil
+}
+
+// PreviousOutputs returns the retained build outputs, newest first
+func (b *Builder) PreviousOutputs() ([]string, error) {
+	publicDir := filepath.Clean(b.config.PublicDir)
+	matches, err := filepath.Glob(publicDir + ".prev-*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return outputTimestamp(matches[i]) > outputTimestamp(matches[j])
+	})
+	return matches, nil
+}
+
+// outputTimestamp extracts the timestamp suffix from a retained output path
+func outputTimestamp(path string) int64 {
+	idx := strings.LastIndex(path, "-")
+	if idx < 0 {
+		return 0
+	}
+	return int64(util.ParseInt(path[idx+1:], 0))
+}
+
+// replaceContents empties dst and moves every entry of src into it
+func (b *Builder) replaceContents(dst, src string) error {
+	entries, err := os.ReadDir(dst)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	entries, err = os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		from := filepath.Join(src, entry.Name())
+		to := filepath.Join(dst, entry.Name())
+		if err := os.Rename(from, to); err != nil {
+			if err := b.copyTree(from, to); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyTree recursively copies src to dst
+func (b *Builder) copyTree(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, relPath)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode())
+		}
+		return b.copyFile(path, target)
+	})
+}
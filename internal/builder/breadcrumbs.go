@@ -0,0 +1,54 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"vango/internal/content"
+)
+
+// linkBreadcrumbs sets the breadcrumbs of every page built from a content
+// file. It returns the pages whose breadcrumbs changed, which includes the
+// pages below a directory whose index page changed its title. Taxonomy
+// pages are left out, as they are re-rendered with their terms.
+func (b *Builder) linkBreadcrumbs() []*content.Page {
+	for _, page := range b.taxonomyPages {
+		b.setBreadcrumbs(page)
+	}
+	var changed []*content.Page
+	for _, page := range b.pages {
+		before := breadcrumbOutline(page.Breadcrumbs)
+		b.setBreadcrumbs(page)
+		if breadcrumbOutline(page.Breadcrumbs) != before {
+			changed = append(changed, page)
+		}
+	}
+	return changed
+}
+
+// setBreadcrumbs sets page's breadcrumbs from the ref index
+func (b *Builder) setBreadcrumbs(page *content.Page) {
+	if page.FilePath == "" {
+		page.Breadcrumbs = nil
+		return
+	}
+	b.refMu.RLock()
+	index := b.refs
+	b.refMu.RUnlock()
+	lookup := func(dir string) *content.Page {
+		if index == nil {
+			return nil
+		}
+		return index.byPath[dir]
+	}
+	page.Breadcrumbs = content.Breadcrumbs(page, page.ContentPath(b.config.ContentDir), b.config.Title, lookup)
+}
+
+// breadcrumbOutline summarizes the breadcrumbs a page renders
+func breadcrumbOutline(crumbs []content.Breadcrumb) string {
+	var out strings.Builder
+	for _, crumb := range crumbs {
+		fmt.Fprintf(&out, "%s %s\t", crumb.Name, crumb.URL)
+	}
+	return out.String()
+}
@@ -0,0 +1,239 @@
+package builder
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// Budgets a BudgetViolation can exceed, named as in the budget config
+const (
+	BudgetHTML       = "html"
+	BudgetImage      = "image"
+	BudgetCSS        = "css"
+	BudgetJS         = "js"
+	BudgetPageWeight = "pageWeight"
+)
+
+// scriptTagPattern matches script start tags with their attributes
+var scriptTagPattern = regexp.MustCompile(`(?is)<script\b([^>]*)>`)
+
+// BudgetViolation is an output file, or a page with what it references,
+// larger than the performance budget allows
+type BudgetViolation struct {
+	Budget string `json:"budget"`
+	File   string `json:"file"`           // Relative to the output directory
+	Page   string `json:"page,omitempty"` // Page URL, for HTML files and page weights
+	Size   int64  `json:"size"`
+	Limit  int64  `json:"limit"`
+}
+
+// String describes the violation
+func (v BudgetViolation) String() string {
+	if v.Budget == BudgetPageWeight {
+		return fmt.Sprintf("%s weighs %s with its stylesheets, scripts and images, over the %s page weight budget",
+			v.Page, humanSize(v.Size), humanSize(v.Limit))
+	}
+	return fmt.Sprintf("%s is %s, over the %s %s budget", v.File, humanSize(v.Size), humanSize(v.Limit), v.Budget)
+}
+
+// pageWeight is what a rendered page, or a pager of one, downloads: its
+// HTML and the local stylesheets, scripts and images it references
+type pageWeight struct {
+	file  string   // HTML file, relative to the output directory
+	html  int64    // Size of the HTML file
+	refs  []string // Referenced files, relative to the output directory
+	total int64    // HTML and the referenced files that exist
+}
+
+// recordPageWeight keeps the files a page written to relPath references,
+// to weigh it once the asset stage has published them
+func (b *Builder) recordPageWeight(url, relPath string, size int64, html string) {
+	weight := &pageWeight{file: relPath, html: size, refs: pageAssets(html, relPath, b.config.BaseURL)}
+	b.weightsMu.Lock()
+	if b.weights == nil {
+		b.weights = make(map[string]*pageWeight)
+	}
+	b.weights[url] = weight
+	b.weightsMu.Unlock()
+}
+
+// resetPageWeights forgets the pages of the previous build
+func (b *Builder) resetPageWeights() {
+	b.weightsMu.Lock()
+	b.weights = nil
+	b.weightsMu.Unlock()
+}
+
+// pageAssets lists the local stylesheets, scripts and images a page at
+// relPath references, relative to the output directory
+func pageAssets(html, relPath, baseURL string) []string {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		base = &url.URL{}
+	}
+	basePath := "/" + strings.Trim(base.Path, "/")
+	pageDir := "/" + path.Dir(relPath)
+
+	var links []string
+	for _, match := range linkTagPattern.FindAllStringSubmatch(html, -1) {
+		if rel, _ := htmlAttr(match[1], "rel"); strings.EqualFold(strings.TrimSpace(rel), "stylesheet") {
+			href, _ := htmlAttr(match[1], "href")
+			links = append(links, href)
+		}
+	}
+	for _, pattern := range []*regexp.Regexp{scriptTagPattern, imgTagPattern} {
+		for _, match := range pattern.FindAllStringSubmatch(html, -1) {
+			src, _ := htmlAttr(match[1], "src")
+			links = append(links, src)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var refs []string
+	for _, link := range links {
+		target, ok := internalTarget(link, pageDir, base, basePath)
+		if !ok || target == "" || strings.HasSuffix(target, "/") || seen[target] {
+			continue
+		}
+		seen[target] = true
+		refs = append(refs, target)
+	}
+	return refs
+}
+
+// weighPages totals the weight of every rendered page, taking the sizes of
+// the files they reference from the asset manifest or the output directory
+func (b *Builder) weighPages(assets *AssetManifest) {
+	sizes := make(map[string]int64)
+	size := func(relPath string) int64 {
+		if n, ok := sizes[relPath]; ok {
+			return n
+		}
+		var n int64
+		if file := assets.Get(relPath); file != nil {
+			n = file.Size
+		} else if info, err := os.Stat(util.OutputPath(b.outputDir, relPath)); err == nil && !info.IsDir() {
+			n = info.Size()
+		}
+		sizes[relPath] = n
+		return n
+	}
+
+	b.weightsMu.Lock()
+	defer b.weightsMu.Unlock()
+	for _, weight := range b.weights {
+		weight.total = weight.html
+		for _, ref := range weight.refs {
+			weight.total += size(ref)
+		}
+	}
+}
+
+// PageWeight returns the weight in bytes of the page at url as the last
+// build wrote it, and whether it or its HTML is over the budget
+func (b *Builder) PageWeight(url string) (int64, bool) {
+	b.weightsMu.Lock()
+	defer b.weightsMu.Unlock()
+	weight := b.weights[url]
+	if weight == nil {
+		return 0, false
+	}
+	budget := b.config.Budget
+	over := overBudget(weight.total, budget.PageWeight) || overBudget(weight.html, budget.HTML)
+	return weight.total, over
+}
+
+// overBudget reports whether size exceeds a limit in kilobytes, 0 being
+// no limit
+func overBudget(size int64, limitKB int) bool {
+	return limitKB > 0 && size > int64(limitKB)*1024
+}
+
+// checkBudget weighs the pages and reports the output files and pages
+// over the performance budget. With budget.strict, any fails the build.
+func (b *Builder) checkBudget(assets *AssetManifest) ([]BudgetViolation, error) {
+	b.weighPages(assets)
+	budget := b.config.Budget
+
+	var violations []BudgetViolation
+	add := func(name, file, page string, size int64, limitKB int) {
+		if overBudget(size, limitKB) {
+			violations = append(violations, BudgetViolation{Budget: name, File: file, Page: page, Size: size, Limit: int64(limitKB) * 1024})
+		}
+	}
+
+	b.weightsMu.Lock()
+	pages := make(map[string]string, len(b.weights))
+	for url, weight := range b.weights {
+		pages[weight.file] = url
+		add(BudgetPageWeight, weight.file, url, weight.total, budget.PageWeight)
+	}
+	b.weightsMu.Unlock()
+
+	for _, relPath := range b.outputList() {
+		var name string
+		var limit int
+		switch ext := strings.ToLower(path.Ext(relPath)); {
+		case ext == ".html":
+			name, limit = BudgetHTML, budget.HTML
+		case ext == ".css":
+			name, limit = BudgetCSS, budget.CSS
+		case ext == ".js":
+			name, limit = BudgetJS, budget.JS
+		case strings.HasPrefix(mime.TypeByExtension(ext), "image/"):
+			name, limit = BudgetImage, budget.Image
+		}
+		if limit == 0 {
+			continue
+		}
+		var size int64
+		if file := assets.Get(relPath); file != nil {
+			size = file.Size
+		} else if info, err := os.Stat(util.OutputPath(b.outputDir, relPath)); err == nil {
+			size = info.Size()
+		}
+		add(name, relPath, pages[relPath], size, limit)
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].File != violations[j].File {
+			return violations[i].File < violations[j].File
+		}
+		return violations[i].Budget < violations[j].Budget
+	})
+	if len(violations) == 0 {
+		return nil, nil
+	}
+	for _, violation := range violations {
+		logging.Warnf("⚠️  Budget: %s", violation)
+	}
+	if budget.Strict {
+		return violations, fmt.Errorf("%d files and pages are over the performance budget", len(violations))
+	}
+	logging.Warnf("⚠️  %d files and pages are over the performance budget; see the warnings above", len(violations))
+	return violations, nil
+}
+
+// humanSize formats a size in bytes, e.g. "1.2 MB"
+func humanSize(n int64) string {
+	size := float64(n)
+	for _, unit := range []string{"B", "KB", "MB"} {
+		if size < 1024 {
+			if unit == "B" {
+				return fmt.Sprintf("%d %s", n, unit)
+			}
+			return fmt.Sprintf("%.1f %s", size, unit)
+		}
+		size /= 1024
+	}
+	return fmt.Sprintf("%.1f GB", size)
+}
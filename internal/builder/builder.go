@@ -4,16 +4,19 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
-	"runtime"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	"vango/internal/config"
 	"vango/internal/content"
+	"vango/internal/logging"
 	"vango/internal/template"
 	"vango/internal/theme"
+	"vango/internal/util"
 )
 
 // Builder handles site building
@@ -22,112 +25,373 @@ type Builder struct {
 	parser       *content.Parser
 	engine       *template.Engine
 	pages        []*content.Page
+	pagesMu      sync.RWMutex
 	themeManager *theme.ThemeManager
 	
 	// Performance enhancements
 	workers      int
 	cache        map[string]time.Time // File modification cache
 	cacheMutex   sync.RWMutex
+	
+	// outputDir is where the current build writes; a staging directory
+	// during atomic builds, otherwise the public directory itself
+	outputDir    string
+	
+	// Output tracking for orphan pruning and the build report
+	outputs      map[string]bool
+	outputsMu    sync.Mutex
+	report       *BuildReport
+	
+	// Phase durations of the last full build, for the build metrics
+	parseTime    time.Duration
+	renderTime   time.Duration
+	
+	// Whether precompressed variants of the output are written
+	precompress  bool
+	
+	// Change tracking: files written by the current build, and for the
+	// development server the content hashes of the outputs and the files
+	// the last build changed. The output manifest records what builds
+	// wrote, to spot files edited by hand since.
+	trackChanges bool
+	written      map[string]bool // Guarded by outputsMu
+	digests      map[string]string
+	changed      map[string]bool
+	manifest     *outputManifest
+	changesMu    sync.Mutex
+	
+	// Progress reporting for long-running builds
+	progress     ProgressFunc
+	rendered     int64
+	
+	// Compiled stylesheet URLs by source name, for the scss template function
+	scssURLs     map[string]string
+	scssMu       sync.RWMutex
+
+	// Asset files referenced with the resource template function, by name
+	assetFiles   map[string]*content.Resource
+	assetFilesMu sync.Mutex
+
+	// Bundle files of rendered pages waiting for the asset stage, by output
+	// path, and the lock that runs one asset stage at a time
+	pendingResources map[string]*content.Resource
+	pendingMu        sync.Mutex
+	stageMu          sync.Mutex
+
+	// Social images already reported missing, so each is warned about once
+	// per build
+	missingImages   map[string]bool
+	missingImagesMu sync.Mutex
+
+	// Preload Link headers by page URL, for the _headers file
+	linkHeaders   map[string][]string
+	linkHeadersMu sync.Mutex
+
+	// Publish times of the pages left out only until they are due, by
+	// content file
+	scheduled   map[string]time.Time
+	scheduledMu sync.Mutex
+
+	// Markup problems by page URL, when htmlValidation is on
+	htmlIssues   map[string][]HTMLIssue
+	htmlIssuesMu sync.Mutex
+
+	// Page weights by page URL, for the performance budget
+	weights   map[string]*pageWeight
+	weightsMu sync.Mutex
+
+	// Processing of image resources, for their Resize, Fit, Fill and
+	// Grayscale methods
+	images       *imageProcessor
+	
+	// Taxonomies from the last build and the pages rendered for them
+	taxonomies    map[string]content.Taxonomy
+	taxonomyPages []*content.Page
+	taxonomyLists map[string][]*content.Page // Page URL -> pages it lists
+	// Taxonomy and term _index pages by content directory, taken out of
+	// pages by the first buildTaxonomies after content is parsed
+	taxonomyIndexes map[string]*content.Page
+
+	// Content sections with their navigation trees, from the last build
+	sections      map[string]*content.Section
+	
+	// Cascade tables of the _index files read this build, by directory
+	cascades      map[string]map[string]interface{}
+	cascadesMu    sync.Mutex
+	
+	// Content path index for the ref and relref functions
+	refs          *refIndex
+	refMu         sync.RWMutex
+	
+	// Held by RenderOnDemand for reading, and for writing by Build,
+	// IncrementalBuild, ReloadTemplates and Rebuild while they replace the
+	// theme, templates, pages and output directory
+	lazyMu        sync.RWMutex
+	
+	// Git revision of the site, looked up once for .Site.BuildInfo
+	gitOnce       sync.Once
+	gitCommit     string
+	gitBranch     string
+}
+
+// BuildReport summarizes the result of the last build
+type BuildReport struct {
+	Pages       int           `json:"pages"`
+	Workers     int           `json:"workers"`
+	Duration    time.Duration `json:"duration"`
+	OutputFiles []string      `json:"output_files"`
+	PrunedFiles []string      `json:"pruned_files"`
+	Metrics     *BuildMetrics `json:"metrics,omitempty"`
+	Compression *CompressionReport `json:"compression,omitempty"`
+	Assets      *AssetManifest `json:"assets,omitempty"`
+	HTMLIssues  []HTMLIssue    `json:"html_issues,omitempty"`
+	Budget      []BudgetViolation `json:"budget,omitempty"` // Files and pages over the performance budget
+	// NextPublishAt is when the next scheduled page goes live, for an
+	// external scheduler to rebuild the site then
+	NextPublishAt *time.Time `json:"next_publish_at,omitempty"`
 }
 
 // New creates a new builder
 func New(cfg *config.Config) *Builder {
-	workers := runtime.NumCPU()
-	if workers > 8 {
-		workers = 8 // Cap at 8 for optimal performance
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = config.DefaultWorkers()
 	}
 	
 	tm := theme.NewThemeManager(cfg)
-	return &Builder{
+	parser := content.NewParser()
+	parser.SetTOCLevels(cfg.Markup.TableOfContents.StartLevel, cfg.Markup.TableOfContents.EndLevel)
+	parser.SetDefaultLanguage(cfg.Language)
+	parser.SetEllipsis(cfg.Ellipsis)
+	if cfg.TimeZone != "" {
+		parser.SetLocation(cfg.GetLocation())
+	}
+	parser.SetDateFromFilename(cfg.DateFromFilename, cfg.KeepFilenameDate)
+	parser.SetFrontMatterExpander(FrontMatterExpander(cfg))
+	parser.SetTitleCaseStyle(cfg.TitleCaseStyle)
+	parser.SetExtensions(cfg.ContentExtensions)
+	b := &Builder{
 		config:       cfg,
-		parser:       content.NewParser(),
+		parser:       parser,
 		engine:       template.NewEngine(cfg, tm),
 		pages:        make([]*content.Page, 0),
 		themeManager: tm,
 		workers:      workers,
 		cache:        make(map[string]time.Time),
+		outputDir:    cfg.PublicDir,
+		outputs:      make(map[string]bool),
+		report:       &BuildReport{},
+		scssURLs:     make(map[string]string),
+		assetFiles:   make(map[string]*content.Resource),
+		precompress:  true,
 	}
+	b.images = newImageProcessor(b)
+	b.engine.SetMetrics(cfg.Features.ProfileMode)
+	b.engine.SetFunc("scss", b.scssURL)
+	b.engine.SetFunc("resource", b.assetResource)
+	b.engine.SetFunc("ref", b.refFunc("ref"))
+	b.engine.SetFunc("relref", b.refFunc("relref"))
+	b.engine.SetFunc("socialImage", b.socialImage)
+	b.engine.SetFunc("openGraph", b.openGraph)
+	b.engine.SetFunc("twitterCard", b.twitterCard)
+	b.engine.SetFunc("preload", b.preload)
+	b.engine.SetFunc("prefetchNext", b.prefetchNext)
+	b.engine.SetFunc("feedHTML", b.feedHTML)
+	b.engine.SetFunc("analyticsSnippet", b.analyticsSnippet)
+	b.engine.SetFunc("faviconTags", b.faviconTags)
+	return b
 }
 
 // Build builds the entire site
 func (b *Builder) Build() error {
+	b.lazyMu.Lock()
+	defer b.lazyMu.Unlock()
+	return b.build()
+}
+
+// build is Build for callers that already hold lazyMu
+func (b *Builder) build() error {
 	start := time.Now()
-	fmt.Printf("🏗️  Building site with %d workers...\n", b.workers)
+	logging.Infof("🏗️  Building site with %d workers...", b.workers)
+	b.resetOutputs()
+	b.startChanges()
+	b.resetAssetFiles()
+	b.resetPendingResources()
+	b.resetMissingImages()
+	b.resetLinkHeaders()
+	b.resetHTMLIssues()
+	b.resetPageWeights()
+	b.engine.ResetMetrics()
+	b.reportProgress("start", 0)
+
+	b.setupTheme()
+	if err := b.startReproducible(); err != nil {
+		return err
+	}
+
+	// Clean builds render into a staging directory that is swapped into
+	// place only once the build succeeds
+	if b.config.CleanBuild {
+		staging, err := b.createStagingDir()
+		if err != nil {
+			return fmt.Errorf("failed to create staging directory: %w", err)
+		}
+		b.outputDir = staging
+		defer func() {
+			os.RemoveAll(staging)
+			b.outputDir = b.config.PublicDir
+			// A failed clean build wrote nothing to the public directory
+			b.outputsMu.Lock()
+			b.written = nil
+			b.outputsMu.Unlock()
+		}()
+	}
+
+	// Fresh output and orphan pruning both need every page, not just the
+	// ones modified since the previous build
+	if b.config.CleanBuild || b.config.CleanOrphans {
+		b.resetCache()
+	}
+
+	if err := b.checkModifiedOutputs(); err != nil {
+		return err
+	}
+
+	// Ensure output directory exists
+	if err := os.MkdirAll(b.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create public directory: %w", err)
+	}
+
+	if err := b.preparePhase(); err != nil {
+		return err
+	}
+	if err := b.parsePhase(); err != nil {
+		return err
+	}
+	if err := b.enrichPhase(); err != nil {
+		return err
+	}
+	// Pages are read-only while they render, so on-demand renders may run
+	// alongside
+	b.lazyMu.Unlock()
+	err := b.renderPhase()
+	b.lazyMu.Lock()
+	if err != nil {
+		return err
+	}
+	assets, compression, err := b.assetPhase(start)
+	if err != nil {
+		return err
+	}
+	budget, err := b.checkBudget(assets)
+	if err != nil {
+		return err
+	}
+
+	b.reportProgress("publish", 98)
+	var pruned []string
+	if b.outputDir != b.config.PublicDir {
+		if err := b.swapOutput(b.outputDir); err != nil {
+			return fmt.Errorf("failed to publish build output: %w", err)
+		}
+	} else if b.config.CleanOrphans {
+		if pruned, err = b.pruneOrphans(); err != nil {
+			return fmt.Errorf("failed to prune orphaned files: %w", err)
+		}
+	}
+
+	b.finishChanges(true)
+
+	duration := time.Since(start)
+	b.report = &BuildReport{
+		Pages:       len(b.pages),
+		Workers:     b.workers,
+		Duration:    duration,
+		OutputFiles: b.outputList(),
+		PrunedFiles: pruned,
+		Metrics:     b.Metrics(metricsTopN),
+		Compression: compression,
+		Assets:      assets,
+		HTMLIssues:  b.HTMLIssues(),
+		Budget:      budget,
+		NextPublishAt: b.NextPublishAt(),
+	}
+	if next := b.report.NextPublishAt; next != nil {
+		logging.Infof("⏰ Next scheduled page goes live at %s", next.In(b.config.GetLocation()).Format("2006-01-02 15:04 MST"))
+	}
+	if n := len(b.report.HTMLIssues); n > 0 {
+		logging.Warnf("⚠️  Found %d HTML problems; see the warnings above", n)
+	}
+	logging.Infof("✅ Generated %d pages in %v", len(b.pages), duration)
+	b.reportProgress("done", 100)
+	return nil
+}
 
+// setupTheme loads themes and activates the configured one
+func (b *Builder) setupTheme() {
 	// Load themes and set active theme
 	if err := b.themeManager.LoadThemes(); err != nil {
-        fmt.Printf("⚠️  Warning: Failed to load themes: %v\n", err)
+        logging.Warnf("⚠️  Warning: Failed to load themes: %v", err)
     }
     
     if b.config.Theme != "" {
         if err := b.themeManager.SetActiveTheme(b.config.Theme); err != nil {
-            fmt.Printf("⚠️  Warning: Theme '%s' not found, using default theme\n", b.config.Theme)
+            logging.Warnf("⚠️  Warning: Theme '%s' not found, using default theme", b.config.Theme)
             b.themeManager.SetDefaultTheme("default")
+        } else {
+            logging.Infof("📦 Using theme: %s", b.themeManager.GetActiveTheme().Name)
         }
-        fmt.Printf("📦 Using theme: %s\n", b.themeManager.GetActiveTheme().Name)
     } else {
         // No theme specified, use default
         b.themeManager.SetDefaultTheme("default")
-        fmt.Printf("📦 Using default theme\n")
+        logging.Infof("📦 Using default theme")
     }
+    
+    // Read the theme configuration once, before page workers share it
+    if _, err := b.themeManager.GetThemeConfig(); err != nil {
+        logging.Warnf("⚠️  Warning: %v", err)
+    }
+}
 
-	// Clean public directory if configured
-	if b.config.CleanBuild {
-		if err := b.cleanPublicDir(); err != nil {
-			return fmt.Errorf("failed to clean public directory: %w", err)
-		}
-	}
-
-	// Ensure public directory exists
-	if err := os.MkdirAll(b.config.PublicDir, 0755); err != nil {
-		return fmt.Errorf("failed to create public directory: %w", err)
-	}
-
-	// Load templates with caching
+// PrepareTemplates loads the theme and templates without building anything,
+// so pages can be rendered on demand
+func (b *Builder) PrepareTemplates() error {
+	b.setupTheme()
 	if err := b.engine.LoadTemplates(b.themeManager.GetThemeTemplatesPath()); err != nil {
 		return fmt.Errorf("failed to load templates: %w", err)
 	}
-
-	// Parse content files in parallel
-	if err := b.parseContentParallel(); err != nil {
-		return fmt.Errorf("failed to parse content: %w", err)
+	if err := b.loadTranslations(); err != nil {
+		return err
 	}
-
-	// Generate pages in parallel
-	if err := b.generatePagesParallel(); err != nil {
-		return fmt.Errorf("failed to generate pages: %w", err)
+	if _, err := b.compileSCSS(); err != nil {
+		return fmt.Errorf("failed to compile stylesheets: %w", err)
 	}
+	return nil
+}
 
-	// Copy static assets and theme assets in parallel
-	errChan := make(chan error, 2)
-	go func() {
-		errChan <- b.copyStaticFiles()
-	}()
-	go func() {
-		errChan <- b.themeManager.CopyThemeAssets(b.config.PublicDir)
-	}()
-
-	// Wait for both operations to complete
-	for i := 0; i < 2; i++ {
-		if err := <-errChan; err != nil {
-			return fmt.Errorf("failed to copy assets: %w", err)
-		}
+// loadTranslations loads the theme's and the site's i18n files, the site's
+// taking precedence
+func (b *Builder) loadTranslations() error {
+	translations, err := template.LoadTranslations(b.themeManager.GetThemeI18nPath(), b.config.I18nDir)
+	if err != nil {
+		return fmt.Errorf("failed to load translations: %w", err)
 	}
-
-	duration := time.Since(start)
-	fmt.Printf("✅ Generated %d pages in %v\n", len(b.pages), duration)
+	b.engine.SetTranslations(translations)
 	return nil
 }
 
 // parseContentParallel parses content files using worker goroutines
 func (b *Builder) parseContentParallel() error {
+	// _index files may have changed since their cascades were read
+	b.resetCascades()
+
 	// Collect all markdown files
 	var files []string
 	err := filepath.Walk(b.config.ContentDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if !info.IsDir() && strings.HasSuffix(strings.ToLower(path), ".md") {
+		if !info.IsDir() && b.isPageFile(path) {
 			// Check cache for file modification time
 			if b.isFileModified(path, info.ModTime()) {
 				files = append(files, path)
@@ -140,11 +404,11 @@ func (b *Builder) parseContentParallel() error {
 	}
 
 	if len(files) == 0 {
-		fmt.Println("📝 No content files to process")
+		logging.Infof("📝 No content files to process")
 		return nil
 	}
 
-	fmt.Printf("📝 Processing %d content files...\n", len(files))
+	logging.Infof("📝 Processing %d content files...", len(files))
 
 	// Create worker pool
 	fileChan := make(chan string, len(files))
@@ -191,7 +455,10 @@ func (b *Builder) parseContentParallel() error {
 		return fmt.Errorf("content parsing errors: %v", errors[0])
 	}
 
+	b.pagesMu.Lock()
 	b.pages = pages
+	b.taxonomyIndexes = nil
+	b.pagesMu.Unlock()
 	return nil
 }
 
@@ -207,10 +474,14 @@ func (b *Builder) contentWorker(wg *sync.WaitGroup, fileChan <-chan string, resu
 		}
 
 		// Check if page should be built
-		if !page.ShouldBuild(b.config.BuildDrafts, b.config.BuildFuture) {
+		if !b.shouldBuild(page) {
+			b.noteScheduled(filePath, page)
 			continue
 		}
+		b.noteScheduled(filePath, nil)
 
+		b.applySectionConfig(page)
+		b.setPermalink(page)
 		resultChan <- page
 	}
 }
@@ -221,21 +492,32 @@ func (b *Builder) generatePagesParallel() error {
 		return nil
 	}
 
-	fmt.Printf("🎨 Rendering %d pages...\n", len(b.pages))
+	logging.Infof("🎨 Rendering %d pages...", len(b.pages))
+	return b.renderPages(b.pages)
+}
+
+// renderPages renders the given pages using worker goroutines
+func (b *Builder) renderPages(pages []*content.Page) error {
+	if len(pages) == 0 {
+		return nil
+	}
+
+	b.rendered = 0
+	b.reportProgress("render", renderProgressStart)
 
 	// Create worker pool for page generation
-	pageChan := make(chan *content.Page, len(b.pages))
-	errorChan := make(chan error, len(b.pages))
+	pageChan := make(chan *content.Page, len(pages))
+	errorChan := make(chan error, len(pages))
 
 	// Start workers
 	var wg sync.WaitGroup
 	for i := 0; i < b.workers; i++ {
 		wg.Add(1)
-		go b.pageWorker(&wg, pageChan, errorChan)
+		go b.pageWorker(&wg, pageChan, errorChan, len(pages))
 	}
 
 	// Send pages to workers
-	for _, page := range b.pages {
+	for _, page := range pages {
 		pageChan <- page
 	}
 	close(pageChan)
@@ -262,16 +544,39 @@ func (b *Builder) generatePagesParallel() error {
 }
 
 // pageWorker renders individual pages
-func (b *Builder) pageWorker(wg *sync.WaitGroup, pageChan <-chan *content.Page, errorChan chan<- error) {
+func (b *Builder) pageWorker(wg *sync.WaitGroup, pageChan <-chan *content.Page, errorChan chan<- error, total int) {
 	defer wg.Done()
 	
 	for page := range pageChan {
-		if err := b.generatePage(page); err != nil {
+		if err := b.generatePageSafely(page); err != nil {
 			errorChan <- fmt.Errorf("failed to generate page %s: %w", page.FilePath, err)
 		}
+		b.pageRendered(total)
 	}
 }
 
+// generatePageSafely generates a page, turning a panic while rendering it
+// into an error so the other workers' pages still build
+func (b *Builder) generatePageSafely(page *content.Page) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while rendering: %v", r)
+		}
+	}()
+	return b.generatePage(page)
+}
+
+// resetCache forgets file modification times so every file is processed again
+func (b *Builder) resetCache() {
+	b.cacheMutex.Lock()
+	b.cache = make(map[string]time.Time)
+	b.cacheMutex.Unlock()
+
+	b.scheduledMu.Lock()
+	b.scheduled = nil
+	b.scheduledMu.Unlock()
+}
+
 // isFileModified checks if a file has been modified since last build
 func (b *Builder) isFileModified(path string, modTime time.Time) bool {
 	b.cacheMutex.RLock()
@@ -290,32 +595,90 @@ func (b *Builder) isFileModified(path string, modTime time.Time) bool {
 // IncrementalBuild performs incremental build based on changed files
 func (b *Builder) IncrementalBuild(changedFiles []string) error {
 	start := time.Now()
-	fmt.Printf("🔄 Incremental build for %d changed files...\n", len(changedFiles))
+	logging.Infof("🔄 Incremental build for %d changed files...", len(changedFiles))
+	b.lazyMu.Lock()
+	defer b.lazyMu.Unlock()
+	b.startChanges()
 
 	var needsFullRebuild bool
+	var stylesChanged bool
+	var themeConfigChanged bool
+	var assetsChanged bool
+	var dataChanged bool
 	var contentFiles []string
+	var templateFiles []string
 
 	for _, file := range changedFiles {
-		switch {
-		case strings.HasSuffix(file, ".html"):
-			// Template changed, need full rebuild
-			needsFullRebuild = true
-		case strings.HasSuffix(file, ".toml"):
-			// Config changed, need full rebuild
-			needsFullRebuild = true
-		case strings.HasSuffix(file, ".md"):
-			// Content file changed
+		switch b.classifyChange(file) {
+		case changeContent:
+			// Content file or bundle resource changed, rebuild the page
+			if index := content.BundleIndex(file, b.config.ContentDir, b.config.ContentExtensions); index != "" {
+				file = index
+			}
 			contentFiles = append(contentFiles, file)
-		case strings.Contains(file, b.config.StaticDir):
-			// Static file changed, just copy
-			if err := b.copyStaticFiles(); err != nil { // Removed argument (file). Check for bugs in this line.
-				return fmt.Errorf("failed to copy static file: %w", err)
+			if b.cascadeChanged(file) {
+				// Every page below the _index file may take something
+				// else, so all of them are parsed again
+				b.resetCache()
+				needsFullRebuild = true
 			}
+		case changeThemeConfig:
+			// Theme configuration changed, every page may use it
+			b.themeManager.InvalidateThemeConfig()
+			themeConfigChanged = true
+		case changeTemplate:
+			// Template changed, reload templates and re-render affected pages
+			templateFiles = append(templateFiles, file)
+		case changeFullRebuild:
+			// Config or translations changed, need full rebuild
+			needsFullRebuild = true
+		case changeStyles:
+			// Stylesheet or partial changed, recompile them all
+			stylesChanged = true
+		case changeAsset:
+			// Asset file used by pages, whose fingerprinted URLs may change
+			assetsChanged = true
+		case changeStatic, changeStaticCSS:
+			// Static file of the site or the theme, copied or removed alone
+			if err := b.copyStaticFile(file); err != nil {
+				return err
+			}
+		case changeData:
+			// Data files aren't tracked per page yet, so every page re-renders
+			dataChanged = true
+		default:
+			logging.Debugf("Ignoring change to %s", file)
+		}
+	}
+
+	if needsFullRebuild || (len(templateFiles) > 0 && len(b.pages) == 0) {
+		return b.build()
+	}
+	b.updateBuildInfo()
+
+	var urlsChanged bool
+	if stylesChanged {
+		var err error
+		if urlsChanged, err = b.compileSCSS(); err != nil {
+			return err
 		}
 	}
 
-	if needsFullRebuild {
-		return b.Build()
+	if len(templateFiles) > 0 {
+		if err := b.reloadTemplates(templateFiles); err != nil {
+			return err
+		}
+	}
+
+	// Fingerprinted stylesheet URLs and theme configuration are baked into
+	// every page that uses them
+	if assetsChanged {
+		b.resetAssetFiles()
+	}
+	if urlsChanged || themeConfigChanged || assetsChanged || dataChanged {
+		if err := b.renderPages(b.pages); err != nil {
+			return fmt.Errorf("failed to re-render pages: %w", err)
+		}
 	}
 
 	// Process only changed content files
@@ -325,11 +688,139 @@ func (b *Builder) IncrementalBuild(changedFiles []string) error {
 		}
 	}
 
+	// Term assignments and section order may have changed with the content
+	if len(contentFiles) > 0 {
+		b.linkSections()
+		b.buildTaxonomies()
+		b.buildStats()
+		if err := b.rerenderSections(b.buildSections()); err != nil {
+			return fmt.Errorf("failed to re-render section pages: %w", err)
+		}
+		if err := b.renderPages(b.linkSeries()); err != nil {
+			return fmt.Errorf("failed to re-render series pages: %w", err)
+		}
+		if err := b.renderPages(b.linkBreadcrumbs()); err != nil {
+			return fmt.Errorf("failed to re-render pages with new breadcrumbs: %w", err)
+		}
+		if err := b.generateTaxonomies(); err != nil {
+			return fmt.Errorf("failed to generate taxonomy pages: %w", err)
+		}
+		if err := b.generateSectionFeeds(); err != nil {
+			return fmt.Errorf("failed to generate section feeds: %w", err)
+		}
+	}
+
+	// Re-rendered pages may reference asset files not published yet
+	var sources []assetSource
+	if themeConfigChanged {
+		themeCSS, err := b.themeCSSSource()
+		if err != nil {
+			return err
+		}
+		sources = append(sources, themeCSS...)
+	}
+	assets, err := b.runAssetStage(append(sources, b.referencedSources()...))
+	if err != nil {
+		return fmt.Errorf("failed to publish assets: %w", err)
+	}
+	b.weighPages(assets)
+
+	// Changed outputs need fresh variants; unchanged ones are kept
+	if _, err := b.compressOutputs(); err != nil {
+		return fmt.Errorf("failed to precompress output: %w", err)
+	}
+
+	b.finishChanges(false)
+
 	duration := time.Since(start)
-	fmt.Printf("✅ Incremental build completed in %v\n", duration)
+	logging.Infof("✅ Incremental build completed in %v", duration)
 	return nil
 }
 
+// ReloadTemplates re-parses the changed templates, rebuilding the template
+// set from the cached parse of the others, and re-renders only the pages
+// whose template chain includes a changed file, reusing the pages already
+// parsed in memory
+func (b *Builder) ReloadTemplates(changedFiles []string) error {
+	b.lazyMu.Lock()
+	defer b.lazyMu.Unlock()
+	return b.reloadTemplates(changedFiles)
+}
+
+// reloadTemplates is ReloadTemplates for callers that already hold lazyMu
+func (b *Builder) reloadTemplates(changedFiles []string) error {
+	start := time.Now()
+
+	// Resolve chains against the old template set so removed includes still count
+	changed := make(map[string]bool)
+	for _, file := range changedFiles {
+		changed[absPath(file)] = true
+	}
+	affected := make(map[*content.Page]bool)
+	rendered := b.renderedPages()
+	for _, page := range rendered {
+		for _, file := range b.engine.TemplateFiles(page) {
+			if changed[absPath(file)] {
+				affected[page] = true
+				break
+			}
+		}
+	}
+
+	if err := b.engine.Reload(changedFiles); err != nil {
+		return fmt.Errorf("failed to reload templates: %w", err)
+	}
+
+	// A changed file outside every chain (a new template, say) can change
+	// which template a page resolves to, so fall back to re-rendering everything
+	var pages []*content.Page
+	if len(affected) == 0 {
+		pages = rendered
+	} else {
+		for _, page := range rendered {
+			if affected[page] || b.usesAny(page, changed) {
+				pages = append(pages, page)
+			}
+		}
+	}
+
+	if err := b.renderPages(pages); err != nil {
+		return fmt.Errorf("failed to re-render pages: %w", err)
+	}
+
+	duration := time.Since(start)
+	logging.Infof("🎨 Reparsed %d template files and re-rendered %d/%d pages after template change in %v", b.engine.ReparsedFiles(), len(pages), len(rendered), duration)
+	if b.report != nil && b.report.Duration > duration {
+		logging.Infof("⚡ Saved %v compared to the last full build (%v)", b.report.Duration-duration, b.report.Duration)
+	}
+	return nil
+}
+
+// renderedPages returns the content pages followed by the taxonomy pages
+func (b *Builder) renderedPages() []*content.Page {
+	pages := make([]*content.Page, 0, len(b.pages)+len(b.taxonomyPages))
+	pages = append(pages, b.pages...)
+	return append(pages, b.taxonomyPages...)
+}
+
+// usesAny reports whether the page's current template chain includes a changed file
+func (b *Builder) usesAny(page *content.Page, changed map[string]bool) bool {
+	for _, file := range b.engine.TemplateFiles(page) {
+		if changed[absPath(file)] {
+			return true
+		}
+	}
+	return false
+}
+
+// absPath returns an absolute form of path for comparison, or path itself
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
 // Additional helper methods for incremental builds...
 func (b *Builder) rebuildContentFile(filePath string) error {
 	page, err := b.parser.ParseFile(filePath, b.config.ContentDir)
@@ -337,34 +828,80 @@ func (b *Builder) rebuildContentFile(filePath string) error {
 		return err
 	}
 
-	if !page.ShouldBuild(b.config.BuildDrafts, b.config.BuildFuture) {
+	if !b.shouldBuild(page) {
 		return nil
 	}
 
+	b.applySectionConfig(page)
+	b.setPermalink(page)
+	b.replacePage(page)
+	b.buildRefIndex()
+	b.setBreadcrumbs(page)
+	if err := b.resolveContentRefs([]*content.Page{page}); err != nil {
+		return err
+	}
+	if _, ok := taxonomyIndexDir(page, b.taxonomyPlurals()); ok {
+		// Rendered with its term once taxonomies are rebuilt
+		return nil
+	}
 	return b.generatePage(page)
 }
 
-// cleanPublicDir removes and recreates the public directory
-func (b *Builder) cleanPublicDir() error {
-	if _, err := os.Stat(b.config.PublicDir); !os.IsNotExist(err) {
-		if err := os.RemoveAll(b.config.PublicDir); err != nil {
-			return err
+// replacePage swaps the parsed page for the one built from the same file,
+// or adds it if the file is new, keeping the pages sorted
+func (b *Builder) replacePage(page *content.Page) {
+	b.pagesMu.Lock()
+	defer b.pagesMu.Unlock()
+	for i, existing := range b.pages {
+		if existing.FilePath == page.FilePath {
+			b.pages[i] = page
+			b.pages = content.Pages(b.pages).Sorted()
+			return
 		}
 	}
-	return nil
+	b.pages = content.Pages(append(b.pages, page)).Sorted()
+}
+
+// sortPages puts the parsed pages in the default order once per build, so
+// every template and taxonomy sees the same stable order regardless of
+// which worker finished first
+func (b *Builder) sortPages() {
+	b.pagesMu.Lock()
+	defer b.pagesMu.Unlock()
+	b.pages = content.Pages(b.pages).Sorted()
+}
+
+// setPermalink makes the page permalink absolute against the configured BaseURL
+// and resolves its canonical URL
+func (b *Builder) setPermalink(page *content.Page) {
+	page.Permalink = strings.TrimSuffix(b.config.BaseURL, "/") + page.URL
+	page.RelPermalink = b.config.RelURL(page.URL)
+	b.setResourcePermalinks(page)
+
+	// Canonical URLs default to the page itself on the canonical host; a
+	// site-relative canonical_url from front matter is resolved against it
+	canonicalBase := strings.TrimSuffix(b.config.GetCanonicalBaseURL(), "/")
+	switch {
+	case page.CanonicalURL == "":
+		page.CanonicalURL = canonicalBase + page.URL
+	case strings.HasPrefix(page.CanonicalURL, "/") && !strings.HasPrefix(page.CanonicalURL, "//"):
+		page.CanonicalURL = canonicalBase + page.CanonicalURL
+	}
 }
 
 // parseContent walks the content directory and parses all markdown files
 func (b *Builder) parseContent() error {
 	b.pages = make([]*content.Page, 0)
+	b.taxonomyIndexes = nil
+	b.resetCascades()
 
 	return filepath.Walk(b.config.ContentDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip directories and non-markdown files
-		if info.IsDir() || !strings.HasSuffix(strings.ToLower(path), ".md") {
+		// Skip directories, non-markdown files and bundle resources
+		if info.IsDir() || !b.isPageFile(path) {
 			return nil
 		}
 
@@ -375,8 +912,8 @@ func (b *Builder) parseContent() error {
 		}
 
 		// Check if page should be built
-		if !page.ShouldBuild(b.config.BuildDrafts, b.config.BuildFuture) {
-			fmt.Printf("Skipping %s (draft: %v, future: %v)\n", path, page.Draft, page.ParsedDate.After(time.Now()))
+		if !b.shouldBuild(page) {
+			logging.Debugf("Skipping %s (draft: %v, future: %v)", path, page.Draft, page.ParsedDate.After(b.config.Now()))
 			return nil
 		}
 
@@ -397,20 +934,73 @@ func (b *Builder) generatePages() error {
 
 // generatePage renders and writes a single page
 func (b *Builder) generatePage(page *content.Page) error {
+	if !b.rendersHTML(page) {
+		return nil
+	}
+
 	// Render the page
-	html, err := b.engine.Render(page, b.pages)
+	start := time.Now()
+	pages := b.pagesFor(page)
+	html, pager, err := b.engine.RenderPager(page, pages, 1)
 	if err != nil {
 		return err
 	}
+	if err := b.writePage(page, page.URL, page.Slug, html); err != nil {
+		return err
+	}
+	page.RenderTime = time.Since(start)
+	page.OutputPath = util.OutputPath(b.config.PublicDir, page.Slug, "index.html")
+	logging.Debugf("Generated: %s", page.OutputPath)
+
+	// A list page that called paginate gets the rest of its pagers, at the
+	// page size its template asked for
+	for n := 2; pager != nil && n <= pager.TotalPages; n++ {
+		html, _, err := b.engine.RenderPager(page, pages, n)
+		if err != nil {
+			return err
+		}
+		if err := b.writePage(page, b.config.PagerURL(page.URL, n), b.config.PagerSlug(page.Slug, n), html); err != nil {
+			return err
+		}
+	}
+	if pager != nil && pager.TotalPages > 1 {
+		logging.Debugf("Generated %d pagers of %s", pager.TotalPages, page.URL)
+	}
+	return nil
+}
 
-	// Determine output path
-	outputPath := filepath.Join(b.config.PublicDir, page.Slug, "index.html")
-	
-	// Handle root-level content files
-	if !strings.Contains(page.Slug, "/") {
-		outputPath = filepath.Join(b.config.PublicDir, page.Slug, "index.html")
+// writePage finishes a rendered page, or a pager of one, and writes it to
+// slug/index.html
+func (b *Builder) writePage(page *content.Page, url, slug, html string) error {
+	var err error
+	if b.config.BaseTag {
+		html = injectBaseTag(html, b.config.BaseURLPath()+"/")
+	}
+	html = injectGeneratorTag(html, b.engine.GeneratorTag())
+	html, preloads := b.applyResourceHints(html)
+	b.recordLinkHeaders(url, preloads)
+
+	// Inject the preview banner at the same point live reload uses
+	if b.config.IsPreview && strings.Contains(html, "</body>") {
+		banner, err := b.engine.RenderPreviewBanner(page)
+		if err != nil {
+			return err
+		}
+		html = strings.Replace(html, "</body>", banner+"\n</body>", 1)
+	}
+	if html, err = b.injectAnalytics(html); err != nil {
+		return err
+	}
+	if html, err = b.injectCodeCopy(html, page); err != nil {
+		return err
+	}
+	if b.config.HTMLValidation.Enable {
+		b.recordHTMLIssues(url, CheckHTML(url, html, b.config.HTMLValidation.ImageDimensions))
 	}
 
+	// Determine output path
+	outputPath := util.OutputPath(b.outputDir, slug, "index.html")
+
 	// Create output directory
 	outputDir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
@@ -424,54 +1014,45 @@ func (b *Builder) generatePage(page *content.Page) error {
 	}
 	defer file.Close()
 
-	if _, err := file.WriteString(html); err != nil {
+	// Tidy the output on its way to the file when templates.trimWhitespace is on
+	var out io.StringWriter = file
+	var trimmer *whitespaceTrimmer
+	if b.config.Templates.TrimWhitespace {
+		trimmer = newWhitespaceTrimmer(file)
+		out = trimmer
+	}
+	if _, err := out.WriteString(html); err != nil {
 		return fmt.Errorf("failed to write output file %s: %w", outputPath, err)
 	}
-
-	page.OutputPath = outputPath
-	fmt.Printf("Generated: %s\n", outputPath)
+	if trimmer != nil {
+		if err := trimmer.Flush(); err != nil {
+			return fmt.Errorf("failed to write output file %s: %w", outputPath, err)
+		}
+	}
+	size := int64(len(html))
+	if info, err := file.Stat(); err == nil {
+		size = info.Size()
+	}
+	b.recordPageWeight(url, path.Join(slug, "index.html"), size, html)
+	b.recordOutput(path.Join(slug, "index.html"))
+	b.queueResources(page)
 	return nil
 }
 
-// copyStaticFiles copies static assets to the public directory
-func (b *Builder) copyStaticFiles() error {
-	staticDir := b.config.StaticDir
-	
-	// Check if static directory exists
-	if _, err := os.Stat(staticDir); os.IsNotExist(err) {
-		fmt.Printf("Static directory %s does not exist, skipping\n", staticDir)
-		return nil
-	}
-
-	staticOutputDir := filepath.Join(b.config.PublicDir, "static")
-	
-	return filepath.Walk(staticDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+// headTag matches the opening <head> tag of a page
+var headTag = regexp.MustCompile(`(?i)<head(\s[^>]*)?>`)
 
-		if info.IsDir() {
-			return nil
-		}
-
-		// Get relative path from static directory
-		relPath, err := filepath.Rel(staticDir, path)
-		if err != nil {
-			return err
-		}
-
-		// Create output path
-		outputPath := filepath.Join(staticOutputDir, relPath)
-		
-		// Create output directory
-		outputDir := filepath.Dir(outputPath)
-		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", outputDir, err)
-		}
-
-		// Copy file
-		return b.copyFile(path, outputPath)
-	})
+// injectBaseTag adds a <base> tag for href right after the opening <head>
+// tag, unless the page already sets one
+func injectBaseTag(html, href string) string {
+	if strings.Contains(strings.ToLower(html), "<base ") {
+		return html
+	}
+	loc := headTag.FindStringIndex(html)
+	if loc == nil {
+		return html
+	}
+	return html[:loc[1]] + "\n    <base href=\"" + href + "\">" + html[loc[1]:]
 }
 
 // copyFile copies a file from src to dst
@@ -502,8 +1083,15 @@ func (b *Builder) copyFile(src, dst string) error {
 	return destFile.Chmod(sourceInfo.Mode())
 }
 
+// GetReport returns the report of the last full build
+func (b *Builder) GetReport() *BuildReport {
+	return b.report
+}
+
 // GetPages returns all parsed pages
 func (b *Builder) GetPages() []*content.Page {
+	b.pagesMu.RLock()
+	defer b.pagesMu.RUnlock()
 	return b.pages
 }
 
@@ -0,0 +1,62 @@
+package builder
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"vango/internal/template"
+	"vango/internal/version"
+)
+
+// Version is the VanGo version reported to templates in .Site.BuildInfo
+// and the generator meta tag
+var Version = version.Get().Version
+
+// updateBuildInfo hands the current build's info to the template engine
+func (b *Builder) updateBuildInfo() {
+	b.gitOnce.Do(func() {
+		b.gitCommit, b.gitBranch = gitRevision(filepath.Dir(filepath.Clean(b.config.ContentDir)))
+	})
+	binary := version.Get()
+	b.engine.SetBuildInfo(template.BuildInfo{
+		Time:        b.config.Now(),
+		Version:     Version,
+		Environment: b.config.Environment,
+		Commit:      b.gitCommit,
+		Branch:      b.gitBranch,
+		VangoCommit: binary.Commit,
+		VangoDate:   binary.Date,
+		GoVersion:   binary.GoVersion,
+	})
+}
+
+// gitRevision returns the short commit hash and branch of the git checkout
+// containing dir, or empty strings when there is none
+func gitRevision(dir string) (commit, branch string) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return "", ""
+	}
+	commit = strings.TrimSpace(string(out))
+	if out, err := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD").Output(); err == nil {
+		branch = strings.TrimSpace(string(out))
+	}
+	if branch == "HEAD" {
+		branch = "" // Detached, as in most CI checkouts
+	}
+	return commit, branch
+}
+
+// injectGeneratorTag adds the generator meta tag right after the opening
+// <head> tag, unless the page's templates already emit one
+func injectGeneratorTag(html, tag string) string {
+	if tag == "" || strings.Contains(strings.ToLower(html), `name="generator"`) {
+		return html
+	}
+	loc := headTag.FindStringIndex(html)
+	if loc == nil {
+		return html
+	}
+	return html[:loc[1]] + "\n    " + tag + html[loc[1]:]
+}
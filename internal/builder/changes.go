@@ -0,0 +1,90 @@
+package builder
+
+import (
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// SetTrackChanges makes builds note which output files they changed, for
+// ChangedOutputs. Only the development server turns it on.
+func (b *Builder) SetTrackChanges(track bool) {
+	b.trackChanges = track
+}
+
+// startChanges begins noting the files the current build writes. What a
+// failed build before it wrote is recorded first.
+func (b *Builder) startChanges() {
+	b.outputsMu.Lock()
+	leftover := b.written != nil
+	b.outputsMu.Unlock()
+	if leftover {
+		b.finishChanges(false)
+	}
+	b.outputsMu.Lock()
+	b.written = make(map[string]bool)
+	b.outputsMu.Unlock()
+}
+
+// finishChanges hashes the files a successful build wrote, records them in
+// the output manifest and, with change tracking, compares them with their
+// content after the previous build. A full build starts the comparison
+// over, since every file it didn't write is gone. Nothing counts as changed
+// after the first build.
+func (b *Builder) finishChanges(full bool) {
+	b.outputsMu.Lock()
+	written := b.written
+	b.written = nil
+	b.outputsMu.Unlock()
+	if !b.trackChanges && b.config.ModifiedOutputLevel == "off" {
+		return
+	}
+
+	entries := make(map[string]outputEntry, len(written))
+	for relPath := range written {
+		entry, err := hashOutputFile(util.OutputPath(b.config.PublicDir, relPath))
+		if err != nil {
+			// Written and then replaced or removed, e.g. a shadowed theme asset
+			continue
+		}
+		entries[relPath] = entry
+	}
+
+	b.changesMu.Lock()
+	defer b.changesMu.Unlock()
+	if b.config.ModifiedOutputLevel != "off" {
+		b.updateOutputManifest(entries, full)
+	}
+	if !b.trackChanges {
+		return
+	}
+
+	first := b.digests == nil
+	digests := b.digests
+	if full || digests == nil {
+		digests = make(map[string]string, len(entries))
+	}
+	changed := make(map[string]bool)
+	for relPath, entry := range entries {
+		if !first && b.digests[relPath] != entry.Hash {
+			changed[relPath] = true
+		}
+		digests[relPath] = entry.Hash
+	}
+	b.digests = digests
+	b.changed = changed
+	logging.Debugf("🔍 %d of %d written files changed", len(changed), len(written))
+}
+
+// ChangedOutputs returns the files, relative to the public directory, whose
+// content changed in the last full or incremental build. It's empty unless
+// SetTrackChanges is on.
+func (b *Builder) ChangedOutputs() map[string]bool {
+	b.changesMu.Lock()
+	defer b.changesMu.Unlock()
+
+	changed := make(map[string]bool, len(b.changed))
+	for relPath := range b.changed {
+		changed[relPath] = true
+	}
+	return changed
+}
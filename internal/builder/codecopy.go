@@ -0,0 +1,26 @@
+package builder
+
+import (
+	"strings"
+
+	"vango/internal/content"
+)
+
+// codeCopyPartial adds a copy button to every code block. The built-in one
+// is used unless the theme or site has its own.
+const codeCopyPartial = "partials/code-copy"
+
+// injectCodeCopy adds the code copy partial before </body> on pages with a
+// code block, when the theme enables the copy_code feature and the
+// templates haven't placed it themselves
+func (b *Builder) injectCodeCopy(html string, page *content.Page) (string, error) {
+	if !b.themeManager.HasFeature("copy_code") || !strings.Contains(html, "<pre><code") ||
+		!strings.Contains(html, "</body>") || strings.Contains(html, "data-vango-code-copy") {
+		return html, nil
+	}
+	script, err := b.engine.RenderPartial(codeCopyPartial, page)
+	if err != nil {
+		return html, err
+	}
+	return strings.Replace(html, "</body>", script+"\n</body>", 1), nil
+}
@@ -0,0 +1,195 @@
+package builder
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+
+	"vango/internal/util"
+)
+
+// compressibleExts are the output types worth precompressing; images,
+// fonts and archives are compressed already
+var compressibleExts = map[string]bool{
+	".html":        true,
+	".htm":         true,
+	".css":         true,
+	".js":          true,
+	".mjs":         true,
+	".json":        true,
+	".xml":         true,
+	".svg":         true,
+	".txt":         true,
+	".map":         true,
+	".webmanifest": true,
+	".wasm":        true,
+	".csv":         true,
+}
+
+// brotliLevel gives up a few percent of the best brotli ratio for an order of
+// magnitude faster compression; level 11 dominates build times on large sites
+const brotliLevel = 6
+
+// compressionExts maps the configured algorithms to variant extensions
+var compressionExts = map[string]string{
+	"gzip": ".gz",
+	"br":   ".br",
+}
+
+// CompressionReport summarizes the precompressed variants of a build
+type CompressionReport struct {
+	Files      int              `json:"files"`
+	Original   int64            `json:"original_bytes"`
+	Compressed map[string]int64 `json:"compressed_bytes"` // By algorithm
+}
+
+// Ratio returns the compressed size of an algorithm's variants as a
+// fraction of the original size
+func (r *CompressionReport) Ratio(algorithm string) float64 {
+	if r.Original == 0 {
+		return 0
+	}
+	return float64(r.Compressed[algorithm]) / float64(r.Original)
+}
+
+// SetPrecompress turns writing precompressed variants on or off; the
+// development server compresses responses on the fly instead
+func (b *Builder) SetPrecompress(precompress bool) {
+	b.precompress = precompress
+}
+
+// compressOutputs writes a precompressed variant for each configured
+// algorithm next to every compressible output file of at least the
+// configured minimum size, so servers can serve them without compressing
+// on every request. Variants newer than their source are kept as they are.
+func (b *Builder) compressOutputs() (*CompressionReport, error) {
+	if !b.precompress || !b.config.Performance.EnableCompression {
+		return nil, nil
+	}
+	settings := b.config.Performance.Compression
+	if len(settings.Algorithms) == 0 {
+		return nil, nil
+	}
+
+	var files []string
+	for _, out := range b.outputList() {
+		if compressibleExts[strings.ToLower(path.Ext(out))] {
+			files = append(files, out)
+		}
+	}
+
+	report := &CompressionReport{Compressed: make(map[string]int64)}
+	var mu sync.Mutex
+	var firstErr error
+	fileChan := make(chan string, len(files))
+	var wg sync.WaitGroup
+	for i := 0; i < b.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for out := range fileChan {
+				sizes, original, err := b.compressOutput(out, settings.Algorithms, int64(settings.MinSize))
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				if sizes != nil {
+					report.Files++
+					report.Original += original
+					for algorithm, size := range sizes {
+						report.Compressed[algorithm] += size
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, out := range files {
+		fileChan <- out
+	}
+	close(fileChan)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return report, nil
+}
+
+// compressOutput writes the variants of one output file and returns their
+// sizes by algorithm with the original size, or nil sizes when the file is
+// below minSize
+func (b *Builder) compressOutput(out string, algorithms []string, minSize int64) (map[string]int64, int64, error) {
+	src := util.OutputPath(b.outputDir, out)
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to stat %s: %w", out, err)
+	}
+	if info.Size() < minSize {
+		return nil, 0, nil
+	}
+
+	sizes := make(map[string]int64, len(algorithms))
+	for _, algorithm := range algorithms {
+		variant := out + compressionExts[algorithm]
+		dst := util.OutputPath(b.outputDir, variant)
+		if existing, err := os.Stat(dst); err == nil && !existing.ModTime().Before(info.ModTime()) {
+			sizes[algorithm] = existing.Size()
+			b.recordOutput(variant)
+			continue
+		}
+		size, err := compressFile(src, dst, algorithm)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to compress %s: %w", out, err)
+		}
+		sizes[algorithm] = size
+		b.recordOutput(variant)
+	}
+	return sizes, info.Size(), nil
+}
+
+// compressFile writes src compressed with algorithm to dst and returns the
+// compressed size
+func compressFile(src, dst, algorithm string) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	var w io.WriteCloser
+	switch algorithm {
+	case "gzip":
+		w, err = gzip.NewWriterLevel(out, gzip.BestCompression)
+		if err != nil {
+			return 0, err
+		}
+	case "br":
+		w = brotli.NewWriterLevel(out, brotliLevel)
+	default:
+		return 0, fmt.Errorf("unknown compression algorithm %q", algorithm)
+	}
+	if _, err := io.Copy(w, in); err != nil {
+		return 0, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+	info, err := out.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
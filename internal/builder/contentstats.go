@@ -0,0 +1,24 @@
+package builder
+
+import (
+	"fmt"
+
+	"vango/internal/content"
+)
+
+// ContentStats parses the content, without rendering anything, and sums
+// up the regular pages, only those of section when it isn't empty
+func (b *Builder) ContentStats(section string) (*content.SiteStats, error) {
+	b.setupTheme()
+	b.resetCache()
+	if err := b.parseContentParallel(); err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+	return content.ComputeStats(b.pages, section), nil
+}
+
+// buildStats computes .Site.Stats once per build, after the pages are
+// parsed and before any renders
+func (b *Builder) buildStats() {
+	b.engine.SetStats(content.ComputeStats(b.pages, ""))
+}
@@ -0,0 +1,77 @@
+package builder
+
+import (
+	"strings"
+
+	"vango/internal/content"
+	"vango/internal/template"
+)
+
+// ResolveTemplate finds the page served at a URL path and explains which
+// template renders it. Pages from the last build are preferred; otherwise
+// the content file is parsed on the spot.
+func (b *Builder) ResolveTemplate(urlPath string) (*content.Page, *template.TemplateResolution, error) {
+	page := b.pageForPath(urlPath)
+	if page == nil {
+		filePath, err := b.contentFileForPath(urlPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		page, err = b.parser.ParseFile(filePath, b.config.ContentDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		b.applySectionConfig(page)
+		b.setPermalink(page)
+	}
+	return page, b.engine.ResolveTemplate(page), nil
+}
+
+// TemplateSources lists every loaded template with the file that defined it
+func (b *Builder) TemplateSources() []template.TemplateSource {
+	return b.engine.TemplateSources()
+}
+
+// pageForPath returns the built page whose URL matches urlPath
+func (b *Builder) pageForPath(urlPath string) *content.Page {
+	want := strings.Trim(urlPath, "/")
+	for _, page := range b.GetPages() {
+		if strings.Trim(page.URL, "/") == want {
+			return page
+		}
+	}
+	for _, page := range b.taxonomyPages {
+		if strings.Trim(page.URL, "/") == want {
+			return page
+		}
+	}
+	return nil
+}
+
+// TemplateFuncs describes every template function, including the builder's
+// own such as ref and scss
+func (b *Builder) TemplateFuncs() []template.FuncInfo {
+	return b.engine.Funcs()
+}
+
+// ExecTemplate runs a template snippet against the page parsed from
+// filePath, or against an empty page when filePath is "". Call
+// PrepareTemplates first to make partials available to the snippet.
+func (b *Builder) ExecTemplate(snippet, filePath string) (string, error) {
+	page := &content.Page{
+		Kind:        "page",
+		Params:      make(map[string]interface{}),
+		FrontMatter: make(map[string]interface{}),
+	}
+	if filePath != "" {
+		parsed, err := b.parser.ParseFile(filePath, b.config.ContentDir)
+		if err != nil {
+			return "", err
+		}
+		b.applySectionConfig(parsed)
+		b.setPermalink(parsed)
+		page = parsed
+	}
+	b.updateBuildInfo()
+	return b.engine.Execute(snippet, page, b.GetPages())
+}
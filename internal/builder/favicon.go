@@ -0,0 +1,263 @@
+package builder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/disintegration/imaging"
+
+	"vango/internal/logging"
+	"vango/internal/svgicon"
+)
+
+// faviconVersion changes with the icons generated from a source, so icons
+// cached by an older VanGo are made again
+const faviconVersion = "2"
+
+// faviconICOSizes are the sizes inside favicon.ico
+var faviconICOSizes = []int{16, 32, 48}
+
+// faviconIcons are the PNG icons generated next to favicon.ico
+var faviconIcons = []struct {
+	name string
+	size int
+}{
+	{"favicon-16x16.png", 16},
+	{"favicon-32x32.png", 32},
+	{"apple-touch-icon.png", 180},
+	{"android-chrome-192x192.png", 192},
+	{"android-chrome-512x512.png", 512},
+}
+
+// faviconSources generates the icons from favicon.source, unless they are
+// cached for its content already, and returns them for the asset stage
+// with site.webmanifest, and favicon.svg for an SVG source
+func (b *Builder) faviconSources() ([]assetSource, error) {
+	fc := b.config.Favicon
+	if fc.Source == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(fc.Source)
+	if err != nil {
+		return nil, fmt.Errorf("favicon: %w", err)
+	}
+	isSVG := strings.EqualFold(filepath.Ext(fc.Source), ".svg")
+	background := fc.BackgroundColor
+	if background == "" {
+		background = "#ffffff"
+	}
+	sum := sha256.Sum256([]byte(faviconVersion + "\n" + background + "\n" + string(data)))
+	dir := filepath.Join(b.faviconCacheDir(), hex.EncodeToString(sum[:])[:16])
+
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		start := time.Now()
+		if err := writeFavicons(dir, data, isSVG, background); err != nil {
+			return nil, fmt.Errorf("favicon: %s: %w", fc.Source, err)
+		}
+		logging.Infof("⭐ Generated favicons from %s in %v", fc.Source, time.Since(start))
+	} else {
+		logging.Debugf("Favicons from %s are cached in %s", fc.Source, dir)
+	}
+
+	sources := []assetSource{{dst: "favicon.ico", src: filepath.Join(dir, "favicon.ico")}}
+	for _, icon := range faviconIcons {
+		sources = append(sources, assetSource{dst: icon.name, src: filepath.Join(dir, icon.name)})
+	}
+	if isSVG {
+		sources = append(sources, assetSource{dst: "favicon.svg", src: fc.Source})
+	}
+	manifest, err := b.webManifest()
+	if err != nil {
+		return nil, err
+	}
+	return append(sources, assetSource{dst: "site.webmanifest", data: manifest, desc: "web app manifest"}), nil
+}
+
+// faviconCacheDir returns the directory generated icons are cached in, a
+// directory for each source
+func (b *Builder) faviconCacheDir() string {
+	if b.config.Performance.CacheDir == "" {
+		return filepath.Join(".cache", "favicons")
+	}
+	return filepath.Join(b.config.Performance.CacheDir, "favicons")
+}
+
+// writeFavicons renders the icons into dir. They are written to a
+// temporary directory renamed into place, so a cached set is complete.
+func writeFavicons(dir string, data []byte, isSVG bool, background string) error {
+	var src image.Image
+	if !isSVG {
+		img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+		if err != nil {
+			return err
+		}
+		src = img
+	}
+	if isSVG {
+		if names := svgicon.Unsupported(data); len(names) > 0 {
+			logging.Warnf("⚠️  Warning: favicon source uses <%s>, which can't be rasterized; the icons are drawn without it", strings.Join(names, ">, <"))
+		}
+	}
+	render := func(size int) (image.Image, error) {
+		if isSVG {
+			return svgicon.Rasterize(data, size)
+		}
+		icon := imaging.New(size, size, color.Transparent)
+		return imaging.PasteCenter(icon, imaging.Fit(src, size, size, imaging.Lanczos)), nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, icon := range faviconIcons {
+		img, err := render(icon.size)
+		if err != nil {
+			return err
+		}
+		// iOS shows transparency in home screen icons as black
+		if icon.name == "apple-touch-icon.png" {
+			bg, ok := svgicon.ParseColor(background)
+			if !ok {
+				bg = color.Transparent
+			}
+			img = imaging.Overlay(imaging.New(icon.size, icon.size, bg), img, image.Point{}, 1)
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(tmp, icon.name), buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+
+	var images [][]byte
+	for _, size := range faviconICOSizes {
+		img, err := render(size)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		images = append(images, buf.Bytes())
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "favicon.ico"), encodeICO(faviconICOSizes, images), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dir)
+}
+
+// encodeICO packs PNG images of the given sizes into an ICO file, which
+// every browser since IE 11 reads
+func encodeICO(sizes []int, images [][]byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, [3]uint16{0, 1, uint16(len(images))})
+	offset := 6 + 16*len(images)
+	for i, data := range images {
+		dim := uint8(sizes[i] % 256) // 0 stands for 256
+		binary.Write(&buf, binary.LittleEndian, struct {
+			Width, Height, Colors, Reserved uint8
+			Planes, BitCount                uint16
+			Size, Offset                    uint32
+		}{dim, dim, 0, 0, 1, 32, uint32(len(data)), uint32(offset)})
+		offset += len(data)
+	}
+	for _, data := range images {
+		buf.Write(data)
+	}
+	return buf.Bytes()
+}
+
+// webManifest returns site.webmanifest, naming the site and listing the
+// Android icons
+func (b *Builder) webManifest() ([]byte, error) {
+	fc := b.config.Favicon
+	type manifestIcon struct {
+		Src   string `json:"src"`
+		Sizes string `json:"sizes"`
+		Type  string `json:"type"`
+	}
+	manifest := struct {
+		Name            string         `json:"name"`
+		ShortName       string         `json:"short_name"`
+		Icons           []manifestIcon `json:"icons"`
+		StartURL        string         `json:"start_url"`
+		ThemeColor      string         `json:"theme_color,omitempty"`
+		BackgroundColor string         `json:"background_color,omitempty"`
+		Display         string         `json:"display"`
+	}{
+		Name:            fc.Name,
+		ShortName:       fc.ShortName,
+		StartURL:        b.config.RelURL("/"),
+		ThemeColor:      fc.ThemeColor,
+		BackgroundColor: fc.BackgroundColor,
+		Display:         "standalone",
+	}
+	if manifest.Name == "" {
+		manifest.Name = b.config.Title
+	}
+	if manifest.ShortName == "" {
+		manifest.ShortName = manifest.Name
+	}
+	for _, icon := range faviconIcons {
+		if strings.HasPrefix(icon.name, "android-chrome-") {
+			manifest.Icons = append(manifest.Icons, manifestIcon{
+				Src:   b.config.RelURL("/" + icon.name),
+				Sizes: fmt.Sprintf("%dx%d", icon.size, icon.size),
+				Type:  "image/png",
+			})
+		}
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// faviconTags is the "faviconTags" template function: the link and meta
+// tags for the generated icons, or nothing when favicon.source isn't set
+func (b *Builder) faviconTags() template.HTML {
+	fc := b.config.Favicon
+	if fc.Source == "" {
+		return ""
+	}
+	url := func(name string) string {
+		return html.EscapeString(b.config.RelURL("/" + name))
+	}
+	tags := []string{fmt.Sprintf(`<link rel="icon" href="%s" sizes="48x48">`, url("favicon.ico"))}
+	if strings.EqualFold(filepath.Ext(fc.Source), ".svg") {
+		tags = append(tags, fmt.Sprintf(`<link rel="icon" href="%s" type="image/svg+xml">`, url("favicon.svg")))
+	}
+	tags = append(tags,
+		fmt.Sprintf(`<link rel="icon" type="image/png" sizes="32x32" href="%s">`, url("favicon-32x32.png")),
+		fmt.Sprintf(`<link rel="icon" type="image/png" sizes="16x16" href="%s">`, url("favicon-16x16.png")),
+		fmt.Sprintf(`<link rel="apple-touch-icon" sizes="180x180" href="%s">`, url("apple-touch-icon.png")),
+		fmt.Sprintf(`<link rel="manifest" href="%s">`, url("site.webmanifest")),
+	)
+	if fc.ThemeColor != "" {
+		tags = append(tags, fmt.Sprintf(`<meta name="theme-color" content="%s">`, html.EscapeString(fc.ThemeColor)))
+	}
+	return template.HTML(strings.Join(tags, "\n"))
+}
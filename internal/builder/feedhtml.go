@@ -0,0 +1,178 @@
+package builder
+
+import (
+	"html"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"vango/internal/content"
+	"vango/internal/util"
+)
+
+var (
+	// tagPattern matches start and end tags, capturing the slash, the name
+	// and the attributes
+	tagPattern = regexp.MustCompile(`(?s)<(/?)([a-zA-Z][a-zA-Z0-9-]*)([^>]*)>`)
+	// urlAttrPattern matches the attributes holding a URL or a srcset
+	urlAttrPattern = regexp.MustCompile(`(?i)(\s(href|src|poster|srcset)\s*=\s*)(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// feedHTML prepares a page's HTML for readers outside the site, such as
+// feed readers and search indexes: elements matching feeds.remove are
+// replaced by feeds.replacement, and relative URLs are made absolute
+// against the page so images in page bundles keep working
+func (b *Builder) feedHTML(page *content.Page, s template.HTML) template.HTML {
+	out := removeElements(string(s), b.config.Feeds.Remove, b.config.Feeds.Replacement)
+	if page != nil {
+		base := page.Permalink
+		if base == "" {
+			base = b.config.AbsURL(page.URL)
+		}
+		out = absoluteURLs(out, base)
+	}
+	return template.HTML(out)
+}
+
+// absoluteURLs resolves the URLs in the tags of s against base. Text,
+// including escaped markup in code blocks, is left alone.
+func absoluteURLs(s, base string) string {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return s
+	}
+	resolve := func(ref string) string {
+		ref = strings.TrimSpace(ref)
+		u, err := url.Parse(ref)
+		if err != nil || ref == "" {
+			return ref
+		}
+		return baseURL.ResolveReference(u).String()
+	}
+
+	return tagPattern.ReplaceAllStringFunc(s, func(tag string) string {
+		if strings.HasPrefix(tag, "</") {
+			return tag
+		}
+		return urlAttrPattern.ReplaceAllStringFunc(tag, func(attr string) string {
+			match := urlAttrPattern.FindStringSubmatch(attr)
+			value := html.UnescapeString(match[3] + match[4] + match[5])
+			if strings.EqualFold(match[2], "srcset") {
+				candidates := strings.Split(value, ",")
+				for i, candidate := range candidates {
+					fields := strings.Fields(candidate)
+					if len(fields) > 0 {
+						fields[0] = resolve(fields[0])
+					}
+					candidates[i] = strings.Join(fields, " ")
+				}
+				value = strings.Join(candidates, ", ")
+			} else {
+				value = resolve(value)
+			}
+			return match[1] + `"` + html.EscapeString(value) + `"`
+		})
+	})
+}
+
+// elementSelector matches elements by tag name, class or both, as in
+// "iframe", ".embed" or "div.embed"
+type elementSelector struct {
+	tag   string
+	class string
+}
+
+// parseSelectors parses the feeds.remove selectors, skipping empty ones
+func parseSelectors(selectors []string) []elementSelector {
+	var parsed []elementSelector
+	for _, selector := range selectors {
+		tag, class, _ := strings.Cut(strings.TrimSpace(selector), ".")
+		if tag == "" && class == "" {
+			continue
+		}
+		parsed = append(parsed, elementSelector{tag: strings.ToLower(tag), class: class})
+	}
+	return parsed
+}
+
+// matches reports whether a start tag's name and attributes match
+func (s elementSelector) matches(name, attrs string) bool {
+	if s.tag != "" && s.tag != name {
+		return false
+	}
+	if s.class == "" {
+		return true
+	}
+	classes, _ := htmlAttr(attrs, "class")
+	for _, class := range strings.Fields(classes) {
+		if class == s.class {
+			return true
+		}
+	}
+	return false
+}
+
+// removeElements replaces each element matching one of selectors, with
+// everything inside it, by replacement. A start tag that is never closed
+// is replaced on its own.
+func removeElements(s string, selectors []string, replacement string) string {
+	parsed := parseSelectors(selectors)
+	if len(parsed) == 0 {
+		return s
+	}
+
+	tags := tagPattern.FindAllStringSubmatchIndex(s, -1)
+	var out strings.Builder
+	last := 0
+	for i := 0; i < len(tags); i++ {
+		tag := tags[i]
+		if s[tag[2]:tag[3]] == "/" {
+			continue
+		}
+		name := strings.ToLower(s[tag[4]:tag[5]])
+		attrs := s[tag[6]:tag[7]]
+		matched := false
+		for _, selector := range parsed {
+			if selector.matches(name, attrs) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		end := tag[1]
+		if !util.IsVoidElement(name) && !strings.HasSuffix(strings.TrimSpace(attrs), "/") {
+			if j := closingTag(s, tags, i, name); j > 0 {
+				end = tags[j][1]
+				i = j
+			}
+		}
+		out.WriteString(s[last:tag[0]])
+		out.WriteString(replacement)
+		last = end
+	}
+	out.WriteString(s[last:])
+	return out.String()
+}
+
+// closingTag returns the index in tags of the end tag closing the element
+// started by tags[start], or -1
+func closingTag(s string, tags [][]int, start int, name string) int {
+	depth := 0
+	for j := start + 1; j < len(tags); j++ {
+		if !strings.EqualFold(s[tags[j][4]:tags[j][5]], name) {
+			continue
+		}
+		if s[tags[j][2]:tags[j][3]] != "/" {
+			depth++
+		} else if depth == 0 {
+			return j
+		} else {
+			depth--
+		}
+	}
+	return -1
+}
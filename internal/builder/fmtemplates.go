@@ -0,0 +1,57 @@
+package builder
+
+import (
+	"strings"
+	"text/template"
+	"time"
+
+	"vango/internal/config"
+	"vango/internal/content"
+	"vango/internal/util"
+)
+
+// FrontMatterExpander returns what evaluates front matter templates when
+// frontMatterTemplates is on, or nil. Values see the site configuration as
+// .Site and only functions that can't read files or reach the network.
+func FrontMatterExpander(cfg *config.Config) content.FrontMatterExpander {
+	if !cfg.FrontMatterTemplates {
+		return nil
+	}
+	funcs := template.FuncMap{
+		"now": func() time.Time {
+			return cfg.Now().In(cfg.GetLocation())
+		},
+		"dateFormat": func(layout string, date time.Time) string {
+			return date.Format(layout)
+		},
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"title": strings.Title,
+		"trim":  strings.TrimSpace,
+		"replace": func(old, new, s string) string {
+			return strings.ReplaceAll(s, old, new)
+		},
+		"default": func(defaultValue, value interface{}) interface{} {
+			if value == nil || value == "" {
+				return defaultValue
+			}
+			return value
+		},
+		"slugify": util.Slugify,
+		"relURL":  cfg.RelURL,
+		"absURL":  cfg.AbsURL,
+	}
+	data := struct{ Site *config.Config }{cfg}
+
+	return func(key, value string) (string, error) {
+		tmpl, err := template.New(key).Funcs(funcs).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return "", err
+		}
+		var out strings.Builder
+		if err := tmpl.Execute(&out, data); err != nil {
+			return "", err
+		}
+		return out.String(), nil
+	}
+}
@@ -0,0 +1,283 @@
+package builder
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"vango/internal/content"
+)
+
+// GraphNode is a page in the content graph
+type GraphNode struct {
+	URL       string   `json:"url"` // Site-relative, and the node's ID in edges
+	Title     string   `json:"title"`
+	Kind      string   `json:"kind"`
+	Section   string   `json:"section,omitempty"`
+	WordCount int      `json:"word_count"`
+	Tags      []string `json:"tags,omitempty"`
+	Inbound   int      `json:"inbound"`  // Pages linking here
+	Outbound  int      `json:"outbound"` // Pages linked from here
+}
+
+// GraphEdge connects two pages: a link from one to the other, or tags they
+// share. A broken link's target is the link as written in the page.
+type GraphEdge struct {
+	From   string   `json:"from"`
+	To     string   `json:"to"`
+	Type   string   `json:"type"` // "link" or "tag"
+	Count  int      `json:"count,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Broken bool     `json:"broken,omitempty"`
+}
+
+// ContentGraph is the site's pages and the connections between them
+type ContentGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// BrokenLinks returns the links that resolve to no page or file
+func (g *ContentGraph) BrokenLinks() []GraphEdge {
+	var broken []GraphEdge
+	for _, edge := range g.Edges {
+		if edge.Broken {
+			broken = append(broken, edge)
+		}
+	}
+	return broken
+}
+
+// ContentGraph parses the content, without rendering anything, and returns
+// the graph of its pages and internal links. Tag edges join every pair of
+// pages sharing a tag, which grows quickly with popular tags, so they are
+// only added when tagEdges is set.
+func (b *Builder) ContentGraph(tagEdges bool) (*ContentGraph, error) {
+	b.setupTheme()
+	b.resetCache()
+	if err := b.parseContentParallel(); err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+	b.sortPages()
+	b.linkSections()
+	b.buildTaxonomies()
+	b.buildSections()
+	b.buildRefIndex()
+
+	var pages []*content.Page
+	for _, page := range b.renderedPages() {
+		if b.rendersHTML(page) {
+			pages = append(pages, page)
+		}
+	}
+
+	// A ref to a missing page keeps its target, so it shows up as a broken
+	// link instead of failing the export
+	for _, page := range pages {
+		page.ResolveRefs(func(kind, target string, from *content.Page) (string, error) {
+			if resolved, err := b.resolveRef(kind, target, from); err == nil {
+				return resolved, nil
+			}
+			return target, nil
+		})
+	}
+
+	g := &ContentGraph{Nodes: make([]GraphNode, 0, len(pages)), Edges: []GraphEdge{}}
+	index := make(map[string]int, len(pages))
+	for _, page := range pages {
+		index[graphKey(page.URL)] = len(g.Nodes)
+		g.Nodes = append(g.Nodes, GraphNode{
+			URL:       page.URL,
+			Title:     page.Title,
+			Kind:      page.Kind,
+			Section:   page.Section,
+			WordCount: page.WordCount,
+			Tags:      page.Tags,
+		})
+	}
+
+	g.addLinkEdges(b, pages, index)
+	if tagEdges {
+		g.addTagEdges()
+	}
+	return g, nil
+}
+
+// addLinkEdges adds an edge for each page's internal links, one per target
+// however often it is linked
+func (g *ContentGraph) addLinkEdges(b *Builder, pages []*content.Page, index map[string]int) {
+	base, err := url.Parse(b.config.BaseURL)
+	if err != nil {
+		base = &url.URL{}
+	}
+	basePath := "/" + strings.Trim(base.Path, "/")
+	files := b.publishedFiles(pages)
+
+	for i, page := range pages {
+		from := &g.Nodes[i]
+		pageDir := strings.TrimSuffix(page.URL, "/")
+		if !strings.HasSuffix(page.URL, "/") {
+			pageDir = path.Dir(page.URL)
+		}
+
+		edges := make(map[string]*GraphEdge)
+		var order []string
+		for _, link := range page.Links {
+			target, ok := internalTarget(link.URL, pageDir, base, basePath)
+			if !ok {
+				continue
+			}
+			edge := GraphEdge{From: page.URL, Type: "link"}
+			if n, ok := index[graphKey(target)]; ok {
+				edge.To = g.Nodes[n].URL
+			} else if files[target] || outputExists(b.config.PublicDir, target) {
+				// Images, downloads and other files aren't part of the graph
+				continue
+			} else {
+				edge.To = link.URL
+				edge.Broken = true
+			}
+
+			if existing, ok := edges[edge.To]; ok {
+				existing.Count++
+				continue
+			}
+			edge.Count = 1
+			edges[edge.To] = &edge
+			order = append(order, edge.To)
+		}
+
+		for _, to := range order {
+			edge := edges[to]
+			g.Edges = append(g.Edges, *edge)
+			if edge.Broken || edge.To == page.URL {
+				continue
+			}
+			from.Outbound++
+			g.Nodes[index[graphKey(edge.To)]].Inbound++
+		}
+	}
+}
+
+// addTagEdges adds an undirected edge for each pair of pages sharing tags
+func (g *ContentGraph) addTagEdges() {
+	tagged := make(map[string][]int)
+	var tags []string
+	for i, node := range g.Nodes {
+		if node.Kind != "page" {
+			continue
+		}
+		for _, tag := range node.Tags {
+			if _, ok := tagged[tag]; !ok {
+				tags = append(tags, tag)
+			}
+			tagged[tag] = append(tagged[tag], i)
+		}
+	}
+	sort.Strings(tags)
+
+	type pair struct{ a, b int }
+	shared := make(map[pair][]string)
+	var pairs []pair
+	for _, tag := range tags {
+		members := tagged[tag]
+		for i := 0; i < len(members); i++ {
+			for j := i + 1; j < len(members); j++ {
+				if members[i] == members[j] {
+					continue
+				}
+				p := pair{members[i], members[j]}
+				if _, ok := shared[p]; !ok {
+					pairs = append(pairs, p)
+				}
+				shared[p] = append(shared[p], tag)
+			}
+		}
+	}
+
+	for _, p := range pairs {
+		g.Edges = append(g.Edges, GraphEdge{
+			From: g.Nodes[p.a].URL,
+			To:   g.Nodes[p.b].URL,
+			Type: "tag",
+			Tags: shared[p],
+		})
+	}
+}
+
+// publishedFiles returns the output paths of the static, theme and bundle
+// files, which links may point to without naming a page
+func (b *Builder) publishedFiles(pages []*content.Page) map[string]bool {
+	files := make(map[string]bool)
+	if sources, err := b.staticSources(); err == nil {
+		for _, source := range sources {
+			files[source.dst] = true
+		}
+	}
+	files[themeVariablesFile] = true
+	for _, page := range pages {
+		for _, resource := range page.Resources {
+			files[resource.PublishPath] = true
+		}
+	}
+	return files
+}
+
+// graphKey normalizes a page URL or output path for matching links to pages
+func graphKey(p string) string {
+	p = strings.Trim(p, "/")
+	p = strings.TrimSuffix(p, "index.html")
+	return strings.Trim(p, "/")
+}
+
+// WriteDOT writes the graph in Graphviz DOT format. Broken links point to
+// red boxes named after the link; tag edges are dotted and undirected.
+func (g *ContentGraph) WriteDOT(w io.Writer) error {
+	out := &errWriter{w: w}
+	out.printf("digraph content {\n")
+	out.printf("  node [shape=ellipse];\n")
+	for _, node := range g.Nodes {
+		out.printf("  %s [label=%s];\n", dotID(node.URL), dotID(node.Title))
+	}
+
+	broken := make(map[string]bool)
+	for _, edge := range g.Edges {
+		if edge.Broken && !broken[edge.To] {
+			broken[edge.To] = true
+			out.printf("  %s [shape=box, color=red, fontcolor=red];\n", dotID(edge.To))
+		}
+	}
+
+	for _, edge := range g.Edges {
+		switch {
+		case edge.Type == "tag":
+			out.printf("  %s -> %s [dir=none, style=dotted, label=%s];\n", dotID(edge.From), dotID(edge.To), dotID(strings.Join(edge.Tags, ", ")))
+		case edge.Broken:
+			out.printf("  %s -> %s [color=red, style=dashed];\n", dotID(edge.From), dotID(edge.To))
+		default:
+			out.printf("  %s -> %s;\n", dotID(edge.From), dotID(edge.To))
+		}
+	}
+	out.printf("}\n")
+	return out.err
+}
+
+// dotID quotes s as a DOT identifier
+func dotID(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s) + `"`
+}
+
+// errWriter keeps the first write error so formatted output can be checked once
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) printf(format string, args ...interface{}) {
+	if e.err == nil {
+		_, e.err = fmt.Fprintf(e.w, format, args...)
+	}
+}
@@ -0,0 +1,196 @@
+package builder
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"sort"
+	"strings"
+
+	"vango/internal/content"
+)
+
+// preloadTypes are the destinations a preload's as attribute may name
+var preloadTypes = map[string]bool{
+	"audio": true, "document": true, "embed": true, "fetch": true, "font": true, "image": true,
+	"object": true, "script": true, "style": true, "track": true, "video": true, "worker": true,
+}
+
+var (
+	// linkTagPattern matches link tags with their attributes
+	linkTagPattern = regexp.MustCompile(`(?is)<link\b([^>]*)>`)
+	// resourceHintRels are the link relations deduplicated per page
+	resourceHintRels = map[string]bool{"preload": true, "prefetch": true, "preconnect": true, "dns-prefetch": true, "modulepreload": true}
+)
+
+// preload is the "preload" template function: a preload hint for url,
+// fetched as the given destination, e.g. {{ preload (scss "main.scss") "style" }}.
+// Fonts and fetches are requested with CORS, as browsers require for them.
+func (b *Builder) preload(url, as string) (template.HTML, error) {
+	as = strings.ToLower(strings.TrimSpace(as))
+	if !preloadTypes[as] {
+		return "", fmt.Errorf("preload %s: invalid as %q (use one of %s)", url, as, strings.Join(sortedHintTypes(), ", "))
+	}
+	tag := fmt.Sprintf(`<link rel="preload" href="%s" as="%s"`, html.EscapeString(url), as)
+	if as == "font" || as == "fetch" {
+		tag += " crossorigin"
+	}
+	return template.HTML(tag + ">"), nil
+}
+
+// sortedHintTypes lists the valid preload destinations
+func sortedHintTypes() []string {
+	types := make([]string, 0, len(preloadTypes))
+	for as := range preloadTypes {
+		types = append(types, as)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// prefetchNext is the "prefetchNext" template function: a prefetch hint
+// for the next page in the page's section, the one readers likely open
+// next, or nothing for the last page
+func (b *Builder) prefetchNext(page *content.Page) template.HTML {
+	if page == nil || page.NextInSection == nil {
+		return ""
+	}
+	return template.HTML(fmt.Sprintf(`<link rel="prefetch" href="%s">`, html.EscapeString(page.NextInSection.RelPermalink)))
+}
+
+// applyResourceHints preloads the page's main stylesheet when asset
+// bundling is on and drops repeated resource hints, which templates and
+// partials easily emit twice. It returns the page and its preloads, for
+// Link headers.
+func (b *Builder) applyResourceHints(page string) (string, []string) {
+	bundling := b.config.Performance.AssetBundling
+	if bundling.Enable && bundling.CSS {
+		page = injectStylesheetPreload(page)
+	}
+
+	seen := make(map[string]bool)
+	var preloads []string
+	page = linkTagPattern.ReplaceAllStringFunc(page, func(tag string) string {
+		attrs := linkTagPattern.FindStringSubmatch(tag)[1]
+		rel, _ := htmlAttr(attrs, "rel")
+		rel = strings.ToLower(strings.TrimSpace(rel))
+		if !resourceHintRels[rel] {
+			return tag
+		}
+		href, _ := htmlAttr(attrs, "href")
+		key := rel + " " + href
+		if seen[key] {
+			return ""
+		}
+		seen[key] = true
+		if rel == "preload" {
+			preloads = append(preloads, linkHeader(attrs, href))
+		}
+		return tag
+	})
+	return page, preloads
+}
+
+// injectStylesheetPreload adds a preload hint for the first local
+// stylesheet right after the opening <head> tag, so it is fetched before
+// anything else in the head
+func injectStylesheetPreload(page string) string {
+	loc := headTag.FindStringIndex(page)
+	if loc == nil {
+		return page
+	}
+	for _, match := range linkTagPattern.FindAllStringSubmatch(page, -1) {
+		attrs := match[1]
+		if rel, _ := htmlAttr(attrs, "rel"); !strings.EqualFold(strings.TrimSpace(rel), "stylesheet") {
+			continue
+		}
+		href, _ := htmlAttr(attrs, "href")
+		if href == "" || isExternalURL(href) {
+			continue
+		}
+		hint := fmt.Sprintf(`<link rel="preload" href="%s" as="style">`, html.EscapeString(href))
+		return page[:loc[1]] + "\n    " + hint + page[loc[1]:]
+	}
+	return page
+}
+
+// isExternalURL reports whether href points at another host
+func isExternalURL(href string) bool {
+	return strings.HasPrefix(href, "//") || strings.Contains(href, "://")
+}
+
+// linkHeader returns the Link header value for a preload tag
+func linkHeader(attrs, href string) string {
+	header := "<" + href + ">; rel=preload"
+	if as, ok := htmlAttr(attrs, "as"); ok {
+		header += "; as=" + as
+	}
+	if _, ok := htmlAttr(attrs, "crossorigin"); ok {
+		header += "; crossorigin"
+	}
+	return header
+}
+
+// recordLinkHeaders notes a page's preloads for the _headers file
+func (b *Builder) recordLinkHeaders(url string, preloads []string) {
+	b.linkHeadersMu.Lock()
+	defer b.linkHeadersMu.Unlock()
+	if len(preloads) == 0 {
+		delete(b.linkHeaders, url)
+		return
+	}
+	if b.linkHeaders == nil {
+		b.linkHeaders = make(map[string][]string)
+	}
+	b.linkHeaders[url] = preloads
+}
+
+// resetLinkHeaders forgets the preloads of the previous build
+func (b *Builder) resetLinkHeaders() {
+	b.linkHeadersMu.Lock()
+	b.linkHeaders = nil
+	b.linkHeadersMu.Unlock()
+}
+
+// writeLinkHeaders writes a _headers file sending each page's preloads as
+// Link headers, which Netlify and Cloudflare Pages also turn into 103
+// Early Hints. It's written when performance.preloadHeaders is on and one
+// of those is a redirect target.
+func (b *Builder) writeLinkHeaders() error {
+	if !b.config.Performance.PreloadHeaders || !b.hasTarget("netlify", "cloudflare") {
+		return nil
+	}
+
+	b.linkHeadersMu.Lock()
+	urls := make([]string, 0, len(b.linkHeaders))
+	for url := range b.linkHeaders {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	var out strings.Builder
+	for _, url := range urls {
+		out.WriteString(url + "\n")
+		for _, header := range b.linkHeaders[url] {
+			out.WriteString("  Link: " + header + "\n")
+		}
+	}
+	b.linkHeadersMu.Unlock()
+
+	if out.Len() == 0 {
+		return nil
+	}
+	return b.writeOutputFile("_headers", []byte(out.String()))
+}
+
+// hasTarget reports whether any of the hosting targets is configured
+func (b *Builder) hasTarget(targets ...string) bool {
+	for _, configured := range b.config.RedirectOptions.Targets {
+		for _, target := range targets {
+			if strings.EqualFold(configured, target) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,371 @@
+package builder
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"vango/internal/logging"
+)
+
+// HTML validation rules reported by CheckHTML, besides RuleDuplicateID
+const (
+	RuleUnclosedTag     = "unclosed-tag"
+	RuleStrayEndTag     = "stray-end-tag"
+	RuleListItem        = "li-outside-list"
+	RuleNestedForm      = "nested-form"
+	RuleNestedAnchor    = "nested-anchor"
+	RuleImageDimensions = "image-dimensions"
+)
+
+// HTMLIssue is a structural problem in a generated page's markup
+type HTMLIssue struct {
+	Page    string `json:"page"` // Page URL
+	Line    int    `json:"line"`
+	Heading string `json:"heading,omitempty"` // Nearest heading before the problem, to find it in the page
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// String formats the issue with where to find it
+func (i HTMLIssue) String() string {
+	where := fmt.Sprintf("%s line %d", i.Page, i.Line)
+	if i.Heading != "" {
+		where += fmt.Sprintf(" (under %q)", i.Heading)
+	}
+	return where + ": " + i.Message
+}
+
+// tagSet is a set of lowercase element names
+type tagSet map[string]bool
+
+func newTagSet(names ...string) tagSet {
+	set := make(tagSet, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+var (
+	// voidElements never have content or an end tag
+	voidElements = newTagSet("area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "param", "source", "track", "wbr")
+	// rawTextElements hold text that isn't parsed as markup
+	rawTextElements = newTagSet("script", "style", "textarea", "title")
+	// optionalEndElements may be left open for the parser to close
+	optionalEndElements = newTagSet("html", "head", "body", "p", "li", "dt", "dd", "option", "optgroup", "tr", "td", "th", "thead", "tbody", "tfoot", "colgroup", "caption", "rt", "rp")
+	// paragraphClosers are the start tags that close an open paragraph
+	paragraphClosers = newTagSet("address", "article", "aside", "blockquote", "details", "div", "dl", "fieldset", "figcaption", "figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hgroup", "hr", "main", "menu", "nav", "ol", "p", "pre", "section", "table", "ul")
+	// impliedEnds are the open elements a start tag closes
+	impliedEnds = map[string]tagSet{
+		"li":       newTagSet("li"),
+		"dt":       newTagSet("dt", "dd"),
+		"dd":       newTagSet("dt", "dd"),
+		"option":   newTagSet("option"),
+		"optgroup": newTagSet("option", "optgroup"),
+		"tr":       newTagSet("tr", "td", "th"),
+		"td":       newTagSet("td", "th"),
+		"th":       newTagSet("td", "th"),
+		"thead":    newTagSet("tr", "td", "th", "thead", "tbody", "tfoot", "caption", "colgroup"),
+		"tbody":    newTagSet("tr", "td", "th", "thead", "tbody", "tfoot", "caption", "colgroup"),
+		"tfoot":    newTagSet("tr", "td", "th", "thead", "tbody", "tfoot", "caption", "colgroup"),
+	}
+	// headingElements are the elements whose text locates an issue
+	headingElements = newTagSet("h1", "h2", "h3", "h4", "h5", "h6")
+)
+
+// openElement is an element whose end tag hasn't been seen yet
+type openElement struct {
+	name    string
+	line    int
+	heading string // Heading in effect where it was opened
+	inner   int    // Offset of its content
+}
+
+// htmlChecker walks a page's tags, tracking the open elements the way a
+// tolerant HTML5 parser would
+type htmlChecker struct {
+	page      string
+	doc       string
+	imageSize bool
+
+	stack   []openElement
+	ids     map[string]int // id -> line of its first use
+	heading string
+	issues  []HTMLIssue
+
+	line    int // Line at offset linePos
+	linePos int
+}
+
+// CheckHTML reports structural problems in a page's markup: elements left
+// open or closed out of order, end tags without a start tag, ids used twice,
+// list items outside a list, and nested forms and links. With imageSize it
+// also reports images without width and height attributes.
+func CheckHTML(page, doc string, imageSize bool) []HTMLIssue {
+	c := &htmlChecker{page: page, doc: doc, imageSize: imageSize, ids: make(map[string]int), line: 1}
+	c.run()
+	sort.SliceStable(c.issues, func(i, j int) bool { return c.issues[i].Line < c.issues[j].Line })
+	return c.issues
+}
+
+// lineAt returns the line of an offset, which never goes back between calls
+func (c *htmlChecker) lineAt(pos int) int {
+	c.line += strings.Count(c.doc[c.linePos:pos], "\n")
+	c.linePos = pos
+	return c.line
+}
+
+func (c *htmlChecker) add(line int, heading, rule, format string, args ...interface{}) {
+	c.issues = append(c.issues, HTMLIssue{Page: c.page, Line: line, Heading: heading, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *htmlChecker) run() {
+	doc := c.doc
+	for i := 0; i < len(doc); {
+		j := strings.IndexByte(doc[i:], '<')
+		if j < 0 {
+			break
+		}
+		pos := i + j
+		rest := doc[pos:]
+
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				return
+			}
+			i = pos + 4 + end + 3
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				return
+			}
+			i = pos + end + 1
+		case strings.HasPrefix(rest, "</"):
+			name := tagName(rest[2:])
+			end := strings.IndexByte(rest, '>')
+			if name == "" || end < 0 {
+				i = pos + 1
+				continue
+			}
+			c.endTag(name, pos)
+			i = pos + end + 1
+		default:
+			name := tagName(rest[1:])
+			if name == "" {
+				i = pos + 1
+				continue
+			}
+			end := tagEnd(rest)
+			if end < 0 {
+				return
+			}
+			attrs := rest[1+len(name) : end]
+			i = pos + end + 1
+			selfClosing := strings.HasSuffix(strings.TrimSpace(attrs), "/")
+			c.startTag(name, attrs, pos, i, selfClosing)
+
+			// Skip to the end tag of elements holding raw text
+			if rawTextElements[name] && !selfClosing {
+				close := indexFold(doc[i:], "</"+name)
+				if close < 0 {
+					i = len(doc)
+				} else {
+					i += close
+				}
+			}
+		}
+	}
+
+	for _, open := range c.stack {
+		if !optionalEndElements[open.name] {
+			c.add(open.line, open.heading, RuleUnclosedTag, "<%s> is never closed", open.name)
+		}
+	}
+}
+
+// startTag handles a start tag at pos whose content starts at inner
+func (c *htmlChecker) startTag(name, attrs string, pos, inner int, selfClosing bool) {
+	line := c.lineAt(pos)
+
+	// Close the elements this tag implicitly ends, as the parser would
+	ends := impliedEnds[name]
+	for n := len(c.stack); n > 0; n = len(c.stack) {
+		top := c.stack[n-1].name
+		if !ends[top] && !(top == "p" && (paragraphClosers[name] || ends != nil)) {
+			break
+		}
+		c.stack = c.stack[:n-1]
+	}
+
+	if id, ok := htmlAttr(attrs, "id"); ok && id != "" {
+		if first, seen := c.ids[id]; seen {
+			c.add(line, c.heading, RuleDuplicateID, "id %q is already used on line %d", id, first)
+		} else {
+			c.ids[id] = line
+		}
+	}
+
+	switch name {
+	case "li":
+		parent := ""
+		if n := len(c.stack); n > 0 {
+			parent = c.stack[n-1].name
+		}
+		if parent != "ul" && parent != "ol" && parent != "menu" {
+			if parent == "" {
+				c.add(line, c.heading, RuleListItem, "<li> is outside a list")
+			} else {
+				c.add(line, c.heading, RuleListItem, "<li> is outside a list, inside <%s>", parent)
+			}
+		}
+	case "form":
+		if open := c.open("form"); open != nil {
+			c.add(line, c.heading, RuleNestedForm, "<form> is nested in the form opened on line %d", open.line)
+		}
+	case "a":
+		if open := c.open("a"); open != nil {
+			c.add(line, c.heading, RuleNestedAnchor, "<a> is nested in the link opened on line %d", open.line)
+		}
+	case "img":
+		if c.imageSize {
+			_, hasWidth := htmlAttr(attrs, "width")
+			_, hasHeight := htmlAttr(attrs, "height")
+			if !hasWidth || !hasHeight {
+				src, _ := htmlAttr(attrs, "src")
+				c.add(line, c.heading, RuleImageDimensions, "image %q has no width and height", src)
+			}
+		}
+	}
+
+	if voidElements[name] || selfClosing {
+		return
+	}
+	c.stack = append(c.stack, openElement{name: name, line: line, heading: c.heading, inner: inner})
+}
+
+// endTag handles an end tag at pos
+func (c *htmlChecker) endTag(name string, pos int) {
+	if voidElements[name] {
+		return
+	}
+	line := c.lineAt(pos)
+
+	i := len(c.stack) - 1
+	for i >= 0 && c.stack[i].name != name {
+		i--
+	}
+	if i < 0 {
+		c.add(line, c.heading, RuleStrayEndTag, "</%s> has no open <%s> to close", name, name)
+		return
+	}
+	for _, open := range c.stack[i+1:] {
+		if !optionalEndElements[open.name] {
+			c.add(open.line, open.heading, RuleUnclosedTag, "<%s> is not closed before </%s> on line %d", open.name, name, line)
+		}
+	}
+	if headingElements[name] {
+		text := html.UnescapeString(anyTagPattern.ReplaceAllString(c.doc[c.stack[i].inner:pos], ""))
+		c.heading = strings.Join(strings.Fields(text), " ")
+	}
+	c.stack = c.stack[:i]
+}
+
+// open returns the innermost open element of a name, or nil
+func (c *htmlChecker) open(name string) *openElement {
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		if c.stack[i].name == name {
+			return &c.stack[i]
+		}
+	}
+	return nil
+}
+
+// tagName returns the lowercase element name at the start of s, or ""
+func tagName(s string) string {
+	n := 0
+	for n < len(s) {
+		ch := s[n]
+		letter := ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+		if !letter && (n == 0 || !(ch >= '0' && ch <= '9' || ch == '-' || ch == ':')) {
+			break
+		}
+		n++
+	}
+	return strings.ToLower(s[:n])
+}
+
+// tagEnd returns the offset of the '>' ending the tag that starts s,
+// skipping quoted attribute values, or -1
+func tagEnd(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch ch := s[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '>':
+			return i
+		}
+	}
+	return -1
+}
+
+// indexFold is strings.Index for an ASCII substr, ignoring case
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// recordHTMLIssues logs a rendered page's markup problems and keeps them
+// for the build report, replacing those of an earlier render
+func (b *Builder) recordHTMLIssues(url string, issues []HTMLIssue) {
+	for _, issue := range issues {
+		logging.Warnf("⚠️  HTML: %s", issue)
+	}
+	b.htmlIssuesMu.Lock()
+	defer b.htmlIssuesMu.Unlock()
+	if len(issues) == 0 {
+		delete(b.htmlIssues, url)
+		return
+	}
+	if b.htmlIssues == nil {
+		b.htmlIssues = make(map[string][]HTMLIssue)
+	}
+	b.htmlIssues[url] = issues
+}
+
+// resetHTMLIssues forgets the markup problems of the previous build
+func (b *Builder) resetHTMLIssues() {
+	b.htmlIssuesMu.Lock()
+	b.htmlIssues = nil
+	b.htmlIssuesMu.Unlock()
+}
+
+// HTMLIssues returns the markup problems found in the rendered pages when
+// htmlValidation is on, sorted by page and line
+func (b *Builder) HTMLIssues() []HTMLIssue {
+	b.htmlIssuesMu.Lock()
+	defer b.htmlIssuesMu.Unlock()
+	var issues []HTMLIssue
+	for _, pageIssues := range b.htmlIssues {
+		issues = append(issues, pageIssues...)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Page != issues[j].Page {
+			return issues[i].Page < issues[j].Page
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues
+}
@@ -0,0 +1,310 @@
+package builder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disintegration/imaging"
+
+	"vango/internal/content"
+	"vango/internal/logging"
+)
+
+// defaultImageQuality is the JPEG quality of processed images without a
+// q option
+const defaultImageQuality = 75
+
+// imageAnchors are the Fill anchors by name
+var imageAnchors = map[string]imaging.Anchor{
+	"center":      imaging.Center,
+	"top":         imaging.Top,
+	"bottom":      imaging.Bottom,
+	"left":        imaging.Left,
+	"right":       imaging.Right,
+	"topleft":     imaging.TopLeft,
+	"topright":    imaging.TopRight,
+	"bottomleft":  imaging.BottomLeft,
+	"bottomright": imaging.BottomRight,
+}
+
+// imageOp is a parsed image operation such as "fill 600x400 top q85"
+type imageOp struct {
+	action        string
+	width, height int
+	anchor        imaging.Anchor
+	quality       int // 0 when not given
+}
+
+// parseImageOp parses an operation made by the image methods of
+// content.Resource
+func parseImageOp(op string) (imageOp, error) {
+	fields := strings.Fields(op)
+	parsed := imageOp{action: fields[0], anchor: imaging.Center}
+	options := fields[1:]
+
+	switch parsed.action {
+	case "grayscale":
+	case "resize", "fit", "fill":
+		if len(options) == 0 {
+			return parsed, fmt.Errorf("%s needs a size such as \"800x600\"", parsed.action)
+		}
+		width, height, ok := strings.Cut(options[0], "x")
+		if !ok {
+			return parsed, fmt.Errorf("invalid size %q, expected e.g. \"800x600\" or \"800x\"", options[0])
+		}
+		for _, dim := range []struct {
+			value string
+			dst   *int
+		}{{width, &parsed.width}, {height, &parsed.height}} {
+			if dim.value == "" {
+				continue
+			}
+			n, err := strconv.Atoi(dim.value)
+			if err != nil || n <= 0 {
+				return parsed, fmt.Errorf("invalid size %q", options[0])
+			}
+			*dim.dst = n
+		}
+		if parsed.width == 0 && parsed.height == 0 {
+			return parsed, fmt.Errorf("invalid size %q", options[0])
+		}
+		if parsed.action != "resize" && (parsed.width == 0 || parsed.height == 0) {
+			return parsed, fmt.Errorf("%s needs both a width and a height, got %q", parsed.action, options[0])
+		}
+		options = options[1:]
+	default:
+		return parsed, fmt.Errorf("unknown image operation %q", parsed.action)
+	}
+
+	for _, option := range options {
+		option = strings.ToLower(option)
+		if anchor, ok := imageAnchors[option]; ok && parsed.action == "fill" {
+			parsed.anchor = anchor
+			continue
+		}
+		if q, err := strconv.Atoi(strings.TrimPrefix(option, "q")); err == nil && strings.HasPrefix(option, "q") {
+			if q < 1 || q > 100 {
+				return parsed, fmt.Errorf("quality %q out of range 1-100", option)
+			}
+			parsed.quality = q
+			continue
+		}
+		return parsed, fmt.Errorf("unknown option %q for %s", option, parsed.action)
+	}
+	return parsed, nil
+}
+
+// apply runs the operation on img
+func (op imageOp) apply(img image.Image) image.Image {
+	switch op.action {
+	case "resize":
+		return imaging.Resize(img, op.width, op.height, imaging.Lanczos)
+	case "fit":
+		return imaging.Fit(img, op.width, op.height, imaging.Lanczos)
+	case "fill":
+		return imaging.Fill(img, op.width, op.height, op.anchor, imaging.Lanczos)
+	case "grayscale":
+		return imaging.Grayscale(img)
+	}
+	return img
+}
+
+// imageProcessor makes processed variants of image resources for a
+// builder. Variants are cached in the cache directory by source content and
+// operations, and published next to the original under a name carrying
+// that hash.
+type imageProcessor struct {
+	b        *Builder
+	mu       sync.Mutex
+	inFlight map[string]*imageCall // Cache file -> generation in progress
+	hashes   map[string]sourceHash // Source file -> content hash
+}
+
+// imageCall is a variant being generated, which concurrent requests for
+// the same variant wait for
+type imageCall struct {
+	done chan struct{}
+	err  error
+}
+
+// sourceHash is the content hash of a source image as of its modification
+// time and size
+type sourceHash struct {
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
+func newImageProcessor(b *Builder) *imageProcessor {
+	return &imageProcessor{
+		b:        b,
+		inFlight: make(map[string]*imageCall),
+		hashes:   make(map[string]sourceHash),
+	}
+}
+
+// ProcessImage applies ops to src, generating the variant unless it's
+// cached, and registers it to be published
+func (p *imageProcessor) ProcessImage(src *content.Resource, ops []string) (*content.Resource, error) {
+	parsed := make([]imageOp, 0, len(ops))
+	quality := defaultImageQuality
+	for _, op := range ops {
+		imgOp, err := parseImageOp(op)
+		if err != nil {
+			return nil, fmt.Errorf("image %s: %w", src.Name, err)
+		}
+		if imgOp.quality > 0 {
+			quality = imgOp.quality
+		}
+		parsed = append(parsed, imgOp)
+	}
+	if src.PublishPath == "" {
+		return nil, fmt.Errorf("image %s is not published", src.Name)
+	}
+	format, err := imaging.FormatFromFilename(src.SourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("image %s: %w", src.Name, err)
+	}
+
+	hash, err := p.sourceHash(src.SourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("image %s: %w", src.Name, err)
+	}
+	sum := sha256.Sum256([]byte(hash + "\n" + strings.Join(ops, "\n")))
+	key := hex.EncodeToString(sum[:])[:16]
+	ext := path.Ext(src.PublishPath)
+	cachePath := filepath.Join(p.cacheDir(), key+strings.ToLower(ext))
+
+	if err := p.generate(cachePath, src, ops, parsed, format, quality); err != nil {
+		return nil, fmt.Errorf("image %s: %w", src.Name, err)
+	}
+	size, err := imageSize(cachePath)
+	if err != nil {
+		return nil, fmt.Errorf("image %s: %w", src.Name, err)
+	}
+
+	publishPath := strings.TrimSuffix(src.PublishPath, ext) + "_" + key + ext
+	variant := src.ImageVariant(ops)
+	variant.SourcePath = cachePath
+	variant.PublishPath = publishPath
+	variant.RelPermalink = p.b.config.RelURL("/" + publishPath)
+	variant.Permalink = p.b.config.AbsURL("/" + publishPath)
+	variant.Width, variant.Height = size.Width, size.Height
+
+	p.b.assetFilesMu.Lock()
+	p.b.assetFiles[publishPath] = content.NewResource(publishPath, cachePath)
+	p.b.assetFilesMu.Unlock()
+	return variant, nil
+}
+
+// generate writes the variant to cachePath unless it's there already. Only
+// one worker generates a variant; others asking for it meanwhile wait.
+func (p *imageProcessor) generate(cachePath string, src *content.Resource, ops []string, parsed []imageOp, format imaging.Format, quality int) error {
+	p.mu.Lock()
+	if call, ok := p.inFlight[cachePath]; ok {
+		p.mu.Unlock()
+		<-call.done
+		return call.err
+	}
+	if _, err := os.Stat(cachePath); err == nil {
+		p.mu.Unlock()
+		return nil
+	}
+	call := &imageCall{done: make(chan struct{})}
+	p.inFlight[cachePath] = call
+	p.mu.Unlock()
+
+	start := time.Now()
+	call.err = writeImage(cachePath, src.SourcePath, parsed, format, quality)
+	if call.err == nil {
+		logging.Debugf("🖼️  Processed %s (%s) in %v", src.Name, strings.Join(ops, ", "), time.Since(start))
+	}
+
+	p.mu.Lock()
+	delete(p.inFlight, cachePath)
+	p.mu.Unlock()
+	close(call.done)
+	return call.err
+}
+
+// writeImage decodes src, turned upright by its EXIF orientation, applies
+// the operations and encodes the result without metadata to dst
+func writeImage(dst, src string, ops []imageOp, format imaging.Format, quality int) error {
+	img, err := imaging.Open(src, imaging.AutoOrientation(true))
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		img = op.apply(img)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := imaging.Encode(tmp, img, format, imaging.JPEGQuality(quality)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// Renaming into place means a cached file is always complete
+	return os.Rename(tmp.Name(), dst)
+}
+
+// sourceHash returns the content hash of a source image, rehashing it only
+// when it changed
+func (p *imageProcessor) sourceHash(filePath string) (string, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", err
+	}
+	p.mu.Lock()
+	cached, ok := p.hashes[filePath]
+	p.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.hash, nil
+	}
+
+	hash, err := fileHash(filePath)
+	if err != nil {
+		return "", err
+	}
+	p.mu.Lock()
+	p.hashes[filePath] = sourceHash{modTime: info.ModTime(), size: info.Size(), hash: hash}
+	p.mu.Unlock()
+	return hash, nil
+}
+
+// cacheDir returns the directory processed images are cached in
+func (p *imageProcessor) cacheDir() string {
+	if p.b.config.Performance.CacheDir == "" {
+		return filepath.Join(".cache", "images")
+	}
+	return filepath.Join(p.b.config.Performance.CacheDir, "images")
+}
+
+// imageSize reads the pixel size of an image file from its header
+func imageSize(filePath string) (image.Config, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer f.Close()
+	size, _, err := image.DecodeConfig(f)
+	return size, err
+}
@@ -0,0 +1,72 @@
+package builder
+
+import (
+	"path/filepath"
+	"strings"
+
+	"vango/internal/content"
+	"vango/internal/util"
+)
+
+// changeKind is what a changed file means to an incremental build
+type changeKind int
+
+const (
+	changeIgnored     changeKind = iota
+	changeContent                // A page, or a resource of its bundle
+	changeThemeConfig            // The active theme's config.json
+	changeTemplate               // A layout of the site or the theme
+	changeStyles                 // SCSS or Sass, compiled together
+	changeAsset                  // An asset file pages reference
+	changeStaticCSS              // A plain stylesheet in the site's or the theme's static files
+	changeStatic                 // Any other static file of the site or the theme
+	changeData                   // A data file, which any page may use
+	changeFullRebuild            // Translations or configuration
+)
+
+// classifyChange tells what a changed file is by the directory it is in.
+// Only files in none of the site's or the theme's directories are judged
+// by their extension, so static/page.html is a static file, not a layout.
+func (b *Builder) classifyChange(file string) changeKind {
+	theme := b.themeManager.GetActiveTheme() != nil
+	switch {
+	case content.BundleIndex(file, b.config.ContentDir, b.config.ContentExtensions) != "":
+		return changeContent
+	case b.themeManager.IsThemeConfig(file):
+		return changeThemeConfig
+	case inDir(b.config.ContentDir, file):
+		if b.isPageFile(file) {
+			return changeContent
+		}
+		return changeIgnored
+	case inDir(b.config.LayoutDir, file), theme && inDir(b.themeManager.GetThemeTemplatesPath(), file):
+		return changeTemplate
+	case isStylesheet(file):
+		return changeStyles
+	case b.isReferencedAsset(file):
+		return changeAsset
+	case inDir(b.config.StaticDir, file), theme && inDir(b.themeManager.GetThemeStaticPath(), file):
+		if strings.EqualFold(filepath.Ext(file), ".css") {
+			return changeStaticCSS
+		}
+		return changeStatic
+	case inDir(b.config.DataDir, file):
+		return changeData
+	case inDir(b.config.I18nDir, file), theme && inDir(b.themeManager.GetThemeI18nPath(), file):
+		return changeFullRebuild
+	}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".toml", ".yaml", ".yml":
+		return changeFullRebuild
+	}
+	return changeIgnored
+}
+
+// inDir reports whether file is inside dir
+func inDir(dir, file string) bool {
+	if dir == "" {
+		return false
+	}
+	rel, err := util.RelSlashPath(absPath(dir), absPath(file))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
@@ -0,0 +1,117 @@
+package builder
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"vango/internal/content"
+	"vango/internal/util"
+)
+
+// ErrPageNotFound is returned by RenderOnDemand when no content file maps to
+// the requested URL
+var ErrPageNotFound = errors.New("page not found")
+
+// RenderOnDemand parses and renders the page for a URL path without writing
+// it to disk. Cross-page data such as .Pages only covers what has been parsed
+// so far, which is everything once a full build has completed. Builds and
+// rebuilds in progress hold it off, except while a full build renders, when
+// pages are read-only.
+func (b *Builder) RenderOnDemand(urlPath string) (string, error) {
+	b.lazyMu.RLock()
+	defer b.lazyMu.RUnlock()
+
+	filePath, err := b.contentFileForPath(urlPath)
+	if err != nil {
+		return "", err
+	}
+
+	page, err := b.parser.ParseFile(filePath, b.config.ContentDir)
+	if err != nil {
+		return "", err
+	}
+	if !b.shouldBuild(page) {
+		return "", ErrPageNotFound
+	}
+	b.applySectionConfig(page)
+	if !b.rendersHTML(page) {
+		return "", ErrPageNotFound
+	}
+	b.setPermalink(page)
+	b.updateBuildInfo()
+	if err := b.resolveContentRefs([]*content.Page{page}); err != nil {
+		return "", err
+	}
+
+	html, err := b.engine.Render(page, b.GetPages())
+	if err != nil {
+		return "", err
+	}
+	// Asset files the page references are served from the output directory
+	return html, b.publishReferenced()
+}
+
+// contentFileForPath maps a URL path back to the content file that produces it
+func (b *Builder) contentFileForPath(urlPath string) (string, error) {
+	slug := strings.Trim(urlPath, "/")
+	slug = strings.TrimSuffix(slug, "/index.html")
+	slug = strings.TrimSuffix(slug, ".html")
+	if slug == "" || slug == "index" {
+		slug = "index"
+	}
+
+	base := util.OutputPath(b.config.ContentDir, slug)
+	extensions := b.config.ContentExtensions
+	if len(extensions) == 0 {
+		extensions = content.DefaultExtensions
+	}
+	var candidates []string
+	for _, ext := range extensions {
+		ext = "." + strings.TrimPrefix(ext, ".")
+		candidates = append(candidates, base+ext, filepath.Join(base, "index"+ext), filepath.Join(base, "_index"+ext))
+		if slug == "index" {
+			// The home page
+			candidates = append(candidates, filepath.Join(b.config.ContentDir, "_index"+ext))
+		}
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	if b.config.DateFromFilename && !b.config.KeepFilenameDate {
+		// The date prefix was dropped from the URL, e.g. 2024-01-15-my-post.md
+		dir, name := filepath.Split(base)
+		dated := filepath.Join(dir, "[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]-"+name)
+		for _, ext := range extensions {
+			ext = "." + strings.TrimPrefix(ext, ".")
+			for _, pattern := range []string{dated + ext, filepath.Join(dated, "index"+ext)} {
+				if matches, _ := filepath.Glob(pattern); len(matches) > 0 {
+					return matches[0], nil
+				}
+			}
+		}
+	}
+	return "", ErrPageNotFound
+}
+
+// ContentFileCount returns the number of content files without parsing them
+func (b *Builder) ContentFileCount() int {
+	count := 0
+	filepath.Walk(b.config.ContentDir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && b.isPageFile(path) {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+// HasContent reports whether a content file maps to the URL path, for
+// routing requests before the page has been written
+func (b *Builder) HasContent(urlPath string) bool {
+	_, err := b.contentFileForPath(urlPath)
+	return err == nil
+}
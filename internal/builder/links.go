@@ -0,0 +1,126 @@
+package builder
+
+import (
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"vango/internal/util"
+)
+
+// linkPattern matches href and src attributes in generated HTML
+var linkPattern = regexp.MustCompile(`(?i)\s(?:href|src)\s*=\s*["']([^"']*)["']`)
+
+// scriptPattern matches inline scripts, whose markup strings are not links
+var scriptPattern = regexp.MustCompile(`(?is)<script\b[^>]*>.*?</script>`)
+
+// BrokenLink is an internal link whose target is missing from the output
+type BrokenLink struct {
+	Page   string // HTML file containing the link, relative to the output directory
+	Target string // link as written in the page
+}
+
+// CheckLinks reports internal links in the HTML files under dir that point
+// to no file in dir. Links to baseURL are internal; its path prefix is
+// stripped before resolving.
+func CheckLinks(dir, baseURL string) ([]BrokenLink, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	basePath := "/" + strings.Trim(base.Path, "/")
+
+	var broken []BrokenLink
+	err = filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(filePath) != ".html" {
+			return nil
+		}
+		relPath, err := util.RelSlashPath(dir, filePath)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		html := scriptPattern.ReplaceAllString(string(data), "")
+		seen := make(map[string]bool)
+		for _, match := range linkPattern.FindAllStringSubmatch(html, -1) {
+			link := match[1]
+			if seen[link] {
+				continue
+			}
+			seen[link] = true
+
+			target, ok := internalTarget(link, "/"+path.Dir(relPath), base, basePath)
+			if ok && !outputExists(dir, target) {
+				broken = append(broken, BrokenLink{Page: relPath, Target: link})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(broken, func(i, j int) bool {
+		if broken[i].Page != broken[j].Page {
+			return broken[i].Page < broken[j].Page
+		}
+		return broken[i].Target < broken[j].Target
+	})
+	return broken, nil
+}
+
+// internalTarget resolves a link found in a page under pageDir to a path
+// relative to the output directory. It reports false for external links,
+// fragments and non-HTTP schemes.
+func internalTarget(link, pageDir string, base *url.URL, basePath string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Opaque != "" {
+		return "", false
+	}
+	if u.Scheme != "" || u.Host != "" {
+		if u.Host != base.Host || (u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https") {
+			return "", false
+		}
+	}
+	if u.Path == "" {
+		return "", false
+	}
+
+	target := u.Path
+	if !strings.HasPrefix(target, "/") {
+		target = path.Join(pageDir, target)
+		if strings.HasSuffix(u.Path, "/") {
+			target += "/"
+		}
+	} else if basePath != "/" {
+		if target != basePath && !strings.HasPrefix(target, basePath+"/") {
+			// Outside the site, so served by something else if at all
+			return "", false
+		}
+		target = strings.TrimPrefix(target, basePath)
+	}
+	return strings.TrimPrefix(target, "/"), true
+}
+
+// outputExists reports whether a site path is served by a file in dir,
+// either directly or as a directory index
+func outputExists(dir, target string) bool {
+	filePath := filepath.Join(dir, filepath.FromSlash(target))
+	info, err := os.Stat(filePath)
+	if err == nil && !info.IsDir() {
+		return true
+	}
+	_, err = os.Stat(filepath.Join(filePath, "index.html"))
+	return err == nil
+}
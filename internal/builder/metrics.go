@@ -0,0 +1,70 @@
+package builder
+
+import (
+	"sort"
+	"time"
+
+	"vango/internal/template"
+)
+
+// metricsTopN is how many templates and pages the build metrics list
+const metricsTopN = 10
+
+// BuildMetrics breaks build time down by phase, template and page, to tell
+// a template problem from a parsing problem. Collected with profileMode.
+type BuildMetrics struct {
+	ParseTime    time.Duration             `json:"parse_time"`
+	RenderTime   time.Duration             `json:"render_time"`
+	Templates    []template.TemplateTiming `json:"templates"`
+	SlowestPages []PageTiming              `json:"slowest_pages"`
+}
+
+// PageTiming is the render duration of a page
+type PageTiming struct {
+	File       string        `json:"file,omitempty"`
+	URL        string        `json:"url"`
+	RenderTime time.Duration `json:"render_time"`
+}
+
+// metricsEnabled reports whether template and page timings are collected
+func (b *Builder) metricsEnabled() bool {
+	return b.config.Features.ProfileMode
+}
+
+// Metrics returns the slowest n templates and pages with the phase times of
+// the last full build, or nil when metrics are disabled. Incremental
+// rebuilds update the template and page timings.
+func (b *Builder) Metrics(n int) *BuildMetrics {
+	if !b.metricsEnabled() {
+		return nil
+	}
+
+	metrics := &BuildMetrics{
+		ParseTime:  b.parseTime,
+		RenderTime: b.renderTime,
+		Templates:  b.engine.TemplateTimings(),
+	}
+	if len(metrics.Templates) > n {
+		metrics.Templates = metrics.Templates[:n]
+	}
+
+	b.pagesMu.RLock()
+	pages := b.renderedPages()
+	b.pagesMu.RUnlock()
+	for _, page := range pages {
+		if page.RenderTime > 0 {
+			metrics.SlowestPages = append(metrics.SlowestPages, PageTiming{
+				File:       page.FilePath,
+				URL:        page.URL,
+				RenderTime: page.RenderTime,
+			})
+		}
+	}
+	sort.Slice(metrics.SlowestPages, func(i, j int) bool {
+		return metrics.SlowestPages[i].RenderTime > metrics.SlowestPages[j].RenderTime
+	})
+	if len(metrics.SlowestPages) > n {
+		metrics.SlowestPages = metrics.SlowestPages[:n]
+	}
+	return metrics
+}
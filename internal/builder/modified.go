@@ -0,0 +1,151 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// outputManifestFile is the output manifest's name in the cache directory
+const outputManifestFile = "outputs.json"
+
+// outputManifest lists the files builds wrote to the public directory with
+// their hashes, so the next build can tell which were edited by hand since
+type outputManifest struct {
+	PublicDir string                 `json:"public_dir"`
+	Files     map[string]outputEntry `json:"files"`
+}
+
+// outputEntry is a written file as the build left it. Files with the same
+// size and modification time aren't hashed again to be compared.
+type outputEntry struct {
+	Hash    string    `json:"hash"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// hashOutputFile returns the manifest entry of a file
+func hashOutputFile(filePath string) (outputEntry, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return outputEntry{}, err
+	}
+	sum, err := fileHash(filePath)
+	if err != nil {
+		return outputEntry{}, err
+	}
+	return outputEntry{Hash: sum, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// outputManifestPath returns where the output manifest is kept
+func (b *Builder) outputManifestPath() string {
+	if b.config.Performance.CacheDir == "" {
+		return filepath.Join(".cache", outputManifestFile)
+	}
+	return filepath.Join(b.config.Performance.CacheDir, outputManifestFile)
+}
+
+// loadOutputManifest returns the output manifest, read from the cache
+// directory the first time. It is empty when no build recorded one for
+// this public directory. The caller holds changesMu.
+func (b *Builder) loadOutputManifest() *outputManifest {
+	if b.manifest != nil {
+		return b.manifest
+	}
+	b.manifest = &outputManifest{PublicDir: util.SlashPath(filepath.Clean(b.config.PublicDir)), Files: make(map[string]outputEntry)}
+	data, err := os.ReadFile(b.outputManifestPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logging.Warnf("⚠️  Warning: could not read the output manifest: %v", err)
+		}
+		return b.manifest
+	}
+	var saved outputManifest
+	if err := json.Unmarshal(data, &saved); err != nil {
+		logging.Warnf("⚠️  Warning: ignoring the output manifest %s: %v", b.outputManifestPath(), err)
+		return b.manifest
+	}
+	if saved.PublicDir == b.manifest.PublicDir && saved.Files != nil {
+		b.manifest.Files = saved.Files
+	}
+	return b.manifest
+}
+
+// updateOutputManifest records the files a build wrote and saves the
+// manifest. A full build's files replace the list, since the others are
+// gone. The caller holds changesMu.
+func (b *Builder) updateOutputManifest(entries map[string]outputEntry, full bool) {
+	manifest := b.loadOutputManifest()
+	if full {
+		manifest.Files = make(map[string]outputEntry, len(entries))
+	}
+	for relPath, entry := range entries {
+		manifest.Files[relPath] = entry
+	}
+
+	data, err := json.Marshal(manifest)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(b.outputManifestPath()), 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(b.outputManifestPath(), data, 0644)
+	}
+	if err != nil {
+		logging.Warnf("⚠️  Warning: could not save the output manifest: %v", err)
+	}
+}
+
+// modifiedOutputs lists the files in the public directory whose content
+// changed since a build wrote them, sorted. Files that are gone don't
+// count.
+func (b *Builder) modifiedOutputs() []string {
+	b.changesMu.Lock()
+	defer b.changesMu.Unlock()
+
+	var modified []string
+	for relPath, entry := range b.loadOutputManifest().Files {
+		filePath := util.OutputPath(b.config.PublicDir, relPath)
+		info, err := os.Stat(filePath)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if info.Size() == entry.Size && info.ModTime().Equal(entry.ModTime) {
+			continue
+		}
+		if sum, err := fileHash(filePath); err == nil && sum != entry.Hash {
+			modified = append(modified, relPath)
+		}
+	}
+	sort.Strings(modified)
+	return modified
+}
+
+// checkModifiedOutputs warns about generated files edited by hand since the
+// last build, which this one is about to overwrite, or fails the build
+// when modifiedOutputLevel is "error". --force and --clean skip it.
+func (b *Builder) checkModifiedOutputs() error {
+	if b.config.ModifiedOutputLevel == "off" || b.config.ForceOverwrite {
+		return nil
+	}
+	modified := b.modifiedOutputs()
+	if len(modified) == 0 {
+		return nil
+	}
+	if b.config.ModifiedOutputLevel == "error" {
+		return fmt.Errorf("%d files in %s were edited since the last build wrote them: %s; copy the edits into the site's sources, or build with --force to overwrite them",
+			len(modified), b.config.PublicDir, strings.Join(modified, ", "))
+	}
+	logging.Warnf("⚠️  %d files in %s were edited since the last build wrote them and will be overwritten:", len(modified), b.config.PublicDir)
+	for _, relPath := range modified {
+		logging.Warnf("   - %s", relPath)
+	}
+	logging.Warnf("⚠️  Copy the edits into the site's sources to keep them; build with --force to skip this check")
+	return nil
+}
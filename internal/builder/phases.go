@@ -0,0 +1,139 @@
+// Package builder turns a site's content, templates and assets into its
+// output directory.
+//
+// A build runs in phases, each finishing before the next starts, so no
+// goroutine renders a page while another changes it:
+//
+//   - prepare: theme, templates, translations and stylesheets are loaded.
+//   - parse: content files are parsed by parallel workers. A worker owns the
+//     page it parses until the page is added to b.pages.
+//   - enrich: a single goroutine links the pages to each other: order,
+//     sections, taxonomies, series, references and breadcrumbs. This is the
+//     only phase that may set a field on a page from another page.
+//   - render: workers render the pages, taxonomy pages and feeds. b.pages
+//     and every page's fields are read-only here, except the fields a worker
+//     records about the page it renders, such as OutputPath and RenderTime.
+//   - assets: static files, redirects, headers, the sitemap, metrics and
+//     compressed variants are written from the rendered site.
+//
+// New features slot into the phase matching what they do: anything that
+// computes data from other pages, such as related pages or translations,
+// belongs in enrich, and new output formats in render or assets.
+// Incremental rebuilds go through enrich and render again for the pages
+// they touch, in the same order and never overlapping. Pages rendered on
+// demand by the development server's lazy mode wait for a full build's
+// other phases and only run alongside render.
+package builder
+
+import (
+	"fmt"
+	"time"
+)
+
+// preparePhase loads the theme, templates, translations and stylesheets
+func (b *Builder) preparePhase() error {
+	b.reportProgress("templates", 5)
+	if err := b.engine.LoadTemplates(b.themeManager.GetThemeTemplatesPath()); err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
+	}
+	if err := b.loadTranslations(); err != nil {
+		return err
+	}
+
+	// Stylesheets compile before rendering so pages can link them
+	b.reportProgress("styles", 8)
+	if _, err := b.compileSCSS(); err != nil {
+		return fmt.Errorf("failed to compile stylesheets: %w", err)
+	}
+	return nil
+}
+
+// parsePhase parses the content files in parallel into b.pages
+func (b *Builder) parsePhase() error {
+	b.reportProgress("parse", 10)
+	start := time.Now()
+	if err := b.parseContentParallel(); err != nil {
+		return fmt.Errorf("failed to parse content: %w", err)
+	}
+	b.parseTime = time.Since(start)
+	b.finishReproducible()
+	b.updateBuildInfo()
+	return nil
+}
+
+// enrichPhase links the parsed pages to each other. It runs on a single
+// goroutine, before any page renders, and first makes sure no two pages,
+// aliases or redirects claim the same URL.
+func (b *Builder) enrichPhase() error {
+	if err := b.checkURLs(); err != nil {
+		return err
+	}
+	b.sortPages()
+	b.linkSections()
+	b.buildTaxonomies()
+	b.buildStats()
+	b.linkSeries()
+	b.buildSections()
+	b.buildRefIndex()
+	b.linkBreadcrumbs()
+	if err := b.resolveContentRefs(b.renderedPages()); err != nil {
+		return fmt.Errorf("failed to resolve page references: %w", err)
+	}
+	return nil
+}
+
+// renderPhase renders the pages in parallel, then the taxonomy pages and
+// section feeds
+func (b *Builder) renderPhase() error {
+	start := time.Now()
+	if err := b.generatePagesParallel(); err != nil {
+		return fmt.Errorf("failed to generate pages: %w", err)
+	}
+	if err := b.generateTaxonomies(); err != nil {
+		return fmt.Errorf("failed to generate taxonomy pages: %w", err)
+	}
+	b.renderTime = time.Since(start)
+
+	if err := b.generateSectionFeeds(); err != nil {
+		return fmt.Errorf("failed to generate section feeds: %w", err)
+	}
+	return nil
+}
+
+// assetPhase writes the files built from the rendered site: static and
+// referenced assets, hosting redirects and headers, the sitemap, build
+// metrics and, last, compressed variants of every output file
+func (b *Builder) assetPhase(start time.Time) (*AssetManifest, *CompressionReport, error) {
+	// Publish static files, theme files and the files pages reference
+	b.reportProgress("assets", 92)
+	assets, err := b.publishAssets()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to publish assets: %w", err)
+	}
+
+	// Generate hosting redirect files
+	b.reportProgress("redirects", 96)
+	if err := b.generateRedirects(); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate redirects: %w", err)
+	}
+	if err := b.writeLinkHeaders(); err != nil {
+		return nil, nil, fmt.Errorf("failed to write headers: %w", err)
+	}
+
+	if err := b.generateSitemap(); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate sitemap: %w", err)
+	}
+
+	// Metrics sum up every other output file, so they come last
+	if err := b.writeSiteMetrics(start); err != nil {
+		return nil, nil, fmt.Errorf("failed to write build metrics: %w", err)
+	}
+
+	// Precompress last, once every output file is written
+	b.reportProgress("compress", 97)
+	compression, err := b.compressOutputs()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to precompress output: %w", err)
+	}
+	return assets, compression, nil
+}
@@ -0,0 +1,92 @@
+package builder
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// ProgressFunc receives build progress as a stage name and an overall
+// completion percentage between 0 and 100
+type ProgressFunc func(stage string, percent int)
+
+// Rendering progress is reported within this percentage band
+const (
+	renderProgressStart = 30
+	renderProgressEnd   = 90
+)
+
+// SetProgressFunc registers a callback for build progress. It is called from
+// worker goroutines and must be safe for concurrent use.
+func (b *Builder) SetProgressFunc(fn ProgressFunc) {
+	b.progress = fn
+}
+
+// reportProgress forwards a progress update to the registered callback
+func (b *Builder) reportProgress(stage string, percent int) {
+	if b.progress != nil {
+		b.progress(stage, percent)
+	}
+}
+
+// pageRendered counts a finished page and reports progress roughly every 5%
+func (b *Builder) pageRendered(total int) {
+	done := int(atomic.AddInt64(&b.rendered, 1))
+	if total == 0 || done*20/total == (done-1)*20/total {
+		return
+	}
+	span := renderProgressEnd - renderProgressStart
+	b.reportProgress("render", renderProgressStart+span*done/total)
+}
+
+// Rebuild runs a build limited to scope. "all" performs a full build,
+// "content" re-parses and re-renders content and "templates" reloads the
+// template set and re-renders the pages that use it. Paths narrow content
+// and template rebuilds to the given files.
+func (b *Builder) Rebuild(scope string, paths []string) error {
+	b.lazyMu.Lock()
+	defer b.lazyMu.Unlock()
+	switch scope {
+	case "", "all":
+		b.resetCache()
+		return b.build()
+
+	case "templates":
+		if len(b.GetPages()) == 0 {
+			return b.build()
+		}
+		b.reportProgress("templates", 10)
+		if err := b.reloadTemplates(paths); err != nil {
+			return err
+		}
+
+	case "content":
+		if len(paths) == 0 {
+			b.resetCache()
+			b.reportProgress("parse", 10)
+			if err := b.parseContentParallel(); err != nil {
+				return fmt.Errorf("failed to parse content: %w", err)
+			}
+			if err := b.generatePagesParallel(); err != nil {
+				return fmt.Errorf("failed to generate pages: %w", err)
+			}
+			break
+		}
+		for i, path := range paths {
+			b.reportProgress("content", 100*i/len(paths))
+			if err := b.rebuildContentFile(path); err != nil {
+				return fmt.Errorf("failed to rebuild content file %s: %w", path, err)
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown rebuild scope %q", scope)
+	}
+
+	// Re-rendered pages may reference files not published yet
+	if err := b.publishReferenced(); err != nil {
+		return fmt.Errorf("failed to publish assets: %w", err)
+	}
+
+	b.reportProgress("done", 100)
+	return nil
+}
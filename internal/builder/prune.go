@@ -0,0 +1,170 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// resetOutputs clears the set of files written by the current build
+func (b *Builder) resetOutputs() {
+	b.outputsMu.Lock()
+	b.outputs = make(map[string]bool)
+	b.outputsMu.Unlock()
+}
+
+// recordOutput marks a path, relative to the output directory, as written
+func (b *Builder) recordOutput(relPath string) {
+	b.outputsMu.Lock()
+	b.outputs[util.SlashPath(relPath)] = true
+	if b.written != nil {
+		b.written[util.SlashPath(relPath)] = true
+	}
+	b.outputsMu.Unlock()
+}
+
+// hasOutput reports whether a path, relative to the output directory, was
+// written since the last full build
+func (b *Builder) hasOutput(relPath string) bool {
+	b.outputsMu.Lock()
+	defer b.outputsMu.Unlock()
+	return b.outputs[util.SlashPath(relPath)]
+}
+
+// removeOutput deletes a published file or directory, relative to the
+// output directory, with its precompressed variants, and forgets it was
+// written
+func (b *Builder) removeOutput(relPath string) error {
+	relPath = util.SlashPath(relPath)
+	dst := util.OutputPath(b.outputDir, relPath)
+	for _, file := range []string{dst, dst + ".gz", dst + ".br"} {
+		if err := os.RemoveAll(file); err != nil {
+			return err
+		}
+	}
+	b.outputsMu.Lock()
+	for out := range b.outputs {
+		if out == relPath || strings.HasPrefix(out, relPath+"/") {
+			delete(b.outputs, out)
+		}
+	}
+	b.outputsMu.Unlock()
+	logging.Debugf("🗑️  Removed %s", relPath)
+	return nil
+}
+
+// outputList returns the sorted list of files written by the current build
+func (b *Builder) outputList() []string {
+	b.outputsMu.Lock()
+	defer b.outputsMu.Unlock()
+
+	files := make([]string, 0, len(b.outputs))
+	for path := range b.outputs {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// isProtected reports whether an output path must never be pruned
+func (b *Builder) isProtected(relPath string) bool {
+	first := strings.Split(relPath, "/")[0]
+	for _, protected := range b.config.ProtectedFiles {
+		protected = strings.Trim(util.SlashPath(protected), "/")
+		if relPath == protected || first == protected || strings.HasPrefix(relPath, protected+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// findOrphans lists files in the public directory that the current build
+// did not produce
+func (b *Builder) findOrphans() ([]string, error) {
+	b.outputsMu.Lock()
+	defer b.outputsMu.Unlock()
+
+	var orphans []string
+	err := filepath.Walk(b.config.PublicDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(b.config.PublicDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = util.SlashPath(relPath)
+		if relPath == "." {
+			return nil
+		}
+		if b.isProtected(relPath) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() && !b.outputs[relPath] {
+			orphans = append(orphans, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// pruneOrphans deletes files left over from earlier builds. The orphans are
+// always listed first; with PruneDryRun set nothing is deleted.
+func (b *Builder) pruneOrphans() ([]string, error) {
+	orphans, err := b.findOrphans()
+	if err != nil {
+		return nil, err
+	}
+	if len(orphans) == 0 {
+		return nil, nil
+	}
+
+	logging.Infof("🧹 %d orphaned files in %s:", len(orphans), b.config.PublicDir)
+	for _, orphan := range orphans {
+		logging.Infof("   - %s", orphan)
+	}
+	if b.config.PruneDryRun {
+		logging.Infof("🧹 Dry run: no files removed")
+		return orphans, nil
+	}
+
+	for _, orphan := range orphans {
+		if err := os.Remove(filepath.Join(b.config.PublicDir, filepath.FromSlash(orphan))); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	b.removeEmptyDirs(b.config.PublicDir)
+	return orphans, nil
+}
+
+// removeEmptyDirs removes directories left empty after pruning
+func (b *Builder) removeEmptyDirs(root string) {
+	var dirs []string
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() || path == root {
+			return nil
+		}
+		if relPath, err := filepath.Rel(root, path); err == nil && b.isProtected(util.SlashPath(relPath)) {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, path)
+		return nil
+	})
+	// Deepest directories first so parents become empty in turn
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if entries, err := os.ReadDir(dirs[i]); err == nil && len(entries) == 0 {
+			os.Remove(dirs[i])
+		}
+	}
+}
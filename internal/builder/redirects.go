@@ -0,0 +1,157 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// generateRedirects writes the redirect artifacts for the configured hosting
+// targets into the output directory
+func (b *Builder) generateRedirects() error {
+	if len(b.config.Redirects) == 0 {
+		return nil
+	}
+
+	for _, target := range b.config.RedirectOptions.Targets {
+		var err error
+		switch strings.ToLower(target) {
+		case "netlify", "cloudflare":
+			err = b.writeNetlifyRedirects()
+		case "vercel":
+			err = b.writeVercelRedirects()
+		default:
+			logging.Warnf("⚠️  Warning: unknown redirect target '%s'", target)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if b.config.RedirectOptions.HTMLFallback {
+		return b.writeHTMLRedirects()
+	}
+	return nil
+}
+
+// writeNetlifyRedirects writes a _redirects file, the format shared by
+// Netlify and Cloudflare Pages
+func (b *Builder) writeNetlifyRedirects() error {
+	var out strings.Builder
+	for _, r := range b.config.Redirects {
+		status := fmt.Sprintf("%d", r.Status)
+		if r.Force {
+			status += "!"
+		}
+		out.WriteString(fmt.Sprintf("%s  %s  %s\n", r.From, r.To, status))
+	}
+	return b.writeOutputFile("_redirects", []byte(out.String()))
+}
+
+// writeVercelRedirects writes a vercel.json with redirects and rewrites.
+// Rules with other statuses have no Vercel equivalent and are reported.
+func (b *Builder) writeVercelRedirects() error {
+	type vercelRoute struct {
+		Source      string `json:"source"`
+		Destination string `json:"destination"`
+		StatusCode  int    `json:"statusCode,omitempty"`
+	}
+	var vercel struct {
+		Redirects []vercelRoute `json:"redirects,omitempty"`
+		Rewrites  []vercelRoute `json:"rewrites,omitempty"`
+	}
+
+	for _, r := range b.config.Redirects {
+		route := vercelRoute{
+			Source:      vercelPattern(r.From),
+			Destination: r.To,
+		}
+		if r.IsRedirect() {
+			route.StatusCode = r.Status
+			vercel.Redirects = append(vercel.Redirects, route)
+		} else if r.Status == 200 {
+			vercel.Rewrites = append(vercel.Rewrites, route)
+		} else {
+			// Vercel's redirects and rewrites can't answer with an error
+			// status such as 404 or 410
+			logging.Warnf("⚠️  Warning: vercel.json can't express the %d rule %s  %s; it is left out", r.Status, r.From, r.To)
+		}
+	}
+
+	data, err := json.MarshalIndent(vercel, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode vercel.json: %w", err)
+	}
+	return b.writeOutputFile("vercel.json", data)
+}
+
+// vercelPattern converts a trailing "/*" wildcard to Vercel's ":splat*" syntax
+func vercelPattern(from string) string {
+	if strings.HasSuffix(from, "/*") {
+		return strings.TrimSuffix(from, "*") + ":splat*"
+	}
+	return from
+}
+
+// writeHTMLRedirects writes meta-refresh stubs for exact redirects, for hosts
+// without server-side redirect support
+func (b *Builder) writeHTMLRedirects() error {
+	for _, r := range b.config.Redirects {
+		if !r.IsRedirect() || strings.Contains(r.From, "*") {
+			continue
+		}
+		relPath := filepath.Join(strings.Trim(r.From, "/"), "index.html")
+		if strings.HasSuffix(r.From, ".html") {
+			relPath = strings.TrimPrefix(r.From, "/")
+		}
+		// Site paths are served under the BaseURL path
+		to := r.To
+		if strings.HasPrefix(to, "/") && !strings.HasPrefix(to, "//") {
+			to = b.config.BaseURLPath() + to
+		}
+		if err := b.writeOutputFile(relPath, []byte(htmlRedirectStub(to, b.config.Language))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// htmlRedirectStub returns a minimal page in language lang that redirects
+// to target
+func htmlRedirectStub(target, lang string) string {
+	to := template.HTMLEscapeString(target)
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="%s">
+<head>
+    <meta charset="UTF-8">
+    <title>Redirecting…</title>
+    <link rel="canonical" href="%s">
+    <meta http-equiv="refresh" content="0; url=%s">
+    <meta name="robots" content="noindex">
+</head>
+<body>
+    <p>This page has moved to <a href="%s">%s</a>.</p>
+</body>
+</html>
+`, template.HTMLEscapeString(lang), to, to, to, to)
+}
+
+// writeOutputFile writes a generated file relative to the output directory
+// and records it as part of the build
+func (b *Builder) writeOutputFile(relPath string, data []byte) error {
+	outputPath := util.OutputPath(b.outputDir, relPath)
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", outputPath, err)
+	}
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	b.recordOutput(relPath)
+	return nil
+}
@@ -0,0 +1,145 @@
+package builder
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"vango/internal/content"
+	"vango/internal/logging"
+)
+
+// refIndex finds pages by content path for ref and relref
+type refIndex struct {
+	byPath map[string]*content.Page   // "posts/my-post.md", "posts/my-post"
+	byName map[string][]*content.Page // "my-post.md", "my-post"
+}
+
+// buildRefIndex indexes every page built from a content file
+func (b *Builder) buildRefIndex() {
+	index := &refIndex{
+		byPath: make(map[string]*content.Page),
+		byName: make(map[string][]*content.Page),
+	}
+	for _, page := range b.renderedPages() {
+		if page.FilePath == "" {
+			continue
+		}
+		rel := page.ContentPath(b.config.ContentDir)
+		trimmed := strings.TrimSuffix(rel, path.Ext(rel))
+		keys := []string{rel, trimmed}
+		names := []string{path.Base(rel), path.Base(trimmed)}
+
+		// Bundles are referred to by their directory
+		if base := path.Base(trimmed); base == "index" || base == "_index" {
+			dir := path.Dir(trimmed)
+			keys = append(keys, dir)
+			names = []string{path.Base(dir)}
+		}
+
+		for _, key := range keys {
+			index.byPath[key] = page
+		}
+		for _, name := range names {
+			index.byName[name] = append(index.byName[name], page)
+		}
+	}
+
+	b.refMu.Lock()
+	b.refs = index
+	b.refMu.Unlock()
+}
+
+// lookupRef finds the page a ref target names. Targets are content paths,
+// with or without the extension, tried relative to the referring page's
+// directory first; a bare file name matches anywhere if it is unique.
+func (b *Builder) lookupRef(target string, from *content.Page) (*content.Page, error) {
+	b.refMu.RLock()
+	index := b.refs
+	b.refMu.RUnlock()
+	if index == nil {
+		return nil, fmt.Errorf("page %q not found: pages are not indexed yet", target)
+	}
+
+	clean := strings.Trim(target, "/")
+	if clean == "" {
+		return from, nil
+	}
+
+	if from != nil && from.FilePath != "" && !strings.HasPrefix(target, "/") {
+		dir := path.Dir(from.ContentPath(b.config.ContentDir))
+		if page, ok := index.byPath[path.Join(dir, clean)]; ok {
+			return page, nil
+		}
+	}
+	if page, ok := index.byPath[clean]; ok {
+		return page, nil
+	}
+
+	if !strings.Contains(clean, "/") {
+		switch matches := index.byName[clean]; len(matches) {
+		case 0:
+		case 1:
+			return matches[0], nil
+		default:
+			candidates := make([]string, len(matches))
+			for i, page := range matches {
+				candidates[i] = page.ContentPath(b.config.ContentDir)
+			}
+			sort.Strings(candidates)
+			return nil, fmt.Errorf("page %q is ambiguous, use one of: %s", target, strings.Join(candidates, ", "))
+		}
+	}
+	return nil, fmt.Errorf("page %q not found", target)
+}
+
+// resolveRef returns the URL for a ref ("ref", absolute) or relref ("relref",
+// site-relative) to target, keeping any #fragment. Missing pages fail or
+// only warn depending on RefLinksErrorLevel.
+func (b *Builder) resolveRef(kind, target string, from *content.Page) (string, error) {
+	pagePath, fragment, _ := strings.Cut(target, "#")
+	page, err := b.lookupRef(pagePath, from)
+	if err != nil {
+		if from != nil && from.FilePath != "" {
+			err = fmt.Errorf("%s %s: %w", from.FilePath, kind, err)
+		} else {
+			err = fmt.Errorf("%s: %w", kind, err)
+		}
+		if b.config.RefLinksErrorLevel == "warning" {
+			logging.Warnf("⚠️  %v", err)
+			return b.config.RefLinksNotFoundURL, nil
+		}
+		return "", err
+	}
+
+	url := page.RelPermalink
+	if kind == "ref" {
+		url = page.Permalink
+	}
+	if fragment != "" {
+		url += "#" + fragment
+	}
+	return url, nil
+}
+
+// resolveContentRefs replaces ref shortcodes in the pages' content
+func (b *Builder) resolveContentRefs(pages []*content.Page) error {
+	for _, page := range pages {
+		if !page.HasRefs() {
+			continue
+		}
+		if err := page.ResolveRefs(b.resolveRef); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refFunc returns the "ref" or "relref" template function, called as
+// {{ ref .Page "posts/my-post.md" }}
+func (b *Builder) refFunc(kind string) func(page *content.Page, target string) (string, error) {
+	return func(page *content.Page, target string) (string, error) {
+		return b.resolveRef(kind, target, page)
+	}
+}
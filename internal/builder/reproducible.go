@@ -0,0 +1,70 @@
+package builder
+
+import (
+	"time"
+
+	"vango/internal/config"
+	"vango/internal/content"
+	"vango/internal/logging"
+)
+
+// startReproducible pins the build time of a reproducible build to
+// SOURCE_DATE_EPOCH before content is parsed. Without it the time is pinned
+// by finishReproducible once the content dates are known.
+func (b *Builder) startReproducible() error {
+	if !b.config.Reproducible {
+		return nil
+	}
+	epoch, ok, err := config.SourceDateEpoch()
+	if err != nil {
+		return err
+	}
+	b.config.BuildTime = epoch
+	if ok {
+		logging.Debugf("🔒 Build time pinned to SOURCE_DATE_EPOCH: %s", epoch.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// finishReproducible pins a reproducible build's time to the latest content
+// date, unless SOURCE_DATE_EPOCH already did, and moves undated pages to it
+func (b *Builder) finishReproducible() {
+	if !b.config.Reproducible {
+		return
+	}
+	if b.config.BuildTime.IsZero() {
+		latest := time.Unix(0, 0).UTC()
+		for _, page := range b.pages {
+			if page.DateSource != "" && page.ParsedDate.After(latest) {
+				latest = page.ParsedDate
+			}
+			if page.LastMod.After(latest) {
+				latest = page.LastMod
+			}
+		}
+		b.config.BuildTime = latest
+		logging.Debugf("🔒 Build time pinned to the latest content date: %s", latest.Format(time.RFC3339))
+	}
+	for _, page := range b.pages {
+		b.pinPageTime(page)
+	}
+}
+
+// pinPageTime replaces the parse time stamped on a page with the pinned
+// build time, for undated pages whose date would otherwise differ per build
+func (b *Builder) pinPageTime(page *content.Page) {
+	if b.config.BuildTime.IsZero() {
+		return
+	}
+	if page.DateSource == "" {
+		page.ParsedDate = b.config.BuildTime
+	}
+	page.LastBuilt = b.config.BuildTime
+}
+
+// shouldBuild reports whether a freshly parsed page is published at the
+// build time, pinning its time first in reproducible builds
+func (b *Builder) shouldBuild(page *content.Page) bool {
+	b.pinPageTime(page)
+	return page.ShouldBuildAt(b.config.BuildDrafts, b.config.BuildFuture, b.config.Now())
+}
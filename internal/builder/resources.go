@@ -0,0 +1,28 @@
+package builder
+
+import (
+	"path"
+
+	"vango/internal/content"
+)
+
+// isPageFile reports whether a content file is parsed as a page. Markdown
+// files inside a leaf bundle, other than its index, are resources of the
+// bundle's page instead.
+func (b *Builder) isPageFile(filePath string) bool {
+	if !content.IsContentFile(filePath, b.config.ContentExtensions) {
+		return false
+	}
+	return content.BundleIndex(filePath, b.config.ContentDir, b.config.ContentExtensions) == ""
+}
+
+// setResourcePermalinks points a bundle's resources at their published
+// location next to the page, and lets templates process its images
+func (b *Builder) setResourcePermalinks(page *content.Page) {
+	for _, resource := range page.Resources {
+		resource.RelPermalink = b.config.RelURL(page.URL + resource.Name)
+		resource.Permalink = page.Permalink + resource.Name
+		resource.PublishPath = path.Join(page.Slug, resource.Name)
+		resource.SetImageProcessor(b.images)
+	}
+}
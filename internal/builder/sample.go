@@ -0,0 +1,128 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"vango/internal/config"
+)
+
+// sampleConfig is the configuration of the site SampleTheme builds
+const sampleConfig = `title = "Theme Sample"
+baseURL = "https://example.com/"
+description = "Sample content for checking a theme"
+author = "Sample Author"
+`
+
+// sampleContent is the fixture content SampleTheme renders: a standalone
+// page, dated posts with taxonomies, a nested docs section and a page
+// using most markdown features
+var sampleContent = map[string]string{
+	"about.md": `+++
+title = "About"
+description = "A standalone page"
++++
+
+A page outside any section.
+`,
+	"posts/first-post.md": `+++
+title = "First Post"
+date = "2024-01-15T10:00:00Z"
+description = "The first sample post"
+tags = ["sample", "getting-started"]
+categories = ["news"]
++++
+
+The first post, with a [link to the second](/posts/second-post/).
+`,
+	"posts/second-post.md": `+++
+title = "Second Post"
+date = "2024-02-20T10:00:00Z"
+tags = ["sample"]
+categories = ["guides"]
++++
+
+## A heading
+
+Some **bold**, _italic_ and ` + "`code`" + ` text.
+
+` + "```go\nfunc main() {}\n```" + `
+
+| Column | Value |
+|--------|-------|
+| a      | 1     |
+
+> A quote.
+
+- A list
+- of items
+`,
+	"docs/intro.md": `+++
+title = "Introduction"
+weight = 1
++++
+
+Documentation in a section.
+`,
+	"docs/guide/install.md": `+++
+title = "Installation"
+weight = 2
++++
+
+A nested documentation page.
+`,
+}
+
+// SampleTheme builds fixture content with the theme in themeDir in a
+// temporary site, to confirm the theme's templates execute. It returns the
+// number of pages rendered.
+func SampleTheme(themeDir string) (int, error) {
+	themeDir, err := filepath.Abs(themeDir)
+	if err != nil {
+		return 0, err
+	}
+	tmp, err := os.MkdirTemp("", "vango-theme-sample-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(tmp)
+
+	configPath := filepath.Join(tmp, "config.toml")
+	if err := os.WriteFile(configPath, []byte(sampleConfig), 0644); err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Join(tmp, "layouts"), 0755); err != nil {
+		return 0, err
+	}
+	for name, body := range sampleContent {
+		path := filepath.Join(tmp, "content", filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return 0, err
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			return 0, err
+		}
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load sample config: %w", err)
+	}
+	// Keep everything inside the sample site, away from the current one
+	for _, dir := range []*string{&cfg.ContentDir, &cfg.LayoutDir, &cfg.StaticDir, &cfg.PublicDir, &cfg.DataDir, &cfg.AssetsDir, &cfg.I18nDir, &cfg.Performance.CacheDir} {
+		*dir = filepath.Join(tmp, *dir)
+	}
+	cfg.Theme = filepath.Base(themeDir)
+	cfg.SetParam("themes_dir", filepath.Dir(themeDir))
+
+	b := New(cfg)
+	if err := b.Build(); err != nil {
+		return 0, err
+	}
+	// A theme that fails to load is silently replaced by the default one
+	if active := b.themeManager.GetActiveTheme(); active == nil || active.Name != cfg.Theme {
+		return 0, fmt.Errorf("theme %s could not be loaded", cfg.Theme)
+	}
+	return len(b.GetPages()) + len(b.taxonomyPages), nil
+}
@@ -0,0 +1,134 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"vango/internal/content"
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// ScheduledPage is a page the build leaves out because of its dates
+type ScheduledPage struct {
+	File  string    `json:"file"`
+	Title string    `json:"title"`
+	URL   string    `json:"url"`
+	Date  time.Time `json:"date"` // When it goes live or expired, or for stale drafts when it was last touched
+	Draft bool      `json:"draft,omitempty"`
+}
+
+// Schedule is the content calendar at the build time
+type Schedule struct {
+	Now           time.Time       `json:"now"`
+	Scheduled     []ScheduledPage `json:"scheduled"`    // Future pages, soonest first
+	Expired       []ScheduledPage `json:"expired"`      // Pages past their expiry date
+	StaleDrafts   []ScheduledPage `json:"stale_drafts"` // Drafts untouched for longer than staleAfter
+	NextPublishAt *time.Time      `json:"next_publish_at,omitempty"`
+}
+
+// Schedule parses every content file, published or not, and lists the
+// scheduled and expired pages and the drafts left untouched for longer
+// than staleAfter. With a zero staleAfter no draft is stale.
+func (b *Builder) Schedule(staleAfter time.Duration) (*Schedule, error) {
+	now := b.config.Now()
+	schedule := &Schedule{Now: now, Scheduled: []ScheduledPage{}, Expired: []ScheduledPage{}, StaleDrafts: []ScheduledPage{}}
+
+	err := filepath.Walk(b.config.ContentDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !b.isPageFile(filePath) {
+			return nil
+		}
+		page, err := b.parser.ParseFile(filePath, b.config.ContentDir)
+		if err != nil {
+			logging.Warnf("⚠️  Skipping %s: %v", filePath, err)
+			return nil
+		}
+		// Undated pages carry the parse time, which schedules nothing
+		if page.DateSource == "" {
+			page.ParsedDate = time.Time{}
+		}
+		b.applySectionConfig(page)
+		b.setPermalink(page)
+
+		entry := ScheduledPage{File: util.SlashPath(filePath), Title: page.Title, URL: page.URL, Draft: page.Draft}
+		switch {
+		case page.IsFutureAt(now):
+			entry.Date = page.PublishAt()
+			schedule.Scheduled = append(schedule.Scheduled, entry)
+		case page.IsExpiredAt(now):
+			entry.Date = page.ExpiryDate
+			schedule.Expired = append(schedule.Expired, entry)
+		}
+		if page.Draft && staleAfter > 0 {
+			if touched := draftTouched(page, info); now.Sub(touched) > staleAfter {
+				entry.Date = touched
+				schedule.StaleDrafts = append(schedule.StaleDrafts, entry)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan content: %w", err)
+	}
+
+	for _, pages := range [][]ScheduledPage{schedule.Scheduled, schedule.Expired, schedule.StaleDrafts} {
+		sort.SliceStable(pages, func(i, j int) bool { return pages[i].Date.Before(pages[j].Date) })
+	}
+	for _, page := range schedule.Scheduled {
+		if !page.Draft {
+			next := page.Date
+			schedule.NextPublishAt = &next
+			break
+		}
+	}
+	return schedule, nil
+}
+
+// draftTouched returns when a draft was last worked on: its lastmod date,
+// else its page date, else the file's modification time
+func draftTouched(page *content.Page, info os.FileInfo) time.Time {
+	switch {
+	case !page.LastMod.IsZero():
+		return page.LastMod
+	case page.DateSource != "":
+		return page.ParsedDate
+	}
+	return info.ModTime()
+}
+
+// noteScheduled remembers when a content file's page goes live, if it is
+// left out of the build only because it is scheduled for later. A nil page
+// forgets the file.
+func (b *Builder) noteScheduled(filePath string, page *content.Page) {
+	b.scheduledMu.Lock()
+	defer b.scheduledMu.Unlock()
+	if page == nil || page.Draft || b.config.BuildFuture || !page.IsFutureAt(b.config.Now()) {
+		delete(b.scheduled, filePath)
+		return
+	}
+	if b.scheduled == nil {
+		b.scheduled = make(map[string]time.Time)
+	}
+	b.scheduled[filePath] = page.PublishAt()
+}
+
+// NextPublishAt returns when the next scheduled page goes live, which is
+// when the site needs rebuilding for it to appear, or nil
+func (b *Builder) NextPublishAt() *time.Time {
+	b.scheduledMu.Lock()
+	defer b.scheduledMu.Unlock()
+	var next *time.Time
+	for _, publish := range b.scheduled {
+		if next == nil || publish.Before(*next) {
+			publish := publish
+			next = &publish
+		}
+	}
+	return next
+}
@@ -0,0 +1,234 @@
+package builder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// sassBinary is the Dart Sass executable used to compile stylesheets
+const sassBinary = "sass"
+
+// scssOutputDir is where compiled stylesheets are written, relative to the
+// output directory
+const scssOutputDir = "static/css"
+
+// SCSSError is a stylesheet compilation error with its source position
+type SCSSError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *SCSSError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// sassLocation matches the "file line:column" frame Dart Sass prints below an error
+var sassLocation = regexp.MustCompile(`^\s*(\S.*?) (\d+):(\d+)\s`)
+
+// isStylesheet reports whether path is a Sass source file
+func isStylesheet(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".scss" || ext == ".sass"
+}
+
+// assetDirs returns the existing asset directories, lowest priority first
+// so site assets override theme assets of the same name
+func (b *Builder) assetDirs() []string {
+	var dirs []string
+	if themeAssets := b.themeManager.GetThemeAssetsPath(); themeAssets != "" {
+		dirs = append(dirs, themeAssets)
+	}
+	if b.config.AssetsDir != "" {
+		dirs = append(dirs, b.config.AssetsDir)
+	}
+
+	var existing []string
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			existing = append(existing, dir)
+		}
+	}
+	return existing
+}
+
+// compileSCSS compiles every stylesheet in the asset directories into the
+// output directory. Partials (names starting with "_") are only compiled
+// through imports. It reports whether any stylesheet URL changed.
+func (b *Builder) compileSCSS() (bool, error) {
+	dirs := b.assetDirs()
+	sources := make(map[string]string) // Name relative to its assets dir -> file
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !isStylesheet(p) || strings.HasPrefix(info.Name(), "_") {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			sources[util.SlashPath(rel)] = p
+			return nil
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to scan %s for stylesheets: %w", dir, err)
+		}
+	}
+
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		if _, err := exec.LookPath(sassBinary); err != nil {
+			return false, missingSassError(sources, names)
+		}
+	}
+
+	urls := make(map[string]string, len(sources))
+	for _, name := range names {
+		css, err := b.runSass(sources[name], dirs)
+		if err != nil {
+			return false, err
+		}
+
+		out := path.Join(scssOutputDir, strings.TrimSuffix(name, path.Ext(name))+".css")
+		if b.config.Performance.AssetBundling.Fingerprinting {
+			sum := sha256.Sum256(css)
+			out = strings.TrimSuffix(out, ".css") + "." + hex.EncodeToString(sum[:4]) + ".css"
+		}
+
+		dst := util.OutputPath(b.outputDir, out)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return false, fmt.Errorf("failed to create directory for %s: %w", out, err)
+		}
+		if err := os.WriteFile(dst, css, 0644); err != nil {
+			return false, fmt.Errorf("failed to write %s: %w", out, err)
+		}
+		b.recordOutput(out)
+		urls[name] = "/" + out
+	}
+
+	b.scssMu.Lock()
+	previous := b.scssURLs
+	b.scssURLs = urls
+	b.scssMu.Unlock()
+
+	changed := len(previous) != len(urls)
+	for name, url := range previous {
+		if urls[name] == url {
+			continue
+		}
+		changed = true
+		// Drop the stale fingerprinted file so incremental builds don't pile them up
+		os.Remove(filepath.Join(b.outputDir, filepath.FromSlash(strings.TrimPrefix(url, "/"))))
+	}
+	if len(urls) > 0 {
+		logging.Infof("🎨 Compiled stylesheets: %d", len(urls))
+	}
+	return changed, nil
+}
+
+// runSass compiles a single stylesheet with the Dart Sass executable.
+// Development builds embed a source map; minified builds are compressed.
+func (b *Builder) runSass(src string, loadPaths []string) ([]byte, error) {
+	args := []string{"--no-color", "--no-unicode"}
+	if b.config.Performance.EnableMinification {
+		args = append(args, "--style=compressed")
+	} else {
+		args = append(args, "--style=expanded")
+	}
+	if b.config.DevMode {
+		args = append(args, "--embed-source-map", "--embed-sources")
+	} else {
+		args = append(args, "--no-source-map")
+	}
+	for _, dir := range loadPaths {
+		args = append(args, "--load-path="+dir)
+	}
+	args = append(args, src)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(sassBinary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("compiling %s requires the Dart Sass %q executable on PATH (see https://sass-lang.com/install)", src, sassBinary)
+		}
+		return nil, parseSassError(src, stderr.String(), err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// missingSassError explains which stylesheets need Dart Sass when the
+// executable isn't on PATH, and how to get it
+func missingSassError(sources map[string]string, names []string) error {
+	files := make([]string, len(names))
+	for i, name := range names {
+		files[i] = sources[name]
+	}
+	return fmt.Errorf("compiling %s requires Dart Sass, but no %q executable is on PATH: install it with `npm install -g sass` or `brew install sass/sass/sass`, or download it from https://sass-lang.com/install",
+		strings.Join(files, ", "), sassBinary)
+}
+
+// parseSassError extracts the message and the innermost source position
+// from Dart Sass error output
+func parseSassError(src, output string, runErr error) error {
+	scssErr := &SCSSError{File: src, Message: strings.TrimSpace(output)}
+	if scssErr.Message == "" {
+		scssErr.Message = runErr.Error()
+	}
+
+	lines := strings.Split(output, "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "Error: ") {
+		scssErr.Message = strings.TrimPrefix(lines[0], "Error: ")
+	}
+	for _, line := range lines[1:] {
+		match := sassLocation.FindStringSubmatch(line + " ")
+		if match == nil || strings.ContainsAny(match[1], "|│╷╵") {
+			continue
+		}
+		scssErr.File = match[1]
+		scssErr.Line, _ = strconv.Atoi(match[2])
+		scssErr.Column, _ = strconv.Atoi(match[3])
+		break
+	}
+	return scssErr
+}
+
+// scssURL is the "scss" template function: it returns the URL of the
+// compiled stylesheet for a file in the assets directory
+func (b *Builder) scssURL(name string) (string, error) {
+	name = strings.TrimPrefix(util.SlashPath(name), "/")
+
+	b.scssMu.RLock()
+	url, ok := b.scssURLs[name]
+	b.scssMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("scss: stylesheet %q not found in %s", name, strings.Join(b.assetDirs(), ", "))
+	}
+	return b.config.RelURL(url), nil
+}
@@ -0,0 +1,336 @@
+package builder
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"vango/internal/content"
+	"vango/internal/util"
+)
+
+// applySectionConfig fills in the layout, type and params of a page
+// wherever its front matter leaves them unset: first from the cascade of
+// the _index files above it, nearest first, then from its section's config
+func (b *Builder) applySectionConfig(page *content.Page) {
+	if page.Kind != "page" {
+		return
+	}
+	var layout, pageType string
+	params := make(map[string]interface{})
+	for _, cascade := range b.pageCascades(page) {
+		if layout == "" {
+			layout, _ = cascade["layout"].(string)
+		}
+		if pageType == "" {
+			pageType, _ = cascade["type"].(string)
+		}
+		for key, value := range content.CascadeParams(cascade) {
+			if _, set := params[key]; !set {
+				params[key] = value
+			}
+		}
+	}
+	if section, ok := b.config.Sections[page.Section]; ok {
+		if layout == "" {
+			layout = section.DefaultLayout
+		}
+		if pageType == "" {
+			pageType = section.DefaultType
+		}
+		for key, value := range section.Params {
+			if _, set := params[key]; !set {
+				params[key] = value
+			}
+		}
+	}
+
+	if _, set := page.FrontMatter["layout"]; !set && layout != "" {
+		page.Layout = layout
+	}
+	if _, set := page.FrontMatter["type"]; !set && pageType != "" {
+		page.Type = pageType
+	}
+	if len(params) > 0 && page.Params == nil {
+		page.Params = make(map[string]interface{})
+	}
+	for key, value := range params {
+		if _, set := page.Params[key]; !set {
+			page.Params[key] = value
+		}
+	}
+}
+
+// pageCascades returns the cascade tables of the _index files in the
+// directories above page, nearest first
+func (b *Builder) pageCascades(page *content.Page) []map[string]interface{} {
+	rel, err := util.RelSlashPath(b.config.ContentDir, page.FilePath)
+	if err != nil || strings.HasPrefix(rel, "../") {
+		return nil
+	}
+	var cascades []map[string]interface{}
+	for dir := path.Dir(rel); ; dir = path.Dir(dir) {
+		if cascade := b.cascade(dir); len(cascade) > 0 {
+			cascades = append(cascades, cascade)
+		}
+		if dir == "." {
+			return cascades
+		}
+	}
+}
+
+// cascade returns the cascade table of the _index file in a directory
+// relative to the content directory, read once per build
+func (b *Builder) cascade(dir string) map[string]interface{} {
+	b.cascadesMu.Lock()
+	defer b.cascadesMu.Unlock()
+	if cascade, ok := b.cascades[dir]; ok {
+		return cascade
+	}
+	if b.cascades == nil {
+		b.cascades = make(map[string]map[string]interface{})
+	}
+	cascade := b.readCascade(dir)
+	b.cascades[dir] = cascade
+	return cascade
+}
+
+// readCascade reads the cascade table of the _index file in dir. A file
+// that fails to parse cascades nothing; parsing the page reports it.
+func (b *Builder) readCascade(dir string) map[string]interface{} {
+	index := content.SectionIndex(filepath.Join(b.config.ContentDir, filepath.FromSlash(dir)), b.config.ContentExtensions)
+	if index == "" {
+		return nil
+	}
+	frontMatter, err := b.parser.ParseFrontMatter(index)
+	if err != nil {
+		return nil
+	}
+	cascade, _ := frontMatter["cascade"].(map[string]interface{})
+	return cascade
+}
+
+// resetCascades forgets the cascades read by an earlier build
+func (b *Builder) resetCascades() {
+	b.cascadesMu.Lock()
+	b.cascades = nil
+	b.cascadesMu.Unlock()
+}
+
+// cascadeChanged reports whether a changed _index file now cascades
+// something else than the pages below it were built with
+func (b *Builder) cascadeChanged(file string) bool {
+	if !content.IsSectionIndex(file, b.config.ContentExtensions) {
+		return false
+	}
+	rel, err := util.RelSlashPath(b.config.ContentDir, file)
+	if err != nil {
+		return false
+	}
+	dir := path.Dir(rel)
+	b.cascadesMu.Lock()
+	old, ok := b.cascades[dir]
+	b.cascadesMu.Unlock()
+	if !ok {
+		return false
+	}
+	return !reflect.DeepEqual(old, b.readCascade(dir))
+}
+
+// rendersHTML reports whether the page is published as HTML, which its
+// section's outputs can turn off
+func (b *Builder) rendersHTML(page *content.Page) bool {
+	section, ok := b.config.Sections[page.Section]
+	return !ok || page.Kind != "page" || section.HasOutput("html")
+}
+
+// sectionPages groups the content pages by section, each in the order its
+// section's sortBy asks for
+func (b *Builder) sectionPages() map[string]content.Pages {
+	b.pagesMu.RLock()
+	defer b.pagesMu.RUnlock()
+
+	sections := make(map[string]content.Pages)
+	for _, page := range b.pages {
+		if page.Section != "" && page.Kind == "page" {
+			sections[page.Section] = append(sections[page.Section], page)
+		}
+	}
+	for name, pages := range sections {
+		switch b.config.Sections[name].SortBy {
+		case "weight":
+			sections[name] = pages.ByWeight()
+		case "title":
+			sections[name] = pages.ByTitle()
+		default:
+			sections[name] = pages.ByDate()
+		}
+	}
+	return sections
+}
+
+// linkSections sets each page's previous and next page within its section
+func (b *Builder) linkSections() {
+	for _, pages := range b.sectionPages() {
+		for i, page := range pages {
+			page.PrevInSection, page.NextInSection = nil, nil
+			if i > 0 {
+				page.PrevInSection = pages[i-1]
+			}
+			if i < len(pages)-1 {
+				page.NextInSection = pages[i+1]
+			}
+		}
+	}
+}
+
+// sectionIndexPages groups the section pages, from _index.md files, by the
+// section they belong to
+func (b *Builder) sectionIndexPages() map[string][]*content.Page {
+	b.pagesMu.RLock()
+	defer b.pagesMu.RUnlock()
+
+	indexes := make(map[string][]*content.Page)
+	for _, page := range b.pages {
+		if page.Kind == "section" {
+			indexes[page.Section] = append(indexes[page.Section], page)
+		}
+	}
+	return indexes
+}
+
+// sectionListPages returns the regular pages below a section page's
+// directory, in its section's order
+func (b *Builder) sectionListPages(page *content.Page) []*content.Page {
+	var pages []*content.Page
+	for _, candidate := range b.sectionPages()[page.Section] {
+		if strings.HasPrefix(candidate.Slug, page.Slug+"/") {
+			pages = append(pages, candidate)
+		}
+	}
+	return pages
+}
+
+// buildSections builds every section's navigation tree and exposes the
+// sections to templates as .Site.Sections. It returns the names of the
+// sections whose tree changed since the last call. Section pages head their
+// directories in the tree but aren't among a section's pages.
+func (b *Builder) buildSections() []string {
+	sections := make(map[string]*content.Section)
+	indexes := b.sectionIndexPages()
+	var changed []string
+	for name, pages := range b.sectionPages() {
+		tree := content.BuildSectionTree(name, append(content.Pages(indexes[name]), pages...))
+		section := &content.Section{Name: name, Pages: pages, Tree: tree}
+		sections[name] = section
+		if old, ok := b.sections[name]; !ok || treeOutline(old.Tree) != treeOutline(section.Tree) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range b.sections {
+		if _, ok := sections[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+
+	b.sections = sections
+	b.engine.SetSections(sections)
+	return changed
+}
+
+// rerenderSections renders the pages of the named sections again, so their
+// navigation reflects added, removed or retitled pages
+func (b *Builder) rerenderSections(names []string) error {
+	var pages []*content.Page
+	for _, name := range names {
+		if section, ok := b.sections[name]; ok {
+			pages = append(pages, section.Pages...)
+		}
+	}
+	return b.renderPages(pages)
+}
+
+// treeOutline summarizes what a navigation tree renders, to tell whether
+// pages showing it need rendering again
+func treeOutline(node *content.NavNode) string {
+	var out strings.Builder
+	var walk func(node *content.NavNode, depth int)
+	walk = func(node *content.NavNode, depth int) {
+		fmt.Fprintf(&out, "%d\t%s\t%s\n", depth, node.Title, node.URL)
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+	walk(node, 0)
+	return out.String()
+}
+
+// linkSeries sets the series fields of every page. It returns the pages
+// whose series navigation changed since the last call.
+func (b *Builder) linkSeries() []*content.Page {
+	b.pagesMu.RLock()
+	defer b.pagesMu.RUnlock()
+
+	before := make(map[*content.Page]string, len(b.pages))
+	for _, page := range b.pages {
+		before[page] = seriesOutline(page)
+	}
+	// Series configured as a taxonomy have a term page to link to
+	content.LinkSeries(b.pages, func(slug string) string {
+		if term, ok := b.taxonomies[content.SeriesField][slug]; ok {
+			return term.URL
+		}
+		return ""
+	})
+
+	var changed []*content.Page
+	for _, page := range b.pages {
+		if seriesOutline(page) != before[page] {
+			changed = append(changed, page)
+		}
+	}
+	return changed
+}
+
+// seriesOutline summarizes the series navigation a page renders
+func seriesOutline(page *content.Page) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s\t%d/%d", page.SeriesName, page.SeriesPosition, page.SeriesCount)
+	for _, part := range page.Series {
+		fmt.Fprintf(&out, "\t%s %s", part.Title, part.URL)
+	}
+	return out.String()
+}
+
+// generateSectionFeeds writes an RSS feed for every section whose outputs
+// include rss
+func (b *Builder) generateSectionFeeds() error {
+	sections := b.sectionPages()
+	names := make([]string, 0, len(b.config.Sections))
+	for name := range b.config.Sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !b.config.Sections[name].HasOutput("rss") {
+			continue
+		}
+		pages := sections[name]
+		link := strings.TrimSuffix(b.config.BaseURL, "/") + util.SlugURL(name)
+		title := strings.Title(strings.ReplaceAll(name, "-", " "))
+		var updated time.Time
+		if len(pages) > 0 {
+			updated = pages.ByDate()[0].ParsedDate
+		}
+		if err := b.writeFeed(name, title, link, updated, pages); err != nil {
+			return fmt.Errorf("section %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package builder
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"vango/internal/content"
+	"vango/internal/util"
+)
+
+// metaTagPattern matches meta tags, capturing their attributes
+var metaTagPattern = regexp.MustCompile(`(?is)<meta\b([^>]*)>`)
+
+// SEOIssue is a built page whose indexing signals contradict each other
+type SEOIssue struct {
+	Page    string `json:"page"`             // Page URL
+	Source  string `json:"source,omitempty"` // Content file, when there is one
+	Message string `json:"message"`
+}
+
+// CheckSEO reads the built sitemap and reports the pages it lists that ask
+// search engines not to index them, through front matter or a meta robots
+// tag in their HTML
+func (b *Builder) CheckSEO() ([]SEOIssue, error) {
+	if !b.config.SEO.EnableSitemap {
+		return nil, nil
+	}
+	filename := b.config.SEO.SitemapFilename
+	if filename == "" {
+		filename = "sitemap.xml"
+	}
+	data, err := os.ReadFile(filepath.Join(b.config.PublicDir, filename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var urlset sitemapURLSet
+	if err := xml.Unmarshal(data, &urlset); err != nil {
+		return nil, err
+	}
+	listed := make(map[string]bool, len(urlset.URLs))
+	for _, entry := range urlset.URLs {
+		listed[entry.Loc] = true
+	}
+
+	var issues []SEOIssue
+	pages := append(append([]*content.Page(nil), b.GetPages()...), b.taxonomyPages...)
+	for _, page := range pages {
+		if !listed[page.Permalink] {
+			continue
+		}
+		robots := page.Robots
+		if !page.NoIndex() {
+			robots = builtRobots(util.OutputPath(b.config.PublicDir, page.Slug, "index.html"))
+			if !content.RobotsNoIndex(robots) {
+				continue
+			}
+		}
+		issues = append(issues, SEOIssue{
+			Page:    page.URL,
+			Source:  page.FilePath,
+			Message: "listed in " + filename + " but marked " + robots,
+		})
+	}
+	return issues, nil
+}
+
+// builtRobots returns the content of the meta robots tag in a built HTML
+// file, or ""
+func builtRobots(file string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	for _, match := range metaTagPattern.FindAllStringSubmatch(string(data), -1) {
+		if name, _ := htmlAttr(match[1], "name"); strings.EqualFold(name, "robots") {
+			robots, _ := htmlAttr(match[1], "content")
+			return robots
+		}
+	}
+	return ""
+}
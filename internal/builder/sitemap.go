@@ -0,0 +1,80 @@
+package builder
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"vango/internal/content"
+)
+
+// sitemapURLSet is the root element of sitemap.xml
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL is a single sitemap entry
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// generateSitemap writes the sitemap for all built pages, plus taxonomy and
+// term pages when enabled. Pages whose canonical URL points at another host
+// are duplicates and are left out, as are noindex pages.
+func (b *Builder) generateSitemap() error {
+	if !b.config.SEO.EnableSitemap {
+		return nil
+	}
+	filename := b.config.SEO.SitemapFilename
+	if filename == "" {
+		filename = "sitemap.xml"
+	}
+
+	pages := b.GetPages()
+	sorted := make([]*content.Page, len(pages))
+	copy(sorted, pages)
+	if b.config.TaxonomyOptions.Sitemap {
+		sorted = append(sorted, b.taxonomyPages...)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].URL < sorted[j].URL })
+
+	urlset := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, page := range sorted {
+		if b.isOffSite(page.CanonicalURL) || !b.rendersHTML(page) || page.NoIndex() {
+			continue
+		}
+		entry := sitemapURL{Loc: page.Permalink}
+		if !page.LastMod.IsZero() {
+			entry.LastMod = page.LastMod.Format(time.RFC3339)
+		} else if page.Date != "" || (page.Kind != "page" && !page.ParsedDate.IsZero()) {
+			entry.LastMod = page.ParsedDate.Format(time.RFC3339)
+		}
+		urlset.URLs = append(urlset.URLs, entry)
+	}
+
+	data, err := xml.MarshalIndent(urlset, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filename, err)
+	}
+	return b.writeOutputFile(filename, append([]byte(xml.Header), data...))
+}
+
+// isOffSite reports whether an absolute URL points at a host other than the
+// site's BaseURL or canonical base URL
+func (b *Builder) isOffSite(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, base := range []string{b.config.BaseURL, b.config.GetCanonicalBaseURL()} {
+		if baseURL, err := url.Parse(base); err == nil && baseURL.Host == u.Host {
+			return false
+		}
+	}
+	return true
+}
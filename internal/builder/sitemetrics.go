@@ -0,0 +1,172 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// SiteMetrics describes a full build. It's published as the metrics JSON
+// file and is what the badge value template is executed with.
+type SiteMetrics struct {
+	Generator  string    `json:"generator"`
+	Pages      int       `json:"pages"`
+	Files      int       `json:"files"`
+	Size       int64     `json:"size_bytes"`
+	DurationMs int64     `json:"build_duration_ms,omitempty"` // Left out of reproducible builds
+	BuildTime  time.Time `json:"build_time"`
+	// NextPublishAt is when the next scheduled page goes live, with
+	// metrics.nextPublishAt
+	NextPublishAt *time.Time `json:"next_publish_at,omitempty"`
+}
+
+// HumanSize formats Size for the badge, e.g. "1.2 MB"
+func (m SiteMetrics) HumanSize() string {
+	return humanSize(m.Size)
+}
+
+// Duration returns the build duration, e.g. for {{ .Duration.Seconds }}
+func (m SiteMetrics) Duration() time.Duration {
+	return time.Duration(m.DurationMs) * time.Millisecond
+}
+
+// badgeTemplate renders a flat, shields.io style badge
+var badgeTemplate = template.Must(template.New("badge").Funcs(template.FuncMap{
+	"xml": template.HTMLEscapeString,
+}).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{ .Width }}" height="20" role="img" aria-label="{{ xml .Label }}: {{ xml .Value }}">
+<title>{{ xml .Label }}: {{ xml .Value }}</title>
+<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="{{ .Width }}" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)">
+<rect width="{{ .LabelWidth }}" height="20" fill="{{ xml .LabelColor }}"/>
+<rect x="{{ .LabelWidth }}" width="{{ .ValueWidth }}" height="20" fill="{{ xml .Color }}"/>
+<rect width="{{ .Width }}" height="20" fill="url(#s)"/>
+</g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="{{ .LabelX }}" y="15" fill="#010101" fill-opacity=".3" aria-hidden="true">{{ xml .Label }}</text>
+<text x="{{ .LabelX }}" y="14">{{ xml .Label }}</text>
+<text x="{{ .ValueX }}" y="15" fill="#010101" fill-opacity=".3" aria-hidden="true">{{ xml .Value }}</text>
+<text x="{{ .ValueX }}" y="14">{{ xml .Value }}</text>
+</g>
+</svg>
+`))
+
+// badge is the data badgeTemplate is executed with
+type badge struct {
+	Label, Value, Color, LabelColor string
+	LabelWidth, ValueWidth, Width   int
+	LabelX, ValueX                  float64
+}
+
+// writeSiteMetrics writes the metrics file and badge of a full build that
+// started at start, once every other output is written. They are data files
+// rather than pages, so the sitemap never lists them.
+func (b *Builder) writeSiteMetrics(start time.Time) error {
+	cfg := b.config.Metrics
+	if !cfg.Enable {
+		return nil
+	}
+
+	metrics, err := b.siteMetrics(start)
+	if err != nil {
+		return fmt.Errorf("failed to collect build metrics: %w", err)
+	}
+	data, err := json.MarshalIndent(metrics, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := b.writeOutputFile(cfg.Filename, append(data, '\n')); err != nil {
+		return err
+	}
+
+	if cfg.Badge.Enable {
+		svg, err := renderBadge(cfg.Badge.Label, cfg.Badge.Value, cfg.Badge.Color, cfg.Badge.LabelColor, metrics)
+		if err != nil {
+			return err
+		}
+		if err := b.writeOutputFile(cfg.Badge.Filename, svg); err != nil {
+			return err
+		}
+	}
+	logging.Debugf("📊 Wrote build metrics: %d pages, %d files, %s", metrics.Pages, metrics.Files, metrics.HumanSize())
+	return nil
+}
+
+// siteMetrics sums up the files written so far by the build
+func (b *Builder) siteMetrics(start time.Time) (SiteMetrics, error) {
+	metrics := SiteMetrics{
+		Generator: "VanGo " + Version,
+		Pages:     len(b.pages),
+		BuildTime: b.config.Now().UTC(),
+	}
+	if !b.config.Reproducible {
+		metrics.DurationMs = time.Since(start).Milliseconds()
+	}
+	if b.config.Metrics.NextPublishAt {
+		if next := b.NextPublishAt(); next != nil {
+			utc := next.UTC()
+			metrics.NextPublishAt = &utc
+		}
+	}
+	for _, relPath := range b.outputList() {
+		info, err := os.Stat(util.OutputPath(b.outputDir, relPath))
+		if os.IsNotExist(err) {
+			// Recorded but not written, e.g. theme assets shadowed by the site
+			continue
+		}
+		if err != nil {
+			return metrics, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		metrics.Files++
+		metrics.Size += info.Size()
+	}
+	return metrics, nil
+}
+
+// renderBadge renders the badge SVG, executing value with the metrics
+func renderBadge(label, value, color, labelColor string, metrics SiteMetrics) ([]byte, error) {
+	tmpl, err := template.New("value").Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metrics.badge.value: %w", err)
+	}
+	var text strings.Builder
+	if err := tmpl.Execute(&text, metrics); err != nil {
+		return nil, fmt.Errorf("invalid metrics.badge.value: %w", err)
+	}
+
+	data := badge{
+		Label:      label,
+		Value:      text.String(),
+		Color:      color,
+		LabelColor: labelColor,
+		LabelWidth: badgeTextWidth(label),
+		ValueWidth: badgeTextWidth(text.String()),
+	}
+	data.Width = data.LabelWidth + data.ValueWidth
+	data.LabelX = float64(data.LabelWidth) / 2
+	data.ValueX = float64(data.LabelWidth) + float64(data.ValueWidth)/2
+
+	var buf bytes.Buffer
+	if err := badgeTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// badgeTextWidth estimates the width of a badge half, text plus padding,
+// from the average width of 11px Verdana
+func badgeTextWidth(text string) int {
+	return int(math.Ceil(float64(utf8.RuneCountInString(text))*6.5)) + 10
+}
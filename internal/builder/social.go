@@ -0,0 +1,249 @@
+package builder
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"vango/internal/content"
+	"vango/internal/logging"
+)
+
+// socialImage is the "socialImage" template function. It returns the
+// absolute URL of the image shared with a page, the first that exists of:
+// the image or images[0] in its front matter, the first image in its
+// content, the image its section cascades or is configured with, and the
+// site's openGraph.defaultImage. It is "" when there is none.
+func (b *Builder) socialImage(page *content.Page) string {
+	return b.resolveSocialImage(page, b.config.Social.OpenGraph.DefaultImage)
+}
+
+// resolveSocialImage works through the image candidates for page, site
+// defaults last, warning about each one that names a missing file
+func (b *Builder) resolveSocialImage(page *content.Page, siteDefaults ...string) string {
+	if page == nil {
+		return ""
+	}
+	candidates := []string{frontMatterImage(page.FrontMatter)}
+	if len(page.Images) > 0 {
+		candidates = append(candidates, page.Images[0].Src)
+	}
+	candidates = append(candidates, b.sectionImage(page))
+	candidates = append(candidates, siteDefaults...)
+
+	for _, src := range candidates {
+		src = strings.TrimSpace(src)
+		if src == "" {
+			continue
+		}
+		if imageURL, ok := b.socialImageURL(page, src); ok {
+			return imageURL
+		}
+		b.warnMissingImage(page, src)
+	}
+	return ""
+}
+
+// frontMatterImage returns the image or first of the images set in front
+// matter
+func frontMatterImage(frontMatter map[string]interface{}) string {
+	if image, ok := frontMatter["image"].(string); ok && image != "" {
+		return image
+	}
+	switch images := frontMatter["images"].(type) {
+	case []interface{}:
+		if len(images) > 0 {
+			image, _ := images[0].(string)
+			return image
+		}
+	case []string:
+		if len(images) > 0 {
+			return images[0]
+		}
+	}
+	return ""
+}
+
+// sectionImage returns the image cascaded to page by the nearest _index.md
+// above it, or else the image param of its [sections] config
+func (b *Builder) sectionImage(page *content.Page) string {
+	b.refMu.RLock()
+	index := b.refs
+	b.refMu.RUnlock()
+
+	if index != nil && page.FilePath != "" {
+		rel := page.ContentPath(b.config.ContentDir)
+		dir := path.Dir(rel)
+		if strings.TrimSuffix(path.Base(rel), path.Ext(rel)) == "_index" {
+			// A section's own image comes from its front matter, not its cascade
+			dir = path.Dir(dir)
+		}
+		for ; dir != "." && dir != "/"; dir = path.Dir(dir) {
+			section, ok := index.byPath[dir+"/_index"]
+			if !ok {
+				continue
+			}
+			cascade, _ := section.FrontMatter["cascade"].(map[string]interface{})
+			if image, ok := cascade["image"].(string); ok && image != "" {
+				return image
+			}
+		}
+	}
+	image, _ := b.config.Sections[page.Section].Params["image"].(string)
+	return image
+}
+
+// socialImageURL makes src absolute. Relative paths are looked up in the
+// page's bundle and then the static directory; site paths must name a
+// static or theme file or a bundle resource. It reports false when the
+// file doesn't exist. Remote images are taken as they are.
+func (b *Builder) socialImageURL(page *content.Page, src string) (string, bool) {
+	if u, err := url.Parse(src); err != nil || u.Scheme != "" || u.Host != "" {
+		return src, err == nil
+	}
+	name := src
+	if i := strings.IndexAny(name, "?#"); i >= 0 {
+		name = name[:i]
+	}
+
+	if !strings.HasPrefix(name, "/") {
+		clean := path.Clean(name)
+		for _, resource := range page.Resources {
+			if resource.Name == clean && resource.ResourceType != "page" {
+				return resource.Permalink, true
+			}
+		}
+		if isFile(filepath.Join(b.config.StaticDir, filepath.FromSlash(clean))) {
+			return b.config.AbsURL(b.config.StaticPath(clean)), true
+		}
+		return "", false
+	}
+
+	// Site paths, which may carry the BaseURL path
+	if base := b.config.BaseURLPath(); base != "" && strings.HasPrefix(name, base+"/") {
+		name = strings.TrimPrefix(name, base)
+	}
+	rel := strings.TrimPrefix(path.Clean(name), "/")
+	if static, ok := b.config.CutStaticPrefix(rel); ok && isFile(filepath.Join(b.config.StaticDir, filepath.FromSlash(static))) {
+		return b.config.AbsURL("/" + rel), true
+	}
+	if themed, ok := strings.CutPrefix(rel, "theme/"); ok && isFile(filepath.Join(b.themeManager.GetThemeStaticPath(), filepath.FromSlash(themed))) {
+		return b.config.AbsURL("/" + rel), true
+	}
+	for _, other := range b.renderedPages() {
+		for _, resource := range other.Resources {
+			if resource.PublishPath == rel && resource.ResourceType != "page" {
+				return resource.Permalink, true
+			}
+		}
+	}
+	return "", false
+}
+
+// isFile reports whether a regular file exists at name
+func isFile(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && !info.IsDir()
+}
+
+// warnMissingImage warns once per build about a page's social image that
+// names a missing file
+func (b *Builder) warnMissingImage(page *content.Page, src string) {
+	key := page.FilePath + "\x00" + src
+	b.missingImagesMu.Lock()
+	warned := b.missingImages[key]
+	if b.missingImages == nil {
+		b.missingImages = make(map[string]bool)
+	}
+	b.missingImages[key] = true
+	b.missingImagesMu.Unlock()
+
+	if !warned {
+		where := page.FilePath
+		if where == "" {
+			where = page.URL
+		}
+		logging.Warnf("⚠️  %s: social image %q not found", where, src)
+	}
+}
+
+// resetMissingImages forgets the missing images warned about so far
+func (b *Builder) resetMissingImages() {
+	b.missingImagesMu.Lock()
+	b.missingImages = nil
+	b.missingImagesMu.Unlock()
+}
+
+// openGraph is the "openGraph" template function: the Open Graph meta tags
+// for a page, or nothing when openGraph.enable is off
+func (b *Builder) openGraph(page *content.Page) template.HTML {
+	cfg := b.config.Social.OpenGraph
+	if !cfg.Enable || page == nil {
+		return ""
+	}
+	kind := "website"
+	if page.DateSource != "" {
+		kind = "article"
+	}
+	siteName := cfg.SiteName
+	if siteName == "" {
+		siteName = b.config.Title
+	}
+
+	var tags strings.Builder
+	writeMeta(&tags, "property", "og:type", kind)
+	writeMeta(&tags, "property", "og:url", page.Permalink)
+	writeMeta(&tags, "property", "og:title", page.Title)
+	writeMeta(&tags, "property", "og:description", b.pageDescription(page))
+	writeMeta(&tags, "property", "og:site_name", siteName)
+	writeMeta(&tags, "property", "og:image", b.socialImage(page))
+	return template.HTML(tags.String())
+}
+
+// twitterCard is the "twitterCard" template function: the Twitter card meta
+// tags for a page, a large image card when it has an image, or nothing when
+// twitterCard.enable is off
+func (b *Builder) twitterCard(page *content.Page) template.HTML {
+	cfg := b.config.Social.TwitterCard
+	if !cfg.Enable || page == nil {
+		return ""
+	}
+	image := b.resolveSocialImage(page, cfg.DefaultImage, b.config.Social.OpenGraph.DefaultImage)
+	card := "summary"
+	if image != "" {
+		card = "summary_large_image"
+	}
+
+	var tags strings.Builder
+	writeMeta(&tags, "name", "twitter:card", card)
+	writeMeta(&tags, "name", "twitter:site", cfg.Site)
+	writeMeta(&tags, "name", "twitter:creator", cfg.Creator)
+	writeMeta(&tags, "name", "twitter:title", page.Title)
+	writeMeta(&tags, "name", "twitter:description", b.pageDescription(page))
+	writeMeta(&tags, "name", "twitter:image", image)
+	return template.HTML(tags.String())
+}
+
+// pageDescription returns the page's description, or the site's
+func (b *Builder) pageDescription(page *content.Page) string {
+	if page.Description != "" {
+		return page.Description
+	}
+	return b.config.Description
+}
+
+// writeMeta writes a meta tag, skipping empty values
+func writeMeta(tags *strings.Builder, attr, name, value string) {
+	if value == "" {
+		return
+	}
+	if tags.Len() > 0 {
+		tags.WriteString("\n    ")
+	}
+	fmt.Fprintf(tags, `<meta %s="%s" content="%s">`, attr, name, html.EscapeString(value))
+}
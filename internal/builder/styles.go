@@ -0,0 +1,86 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"vango/internal/util"
+)
+
+// UpdateStyles applies a change that only touched stylesheets: changed CSS
+// files are copied to the output and Sass sources recompiled. It returns the
+// URL paths of the stylesheets to swap in open pages, or none when the pages
+// had to be re-rendered instead because fingerprinted stylesheet URLs
+// changed.
+func (b *Builder) UpdateStyles(files []string) ([]string, error) {
+	var urls []string
+	sassChanged := false
+	for _, file := range files {
+		if isStylesheet(file) {
+			sassChanged = true
+			continue
+		}
+		url, err := b.copyStylesheet(file)
+		if err != nil {
+			return nil, err
+		}
+		if url != "" {
+			urls = append(urls, url)
+		}
+	}
+
+	if sassChanged {
+		urlsChanged, err := b.compileSCSS()
+		if err != nil {
+			return nil, err
+		}
+		if urlsChanged {
+			if err := b.renderPages(b.pages); err != nil {
+				return nil, fmt.Errorf("failed to re-render pages: %w", err)
+			}
+			return nil, nil
+		}
+		// Any compiled stylesheet may import the changed partial
+		b.scssMu.RLock()
+		for _, url := range b.scssURLs {
+			urls = append(urls, url)
+		}
+		b.scssMu.RUnlock()
+	}
+
+	// Pages link the stylesheets under the BaseURL path
+	for i, url := range urls {
+		urls[i] = b.config.BaseURLPath() + url
+	}
+	sort.Strings(urls)
+	return urls, nil
+}
+
+// copyStylesheet copies a changed CSS file from the site's or the theme's
+// static directory to the output and returns the URL it is served at, or
+// "" when the file is in neither or no longer exists
+func (b *Builder) copyStylesheet(file string) (string, error) {
+	for _, root := range b.staticRoots() {
+		if !inDir(root.dir, file) {
+			continue
+		}
+		rel, _ := util.RelSlashPath(absPath(root.dir), absPath(file))
+		if _, err := os.Stat(file); err != nil {
+			return "", nil
+		}
+		out := path.Join(root.prefix, rel)
+		dst := util.OutputPath(b.outputDir, out)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return "", fmt.Errorf("failed to create directory for %s: %w", out, err)
+		}
+		if err := b.copyFile(file, dst); err != nil {
+			return "", fmt.Errorf("failed to copy stylesheet %s: %w", file, err)
+		}
+		b.recordOutput(out)
+		return "/" + out, nil
+	}
+	return "", nil
+}
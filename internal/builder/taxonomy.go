@@ -0,0 +1,246 @@
+package builder
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"vango/internal/content"
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// contentModuleNS is the RSS content module, for full content in items
+const contentModuleNS = "http://purl.org/rss/1.0/modules/content/"
+
+// rssFeed is the root element of an RSS 2.0 feed
+type rssFeed struct {
+	XMLName      xml.Name   `xml:"rss"`
+	Version      string     `xml:"version,attr"`
+	ContentXMLNS string     `xml:"xmlns:content,attr,omitempty"`
+	Channel      rssChannel `xml:"channel"`
+}
+
+// rssChannel describes a feed and its items
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssItem is a single feed entry
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate,omitempty"`
+	Description string `xml:"description,omitempty"`
+	Content     string `xml:"content:encoded,omitempty"` // Full content, with feeds.fullContent
+}
+
+// buildTaxonomies groups the parsed pages into the configured taxonomies
+// and creates a list page for each taxonomy and a page for each term.
+// Term content from content/<taxonomy>/<term>/_index.md (and the taxonomy's
+// own _index.md) is taken out of the regular pages and attached instead.
+func (b *Builder) buildTaxonomies() {
+	taxonomies := b.config.GetTaxonomies()
+	plurals := b.taxonomyPlurals()
+
+	// Index pages stay out of pages between calls; one re-parsed by an
+	// incremental build is back in pages and replaces the one kept
+	b.pagesMu.Lock()
+	if b.taxonomyIndexes == nil {
+		b.taxonomyIndexes = make(map[string]*content.Page)
+	}
+	indexPages := b.taxonomyIndexes
+	pages := make([]*content.Page, 0, len(b.pages))
+	for _, page := range b.pages {
+		if dir, ok := taxonomyIndexDir(page, plurals); ok {
+			indexPages[dir] = page
+			continue
+		}
+		pages = append(pages, page)
+	}
+	b.pages = pages
+	b.pagesMu.Unlock()
+
+	built := content.BuildTaxonomies(pages, taxonomies)
+	lists := make(map[string][]*content.Page)
+	var taxonomyPages []*content.Page
+
+	names := make([]string, 0, len(built))
+	for plural := range built {
+		names = append(names, plural)
+	}
+	sort.Strings(names)
+
+	for _, plural := range names {
+		taxonomy := built[plural]
+		listPage := b.taxonomyPage(indexPages[plural], "taxonomy", plural, strings.Title(plural))
+
+		var termPages []*content.Page
+		for _, term := range taxonomy.Alphabetical() {
+			termPage := b.taxonomyPage(indexPages[plural+"/"+term.Slug], "term", plural+"/"+term.Slug, term.Name)
+			for _, page := range term.Pages {
+				if page.ParsedDate.After(termPage.ParsedDate) {
+					termPage.ParsedDate = page.ParsedDate
+				}
+			}
+			term.Page = termPage
+			lists[termPage.URL] = term.Pages
+			termPages = append(termPages, termPage)
+		}
+
+		lists[listPage.URL] = termPages
+		taxonomyPages = append(taxonomyPages, listPage)
+		taxonomyPages = append(taxonomyPages, termPages...)
+	}
+
+	b.taxonomies = built
+	b.taxonomyPages = taxonomyPages
+	b.taxonomyLists = lists
+	b.engine.SetTaxonomies(built)
+}
+
+// taxonomyPlurals returns the set of configured taxonomy plural names
+func (b *Builder) taxonomyPlurals() map[string]bool {
+	plurals := make(map[string]bool)
+	for _, plural := range b.config.GetTaxonomies() {
+		plurals[plural] = true
+	}
+	return plurals
+}
+
+// taxonomyIndexDir reports whether page is the _index page of a taxonomy
+// or one of its terms, returning its directory, e.g. "tags/go"
+func taxonomyIndexDir(page *content.Page, plurals map[string]bool) (string, bool) {
+	if page.Kind != "section" {
+		return "", false
+	}
+	dir := page.Slug
+	parts := strings.Split(dir, "/")
+	if len(parts) > 2 || !plurals[parts[0]] {
+		return "", false
+	}
+	return dir, true
+}
+
+// taxonomyPage returns the page rendered at /<slug>/ for a taxonomy or term,
+// built from its _index.md content when there is one
+func (b *Builder) taxonomyPage(index *content.Page, kind, slug, title string) *content.Page {
+	page := index
+	if page == nil {
+		page = &content.Page{
+			Title:    title,
+			Language: b.config.Language,
+			Params:   make(map[string]interface{}),
+		}
+	}
+	page.Kind = kind
+	page.Slug = slug
+	page.Section = util.FirstSegment(slug)
+	page.Type = page.Section
+	page.URL = util.SlugURL(slug)
+	page.CanonicalURL = ""
+	b.setPermalink(page)
+	return page
+}
+
+// pagesFor returns the pages listed on page: the tagged pages for a term,
+// the terms for a taxonomy, the pages below a section page, and every page
+// otherwise
+func (b *Builder) pagesFor(page *content.Page) []*content.Page {
+	if pages, ok := b.taxonomyLists[page.URL]; ok && page.Kind != "page" {
+		return pages
+	}
+	if page.Kind == "section" {
+		return b.sectionListPages(page)
+	}
+	return b.pages
+}
+
+// generateTaxonomies renders the taxonomy and term pages, and a feed for
+// each term when taxonomy feeds are enabled
+func (b *Builder) generateTaxonomies() error {
+	if len(b.taxonomyPages) == 0 {
+		return nil
+	}
+	if err := b.renderPages(b.taxonomyPages); err != nil {
+		return err
+	}
+	logging.Infof("🏷️  Generated %d taxonomy pages", len(b.taxonomyPages))
+
+	if !b.config.TaxonomyOptions.RSS || !b.config.SEO.EnableRSSFeed {
+		return nil
+	}
+	for _, page := range b.taxonomyPages {
+		if page.Kind != "term" {
+			continue
+		}
+		if err := b.writeTermFeed(page, b.taxonomyLists[page.URL]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTermFeed writes an RSS feed of a term's pages, newest first, next to
+// the term page
+func (b *Builder) writeTermFeed(term *content.Page, pages []*content.Page) error {
+	return b.writeFeed(term.Slug, term.Title, term.Permalink, term.ParsedDate, pages)
+}
+
+// writeFeed writes an RSS feed of pages, newest first, below slug, leaving
+// out noindex pages
+func (b *Builder) writeFeed(slug, title, link string, updated time.Time, pages []*content.Page) error {
+	filename := b.config.SEO.RSSFilename
+	if filename == "" {
+		filename = "feed.xml"
+	}
+
+	sorted := content.Pages(pages).ByDate()
+
+	channel := rssChannel{
+		Title:       title + " - " + b.config.Title,
+		Link:        link,
+		Description: b.config.Description,
+		Language:    b.config.Language,
+	}
+	if !updated.IsZero() {
+		channel.LastBuildDate = updated.Format(time.RFC1123Z)
+	}
+	for _, page := range sorted {
+		if page.NoIndex() {
+			continue
+		}
+		item := rssItem{
+			Title:       page.Title,
+			Link:        page.Permalink,
+			GUID:        page.Permalink,
+			Description: string(b.feedHTML(page, page.Summary)),
+		}
+		if b.config.Feeds.FullContent {
+			item.Content = string(b.feedHTML(page, page.Content))
+		}
+		if !page.ParsedDate.IsZero() {
+			item.PubDate = page.ParsedDate.Format(time.RFC1123Z)
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	feed := rssFeed{Version: "2.0", Channel: channel}
+	if b.config.Feeds.FullContent {
+		feed.ContentXMLNS = contentModuleNS
+	}
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode feed for %s: %w", util.SlugURL(slug), err)
+	}
+	return b.writeOutputFile(path.Join(slug, filename), append([]byte(xml.Header), data...))
+}
@@ -0,0 +1,105 @@
+package builder
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+
+	"vango/internal/util"
+)
+
+// rawOpenPattern matches the start of an element whose content is written
+// out byte for byte
+var rawOpenPattern = regexp.MustCompile(`(?i)<(pre|textarea|script|style)(?:[\s>/]|$)`)
+
+// whitespaceTrimmer is a streaming filter for rendered HTML that removes
+// trailing whitespace and collapses runs of blank lines into one, leaving
+// the content of pre, textarea, script and style elements untouched. It's
+// cheap enough to run on every build, development ones included.
+type whitespaceTrimmer struct {
+	w       io.Writer
+	pending []byte // Incomplete last line
+	raw     string // Element the current position is inside of, or ""
+	blank   bool   // The last line written was blank
+}
+
+// newWhitespaceTrimmer returns a trimmer writing to w. Call Flush once
+// everything has been written.
+func newWhitespaceTrimmer(w io.Writer) *whitespaceTrimmer {
+	return &whitespaceTrimmer{w: w}
+}
+
+// Write filters every complete line of p, keeping the rest for later
+func (t *whitespaceTrimmer) Write(p []byte) (int, error) {
+	t.pending = append(t.pending, p...)
+	for {
+		i := bytes.IndexByte(t.pending, '\n')
+		if i < 0 {
+			break
+		}
+		if err := t.writeLine(string(t.pending[:i]), true); err != nil {
+			return 0, err
+		}
+		t.pending = t.pending[i+1:]
+	}
+	return len(p), nil
+}
+
+// WriteString is Write for strings
+func (t *whitespaceTrimmer) WriteString(s string) (int, error) {
+	return t.Write([]byte(s))
+}
+
+// Flush writes the last line when the output doesn't end in a newline
+func (t *whitespaceTrimmer) Flush() error {
+	if len(t.pending) == 0 {
+		return nil
+	}
+	line := string(t.pending)
+	t.pending = nil
+	return t.writeLine(line, false)
+}
+
+// writeLine writes a line, trimmed unless it ends inside a preserved
+// element and dropped when it's blank and follows another blank line
+func (t *whitespaceTrimmer) writeLine(line string, newline bool) error {
+	startRaw := t.raw
+	t.raw = rawElementAtEnd(line, t.raw)
+
+	if t.raw == "" {
+		line = strings.TrimRight(line, " \t\r\f\v")
+	}
+	blank := startRaw == "" && t.raw == "" && line == ""
+	if blank && t.blank {
+		return nil
+	}
+	t.blank = blank
+
+	if newline {
+		line += "\n"
+	}
+	_, err := io.WriteString(t.w, line)
+	return err
+}
+
+// rawElementAtEnd returns the preserved element open at the end of line,
+// given the one open at its start
+func rawElementAtEnd(line, raw string) string {
+	for {
+		if raw != "" {
+			end := util.IndexFold(line, "</"+raw)
+			if end < 0 {
+				return raw
+			}
+			line = line[end+len(raw)+2:]
+			raw = ""
+		}
+		match := rawOpenPattern.FindStringSubmatchIndex(line)
+		if match == nil {
+			return ""
+		}
+		raw = strings.ToLower(line[match[2]:match[3]])
+		line = line[match[3]:]
+	}
+}
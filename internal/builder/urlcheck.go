@@ -0,0 +1,150 @@
+package builder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"vango/internal/content"
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// URL conflict kinds reported by CheckURLs
+const (
+	URLConflictSame  = "same-url" // Pages with the same URL
+	URLConflictCase  = "case"     // Page URLs that differ only in case
+	URLConflictAlias = "alias"    // An alias or redirect at a page's URL, or claimed twice
+)
+
+// configFileLabel stands in for the file of a [[redirects]] entry
+const configFileLabel = "config file"
+
+// URLConflict is a URL claimed by more than one page, alias or redirect
+type URLConflict struct {
+	Kind    string   `json:"kind"`
+	URL     string   `json:"url"`
+	Files   []string `json:"files"` // Every file claiming the URL
+	Message string   `json:"message"`
+}
+
+// CheckURLs parses the content, without rendering anything, and returns
+// the URLs claimed twice: pages sharing a URL, page URLs that differ only
+// in case and aliases or redirects overlapping a page or each other
+func (b *Builder) CheckURLs() ([]URLConflict, error) {
+	b.setupTheme()
+	b.resetCache()
+	if err := b.parseContentParallel(); err != nil {
+		return nil, fmt.Errorf("failed to parse content: %w", err)
+	}
+	return b.findURLConflicts(b.pages), nil
+}
+
+// checkURLs fails the build on URL conflicts between the parsed pages.
+// Case collisions only warn when caseCollisionLevel is "warning".
+func (b *Builder) checkURLs() error {
+	var errs []string
+	for _, conflict := range b.findURLConflicts(b.pages) {
+		if conflict.Kind == URLConflictCase && b.config.CaseCollisionLevel == "warning" {
+			logging.Warnf("⚠️  %s", conflict.Message)
+			continue
+		}
+		errs = append(errs, conflict.Message)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("conflicting URLs: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// findURLConflicts compares the URLs of pages, their aliases and the
+// configured redirects, ignoring wildcard redirects
+func (b *Builder) findURLConflicts(pages []*content.Page) []URLConflict {
+	byURL := make(map[string][]string)
+	for _, page := range pages {
+		byURL[page.URL] = append(byURL[page.URL], util.SlashPath(page.FilePath))
+	}
+	urls := make([]string, 0, len(byURL))
+	for url := range byURL {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	var conflicts []URLConflict
+	byFold := make(map[string][]string)
+	for _, url := range urls {
+		files := byURL[url]
+		if len(files) > 1 {
+			sort.Strings(files)
+			conflicts = append(conflicts, URLConflict{
+				Kind:    URLConflictSame,
+				URL:     url,
+				Files:   files,
+				Message: fmt.Sprintf("%s publish the same URL %s", joinFiles(files), url),
+			})
+		}
+		folded := strings.ToLower(url)
+		byFold[folded] = append(byFold[folded], url)
+	}
+	for _, url := range urls {
+		variants := byFold[strings.ToLower(url)]
+		if len(variants) < 2 || variants[0] != url {
+			continue
+		}
+		var files []string
+		for _, variant := range variants {
+			files = append(files, byURL[variant]...)
+		}
+		conflicts = append(conflicts, URLConflict{
+			Kind:    URLConflictCase,
+			URL:     url,
+			Files:   files,
+			Message: fmt.Sprintf("%s publish %s, which differ only in case and collide on case-insensitive file systems", joinFiles(files), strings.Join(variants, " and ")),
+		})
+	}
+
+	// Aliases and redirects may not land on a page or on each other
+	claimed := make(map[string]string)
+	claim := func(from, file, what string) {
+		if from == "" || strings.HasSuffix(from, "*") {
+			return
+		}
+		url := util.SlugURL(from)
+		if pageFiles, ok := byURL[url]; ok {
+			conflicts = append(conflicts, URLConflict{
+				Kind:    URLConflictAlias,
+				URL:     url,
+				Files:   append([]string{file}, pageFiles...),
+				Message: fmt.Sprintf("%s in %s is also the URL of %s", what, file, joinFiles(pageFiles)),
+			})
+			return
+		}
+		if other, ok := claimed[url]; ok {
+			conflicts = append(conflicts, URLConflict{
+				Kind:    URLConflictAlias,
+				URL:     url,
+				Files:   []string{other, file},
+				Message: fmt.Sprintf("%s and %s both redirect %s", other, file, url),
+			})
+			return
+		}
+		claimed[url] = file
+	}
+	for _, page := range pages {
+		for _, alias := range page.Aliases {
+			claim(alias, util.SlashPath(page.FilePath), "alias "+alias)
+		}
+	}
+	for _, redirect := range b.config.Redirects {
+		claim(redirect.From, configFileLabel, "redirect from "+redirect.From)
+	}
+	return conflicts
+}
+
+// joinFiles lists files as "a, b and c"
+func joinFiles(files []string) string {
+	if len(files) < 2 {
+		return strings.Join(files, "")
+	}
+	return strings.Join(files[:len(files)-1], ", ") + " and " + files[len(files)-1]
+}
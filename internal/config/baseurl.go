@@ -0,0 +1,189 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeBaseURL checks that raw is an absolute http or https URL with a
+// host and returns it with a lowercase scheme and host, unicode domains in
+// punycode and a trailing slash. Ports, paths and IP hosts, IPv6 ones in
+// brackets, are allowed.
+func NormalizeBaseURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", fmt.Errorf("invalid baseURL %q: %v", raw, err)
+	}
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid baseURL %q: %s (parsed scheme %q, host %q, path %q)", raw, reason, u.Scheme, u.Host, u.Path)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", invalid("must start with http:// or https://")
+	}
+	if u.Opaque != "" || u.Hostname() == "" {
+		return "", invalid("must have a host")
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", invalid("must not have a query or fragment")
+	}
+
+	host, err := normalizeHost(u.Hostname())
+	if err != nil {
+		return "", invalid(err.Error())
+	}
+	if port := u.Port(); port != "" {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+	}
+
+	u.Scheme = scheme
+	u.Host = host
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+		if u.RawPath != "" {
+			u.RawPath += "/"
+		}
+	}
+	return u.String(), nil
+}
+
+// normalizeHost lowercases a host and punycodes its unicode labels. IPv6
+// addresses come back in brackets.
+func normalizeHost(host string) (string, error) {
+	if strings.Contains(host, ":") {
+		if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+			return "[" + ip.String() + "]", nil
+		}
+		return "", fmt.Errorf("host %q is not an IPv6 address", host)
+	}
+	if net.ParseIP(host) != nil {
+		return host, nil
+	}
+
+	labels := strings.Split(strings.ToLower(norm.NFC.String(host)), ".")
+	for i, label := range labels {
+		if label == "" {
+			return "", fmt.Errorf("host %q has an empty label", host)
+		}
+		if isASCII(label) {
+			for _, r := range label {
+				if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+					return "", fmt.Errorf("host %q contains %q", host, r)
+				}
+			}
+			continue
+		}
+		labels[i] = "xn--" + punycode(label)
+	}
+	return strings.Join(labels, "."), nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// Punycode parameters from RFC 3492
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+)
+
+// punycode encodes a domain label as RFC 3492 describes, without the
+// "xn--" prefix
+func punycode(label string) string {
+	runes := []rune(label)
+	var out []byte
+	for _, r := range runes {
+		if r < 0x80 {
+			out = append(out, byte(r))
+		}
+	}
+	basic := len(out)
+	if basic > 0 {
+		out = append(out, '-')
+	}
+
+	n, delta, bias := punyInitialN, 0, punyInitialBias
+	for handled := basic; handled < len(runes); {
+		next := int(^uint32(0) >> 1)
+		for _, r := range runes {
+			if int(r) >= n && int(r) < next {
+				next = int(r)
+			}
+		}
+		delta += (next - n) * (handled + 1)
+		n = next
+		for _, r := range runes {
+			if int(r) < n {
+				delta++
+				continue
+			}
+			if int(r) > n {
+				continue
+			}
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := k - bias
+				if t < punyTMin {
+					t = punyTMin
+				} else if t > punyTMax {
+					t = punyTMax
+				}
+				if q < t {
+					break
+				}
+				out = append(out, punyDigit(t+(q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			out = append(out, punyDigit(q))
+			bias = punyAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+	return string(out)
+}
+
+func punyDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+func punyAdapt(delta, points int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / points
+	k := 0
+	for delta > (punyBase-punyTMin)*punyTMax/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
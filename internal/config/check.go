@@ -0,0 +1,291 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"vango/internal/util"
+)
+
+// Issue severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue is a problem Check found in a configuration
+type Issue struct {
+	Severity string `json:"severity"`
+	Setting  string `json:"setting"` // Configuration key the issue is about
+	Message  string `json:"message"`
+	Fix      string `json:"fix,omitempty"` // What Apply does to repair it, if it can
+	fix      func() error
+}
+
+// Fixable reports whether Apply can repair the issue
+func (i Issue) Fixable() bool {
+	return i.fix != nil
+}
+
+// Apply repairs the issue, for the issues that are Fixable
+func (i Issue) Apply() error {
+	if i.fix == nil {
+		return fmt.Errorf("%s cannot be fixed automatically", i.Setting)
+	}
+	return i.fix()
+}
+
+// String formats the issue for text output
+func (i Issue) String() string {
+	return i.Setting + ": " + i.Message
+}
+
+// SetDeferChecks leaves the checks Check covers to the caller: unknown keys
+// are collected instead of logged and missing or nested directories no
+// longer fail LoadConfig
+func (cl *ConfigLoader) SetDeferChecks(deferChecks bool) {
+	cl.deferChecks = deferChecks
+}
+
+// Check looks for settings that parse but can't work together: missing
+// directories and themes, an output directory nested in the sources, and a
+// baseURL at odds with the server settings. Errors are sorted before
+// warnings.
+func Check(cfg *Config) []Issue {
+	var issues []Issue
+	issues = append(issues, checkDirectories(cfg)...)
+	issues = append(issues, checkTheme(cfg)...)
+	issues = append(issues, checkLanguages(cfg)...)
+	issues = append(issues, checkBaseURL(cfg)...)
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Severity == SeverityError && issues[j].Severity != SeverityError
+	})
+	return issues
+}
+
+// missingDir reports a directory that doesn't exist, which Apply creates
+func missingDir(severity, setting, dir, why string) Issue {
+	return Issue{
+		Severity: severity,
+		Setting:  setting,
+		Message:  fmt.Sprintf("directory %q does not exist%s", dir, why),
+		Fix:      fmt.Sprintf("create %q", dir),
+		fix:      func() error { return os.MkdirAll(dir, 0755) },
+	}
+}
+
+// dirExists reports whether dir exists and is a directory
+func dirExists(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
+
+// checkDirectories checks the site's source directories exist and the
+// output directory can be cleaned and rebuilt without touching them
+func checkDirectories(cfg *Config) []Issue {
+	var issues []Issue
+	required := []struct{ key, dir string }{
+		{"contentDir", cfg.ContentDir},
+		{"layoutDir", cfg.LayoutDir},
+	}
+	for _, source := range required {
+		if !dirExists(source.dir) {
+			issues = append(issues, missingDir(SeverityError, source.key, source.dir, ": builds need it"))
+		}
+	}
+	optional := []struct{ key, dir string }{
+		{"staticDir", cfg.StaticDir},
+		{"dataDir", cfg.DataDir},
+	}
+	for _, source := range optional {
+		if source.dir != "" && !dirExists(source.dir) {
+			issues = append(issues, missingDir(SeverityWarning, source.key, source.dir, ""))
+		}
+	}
+
+	sources := []struct{ key, dir string }{
+		{"contentDir", cfg.ContentDir},
+		{"layoutDir", cfg.LayoutDir},
+		{"staticDir", cfg.StaticDir},
+		{"assetsDir", cfg.AssetsDir},
+	}
+	for _, source := range sources {
+		if source.dir == "" {
+			continue
+		}
+		if util.IsWithin(cfg.PublicDir, source.dir) {
+			issues = append(issues, Issue{
+				Severity: SeverityError,
+				Setting:  "publicDir",
+				Message:  fmt.Sprintf("%q is inside %s %q: builds would write into their own sources", cfg.PublicDir, source.key, source.dir),
+			})
+		} else if util.IsWithin(source.dir, cfg.PublicDir) {
+			issues = append(issues, Issue{
+				Severity: SeverityError,
+				Setting:  "publicDir",
+				Message:  fmt.Sprintf("%q contains %s %q: clean builds replace the output directory and would delete it", cfg.PublicDir, source.key, source.dir),
+			})
+		}
+	}
+	return issues
+}
+
+// checkTheme checks the configured theme is installed
+func checkTheme(cfg *Config) []Issue {
+	if cfg.Theme == "" {
+		return nil
+	}
+	themesDir := cfg.ThemesDir
+	if themesDir == "" {
+		themesDir = "themes"
+	}
+	if dirExists(filepath.Join(themesDir, cfg.Theme)) {
+		return nil
+	}
+
+	message := fmt.Sprintf("theme %q is not installed in %s, so builds fall back to the default theme", cfg.Theme, themesDir)
+	entries, _ := os.ReadDir(themesDir)
+	var installed []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			installed = append(installed, entry.Name())
+		}
+	}
+	if len(installed) > 0 {
+		message += " (installed: " + strings.Join(installed, ", ") + ")"
+	}
+	return []Issue{{Severity: SeverityError, Setting: "theme", Message: message}}
+}
+
+// checkLanguages checks each language's content directory exists and the
+// default content language is one of the languages
+func checkLanguages(cfg *Config) []Issue {
+	var issues []Issue
+	codes := make([]string, 0, len(cfg.Languages))
+	for code := range cfg.Languages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		dir := cfg.Languages[code].ContentDir
+		if dir != "" && !dirExists(dir) {
+			issues = append(issues, missingDir(SeverityError, "languages."+code+".contentDir", dir, ""))
+		}
+	}
+	if len(codes) > 0 && cfg.DefaultContentLanguage != "" {
+		if _, ok := cfg.Languages[cfg.DefaultContentLanguage]; !ok {
+			issues = append(issues, Issue{
+				Severity: SeverityError,
+				Setting:  "defaultContentLanguage",
+				Message:  fmt.Sprintf("%q is not one of the configured languages (%s)", cfg.DefaultContentLanguage, strings.Join(codes, ", ")),
+			})
+		}
+	}
+	return issues
+}
+
+// checkBaseURL checks the baseURL as written in the configuration files and
+// against the HTTPS settings of the development server
+func checkBaseURL(cfg *Config) []Issue {
+	var issues []Issue
+	if file, raw := baseURLSetting(cfg.ConfigFiles); raw != "" && !strings.HasSuffix(raw, "/") {
+		issues = append(issues, Issue{
+			Severity: SeverityWarning,
+			Setting:  "baseURL",
+			Message:  fmt.Sprintf("%q in %s has no trailing slash; one is added when loading", raw, file),
+			Fix:      fmt.Sprintf("add the trailing slash in %s", file),
+			fix:      func() error { return fixBaseURL(file) },
+		})
+	}
+
+	u, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return issues
+	}
+	local := u.Hostname() == "localhost" || u.Hostname() == "127.0.0.1"
+	https := cfg.Security.HTTPS
+	switch {
+	case https.Enable && local && u.Scheme == "http":
+		issues = append(issues, Issue{
+			Severity: SeverityWarning,
+			Setting:  "baseURL",
+			Message:  fmt.Sprintf("%q uses http but security.https.enable serves the development site over https, so absolute links leave it", cfg.BaseURL),
+		})
+	case https.HSTS && !https.Enable:
+		issues = append(issues, Issue{
+			Severity: SeverityWarning,
+			Setting:  "security.https.hsts",
+			Message:  "has no effect without security.https.enable",
+		})
+	}
+	if cfg.Environment == "production" && local {
+		issues = append(issues, Issue{
+			Severity: SeverityWarning,
+			Setting:  "baseURL",
+			Message:  fmt.Sprintf("%q points to this machine in the production environment", cfg.BaseURL),
+		})
+	}
+	return issues
+}
+
+// baseURLPattern matches a top-level baseURL line in a TOML or YAML file,
+// capturing the text before the value, the value and its closing quote
+var baseURLPattern = regexp.MustCompile(`(?m)^(baseURL\s*[=:]\s*["']?)([^"'\s#]*)(["']?)`)
+
+// tableHeaderPattern matches the first TOML table header, after which keys
+// are no longer top level
+var tableHeaderPattern = regexp.MustCompile(`(?m)^\s*\[`)
+
+// baseURLSetting returns the last of files to set baseURL, with the value
+// as written
+func baseURLSetting(files []string) (string, string) {
+	for i := len(files) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(files[i])
+		if err != nil {
+			continue
+		}
+		if match := baseURLPattern.FindSubmatch(topLevel(data)); match != nil {
+			return files[i], string(match[2])
+		}
+	}
+	return "", ""
+}
+
+// topLevel returns the part of a configuration file before its first TOML
+// table, the whole file for YAML
+func topLevel(data []byte) []byte {
+	if loc := tableHeaderPattern.FindIndex(data); loc != nil {
+		return data[:loc[0]]
+	}
+	return data
+}
+
+// fixBaseURL adds the trailing slash to the baseURL set in file, leaving
+// the rest of the file as it is
+func fixBaseURL(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	head := topLevel(data)
+	loc := baseURLPattern.FindSubmatchIndex(head)
+	if loc == nil {
+		return fmt.Errorf("baseURL not found in %s", file)
+	}
+	fixed := make([]byte, 0, len(data)+1)
+	fixed = append(fixed, data[:loc[5]]...)
+	fixed = append(fixed, '/')
+	fixed = append(fixed, data[loc[5]:]...)
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, fixed, info.Mode())
+}
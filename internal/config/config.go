@@ -2,10 +2,17 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
-	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"vango/internal/util"
 
 	"github.com/pelletier/go-toml"
 	"gopkg.in/yaml.v2"
@@ -16,10 +23,18 @@ type Config struct {
 	// Basic site information
 	Title         string            `toml:"title" yaml:"title"`
 	BaseURL       string            `toml:"baseURL" yaml:"baseURL"`
+	// BaseTag adds a <base> tag for the BaseURL path to every page and
+	// keeps generated links relative to it, instead of prefixing each link
+	// with the path of a subpath deployment
+	BaseTag       bool              `toml:"baseTag" yaml:"baseTag"`
 	Language      string            `toml:"language" yaml:"language"`
+	// TimeZone is the IANA zone, e.g. "Europe/Berlin", for dates written
+	// without an offset; empty means UTC
+	TimeZone      string            `toml:"timeZone" yaml:"timeZone"`
 	Description   string            `toml:"description" yaml:"description"`
 	Author        string            `toml:"author" yaml:"author"`
 	Theme         string            `toml:"theme" yaml:"theme"`
+	Themes        ThemesConfig      `toml:"themes" yaml:"themes"`
 	Params        map[string]interface{} `toml:"params" yaml:"params"`
 	
 	// Directory configuration
@@ -30,13 +45,33 @@ type Config struct {
 	ThemesDir     string `toml:"themesDir" yaml:"themesDir"`
 	DataDir       string `toml:"dataDir" yaml:"dataDir"`
 	AssetsDir     string `toml:"assetsDir" yaml:"assetsDir"`
+	I18nDir       string `toml:"i18nDir" yaml:"i18nDir"`
+	// StaticAtRoot publishes the static directory at the site root instead
+	// of under /static/
+	StaticAtRoot  bool     `toml:"staticAtRoot" yaml:"staticAtRoot"`
+	// RootFiles are files in the static directory also published at the
+	// site root, such as favicon.ico
+	RootFiles     []string `toml:"rootFiles" yaml:"rootFiles"`
 	
 	// Build configuration
 	BuildDrafts   bool     `toml:"buildDrafts" yaml:"buildDrafts"`
 	BuildFuture   bool     `toml:"buildFuture" yaml:"buildFuture"`
 	BuildExpired  bool     `toml:"buildExpired" yaml:"buildExpired"`
 	CleanBuild    bool     `toml:"cleanBuild" yaml:"cleanBuild"`
+	KeepPrevious  int      `toml:"keepPrevious" yaml:"keepPrevious"`
+	CleanOrphans  bool     `toml:"cleanOrphans" yaml:"cleanOrphans"`
+	PruneDryRun   bool     `toml:"-" yaml:"-"`
+	ProtectedFiles []string `toml:"protectedFiles" yaml:"protectedFiles"`
+	// ModifiedOutputLevel is what a build does about generated files edited
+	// since the previous build wrote them: "warning" (the default) lists
+	// them, "error" fails the build and "off" doesn't record output hashes
+	ModifiedOutputLevel string `toml:"modifiedOutputLevel" yaml:"modifiedOutputLevel"`
+	ForceOverwrite bool    `toml:"-" yaml:"-"` // Overwrite edited output files silently
 	Watch         bool     `toml:"watch" yaml:"watch"`
+	// Reproducible makes two builds of the same source byte-identical by
+	// pinning every use of the current time to BuildTime
+	Reproducible  bool     `toml:"reproducible" yaml:"reproducible"`
+	BuildTime     time.Time `toml:"-" yaml:"-"` // Fixed build time; zero means the wall clock
 	Workers       int      `toml:"workers" yaml:"workers"`
 	
 	// Server configuration
@@ -44,17 +79,45 @@ type Config struct {
 	Host          string   `toml:"host" yaml:"host"`
 	LiveReload    bool     `toml:"liveReload" yaml:"liveReload"`
 	DevMode       bool     `toml:"devMode" yaml:"devMode"`
+	Notify        bool     `toml:"notify" yaml:"notify"`               // Desktop notification when a serve build fails or recovers
+	NotifyCommand string   `toml:"notifyCommand" yaml:"notifyCommand"` // Runs as `command title body` instead of notify-send/osascript
+	
+	// Development server behavior
+	Routing       RoutingConfig `toml:"routing" yaml:"routing"`
+	Server        ServerConfig  `toml:"server" yaml:"server"` // Development server only, never part of the built site
 	
 	// Content processing
 	DefaultContentType string   `toml:"defaultContentType" yaml:"defaultContentType"`
+	// ContentExtensions are the extensions of content pages, matched
+	// ignoring case
+	ContentExtensions  []string `toml:"contentExtensions" yaml:"contentExtensions"`
 	DefaultLayout      string   `toml:"defaultLayout" yaml:"defaultLayout"`
 	SummaryLength      int      `toml:"summaryLength" yaml:"summaryLength"`
+	// Ellipsis ends truncated summaries, excerpts and truncate output
+	Ellipsis           string   `toml:"ellipsis" yaml:"ellipsis"`
+	ReadingTime        ReadingTimeConfig `toml:"readingTime" yaml:"readingTime"`
+	Pagination         PaginationConfig  `toml:"pagination" yaml:"pagination"`
+	// DateFromFilename dates pages named like 2024-01-15-my-post.md whose
+	// front matter has no date, and drops the date from their URL unless
+	// KeepFilenameDate is set
+	DateFromFilename   bool     `toml:"dateFromFilename" yaml:"dateFromFilename"`
+	KeepFilenameDate   bool     `toml:"keepFilenameDate" yaml:"keepFilenameDate"`
+	// TitleCaseStyle cases titles derived from file names: "title" (the
+	// default), "chicago" to keep small words lowercase, or "none"
+	TitleCaseStyle     string   `toml:"titleCaseStyle" yaml:"titleCaseStyle"`
+	// FrontMatterTemplates evaluates front matter string values containing
+	// {{ }} as templates with the site config as .Site when pages are parsed
+	FrontMatterTemplates bool   `toml:"frontMatterTemplates" yaml:"frontMatterTemplates"`
 	
 	// URL configuration
 	PrettyURLs        bool              `toml:"prettyURLs" yaml:"prettyURLs"`
 	CanonicalifyURLs  bool              `toml:"canonicalifyURLs" yaml:"canonicalifyURLs"`
 	RelativeURLs      bool              `toml:"relativeURLs" yaml:"relativeURLs"`
 	UglyURLs          bool              `toml:"uglyURLs" yaml:"uglyURLs"`
+	// CaseCollisionLevel is "error" to fail the build on page URLs that
+	// differ only in case, which collide on case-insensitive file systems,
+	// or "warning" to log them
+	CaseCollisionLevel string           `toml:"caseCollisionLevel" yaml:"caseCollisionLevel"`
 	
 	// Markup configuration
 	Markup            MarkupConfig      `toml:"markup" yaml:"markup"`
@@ -62,13 +125,24 @@ type Config struct {
 	// Multilingual support
 	Languages         map[string]Language `toml:"languages" yaml:"languages"`
 	DefaultContentLanguage string         `toml:"defaultContentLanguage" yaml:"defaultContentLanguage"`
+	// WarnMissingTranslations reports i18n keys that fall back to the
+	// default language during the build
+	WarnMissingTranslations bool          `toml:"warnMissingTranslations" yaml:"warnMissingTranslations"`
 	
 	// SEO and social
 	SEO               SEOConfig         `toml:"seo" yaml:"seo"`
+	Feeds             FeedsConfig       `toml:"feeds" yaml:"feeds"`
 	Social            SocialConfig      `toml:"social" yaml:"social"`
+	Analytics         AnalyticsConfig   `toml:"analytics" yaml:"analytics"`
+	Favicon           FaviconConfig     `toml:"favicon" yaml:"favicon"`
 	
 	// Performance and optimization
 	Performance       PerformanceConfig `toml:"performance" yaml:"performance"`
+	Budget            BudgetConfig      `toml:"budget" yaml:"budget"`
+	
+	// Template output
+	Templates         TemplatesConfig   `toml:"templates" yaml:"templates"`
+	HTMLValidation    HTMLValidationConfig `toml:"htmlValidation" yaml:"htmlValidation"`
 	
 	// Security
 	Security          SecurityConfig    `toml:"security" yaml:"security"`
@@ -82,7 +156,75 @@ type Config struct {
 	// Environment-specific overrides
 	Environment       string            `toml:"environment" yaml:"environment"`
 	Environments      map[string]EnvConfig `toml:"environments" yaml:"environments"`
+	
+	// Redirects generated for the hosting platform and honored by the dev server
+	Redirects         []Redirect        `toml:"redirects" yaml:"redirects"`
+	RedirectOptions   RedirectOptions   `toml:"redirectOptions" yaml:"redirectOptions"`
+	
+	// Taxonomies map singular names to plural ones; the plural names the
+	// front matter field and the URL section. Setting it replaces the defaults.
+	Taxonomies        map[string]string `toml:"taxonomies" yaml:"taxonomies"`
+	TaxonomyOptions   TaxonomyOptions   `toml:"taxonomyOptions" yaml:"taxonomyOptions"`
+	
+	// Sections holds per-section page defaults, e.g. [sections.projects]
+	// for every page under content/projects/
+	Sections          map[string]SectionConfig `toml:"sections" yaml:"sections"`
+	
+	// Menus are named navigation menus, e.g. [[menus.main]], available to
+	// templates as .Site.Menus.main
+	Menus             map[string][]MenuEntry `toml:"menus" yaml:"menus"`
+	
+	// RefLinksErrorLevel is "error" to fail the build on a ref or relref to
+	// a missing page, or "warning" to log it and link to RefLinksNotFoundURL
+	RefLinksErrorLevel  string          `toml:"refLinksErrorLevel" yaml:"refLinksErrorLevel"`
+	RefLinksNotFoundURL string          `toml:"refLinksNotFoundURL" yaml:"refLinksNotFoundURL"`
+	
+	// Build metrics and badge published with the site
+	Metrics           MetricsConfig     `toml:"metrics" yaml:"metrics"`
+	
+	// Preview deploys
+	CanonicalBaseURL  string            `toml:"canonicalBaseURL" yaml:"canonicalBaseURL"`
+	Preview           PreviewConfig     `toml:"preview" yaml:"preview"`
+	IsPreview         bool              `toml:"-" yaml:"-"`
+	// IsServing is set for builds made by the development server
+	IsServing         bool              `toml:"-" yaml:"-"`
+
+	// ConfigFiles are the files the configuration was merged from, in order
+	ConfigFiles       []string          `toml:"-" yaml:"-"`
 }
+
+// RoutingConfig makes the development server resolve page URLs the way the
+// hosting platform will, so URL mistakes show up before deploying
+type RoutingConfig struct {
+	// TrailingSlash is "redirect" to send /posts to /posts/ with a 301, as
+	// GitHub Pages and most static hosts do, "serve" to serve /posts as is,
+	// or "strict" to answer 404 like hosts without directory fallback
+	TrailingSlash     string `toml:"trailingSlash" yaml:"trailingSlash"`
+	// CaseInsensitive redirects URLs that only match a page in another
+	// case, with a warning, instead of answering 404
+	CaseInsensitive   bool   `toml:"caseInsensitive" yaml:"caseInsensitive"`
+}
+
+// ServerConfig holds development server settings
+type ServerConfig struct {
+	// Proxies forward requests under a path prefix to a backend, so pages
+	// fetch its API from their own origin, e.g. "/api/" = "http://localhost:4000"
+	Proxies           map[string]ProxyConfig `toml:"proxies" yaml:"proxies"`
+}
+
+// ProxyConfig is the backend of a proxied path prefix, written as its URL
+// alone or as a table with options
+type ProxyConfig struct {
+	Target            string            `toml:"target" yaml:"target"`
+	// Rewrite replaces the prefix in forwarded paths, e.g. "/" to send
+	// /api/users to /users; empty keeps the path as it is
+	Rewrite           string            `toml:"rewrite" yaml:"rewrite"`
+	Headers           map[string]string `toml:"headers" yaml:"headers"` // Set on every forwarded request
+	// Insecure accepts any certificate from an https target, for backends
+	// with self-signed ones
+	Insecure          bool              `toml:"insecure" yaml:"insecure"`
+}
+
 // MarkupConfig configures markdown processing
 type MarkupConfig struct {
 	Goldmark          GoldmarkConfig    `toml:"goldmark" yaml:"goldmark"`
@@ -157,6 +299,48 @@ type SEOConfig struct {
 	MetaGenerator     bool     `toml:"metaGenerator" yaml:"metaGenerator"`
 }
 
+// FeedsConfig controls the page content in feeds, which is also what the
+// feedHTML template function produces for search indexes
+type FeedsConfig struct {
+	// FullContent adds each page's whole content to its feed item; items
+	// otherwise only carry the summary
+	FullContent       bool     `toml:"fullContent" yaml:"fullContent"`
+	// Remove lists the elements taken out, by tag name, class or both:
+	// "iframe", ".embed", "div.embed"
+	Remove            []string `toml:"remove" yaml:"remove"`
+	// Replacement is the HTML put in place of each removed element
+	Replacement       string   `toml:"replacement" yaml:"replacement"`
+}
+
+// AnalyticsProviders are the analytics.provider values VanGo has snippets for
+var AnalyticsProviders = []string{"plausible", "goatcounter", "umami", "gtag", "custom"}
+
+// AnalyticsConfig adds a web analytics script to the built pages
+type AnalyticsConfig struct {
+	// Provider is one of AnalyticsProviders; empty turns analytics off
+	Provider          string   `toml:"provider" yaml:"provider"`
+	// SiteID identifies the site to the provider: the Plausible domain
+	// (the baseURL host by default), the GoatCounter code, the Umami
+	// website ID or the Google Analytics measurement ID
+	SiteID            string   `toml:"siteID" yaml:"siteID"`
+	// URL replaces the provider's hosted script or endpoint, for
+	// self-hosted instances
+	URL               string   `toml:"url" yaml:"url"`
+	// RespectDoNotTrack skips loading the script for visitors whose
+	// browser sends Do Not Track
+	RespectDoNotTrack bool     `toml:"respectDoNotTrack" yaml:"respectDoNotTrack"`
+	// ProductionOnly limits the snippet to the production environment
+	ProductionOnly    bool     `toml:"productionOnly" yaml:"productionOnly"`
+	// Serve adds the snippet to pages from the development server too
+	Serve             bool     `toml:"serve" yaml:"serve"`
+	// Inject adds the snippet before </body> on pages whose templates
+	// don't call analyticsSnippet
+	Inject            bool     `toml:"inject" yaml:"inject"`
+	// Template is the custom provider's snippet, a Go template given
+	// .SiteID and .URL
+	Template          string   `toml:"template" yaml:"template"`
+}
+
 // SocialConfig configures social media integration
 type SocialConfig struct {
 	Twitter           string   `toml:"twitter" yaml:"twitter"`
@@ -183,6 +367,21 @@ type TwitterCardConfig struct {
 	DefaultImage      string `toml:"defaultImage" yaml:"defaultImage"`
 }
 
+// TemplatesConfig controls how rendered templates are written out
+type TemplatesConfig struct {
+	// TrimWhitespace removes trailing whitespace and collapses runs of
+	// blank lines in generated pages, leaving pre, textarea, script and
+	// style content as it is
+	TrimWhitespace bool `toml:"trimWhitespace" yaml:"trimWhitespace"`
+}
+
+// HTMLValidationConfig checks the markup of each generated page for
+// structural problems as it is rendered
+type HTMLValidationConfig struct {
+	Enable          bool `toml:"enable" yaml:"enable"`
+	ImageDimensions bool `toml:"imageDimensions" yaml:"imageDimensions"` // Also report images without width and height
+}
+
 // PerformanceConfig configures performance optimizations
 type PerformanceConfig struct {
 	EnableCompression bool     `toml:"enableCompression" yaml:"enableCompression"`
@@ -191,6 +390,31 @@ type PerformanceConfig struct {
 	CacheDir          string   `toml:"cacheDir" yaml:"cacheDir"`
 	ImageOptimization ImageOptConfig `toml:"imageOptimization" yaml:"imageOptimization"`
 	AssetBundling     AssetBundlingConfig `toml:"assetBundling" yaml:"assetBundling"`
+	Compression       CompressionConfig `toml:"compression" yaml:"compression"`
+	// PreloadHeaders also sends each page's preload hints as Link headers,
+	// in a _headers file for the netlify and cloudflare redirect targets
+	PreloadHeaders    bool     `toml:"preloadHeaders" yaml:"preloadHeaders"`
+}
+
+// BudgetConfig sets the performance budget builds check the output
+// against, in kilobytes; 0 turns a limit off
+type BudgetConfig struct {
+	HTML       int  `toml:"html" yaml:"html"`   // Each generated HTML file
+	Image      int  `toml:"image" yaml:"image"` // Each published image
+	CSS        int  `toml:"css" yaml:"css"`
+	JS         int  `toml:"js" yaml:"js"`
+	// PageWeight limits a page's HTML with the local stylesheets, scripts
+	// and images it references
+	PageWeight int  `toml:"pageWeight" yaml:"pageWeight"`
+	// Strict fails the build when a limit is exceeded, instead of warning
+	Strict     bool `toml:"strict" yaml:"strict"`
+}
+
+// CompressionConfig configures the precompressed variants written next to
+// compressible output files when EnableCompression is set
+type CompressionConfig struct {
+	Algorithms        []string `toml:"algorithms" yaml:"algorithms"` // "gzip" and "br"
+	MinSize           int      `toml:"minSize" yaml:"minSize"`       // Smaller files are not compressed
 }
 
 type ImageOptConfig struct {
@@ -223,10 +447,15 @@ type CSPConfig struct {
 	ImgSrc            string `toml:"imgSrc" yaml:"imgSrc"`
 }
 
+// HTTPSConfig configures HTTPS for the development server. Without a
+// certificate and key a self-signed localhost certificate is generated.
 type HTTPSConfig struct {
-	Enable            bool `toml:"enable" yaml:"enable"`
-	RedirectHTTP      bool `toml:"redirectHTTP" yaml:"redirectHTTP"`
-	HSTS              bool `toml:"hsts" yaml:"hsts"`
+	Enable            bool   `toml:"enable" yaml:"enable"`
+	RedirectHTTP      bool   `toml:"redirectHTTP" yaml:"redirectHTTP"`
+	HTTPPort          int    `toml:"httpPort" yaml:"httpPort"`
+	HSTS              bool   `toml:"hsts" yaml:"hsts"`
+	CertFile          string `toml:"certFile" yaml:"certFile"`
+	KeyFile           string `toml:"keyFile" yaml:"keyFile"`
 }
 
 // PluginConfig configures individual plugins
@@ -254,10 +483,204 @@ type EnvConfig struct {
 	Params            map[string]interface{} `toml:"params" yaml:"params"`
 }
 
+// FaviconConfig generates the site's icons and web app manifest from a
+// single image
+type FaviconConfig struct {
+	// Source is a PNG or SVG image, relative to the site directory; empty
+	// turns favicon generation off
+	Source          string `toml:"source" yaml:"source"`
+	// Name and ShortName name the site in site.webmanifest; both default
+	// to the site title
+	Name            string `toml:"name" yaml:"name"`
+	ShortName       string `toml:"shortName" yaml:"shortName"`
+	// ThemeColor is also emitted as <meta name="theme-color">
+	ThemeColor      string `toml:"themeColor" yaml:"themeColor"`
+	// BackgroundColor is the manifest's splash background and is drawn
+	// under apple-touch-icon.png, white by default
+	BackgroundColor string `toml:"backgroundColor" yaml:"backgroundColor"`
+}
+
+// ReadingTimeConfig controls how readingTimeText describes reading time.
+// Its wording comes from the readingTime, readingTimeRange and
+// readingTimeUnderMinute translations.
+type ReadingTimeConfig struct {
+	// UnderMinute is the reading time in seconds below which a page reads
+	// "less than a minute"; 0 turns it off
+	UnderMinute int    `toml:"underMinute" yaml:"underMinute"`
+	// Rounding is "up" (the default) to round to the next minute,
+	// "nearest", or "range" for "3–4 min read"
+	Rounding    string `toml:"rounding" yaml:"rounding"`
+}
+
+// PaginationConfig sets how many pages list pages show per pager. A
+// template's paginate call can override the size for its pages.
+type PaginationConfig struct {
+	// PagerSize is the size for every kind without its own; a negative
+	// size puts all pages on one pager
+	PagerSize int    `toml:"pagerSize" yaml:"pagerSize"`
+	// Path is the URL segment before the pager number, e.g. /posts/page/2/
+	Path      string `toml:"path" yaml:"path"`
+	// Sizes by page kind; 0 uses PagerSize and a negative size is unlimited
+	Home      int `toml:"home" yaml:"home"`
+	Section   int `toml:"section" yaml:"section"`
+	Taxonomy  int `toml:"taxonomy" yaml:"taxonomy"`
+	Term      int `toml:"term" yaml:"term"`
+}
+
+// PagerSize returns the configured pager size for pages of a kind, or 0 for
+// no limit
+func (c *Config) PagerSize(kind string) int {
+	size := 0
+	switch kind {
+	case "home":
+		size = c.Pagination.Home
+	case "section":
+		size = c.Pagination.Section
+	case "taxonomy":
+		size = c.Pagination.Taxonomy
+	case "term":
+		size = c.Pagination.Term
+	}
+	if size == 0 {
+		size = c.Pagination.PagerSize
+	}
+	if size < 0 {
+		return 0
+	}
+	return size
+}
+
+// PagerURL returns the URL of pager n of the list page at pageURL; the
+// first pager is the page itself
+func (c *Config) PagerURL(pageURL string, n int) string {
+	if n <= 1 {
+		return pageURL
+	}
+	return strings.TrimSuffix(pageURL, "/") + "/" + c.pagerPath() + "/" + strconv.Itoa(n) + "/"
+}
+
+// PagerSlug returns the slug of pager n of the list page at slug
+func (c *Config) PagerSlug(slug string, n int) string {
+	if n <= 1 {
+		return slug
+	}
+	return path.Join(slug, c.pagerPath(), strconv.Itoa(n))
+}
+
+func (c *Config) pagerPath() string {
+	if c.Pagination.Path == "" {
+		return "page"
+	}
+	return c.Pagination.Path
+}
+
+// ThemesConfig controls how much themes are trusted
+type ThemesConfig struct {
+	// Sandbox restricts the functions the active theme's templates may
+	// call, for third-party themes. The site's own layouts keep them all.
+	Sandbox bool `toml:"sandbox" yaml:"sandbox"`
+}
+
+// Redirect is a single entry of the [[redirects]] table. A trailing "/*" in
+// From matches any suffix, which is available in To as ":splat".
+type Redirect struct {
+	From              string `toml:"from" yaml:"from" json:"from"`
+	To                string `toml:"to" yaml:"to" json:"to"`
+	Status            int    `toml:"status" yaml:"status" json:"status"`
+	Force             bool   `toml:"force" yaml:"force" json:"force"`
+}
+
+// RedirectOptions controls which redirect artifacts are generated. It lives
+// beside [[redirects]] because a TOML array of tables can't carry options.
+type RedirectOptions struct {
+	Targets           []string `toml:"targets" yaml:"targets"`
+	HTMLFallback      bool     `toml:"htmlFallback" yaml:"htmlFallback"`
+}
+
+// TaxonomyOptions controls where taxonomy and term pages are published
+// besides their own list pages
+type TaxonomyOptions struct {
+	Sitemap           bool `toml:"sitemap" yaml:"sitemap"`
+	RSS               bool `toml:"rss" yaml:"rss"`
+}
+
+// SectionConfig holds the defaults applied to every page in a section.
+// A page's own front matter takes precedence over them.
+type SectionConfig struct {
+	DefaultLayout     string                 `toml:"defaultLayout" yaml:"defaultLayout"`
+	DefaultType       string                 `toml:"defaultType" yaml:"defaultType"`
+	SortBy            string                 `toml:"sortBy" yaml:"sortBy"`   // "date", "weight" or "title"
+	Outputs           []string               `toml:"outputs" yaml:"outputs"` // "html" and "rss"; html only by default
+	Params            map[string]interface{} `toml:"params" yaml:"params"`
+}
+
+// HasOutput reports whether the section's pages are published in format
+func (s SectionConfig) HasOutput(format string) bool {
+	if len(s.Outputs) == 0 {
+		return format == "html"
+	}
+	for _, output := range s.Outputs {
+		if strings.EqualFold(output, format) {
+			return true
+		}
+	}
+	return false
+}
+
+// MenuEntry is a link in a menu, with any nested entries below it
+type MenuEntry struct {
+	Name     string      `toml:"name" yaml:"name"`
+	URL      string      `toml:"url" yaml:"url"` // Site path or absolute URL; "" for a heading over its children
+	Weight   int         `toml:"weight" yaml:"weight"`
+	Children []MenuEntry `toml:"children" yaml:"children"`
+}
+
+// HasChildren reports whether the entry has nested entries
+func (m MenuEntry) HasChildren() bool {
+	return len(m.Children) > 0
+}
+
+// DefaultTaxonomies are used when no [taxonomies] table is configured
+var DefaultTaxonomies = map[string]string{
+	"tag":      "tags",
+	"category": "categories",
+}
+
+// MetricsConfig publishes a JSON summary of each full build, and an SVG
+// badge made from it, as part of the site
+type MetricsConfig struct {
+	Enable            bool        `toml:"enable" yaml:"enable"`
+	Filename          string      `toml:"filename" yaml:"filename"`
+	Badge             BadgeConfig `toml:"badge" yaml:"badge"`
+	// NextPublishAt adds when the next scheduled page goes live, so a cron
+	// job can rebuild the site then
+	NextPublishAt     bool        `toml:"nextPublishAt" yaml:"nextPublishAt"`
+}
+
+// BadgeConfig configures the build badge. Value is a template executed with
+// the build metrics, e.g. "{{ .Pages }} pages".
+type BadgeConfig struct {
+	Enable            bool   `toml:"enable" yaml:"enable"`
+	Filename          string `toml:"filename" yaml:"filename"`
+	Label             string `toml:"label" yaml:"label"`
+	Value             string `toml:"value" yaml:"value"`
+	Color             string `toml:"color" yaml:"color"`
+	LabelColor        string `toml:"labelColor" yaml:"labelColor"`
+}
+
+// PreviewConfig configures the banner injected into preview builds
+type PreviewConfig struct {
+	Message           string `toml:"message" yaml:"message"`
+	Partial           string `toml:"partial" yaml:"partial"`
+}
+
 // ConfigLoader handles loading and validating configuration
 type ConfigLoader struct {
 	searchPaths []string
 	envOverrides map[string]string
+	strict      bool     // Unknown keys are errors rather than warnings
+	unknownKeys []string // Unknown keys found while loading, with their file
+	deferChecks bool     // Leave the checks Check covers to the caller
 }
 
 // NewConfigLoader creates a new configuration loader
@@ -288,42 +711,112 @@ func (cl *ConfigLoader) SetEnvOverride(key, value string) {
 	cl.envOverrides[key] = value
 }
 
+// ConfigFiles returns the files Load merges for configPaths, in order. With
+// no paths, it is the first configuration file found in the search paths, or
+// none when the defaults are used. With local, the base file's local
+// overrides, e.g. config.local.toml next to config.toml, are appended when
+// they exist and aren't listed already.
+func ConfigFiles(configPaths []string, local bool) []string {
+	files := make([]string, 0, len(configPaths)+1)
+	for _, path := range configPaths {
+		if path != "" {
+			files = append(files, path)
+		}
+	}
+	if len(files) == 0 {
+		path, err := NewConfigLoader().findConfigFile()
+		if err != nil {
+			return nil
+		}
+		files = append(files, path)
+	}
+	if local {
+		if path := localConfigFile(files[0]); path != "" && !containsFile(files, path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// localConfigFile returns the existing local overrides file of base, named
+// <name>.local.toml, .yaml or .yml, or ""
+func localConfigFile(base string) string {
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	for _, ext := range []string{".toml", ".yaml", ".yml"} {
+		path := stem + ".local" + ext
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// containsFile reports whether files lists path
+func containsFile(files []string, path string) bool {
+	for _, file := range files {
+		if filepath.Clean(file) == filepath.Clean(path) {
+			return true
+		}
+	}
+	return false
+}
 
 // Load reads and parses the configuration with enhanced features
-func Load(configPath string) (*Config, error) {
+func Load(configPaths ...string) (*Config, error) {
 	loader := NewConfigLoader()
-	return loader.LoadConfig(configPath)
+	return loader.LoadConfig(configPaths...)
 }
 
-// LoadConfig loads configuration with full feature support
-func (cl *ConfigLoader) LoadConfig(configPath string) (*Config, error) {
+// LoadConfig loads configuration with full feature support. Later files are
+// deep-merged over earlier ones. Without paths, it loads ConfigFiles(nil, true).
+func (cl *ConfigLoader) LoadConfig(configPaths ...string) (*Config, error) {
 	// Set defaults
 	cfg := cl.getDefaultConfig()
+	cl.unknownKeys = nil
 
-	// Determine config file to use
-	var configFile string
-	if configPath != "" {
-		configFile = configPath
-	} else {
-		var err error
-		configFile, err = cl.findConfigFile()
-		if err != nil {
+	// Determine config files to use
+	files := configPaths
+	if len(files) == 0 {
+		files = ConfigFiles(nil, true)
+		if len(files) == 0 {
 			return cfg, nil // Return defaults if no config file found
 		}
 	}
 
-	// Load main config file
-	if err := cl.loadConfigFile(configFile, cfg); err != nil {
-		return nil, fmt.Errorf("failed to load config file %s: %w", configFile, err)
+	// Load and merge the config files
+	layers := make([]*configLayer, 0, len(files)+1)
+	for _, file := range files {
+		layer, err := cl.readLayer(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config file %s: %w", file, err)
+		}
+		layers = append(layers, layer)
+	}
+	if err := decodeLayers(cfg, layers); err != nil {
+		return nil, fmt.Errorf("failed to load config files %s: %w", strings.Join(files, ", "), err)
 	}
 
 	// Load environment-specific config
-	if err := cl.loadEnvironmentConfig(cfg); err != nil {
+	if envLayer, err := cl.loadEnvironmentConfig(cfg); err != nil {
 		return nil, fmt.Errorf("failed to load environment config: %w", err)
+	} else if envLayer != nil {
+		layers = append(layers, envLayer)
+		cfg = cl.getDefaultConfig()
+		if err := decodeLayers(cfg, layers); err != nil {
+			return nil, fmt.Errorf("failed to load environment config %s: %w", envLayer.path, err)
+		}
+	}
+	for _, layer := range layers {
+		cfg.ConfigFiles = append(cfg.ConfigFiles, layer.path)
+	}
+
+	if err := cl.reportUnknownKeys(); err != nil {
+		return nil, err
 	}
 
 	// Apply environment variable overrides
 	cl.applyEnvironmentOverrides(cfg)
+	normalizeParams(cfg)
 
 	// Validate configuration
 	if err := cl.validateConfig(cfg); err != nil {
@@ -350,10 +843,16 @@ func (cl *ConfigLoader) getDefaultConfig() *Config {
 		ThemesDir:              "themes",
 		DataDir:                "data",
 		AssetsDir:              "assets",
+		I18nDir:                "i18n",
+		RootFiles:              []string{"favicon.ico", "robots.txt", "CNAME"},
+		RefLinksErrorLevel:     "error",
+		RefLinksNotFoundURL:    "#",
 		BuildDrafts:            false,
 		BuildFuture:            false,
 		BuildExpired:           false,
 		CleanBuild:             true,
+		ProtectedFiles:         []string{"CNAME", ".git", ".nojekyll", ".well-known"},
+		ModifiedOutputLevel:    "warning",
 		Watch:                  false,
 		Workers:                0, // Auto-detect
 		Port:                   1313,
@@ -361,18 +860,49 @@ func (cl *ConfigLoader) getDefaultConfig() *Config {
 		LiveReload:             true,
 		DevMode:                false,
 		DefaultContentType:     "page",
+		ContentExtensions:      []string{".md", ".markdown", ".mdown"},
 		DefaultLayout:          "single",
 		SummaryLength:          70,
+		Ellipsis:               util.DefaultEllipsis,
+		Pagination:             PaginationConfig{PagerSize: 10, Path: "page"},
 		PrettyURLs:             true,
 		CanonicalifyURLs:       false,
 		RelativeURLs:           false,
 		UglyURLs:               false,
+		Routing: RoutingConfig{
+			TrailingSlash: "redirect",
+		},
 		DefaultContentLanguage: "en",
 		Environment:            "development",
 		Params:                 make(map[string]interface{}),
 		Languages:              make(map[string]Language),
 		Environments:           make(map[string]EnvConfig),
 		
+		// Redirect defaults
+		RedirectOptions: RedirectOptions{
+			Targets: []string{"netlify", "vercel"},
+		},
+		
+		// Metrics defaults
+		Metrics: MetricsConfig{
+			Enable:   false,
+			Filename: "metrics.json",
+			Badge: BadgeConfig{
+				Enable:     true,
+				Filename:   "badge.svg",
+				Label:      "built with VanGo",
+				Value:      "{{ .Pages }} pages",
+				Color:      "#007ec6",
+				LabelColor: "#555",
+			},
+		},
+		
+		// Preview defaults
+		Preview: PreviewConfig{
+			Message: "Preview build",
+			Partial: "partials/preview-banner",
+		},
+		
 		// Markup defaults
 		Markup: MarkupConfig{
 			Goldmark: GoldmarkConfig{
@@ -421,6 +951,13 @@ func (cl *ConfigLoader) getDefaultConfig() *Config {
 			JSONFeedFilename: "feed.json",
 			MetaGenerator:    true,
 		},
+		Feeds: FeedsConfig{
+			Remove: []string{"script", "style", "iframe", "form", "object", "embed"},
+		},
+		Analytics: AnalyticsConfig{
+			ProductionOnly: true,
+			Inject:         true,
+		},
 		
 		// Social defaults
 		Social: SocialConfig{
@@ -451,6 +988,15 @@ func (cl *ConfigLoader) getDefaultConfig() *Config {
 				JS:             true,
 				Fingerprinting: true,
 			},
+			Compression: CompressionConfig{
+				Algorithms: []string{"gzip", "br"},
+				MinSize:    1024,
+			},
+		},
+		Budget: BudgetConfig{
+			HTML:       200,
+			Image:      500,
+			PageWeight: 1024,
 		},
 		
 		// Security defaults
@@ -461,6 +1007,7 @@ func (cl *ConfigLoader) getDefaultConfig() *Config {
 			HTTPS: HTTPSConfig{
 				Enable:       false,
 				RedirectHTTP: false,
+				HTTPPort:     8080,
 				HSTS:         false,
 			},
 			Headers: make(map[string]string),
@@ -486,52 +1033,95 @@ func (cl *ConfigLoader) findConfigFile() (string, error) {
 	return "", fmt.Errorf("no configuration file found")
 }
 
-// loadConfigFile loads a specific configuration file
-func (cl *ConfigLoader) loadConfigFile(path string, cfg *Config) error {
+// normalizeParams gives params decoded from YAML string keys throughout, like
+// params decoded from TOML, so they encode as JSON and index by string key
+func normalizeParams(cfg *Config) {
+	util.NormalizeMap(cfg.Params)
+	for code, lang := range cfg.Languages {
+		util.NormalizeMap(lang.Params)
+		cfg.Languages[code] = lang
+	}
+	for name, env := range cfg.Environments {
+		util.NormalizeMap(env.Params)
+		cfg.Environments[name] = env
+	}
+	for i := range cfg.Plugins {
+		util.NormalizeMap(cfg.Plugins[i].Config)
+	}
+}
+
+// configLayer is one configuration file, as read and as a generic map
+type configLayer struct {
+	path   string
+	format string
+	data   []byte
+	raw    map[string]interface{}
+}
+
+// readLayer reads a configuration file and records its unknown keys
+func (cl *ConfigLoader) readLayer(path string) (*configLayer, error) {
 	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	layer := &configLayer{path: path, format: configFormat(path, data), data: data}
+	if layer.raw, err = rawConfig(data, layer.format); err != nil {
+		return nil, err
+	}
+	cl.checkKeys(path, layer.raw, layer.format)
+	return layer, nil
+}
+
+// decodeLayers decodes the layers into cfg. A single file is decoded as it
+// is; several are deep-merged in order first, so a later file only replaces
+// the values it sets. Lists are replaced as a whole.
+func decodeLayers(cfg *Config, layers []*configLayer) error {
+	if len(layers) == 1 {
+		if layers[0].format == "toml" {
+			return toml.Unmarshal(layers[0].data, cfg)
+		}
+		return yaml.Unmarshal(layers[0].data, cfg)
+	}
+
+	merged := make(map[string]interface{})
+	for _, layer := range layers {
+		util.MergeMaps(merged, copyMap(layer.raw))
+	}
+	tree, err := toml.TreeFromMap(merged)
 	if err != nil {
 		return err
 	}
+	return tree.Unmarshal(cfg)
+}
 
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
-	case ".toml":
-		return toml.Unmarshal(data, cfg)
-	case ".yaml", ".yml":
-		return yaml.Unmarshal(data, cfg)
-	default:
-		// Try to detect format
-		if strings.Contains(string(data), "=") {
-			return toml.Unmarshal(data, cfg)
-		} else {
-			return yaml.Unmarshal(data, cfg)
+// copyMap copies the nested maps of m, so merging into the copy leaves m as
+// it was
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = copyMap(nested)
 		}
+		c[key] = value
 	}
+	return c
 }
 
-// loadEnvironmentConfig loads environment-specific configuration
-func (cl *ConfigLoader) loadEnvironmentConfig(cfg *Config) error {
+// loadEnvironmentConfig reads config/<environment>.toml or .yaml, if it
+// exists, to be merged over the main configuration
+func (cl *ConfigLoader) loadEnvironmentConfig(cfg *Config) (*configLayer, error) {
 	if cfg.Environment == "" {
-		return nil
+		return nil, nil
 	}
 
 	envConfigPath := fmt.Sprintf("config/%s.toml", cfg.Environment)
 	if _, err := os.Stat(envConfigPath); os.IsNotExist(err) {
 		envConfigPath = fmt.Sprintf("config/%s.yaml", cfg.Environment)
 		if _, err := os.Stat(envConfigPath); os.IsNotExist(err) {
-			return nil // No environment-specific config
+			return nil, nil // No environment-specific config
 		}
 	}
-
-	envCfg := &Config{}
-	if err := cl.loadConfigFile(envConfigPath, envCfg); err != nil {
-		return err
-	}
-
-	// Merge environment config into main config
-	cl.mergeConfigs(cfg, envCfg)
-
-	return nil
+	return cl.readLayer(envConfigPath)
 }
 
 // applyEnvironmentOverrides applies environment variable overrides
@@ -542,7 +1132,7 @@ func (cl *ConfigLoader) applyEnvironmentOverrides(cfg *Config) {
 		"VANGO_TITLE":    func(v string) { cfg.Title = v },
 		"VANGO_THEME":    func(v string) { cfg.Theme = v },
 		"VANGO_PORT":     func(v string) { 
-			if port := parseInt(v); port > 0 { 
+			if port := util.ParseInt(v, 0); port > 0 { 
 				cfg.Port = port 
 			} 
 		},
@@ -573,15 +1163,94 @@ func (cl *ConfigLoader) validateConfig(cfg *Config) error {
 	}
 
 	// Validate URLs
-	if !cl.isValidURL(cfg.BaseURL) {
-		return fmt.Errorf("invalid baseURL: %s", cfg.BaseURL)
+	if _, err := NormalizeBaseURL(cfg.BaseURL); err != nil {
+		return err
+	}
+	if cfg.CanonicalBaseURL != "" {
+		if _, err := NormalizeBaseURL(cfg.CanonicalBaseURL); err != nil {
+			return fmt.Errorf("canonicalBaseURL: %w", err)
+		}
 	}
 
-	// Ensure required directories exist
-	requiredDirs := []string{cfg.ContentDir, cfg.LayoutDir}
-	for _, dir := range requiredDirs {
-		if _, err := os.Stat(dir); os.IsNotExist(err) {
-			return fmt.Errorf("required directory does not exist: %s", dir)
+	if !cl.deferChecks {
+		// Ensure required directories exist
+		requiredDirs := []string{cfg.ContentDir, cfg.LayoutDir}
+		for _, dir := range requiredDirs {
+			if _, err := os.Stat(dir); os.IsNotExist(err) {
+				return fmt.Errorf("required directory does not exist: %s", dir)
+			}
+		}
+
+		if err := cl.validateOutputDir(cfg); err != nil {
+			return err
+		}
+	}
+
+	if cfg.TimeZone != "" {
+		if _, err := time.LoadLocation(cfg.TimeZone); err != nil {
+			return fmt.Errorf("invalid timeZone %q: %w", cfg.TimeZone, err)
+		}
+	}
+
+	switch cfg.TitleCaseStyle {
+	case "", "title", "chicago", "none":
+	default:
+		return fmt.Errorf("invalid titleCaseStyle %q: must be title, chicago or none", cfg.TitleCaseStyle)
+	}
+
+	for _, ext := range cfg.ContentExtensions {
+		if strings.Trim(ext, ".") == "" {
+			return fmt.Errorf("invalid contentExtensions entry %q", ext)
+		}
+	}
+
+	switch cfg.ReadingTime.Rounding {
+	case "", "up", "nearest", "range":
+	default:
+		return fmt.Errorf("invalid readingTime.rounding %q: must be up, nearest or range", cfg.ReadingTime.Rounding)
+	}
+
+	if p := cfg.Pagination.Path; p == "" || strings.ContainsAny(p, "/\\") || p != util.Slugify(p) {
+		return fmt.Errorf("invalid pagination.path %q: must be a single URL segment, such as \"page\"", p)
+	}
+
+	if cfg.CaseCollisionLevel != "" && cfg.CaseCollisionLevel != "error" && cfg.CaseCollisionLevel != "warning" {
+		return fmt.Errorf("invalid caseCollisionLevel %q: must be \"error\" or \"warning\"", cfg.CaseCollisionLevel)
+	}
+
+	switch cfg.ModifiedOutputLevel {
+	case "", "warning", "error", "off":
+	default:
+		return fmt.Errorf("invalid modifiedOutputLevel %q: must be \"warning\", \"error\" or \"off\"", cfg.ModifiedOutputLevel)
+	}
+
+	if cfg.RefLinksErrorLevel != "" && cfg.RefLinksErrorLevel != "error" && cfg.RefLinksErrorLevel != "warning" {
+		return fmt.Errorf("invalid refLinksErrorLevel %q: must be \"error\" or \"warning\"", cfg.RefLinksErrorLevel)
+	}
+
+	if err := validateAnalytics(cfg.Analytics); err != nil {
+		return err
+	}
+	if err := validateFavicon(cfg.Favicon); err != nil {
+		return err
+	}
+	for _, limit := range []struct {
+		name  string
+		value int
+	}{
+		{"html", cfg.Budget.HTML},
+		{"image", cfg.Budget.Image},
+		{"css", cfg.Budget.CSS},
+		{"js", cfg.Budget.JS},
+		{"pageWeight", cfg.Budget.PageWeight},
+	} {
+		if limit.value < 0 {
+			return fmt.Errorf("invalid budget.%s %d: must be a size in KB, or 0 for no limit", limit.name, limit.value)
+		}
+	}
+	for _, name := range cfg.RootFiles {
+		if name == "" || path.IsAbs(name) || strings.HasPrefix(path.Clean(name), "..") {
+			return fmt.Errorf("invalid rootFiles entry %q: must be a path inside staticDir", name)
 		}
 	}
 
@@ -590,11 +1259,194 @@ func (cl *ConfigLoader) validateConfig(cfg *Config) error {
 		return fmt.Errorf("invalid port: %d", cfg.Port)
 	}
 
+	// Validate HTTPS configuration
+	https := cfg.Security.HTTPS
+	if (https.CertFile == "") != (https.KeyFile == "") {
+		return fmt.Errorf("invalid https config: certFile and keyFile must be set together")
+	}
+	if https.RedirectHTTP && (https.HTTPPort < 1 || https.HTTPPort > 65535 || https.HTTPPort == cfg.Port) {
+		return fmt.Errorf("invalid https config: httpPort %d must be a valid port other than %d", https.HTTPPort, cfg.Port)
+	}
+
 	// Validate markup configuration
 	if err := cl.validateMarkupConfig(&cfg.Markup); err != nil {
 		return fmt.Errorf("invalid markup config: %w", err)
 	}
 
+	if err := validateProxies(cfg.Server.Proxies); err != nil {
+		return err
+	}
+
+	// Validate routing
+	switch cfg.Routing.TrailingSlash {
+	case "redirect", "serve", "strict":
+	default:
+		return fmt.Errorf("invalid routing.trailingSlash %q: must be redirect, serve or strict", cfg.Routing.TrailingSlash)
+	}
+
+	// Validate compression
+	for _, algorithm := range cfg.Performance.Compression.Algorithms {
+		if algorithm != "gzip" && algorithm != "br" {
+			return fmt.Errorf("invalid compression algorithm %q: must be \"gzip\" or \"br\"", algorithm)
+		}
+	}
+
+	// Validate sections
+	if err := cl.validateSections(cfg.Sections); err != nil {
+		return fmt.Errorf("invalid sections: %w", err)
+	}
+
+	// Validate menus
+	if err := cl.validateMenus(cfg.Menus); err != nil {
+		return fmt.Errorf("invalid menus: %w", err)
+	}
+
+	// Validate redirects
+	if err := cl.validateRedirects(cfg.Redirects); err != nil {
+		return fmt.Errorf("invalid redirects: %w", err)
+	}
+
+	// Validate metrics
+	if err := cl.validateMetrics(&cfg.Metrics); err != nil {
+		return fmt.Errorf("invalid metrics config: %w", err)
+	}
+
+	return nil
+}
+
+// validateOutputDir rejects a publicDir inside one of the source
+// directories: every build would change the sources, the watcher would
+// rebuild on the build's own writes, and published pages would be read
+// back as content or static files
+func (cl *ConfigLoader) validateOutputDir(cfg *Config) error {
+	sources := []struct{ key, dir string }{
+		{"contentDir", cfg.ContentDir},
+		{"layoutDir", cfg.LayoutDir},
+		{"staticDir", cfg.StaticDir},
+		{"assetsDir", cfg.AssetsDir},
+	}
+	for _, source := range sources {
+		if source.dir != "" && util.IsWithin(cfg.PublicDir, source.dir) {
+			return fmt.Errorf("publicDir %q is inside %s %q: builds would write into their own sources and rebuild endlessly while watching, so move the output directory out of it", cfg.PublicDir, source.key, source.dir)
+		}
+	}
+	return nil
+}
+
+// validateMetrics requires the metrics files to stay inside the output
+// directory and to not overwrite each other
+func (cl *ConfigLoader) validateMetrics(metrics *MetricsConfig) error {
+	if !metrics.Enable {
+		return nil
+	}
+	files := map[string]string{"filename": metrics.Filename}
+	if metrics.Badge.Enable {
+		files["badge.filename"] = metrics.Badge.Filename
+	}
+	for key, name := range files {
+		clean := path.Clean("/" + util.SlashPath(name))
+		if strings.TrimSpace(name) == "" || clean == "/" || strings.Contains(util.SlashPath(name), "..") {
+			return fmt.Errorf("%s %q must be a file path inside the output directory", key, name)
+		}
+	}
+	if metrics.Badge.Enable && path.Clean("/"+util.SlashPath(metrics.Filename)) == path.Clean("/"+util.SlashPath(metrics.Badge.Filename)) {
+		return fmt.Errorf("filename and badge.filename are both %q", metrics.Filename)
+	}
+	return nil
+}
+
+// validateSections rejects unknown sort orders and output formats
+func (cl *ConfigLoader) validateSections(sections map[string]SectionConfig) error {
+	for name, section := range sections {
+		switch section.SortBy {
+		case "", "date", "weight", "title":
+		default:
+			return fmt.Errorf("section %s: unsupported sortBy %q (use date, weight or title)", name, section.SortBy)
+		}
+		for _, output := range section.Outputs {
+			switch strings.ToLower(output) {
+			case "html", "rss":
+			default:
+				return fmt.Errorf("section %s: unsupported output %q (use html or rss)", name, output)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMenus rejects entries without a name or without somewhere to go,
+// and orders every level of each menu by weight, unweighted entries last,
+// keeping the configured order otherwise
+func (cl *ConfigLoader) validateMenus(menus map[string][]MenuEntry) error {
+	for name, entries := range menus {
+		if err := validateMenuEntries(entries, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateMenuEntries checks and orders one level of the menu at path
+func validateMenuEntries(entries []MenuEntry, path string) error {
+	for i, entry := range entries {
+		if entry.Name == "" {
+			return fmt.Errorf("%s: entry %d has no name", path, i+1)
+		}
+		if entry.URL == "" && len(entry.Children) == 0 {
+			return fmt.Errorf("%s: entry %q needs a url or children", path, entry.Name)
+		}
+		if err := validateMenuEntries(entry.Children, path+" > "+entry.Name); err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		wi, wj := entries[i].Weight, entries[j].Weight
+		if (wi == 0) != (wj == 0) {
+			return wj == 0
+		}
+		return wi < wj
+	})
+	return nil
+}
+
+// validateRedirects rejects incomplete rules, duplicate froms and loops
+func (cl *ConfigLoader) validateRedirects(redirects []Redirect) error {
+	targets := make(map[string]string)
+	for i, r := range redirects {
+		if r.From == "" || r.To == "" {
+			return fmt.Errorf("redirect %d: from and to are required", i+1)
+		}
+		if !strings.HasPrefix(r.From, "/") {
+			return fmt.Errorf("redirect %d: from must start with /: %s", i+1, r.From)
+		}
+		switch r.Status {
+		case 0, 200, 301, 302, 303, 307, 308, 404, 410:
+		default:
+			return fmt.Errorf("redirect %s: unsupported status %d", r.From, r.Status)
+		}
+		if _, exists := targets[r.From]; exists {
+			return fmt.Errorf("duplicate redirect from %s", r.From)
+		}
+		targets[r.From] = r.To
+	}
+
+	// Follow each chain of exact redirects looking for a cycle
+	for from := range targets {
+		seen := map[string]bool{from: true}
+		current := from
+		for {
+			next, ok := targets[current]
+			if !ok {
+				break
+			}
+			if seen[next] {
+				return fmt.Errorf("redirect loop detected starting at %s", from)
+			}
+			seen[next] = true
+			current = next
+		}
+	}
+
 	return nil
 }
 
@@ -622,11 +1474,23 @@ func (cl *ConfigLoader) validateMarkupConfig(markup *MarkupConfig) error {
 // postProcessConfig performs post-processing on the configuration
 func (cl *ConfigLoader) postProcessConfig(cfg *Config) {
 	// Normalize URLs
-	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/") + "/"
+	if baseURL, err := NormalizeBaseURL(cfg.BaseURL); err == nil {
+		cfg.BaseURL = baseURL
+	}
+	if canonical, err := NormalizeBaseURL(cfg.CanonicalBaseURL); err == nil {
+		cfg.CanonicalBaseURL = canonical
+	}
+
+	// Redirects default to a permanent redirect
+	for i := range cfg.Redirects {
+		if cfg.Redirects[i].Status == 0 {
+			cfg.Redirects[i].Status = 301
+		}
+	}
 
 	// Set worker count if not specified
 	if cfg.Workers <= 0 {
-		cfg.Workers = max(1, min(8, getNumCPU()))
+		cfg.Workers = DefaultWorkers()
 	}
 
 	// Ensure cache directory exists
@@ -647,28 +1511,11 @@ func (cl *ConfigLoader) postProcessConfig(cfg *Config) {
 	}
 }
 
-// Helper methods
-func (cl *ConfigLoader) mergeConfigs(base, override *Config) {
-	// This would implement deep merging of configurations
-	// For brevity, showing key fields only
-	if override.BaseURL != "" {
-		base.BaseURL = override.BaseURL
-	}
-	if override.Title != "" {
-		base.Title = override.Title
-	}
-	// ... continue for all fields
-}
-
 func (cl *ConfigLoader) setConfigValue(cfg *Config, key, value string) {
 	// Implement setting nested configuration values using dot notation
 	// Example: "performance.enableMinification" = "true"
 }
 
-func (cl *ConfigLoader) isValidURL(urlStr string) bool {
-	urlPattern := regexp.MustCompile(`^https?://[^\s/$.?#].[^\s]*$`)
-	return urlPattern.MatchString(urlStr) || strings.HasPrefix(urlStr, "http://localhost")
-}
 
 
 
@@ -693,6 +1540,29 @@ func (c *Config) validate() error {
 	return nil
 }
 
+// Match reports whether path matches the redirect and returns the target
+// with any ":splat" placeholder filled in
+func (r Redirect) Match(path string) (string, bool) {
+	if strings.HasSuffix(r.From, "/*") {
+		prefix := strings.TrimSuffix(r.From, "*")
+		base := strings.TrimSuffix(prefix, "/")
+		if path != base && !strings.HasPrefix(path, prefix) {
+			return "", false
+		}
+		splat := strings.TrimPrefix(strings.TrimPrefix(path, base), "/")
+		return strings.ReplaceAll(r.To, ":splat", splat), true
+	}
+	if path == r.From || strings.TrimSuffix(path, "/") == strings.TrimSuffix(r.From, "/") {
+		return r.To, true
+	}
+	return "", false
+}
+
+// IsRedirect reports whether the rule changes the URL rather than rewriting it
+func (r Redirect) IsRedirect() bool {
+	return r.Status >= 300 && r.Status < 400
+}
+
 // Enhanced Config methods
 func (c *Config) GetParam(key string) interface{} {
 	return c.Params[key]
@@ -702,6 +1572,141 @@ func (c *Config) SetParam(key string, value interface{}) {
 	c.Params[key] = value
 }
 
+// OverrideBaseURL replaces the configured BaseURL, e.g. for preview deploys
+func (c *Config) OverrideBaseURL(baseURL string) error {
+	normalized, err := NormalizeBaseURL(baseURL)
+	if err != nil {
+		return err
+	}
+	c.BaseURL = normalized
+	return nil
+}
+
+// GetCanonicalBaseURL returns the base URL canonical links should point at.
+// Preview builds keep canonical links on the production host when
+// canonicalBaseURL is configured.
+func (c *Config) GetCanonicalBaseURL() string {
+	if c.CanonicalBaseURL != "" {
+		return c.CanonicalBaseURL
+	}
+	return c.BaseURL
+}
+
+// Now returns the time the build runs at: the pinned BuildTime of a
+// reproducible build, otherwise the wall clock
+func (c *Config) Now() time.Time {
+	if !c.BuildTime.IsZero() {
+		return c.BuildTime
+	}
+	return time.Now()
+}
+
+// SourceDateEpoch returns the time set by the SOURCE_DATE_EPOCH environment
+// variable, the standard way to pin the time of reproducible builds
+func SourceDateEpoch() (time.Time, bool, error) {
+	value := os.Getenv("SOURCE_DATE_EPOCH")
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", value, err)
+	}
+	return time.Unix(seconds, 0).UTC(), true, nil
+}
+
+// BaseURLPath returns the path component of the BaseURL without the
+// trailing slash, e.g. "/blog" for https://example.com/blog/, or "" when
+// the site is deployed at the root
+func (c *Config) BaseURLPath() string {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// StaticPrefix returns the directory static files are published in,
+// relative to the public directory: "static", or "" with staticAtRoot
+func (c *Config) StaticPrefix() string {
+	if c.StaticAtRoot {
+		return ""
+	}
+	return "static"
+}
+
+// StaticPath returns the site path of a file in the static directory, given
+// relative to it, e.g. "/static/img/logo.png" or "/img/logo.png"
+func (c *Config) StaticPath(rel string) string {
+	return "/" + path.Join(c.StaticPrefix(), strings.TrimPrefix(rel, "/"))
+}
+
+// CutStaticPrefix returns a path relative to the public directory as a
+// path relative to the static directory, reporting whether it lies where
+// static files are published
+func (c *Config) CutStaticPrefix(rel string) (string, bool) {
+	if c.StaticAtRoot {
+		return rel, true
+	}
+	return strings.CutPrefix(rel, "static/")
+}
+
+// RelURL turns a site path such as "/css/style.css" into the URL it is
+// served at: prefixed with the BaseURL path, or relative to the <base> tag
+// when BaseTag is set. Absolute URLs and fragments are returned unchanged.
+func (c *Config) RelURL(path string) string {
+	if isAbsoluteURL(path) || strings.HasPrefix(path, "#") {
+		return path
+	}
+	path = strings.TrimPrefix(path, "/")
+	if c.BaseTag {
+		if path == "" {
+			return "./"
+		}
+		return path
+	}
+	return c.BaseURLPath() + "/" + path
+}
+
+// AbsURL turns a site path into an absolute URL against the BaseURL.
+// Absolute URLs are returned unchanged.
+func (c *Config) AbsURL(path string) string {
+	if isAbsoluteURL(path) {
+		return path
+	}
+	return strings.TrimSuffix(c.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// isAbsoluteURL reports whether s has a scheme or is protocol-relative
+func isAbsoluteURL(s string) bool {
+	if strings.HasPrefix(s, "//") {
+		return true
+	}
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != ""
+}
+
+// GetTaxonomies returns the configured taxonomies, or tags and categories
+// when none are configured
+func (c *Config) GetTaxonomies() map[string]string {
+	if c.Taxonomies == nil {
+		return DefaultTaxonomies
+	}
+	return c.Taxonomies
+}
+
+// GetLocation returns the site time zone, UTC when none is configured
+func (c *Config) GetLocation() *time.Location {
+	if c.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
 }
@@ -710,6 +1715,75 @@ func (c *Config) IsDevelopment() bool {
 	return c.Environment == "development"
 }
 
+// AnalyticsEnabled reports whether this build gets the analytics snippet:
+// a provider is set, the environment is production unless
+// productionOnly is off, and the development server only with serve
+func (c *Config) AnalyticsEnabled() bool {
+	a := c.Analytics
+	if a.Provider == "" {
+		return false
+	}
+	if a.ProductionOnly && !c.IsProduction() {
+		return false
+	}
+	return !c.IsServing || a.Serve
+}
+
+// validateFavicon checks the source is a PNG or SVG image and the colors
+// are hex colors
+func validateFavicon(f FaviconConfig) error {
+	if f.Source == "" {
+		return nil
+	}
+	if ext := strings.ToLower(path.Ext(f.Source)); ext != ".png" && ext != ".svg" {
+		return fmt.Errorf("invalid favicon.source %q: must be a .png or .svg image", f.Source)
+	}
+	for _, c := range []struct{ name, value string }{
+		{"themeColor", f.ThemeColor},
+		{"backgroundColor", f.BackgroundColor},
+	} {
+		if c.value != "" && !IsHexColor(c.value) {
+			return fmt.Errorf("invalid favicon.%s %q: must be a hex color such as \"#336699\"", c.name, c.value)
+		}
+	}
+	return nil
+}
+
+// IsHexColor reports whether s is a CSS hex color: #rgb or #rrggbb
+func IsHexColor(s string) bool {
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || len(hex) != 3 && len(hex) != 6 {
+		return false
+	}
+	for _, r := range hex {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// validateAnalytics checks the provider is known and has what its snippet
+// needs
+func validateAnalytics(a AnalyticsConfig) error {
+	if a.Provider == "" {
+		return nil
+	}
+	known := false
+	for _, provider := range AnalyticsProviders {
+		known = known || provider == a.Provider
+	}
+	switch {
+	case !known:
+		return fmt.Errorf("invalid analytics.provider %q: must be one of %s", a.Provider, strings.Join(AnalyticsProviders, ", "))
+	case a.Provider == "custom" && a.Template == "":
+		return fmt.Errorf("analytics.template is required for the custom provider")
+	case a.SiteID == "" && a.Provider != "plausible" && a.Provider != "custom":
+		return fmt.Errorf("analytics.siteID is required for %s", a.Provider)
+	}
+	return nil
+}
+
 func (c *Config) GetLanguage(code string) (Language, bool) {
 	lang, exists := c.Languages[code]
 	return lang, exists
@@ -728,17 +1802,12 @@ func (c *Config) GetDefaultLanguage() Language {
 	}
 }
 
-// Utility functions
-func parseInt(s string) int {
-	// Simple integer parsing with error handling
-	var result int
-	fmt.Sscanf(s, "%d", &result)
-	return result
-}
-
-func getNumCPU() int {
-	// Would use runtime.NumCPU() in real implementation
-	return 4
+// DefaultWorkers returns the worker count used when none is configured: one
+// per CPU, capped at 8 so that memory use stays bounded on large machines.
+// Set workers explicitly to go beyond the cap; `vango benchmark --scaling`
+// shows whether a given machine benefits.
+func DefaultWorkers() int {
+	return max(1, min(8, runtime.NumCPU()))
 }
 
 func min(a, b int) int {
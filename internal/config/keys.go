@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"vango/internal/logging"
+	"vango/internal/util"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v2"
+)
+
+// SetStrict makes unknown configuration keys errors instead of warnings
+func (cl *ConfigLoader) SetStrict(strict bool) {
+	cl.strict = strict
+}
+
+// UnknownKeys returns the unknown keys found by the last LoadConfig, each
+// prefixed with the file it was found in
+func (cl *ConfigLoader) UnknownKeys() []string {
+	return cl.unknownKeys
+}
+
+// configFormat returns "toml" or "yaml" for a configuration file, detecting
+// the format from the content when the extension doesn't tell
+func configFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return "toml"
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	if strings.Contains(string(data), "=") {
+		return "toml"
+	}
+	return "yaml"
+}
+
+// rawConfig decodes a configuration file into a generic map, with the same
+// shape for TOML and YAML
+func rawConfig(data []byte, format string) (map[string]interface{}, error) {
+	raw := make(map[string]interface{})
+	switch format {
+	case "toml":
+		tree, err := toml.LoadBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		raw = tree.ToMap()
+	default:
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+		util.NormalizeMap(raw)
+	}
+	return raw, nil
+}
+
+// checkKeys records the keys of a configuration file that match no setting,
+// since the decoders silently ignore them
+func (cl *ConfigLoader) checkKeys(path string, raw map[string]interface{}, format string) {
+	for _, key := range util.UnknownKeys(raw, Config{}, format) {
+		cl.unknownKeys = append(cl.unknownKeys, fmt.Sprintf("%s: %s", path, key))
+	}
+}
+
+// reportUnknownKeys logs the unknown keys as warnings, or returns them as an
+// error in strict mode
+func (cl *ConfigLoader) reportUnknownKeys() error {
+	if len(cl.unknownKeys) == 0 || cl.deferChecks {
+		return nil
+	}
+	if cl.strict {
+		return fmt.Errorf("%d unknown configuration keys: %s", len(cl.unknownKeys), strings.Join(cl.unknownKeys, "; "))
+	}
+	for _, key := range cl.unknownKeys {
+		logging.Warnf("⚠️  %s", key)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// proxyTable is ProxyConfig decoded as a table, without the short form
+type proxyTable ProxyConfig
+
+// UnmarshalTOML reads a proxy written as its target URL or as a table
+func (p *ProxyConfig) UnmarshalTOML(value interface{}) error {
+	switch value := value.(type) {
+	case string:
+		*p = ProxyConfig{Target: value}
+		return nil
+	case map[string]interface{}:
+		tree, err := toml.TreeFromMap(value)
+		if err != nil {
+			return err
+		}
+		var table proxyTable
+		if err := tree.Unmarshal(&table); err != nil {
+			return err
+		}
+		*p = ProxyConfig(table)
+		return nil
+	}
+	return fmt.Errorf("proxy must be a target URL or a table, not %T", value)
+}
+
+// UnmarshalYAML reads a proxy written as its target URL or as a mapping
+func (p *ProxyConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var target string
+	if err := unmarshal(&target); err == nil {
+		*p = ProxyConfig{Target: target}
+		return nil
+	}
+	var table proxyTable
+	if err := unmarshal(&table); err != nil {
+		return err
+	}
+	*p = ProxyConfig(table)
+	return nil
+}
+
+// validateProxies checks the path prefixes and backends of server.proxies
+func validateProxies(proxies map[string]ProxyConfig) error {
+	for prefix, proxy := range proxies {
+		if !strings.HasPrefix(prefix, "/") || strings.ContainsAny(prefix, " \t{}?#") {
+			return fmt.Errorf("invalid server.proxies prefix %q: must be a URL path like \"/api/\"", prefix)
+		}
+		target, err := url.Parse(proxy.Target)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" ||
+			target.RawQuery != "" || target.Fragment != "" {
+			return fmt.Errorf("invalid server.proxies %q target %q: must be an http or https URL like \"http://localhost:4000\"", prefix, proxy.Target)
+		}
+		if proxy.Rewrite != "" && !strings.HasPrefix(proxy.Rewrite, "/") {
+			return fmt.Errorf("invalid server.proxies %q rewrite %q: must be a path starting with /", prefix, proxy.Rewrite)
+		}
+		for name := range proxy.Headers {
+			if name == "" || strings.ContainsAny(name, " \t:\r\n") {
+				return fmt.Errorf("invalid server.proxies %q header %q: must be a header name", prefix, name)
+			}
+		}
+	}
+	return nil
+}
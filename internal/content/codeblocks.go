@@ -0,0 +1,210 @@
+package content
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	gmutil "github.com/yuin/goldmark/util"
+)
+
+// ParseFenceInfo splits a fenced code block's info string into its
+// language and the attributes in braces after it, e.g.
+// `go {filename="main.go" hl_lines="2-4"}`. Values may be quoted, bare or
+// a bracketed list; attributes are separated by spaces or commas. A fence
+// with just a language, or nothing, has no attributes.
+func ParseFenceInfo(info string) (string, map[string]string) {
+	info = strings.TrimSpace(info)
+	var language string
+	if !strings.HasPrefix(info, "{") {
+		end := strings.IndexAny(info, " \t{")
+		if end < 0 {
+			return info, nil
+		}
+		language, info = info[:end], strings.TrimSpace(info[end:])
+	}
+	if !strings.HasPrefix(info, "{") || !strings.HasSuffix(info, "}") {
+		return language, nil
+	}
+	attrs := parseFenceAttributes(info[1 : len(info)-1])
+	if len(attrs) == 0 {
+		return language, nil
+	}
+	return language, attrs
+}
+
+// parseFenceAttributes reads key=value pairs, keeping the last of repeated
+// keys. A key without a value is set to "".
+func parseFenceAttributes(s string) map[string]string {
+	attrs := make(map[string]string)
+	isSep := func(c byte) bool { return c == ' ' || c == '\t' || c == ',' }
+	for i := 0; i < len(s); {
+		if isSep(s[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(s) && !isSep(s[i]) && s[i] != '=' {
+			i++
+		}
+		key := s[start:i]
+		if i >= len(s) || s[i] != '=' {
+			attrs[key] = ""
+			continue
+		}
+		i++
+		var value string
+		switch {
+		case i < len(s) && (s[i] == '"' || s[i] == '\''):
+			quote := s[i]
+			end := strings.IndexByte(s[i+1:], quote)
+			if end < 0 {
+				value, i = s[i+1:], len(s)
+			} else {
+				value, i = s[i+1:i+1+end], i+end+2
+			}
+		case i < len(s) && s[i] == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				value, i = s[i:], len(s)
+			} else {
+				value, i = s[i:i+end+1], i+end+1
+			}
+		default:
+			start := i
+			for i < len(s) && !isSep(s[i]) {
+				i++
+			}
+			value = s[start:i]
+		}
+		attrs[key] = value
+	}
+	return attrs
+}
+
+// ParseLineRanges returns the line numbers a hl_lines value lists, sorted
+// and without repeats: ranges and single lines such as "2-4 7", "2-4,7"
+// or `[2,"4-5"]`. Entries that aren't line numbers are ignored.
+func ParseLineRanges(spec string) []int {
+	spec = strings.NewReplacer("[", " ", "]", " ", `"`, " ", "'", " ", ",", " ").Replace(spec)
+	seen := make(map[int]bool)
+	var lines []int
+	for _, field := range strings.Fields(spec) {
+		from, to, isRange := strings.Cut(field, "-")
+		first, err := strconv.Atoi(from)
+		if err != nil || first < 1 {
+			continue
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(to); err != nil || last < first {
+				continue
+			}
+		}
+		for line := first; line <= last; line++ {
+			if !seen[line] {
+				seen[line] = true
+				lines = append(lines, line)
+			}
+		}
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// newCodeBlock describes a fenced code block from its info string and code
+func newCodeBlock(info, code string) CodeBlock {
+	language, attrs := ParseFenceInfo(info)
+	block := CodeBlock{
+		Language:   language,
+		Code:       code,
+		Lines:      len(strings.Split(code, "\n")),
+		Filename:   attrs["filename"],
+		Attributes: attrs,
+	}
+	if spec, ok := attrs["hl_lines"]; ok {
+		block.HighlightLines = ParseLineRanges(spec)
+	}
+	return block
+}
+
+// codeBlockExtension renders fenced code blocks with attributes in a
+// figure carrying the filename caption and highlighted lines
+type codeBlockExtension struct{}
+
+// Extend registers the code block renderer ahead of the default one
+func (codeBlockExtension) Extend(m goldmark.Markdown) {
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(gmutil.Prioritized(codeBlockRenderer{}, 200)))
+}
+
+// codeBlockRenderer renders fenced code blocks. Blocks without attributes
+// come out as the default renderer writes them, and the language is the
+// first word of the info string as before.
+type codeBlockRenderer struct{}
+
+// RegisterFuncs implements renderer.NodeRenderer
+func (r codeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r codeBlockRenderer) renderFencedCodeBlock(w gmutil.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.FencedCodeBlock)
+	var info string
+	if n.Info != nil {
+		info = string(n.Info.Segment.Value(source))
+	}
+	block := newCodeBlock(info, "")
+
+	if !entering {
+		w.WriteString("</code></pre>\n")
+		if block.Attributes != nil {
+			w.WriteString("</figure>\n")
+		}
+		return ast.WalkContinue, nil
+	}
+
+	if block.Attributes != nil {
+		w.WriteString(`<figure class="code-block"`)
+		writeDataAttr(w, "lang", block.Language)
+		writeDataAttr(w, "filename", block.Filename)
+		if len(block.HighlightLines) > 0 {
+			lines := make([]string, len(block.HighlightLines))
+			for i, line := range block.HighlightLines {
+				lines[i] = strconv.Itoa(line)
+			}
+			writeDataAttr(w, "hl-lines", strings.Join(lines, ","))
+		}
+		w.WriteString(">\n")
+		if block.Filename != "" {
+			w.WriteString(`<figcaption class="code-filename">`)
+			w.Write(gmutil.EscapeHTML([]byte(block.Filename)))
+			w.WriteString("</figcaption>\n")
+		}
+	}
+	w.WriteString("<pre><code")
+	if block.Language != "" {
+		w.WriteString(` class="language-`)
+		html.DefaultWriter.Write(w, []byte(block.Language))
+		w.WriteString(`"`)
+	}
+	w.WriteByte('>')
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		html.DefaultWriter.RawWrite(w, line.Value(source))
+	}
+	return ast.WalkContinue, nil
+}
+
+// writeDataAttr writes a data- attribute, unless value is empty
+func writeDataAttr(w gmutil.BufWriter, name, value string) {
+	if value == "" {
+		return
+	}
+	w.WriteString(` data-` + name + `="`)
+	w.Write(gmutil.EscapeHTML([]byte(value)))
+	w.WriteString(`"`)
+}
@@ -0,0 +1,66 @@
+package content
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultExtensions are the extensions of content files when a site lists
+// none in contentExtensions
+var DefaultExtensions = []string{".md", ".markdown", ".mdown"}
+
+// IsContentFile reports whether name has one of extensions, ignoring case.
+// With no extensions, DefaultExtensions apply.
+func IsContentFile(name string, extensions []string) bool {
+	ext := path.Ext(filepath.ToSlash(name))
+	if ext == "" {
+		return false
+	}
+	if len(extensions) == 0 {
+		extensions = DefaultExtensions
+	}
+	for _, e := range extensions {
+		if strings.EqualFold(ext, "."+strings.TrimPrefix(e, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// isIndexFile reports whether name is a content file called base, such as
+// index.md or _index.markdown
+func isIndexFile(name, base string, extensions []string) bool {
+	return IsContentFile(name, extensions) && strings.EqualFold(strings.TrimSuffix(name, path.Ext(name)), base)
+}
+
+// IsSectionIndex reports whether name is the index of a branch, such as
+// _index.md
+func IsSectionIndex(name string, extensions []string) bool {
+	return isIndexFile(path.Base(filepath.ToSlash(name)), "_index", extensions)
+}
+
+// SectionIndex returns the _index file of the branch directory dir, or ""
+// when it has none
+func SectionIndex(dir string, extensions []string) string {
+	return findIndex(dir, "_index", extensions)
+}
+
+// findIndex returns the index file called base in dir, trying each
+// extension in lower and upper case
+func findIndex(dir, base string, extensions []string) string {
+	if len(extensions) == 0 {
+		extensions = DefaultExtensions
+	}
+	for _, e := range extensions {
+		e = "." + strings.TrimPrefix(e, ".")
+		for _, ext := range []string{strings.ToLower(e), strings.ToUpper(e)} {
+			file := filepath.Join(dir, base+ext)
+			if info, err := os.Stat(file); err == nil && !info.IsDir() {
+				return file
+			}
+		}
+	}
+	return ""
+}
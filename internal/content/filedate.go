@@ -0,0 +1,71 @@
+package content
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// filenameDate matches the Jekyll-style date prefix of a file name, e.g.
+// "2024-01-15-my-post"
+var filenameDate = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
+
+// datedName returns the element of a slash path that carries a file name
+// date: the file name itself, or the directory of a bundle's index file
+func datedName(p string) (dir, name string) {
+	dir, name = path.Split(p)
+	if name == "index" || name == "_index" {
+		if bundle := strings.TrimSuffix(dir, "/"); bundle != "" {
+			dir, name = path.Split(bundle)
+			return dir, name + "/" + strings.TrimPrefix(p, bundle+"/")
+		}
+	}
+	return dir, name
+}
+
+// stripFilenameDate drops the date prefix from a slug, so
+// "posts/2024-01-15-my-post" becomes "posts/my-post"
+func stripFilenameDate(slug string) string {
+	dir, name := datedName(slug)
+	if m := filenameDate.FindStringSubmatch(name); m != nil {
+		return dir + m[2]
+	}
+	return slug
+}
+
+// dateFromFilename dates the page from its file name when front matter has
+// no date. When both are present the front matter wins.
+func (p *Parser) dateFromFilename(page *Page) {
+	slug := util.SlashPath(strings.TrimSuffix(page.FilePath, filepath.Ext(page.FilePath)))
+	_, name := datedName(slug)
+	m := filenameDate.FindStringSubmatch(name)
+	if m == nil {
+		return
+	}
+	t, ok := p.parseDate(m[1])
+	if !ok {
+		return
+	}
+
+	if page.DateSource == "frontmatter" {
+		if !sameDay(page.ParsedDate, t) {
+			logging.Debugf("📅 %s: front matter date %s differs from file name date %s, using front matter",
+				page.FilePath, page.ParsedDate.Format("2006-01-02"), m[1])
+		}
+		return
+	}
+	page.Date = m[1]
+	page.ParsedDate = t
+	page.DateSource = "filename"
+}
+
+// sameDay reports whether a and b fall on the same calendar day in a's zone
+func sameDay(a, b time.Time) bool {
+	b = b.In(a.Location())
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
@@ -0,0 +1,131 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v2"
+)
+
+// FrontMatterExpander evaluates the front matter string value at key, a
+// dotted path such as "description", "params.version" or "tags[1]"
+type FrontMatterExpander func(key, value string) (string, error)
+
+// SetFrontMatterExpander has front matter string values containing "{{"
+// evaluated by expand before they are assigned to the page. nil turns it
+// off.
+func (p *Parser) SetFrontMatterExpander(expand FrontMatterExpander) {
+	p.options.ExpandFrontMatter = expand
+}
+
+// expandFrontMatter returns the front matter with its string values
+// evaluated, re-encoded in its own format
+func (p *Parser) expandFrontMatter(content, delimiter string) (string, error) {
+	if !strings.Contains(content, "{{") {
+		return content, nil
+	}
+	expand := p.options.ExpandFrontMatter
+
+	format := delimiter
+	if format != "+++" && format != "---" && format != "{" {
+		// Same detection as parseFrontMatter
+		format = "+++"
+		if strings.Contains(content, ":") && !strings.Contains(content, "=") {
+			format = "---"
+		}
+	}
+
+	switch format {
+	case "+++":
+		tree, err := toml.Load(content)
+		if err != nil {
+			return "", err
+		}
+		data := tree.ToMap()
+		if err := expandValues(data, "", expand); err != nil {
+			return "", err
+		}
+		if tree, err = toml.TreeFromMap(data); err != nil {
+			return "", err
+		}
+		return tree.String(), nil
+	case "---":
+		var data yaml.MapSlice
+		if err := yaml.Unmarshal([]byte(content), &data); err != nil {
+			return "", err
+		}
+		if err := expandValues(data, "", expand); err != nil {
+			return "", err
+		}
+		out, err := yaml.Marshal(data)
+		return string(out), err
+	default:
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(content), &data); err != nil {
+			return "", err
+		}
+		if err := expandValues(data, "", expand); err != nil {
+			return "", err
+		}
+		out, err := json.Marshal(data)
+		return string(out), err
+	}
+}
+
+// expandValues evaluates the template strings in value, a decoded map or
+// list found at key, in place
+func expandValues(value interface{}, key string, expand FrontMatterExpander) error {
+	join := func(name string) string {
+		if key == "" {
+			return name
+		}
+		return key + "." + name
+	}
+	// expandOne returns v evaluated when it is a string, else after
+	// expanding the values inside it
+	expandOne := func(v interface{}, key string) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return v, expandValues(v, key, expand)
+		}
+		if !strings.Contains(s, "{{") {
+			return s, nil
+		}
+		out, err := expand(key, s)
+		if err != nil {
+			return nil, fmt.Errorf("front matter %s: %w", key, err)
+		}
+		return out, nil
+	}
+
+	var err error
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, child := range v {
+			if v[name], err = expandOne(child, join(name)); err != nil {
+				return err
+			}
+		}
+	case yaml.MapSlice:
+		for i := range v {
+			if v[i].Value, err = expandOne(v[i].Value, join(fmt.Sprint(v[i].Key))); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			if v[i], err = expandOne(v[i], fmt.Sprintf("%s[%d]", key, i)); err != nil {
+				return err
+			}
+		}
+	case []map[string]interface{}:
+		for i := range v {
+			if err := expandValues(v[i], fmt.Sprintf("%s[%d]", key, i), expand); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package content
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v2"
+)
+
+// FrontMatterEdit sets a top-level front matter key, or removes it
+type FrontMatterEdit struct {
+	Key    string
+	Value  interface{} // From ParseValue
+	Remove bool
+}
+
+var (
+	// numberPattern matches the decimal numbers ParseValue reads as numbers
+	numberPattern = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+	// tomlTablePattern matches a TOML table header, which ends the top level
+	tomlTablePattern = regexp.MustCompile(`^\s*\[`)
+)
+
+// ParseValue infers the type of a value given on the command line: true
+// and false are booleans, decimal numbers are integers or floats, [a, b]
+// is a list of such values, and anything else is a string. Quotes force a
+// string, as in "true".
+func ParseValue(s string) interface{} {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "true" || s == "false":
+		return s == "true"
+	case len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\''):
+		return s[1 : len(s)-1]
+	case strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]"):
+		items := []interface{}{}
+		for _, item := range splitList(s[1 : len(s)-1]) {
+			items = append(items, ParseValue(item))
+		}
+		return items
+	case numberPattern.MatchString(s):
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
+
+// splitList splits the inside of a list at the commas outside quotes,
+// dropping empty items
+func splitList(s string) []string {
+	var items []string
+	var quote rune
+	start := 0
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	items = append(items, s[start:])
+
+	kept := items[:0]
+	for _, item := range items {
+		if strings.TrimSpace(item) != "" {
+			kept = append(kept, strings.TrimSpace(item))
+		}
+	}
+	return kept
+}
+
+// formatValue writes a value in the syntax TOML and YAML flow values share
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return strconv.Quote(fmt.Sprint(v))
+	}
+}
+
+// EditFrontMatter applies edits to the front matter of a content file and
+// returns the new file. TOML and YAML front matter is edited line by line,
+// so other keys keep their order, formatting and comments; JSON front
+// matter is rewritten with sorted keys. The body is left as it is, and a
+// file without front matter gets TOML front matter.
+func EditFrontMatter(data []byte, edits []FrontMatterEdit) ([]byte, error) {
+	for _, edit := range edits {
+		if edit.Key == "" || strings.ContainsAny(edit.Key, ".=: \t\"'[]") {
+			return nil, fmt.Errorf("invalid key %q: only top-level keys can be edited", edit.Key)
+		}
+	}
+
+	frontMatter, delimiter, body, err := SplitFrontMatter(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	switch delimiter {
+	case "{":
+		return editJSONFrontMatter(frontMatter, body, edits)
+	case "":
+		delimiter = "+++"
+		data = append([]byte("+++\n+++\n"), data...)
+	}
+
+	// Split the raw file rather than use the parsed parts, so line endings
+	// and the body survive unchanged
+	lines := strings.SplitAfter(string(data), "\n")
+	closing := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], "\r\n") == delimiter {
+			closing = i
+			break
+		}
+	}
+	if closing < 0 {
+		return nil, fmt.Errorf("unterminated front matter")
+	}
+	newline := "\n"
+	if strings.HasSuffix(lines[0], "\r\n") {
+		newline = "\r\n"
+	}
+
+	fm := append([]string(nil), lines[1:closing]...)
+	for _, edit := range edits {
+		if delimiter == "+++" {
+			fm, err = editTOMLLines(fm, edit, newline)
+		} else {
+			fm, err = editYAMLLines(fm, edit, newline)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Never write front matter the parser can't read back
+	edited := strings.Join(fm, "")
+	if delimiter == "+++" {
+		_, err = toml.Load(edited)
+	} else {
+		err = yaml.Unmarshal([]byte(edited), &map[string]interface{}{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("edited front matter does not parse: %w", err)
+	}
+
+	out := lines[0] + edited + strings.Join(lines[closing:], "")
+	return []byte(out), nil
+}
+
+// editTOMLLines sets or removes a key among the top-level lines of TOML
+// front matter, before its first table
+func editTOMLLines(lines []string, edit FrontMatterEdit, newline string) ([]string, error) {
+	top := len(lines)
+	for i, line := range lines {
+		if tomlTablePattern.MatchString(line) {
+			top = i
+			break
+		}
+	}
+	keyPattern := regexp.MustCompile(`^\s*("?)` + regexp.QuoteMeta(edit.Key) + `("?)\s*=`)
+
+	for i := 0; i < top; i++ {
+		if !keyPattern.MatchString(lines[i]) {
+			continue
+		}
+		// A value may continue over several lines, as long arrays do
+		end := i + 1
+		for ; end <= top; end++ {
+			if _, err := toml.Load(strings.Join(lines[i:end], "")); err == nil {
+				break
+			}
+		}
+		if end > top {
+			return nil, fmt.Errorf("cannot find the end of %s's value", edit.Key)
+		}
+		out := replaceLines(lines, i, end, edit, " = ", newline)
+		if end == i+1 && !edit.Remove {
+			out[i] = keepComment(out[i], lines[i], func(s string) bool {
+				_, err := toml.Load(s)
+				return err == nil
+			})
+		}
+		return out, nil
+	}
+	if edit.Remove {
+		return lines, nil
+	}
+	return replaceLines(lines, lastContentLine(lines[:top]), lastContentLine(lines[:top]), edit, " = ", newline), nil
+}
+
+// editYAMLLines sets or removes a key of YAML front matter, taking the
+// indented or list lines below it as part of its value
+func editYAMLLines(lines []string, edit FrontMatterEdit, newline string) ([]string, error) {
+	keyPattern := regexp.MustCompile(`^("?)` + regexp.QuoteMeta(edit.Key) + `("?)\s*:(\s|$)`)
+
+	for i, line := range lines {
+		if !keyPattern.MatchString(line) {
+			continue
+		}
+		end := i + 1
+		for ; end < len(lines); end++ {
+			next := strings.TrimRight(lines[end], "\r\n")
+			if next != "" && next[0] != ' ' && next[0] != '\t' && next[0] != '-' {
+				break
+			}
+		}
+		// Blank lines before the next key separate it rather than belong here
+		end = i + 1 + lastContentLine(lines[i+1:end])
+		out := replaceLines(lines, i, end, edit, ": ", newline)
+		if end == i+1 && !edit.Remove {
+			out[i] = keepComment(out[i], lines[i], func(s string) bool {
+				return yaml.Unmarshal([]byte(s), &map[string]interface{}{}) == nil
+			})
+		}
+		return out, nil
+	}
+	if edit.Remove {
+		return lines, nil
+	}
+	return replaceLines(lines, lastContentLine(lines), lastContentLine(lines), edit, ": ", newline), nil
+}
+
+// keepComment carries the comment at the end of a replaced line over to its
+// replacement. The comment starts at the last '#' before which the line
+// still parses, so a '#' inside a quoted value is not taken for one.
+func keepComment(replacement, original string, parses func(string) bool) string {
+	text := strings.TrimRight(original, "\r\n")
+	for i := strings.LastIndex(text, "#"); i > 0; i = strings.LastIndex(text[:i], "#") {
+		if text[i-1] != ' ' && text[i-1] != '\t' {
+			continue
+		}
+		if parses(text[:i]) {
+			end := strings.TrimRight(replacement, "\r\n")
+			return end + " " + text[i:] + replacement[len(end):]
+		}
+	}
+	return replacement
+}
+
+// lastContentLine returns the index after the last line that isn't blank
+func lastContentLine(lines []string) int {
+	for i := len(lines); i > 0; i-- {
+		if strings.TrimSpace(lines[i-1]) != "" {
+			return i
+		}
+	}
+	return 0
+}
+
+// replaceLines replaces lines[start:end] with the edited key, or removes
+// them when the edit removes it
+func replaceLines(lines []string, start, end int, edit FrontMatterEdit, separator, newline string) []string {
+	out := append([]string(nil), lines[:start]...)
+	if !edit.Remove {
+		if start > 0 && !strings.HasSuffix(out[start-1], "\n") {
+			out[start-1] += newline
+		}
+		out = append(out, edit.Key+separator+formatValue(edit.Value)+newline)
+	}
+	return append(out, lines[end:]...)
+}
+
+// editJSONFrontMatter applies edits to JSON front matter
+func editJSONFrontMatter(frontMatter, body string, edits []FrontMatterEdit) ([]byte, error) {
+	data := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(frontMatter), &data); err != nil {
+		return nil, err
+	}
+	for _, edit := range edits {
+		if edit.Remove {
+			delete(data, edit.Key)
+		} else {
+			data[edit.Key] = edit.Value
+		}
+	}
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(append(encoded, '\n'), body...), nil
+}
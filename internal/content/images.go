@@ -0,0 +1,65 @@
+package content
+
+import "fmt"
+
+// ImageProcessor makes processed variants of image resources. ops are
+// applied to src in order, e.g. "resize 800x q85" then "grayscale".
+type ImageProcessor interface {
+	ProcessImage(src *Resource, ops []string) (*Resource, error)
+}
+
+// SetImageProcessor lets templates process the resource when it's an image
+func (r *Resource) SetImageProcessor(processor ImageProcessor) {
+	r.processor = processor
+}
+
+// Resize scales an image to spec, e.g. "800x", "x600" or "800x600 q85". A
+// missing dimension keeps the aspect ratio.
+func (r *Resource) Resize(spec string) (*Resource, error) {
+	return r.process("resize " + spec)
+}
+
+// Fit scales an image down to fit in spec, e.g. "800x600", keeping the
+// aspect ratio
+func (r *Resource) Fit(spec string) (*Resource, error) {
+	return r.process("fit " + spec)
+}
+
+// Fill scales and crops an image to exactly spec, e.g. "600x400" or
+// "600x400 top", keeping the part at the anchor, the center by default
+func (r *Resource) Fill(spec string) (*Resource, error) {
+	return r.process("fill " + spec)
+}
+
+// Grayscale turns an image to shades of gray
+func (r *Resource) Grayscale() (*Resource, error) {
+	return r.process("grayscale")
+}
+
+// process applies op after the operations already applied to the resource
+func (r *Resource) process(op string) (*Resource, error) {
+	if r.processor == nil || r.ResourceType != "image" {
+		return nil, fmt.Errorf("%s is not an image that can be processed", r.Name)
+	}
+	if r.imageSource == nil {
+		return r.processor.ProcessImage(r, []string{op})
+	}
+	ops := append(append([]string(nil), r.imageOps...), op)
+	return r.processor.ProcessImage(r.imageSource, ops)
+}
+
+// ImageVariant returns a resource for the image made by applying ops to r,
+// to be completed by the ImageProcessor with its location and size
+func (r *Resource) ImageVariant(ops []string) *Resource {
+	return &Resource{
+		Name:         r.Name,
+		Title:        r.Title,
+		Params:       r.Params,
+		Weight:       r.Weight,
+		ResourceType: r.ResourceType,
+		MediaType:    r.MediaType,
+		processor:    r.processor,
+		imageSource:  r,
+		imageOps:     ops,
+	}
+}
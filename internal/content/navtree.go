@@ -0,0 +1,195 @@
+package content
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Section is a top-level content directory, available to templates as
+// .Site.Sections.<name>
+type Section struct {
+	Name  string
+	Pages Pages
+	// Tree is the section's navigation tree, built from its directories
+	Tree *NavNode
+}
+
+// NavNode is a directory or page in a section's navigation tree. A
+// directory takes its title, URL and weight from its index page, either
+// <dir>/_index.md, <dir>/index.md or <dir>.md, when it has one.
+type NavNode struct {
+	Name     string // File or directory name
+	Title    string
+	URL      string // Site-relative URL, "" for a directory without an index page
+	Weight   int
+	Page     *Page
+	Children []*NavNode
+}
+
+// IsCurrent reports whether the node is page
+func (n *NavNode) IsCurrent(page *Page) bool {
+	return page != nil && n.Page != nil && n.Page.FilePath == page.FilePath
+}
+
+// IsAncestor reports whether page is below the node, so a sidebar can
+// expand the branch holding the page being rendered
+func (n *NavNode) IsAncestor(page *Page) bool {
+	for _, child := range n.Children {
+		if child.IsCurrent(page) || child.IsAncestor(page) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive reports whether the node is page or one of its ancestors
+func (n *NavNode) IsActive(page *Page) bool {
+	return n.IsCurrent(page) || n.IsAncestor(page)
+}
+
+// HasChildren reports whether the node has visible children
+func (n *NavNode) HasChildren() bool {
+	return len(n.Children) > 0
+}
+
+// BuildSectionTree builds the navigation tree of the section name from its
+// pages. Pages with `sidebar = false` in their front matter are left out,
+// along with everything below them. Siblings are ordered by weight, with
+// unweighted ones last, then by title.
+func BuildSectionTree(name string, pages Pages) *NavNode {
+	root := &NavNode{Name: name, Title: sectionTitle(name)}
+	for _, page := range pages {
+		rel := strings.TrimPrefix(strings.TrimPrefix(page.Slug, name), "/")
+		// A directory's own page, from its _index.md, index.md or <dir>.md,
+		// has the directory's slug
+		node := root
+		for _, part := range strings.Split(rel, "/") {
+			if part != "" {
+				node = node.child(part)
+			}
+		}
+		node.setPage(page)
+	}
+	root.prune()
+	return root
+}
+
+// child returns the child directory or page called name, adding it if needed
+func (n *NavNode) child(name string) *NavNode {
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	child := &NavNode{Name: name, Title: sectionTitle(name)}
+	n.Children = append(n.Children, child)
+	return child
+}
+
+// setPage makes page the node's own page
+func (n *NavNode) setPage(page *Page) {
+	n.Page = page
+	n.Title = page.Title
+	n.URL = page.RelPermalink
+	n.Weight = page.Weight
+}
+
+// prune drops hidden nodes and empty directories, and sorts the remaining
+// children
+func (n *NavNode) prune() {
+	children := n.Children[:0]
+	for _, child := range n.Children {
+		if child.Page != nil && !inSidebar(child.Page) {
+			continue
+		}
+		child.prune()
+		if child.Page == nil && len(child.Children) == 0 {
+			continue
+		}
+		children = append(children, child)
+	}
+	n.Children = children
+
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if (a.Weight == 0) != (b.Weight == 0) {
+			return b.Weight == 0
+		}
+		if a.Weight != b.Weight {
+			return a.Weight < b.Weight
+		}
+		if at, bt := strings.ToLower(a.Title), strings.ToLower(b.Title); at != bt {
+			return at < bt
+		}
+		return a.Name < b.Name
+	})
+}
+
+// inSidebar reports whether a page's front matter leaves it in navigation
+func inSidebar(page *Page) bool {
+	value, ok := page.FrontMatter["sidebar"]
+	if !ok {
+		value, ok = page.Params["sidebar"]
+	}
+	show, isBool := value.(bool)
+	return !ok || !isBool || show
+}
+
+// sectionTitle turns a directory name into a title, e.g. "Getting Started"
+// for "getting-started"
+func sectionTitle(name string) string {
+	return titleCase(name, "title", "en")
+}
+
+// Breadcrumb is one step of a page's ancestor chain, from the home page
+// down to the page itself
+type Breadcrumb struct {
+	Name string
+	URL  string // Site-relative URL, "" for a directory without an index page
+	Page *Page  // The directory's index page or the page itself, nil without one
+}
+
+// Breadcrumbs returns the ancestor chain of page, whose slash-separated
+// path below the content directory is contentPath: the home page, each
+// directory above the page, then the page itself. lookup finds a
+// directory's index page by its path, "." for the home page. Directories
+// are named by their index page's title, else by their humanized name;
+// the home page falls back to siteTitle.
+func Breadcrumbs(page *Page, contentPath, siteTitle string, lookup func(dir string) *Page) []Breadcrumb {
+	// An index page stands for its directory, so the chain ends there
+	dir := strings.TrimSuffix(contentPath, path.Ext(contentPath))
+	if base := path.Base(dir); base == "index" || base == "_index" {
+		dir = path.Dir(dir)
+	}
+	if dir == "." {
+		return []Breadcrumb{pageCrumb(page, siteTitle)}
+	}
+
+	var dirs []string
+	for parent := path.Dir(dir); parent != "."; parent = path.Dir(parent) {
+		dirs = append([]string{parent}, dirs...)
+	}
+	crumbs := []Breadcrumb{dirCrumb(lookup("."), siteTitle)}
+	for _, parent := range dirs {
+		crumbs = append(crumbs, dirCrumb(lookup(parent), sectionTitle(path.Base(parent))))
+	}
+	return append(crumbs, pageCrumb(page, sectionTitle(path.Base(dir))))
+}
+
+// dirCrumb is the crumb for a directory with index page, which may be nil
+func dirCrumb(index *Page, name string) Breadcrumb {
+	if index == nil {
+		return Breadcrumb{Name: name}
+	}
+	return pageCrumb(index, name)
+}
+
+// pageCrumb is the crumb for page, named fallback when it has no title
+func pageCrumb(page *Page, fallback string) Breadcrumb {
+	name := page.Title
+	if name == "" {
+		name = fallback
+	}
+	return Breadcrumb{Name: name, URL: page.RelPermalink, Page: page}
+}
@@ -1,13 +1,16 @@
 package content
 
 import (
-	"bufio"
+	"bytes"
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"os"
-	"path/filepath"
+	"path"
 	"regexp"
 	"sort"
 	"strings"
@@ -20,6 +23,9 @@ import (
 	"github.com/yuin/goldmark/renderer"
 	"github.com/yuin/goldmark/renderer/html"
 	"gopkg.in/yaml.v2"
+
+	"vango/internal/logging"
+	"vango/internal/util"
 )
 
 // Enhanced Page structure with additional features
@@ -28,6 +34,7 @@ type Page struct {
 	Title       string                 `toml:"title" yaml:"title"`
 	Date        string                 `toml:"date" yaml:"date"`
 	ParsedDate  time.Time
+	DateSource  string                 `toml:"-" yaml:"-"` // "frontmatter", "filename" or "" when undated
 	Draft       bool                   `toml:"draft" yaml:"draft"`
 	Description string                 `toml:"description" yaml:"description"`
 	Tags        []string               `toml:"tags" yaml:"tags"`
@@ -41,12 +48,15 @@ type Page struct {
 	Translationkey string `toml:"translationkey" yaml:"translationkey"`
 	Aliases     []string `toml:"aliases" yaml:"aliases"`
 	Keywords    []string `toml:"keywords" yaml:"keywords"`
+	ResourceMeta []ResourceMeta `toml:"resources" yaml:"resources"` // Titles and params for bundle files
 	
 	// SEO and social
 	MetaDescription string            `toml:"meta_description" yaml:"meta_description"`
 	OpenGraph       map[string]string `toml:"opengraph" yaml:"opengraph"`
 	TwitterCard     map[string]string `toml:"twitter_card" yaml:"twitter_card"`
 	CanonicalURL    string            `toml:"canonical_url" yaml:"canonical_url"`
+	Robots          string            `toml:"robots" yaml:"robots"`   // Meta robots directives, e.g. "noindex, nofollow"
+	Private         bool              `toml:"private" yaml:"private"` // Shorthand for robots = "noindex, nofollow"
 	
 	// Publishing control
 	PublishDate time.Time `toml:"publish_date" yaml:"publish_date"`
@@ -57,6 +67,11 @@ type Page struct {
 	Section     string `toml:"section" yaml:"section"`
 	Type        string `toml:"type" yaml:"type"`
 	Layout      string `toml:"layout" yaml:"layout"`
+	Kind        string `toml:"-" yaml:"-"` // "home", "section", "taxonomy", "term" or "page"
+	
+	// FrontMatter holds every front matter field, including those without a
+	// typed field above, such as custom taxonomies
+	FrontMatter map[string]interface{} `toml:"-" yaml:"-"`
 	
 	// Computed fields
 	Content     template.HTML
@@ -70,11 +85,12 @@ type Page struct {
 	FilePath    string
 	OutputPath  string
 	RelPermalink string
+	Resources   Resources `toml:"-" yaml:"-"` // Bundle files, for index.md pages in a directory of their own
 	
 	// Enhanced features
 	Hash        string            // Content hash for change detection
 	Headings    []Heading         // Extracted headings for TOC
-	Images      []Image           // Extracted images
+	Images      []Image           `toml:"-" yaml:"-"` // Extracted from the content; front matter images are social images
 	Links       []Link            // Extracted links
 	CodeBlocks  []CodeBlock       // Extracted code blocks
 	Related     []*Page           // Related pages
@@ -82,6 +98,18 @@ type Page struct {
 	PrevInSection *Page           // Previous page in section
 	NextInSection *Page           // Next page in section
 	
+	// Multi-part series, from the series and series_weight front matter
+	SeriesName     string                 // Series the page is part of
+	SeriesURL      string                 // Site path of the series' page, "" unless series is a taxonomy
+	Series         Pages `toml:"-" yaml:"-"` // Parts of the series in order
+	SeriesPosition int                    // Position in Series, starting at 1
+	SeriesCount    int
+	PrevInSeries   *Page
+	NextInSeries   *Page
+	
+	// Ancestor chain from the home page down to the page, from its content path
+	Breadcrumbs    []Breadcrumb `toml:"-" yaml:"-"`
+	
 	// Performance tracking
 	ParseTime   time.Duration
 	RenderTime  time.Duration
@@ -94,6 +122,25 @@ type Heading struct {
 	Text  string `json:"text"`
 	ID    string `json:"id"`
 	Anchor string `json:"anchor"`
+	Order int    `json:"order"` // Position in the document, starting at 1
+}
+
+// HeadingsBetween returns the headings whose level is within start and end,
+// inclusive, keeping document order. Levels outside 1-6 are clamped.
+func HeadingsBetween(start, end int, headings []Heading) []Heading {
+	if start < 1 {
+		start = 1
+	}
+	if end < 1 || end > 6 {
+		end = 6
+	}
+	filtered := make([]Heading, 0, len(headings))
+	for _, heading := range headings {
+		if heading.Level >= start && heading.Level <= end {
+			filtered = append(filtered, heading)
+		}
+	}
+	return filtered
 }
 
 // Image represents an image in the content
@@ -113,11 +160,15 @@ type Link struct {
 	External bool `json:"external"`
 }
 
-// CodeBlock represents a code block in the content
+// CodeBlock represents a code block in the content, with the attributes
+// from its fence, e.g. ```go {filename="main.go" hl_lines="2-4"}
 type CodeBlock struct {
-	Language string `json:"language"`
-	Code     string `json:"code"`
-	Lines    int    `json:"lines"`
+	Language       string            `json:"language"`
+	Code           string            `json:"code"`
+	Lines          int               `json:"lines"`
+	Filename       string            `json:"filename,omitempty"`
+	HighlightLines []int             `json:"hl_lines,omitempty"`
+	Attributes     map[string]string `json:"attributes,omitempty"`
 }
 
 // Enhanced Parser with additional features
@@ -135,8 +186,18 @@ type ParserOptions struct {
 	GenerateTOC       bool
 	EnableSummary     bool
 	SummaryLength     int
+	Ellipsis          string         // Ends truncated summaries
+	TOCStartLevel     int
+	TOCEndLevel       int
+	DefaultLanguage   string
+	Location          *time.Location // Zone for dates without an offset; UTC when nil
+	DateFromFilename  bool           // Date pages from a YYYY-MM-DD- file name prefix
+	KeepFilenameDate  bool           // Keep that prefix in the slug
 	EnableAnchors     bool
 	SafeMode          bool
+	ExpandFrontMatter FrontMatterExpander // Evaluates front matter strings; off when nil
+	TitleCaseStyle    string              // Casing of titles from file names: "title", "chicago" or "none"
+	Extensions        []string            // Content file extensions; DefaultExtensions when empty
 }
 
 // NewParser creates a parser with sensible default options.
@@ -145,9 +206,14 @@ func NewParser() *Parser {
     defaultOptions := ParserOptions{
         ExtractHeadings:   true,
         ExtractLinks:      true,
+        ExtractImages:     true,
+        ExtractCodeBlocks: true,
         GenerateTOC:       true,
         EnableSummary:     true,
         SummaryLength:     300,
+        Ellipsis:          util.DefaultEllipsis,
+        TOCStartLevel:     1,
+        TOCEndLevel:       6,
         EnableAnchors:     true,
         SafeMode:          false,
     }
@@ -164,6 +230,7 @@ func NewParserWithOptions(options ParserOptions) *Parser {
 		extension.TaskList,
 		extension.Footnote,
 		extension.DefinitionList,
+		codeBlockExtension{},
 	}
 
 	// Add typographer extension for smart quotes
@@ -200,49 +267,59 @@ func NewParserWithOptions(options ParserOptions) *Parser {
 	}
 }
 
+// SetDefaultLanguage sets the language of pages that don't declare one
+func (p *Parser) SetDefaultLanguage(lang string) {
+	p.options.DefaultLanguage = lang
+}
+
+// SetEllipsis sets the text that ends truncated summaries
+func (p *Parser) SetEllipsis(ellipsis string) {
+	p.options.Ellipsis = ellipsis
+}
+
+// SetLocation sets the time zone dates without an offset are read in. Parsed
+// dates are also converted to it, so templates show them in the site's zone.
+func (p *Parser) SetLocation(loc *time.Location) {
+	p.options.Location = loc
+}
+
+// SetExtensions sets the file extensions of content pages, matched
+// ignoring case. Bundle indexes with any of them are recognized.
+func (p *Parser) SetExtensions(extensions []string) {
+	p.options.Extensions = extensions
+}
+
+// Extensions returns the file extensions of content pages
+func (p *Parser) Extensions() []string {
+	return p.options.Extensions
+}
+
+// SetDateFromFilename dates pages from a YYYY-MM-DD- prefix on their file
+// name when front matter has none. The prefix is dropped from the slug
+// unless keepInSlug is set.
+func (p *Parser) SetDateFromFilename(enabled, keepInSlug bool) {
+	p.options.DateFromFilename = enabled
+	p.options.KeepFilenameDate = keepInSlug
+}
+
+// SetTOCLevels limits the generated table of contents to headings between
+// start and end, inclusive
+func (p *Parser) SetTOCLevels(start, end int) {
+	p.options.TOCStartLevel = start
+	p.options.TOCEndLevel = end
+}
+
 // ParseFile parses a content file with enhanced features
 func (p *Parser) ParseFile(filePath string, contentDir string) (*Page, error) {
 	startTime := time.Now()
 	
-	file, err := os.Open(filePath)
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	
-	// Read front matter and body
-	var frontMatter strings.Builder
-	var body strings.Builder
-	var inFrontMatter bool
-	var frontMatterDelim string
-
-	if scanner.Scan() {
-		firstLine := scanner.Text()
-		if firstLine == "+++" || firstLine == "---" {
-			inFrontMatter = true
-			frontMatterDelim = firstLine
-		} else {
-			body.WriteString(firstLine + "\n")
-		}
-	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		
-		if inFrontMatter {
-			if line == frontMatterDelim {
-				inFrontMatter = false
-				continue
-			}
-			frontMatter.WriteString(line + "\n")
-		} else {
-			body.WriteString(line + "\n")
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
+	frontMatter, frontMatterDelim, bodyContent, err := SplitFrontMatter(bytes.NewReader(data))
+	if err != nil {
 		return nil, fmt.Errorf("error reading file %s: %w", filePath, err)
 	}
 
@@ -263,14 +340,17 @@ func (p *Parser) ParseFile(filePath string, contentDir string) (*Page, error) {
 	}
 
 	// Parse front matter
-	if frontMatter.Len() > 0 {
-		if err := p.parseFrontMatter(frontMatter.String(), frontMatterDelim, page); err != nil {
+	if frontMatter != "" {
+		if err := p.parseFrontMatter(frontMatter, frontMatterDelim, page); err != nil {
 			return nil, fmt.Errorf("failed to parse front matter in %s: %w", filePath, err)
 		}
 	}
 
+	if p.options.DateFromFilename {
+		p.dateFromFilename(page)
+	}
+
 	// Generate content hash for change detection
-	bodyContent := body.String()
 	page.Hash = p.generateContentHash(bodyContent)
 
 	// Process content with enhanced features
@@ -283,28 +363,172 @@ func (p *Parser) ParseFile(filePath string, contentDir string) (*Page, error) {
 		return nil, fmt.Errorf("failed to generate URLs for %s: %w", filePath, err)
 	}
 
+	// Collect the files of a leaf bundle
+	if IsBundleIndex(filePath, contentDir, p.options.Extensions) {
+		resources, err := loadResources(page, page.ResourceMeta)
+		if err != nil {
+			return nil, err
+		}
+		page.Resources = resources
+	}
+
 	// Set defaults
 	p.setDefaults(page)
 
 	page.ParseTime = time.Since(startTime)
+	logging.Debugf("📄 Parsed %s (%d bytes) in %v", filePath, len(data), page.ParseTime)
 	return page, nil
 }
 
-// parseFrontMatter parses TOML or YAML front matter
+// ParseFrontMatter decodes only the front matter of a content file, the way
+// ParseFile would, without rendering its body
+func (p *Parser) ParseFrontMatter(filePath string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	frontMatter, frontMatterDelim, _, err := SplitFrontMatter(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %w", filePath, err)
+	}
+	page := &Page{Params: make(map[string]interface{})}
+	if frontMatter != "" {
+		if err := p.parseFrontMatter(frontMatter, frontMatterDelim, page); err != nil {
+			return nil, fmt.Errorf("failed to parse front matter in %s: %w", filePath, err)
+		}
+	}
+	return page.FrontMatter, nil
+}
+
+// SplitFrontMatter reads a content file and splits it into its front matter,
+// the delimiter that introduced it ("+++" for TOML, "---" for YAML, "{" for
+// JSON; empty when there is none) and the body. Front matter must be closed
+// by the same delimiter; a "---" followed by a blank line, or by nothing, is
+// a horizontal rule starting the body rather than front matter. Lines keep
+// their original endings, and there is no limit on their length.
+func SplitFrontMatter(r io.Reader) (frontMatter, delimiter, body string, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", "", "", err
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return "", "", "", nil
+	}
+	trim := func(line string) string { return strings.TrimRight(line, "\r\n") }
+
+	var fm strings.Builder
+	var b strings.Builder
+	bodyStart := 0
+	switch first := trim(lines[0]); {
+	case first == "+++" || first == "---" && len(lines) > 1 && strings.TrimSpace(lines[1]) != "":
+		closing := -1
+		for i := 1; i < len(lines); i++ {
+			if trim(lines[i]) == first {
+				closing = i
+				break
+			}
+		}
+		if closing < 0 {
+			return "", "", "", errors.New("unclosed front matter starting at line 1")
+		}
+		fm.WriteString(strings.Join(lines[1:closing], ""))
+		delimiter, bodyStart = first, closing+1
+	case strings.HasPrefix(first, "{"):
+		// JSON front matter runs until the matching closing brace. A first
+		// line that is more than "{" only opens front matter when the block
+		// is a JSON object; otherwise, like a {{< shortcode >}}, it is body.
+		var jsonScanner jsonFrontMatter
+		var jsonFM, jsonBody strings.Builder
+		end := -1
+		for i, line := range lines {
+			if jsonScanner.scan(trim(line), &jsonFM, &jsonBody) {
+				end = i + 1
+				break
+			}
+		}
+		switch {
+		case end >= 0 && (first == "{" || json.Valid([]byte(jsonFM.String()))):
+			fm.WriteString(jsonFM.String())
+			b.WriteString(jsonBody.String())
+			delimiter, bodyStart = "{", end
+		case first == "{":
+			return "", "", "", errors.New("unclosed JSON front matter starting at line 1")
+		}
+	}
+
+	b.WriteString(strings.Join(lines[bodyStart:], ""))
+	return fm.String(), delimiter, b.String(), nil
+}
+
+// jsonFrontMatter tracks brace depth while reading JSON front matter
+type jsonFrontMatter struct {
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+// scan consumes a line of JSON front matter and reports whether the closing
+// brace was reached. Anything after it on the same line belongs to the body.
+func (j *jsonFrontMatter) scan(line string, frontMatter, body *strings.Builder) bool {
+	for i, r := range line {
+		switch {
+		case j.escaped:
+			j.escaped = false
+		case j.inString && r == '\\':
+			j.escaped = true
+		case r == '"':
+			j.inString = !j.inString
+		case !j.inString && r == '{':
+			j.depth++
+		case !j.inString && r == '}':
+			j.depth--
+			if j.depth == 0 {
+				frontMatter.WriteString(line[:i+1] + "\n")
+				if rest := strings.TrimSpace(line[i+1:]); rest != "" {
+					body.WriteString(rest + "\n")
+				}
+				return true
+			}
+		}
+	}
+	frontMatter.WriteString(line + "\n")
+	return false
+}
+
+// parseFrontMatter parses TOML, YAML or JSON front matter
 func (p *Parser) parseFrontMatter(content, delimiter string, page *Page) error {
 	var err error
+	if p.options.ExpandFrontMatter != nil {
+		if content, err = p.expandFrontMatter(content, delimiter); err != nil {
+			return err
+		}
+	}
 	
 	switch delimiter {
 	case "+++":
-		err = toml.Unmarshal([]byte(content), page)
+		err = unmarshalTOML(content, page)
 	case "---":
-		err = yaml.Unmarshal([]byte(content), page)
+		err = unmarshalYAML(content, page)
+	case "{":
+		// Page fields are tagged for TOML and YAML, so route JSON through YAML
+		var data map[string]interface{}
+		if err = json.Unmarshal([]byte(content), &data); err == nil {
+			var converted []byte
+			if converted, err = yaml.Marshal(data); err == nil {
+				err = yaml.Unmarshal(converted, page)
+			}
+			page.FrontMatter = data
+		}
 	default:
 		// Auto-detect format
 		if strings.Contains(content, ":") && !strings.Contains(content, "=") {
-			err = yaml.Unmarshal([]byte(content), page)
+			err = unmarshalYAML(content, page)
 		} else {
-			err = toml.Unmarshal([]byte(content), page)
+			err = unmarshalTOML(content, page)
 		}
 	}
 	
@@ -312,6 +536,15 @@ func (p *Parser) parseFrontMatter(content, delimiter string, page *Page) error {
 		return err
 	}
 
+	// YAML decodes nested maps with interface{} keys; give every format the
+	// same string-keyed shape so templates and JSON encoding treat them alike
+	util.NormalizeMap(page.Params)
+	util.NormalizeMap(page.FrontMatter)
+	for _, meta := range page.ResourceMeta {
+		util.NormalizeMap(meta.Params)
+	}
+	page.Params = frontMatterParams(page.FrontMatter, page.Params)
+
 	// Parse dates
 	if err := p.parseDates(page); err != nil {
 		return err
@@ -320,54 +553,63 @@ func (p *Parser) parseFrontMatter(content, delimiter string, page *Page) error {
 	return nil
 }
 
-// parseDates parses various date fields
-func (p *Parser) parseDates(page *Page) error {
-	layouts := []string{
-		time.RFC3339,
-		"2006-01-02T15:04:05Z07:00",
-		"2006-01-02T15:04:05",
-		"2006-01-02 15:04:05",
-		"2006-01-02",
-		"01/02/2006",
-		"2006/01/02",
+// unmarshalTOML decodes TOML front matter into page and its FrontMatter map
+func unmarshalTOML(content string, page *Page) error {
+	tree, err := toml.Load(content)
+	if err != nil {
+		return err
+	}
+	if err := tree.Unmarshal(page); err != nil {
+		return err
+	}
+	page.FrontMatter = tree.ToMap()
+	return nil
+}
+
+// unmarshalYAML decodes YAML front matter into page and its FrontMatter map
+func unmarshalYAML(content string, page *Page) error {
+	if err := yaml.Unmarshal([]byte(content), page); err != nil {
+		return err
 	}
+	return yaml.Unmarshal([]byte(content), &page.FrontMatter)
+}
 
+// parseDates parses various date fields
+func (p *Parser) parseDates(page *Page) error {
 	// Parse main date
 	if page.Date != "" {
-		for _, layout := range layouts {
-			if t, err := time.Parse(layout, page.Date); err == nil {
-				page.ParsedDate = t
-				break
-			}
+		if t, ok := p.parseDate(page.Date); ok {
+			page.ParsedDate = t
+			page.DateSource = "frontmatter"
 		}
 	}
 
 	// Parse publish date
 	if publishDate, ok := page.Params["publish_date"].(string); ok {
-		for _, layout := range layouts {
-			if t, err := time.Parse(layout, publishDate); err == nil {
-				page.PublishDate = t
-				break
-			}
+		if t, ok := p.parseDate(publishDate); ok {
+			page.PublishDate = t
 		}
 	}
 
 	// Parse expiry date
 	if expiryDate, ok := page.Params["expiry_date"].(string); ok {
-		for _, layout := range layouts {
-			if t, err := time.Parse(layout, expiryDate); err == nil {
-				page.ExpiryDate = t
-				break
-			}
+		if t, ok := p.parseDate(expiryDate); ok {
+			page.ExpiryDate = t
 		}
 	}
 
 	// Parse last modified date
 	if lastMod, ok := page.Params["lastmod"].(string); ok {
-		for _, layout := range layouts {
-			if t, err := time.Parse(layout, lastMod); err == nil {
-				page.LastMod = t
-				break
+		if t, ok := p.parseDate(lastMod); ok {
+			page.LastMod = t
+		}
+	}
+
+	// Dates decoded straight from front matter carry their own offset
+	if loc := p.options.Location; loc != nil {
+		for _, t := range []*time.Time{&page.PublishDate, &page.ExpiryDate, &page.LastMod} {
+			if !t.IsZero() {
+				*t = t.In(loc)
 			}
 		}
 	}
@@ -375,8 +617,39 @@ func (p *Parser) parseDates(page *Page) error {
 	return nil
 }
 
+// dateLayouts are the front matter date formats, tried in order
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"01/02/2006",
+	"2006/01/02",
+}
+
+// parseDate parses a front matter date. Dates without an offset are read in
+// the configured location, so midnight means midnight in the site's zone.
+func (p *Parser) parseDate(value string) (time.Time, bool) {
+	loc := p.options.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			if p.options.Location != nil {
+				t = t.In(loc)
+			}
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
 // processContent converts markdown and extracts features
 func (p *Parser) processContent(content string, page *Page) error {
+	content = expandRefShortcodes(content)
+
 	// Convert markdown to HTML
 	var htmlBuf strings.Builder
 	if err := p.markdown.Convert([]byte(content), &htmlBuf); err != nil {
@@ -404,7 +677,8 @@ func (p *Parser) processContent(content string, page *Page) error {
 	}
 
 	if p.options.GenerateTOC && len(page.Headings) > 0 {
-		page.TableOfContents = p.generateTableOfContents(page.Headings)
+		headings := HeadingsBetween(p.options.TOCStartLevel, p.options.TOCEndLevel, page.Headings)
+		page.TableOfContents = p.generateTableOfContents(headings)
 	}
 
 	if p.options.EnableSummary {
@@ -412,26 +686,45 @@ func (p *Parser) processContent(content string, page *Page) error {
 	}
 
 	// Calculate reading metrics
-	words := strings.Fields(p.stripHTML(content))
-	page.WordCount = len(words)
-	page.ReadingTime = p.calculateReadingTime(page.WordCount)
+	page.WordCount = util.WordCount(content)
+	page.ReadingTime = util.ReadingTime(page.WordCount)
 
 	return nil
 }
 
+// Patterns for content extraction, compiled once since every page uses them
+var (
+	imagePattern     = regexp.MustCompile(`<img[^>]+src="([^"]*)"[^>]*(?:alt="([^"]*)")?[^>]*(?:title="([^"]*)")?[^>]*>`)
+	linkPattern      = regexp.MustCompile(`<a[^>]+href="([^"]*)"[^>]*(?:title="([^"]*)")?[^>]*>([^<]*)</a>`)
+	codeBlockPattern = regexp.MustCompile("```([^\\n`]*)\\n([\\s\\S]*?)```")
+	fencePattern     = regexp.MustCompile("```[\\s\\S]*?```")
+
+	// markdownPatterns strip markdown formatting for summaries
+	markdownPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`#{1,6}\s*`),               // Headers
+		regexp.MustCompile(`\*\*([^*]+)\*\*`),          // Bold
+		regexp.MustCompile(`\*([^*]+)\*`),              // Italic
+		regexp.MustCompile("`([^`]+)`"),                // Inline code
+		regexp.MustCompile(`\[([^\]]+)\]\([^\)]+\)`),  // Links
+		regexp.MustCompile(`!\[([^\]]*)\]\([^\)]+\)`), // Images
+	}
+)
+
 // extractHeadings extracts headings from HTML content
 func (p *Parser) extractHeadings(html string) []Heading {
-	re := regexp.MustCompile(`<h([1-6])(?:\s+id="([^"]*)")?[^>]*>([^<]+)</h[1-6]>`)
-	matches := re.FindAllStringSubmatch(html, -1)
+	matches := util.HeadingPattern.FindAllStringSubmatch(html, -1)
 	
 	var headings []Heading
 	for _, match := range matches {
-		level := parseInt(match[1])
+		level := util.ParseInt(match[1], 0)
+		if level < 1 || level > 6 {
+			continue
+		}
 		id := match[2]
 		text := strings.TrimSpace(match[3])
 		
 		if id == "" {
-			id = p.slugify(text)
+			id = util.Slugify(text)
 		}
 		
 		headings = append(headings, Heading{
@@ -439,6 +732,7 @@ func (p *Parser) extractHeadings(html string) []Heading {
 			Text:   text,
 			ID:     id,
 			Anchor: "#" + id,
+			Order:  len(headings) + 1,
 		})
 	}
 	
@@ -447,8 +741,7 @@ func (p *Parser) extractHeadings(html string) []Heading {
 
 // extractImages extracts images from HTML content
 func (p *Parser) extractImages(html string) []Image {
-	re := regexp.MustCompile(`<img[^>]+src="([^"]*)"[^>]*(?:alt="([^"]*)")?[^>]*(?:title="([^"]*)")?[^>]*>`)
-	matches := re.FindAllStringSubmatch(html, -1)
+	matches := imagePattern.FindAllStringSubmatch(html, -1)
 	
 	var images []Image
 	for _, match := range matches {
@@ -464,8 +757,7 @@ func (p *Parser) extractImages(html string) []Image {
 
 // extractLinks extracts links from HTML content
 func (p *Parser) extractLinks(html string) []Link {
-	re := regexp.MustCompile(`<a[^>]+href="([^"]*)"[^>]*(?:title="([^"]*)")?[^>]*>([^<]*)</a>`)
-	matches := re.FindAllStringSubmatch(html, -1)
+	matches := linkPattern.FindAllStringSubmatch(html, -1)
 	
 	var links []Link
 	for _, match := range matches {
@@ -485,20 +777,11 @@ func (p *Parser) extractLinks(html string) []Link {
 
 // extractCodeBlocks extracts code blocks from markdown content
 func (p *Parser) extractCodeBlocks(content string) []CodeBlock {
-	re := regexp.MustCompile("```(\\w+)?\\n([\\s\\S]*?)```")
-	matches := re.FindAllStringSubmatch(content, -1)
+	matches := codeBlockPattern.FindAllStringSubmatch(content, -1)
 	
 	var codeBlocks []CodeBlock
 	for _, match := range matches {
-		language := match[1]
-		code := strings.TrimSpace(match[2])
-		lines := len(strings.Split(code, "\n"))
-		
-		codeBlocks = append(codeBlocks, CodeBlock{
-			Language: language,
-			Code:     code,
-			Lines:    lines,
-		})
+		codeBlocks = append(codeBlocks, newCodeBlock(match[1], strings.TrimSpace(match[2])))
 	}
 	
 	return codeBlocks
@@ -516,8 +799,8 @@ func (p *Parser) generateTableOfContents(headings []Heading) template.HTML {
 	
 	for _, heading := range headings {
 		toc.WriteString(fmt.Sprintf(
-			`<li class="toc-level-%d"><a href="%s">%s</a></li>`,
-			heading.Level, heading.Anchor, heading.Text,
+			`<li class="toc-level-%d" data-order="%d"><a href="%s">%s</a></li>`,
+			heading.Level, heading.Order, heading.Anchor, heading.Text,
 		))
 	}
 	
@@ -525,54 +808,48 @@ func (p *Parser) generateTableOfContents(headings []Heading) template.HTML {
 	return template.HTML(toc.String())
 }
 
-// generateSummary creates a summary from content
+// generateSummary creates a summary from content: its text without code
+// blocks and markdown formatting, cut to maxLength characters at a sentence
+// or word
 func (p *Parser) generateSummary(content string, maxLength int) template.HTML {
-	// Remove code blocks first
-	re := regexp.MustCompile("```[\\s\\S]*?```")
-	content = re.ReplaceAllString(content, "")
-	
-	// Remove markdown formatting
+	content = fencePattern.ReplaceAllString(content, "")
 	content = p.stripMarkdown(content)
-	
-	// Truncate to maxLength
-	if len(content) <= maxLength {
-		return template.HTML(content)
-	}
-	
-	// Find the last complete sentence within maxLength
-	truncated := content[:maxLength]
-	lastPeriod := strings.LastIndex(truncated, ".")
-	lastExclamation := strings.LastIndex(truncated, "!")
-	lastQuestion := strings.LastIndex(truncated, "?")
-	
-	lastSentence := max(max(lastPeriod, lastExclamation), lastQuestion)
-	if lastSentence > maxLength-50 { // Only use if it's not too short
-		truncated = content[:lastSentence+1]
-	} else {
-		truncated += "..."
-	}
-	
-	return template.HTML(truncated)
+	return template.HTML(util.SummarizeHTML(content, maxLength, p.options.Ellipsis))
 }
 
 // generateURLs creates URL and slug for the page
 func (p *Parser) generateURLs(page *Page, contentDir string) error {
-	relPath, err := filepath.Rel(contentDir, page.FilePath)
+	relPath, err := util.RelSlashPath(contentDir, page.FilePath)
 	if err != nil {
 		return err
 	}
 
-	page.Slug = strings.TrimSuffix(relPath, filepath.Ext(relPath))
-	page.Slug = strings.ReplaceAll(page.Slug, "\\", "/")
+	page.Slug = strings.TrimSuffix(relPath, path.Ext(relPath))
+	if p.options.DateFromFilename && !p.options.KeepFilenameDate {
+		page.Slug = stripFilenameDate(page.Slug)
+	}
+	// A leaf bundle is published at its directory
+	if IsBundleIndex(page.FilePath, contentDir, p.options.Extensions) {
+		page.Slug = path.Dir(page.Slug)
+	}
 	
 	// Generate section from file path
-	pathParts := strings.Split(page.Slug, "/")
-	if len(pathParts) > 1 {
-		page.Section = pathParts[0]
+	if strings.Contains(page.Slug, "/") {
+		page.Section = util.FirstSegment(page.Slug)
+	}
+	
+	// An _index.md is the page of its directory: the home page at the
+	// content root, a section page anywhere else
+	if path.Base(page.Slug) == "_index" {
+		page.Slug = strings.TrimSuffix(path.Dir(page.Slug), ".")
+		page.Kind = "section"
+		if page.Slug == "" {
+			page.Kind = "home"
+		}
 	}
 	
 	// Generate URLs
-	page.URL = "/" + page.Slug + "/"
+	page.URL = util.SlugURL(page.Slug)
 	page.RelPermalink = page.URL
 	page.Permalink = page.URL // Would be full URL with baseURL in production
 
@@ -581,11 +858,15 @@ func (p *Parser) generateURLs(page *Page, contentDir string) error {
 
 // setDefaults sets default values for the page
 func (p *Parser) setDefaults(page *Page) {
-	if page.Title == "" {
-		page.Title = strings.ReplaceAll(filepath.Base(page.Slug), "-", " ")
-		page.Title = strings.Title(page.Title)
+	if page.Language == "" {
+		page.Language = p.options.DefaultLanguage
+		if page.Language == "" {
+			page.Language = "en"
+		}
 	}
 	
+	p.deriveTitle(page)
+	
 	if page.Type == "" {
 		page.Type = page.Section
 		if page.Type == "" {
@@ -593,13 +874,17 @@ func (p *Parser) setDefaults(page *Page) {
 		}
 	}
 	
-	if page.Language == "" {
-		page.Language = "en"
+	if page.Kind == "" {
+		page.Kind = "page"
 	}
 	
 	if page.MetaDescription == "" && len(page.Summary) > 0 {
 		page.MetaDescription = string(page.Summary)
 	}
+	
+	if page.Private && page.Robots == "" {
+		page.Robots = "noindex, nofollow"
+	}
 }
 
 // Helper functions
@@ -608,60 +893,34 @@ func (p *Parser) generateContentHash(content string) string {
 	return hex.EncodeToString(hash[:])
 }
 
-func (p *Parser) stripHTML(content string) string {
-	re := regexp.MustCompile(`<[^>]*>`)
-	return re.ReplaceAllString(content, "")
-}
-
 func (p *Parser) stripMarkdown(content string) string {
 	// Remove various markdown elements
-	patterns := []string{
-		`#{1,6}\s*`,      // Headers
-		`\*\*([^*]+)\*\*`, // Bold
-		`\*([^*]+)\*`,     // Italic
-		"`([^`]+)`",       // Inline code
-		`\[([^\]]+)\]\([^\)]+\)`, // Links
-		`!\[([^\]]*)\]\([^\)]+\)`, // Images
-	}
-	
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
+	for _, re := range markdownPatterns {
 		content = re.ReplaceAllString(content, "$1")
 	}
 	
 	return content
 }
 
-func (p *Parser) slugify(text string) string {
-	re := regexp.MustCompile(`[^a-zA-Z0-9\s-]`)
-	text = re.ReplaceAllString(text, "")
-	text = strings.ToLower(text)
-	text = regexp.MustCompile(`\s+`).ReplaceAllString(text, "-")
-	text = regexp.MustCompile(`-+`).ReplaceAllString(text, "-")
-	return strings.Trim(text, "-")
-}
-
-func (p *Parser) calculateReadingTime(wordCount int) int {
-	// Average reading speed: 200 words per minute
-	minutes := (wordCount + 199) / 200
-	if minutes < 1 {
-		return 1
-	}
-	return minutes
-}
 
 
 // Enhanced page methods
 func (page *Page) ShouldBuild(buildDrafts, buildFuture bool) bool {
+	return page.ShouldBuildAt(buildDrafts, buildFuture, time.Now())
+}
+
+// ShouldBuildAt reports whether the page is published at now. Dates are
+// absolute instants once parsed, so the build machine's zone doesn't matter.
+func (page *Page) ShouldBuildAt(buildDrafts, buildFuture bool, now time.Time) bool {
 	if page.Draft && !buildDrafts {
 		return false
 	}
 	
-	if !page.PublishDate.IsZero() && page.PublishDate.After(time.Now()) && !buildFuture {
+	if page.IsFutureAt(now) && !buildFuture {
 		return false
 	}
 	
-	if !page.ExpiryDate.IsZero() && page.ExpiryDate.Before(time.Now()) {
+	if page.IsExpiredAt(now) {
 		return false
 	}
 	
@@ -669,11 +928,47 @@ func (page *Page) ShouldBuild(buildDrafts, buildFuture bool) bool {
 }
 
 func (page *Page) IsExpired() bool {
-	return !page.ExpiryDate.IsZero() && page.ExpiryDate.Before(time.Now())
+	return page.IsExpiredAt(time.Now())
+}
+
+// IsExpiredAt reports whether the page's expiry date has passed at now
+func (page *Page) IsExpiredAt(now time.Time) bool {
+	return !page.ExpiryDate.IsZero() && page.ExpiryDate.Before(now)
 }
 
 func (page *Page) IsFuture() bool {
-	return !page.PublishDate.IsZero() && page.PublishDate.After(time.Now())
+	return page.IsFutureAt(time.Now())
+}
+
+// IsFutureAt reports whether the page is scheduled after now
+func (page *Page) IsFutureAt(now time.Time) bool {
+	publish := page.PublishAt()
+	return !publish.IsZero() && publish.After(now)
+}
+
+// PublishAt returns when the page goes live: its publish date, or else its
+// page date
+func (page *Page) PublishAt() time.Time {
+	if page.PublishDate.IsZero() {
+		return page.ParsedDate
+	}
+	return page.PublishDate
+}
+
+// IsHome reports whether the page is the home page, from content/_index.md
+func (page *Page) IsHome() bool {
+	return page.Kind == "home"
+}
+
+// IsSection reports whether the page is a section's own page, from its _index.md
+func (page *Page) IsSection() bool {
+	return page.Kind == "section"
+}
+
+// IsPage reports whether the page is a regular content page, rather than
+// a home, section or taxonomy page
+func (page *Page) IsPage() bool {
+	return page.Kind == "page"
 }
 
 func (page *Page) HasChanged(hash string) bool {
@@ -728,19 +1023,6 @@ func (page *Page) GetRelatedByTags(allPages []*Page, limit int) []*Page {
 	return related
 }
 
-// Utility functions
-func parseInt(s string) int {
-	switch s {
-	case "1": return 1
-	case "2": return 2
-	case "3": return 3
-	case "4": return 4
-	case "5": return 5
-	case "6": return 6
-	default: return 1
-	}
-}
-
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -753,6 +1035,64 @@ func (p *Page) GetParam(key string) interface{} {
 	return p.Params[key]
 }
 
+// TermValues returns the terms the page lists for the taxonomy stored in
+// the given front matter field. Fields may be a list or a single string and
+// are looked up in the front matter first, then in params.
+func (p *Page) TermValues(field string) []string {
+	switch field {
+	case "tags":
+		if len(p.Tags) > 0 {
+			return p.Tags
+		}
+	case "categories":
+		if len(p.Categories) > 0 {
+			return p.Categories
+		}
+	}
+
+	value, ok := p.FrontMatter[field]
+	if !ok {
+		value = p.Params[field]
+	}
+
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		terms := make([]string, 0, len(v))
+		for _, item := range v {
+			if term := strings.TrimSpace(fmt.Sprint(item)); term != "" {
+				terms = append(terms, term)
+			}
+		}
+		return terms
+	}
+	return nil
+}
+
+// NoIndex reports whether the page asks search engines not to index it,
+// which keeps it out of the sitemap and feeds
+func (p *Page) NoIndex() bool {
+	return p.Private || RobotsNoIndex(p.Robots)
+}
+
+// RobotsNoIndex reports whether meta robots directives such as
+// "noindex, follow" forbid indexing
+func RobotsNoIndex(robots string) bool {
+	for _, directive := range strings.Split(robots, ",") {
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "noindex", "none":
+			return true
+		}
+	}
+	return false
+}
+
 // SetParam sets a parameter value
 func (p *Page) SetParam(key string, value interface{}) {
 	p.Params[key] = value
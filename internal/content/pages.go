@@ -0,0 +1,79 @@
+package content
+
+import (
+	"sort"
+	"strings"
+)
+
+// Pages is an ordered collection of pages. Templates receive it as .Pages
+// and can reorder it with the By* methods, which return sorted copies.
+type Pages []*Page
+
+// defaultLess orders pages newest first, then by weight, then by title.
+// The file path breaks any remaining tie so the order never depends on the
+// order pages were parsed in.
+func defaultLess(a, b *Page) bool {
+	if !a.ParsedDate.Equal(b.ParsedDate) {
+		return a.ParsedDate.After(b.ParsedDate)
+	}
+	if a.Weight != b.Weight {
+		return a.Weight < b.Weight
+	}
+	if at, bt := strings.ToLower(a.Title), strings.ToLower(b.Title); at != bt {
+		return at < bt
+	}
+	return a.FilePath < b.FilePath
+}
+
+// sorted returns a copy of p ordered by less, falling back to the default order
+func (p Pages) sorted(less func(a, b *Page) (bool, bool)) Pages {
+	out := make(Pages, len(p))
+	copy(out, p)
+	sort.SliceStable(out, func(i, j int) bool {
+		if result, decided := less(out[i], out[j]); decided {
+			return result
+		}
+		return defaultLess(out[i], out[j])
+	})
+	return out
+}
+
+// Sorted returns the pages in the default order: date descending, with
+// weight and title as tie-breakers
+func (p Pages) Sorted() Pages {
+	return p.sorted(func(a, b *Page) (bool, bool) { return false, false })
+}
+
+// ByDate returns the pages newest first
+func (p Pages) ByDate() Pages {
+	return p.Sorted()
+}
+
+// ByTitle returns the pages sorted alphabetically by title
+func (p Pages) ByTitle() Pages {
+	return p.sorted(func(a, b *Page) (bool, bool) {
+		at, bt := strings.ToLower(a.Title), strings.ToLower(b.Title)
+		return at < bt, at != bt
+	})
+}
+
+// ByWeight returns the pages sorted by weight, lightest first
+func (p Pages) ByWeight() Pages {
+	return p.sorted(func(a, b *Page) (bool, bool) {
+		return a.Weight < b.Weight, a.Weight != b.Weight
+	})
+}
+
+// Reverse returns the pages in reverse order
+func (p Pages) Reverse() Pages {
+	out := make(Pages, len(p))
+	for i, page := range p {
+		out[len(p)-1-i] = page
+	}
+	return out
+}
+
+// Len returns the number of pages
+func (p Pages) Len() int {
+	return len(p)
+}
@@ -0,0 +1,77 @@
+package content
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// reservedFrontMatterKeys are the front matter keys VanGo reads itself:
+// those of Page's tagged fields, plus cascade, which a section's _index.md
+// passes down to its pages
+var reservedFrontMatterKeys = pageFieldKeys()
+
+// pageFieldKeys returns the front matter keys Page's fields are decoded from
+func pageFieldKeys() map[string]bool {
+	keys := map[string]bool{"cascade": true}
+	pageType := reflect.TypeOf(Page{})
+	for i := 0; i < pageType.NumField(); i++ {
+		field := pageType.Field(i)
+		for _, tag := range []string{"toml", "yaml"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name != "" && name != "-" {
+				keys[strings.ToLower(name)] = true
+			}
+		}
+	}
+	return keys
+}
+
+// frontMatterParams returns the page's params: the [params] table merged
+// with every top-level front matter key VanGo doesn't read itself, so a
+// custom field such as technologies = [...] works at either level. Keys are
+// lowercased. A key set in both places takes the [params] value, and keys
+// that differ only in case take the value of the all-lowercase spelling,
+// else of the first spelling in sort order.
+func frontMatterParams(frontMatter, params map[string]interface{}) map[string]interface{} {
+	merged := lowercaseKeys(params)
+	for key, value := range lowercaseKeys(frontMatter) {
+		if reservedFrontMatterKeys[key] {
+			continue
+		}
+		if _, set := merged[key]; !set {
+			merged[key] = value
+		}
+	}
+	if len(merged) == 0 {
+		return params
+	}
+	return merged
+}
+
+// CascadeParams returns the params a section's cascade passes down to its
+// pages: its params table merged with its other keys VanGo doesn't read
+// itself, the way a page's own front matter becomes its params
+func CascadeParams(cascade map[string]interface{}) map[string]interface{} {
+	params, _ := cascade["params"].(map[string]interface{})
+	return frontMatterParams(cascade, params)
+}
+
+// lowercaseKeys copies m with its top-level keys lowercased, resolving keys
+// that differ only in case as frontMatterParams describes
+func lowercaseKeys(m map[string]interface{}) map[string]interface{} {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lowered := make(map[string]interface{}, len(m))
+	for _, key := range keys {
+		lower := strings.ToLower(key)
+		if _, set := lowered[lower]; !set || key == lower {
+			lowered[lower] = m[key]
+		}
+	}
+	return lowered
+}
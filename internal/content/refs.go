@@ -0,0 +1,72 @@
+package content
+
+import (
+	"html/template"
+	"net/url"
+	"regexp"
+
+	"vango/internal/util"
+)
+
+// refShortcode matches {{< ref "path" >}} and {{< relref "path" >}} in markdown
+var refShortcode = regexp.MustCompile(`\{\{<\s*(ref|relref)\s+"([^"]+)"\s*>\}\}`)
+
+// refPlaceholder matches the placeholders refShortcode is replaced with
+var refPlaceholder = regexp.MustCompile(`vango-ref://(ref|relref)/([^"'\s<>()]+)`)
+
+// RefResolver resolves a ref or relref target from page to a URL
+type RefResolver func(kind, target string, page *Page) (string, error)
+
+// expandRefShortcodes replaces ref shortcodes with placeholders that survive
+// markdown conversion, e.g. as link destinations. They are resolved by
+// ResolveRefs once every page is known.
+func expandRefShortcodes(markdown string) string {
+	return refShortcode.ReplaceAllStringFunc(markdown, func(match string) string {
+		parts := refShortcode.FindStringSubmatch(match)
+		return "vango-ref://" + parts[1] + "/" + url.PathEscape(parts[2])
+	})
+}
+
+// HasRefs reports whether the page content links to other pages through
+// ref shortcodes
+func (p *Page) HasRefs() bool {
+	return refPlaceholder.MatchString(string(p.Content))
+}
+
+// ResolveRefs replaces the page's ref placeholders with the URLs resolve
+// returns. The first resolution error is returned after every placeholder
+// has been processed.
+func (p *Page) ResolveRefs(resolve RefResolver) error {
+	var firstErr error
+	replace := func(s string) string {
+		return refPlaceholder.ReplaceAllStringFunc(s, func(match string) string {
+			parts := refPlaceholder.FindStringSubmatch(match)
+			target, err := url.PathUnescape(parts[2])
+			if err != nil {
+				target = parts[2]
+			}
+			resolved, err := resolve(parts[1], target, p)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			return resolved
+		})
+	}
+
+	p.Content = template.HTML(replace(string(p.Content)))
+	p.Summary = template.HTML(replace(string(p.Summary)))
+	for i := range p.Links {
+		p.Links[i].URL = replace(p.Links[i].URL)
+	}
+	return firstErr
+}
+
+// ContentPath returns the page's content file path relative to contentDir,
+// with forward slashes
+func (p *Page) ContentPath(contentDir string) string {
+	rel, err := util.RelSlashPath(contentDir, p.FilePath)
+	if err != nil {
+		return util.SlashPath(p.FilePath)
+	}
+	return rel
+}
@@ -0,0 +1,218 @@
+package content
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"vango/internal/util"
+)
+
+// Resource is a file in a page bundle, published next to the page
+type Resource struct {
+	Name         string                 // Path relative to the bundle, e.g. "gallery/sunset.jpg"
+	Title        string                 // From a matching resources block, otherwise Name
+	Params       map[string]interface{} // From matching resources blocks
+	Weight       int                    // params.weight, for ordering
+	ResourceType string                 // Main media type, e.g. "image", or "page" for markdown
+	MediaType    string                 // Full media type, e.g. "image/jpeg"
+	RelPermalink string
+	Permalink    string
+	SourcePath   string // File the resource is copied from
+	PublishPath  string // Output path below the publish directory, e.g. "posts/trip/cover.jpg"
+	Width        int    // Pixel size of a processed image, 0 otherwise
+	Height       int
+
+	processor   ImageProcessor
+	imageSource *Resource // Original of a processed image
+	imageOps    []string  // Operations applied to imageSource
+}
+
+// ResourceMeta is a resources front matter block, giving the bundle files
+// matching Src a title and params. The first block matching a file sets its
+// title, and params are merged with earlier blocks taking precedence.
+// ":counter" in a title is replaced by the count of files the block matched
+// so far.
+type ResourceMeta struct {
+	Src    string                 `toml:"src" yaml:"src"`
+	Title  string                 `toml:"title" yaml:"title"`
+	Params map[string]interface{} `toml:"params" yaml:"params"`
+}
+
+// Resources is a page's bundle files, ordered by weight and then by name
+type Resources []*Resource
+
+// ByType returns the resources of a main media type, e.g. "image"
+func (r Resources) ByType(resourceType string) Resources {
+	var matched Resources
+	for _, resource := range r {
+		if resource.ResourceType == resourceType {
+			matched = append(matched, resource)
+		}
+	}
+	return matched
+}
+
+// Match returns the resources whose name matches a glob pattern such as
+// "gallery/*", ignoring case
+func (r Resources) Match(pattern string) Resources {
+	var matched Resources
+	for _, resource := range r {
+		if matchResource(pattern, resource.Name) {
+			matched = append(matched, resource)
+		}
+	}
+	return matched
+}
+
+// GetMatch returns the first resource whose name matches a glob pattern, or
+// nil when none does
+func (r Resources) GetMatch(pattern string) *Resource {
+	for _, resource := range r {
+		if matchResource(pattern, resource.Name) {
+			return resource
+		}
+	}
+	return nil
+}
+
+// matchResource reports whether name matches pattern, ignoring case
+func matchResource(pattern, name string) bool {
+	matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return err == nil && matched
+}
+
+// IsBundleIndex reports whether a content file is the index of a leaf
+// bundle: an index file, such as index.md, in a directory below the content
+// root, whose other files are the page's resources
+func IsBundleIndex(filePath, contentDir string, extensions []string) bool {
+	rel, err := util.RelSlashPath(contentDir, filePath)
+	if err != nil {
+		return false
+	}
+	dir, name := path.Split(rel)
+	return dir != "" && isIndexFile(name, "index", extensions)
+}
+
+// BundleIndex returns the index file of the leaf bundle containing a content
+// file, or "" when the file isn't in one
+func BundleIndex(filePath, contentDir string, extensions []string) string {
+	rel, err := util.RelSlashPath(contentDir, filePath)
+	if err != nil || strings.HasPrefix(rel, "../") {
+		return ""
+	}
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		if path.Dir(rel) == dir && isIndexFile(path.Base(rel), "index", extensions) {
+			continue
+		}
+		if index := findIndex(filepath.Join(contentDir, filepath.FromSlash(dir)), "index", extensions); index != "" {
+			return index
+		}
+	}
+	return ""
+}
+
+// loadResources collects the files of page's bundle, applies the resources
+// front matter blocks and orders them
+func loadResources(page *Page, meta []ResourceMeta) (Resources, error) {
+	bundleDir := filepath.Dir(page.FilePath)
+	var resources Resources
+	err := filepath.Walk(bundleDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || p == page.FilePath {
+			return nil
+		}
+		name, err := util.RelSlashPath(bundleDir, p)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, newResource(page, name, p))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %s: %w", bundleDir, err)
+	}
+
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Name < resources[j].Name })
+	applyResourceMeta(resources, meta)
+	sort.SliceStable(resources, func(i, j int) bool {
+		wi, wj := resources[i].Weight, resources[j].Weight
+		if (wi == 0) != (wj == 0) {
+			return wj == 0 // Weighted resources come first
+		}
+		return wi < wj
+	})
+	return resources, nil
+}
+
+// newResource describes the bundle file at p with default metadata
+func newResource(page *Page, name, p string) *Resource {
+	resource := NewResource(name, p)
+	resource.RelPermalink = page.URL + name
+	resource.Permalink = page.URL + name
+	return resource
+}
+
+// NewResource describes the file at sourcePath, published as name, with
+// its media type taken from the extension
+func NewResource(name, sourcePath string) *Resource {
+	ext := strings.ToLower(path.Ext(name))
+	mediaType, _, _ := strings.Cut(mime.TypeByExtension(ext), ";")
+	resourceType, _, _ := strings.Cut(mediaType, "/")
+	if IsContentFile(name, nil) {
+		mediaType, resourceType = "text/markdown", "page"
+	}
+	return &Resource{
+		Name:         name,
+		Title:        name,
+		Params:       make(map[string]interface{}),
+		ResourceType: resourceType,
+		MediaType:    mediaType,
+		SourcePath:   sourcePath,
+	}
+}
+
+// applyResourceMeta sets titles and params from the resources blocks
+func applyResourceMeta(resources Resources, meta []ResourceMeta) {
+	titled := make(map[*Resource]bool)
+	for _, block := range meta {
+		counter := 0
+		for _, resource := range resources {
+			if !matchResource(block.Src, resource.Name) {
+				continue
+			}
+			counter++
+			if block.Title != "" && !titled[resource] {
+				resource.Title = strings.ReplaceAll(block.Title, ":counter", fmt.Sprint(counter))
+				titled[resource] = true
+			}
+			for key, value := range block.Params {
+				if _, set := resource.Params[key]; !set {
+					resource.Params[key] = value
+				}
+			}
+		}
+	}
+	for _, resource := range resources {
+		resource.Weight = intParam(resource.Params["weight"])
+	}
+}
+
+// intParam converts a decoded front matter number to an int
+func intParam(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
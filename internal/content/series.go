@@ -0,0 +1,97 @@
+package content
+
+import (
+	"sort"
+
+	"vango/internal/util"
+)
+
+// SeriesField is the front matter field naming the series a page is part of
+const SeriesField = "series"
+
+// seriesWeightField is the front matter field ordering a part explicitly
+const seriesWeightField = "series_weight"
+
+// LinkSeries groups pages sharing a series value and sets each part's
+// series fields. Parts with a series_weight come first, lightest first,
+// then the rest oldest first; the file path breaks ties so the order is
+// stable. seriesURL returns the URL of a series' own page from its slug,
+// or "" when there is none.
+func LinkSeries(pages []*Page, seriesURL func(slug string) string) {
+	groups := make(map[string]Pages)
+	names := make(map[string]string)
+	for _, page := range pages {
+		page.SeriesName, page.SeriesURL, page.Series = "", "", nil
+		page.SeriesPosition, page.SeriesCount = 0, 0
+		page.PrevInSeries, page.NextInSeries = nil, nil
+
+		values := page.TermValues(SeriesField)
+		if len(values) == 0 || page.Kind != "page" {
+			continue
+		}
+		slug := util.Slugify(values[0])
+		if slug == "" {
+			continue
+		}
+		if _, ok := names[slug]; !ok {
+			names[slug] = values[0]
+		}
+		groups[slug] = append(groups[slug], page)
+	}
+
+	for slug, parts := range groups {
+		sort.SliceStable(parts, func(i, j int) bool {
+			return seriesLess(parts[i], parts[j])
+		})
+		url := seriesURL(slug)
+		for i, page := range parts {
+			page.SeriesName = names[slug]
+			page.SeriesURL = url
+			page.Series = parts
+			page.SeriesPosition = i + 1
+			page.SeriesCount = len(parts)
+			if i > 0 {
+				page.PrevInSeries = parts[i-1]
+			}
+			if i < len(parts)-1 {
+				page.NextInSeries = parts[i+1]
+			}
+		}
+	}
+}
+
+// seriesLess orders two parts of a series
+func seriesLess(a, b *Page) bool {
+	aw, aok := seriesWeight(a)
+	bw, bok := seriesWeight(b)
+	if aok != bok {
+		return aok
+	}
+	if aw != bw {
+		return aw < bw
+	}
+	if !a.ParsedDate.Equal(b.ParsedDate) {
+		return a.ParsedDate.Before(b.ParsedDate)
+	}
+	return a.FilePath < b.FilePath
+}
+
+// seriesWeight returns a page's series_weight, if it sets one
+func seriesWeight(page *Page) (int, bool) {
+	value, ok := page.FrontMatter[seriesWeightField]
+	if !ok {
+		value, ok = page.Params[seriesWeightField]
+	}
+	if !ok {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
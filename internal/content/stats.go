@@ -0,0 +1,146 @@
+package content
+
+import (
+	"sort"
+
+	"vango/internal/util"
+)
+
+// statsExtremes is how many of the longest and shortest pages SiteStats lists
+const statsExtremes = 5
+
+// SiteStats sums up a site's regular pages, for `vango stats` and as
+// .Site.Stats in templates
+type SiteStats struct {
+	Pages              int           `json:"pages"`
+	Words              int           `json:"words"`
+	ReadingTime        int           `json:"reading_time"` // Minutes to read every page
+	AverageWords       int           `json:"average_words"`
+	AverageReadingTime int           `json:"average_reading_time"` // Minutes
+	Years              []PeriodStats `json:"years"`                // Newest first
+	Months             []PeriodStats `json:"months"`               // Newest first, as "2006-01"
+	Tags               []TagStats    `json:"tags"`                 // Most used first
+	Longest            []PageStats   `json:"longest"`
+	Shortest           []PageStats   `json:"shortest"`
+}
+
+// PeriodStats counts the pages dated in a year or month
+type PeriodStats struct {
+	Period string `json:"period"`
+	Pages  int    `json:"pages"`
+	Words  int    `json:"words"`
+}
+
+// TagStats counts the pages with a tag
+type TagStats struct {
+	Tag   string `json:"tag"`
+	Slug  string `json:"slug"`
+	Pages int    `json:"pages"`
+	Words int    `json:"words"`
+}
+
+// PageStats is a page's entry in the longest and shortest lists
+type PageStats struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	File  string `json:"file"`
+	Words int    `json:"words"`
+}
+
+// ComputeStats sums up the regular pages, only those of section when it
+// isn't empty, in a single pass over them
+func ComputeStats(pages []*Page, section string) *SiteStats {
+	stats := &SiteStats{}
+	years := make(map[string]*PeriodStats)
+	months := make(map[string]*PeriodStats)
+	tags := make(map[string]*TagStats)
+	var counted []*Page
+
+	count := func(periods map[string]*PeriodStats, period string, words int) {
+		p, ok := periods[period]
+		if !ok {
+			p = &PeriodStats{Period: period}
+			periods[period] = p
+		}
+		p.Pages++
+		p.Words += words
+	}
+
+	for _, page := range pages {
+		if page.Kind != "page" || section != "" && page.Section != section {
+			continue
+		}
+		counted = append(counted, page)
+		stats.Pages++
+		stats.Words += page.WordCount
+		stats.ReadingTime += page.ReadingTime
+		if !page.ParsedDate.IsZero() {
+			count(years, page.ParsedDate.Format("2006"), page.WordCount)
+			count(months, page.ParsedDate.Format("2006-01"), page.WordCount)
+		}
+		seen := make(map[string]bool)
+		for _, tag := range page.Tags {
+			slug := util.Slugify(tag)
+			if slug == "" || seen[slug] {
+				continue
+			}
+			seen[slug] = true
+			t, ok := tags[slug]
+			if !ok {
+				t = &TagStats{Tag: tag, Slug: slug}
+				tags[slug] = t
+			}
+			t.Pages++
+			t.Words += page.WordCount
+		}
+	}
+	if stats.Pages == 0 {
+		return stats
+	}
+	stats.AverageWords = stats.Words / stats.Pages
+	stats.AverageReadingTime = util.ReadingTime(stats.AverageWords)
+	stats.Years = sortedPeriods(years)
+	stats.Months = sortedPeriods(months)
+
+	for _, t := range tags {
+		stats.Tags = append(stats.Tags, *t)
+	}
+	sort.Slice(stats.Tags, func(i, j int) bool {
+		if stats.Tags[i].Pages != stats.Tags[j].Pages {
+			return stats.Tags[i].Pages > stats.Tags[j].Pages
+		}
+		return stats.Tags[i].Slug < stats.Tags[j].Slug
+	})
+
+	sort.SliceStable(counted, func(i, j int) bool {
+		if counted[i].WordCount != counted[j].WordCount {
+			return counted[i].WordCount > counted[j].WordCount
+		}
+		return counted[i].FilePath < counted[j].FilePath
+	})
+	n := statsExtremes
+	if n > len(counted) {
+		n = len(counted)
+	}
+	for i := 0; i < n; i++ {
+		stats.Longest = append(stats.Longest, pageStats(counted[i]))
+		stats.Shortest = append(stats.Shortest, pageStats(counted[len(counted)-1-i]))
+	}
+	return stats
+}
+
+// sortedPeriods lists periods newest first
+func sortedPeriods(periods map[string]*PeriodStats) []PeriodStats {
+	list := make([]PeriodStats, 0, len(periods))
+	for _, p := range periods {
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Period > list[j].Period
+	})
+	return list
+}
+
+func pageStats(page *Page) PageStats {
+	return PageStats{Title: page.Title, URL: page.URL, File: util.SlashPath(page.FilePath), Words: page.WordCount}
+}
@@ -0,0 +1,83 @@
+package content
+
+import (
+	"sort"
+	"strings"
+
+	"vango/internal/util"
+)
+
+// Term is a single taxonomy term and the pages assigned to it
+type Term struct {
+	Name  string
+	Slug  string
+	URL   string
+	Pages Pages
+	// Page is the term's own page, from content/<taxonomy>/<term>/_index.md
+	// when present
+	Page *Page
+}
+
+// Count returns the number of pages assigned to the term
+func (t *Term) Count() int {
+	return len(t.Pages)
+}
+
+// Taxonomy maps term slugs to terms
+type Taxonomy map[string]*Term
+
+// Alphabetical returns the terms sorted by name
+func (t Taxonomy) Alphabetical() []*Term {
+	terms := t.terms()
+	sort.SliceStable(terms, func(i, j int) bool {
+		return strings.ToLower(terms[i].Name) < strings.ToLower(terms[j].Name)
+	})
+	return terms
+}
+
+// ByCount returns the terms sorted by page count, most used first
+func (t Taxonomy) ByCount() []*Term {
+	terms := t.Alphabetical()
+	sort.SliceStable(terms, func(i, j int) bool {
+		return len(terms[i].Pages) > len(terms[j].Pages)
+	})
+	return terms
+}
+
+func (t Taxonomy) terms() []*Term {
+	terms := make([]*Term, 0, len(t))
+	for _, term := range t {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool { return terms[i].Slug < terms[j].Slug })
+	return terms
+}
+
+// BuildTaxonomies groups pages by term for each taxonomy. taxonomies maps
+// singular names to plural names; the result is keyed by plural name, which
+// is also the front matter field and the URL section of the taxonomy.
+func BuildTaxonomies(pages []*Page, taxonomies map[string]string) map[string]Taxonomy {
+	result := make(map[string]Taxonomy, len(taxonomies))
+	for _, plural := range taxonomies {
+		taxonomy := make(Taxonomy)
+		for _, page := range pages {
+			seen := make(map[string]bool)
+			for _, name := range page.TermValues(plural) {
+				slug := util.Slugify(name)
+				if slug == "" || seen[slug] {
+					continue
+				}
+				seen[slug] = true
+
+				term, ok := taxonomy[slug]
+				if !ok {
+					term = &Term{Name: name, Slug: slug, URL: util.SlugURL(plural + "/" + slug)}
+					taxonomy[slug] = term
+				}
+				term.Pages = append(term.Pages, page)
+			}
+		}
+		result[plural] = taxonomy
+	}
+	return result
+}
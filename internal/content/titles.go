@@ -0,0 +1,96 @@
+package content
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+
+	"vango/internal/util"
+)
+
+// Where a page's title came from, in page.Params["titleSource"]
+const (
+	TitleFromFrontMatter = "frontmatter"
+	TitleFromHeading     = "heading"
+	TitleFromFilename    = "filename"
+	TitleFromDefault     = "default" // "Home" for an untitled home page
+)
+
+// firstH1Pattern matches the first level 1 heading, inner markup included
+var firstH1Pattern = regexp.MustCompile(`(?s)<h1[^>]*>(.*?)</h1>`)
+
+// smallWords stay lowercase inside titles cased in the chicago style
+var smallWords = map[string]bool{
+	"a": true, "an": true, "and": true, "as": true, "at": true, "but": true,
+	"by": true, "for": true, "from": true, "in": true, "into": true, "nor": true,
+	"of": true, "on": true, "or": true, "over": true, "per": true, "the": true,
+	"to": true, "up": true, "via": true, "vs": true, "with": true,
+}
+
+// SetTitleCaseStyle sets how titles derived from file names are cased:
+// "title" capitalizes every word, "chicago" keeps small words such as "of"
+// and "the" lowercase unless they come first or last, and "none" leaves
+// the words as they are. "" is "title".
+func (p *Parser) SetTitleCaseStyle(style string) {
+	p.options.TitleCaseStyle = style
+}
+
+// deriveTitle gives an untitled page the text of its first level 1
+// heading, else "Home" for the home page, else its file name in title
+// case, and records where the title came from
+func (p *Parser) deriveTitle(page *Page) {
+	source := TitleFromFrontMatter
+	switch {
+	case page.Title != "":
+	case firstHeading(string(page.Content)) != "":
+		page.Title, source = firstHeading(string(page.Content)), TitleFromHeading
+	case page.Kind == "home":
+		page.Title, source = "Home", TitleFromDefault
+	default:
+		name := path.Base(page.Slug)
+		if p.options.DateFromFilename {
+			name = path.Base(stripFilenameDate(page.Slug))
+		}
+		page.Title, source = titleCase(name, p.options.TitleCaseStyle, page.Language), TitleFromFilename
+	}
+	if page.Params == nil {
+		page.Params = make(map[string]interface{})
+	}
+	page.Params["titleSource"] = source
+}
+
+// firstHeading returns the plain text of the first <h1> in rendered
+// content, or ""
+func firstHeading(content string) string {
+	match := firstH1Pattern.FindStringSubmatch(content)
+	if match == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(util.StripHTML(match[1])), " ")
+}
+
+// titleCase turns a file name such as "getting-started_guide" into a
+// title in the given style, cased by the rules of lang
+func titleCase(name, style, lang string) string {
+	words := strings.Fields(strings.NewReplacer("-", " ", "_", " ").Replace(name))
+	if style == "none" {
+		return strings.Join(words, " ")
+	}
+	tag, err := language.Parse(lang)
+	if err != nil {
+		tag = language.English
+	}
+	// NoLower keeps acronyms such as "API" intact
+	caser := cases.Title(tag, cases.NoLower)
+	for i, word := range words {
+		if style == "chicago" && i > 0 && i < len(words)-1 && smallWords[strings.ToLower(word)] {
+			words[i] = strings.ToLower(word)
+			continue
+		}
+		words[i] = caser.String(word)
+	}
+	return strings.Join(words, " ")
+}
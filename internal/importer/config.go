@@ -0,0 +1,124 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v2"
+
+	"vango/internal/util"
+)
+
+// setting is a top-level config.toml key, written in order
+type setting struct {
+	key   string
+	value interface{}
+}
+
+// writeConfig writes the destination's config.toml. Empty settings are left
+// out so VanGo's defaults apply.
+func writeConfig(r *Report, settings []setting, taxonomies map[string]string, params map[string]interface{}) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by vango import %s from %s\n", r.Kind, r.Source)
+	for _, s := range settings {
+		switch v := s.value.(type) {
+		case string:
+			if v != "" {
+				fmt.Fprintf(&b, "%s = %q\n", s.key, v)
+			}
+		case bool:
+			if v {
+				fmt.Fprintf(&b, "%s = true\n", s.key)
+			}
+		case int:
+			if v != 0 {
+				fmt.Fprintf(&b, "%s = %d\n", s.key, v)
+			}
+		}
+	}
+
+	if len(taxonomies) > 0 {
+		singulars := make([]string, 0, len(taxonomies))
+		for singular := range taxonomies {
+			singulars = append(singulars, singular)
+		}
+		sort.Strings(singulars)
+		b.WriteString("\n[taxonomies]\n")
+		for _, singular := range singulars {
+			fmt.Fprintf(&b, "  %s = %q\n", singular, taxonomies[singular])
+		}
+	}
+
+	if len(params) > 0 {
+		tree, err := toml.TreeFromMap(map[string]interface{}{"params": util.NormalizeMap(params)})
+		if err != nil {
+			return fmt.Errorf("failed to convert params: %w", err)
+		}
+		out, err := tree.ToTomlString()
+		if err != nil {
+			return fmt.Errorf("failed to convert params: %w", err)
+		}
+		b.WriteString("\n" + strings.TrimLeft(out, "\n"))
+	}
+
+	name := filepath.Join(r.Dest, "config.toml")
+	if err := writeFile(name, []byte(b.String())); err != nil {
+		return err
+	}
+	r.Config = name
+	return nil
+}
+
+// readYAMLFile reads a YAML file into a string-keyed map; a missing file
+// gives an empty map
+func readYAMLFile(name string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(name)
+	if os.IsNotExist(err) {
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return util.NormalizeMap(m), nil
+}
+
+// stringValue returns value as a string, or "" when it is not a scalar
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool, int, int64, float64:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// stringList returns a list of scalars as strings
+func stringList(value interface{}) []string {
+	var list []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s := stringValue(item); s != "" {
+				list = append(list, s)
+			}
+		}
+	case []string:
+		list = v
+	case string:
+		if v != "" {
+			list = []string{v}
+		}
+	}
+	return list
+}
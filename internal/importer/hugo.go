@@ -0,0 +1,440 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v2"
+
+	"vango/internal/content"
+	"vango/internal/util"
+)
+
+// hugoConfigFiles are the names Hugo reads its configuration from, in order
+var hugoConfigFiles = []string{
+	"hugo.toml", "hugo.yaml", "hugo.yml", "hugo.json",
+	"config.toml", "config.yaml", "config.yml", "config.json",
+}
+
+// hugoFrontMatterKeys maps Hugo front matter keys to VanGo's
+var hugoFrontMatterKeys = map[string]string{
+	"publishDate": "publish_date",
+	"expiryDate":  "expiry_date",
+	"lastMod":     "lastmod",
+}
+
+var (
+	hugoHighlight = regexp.MustCompile(`(?s)\{\{<\s*highlight\s+(\w[\w+-]*)[^>]*>\}\}\n?(.*?)\n?\{\{<\s*/highlight\s*>\}\}`)
+	hugoFigure    = regexp.MustCompile(`\{\{<\s*figure\s+(.*?)\s*/?>\}\}`)
+	hugoShortcode = regexp.MustCompile(`\{\{([<%])\s*/?\s*(\w[\w/-]*).*?[>%]\}\}`)
+	hugoAttribute = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// hugoImport holds the state of a Hugo import
+type hugoImport struct {
+	opts       Options
+	report     *Report
+	config     map[string]interface{}
+	contentDir string
+	permalinks map[string]string
+	parser     *content.Parser
+}
+
+// importHugo converts content, copies static files, assets, data and i18n
+// files and generates config.toml from Hugo's configuration
+func importHugo(opts Options, report *Report) error {
+	config, err := readHugoConfig(opts.Source)
+	if err != nil {
+		return err
+	}
+
+	h := &hugoImport{
+		opts:       opts,
+		report:     report,
+		config:     config,
+		contentDir: stringValue(config["contentDir"]),
+		permalinks: make(map[string]string),
+		parser:     content.NewParser(),
+	}
+	if h.contentDir == "" {
+		h.contentDir = "content"
+	}
+	if permalinks, ok := config["permalinks"].(map[string]interface{}); ok {
+		for section, pattern := range permalinks {
+			if s, ok := pattern.(string); ok {
+				h.permalinks[section] = s
+			}
+		}
+	}
+	if zone := stringValue(config["timeZone"]); zone != "" {
+		if loc, err := time.LoadLocation(zone); err == nil {
+			h.parser.SetLocation(loc)
+		}
+	}
+
+	if err := h.convertContent(); err != nil {
+		return err
+	}
+	for _, dir := range []string{"assets", "data", "i18n", "archetypes"} {
+		if err := report.copyTree(filepath.Join(opts.Source, dir), filepath.Join(opts.Dest, dir), nil); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(filepath.Join(opts.Source, "archetypes")); err == nil {
+		report.addUntranslated("archetypes/", "archetypes may use Hugo template functions; check them with vango new")
+	}
+	if _, err := os.Stat(filepath.Join(opts.Source, "layouts")); err == nil {
+		report.addUntranslated("layouts/", "Hugo templates must be ported to VanGo's layouts")
+	}
+	if err := h.copyStatic(); err != nil {
+		return err
+	}
+	return h.writeConfig()
+}
+
+// readHugoConfig reads the first Hugo configuration file found in dir
+func readHugoConfig(dir string) (map[string]interface{}, error) {
+	for _, name := range hugoConfigFiles {
+		file := filepath.Join(dir, name)
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		config := make(map[string]interface{})
+		switch filepath.Ext(name) {
+		case ".toml":
+			tree, err := toml.LoadBytes(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+			}
+			config = tree.ToMap()
+		case ".json":
+			err = json.Unmarshal(data, &config)
+		default:
+			err = yaml.Unmarshal(data, &config)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		return util.NormalizeMap(config), nil
+	}
+	return nil, fmt.Errorf("no Hugo configuration (hugo.toml or config.toml) found in %s", dir)
+}
+
+// convertContent converts markdown content files. Page resources such as
+// images in bundles are copied to the static directory.
+func (h *hugoImport) convertContent() error {
+	src := filepath.Join(h.opts.Source, h.contentDir)
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := util.RelSlashPath(src, p)
+		if err != nil {
+			return err
+		}
+		if content.IsContentFile(rel, nil) {
+			return h.convertFile(p, rel)
+		}
+
+		target := path.Join("static", rel)
+		if err := copyFile(p, util.OutputPath(h.opts.Dest, target)); err != nil {
+			return err
+		}
+		h.report.Copied = append(h.report.Copied, target)
+		h.report.addUntranslated(path.Join(h.contentDir, rel), "page resource copied to "+target+"; link it as /"+target)
+		return nil
+	})
+}
+
+// convertFile converts one content file and records its permalink change
+func (h *hugoImport) convertFile(src, rel string) error {
+	frontMatter, delimiter, body, err := readContent(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	out := markdownName(rel)
+	file := path.Join("content", out)
+
+	var fields map[string]interface{}
+	switch delimiter {
+	case "+++":
+		tree, err := toml.Load(frontMatter)
+		if err != nil {
+			return fmt.Errorf("failed to parse front matter in %s: %w", src, err)
+		}
+		fields = util.NormalizeMap(tree.ToMap())
+		h.convertTOML(file, tree)
+		converted, err := tree.ToTomlString()
+		if err != nil {
+			return fmt.Errorf("failed to write front matter for %s: %w", src, err)
+		}
+		frontMatter = "+++\n" + converted + "+++\n"
+	case "---":
+		var data yaml.MapSlice
+		if err := yaml.Unmarshal([]byte(frontMatter), &data); err != nil {
+			return fmt.Errorf("failed to parse front matter in %s: %w", src, err)
+		}
+		yaml.Unmarshal([]byte(frontMatter), &fields)
+		util.NormalizeMap(fields)
+		converted, err := yaml.Marshal(h.convertYAML(file, data))
+		if err != nil {
+			return fmt.Errorf("failed to write front matter for %s: %w", src, err)
+		}
+		frontMatter = "---\n" + string(converted) + "---\n"
+	case "{":
+		json.Unmarshal([]byte(frontMatter), &fields)
+	}
+
+	body = h.convertShortcodes(file, body)
+	if err := h.report.writeContent(out, []byte(frontMatter+body)); err != nil {
+		return err
+	}
+
+	page, err := parsePage(h.parser, h.opts.Dest, out)
+	if err != nil {
+		return err
+	}
+	h.report.comparePermalink(file, h.oldURL(rel, fields, page), page.URL)
+	for _, alias := range stringList(fields["aliases"]) {
+		h.report.Permalinks = append(h.report.Permalinks, PermalinkChange{File: file, Old: alias, New: page.URL})
+	}
+	return nil
+}
+
+// convertTOML renames Hugo front matter keys in TOML front matter
+func (h *hugoImport) convertTOML(file string, tree *toml.Tree) {
+	// VanGo reads the date as a string
+	switch date := tree.Get("date").(type) {
+	case time.Time:
+		tree.Set("date", date.Format(time.RFC3339))
+	case toml.LocalDate, toml.LocalDateTime:
+		tree.Set("date", fmt.Sprint(date))
+	}
+	for from, to := range hugoFrontMatterKeys {
+		if tree.Has(from) {
+			tree.Set(to, frontMatterTime(tree.Get(from)))
+			tree.Delete(from)
+		}
+	}
+	if layout, ok := tree.Get("layout").(string); ok {
+		tree.Delete("layout")
+		tree.SetPath([]string{"params", "layout"}, layout)
+		h.report.addUntranslated(file, "layout: "+layout+" (create a matching VanGo template)")
+	}
+	h.checkURLFields(file, tree.Get("url"), tree.Get("slug"))
+}
+
+// convertYAML renames Hugo front matter keys in YAML front matter
+func (h *hugoImport) convertYAML(file string, data yaml.MapSlice) yaml.MapSlice {
+	var out yaml.MapSlice
+	var layout string
+	for _, item := range data {
+		key := fmt.Sprint(item.Key)
+		if to, ok := hugoFrontMatterKeys[key]; ok {
+			item = yaml.MapItem{Key: to, Value: frontMatterTime(item.Value)}
+		}
+		if key == "layout" {
+			layout = stringValue(item.Value)
+			continue
+		}
+		out = append(out, item)
+	}
+	if layout != "" {
+		params, _ := mapSliceValue(out, "params").(yaml.MapSlice)
+		params = append(params, yaml.MapItem{Key: "layout", Value: layout})
+		out = setMapSlice(out, "params", params)
+		h.report.addUntranslated(file, "layout: "+layout+" (create a matching VanGo template)")
+	}
+	h.checkURLFields(file, mapSliceValue(data, "url"), mapSliceValue(data, "slug"))
+	return out
+}
+
+// checkURLFields reports url and slug front matter, which VanGo does not
+// use: pages are published at their content path
+func (h *hugoImport) checkURLFields(file string, url, slug interface{}) {
+	if s := stringValue(url); s != "" {
+		h.report.addUntranslated(file, "url: "+s+" (VanGo publishes pages at their content path)")
+	}
+	if s := stringValue(slug); s != "" {
+		h.report.addUntranslated(file, "slug: "+s+" (rename the file to change its URL)")
+	}
+}
+
+// convertShortcodes translates highlight and figure shortcodes and marks
+// the others, apart from ref and relref, with TODO comments
+func (h *hugoImport) convertShortcodes(file, body string) string {
+	body = hugoHighlight.ReplaceAllString(body, "```$1\n$2\n```")
+	body = hugoFigure.ReplaceAllStringFunc(body, func(match string) string {
+		attrs := make(map[string]string)
+		for _, m := range hugoAttribute.FindAllStringSubmatch(match, -1) {
+			attrs[m[1]] = m[2]
+		}
+		if attrs["src"] == "" {
+			return h.report.addUntranslated(file, match)
+		}
+		alt := attrs["alt"]
+		if alt == "" {
+			alt = attrs["caption"]
+		}
+		if caption := attrs["caption"]; caption != "" {
+			return fmt.Sprintf("![%s](%s %q)", alt, attrs["src"], caption)
+		}
+		return fmt.Sprintf("![%s](%s)", alt, attrs["src"])
+	})
+	return hugoShortcode.ReplaceAllStringFunc(body, func(match string) string {
+		m := hugoShortcode.FindStringSubmatch(match)
+		if m[1] == "<" && (m[2] == "ref" || m[2] == "relref") {
+			return match
+		}
+		return h.report.addUntranslated(file, match)
+	})
+}
+
+// oldURL returns the URL Hugo published a page at: its url front matter,
+// its section's permalink pattern, or its content path, lower-cased
+func (h *hugoImport) oldURL(rel string, fields map[string]interface{}, page *content.Page) string {
+	if url := stringValue(fields["url"]); url != "" {
+		return cleanURL(url)
+	}
+
+	dir, name := path.Split(strings.TrimSuffix(rel, path.Ext(rel)))
+	dir = strings.TrimSuffix(dir, "/")
+	if name == "_index" {
+		return strings.ToLower(cleanURL("/" + dir + "/"))
+	}
+	if name == "index" && dir != "" {
+		dir, name = path.Split(dir)
+		dir = strings.TrimSuffix(dir, "/")
+	}
+	slug := stringValue(fields["slug"])
+	if slug == "" {
+		slug = name
+	}
+
+	section := util.FirstSegment(dir)
+	pattern, ok := h.permalinks[section]
+	if !ok {
+		return strings.ToLower(cleanURL("/" + dir + "/" + slug + "/"))
+	}
+	date := page.ParsedDate
+	replacer := strings.NewReplacer(
+		":year", date.Format("2006"),
+		":month", date.Format("01"),
+		":monthname", strings.ToLower(date.Format("January")),
+		":day", date.Format("02"),
+		":weekdayname", strings.ToLower(date.Format("Monday")),
+		":yearday", fmt.Sprint(date.YearDay()),
+		":sections", dir,
+		":section", section,
+		":title", util.Slugify(page.Title),
+		":slugorfilename", slug,
+		":slug", slug,
+		":filename", name,
+		":contentbasename", name,
+	)
+	return strings.ToLower(cleanURL(replacer.Replace(pattern)))
+}
+
+// copyStatic copies Hugo's static directory, which is published at the
+// site root; VanGo publishes it below /static/
+func (h *hugoImport) copyStatic() error {
+	src := filepath.Join(h.opts.Source, "static")
+	if err := h.report.copyTree(src, filepath.Join(h.opts.Dest, "static"), nil); err != nil {
+		return err
+	}
+	entries, _ := os.ReadDir(src)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			h.report.comparePermalink("static/"+name+"/", "/"+name+"/*", "/static/"+name+"/:splat")
+		} else {
+			h.report.comparePermalink("static/"+name, "/"+name, "/static/"+name)
+		}
+	}
+	return nil
+}
+
+// writeConfig generates config.toml from Hugo's configuration
+func (h *hugoImport) writeConfig() error {
+	params, _ := h.config["params"].(map[string]interface{})
+	language := stringValue(h.config["defaultContentLanguage"])
+	if language == "" {
+		language = stringValue(h.config["languageCode"])
+	}
+	author := authorName(h.config["author"])
+	if author == "" {
+		author = authorName(params["author"])
+	}
+	settings := []setting{
+		{"theme", importedTheme},
+		{"title", stringValue(h.config["title"])},
+		{"baseURL", stringValue(h.config["baseURL"])},
+		{"language", language},
+		{"description", stringValue(params["description"])},
+		{"author", author},
+		{"timeZone", stringValue(h.config["timeZone"])},
+		{"buildDrafts", h.config["buildDrafts"] == true},
+		{"buildFuture", h.config["buildFuture"] == true},
+		{"buildExpired", h.config["buildExpired"] == true},
+	}
+
+	var taxonomies map[string]string
+	if t, ok := h.config["taxonomies"].(map[string]interface{}); ok {
+		taxonomies = make(map[string]string, len(t))
+		for singular, plural := range t {
+			taxonomies[singular] = stringValue(plural)
+		}
+	}
+	if theme := stringValue(h.config["theme"]); theme != "" {
+		h.report.addUntranslated("config", "theme: "+theme+" (Hugo themes must be ported)")
+	}
+	if _, ok := h.config["languages"]; ok {
+		h.report.addUntranslated("config", "languages (configure VanGo's [languages] by hand)")
+	}
+	if _, ok := h.config["menus"]; ok {
+		h.report.addUntranslated("config", "menus (add them to VanGo's configuration by hand)")
+	}
+	return writeConfig(h.report, settings, taxonomies, params)
+}
+
+// frontMatterTime parses a date written as a string, which Hugo accepts
+// for publishDate and friends but VanGo decodes as a timestamp
+func frontMatterTime(value interface{}) interface{} {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case toml.LocalDate, toml.LocalDateTime:
+		s = fmt.Sprint(v)
+	default:
+		return value
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return value
+}
+
+// setMapSlice sets key in ordered YAML data, appending it when missing
+func setMapSlice(data yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i, item := range data {
+		if fmt.Sprint(item.Key) == key {
+			data[i].Value = value
+			return data
+		}
+	}
+	return append(data, yaml.MapItem{Key: key, Value: value})
+}
@@ -0,0 +1,261 @@
+// Package importer migrates Jekyll and Hugo sites to VanGo: it converts
+// content and front matter, copies static assets, generates config.toml and
+// reports what could not be carried over.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"vango/internal/config"
+	"vango/internal/content"
+	"vango/internal/theme"
+	"vango/internal/util"
+)
+
+// Options configures an import
+type Options struct {
+	Source string // Site to import
+	Dest   string // Directory the VanGo site is written to
+	Force  bool   // Write into a non-empty content directory
+}
+
+// Report lists what an import did and what needs attention by hand
+type Report struct {
+	Kind         string // "jekyll" or "hugo"
+	Source       string
+	Dest         string
+	Config       string            // Generated configuration file
+	Converted    []string          // Content files written, relative to Dest
+	Copied       []string          // Other files copied, relative to Dest
+	Untranslated []Untranslated    // Constructs left as TODO comments
+	Permalinks   []PermalinkChange // Pages whose URL changed
+}
+
+// Untranslated is a construct the importer could not convert
+type Untranslated struct {
+	File      string
+	Construct string
+}
+
+// PermalinkChange records a page served at a different URL after the import
+type PermalinkChange struct {
+	File string
+	Old  string
+	New  string
+}
+
+// Import migrates the site at opts.Source, which is a Jekyll or Hugo site
+// depending on kind
+func Import(kind string, opts Options) (*Report, error) {
+	if info, err := os.Stat(opts.Source); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("source %s is not a directory", opts.Source)
+	}
+	contentDir := filepath.Join(opts.Dest, "content")
+	if !opts.Force {
+		if entries, err := os.ReadDir(contentDir); err == nil && len(entries) > 0 {
+			return nil, fmt.Errorf("%s is not empty (use --force to import anyway)", contentDir)
+		}
+		if _, err := os.Stat(filepath.Join(opts.Dest, "config.toml")); err == nil {
+			return nil, fmt.Errorf("%s already has a config.toml (use --force to overwrite it)", opts.Dest)
+		}
+	}
+
+	report := &Report{Kind: kind, Source: opts.Source, Dest: opts.Dest}
+	var err error
+	switch kind {
+	case "jekyll":
+		err = importJekyll(opts, report)
+	case "hugo":
+		err = importHugo(opts, report)
+	default:
+		err = fmt.Errorf("unknown site kind %q (use jekyll or hugo)", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := createTheme(opts.Dest); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(report.Converted)
+	sort.Strings(report.Copied)
+	sort.Slice(report.Permalinks, func(i, j int) bool { return report.Permalinks[i].File < report.Permalinks[j].File })
+	return report, nil
+}
+
+// importedTheme is the theme generated for imported sites, which renders
+// them until their own templates are ported
+const importedTheme = "imported"
+
+// createTheme creates the imported theme and the layouts directory VanGo
+// requires, keeping an imported theme left by an earlier run
+func createTheme(dest string) error {
+	if err := os.MkdirAll(filepath.Join(dest, "layouts"), 0755); err != nil {
+		return err
+	}
+	themesDir := filepath.Join(dest, "themes")
+	if _, err := os.Stat(filepath.Join(themesDir, importedTheme)); err == nil {
+		return nil
+	}
+	cfg := &config.Config{Params: map[string]interface{}{"themes_dir": themesDir}}
+	return theme.NewThemeManager(cfg).CreateTheme(importedTheme, "blog")
+}
+
+// addUntranslated records a construct and returns the TODO comment left in
+// its place in the content
+func (r *Report) addUntranslated(file, construct string) string {
+	r.Untranslated = append(r.Untranslated, Untranslated{File: file, Construct: construct})
+	return fmt.Sprintf("<!-- TODO(import): %s -->", strings.ReplaceAll(construct, "--", "- -"))
+}
+
+// writeContent writes a converted content file below the destination's
+// content directory and records it
+func (r *Report) writeContent(rel string, data []byte) error {
+	rel = path.Join("content", util.SlashPath(rel))
+	if err := writeFile(util.OutputPath(r.Dest, rel), data); err != nil {
+		return err
+	}
+	r.Converted = append(r.Converted, rel)
+	return nil
+}
+
+// parsePage parses a converted content file with the site's parser settings,
+// giving the slug and URL VanGo publishes it at
+func parsePage(parser *content.Parser, dest, rel string) (*content.Page, error) {
+	contentDir := filepath.Join(dest, "content")
+	return parser.ParseFile(util.OutputPath(contentDir, rel), contentDir)
+}
+
+// comparePermalink records a page whose old URL differs from its new one.
+// Jekyll and Hugo serve "/about/" and "/about/index.html" alike.
+func (r *Report) comparePermalink(file, old, new string) {
+	if strings.TrimSuffix(old, "index.html") == new {
+		return
+	}
+	r.Permalinks = append(r.Permalinks, PermalinkChange{File: file, Old: old, New: new})
+}
+
+// copyTree copies every file below src into dst, skipping files skip
+// reports true for, and records them relative to the destination site
+func (r *Report) copyTree(src, dst string, skip func(rel string, info os.FileInfo) bool) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := util.RelSlashPath(src, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		if skip != nil && skip(rel, info) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		target := util.OutputPath(dst, rel)
+		if err := copyFile(p, target); err != nil {
+			return err
+		}
+		if recorded, err := util.RelSlashPath(r.Dest, target); err == nil {
+			r.Copied = append(r.Copied, recorded)
+		}
+		return nil
+	})
+}
+
+// WriteMarkdown writes the report as a markdown document
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s import report\n\n", strings.Title(r.Kind))
+	fmt.Fprintf(&b, "Imported %s into %s.\n\n", r.Source, r.Dest)
+	if r.Config != "" {
+		fmt.Fprintf(&b, "Generated %s; review it before building.\n\n", r.Config)
+	}
+
+	fmt.Fprintf(&b, "## Converted content (%d)\n\n", len(r.Converted))
+	for _, file := range r.Converted {
+		fmt.Fprintf(&b, "- %s\n", file)
+	}
+
+	fmt.Fprintf(&b, "\n## Copied files (%d)\n\n", len(r.Copied))
+	for _, file := range r.Copied {
+		fmt.Fprintf(&b, "- %s\n", file)
+	}
+
+	fmt.Fprintf(&b, "\n## Untranslated constructs (%d)\n\n", len(r.Untranslated))
+	if len(r.Untranslated) > 0 {
+		b.WriteString("Each is marked with a `TODO(import)` comment in the content.\n\n")
+	}
+	for _, u := range r.Untranslated {
+		fmt.Fprintf(&b, "- %s: `%s`\n", u.File, u.Construct)
+	}
+
+	fmt.Fprintf(&b, "\n## Changed permalinks (%d)\n\n", len(r.Permalinks))
+	if len(r.Permalinks) > 0 {
+		b.WriteString("| File | Old URL | New URL |\n|------|---------|---------|\n")
+		for _, p := range r.Permalinks {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", p.File, p.Old, p.New)
+		}
+		b.WriteString("\nAdd these to config.toml to keep the old URLs working:\n\n```toml\n")
+		for _, p := range r.Permalinks {
+			fmt.Fprintf(&b, "[[redirects]]\nfrom = %q\nto = %q\nstatus = 301\n\n", p.Old, p.New)
+		}
+		b.WriteString("```\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeFile writes data to name, creating its directory
+func writeFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", name, err)
+	}
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// copyFile copies src to dst, creating dst's directory
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return writeFile(dst, data)
+}
+
+// readContent splits a content file into front matter and body
+func readContent(name string) (frontMatter, delimiter, body string, err error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", "", "", err
+	}
+	defer file.Close()
+	return content.SplitFrontMatter(file)
+}
+
+// cleanURL joins URL parts, collapsing duplicate slashes but keeping a
+// trailing one
+func cleanURL(url string) string {
+	trailing := strings.HasSuffix(url, "/")
+	url = path.Clean("/" + url)
+	if trailing && url != "/" {
+		url += "/"
+	}
+	return url
+}
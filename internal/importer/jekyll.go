@@ -0,0 +1,470 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"vango/internal/content"
+	"vango/internal/util"
+)
+
+// jekyllPermalinkStyles are Jekyll's built-in permalink styles
+var jekyllPermalinkStyles = map[string]string{
+	"date":    "/:categories/:year/:month/:day/:title:output_ext",
+	"pretty":  "/:categories/:year/:month/:day/:title/",
+	"ordinal": "/:categories/:year/:y_day/:title:output_ext",
+	"none":    "/:categories/:title:output_ext",
+}
+
+// jekyllLayouts are the layouts VanGo's default templates stand in for
+var jekyllLayouts = map[string]bool{"default": true, "home": true, "page": true, "post": true}
+
+// jekyllDateLayouts are the date formats Jekyll accepts in front matter
+var jekyllDateLayouts = []string{
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// jekyllIgnored are files Jekyll never publishes
+var jekyllIgnored = map[string]bool{
+	"Gemfile": true, "Gemfile.lock": true, "node_modules": true, "vendor": true,
+	"package.json": true, "package-lock.json": true,
+}
+
+var (
+	liquidRaw       = regexp.MustCompile(`(?s)\{%-?\s*raw\s*-?%\}(.*?)\{%-?\s*endraw\s*-?%\}`)
+	liquidHighlight = regexp.MustCompile(`(?s)\{%-?\s*highlight\s+(\w[\w+-]*)[^%]*-?%\}\n?(.*?)\n?\{%-?\s*endhighlight\s*-?%\}`)
+	liquidPostURL   = regexp.MustCompile(`\{%-?\s*post_url\s+(\S+)\s*-?%\}`)
+	liquidLink      = regexp.MustCompile(`\{%-?\s*link\s+(\S+)\s*-?%\}`)
+	liquidURLFilter = regexp.MustCompile(`\{\{-?\s*["']([^"']*)["']\s*\|\s*(?:relative_url|absolute_url)\s*-?\}\}`)
+	liquidBaseURL   = regexp.MustCompile(`\{\{-?\s*site\.(?:url|baseurl)\s*-?\}\}`)
+	liquidTag       = regexp.MustCompile(`\{%-?.*?-?%\}`)
+	liquidOutput    = regexp.MustCompile(`\{\{[^<%].*?\}\}`)
+)
+
+// jekyllImport holds the state of a Jekyll import
+type jekyllImport struct {
+	opts      Options
+	report    *Report
+	config    map[string]interface{}
+	permalink string
+	exclude   map[string]bool
+	parser    *content.Parser
+}
+
+// importJekyll converts posts, drafts, collections and pages, copies the
+// remaining files as static assets and generates config.toml from
+// _config.yml
+func importJekyll(opts Options, report *Report) error {
+	config, err := readYAMLFile(filepath.Join(opts.Source, "_config.yml"))
+	if err != nil {
+		return err
+	}
+
+	j := &jekyllImport{
+		opts:      opts,
+		report:    report,
+		config:    config,
+		permalink: stringValue(config["permalink"]),
+		exclude:   make(map[string]bool),
+		parser:    content.NewParser(),
+	}
+	if j.permalink == "" {
+		j.permalink = "date"
+	}
+	if style, ok := jekyllPermalinkStyles[j.permalink]; ok {
+		j.permalink = style
+	}
+	for _, name := range stringList(config["exclude"]) {
+		j.exclude[strings.Trim(name, "/")] = true
+	}
+	j.parser.SetDateFromFilename(true, false)
+	if zone := stringValue(config["timezone"]); zone != "" {
+		if loc, err := time.LoadLocation(zone); err == nil {
+			j.parser.SetLocation(loc)
+		}
+	}
+
+	if err := j.convertDir("_posts", "posts", true, false); err != nil {
+		return err
+	}
+	if err := j.convertDir("_drafts", "posts", true, true); err != nil {
+		return err
+	}
+	for _, collection := range j.collections() {
+		if err := j.convertDir("_"+collection, collection, false, false); err != nil {
+			return err
+		}
+	}
+	if err := j.convertPages(); err != nil {
+		return err
+	}
+	return j.writeConfig()
+}
+
+// collections returns the names of the collections declared in _config.yml,
+// which may be a list or a map
+func (j *jekyllImport) collections() []string {
+	switch c := j.config["collections"].(type) {
+	case []interface{}:
+		return stringList(c)
+	case map[string]interface{}:
+		names := make([]string, 0, len(c))
+		for name := range c {
+			if name != "posts" {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// convertDir converts the documents in a Jekyll directory such as _posts
+// into a content section
+func (j *jekyllImport) convertDir(dir, section string, post, draft bool) error {
+	src := filepath.Join(j.opts.Source, dir)
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !isJekyllDocument(p) {
+			return err
+		}
+		rel, err := util.RelSlashPath(src, p)
+		if err != nil {
+			return err
+		}
+		return j.convertFile(p, path.Join(section, markdownName(rel)), post, draft)
+	})
+}
+
+// convertPages converts pages outside the underscore directories. Files
+// without front matter are not processed by Jekyll and are copied as
+// static assets instead.
+func (j *jekyllImport) convertPages() error {
+	staticDir := filepath.Join(j.opts.Dest, "static")
+	var assetDirs []string
+	err := filepath.Walk(j.opts.Source, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := util.RelSlashPath(j.opts.Source, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		if j.skip(rel, info) || p == filepath.Clean(j.opts.Dest) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			if !strings.Contains(rel, "/") {
+				assetDirs = append(assetDirs, rel)
+			}
+			return nil
+		}
+
+		if isJekyllDocument(p) {
+			if _, delimiter, _, err := readContent(p); err == nil && delimiter == "---" {
+				return j.convertFile(p, markdownName(rel), false, false)
+			}
+		}
+		target := util.OutputPath(staticDir, rel)
+		if err := copyFile(p, target); err != nil {
+			return err
+		}
+		j.report.Copied = append(j.report.Copied, path.Join("static", rel))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Static files are published below /static/
+	for _, dir := range assetDirs {
+		if _, err := os.Stat(filepath.Join(staticDir, dir)); err == nil {
+			j.report.comparePermalink("static/"+dir+"/", "/"+dir+"/*", "/static/"+dir+"/:splat")
+		}
+	}
+	return nil
+}
+
+// skip reports whether a path below the source is left out of the import:
+// underscore and dot files, the generated site, configuration and excluded
+// paths
+func (j *jekyllImport) skip(rel string, info os.FileInfo) bool {
+	name := info.Name()
+	if strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") || jekyllIgnored[name] {
+		return true
+	}
+	if strings.HasSuffix(name, ".gemspec") || j.exclude[rel] || j.exclude[name] {
+		return true
+	}
+	return false
+}
+
+// convertFile converts one Jekyll document and records its permalink change
+func (j *jekyllImport) convertFile(src, rel string, post, draft bool) error {
+	frontMatter, _, body, err := readContent(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	var data yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(frontMatter), &data); err != nil {
+		return fmt.Errorf("failed to parse front matter in %s: %w", src, err)
+	}
+
+	file := path.Join("content", rel)
+	permalink := stringValue(mapSliceValue(data, "permalink"))
+	data = j.convertFrontMatter(file, data, draft)
+	body = j.convertLiquid(file, body)
+
+	out, err := yaml.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to write front matter for %s: %w", src, err)
+	}
+	if err := j.report.writeContent(rel, []byte("---\n"+string(out)+"---\n"+body)); err != nil {
+		return err
+	}
+
+	page, err := parsePage(j.parser, j.opts.Dest, rel)
+	if err != nil {
+		return err
+	}
+	if !draft {
+		j.report.comparePermalink(file, j.oldURL(rel, permalink, post, page), page.URL)
+	}
+	return nil
+}
+
+// convertFrontMatter maps Jekyll front matter fields to VanGo's
+func (j *jekyllImport) convertFrontMatter(file string, data yaml.MapSlice, draft bool) yaml.MapSlice {
+	var out yaml.MapSlice
+	var params yaml.MapSlice
+	hasDescription := mapSliceValue(data, "description") != nil
+	for _, item := range data {
+		key := fmt.Sprint(item.Key)
+		switch key {
+		case "permalink":
+			continue
+		case "layout":
+			if layout := stringValue(item.Value); layout != "" && !jekyllLayouts[layout] {
+				params = append(params, yaml.MapItem{Key: "layout", Value: layout})
+				j.report.addUntranslated(file, fmt.Sprintf("layout: %s (port _layouts/%s.html to layouts/%s.html)", layout, layout, layout))
+			}
+			continue
+		case "category", "categories":
+			item = yaml.MapItem{Key: "categories", Value: fieldsList(item.Value)}
+		case "tag", "tags":
+			item = yaml.MapItem{Key: "tags", Value: fieldsList(item.Value)}
+		case "published":
+			if published, ok := item.Value.(bool); ok && !published {
+				draft = true
+			}
+			continue
+		case "date":
+			item.Value = jekyllDate(item.Value)
+		case "excerpt":
+			if !hasDescription {
+				item.Key = "description"
+			}
+		}
+		out = append(out, item)
+	}
+	if draft {
+		out = append(out, yaml.MapItem{Key: "draft", Value: true})
+	}
+	if len(params) > 0 {
+		out = append(out, yaml.MapItem{Key: "params", Value: params})
+	}
+	return out
+}
+
+// convertLiquid translates the Liquid tags VanGo has an equivalent for and
+// replaces the rest with TODO comments. Text in {% raw %} blocks is kept.
+func (j *jekyllImport) convertLiquid(file, body string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range liquidRaw.FindAllStringSubmatchIndex(body, -1) {
+		b.WriteString(j.convertLiquidText(file, body[last:m[0]]))
+		b.WriteString(body[m[2]:m[3]])
+		last = m[1]
+	}
+	b.WriteString(j.convertLiquidText(file, body[last:]))
+	return b.String()
+}
+
+func (j *jekyllImport) convertLiquidText(file, text string) string {
+	text = liquidHighlight.ReplaceAllString(text, "```$1\n$2\n```")
+	text = liquidPostURL.ReplaceAllStringFunc(text, func(match string) string {
+		name := liquidPostURL.FindStringSubmatch(match)[1]
+		return fmt.Sprintf(`{{< relref "/posts/%s.md" >}}`, name)
+	})
+	text = liquidLink.ReplaceAllStringFunc(text, func(match string) string {
+		target := strings.TrimPrefix(liquidLink.FindStringSubmatch(match)[1], "/")
+		if strings.HasPrefix(target, "_posts/") {
+			target = "posts/" + strings.TrimPrefix(target, "_posts/")
+		}
+		return fmt.Sprintf(`{{< relref "/%s" >}}`, markdownName(target))
+	})
+	text = liquidURLFilter.ReplaceAllString(text, "$1")
+	text = liquidBaseURL.ReplaceAllString(text, "")
+	text = liquidTag.ReplaceAllStringFunc(text, func(match string) string {
+		return j.report.addUntranslated(file, match)
+	})
+	return liquidOutput.ReplaceAllStringFunc(text, func(match string) string {
+		return j.report.addUntranslated(file, match)
+	})
+}
+
+// oldURL returns the URL Jekyll published a document at
+func (j *jekyllImport) oldURL(rel, permalink string, post bool, page *content.Page) string {
+	// The parser has already dropped a post's date prefix from its slug
+	name := path.Base(page.Slug)
+	if slug, ok := page.FrontMatter["slug"].(string); ok && slug != "" {
+		name = slug
+	}
+
+	pattern := permalink
+	if pattern == "" && post {
+		pattern = j.permalink
+	}
+	if pattern == "" {
+		// Pages keep their path, with the site's trailing slash style
+		dir := path.Dir(rel)
+		if name == "index" {
+			return cleanURL("/" + dir + "/")
+		}
+		if strings.HasSuffix(j.permalink, "/") {
+			return cleanURL("/" + dir + "/" + name + "/")
+		}
+		return cleanURL("/" + dir + "/" + name + ".html")
+	}
+
+	var cats []string
+	for _, c := range page.Categories {
+		cats = append(cats, util.Slugify(c))
+	}
+	date := page.ParsedDate
+	replacer := strings.NewReplacer(
+		":categories", strings.Join(cats, "/"),
+		":year", date.Format("2006"),
+		":short_year", date.Format("06"),
+		":i_month", fmt.Sprint(int(date.Month())),
+		":month", date.Format("01"),
+		":i_day", fmt.Sprint(date.Day()),
+		":day", date.Format("02"),
+		":y_day", fmt.Sprintf("%03d", date.YearDay()),
+		":title", name,
+		":slug", name,
+		":path", strings.TrimSuffix(rel, path.Ext(rel)),
+		":basename", name,
+		":output_ext", ".html",
+	)
+	return cleanURL(replacer.Replace(pattern))
+}
+
+// writeConfig generates config.toml from _config.yml
+func (j *jekyllImport) writeConfig() error {
+	baseURL := strings.TrimSuffix(stringValue(j.config["url"]), "/") + stringValue(j.config["baseurl"])
+	if baseURL == "" {
+		baseURL = "http://localhost:1313/"
+	}
+	settings := []setting{
+		{"theme", importedTheme},
+		{"title", stringValue(j.config["title"])},
+		{"baseURL", baseURL},
+		{"language", stringValue(j.config["lang"])},
+		{"description", stringValue(j.config["description"])},
+		{"author", authorName(j.config["author"])},
+		{"timeZone", stringValue(j.config["timezone"])},
+		{"dateFromFilename", true},
+	}
+
+	known := map[string]bool{
+		"title": true, "url": true, "baseurl": true, "lang": true, "description": true,
+		"author": true, "timezone": true, "permalink": true, "exclude": true, "include": true,
+		"collections": true, "plugins": true, "gems": true, "theme": true, "markdown": true,
+		"highlighter": true, "kramdown": true, "sass": true, "defaults": true,
+	}
+	params := make(map[string]interface{})
+	for key, value := range j.config {
+		if !known[key] {
+			params[key] = value
+		}
+	}
+	if theme := stringValue(j.config["theme"]); theme != "" {
+		j.report.addUntranslated("_config.yml", "theme: "+theme+" (Jekyll themes must be ported)")
+	}
+	return writeConfig(j.report, settings, nil, params)
+}
+
+// isJekyllDocument reports whether a file is content Jekyll renders
+func isJekyllDocument(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".mkd", ".html":
+		return true
+	}
+	return content.IsContentFile(name, nil)
+}
+
+// markdownName gives a document path the .md extension VanGo reads
+func markdownName(rel string) string {
+	return strings.TrimSuffix(rel, path.Ext(rel)) + ".md"
+}
+
+// jekyllDate rewrites a Jekyll front matter date in a format VanGo reads
+func jekyllDate(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case string:
+		for _, layout := range jekyllDateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				if strings.Contains(layout, "-07") {
+					return t.Format(time.RFC3339)
+				}
+				return t.Format("2006-01-02T15:04:05")
+			}
+		}
+	}
+	return value
+}
+
+// fieldsList turns Jekyll's space-separated category or tag strings into a list
+func fieldsList(value interface{}) []string {
+	if s, ok := value.(string); ok {
+		return strings.Fields(s)
+	}
+	return stringList(value)
+}
+
+// authorName returns the author's name, which may be a string or a map
+func authorName(value interface{}) string {
+	if m, ok := util.NormalizeValue(value).(map[string]interface{}); ok {
+		return stringValue(m["name"])
+	}
+	return stringValue(value)
+}
+
+// mapSliceValue returns the value of key in ordered YAML data
+func mapSliceValue(data yaml.MapSlice, key string) interface{} {
+	for _, item := range data {
+		if fmt.Sprint(item.Key) == key {
+			return item.Value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+// Package logging routes diagnostic output from the builder, server, theme
+// manager and CLI through a shared slog logger. Interactive terminals get the
+// familiar emoji lines; other outputs get timestamped text or JSON records.
+// Diagnostics always go to stderr so stdout stays free for data output.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Formats accepted by Setup
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options configures the shared logger
+type Options struct {
+	Level   string // debug, info, warn or error; empty means info
+	Format  string // text or json; empty means text
+	Quiet   bool   // Only log errors
+	Verbose bool   // Log at debug level
+	Output  io.Writer
+}
+
+var (
+	level  = new(slog.LevelVar)
+	logger = slog.New(newConsoleHandler(os.Stderr, level))
+
+	// consoleMu serializes console records and the status line, so lines
+	// logged from worker goroutines never break into the status line
+	consoleMu sync.Mutex
+	// statusOut is the terminal the status line is drawn on, nil when the
+	// logger doesn't write plain lines to one
+	statusOut io.Writer
+	// status is the status line currently shown
+	status string
+)
+
+// Setup replaces the shared logger. Quiet wins over Verbose, and both win
+// over Level. Text output on an interactive terminal prints plain messages;
+// otherwise each record carries a timestamp and level.
+func Setup(opts Options) error {
+	lvl, err := ParseLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+	if opts.Verbose {
+		lvl = slog.LevelDebug
+	}
+	if opts.Quiet {
+		lvl = slog.LevelError
+	}
+	level.Set(lvl)
+
+	out := opts.Output
+	if out == nil {
+		out = os.Stderr
+	}
+
+	var terminal io.Writer
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(opts.Format) {
+	case "", FormatText:
+		if isTerminal(out) {
+			logger = slog.New(newConsoleHandler(out, level))
+			terminal = out
+		} else {
+			logger = slog.New(slog.NewTextHandler(out, handlerOpts))
+		}
+	case FormatJSON:
+		logger = slog.New(slog.NewJSONHandler(out, handlerOpts))
+	default:
+		return fmt.Errorf("unknown log format %q (use %s or %s)", opts.Format, FormatText, FormatJSON)
+	}
+
+	consoleMu.Lock()
+	statusOut, status = terminal, ""
+	consoleMu.Unlock()
+	return nil
+}
+
+// ParseLevel parses a level name; empty means info
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", name)
+}
+
+// Logger returns the shared logger
+func Logger() *slog.Logger {
+	return logger
+}
+
+// Enabled reports whether records at lvl are logged
+func Enabled(lvl slog.Level) bool {
+	return lvl >= level.Level()
+}
+
+// Debugf logs a formatted message at debug level
+func Debugf(format string, args ...interface{}) {
+	logf(slog.LevelDebug, format, args...)
+}
+
+// Infof logs a formatted message at info level
+func Infof(format string, args ...interface{}) {
+	logf(slog.LevelInfo, format, args...)
+}
+
+// Warnf logs a formatted message at warn level
+func Warnf(format string, args ...interface{}) {
+	logf(slog.LevelWarn, format, args...)
+}
+
+// Errorf logs a formatted message at error level
+func Errorf(format string, args ...interface{}) {
+	logf(slog.LevelError, format, args...)
+}
+
+func logf(lvl slog.Level, format string, args ...interface{}) {
+	if !Enabled(lvl) {
+		return
+	}
+	logger.Log(context.Background(), lvl, strings.TrimRight(fmt.Sprintf(format, args...), "\n"))
+}
+
+// StatusEnabled reports whether SetStatus shows anything: the logger prints
+// plain lines to an interactive terminal
+func StatusEnabled() bool {
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	return statusOut != nil
+}
+
+// SetStatus shows line below the logged messages, replacing the previous
+// status line, which is redrawn after every record. An empty line clears it.
+func SetStatus(line string) {
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	if statusOut == nil || line == status {
+		return
+	}
+	io.WriteString(statusOut, clearLine+line)
+	status = line
+}
+
+// clearLine returns the cursor to the start of the line and erases it
+const clearLine = "\r\033[K"
+
+// isTerminal reports whether w is an interactive terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// consoleHandler prints each record's message followed by its attributes,
+// with no timestamp or level, for people watching a terminal
+type consoleHandler struct {
+	out   io.Writer
+	level slog.Leveler
+	attrs []slog.Attr
+}
+
+func newConsoleHandler(out io.Writer, level slog.Leveler) *consoleHandler {
+	return &consoleHandler{out: out, level: level}
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return lvl >= h.level.Level()
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(r.Message)
+	write := func(a slog.Attr) bool {
+		fmt.Fprintf(&b, " %s=%v", a.Key, a.Value)
+		return true
+	}
+	for _, a := range h.attrs {
+		write(a)
+	}
+	r.Attrs(write)
+	b.WriteByte('\n')
+
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	if status == "" || statusOut != h.out {
+		_, err := io.WriteString(h.out, b.String())
+		return err
+	}
+	// Write the record over the status line, then draw it again below
+	_, err := io.WriteString(h.out, clearLine+b.String()+status)
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &clone
+}
+
+func (h *consoleHandler) WithGroup(string) slog.Handler {
+	return h
+}
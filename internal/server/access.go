@@ -0,0 +1,42 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// localOnly restricts the admin panel and /dev tools to browsers on this
+// machine. The server may listen on every interface for testing on other
+// devices, and the tools expose the site's files and configuration. The
+// Host header must name this machine too, so a page elsewhere can't reach
+// them through DNS rebinding.
+func localOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopback(r.RemoteAddr) || !isLocalHost(r.Host) {
+			http.Error(w, "Forbidden: development tools are only available from localhost", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopback reports whether a remote address is on this machine
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isLocalHost reports whether a Host header names this machine
+func isLocalHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.Trim(host, "[]")
+	return strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") || isLoopback(host)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// gzipMiddleware compresses responses for clients that accept gzip, so the
+// development server transfers pages the way a production server would
+func gzipMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// WebSocket upgrades and range requests must pass through untouched
+		if r.Header.Get("Upgrade") != "" || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w, head: r.Method == http.MethodHead}
+		defer gw.Close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// isCompressibleType reports whether responses of a content type are worth
+// compressing. Event streams are excluded so each event is delivered as
+// soon as it is flushed.
+func isCompressibleType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/javascript", "application/xml", "application/wasm", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter compresses the response body once the status and
+// content type show it is compressible
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	head        bool
+	wroteHeader bool
+}
+
+func (gw *gzipResponseWriter) WriteHeader(code int) {
+	if gw.wroteHeader {
+		return
+	}
+	gw.wroteHeader = true
+
+	h := gw.Header()
+	compressible := code >= 200 && code != http.StatusNoContent && code != http.StatusNotModified &&
+		code != http.StatusPartialContent && h.Get("Content-Encoding") == "" &&
+		isCompressibleType(h.Get("Content-Type"))
+	if compressible {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		if !gw.head {
+			gw.gz = gzip.NewWriter(gw.ResponseWriter)
+		}
+	}
+	gw.ResponseWriter.WriteHeader(code)
+}
+
+func (gw *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !gw.wroteHeader {
+		if gw.Header().Get("Content-Type") == "" {
+			gw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		gw.WriteHeader(http.StatusOK)
+	}
+	if gw.gz != nil {
+		return gw.gz.Write(b)
+	}
+	return gw.ResponseWriter.Write(b)
+}
+
+// Flush writes out compressed data buffered so far, then flushes the
+// underlying writer
+func (gw *gzipResponseWriter) Flush() {
+	if gw.gz != nil {
+		gw.gz.Flush()
+	}
+	if flusher, ok := gw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close finishes the compressed stream
+func (gw *gzipResponseWriter) Close() error {
+	if gw.gz != nil {
+		return gw.gz.Close()
+	}
+	return nil
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (gw *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return gw.ResponseWriter
+}
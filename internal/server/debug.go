@@ -0,0 +1,68 @@
+package server
+
+import (
+	"fmt"
+	"io"
+
+	tmpl "vango/internal/template"
+)
+
+// templateDebugReport is the response of /dev/template-debug
+type templateDebugReport struct {
+	Path       string                   `json:"path,omitempty"`
+	File       string                   `json:"file,omitempty"`
+	Resolution *tmpl.TemplateResolution `json:"resolution,omitempty"`
+	DataKeys   []string                 `json:"data_keys,omitempty"`
+	Templates  []tmpl.TemplateSource    `json:"templates"`
+}
+
+// writeText renders the report as plain text
+func (rep *templateDebugReport) writeText(w io.Writer) {
+	if rep.Resolution != nil {
+		fmt.Fprintf(w, "Page:     %s\n", rep.Path)
+		fmt.Fprintf(w, "Content:  %s\n", rep.File)
+		fmt.Fprintf(w, "Template: %s\n", rep.Resolution.Template)
+		if rep.Resolution.Blocks != "" {
+			fmt.Fprintf(w, "Blocks:   %s\n", rep.Resolution.Blocks)
+		}
+		fmt.Fprintln(w)
+
+		fmt.Fprintln(w, "Lookup chain:")
+		for i, c := range rep.Resolution.Chain {
+			status := "not found"
+			if c.Found {
+				status = fmt.Sprintf("found in %s (%s)", c.Source, c.Origin)
+			}
+			marker := " "
+			if c.Found && c.Name == rep.Resolution.Template {
+				marker = "→"
+			}
+			fmt.Fprintf(w, " %s %d. %-16s %-24s %s\n", marker, i+1, c.Step, c.Name, status)
+		}
+
+		fmt.Fprintln(w, "\nTemplate files used:")
+		for _, file := range rep.Resolution.Files {
+			fmt.Fprintf(w, "   %s\n", file)
+		}
+
+		fmt.Fprintln(w, "\nTemplate data:")
+		for _, key := range rep.DataKeys {
+			fmt.Fprintf(w, "   %s\n", key)
+		}
+		fmt.Fprintln(w)
+	} else {
+		fmt.Fprintln(w, "Add ?path=/some/page/ to see how that page's template is resolved.")
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "Loaded templates (%d):\n", len(rep.Templates))
+	for _, t := range rep.Templates {
+		source := t.Source
+		if source == "" {
+			source = "(built in)"
+		} else {
+			source = fmt.Sprintf("%s (%s)", t.Source, t.Origin)
+		}
+		fmt.Fprintf(w, "   %-32s %s\n", t.Name, source)
+	}
+}
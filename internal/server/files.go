@@ -0,0 +1,331 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxChangedShown bounds the changed files listed above a /dev/files listing
+const maxChangedShown = 50
+
+// errOutsidePublic is returned for paths that resolve outside the public
+// directory
+var errOutsidePublic = errors.New("path is outside the public directory")
+
+// fileEntry is a file or directory in a /dev/files listing
+type fileEntry struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"` // Relative to the public directory
+	Dir     bool      `json:"dir"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Changed bool      `json:"changed"` // Changed by the last rebuild, or holds files that were
+}
+
+// fileCrumb is a breadcrumb link up the directory tree
+type fileCrumb struct {
+	Name string
+	Href string
+}
+
+// fileListing is a /dev/files directory page
+type fileListing struct {
+	Path    string      `json:"path"`
+	Entries []fileEntry `json:"entries"`
+	Changed []string    `json:"changed"` // Every file the last rebuild changed
+	Ext     string      `json:"ext,omitempty"`
+
+	Crumbs     []fileCrumb `json:"-"`
+	Extensions []string    `json:"-"`
+	Root       string      `json:"-"` // Relative link to /dev/files/
+}
+
+// handleFiles browses the generated site under /dev/files/: directories are
+// listed with sizes, modification times and the files changed by the last
+// rebuild; files are shown, or downloaded with ?download=1, or shown as
+// text with ?source=1. ?ext=css,js filters a listing and ?format=json
+// returns it as JSON.
+func (s *Server) handleFiles(w http.ResponseWriter, r *http.Request) {
+	rel := strings.Trim(path.Clean("/"+strings.TrimPrefix(r.URL.Path, "/dev/files")), "/")
+	full, err := s.publicPath(rel)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	info, err := os.Stat(full)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+
+	if !info.IsDir() {
+		serveArtifact(w, r, full, info)
+		return
+	}
+	// Listings end in a slash so their relative links resolve
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		target := path.Base(r.URL.Path) + "/"
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+
+	listing, err := s.listFiles(full, rel, r.URL.Query().Get("ext"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if r.URL.Query().Get("format") == "json" {
+		writeJSON(w, listing)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := fileListingTemplate.Execute(w, listing); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// publicPath resolves a slash separated path below the public directory,
+// refusing any that leads outside it, symlinks included
+func (s *Server) publicPath(rel string) (string, error) {
+	root, err := filepath.Abs(s.cfg().PublicDir)
+	if err != nil {
+		return "", err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return "", err
+	}
+	if resolved != root && !strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+		return "", errOutsidePublic
+	}
+	return resolved, nil
+}
+
+// listFiles lists a directory of the public directory, directories first,
+// keeping only files with one of the comma separated extensions when ext
+// is set
+func (s *Server) listFiles(dir, rel, ext string) (*fileListing, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := s.site().ChangedOutputs()
+	prefix := ""
+	if rel != "" {
+		prefix = rel + "/"
+	}
+	filter := make(map[string]bool)
+	for _, e := range strings.Split(ext, ",") {
+		if e = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(e), ".")); e != "" {
+			filter["."+e] = true
+		}
+	}
+
+	listing := &fileListing{Path: "/" + prefix, Ext: ext, Entries: []fileEntry{}, Changed: []string{}}
+	extensions := make(map[string]bool)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		item := fileEntry{
+			Name:    entry.Name(),
+			Path:    prefix + entry.Name(),
+			Dir:     info.IsDir(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}
+		if item.Dir {
+			item.Size = 0
+			for changedPath := range changed {
+				if strings.HasPrefix(changedPath, item.Path+"/") {
+					item.Changed = true
+					break
+				}
+			}
+		} else {
+			fileExt := strings.ToLower(path.Ext(item.Name))
+			if fileExt != "" {
+				extensions[fileExt] = true
+			}
+			if len(filter) > 0 && !filter[fileExt] {
+				continue
+			}
+			item.Changed = changed[item.Path]
+		}
+		listing.Entries = append(listing.Entries, item)
+	}
+	sort.Slice(listing.Entries, func(i, j int) bool {
+		a, b := listing.Entries[i], listing.Entries[j]
+		if a.Dir != b.Dir {
+			return a.Dir
+		}
+		return a.Name < b.Name
+	})
+
+	for changedPath := range changed {
+		listing.Changed = append(listing.Changed, changedPath)
+	}
+	sort.Strings(listing.Changed)
+	for fileExt := range extensions {
+		listing.Extensions = append(listing.Extensions, strings.TrimPrefix(fileExt, "."))
+	}
+	sort.Strings(listing.Extensions)
+
+	// Links are relative to the listing, so they work under any base path
+	depth := 0
+	if rel != "" {
+		depth = strings.Count(rel, "/") + 1
+	}
+	listing.Root = strings.Repeat("../", depth)
+	if listing.Root == "" {
+		listing.Root = "./"
+	}
+	listing.Crumbs = append(listing.Crumbs, fileCrumb{Name: "public", Href: listing.Root})
+	if rel != "" {
+		parts := strings.Split(rel, "/")
+		for i, part := range parts {
+			href := strings.Repeat("../", len(parts)-1-i)
+			if href == "" {
+				href = "./"
+			}
+			listing.Crumbs = append(listing.Crumbs, fileCrumb{Name: part, Href: href})
+		}
+	}
+	return listing, nil
+}
+
+// serveArtifact sends a generated file as it would be served, as a
+// download, or as plain text
+func serveArtifact(w http.ResponseWriter, r *http.Request, full string, info os.FileInfo) {
+	f, err := os.Open(full)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	query := r.URL.Query()
+	switch {
+	case query.Get("download") != "":
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", info.Name()))
+	case query.Get("source") != "":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// formatFileSize formats a byte count for listings, e.g. "12.3 KB"
+func formatFileSize(size int64) string {
+	switch {
+	case size < 1024:
+		return fmt.Sprintf("%d B", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	}
+}
+
+// fileHref makes a relative link to a slash separated path, escaping each
+// segment so names with "?", "#" or ":" stay part of the path
+func fileHref(rel string) string {
+	parts := strings.Split(rel, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return "./" + strings.Join(parts, "/")
+}
+
+// fileListingTemplate renders a /dev/files directory page
+var fileListingTemplate = template.Must(template.New("files").Funcs(template.FuncMap{
+	"size": formatFileSize,
+	"time": func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+	"href": fileHref,
+	"head": func(list []string) []string {
+		if len(list) > maxChangedShown {
+			return list[:maxChangedShown]
+		}
+		return list
+	},
+	"rest": func(list []string) int {
+		if len(list) > maxChangedShown {
+			return len(list) - maxChangedShown
+		}
+		return 0
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<title>{{ .Path }} - VanGo files</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 0; padding: 20px; background: #f5f5f5; color: #333; }
+main { max-width: 1000px; margin: 0 auto; background: #fff; padding: 20px; border-radius: 8px; }
+nav a { color: #0066cc; }
+table { width: 100%; border-collapse: collapse; margin-top: 15px; }
+th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid #eee; font-size: 14px; }
+td.size, th.size { text-align: right; }
+tr.changed td { background: #fff8e1; }
+.marker { color: #b45309; font-weight: bold; }
+.filter a { margin-right: 6px; }
+.changed-list { font-size: 14px; background: #fff8e1; padding: 10px 15px; border-radius: 4px; }
+code { font-size: 13px; }
+</style>
+</head>
+<body>
+<main>
+<nav aria-label="Breadcrumb">{{ range $i, $c := .Crumbs }}{{ if $i }} / {{ end }}<a href="{{ $c.Href }}">{{ $c.Name }}</a>{{ end }}</nav>
+<form class="filter" method="get">
+<label>Extensions <input name="ext" value="{{ .Ext }}" placeholder="css,js"></label>
+<button type="submit">Filter</button>
+{{ if .Ext }}<a href="./">Clear</a>{{ end }}
+{{ range .Extensions }}<a href="./?ext={{ . }}">.{{ . }}</a>{{ end }}
+</form>
+{{ if .Changed }}
+<div class="changed-list">
+<strong>Changed in the last rebuild ({{ len .Changed }})</strong>
+<ul>{{ range head .Changed }}<li><a href="{{ $.Root }}{{ href . }}"><code>{{ . }}</code></a></li>{{ end }}</ul>
+{{ with rest .Changed }}<p>and {{ . }} more</p>{{ end }}
+</div>
+{{ end }}
+<table>
+<thead><tr><th scope="col" title="Changed in the last rebuild">●</th><th scope="col">Name</th><th scope="col" class="size">Size</th><th scope="col">Modified</th><th scope="col">Actions</th></tr></thead>
+<tbody>
+{{ range .Entries }}
+<tr{{ if .Changed }} class="changed"{{ end }}>
+<td>{{ if .Changed }}<span class="marker" title="Changed in the last rebuild">●</span>{{ end }}</td>
+{{ if .Dir }}
+<td><a href="{{ href .Name }}/">{{ .Name }}/</a></td><td class="size">-</td><td>{{ time .ModTime }}</td><td></td>
+{{ else }}
+<td><a href="{{ href .Name }}">{{ .Name }}</a></td><td class="size">{{ size .Size }}</td><td>{{ time .ModTime }}</td>
+<td><a href="{{ href .Name }}?source=1">source</a> <a href="{{ href .Name }}?download=1">download</a></td>
+{{ end }}
+</tr>
+{{ else }}
+<tr><td></td><td colspan="4">No files</td></tr>
+{{ end }}
+</tbody>
+</table>
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"vango/internal/logging"
+)
+
+// LiveReloadProtocol is the version of the live reload messages. Clients
+// ask for it with /ws/reload?v=1; those that don't are the inline script
+// of earlier releases, still open in some tab, and get plain strings. That
+// fallback goes away in the next release.
+const LiveReloadProtocol = 1
+
+// LiveReloadScriptPath is where the live reload client is served
+const LiveReloadScriptPath = "/__vango/livereload.js"
+
+// Live reload message types
+const (
+	MessageReload   = "reload"   // Reload the page
+	MessageCSS      = "css"      // Swap the stylesheet at the URL path in Data
+	MessageError    = "error"    // The build failed with the error in Data
+	MessageProgress = "progress" // A build is running; Data is a ProgressData
+)
+
+//go:embed livereload.js
+var liveReloadScript []byte
+
+// liveReloadETag lets browsers keep the client until it changes
+var liveReloadETag = func() string {
+	sum := sha256.Sum256(liveReloadScript)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}()
+
+// ReloadMessage is a message to live reload clients, sent as JSON
+type ReloadMessage struct {
+	Version int         `json:"v"`
+	Type    string      `json:"type"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// ProgressData is the data of a progress message
+type ProgressData struct {
+	Stage   string `json:"stage"`
+	Percent int    `json:"percent"`
+}
+
+// newReloadMessage returns a message of the current protocol
+func newReloadMessage(typ string, data interface{}) ReloadMessage {
+	return ReloadMessage{Version: LiveReloadProtocol, Type: typ, Data: data}
+}
+
+// legacyText returns the message as the plain string earlier clients
+// understand, or "" for messages they don't know
+func (m ReloadMessage) legacyText() string {
+	switch m.Type {
+	case MessageReload:
+		return "reload"
+	case MessageCSS:
+		return "css:" + m.Data.(string)
+	case MessageError:
+		return "error:" + m.Data.(string)
+	}
+	return ""
+}
+
+// upgrader accepts live reload connections from pages of the same origin
+var upgrader = websocket.Upgrader{}
+
+// handleWebSocket sends live reload messages to a client until it goes away
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	legacy := true
+	if v := r.URL.Query().Get("v"); v != "" {
+		if n, err := strconv.Atoi(v); err != nil || n < 1 {
+			http.Error(w, "Invalid protocol version "+strconv.Quote(v), http.StatusBadRequest)
+			return
+		}
+		legacy = false
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has answered the request already
+		logging.Debugf("Live reload connection refused: %v", err)
+		return
+	}
+	defer conn.Close()
+	if legacy {
+		logging.Debugf("🔌 Live reload client of an earlier release connected; it gets plain messages until the page reloads")
+	}
+
+	clientChan := make(chan ReloadMessage, 32)
+	s.clientsMu.Lock()
+	s.clients[clientChan] = true
+	s.clientsMu.Unlock()
+	defer func() {
+		s.clientsMu.Lock()
+		delete(s.clients, clientChan)
+		s.clientsMu.Unlock()
+		close(clientChan)
+	}()
+
+	// Clients send nothing, but reading notices when they go away
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case message := <-clientChan:
+			logging.Debugf("📤 Sending to client: %s", message.Type)
+			if err := writeReloadMessage(conn, message, legacy); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
+
+// writeReloadMessage sends a message as JSON, or as a plain string to a
+// legacy client
+func writeReloadMessage(conn *websocket.Conn, message ReloadMessage, legacy bool) error {
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if !legacy {
+		return conn.WriteJSON(message)
+	}
+	text := message.legacyText()
+	if text == "" {
+		return nil
+	}
+	return conn.WriteMessage(websocket.TextMessage, []byte(text))
+}
+
+// notifyClients sends a message to every connected live reload client
+func (s *Server) notifyClients(message ReloadMessage) {
+	s.clientsMu.RLock()
+	defer s.clientsMu.RUnlock()
+
+	for clientChan := range s.clients {
+		select {
+		case clientChan <- message:
+		default:
+			// Channel is full, skip this client
+		}
+	}
+}
+
+// handleLiveReloadScript serves the live reload client. Browsers cache it
+// and revalidate it on every page load.
+func (s *Server) handleLiveReloadScript(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("ETag", liveReloadETag)
+	http.ServeContent(w, r, "livereload.js", time.Time{}, bytes.NewReader(liveReloadScript))
+}
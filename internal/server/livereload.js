@@ -0,0 +1,162 @@
+// VanGo live reload client, served by the development server at
+// /__vango/livereload.js. It speaks version 1 of the live reload protocol:
+// JSON messages like {"v": 1, "type": "css", "data": "/static/main.css"},
+// whose type is "reload", "css", "error" or "progress".
+(function() {
+    'use strict';
+
+    var PROTOCOL = 1;
+    var MIN_DELAY = 500;
+    var MAX_DELAY = 10000;
+
+    // Pages that include the client twice still get one connection
+    if (window.__vangoLiveReload) {
+        return;
+    }
+    window.__vangoLiveReload = PROTOCOL;
+
+    var script = document.currentScript;
+    var endpoint = new URL('/ws/reload?v=' + PROTOCOL, script ? script.src : location.href);
+    endpoint.protocol = endpoint.protocol === 'https:' ? 'wss:' : 'ws:';
+
+    var delay = MIN_DELAY;
+    var dropped = false;
+    var errorBox = null;
+    var progressBar = null;
+
+    // connect opens the socket, and once it closes tries again after a
+    // delay that doubles up to MAX_DELAY, so a stopped server isn't
+    // hammered. The page reloads when the server is back, since the site
+    // may have changed meanwhile.
+    function connect() {
+        var ws = new WebSocket(endpoint.href);
+        ws.onopen = function() {
+            delay = MIN_DELAY;
+            if (dropped) {
+                location.reload();
+                return;
+            }
+            console.log('🔗 Live reload connected');
+        };
+        ws.onmessage = function(event) {
+            handle(parse(event.data));
+        };
+        ws.onclose = function() {
+            if (!dropped) {
+                console.log('❌ Live reload disconnected, reconnecting...');
+            }
+            dropped = true;
+            setTimeout(connect, delay);
+            delay = Math.min(delay * 2, MAX_DELAY);
+        };
+    }
+
+    // parse decodes a message. Servers of earlier releases send plain
+    // strings: "reload", "css:<path>" and "error:<message>".
+    function parse(data) {
+        try {
+            var message = JSON.parse(data);
+            if (message && typeof message.type === 'string') {
+                return message;
+            }
+        } catch (e) {
+            // Not JSON, so a plain string
+        }
+        if (data === 'reload') {
+            return {v: 0, type: 'reload'};
+        }
+        if (data.indexOf('css:') === 0) {
+            return {v: 0, type: 'css', data: data.slice(4)};
+        }
+        if (data.indexOf('error:') === 0) {
+            return {v: 0, type: 'error', data: data.slice(6)};
+        }
+        return null;
+    }
+
+    function handle(message) {
+        if (!message) {
+            return;
+        }
+        if (message.v > PROTOCOL) {
+            // A newer server: the reloaded page brings the client for it
+            location.reload();
+            return;
+        }
+        switch (message.type) {
+        case 'reload':
+            console.log('🔄 Reloading page...');
+            location.reload();
+            break;
+        case 'css':
+            hideError();
+            swapStylesheet(message.data);
+            break;
+        case 'error':
+            console.error('❌ Build error:', message.data);
+            showProgress(null);
+            showError(message.data);
+            break;
+        case 'progress':
+            showProgress(message.data);
+            break;
+        }
+    }
+
+    // swapStylesheet reloads a changed stylesheet in place, keeping scroll
+    // position and form state; pages that don't link it are left alone
+    function swapStylesheet(path) {
+        document.querySelectorAll('link[rel="stylesheet"]').forEach(function(link) {
+            var url = new URL(link.href, location.href);
+            if (url.origin !== location.origin || url.pathname !== path) {
+                return;
+            }
+            url.searchParams.set('vango-reload', Date.now());
+            link.href = url.pathname + url.search;
+            console.log('🎨 Updated stylesheet ' + path);
+        });
+    }
+
+    // showError shows a build error until the next change is applied or
+    // it is clicked away
+    function showError(error) {
+        hideError();
+        errorBox = document.createElement('div');
+        errorBox.style.cssText = 'position: fixed; top: 20px; right: 20px; background: #ff4444; color: white; ' +
+            'padding: 15px; border-radius: 5px; z-index: 10000; max-width: 400px; ' +
+            'font-family: monospace; font-size: 12px; white-space: pre-wrap; cursor: pointer;';
+        errorBox.title = 'Click to dismiss';
+        errorBox.textContent = 'Build Error: ' + error;
+        errorBox.onclick = hideError;
+        document.body.appendChild(errorBox);
+    }
+
+    function hideError() {
+        if (errorBox && errorBox.parentNode) {
+            errorBox.parentNode.removeChild(errorBox);
+        }
+        errorBox = null;
+    }
+
+    // showProgress draws a build's progress as a bar along the top of the
+    // page, removed when the build is done; null removes it at once
+    function showProgress(progress) {
+        if (!progress || progress.percent >= 100) {
+            if (progressBar && progressBar.parentNode) {
+                progressBar.parentNode.removeChild(progressBar);
+            }
+            progressBar = null;
+            return;
+        }
+        if (!progressBar) {
+            progressBar = document.createElement('div');
+            progressBar.style.cssText = 'position: fixed; top: 0; left: 0; height: 3px; width: 0; ' +
+                'background: #007bff; z-index: 10001; transition: width 0.2s;';
+            document.body.appendChild(progressBar);
+        }
+        progressBar.style.width = progress.percent + '%';
+        progressBar.title = progress.stage;
+    }
+
+    connect();
+})();
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"vango/internal/logging"
+)
+
+const (
+	// maxNotifyBody bounds the text of a desktop notification
+	maxNotifyBody = 200
+	// notifyTimeout stops a notification command that hangs
+	notifyTimeout = 10 * time.Second
+)
+
+// SetNotify shows a desktop notification when a build fails and when the
+// next one succeeds. command, when set, runs as `command title body`;
+// otherwise osascript is used on macOS and notify-send elsewhere.
+func (s *Server) SetNotify(enable bool, command string) {
+	s.notify = enable
+	s.notifyCommand = command
+}
+
+// notifyDesktop shows a notification in the background, so a slow or
+// missing notifier never holds up a build
+func (s *Server) notifyDesktop(title, body string) {
+	if !s.notify {
+		return
+	}
+	if len(body) > maxNotifyBody {
+		body = body[:maxNotifyBody] + "…"
+	}
+	name, args := notifyArgs(s.notifyCommand, title, body)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if out, err := exec.CommandContext(ctx, name, args...).CombinedOutput(); err != nil {
+			s.notifyWarned.Do(func() {
+				logging.Warnf("⚠️  Desktop notification failed: %v %s", err, strings.TrimSpace(string(out)))
+			})
+		}
+	}()
+}
+
+// notifyArgs returns the command line showing a notification
+func notifyArgs(command, title, body string) (string, []string) {
+	if fields := strings.Fields(command); len(fields) > 0 {
+		return fields[0], append(fields[1:], title, body)
+	}
+	if runtime.GOOS == "darwin" {
+		script := "display notification " + appleScriptString(body) + " with title " + appleScriptString(title)
+		return "osascript", []string{"-e", script}
+	}
+	return "notify-send", []string{"--app-name=VanGo", title, body}
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// firstLine returns the first non-blank line of s
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
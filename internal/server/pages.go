@@ -0,0 +1,170 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"vango/internal/content"
+	"vango/internal/util"
+)
+
+// Page listing limits for /api/pages
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 1000
+)
+
+// PageInfo describes a page in the /api/pages listing, without its content
+type PageInfo struct {
+	Title        string                 `json:"title"`
+	URL          string                 `json:"url"`
+	Section      string                 `json:"section,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	Draft        bool                   `json:"draft"`
+	OutputPath   string                 `json:"output_path"`
+	WordCount    int                    `json:"word_count"`
+	ReadingTime  int                    `json:"reading_time"`
+	LastModified time.Time              `json:"last_modified"`
+	Params       map[string]interface{} `json:"params,omitempty"`
+	PageWeight   int64                  `json:"page_weight"` // HTML with the local stylesheets, scripts and images it references
+	OverBudget   bool                   `json:"over_budget,omitempty"`
+}
+
+// PageList is one page of the /api/pages listing. Total counts every page
+// matching the filters, not just the ones returned.
+type PageList struct {
+	Total  int        `json:"total"`
+	Offset int        `json:"offset"`
+	Limit  int        `json:"limit"`
+	Pages  []PageInfo `json:"pages"`
+}
+
+// pageQuery holds the filters, order and window of an /api/pages request
+type pageQuery struct {
+	section string
+	draft   *bool
+	search  string
+	sort    string
+	limit   int
+	offset  int
+}
+
+// parsePageQuery reads ?section=, ?draft=, ?q=, ?sort=date|title|weight,
+// ?limit= and ?offset= from the request
+func parsePageQuery(r *http.Request) (pageQuery, error) {
+	values := r.URL.Query()
+	query := pageQuery{
+		section: values.Get("section"),
+		search:  strings.ToLower(values.Get("q")),
+		sort:    values.Get("sort"),
+		limit:   defaultPageLimit,
+	}
+
+	if v := values.Get("draft"); v != "" {
+		draft, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, fmt.Errorf("invalid draft %q: must be true or false", v)
+		}
+		query.draft = &draft
+	}
+	if query.sort != "" && query.sort != "date" && query.sort != "title" && query.sort != "weight" {
+		return query, fmt.Errorf("invalid sort %q: must be date, title or weight", query.sort)
+	}
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return query, fmt.Errorf("invalid limit %q: must be between 1 and %d", v, maxPageLimit)
+		}
+		query.limit = limit
+	}
+	if v := values.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return query, fmt.Errorf("invalid offset %q: must be 0 or more", v)
+		}
+		query.offset = offset
+	}
+	return query, nil
+}
+
+// matches reports whether page passes the query's filters
+func (q pageQuery) matches(page *content.Page) bool {
+	if q.section != "" && page.Section != q.section {
+		return false
+	}
+	if q.draft != nil && page.Draft != *q.draft {
+		return false
+	}
+	return q.search == "" || strings.Contains(strings.ToLower(page.Title), q.search)
+}
+
+// handlePages lists the site's pages, filtered and paginated by the query
+// parameters. Only the requested window is turned into PageInfo values.
+func (s *Server) handlePages(w http.ResponseWriter, r *http.Request) {
+	query, err := parsePageQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var matched content.Pages
+	for _, page := range s.site().GetPages() {
+		if query.matches(page) {
+			matched = append(matched, page)
+		}
+	}
+	switch query.sort {
+	case "date":
+		matched = matched.ByDate()
+	case "title":
+		matched = matched.ByTitle()
+	case "weight":
+		// Heaviest first, to find the pages over the budget
+		weights := make(map[*content.Page]int64, len(matched))
+		for _, page := range matched {
+			weights[page], _ = s.site().PageWeight(page.URL)
+		}
+		sort.SliceStable(matched, func(i, j int) bool { return weights[matched[i]] > weights[matched[j]] })
+	}
+
+	list := PageList{
+		Total:  len(matched),
+		Offset: query.offset,
+		Limit:  query.limit,
+		Pages:  []PageInfo{},
+	}
+	if query.offset < len(matched) {
+		end := min(query.offset+query.limit, len(matched))
+		for _, page := range matched[query.offset:end] {
+			list.Pages = append(list.Pages, s.pageInfo(page))
+		}
+	}
+	writeJSON(w, list)
+}
+
+// pageInfo summarizes page for the listing
+func (s *Server) pageInfo(page *content.Page) PageInfo {
+	outputPath := page.OutputPath
+	if outputPath == "" {
+		outputPath = util.OutputPath(s.cfg().PublicDir, page.Slug, "index.html")
+	}
+	weight, over := s.site().PageWeight(page.URL)
+	return PageInfo{
+		Title:        page.Title,
+		URL:          page.URL,
+		Section:      page.Section,
+		Tags:         page.Tags,
+		Draft:        page.Draft,
+		OutputPath:   outputPath,
+		WordCount:    page.WordCount,
+		ReadingTime:  page.ReadingTime,
+		LastModified: page.ParsedDate,
+		Params:       page.Params,
+		PageWeight:   weight,
+		OverBudget:   over,
+	}
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"vango/internal/config"
+	"vango/internal/logging"
+)
+
+// InternalAPIPrefix is where the dev server's API endpoints are served.
+// They are also served at /api/, as in earlier releases, except where a
+// proxy forwards /api/ to a backend.
+const InternalAPIPrefix = "/__vango/api/"
+
+// route registers a handler of the dev server's own and records its
+// pattern, so proxies can be checked against it
+func (s *Server) route(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+	s.routes = append(s.routes, pattern)
+}
+
+// setupProxies forwards the path prefixes in server.proxies to their
+// backends. They take precedence over pages; a prefix overlapping one of
+// the dev server's own routes, which would shadow part of the backend or
+// of the server, is an error.
+func (s *Server) setupProxies() error {
+	prefixes := make([]string, 0, len(s.cfg().Server.Proxies))
+	for prefix := range s.cfg().Server.Proxies {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	for _, prefix := range prefixes {
+		proxy := s.cfg().Server.Proxies[prefix]
+		target, err := url.Parse(proxy.Target)
+		if err != nil {
+			return fmt.Errorf("server.proxies %q: %w", prefix, err)
+		}
+		for _, route := range s.routes {
+			if route != "/" && (proxyCovers(prefix, route) || proxyCovers(route, prefix)) {
+				return fmt.Errorf("server.proxies %q overlaps %s, served by the development server itself", prefix, route)
+			}
+		}
+		handler := newProxyHandler(prefix, proxy, target)
+
+		// "/api" forwards /api itself and everything below it
+		s.mux.Handle(prefix, handler)
+		if !strings.HasSuffix(prefix, "/") {
+			s.mux.Handle(prefix+"/", handler)
+		}
+		s.proxyPrefixes = append(s.proxyPrefixes, prefix)
+		logging.Infof("🔀 Proxying %s to %s", prefix, proxy.Target)
+		if proxyCovers(prefix, "/api/status") {
+			logging.Infof("   The development server's own API is at %s", InternalAPIPrefix)
+		}
+	}
+	return nil
+}
+
+// newProxyHandler returns the reverse proxy for a prefix. Requests,
+// WebSocket upgrades included, reach the backend as if made to it
+// directly, with X-Forwarded headers naming the dev server.
+func newProxyHandler(prefix string, proxy config.ProxyConfig, target *url.URL) http.Handler {
+	rp := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if proxy.Rewrite != "" {
+				pr.Out.URL.Path = rewritePath(pr.Out.URL.Path, prefix, proxy.Rewrite)
+				pr.Out.URL.RawPath = ""
+			}
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			for name, value := range proxy.Headers {
+				if strings.EqualFold(name, "Host") {
+					pr.Out.Host = value
+				} else {
+					pr.Out.Header.Set(name, value)
+				}
+			}
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			if resp.StatusCode >= 500 {
+				logging.Warnf("⚠️  Proxy: %s %s answered %d", resp.Request.Method, resp.Request.URL, resp.StatusCode)
+			}
+			return nil
+		},
+		// r is the request to the backend
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if errors.Is(err, context.Canceled) {
+				logging.Debugf("Proxy: %s %s canceled by the client", r.Method, r.URL)
+				return
+			}
+			logging.Errorf("❌ Proxy: %s %s failed: %v", r.Method, r.URL, err)
+			http.Error(w, fmt.Sprintf("Proxy error: %s %s failed: %v", r.Method, r.URL, err), http.StatusBadGateway)
+		},
+	}
+	if proxy.Insecure && target.Scheme == "https" {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		rp.Transport = transport
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server's timeouts are meant for its own files; backends take
+		// their time, and WebSockets stay open
+		rc := http.NewResponseController(w)
+		rc.SetReadDeadline(time.Time{})
+		rc.SetWriteDeadline(time.Time{})
+		rp.ServeHTTP(w, r)
+	})
+}
+
+// rewritePath replaces prefix at the start of path with rewrite
+func rewritePath(path, prefix, rewrite string) string {
+	rest := strings.TrimPrefix(path, prefix)
+	if rest == "" {
+		return rewrite
+	}
+	return strings.TrimSuffix(rewrite, "/") + "/" + strings.TrimPrefix(rest, "/")
+}
+
+// proxyCovers reports whether a proxy for prefix forwards requests for path
+func proxyCovers(prefix, path string) bool {
+	return path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/")
+}
+
+// isProxied reports whether a request path is forwarded to a backend
+func (s *Server) isProxied(path string) bool {
+	for _, prefix := range s.proxyPrefixes {
+		if proxyCovers(prefix, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxiesCover reports whether a configured proxy forwards requests for
+// path, before the proxies are set up
+func (s *Server) proxiesCover(path string) bool {
+	for prefix := range s.cfg().Server.Proxies {
+		if proxyCovers(prefix, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkProxiesChanged warns that edits to server.proxies in a reloaded
+// configuration wait for a restart, since routes are set up once
+func (s *Server) checkProxiesChanged(cfg *config.Config) {
+	if !reflect.DeepEqual(cfg.Server.Proxies, s.cfg().Server.Proxies) {
+		logging.Warnf("⚠️  server.proxies changed; restart the server to apply")
+	}
+}
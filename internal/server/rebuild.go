@@ -0,0 +1,223 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"vango/internal/logging"
+)
+
+// rebuildRequest is the optional JSON body of POST /api/rebuild
+type rebuildRequest struct {
+	Scope string   `json:"scope"`
+	Paths []string `json:"paths"`
+}
+
+// queuedRebuild is a rebuild waiting for, or holding, the build slot
+type queuedRebuild struct {
+	id  int64
+	req rebuildRequest
+}
+
+// BuildEvent is a progress message streamed on /api/build-events. Builds
+// started by the file watcher have ID 0.
+type BuildEvent struct {
+	ID      int64  `json:"id"`
+	Stage   string `json:"stage"`
+	Percent int    `json:"percent"`
+	Done    bool   `json:"done,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// enqueueRebuild schedules a rebuild and returns its build ID. While a
+// rebuild is running, further requests coalesce into a single pending one.
+func (s *Server) enqueueRebuild(req rebuildRequest) int64 {
+	s.rebuildMu.Lock()
+	defer s.rebuildMu.Unlock()
+
+	if s.pendingRebuild != nil {
+		s.pendingRebuild.req = mergeRebuilds(s.pendingRebuild.req, req)
+		return s.pendingRebuild.id
+	}
+
+	s.buildSeq++
+	q := &queuedRebuild{id: s.buildSeq, req: req}
+	if s.activeRebuild != 0 {
+		s.pendingRebuild = q
+		return q.id
+	}
+
+	s.activeRebuild = q.id
+	go s.runRebuilds(q)
+	return q.id
+}
+
+// runRebuilds runs q and then any rebuild that was queued meanwhile
+func (s *Server) runRebuilds(q *queuedRebuild) {
+	for q != nil {
+		s.runRebuild(q)
+
+		s.rebuildMu.Lock()
+		q, s.pendingRebuild = s.pendingRebuild, nil
+		s.activeRebuild = 0
+		if q != nil {
+			s.activeRebuild = q.id
+		}
+		s.rebuildMu.Unlock()
+	}
+}
+
+// runRebuild performs a single rebuild and publishes its outcome
+func (s *Server) runRebuild(q *queuedRebuild) {
+	logging.Infof("🔄 Rebuild #%d (scope: %s)", q.id, scopeName(q.req.Scope))
+
+	s.buildMu.Lock()
+	err := s.trackBuild(func() error {
+		return s.site().Rebuild(q.req.Scope, q.req.Paths)
+	}, q.req.Paths)
+	s.buildMu.Unlock()
+
+	event := BuildEvent{ID: q.id, Stage: "done", Percent: 100, Done: true}
+	if err != nil {
+		logging.Errorf("❌ Rebuild #%d failed: %v", q.id, err)
+		event.Stage = "failed"
+		event.Error = err.Error()
+	} else if scopeName(q.req.Scope) == "all" {
+		s.markWarm()
+	}
+	s.publishBuildEvent(event)
+}
+
+// mergeRebuilds combines two rebuild requests into one covering both
+func mergeRebuilds(a, b rebuildRequest) rebuildRequest {
+	if scopeName(a.Scope) != scopeName(b.Scope) || scopeName(a.Scope) == "all" {
+		return rebuildRequest{Scope: "all"}
+	}
+	// An empty path list means the whole scope
+	if len(a.Paths) == 0 || len(b.Paths) == 0 {
+		return rebuildRequest{Scope: a.Scope}
+	}
+
+	seen := make(map[string]bool)
+	merged := rebuildRequest{Scope: a.Scope}
+	for _, path := range append(a.Paths, b.Paths...) {
+		if !seen[path] {
+			seen[path] = true
+			merged.Paths = append(merged.Paths, path)
+		}
+	}
+	return merged
+}
+
+// scopeName normalizes an empty scope to "all"
+func scopeName(scope string) string {
+	if scope == "" {
+		return "all"
+	}
+	return scope
+}
+
+// onBuildProgress relays builder progress to build event subscribers and
+// live reload clients
+func (s *Server) onBuildProgress(stage string, percent int) {
+	s.rebuildMu.Lock()
+	id := s.activeRebuild
+	s.rebuildMu.Unlock()
+
+	// The final event is published by runRebuild once the outcome is known
+	if stage == "done" && id != 0 {
+		return
+	}
+	s.publishBuildEvent(BuildEvent{ID: id, Stage: stage, Percent: percent})
+	s.notifyClients(newReloadMessage(MessageProgress, ProgressData{Stage: stage, Percent: percent}))
+}
+
+// publishBuildEvent sends an event to every /api/build-events subscriber
+func (s *Server) publishBuildEvent(event BuildEvent) {
+	s.eventsMu.RLock()
+	defer s.eventsMu.RUnlock()
+
+	for ch := range s.eventClients {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber is too slow, drop the update
+		}
+	}
+}
+
+// handleRebuild queues a rebuild and returns its build ID immediately
+func (s *Server) handleRebuild(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req rebuildRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	switch req.Scope {
+	case "", "all", "content", "templates":
+	default:
+		http.Error(w, fmt.Sprintf("Unknown scope %q", req.Scope), http.StatusBadRequest)
+		return
+	}
+
+	id := s.enqueueRebuild(req)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "queued",
+		"build_id": id,
+		"events":   InternalAPIPrefix + "build-events",
+	})
+}
+
+// handleBuildEvents streams build progress as server-sent events
+func (s *Server) handleBuildEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// Builds can outlast the server's write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	events := make(chan BuildEvent, 32)
+	s.eventsMu.Lock()
+	s.eventClients[events] = true
+	s.eventsMu.Unlock()
+
+	defer func() {
+		s.eventsMu.Lock()
+		delete(s.eventClients, events)
+		s.eventsMu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
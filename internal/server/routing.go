@@ -0,0 +1,166 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"vango/internal/logging"
+	"vango/internal/util"
+)
+
+// maxURLSuggestions is how many near-miss URLs a 404 page suggests
+const maxURLSuggestions = 3
+
+// routingMiddleware normalizes page URLs the way the hosting platform does:
+// directory URLs without a trailing slash are redirected, served or refused
+// per routing.trailingSlash, and with routing.caseInsensitive URLs that only
+// match in another case are redirected to the page's actual URL
+func (s *Server) routingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		routing := s.cfg().Routing
+		urlPath := r.URL.Path
+
+		if !strings.HasSuffix(urlPath, "/") && s.isDirectoryPage(urlPath) {
+			switch routing.TrailingSlash {
+			case "strict":
+				s.handle404(w, r)
+				return
+			case "serve":
+			default:
+				s.redirectTo(w, r, urlPath+"/")
+				return
+			}
+		}
+
+		if routing.CaseInsensitive && !s.pageExists(urlPath) && !s.isDirectoryPage(urlPath) {
+			if match, ok := s.findPageFold(urlPath); ok {
+				logging.Warnf("⚠️  %s only matches %s in a different case; most hosts are case-sensitive", urlPath, match)
+				s.redirectTo(w, r, match)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// redirectTo redirects permanently to urlPath, keeping the query string
+func (s *Server) redirectTo(w http.ResponseWriter, r *http.Request, urlPath string) {
+	if r.URL.RawQuery != "" {
+		urlPath += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, s.cfg().BaseURLPath()+urlPath, http.StatusMovedPermanently)
+}
+
+// isDirectoryPage reports whether urlPath names a page served from a
+// directory index, i.e. one whose canonical URL ends in a slash
+func (s *Server) isDirectoryPage(urlPath string) bool {
+	trimmed := strings.Trim(urlPath, "/")
+	if trimmed == "" {
+		return false
+	}
+	if info, err := os.Stat(util.OutputPath(s.cfg().PublicDir, trimmed, "index.html")); err == nil && !info.IsDir() {
+		return true
+	}
+	return s.lazy && s.site().HasContent(urlPath)
+}
+
+// findPageFold looks up urlPath in the public directory ignoring case and
+// returns the URL it is actually served at
+func (s *Server) findPageFold(urlPath string) (string, bool) {
+	dir := s.cfg().PublicDir
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(urlPath, "/"), "/") {
+		if segment == "" {
+			return "", false
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return "", false
+		}
+		found := false
+		for _, entry := range entries {
+			if strings.EqualFold(entry.Name(), segment) {
+				segments = append(segments, entry.Name())
+				dir = util.OutputPath(dir, entry.Name())
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+
+	match := "/" + strings.Join(segments, "/")
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		if _, err := os.Stat(util.OutputPath(dir, "index.html")); err != nil {
+			return "", false
+		}
+		match += "/"
+	}
+	return match, true
+}
+
+// suggestURLs returns the known page URLs closest to urlPath by edit
+// distance, ignoring case, for the 404 page
+func (s *Server) suggestURLs(urlPath string) []string {
+	want := strings.ToLower(util.SlugURL(urlPath))
+	seen := make(map[string]bool)
+	type candidate struct {
+		url      string
+		distance int
+	}
+	var candidates []candidate
+	consider := func(url string) {
+		if seen[url] {
+			return
+		}
+		seen[url] = true
+		distance := util.Levenshtein(want, strings.ToLower(url))
+		if url != urlPath && (distance <= 2 || distance <= len(want)/3) {
+			candidates = append(candidates, candidate{url, distance})
+		}
+	}
+	for _, page := range s.site().GetPages() {
+		consider(page.URL)
+	}
+	for _, file := range s.site().GetReport().OutputFiles {
+		if dir, name := path.Split(file); name == "index.html" {
+			consider(util.SlugURL(dir))
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].url < candidates[j].url
+	})
+	var urls []string
+	for i := 0; i < len(candidates) && i < maxURLSuggestions; i++ {
+		urls = append(urls, candidates[i].url)
+	}
+	return urls
+}
+
+// suggestionsHTML renders the near-miss URLs as a "did you mean" list
+func (s *Server) suggestionsHTML(urls []string) string {
+	if len(urls) == 0 {
+		return ""
+	}
+	var items strings.Builder
+	for _, url := range urls {
+		href := template.HTMLEscapeString(s.cfg().BaseURLPath() + url)
+		items.WriteString(fmt.Sprintf(`<li><a href="%s">%s</a></li>`, href, template.HTMLEscapeString(url)))
+	}
+	return `<div class="vango-suggestions"><p>Did you mean:</p><ul>` + items.String() + `</ul></div>`
+}
@@ -1,35 +1,89 @@
 package server
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"vango/internal/builder"
 	"vango/internal/config"
-
-	"github.com/fsnotify/fsnotify"
+	"vango/internal/logging"
+	tmpl "vango/internal/template"
+	"vango/internal/util"
+	"vango/internal/watcher"
 )
 
 // Server handles the enhanced development server
 type Server struct {
+	// config and builder are replaced when the configuration is reloaded;
+	// read them with cfg and site
 	config    *config.Config
 	builder   *builder.Builder
+	stateMu   sync.RWMutex
 	port      int
 	mux       *http.ServeMux
 	verbose   bool
-	clients   map[chan string]bool
+	configFiles []string
+	clients   map[chan ReloadMessage]bool
 	clientsMu sync.RWMutex
 	
 	// Performance tracking
-	stats     *ServerStats
-	statsMu   sync.RWMutex
+	stats       *ServerStats
+	statsMu     sync.RWMutex
+	latencies   []time.Duration
+	latencyNext int
+	
+	// Optional access log, in combined log format or JSON lines
+	accessLog     io.Writer
+	accessLogJSON bool
+	accessLogMu   sync.Mutex
+	
+	// HTTPS with HTTP/2, using the given or a generated certificate
+	tlsEnabled       bool
+	certFile         string
+	keyFile          string
+	httpRedirectPort int
+	
+	// Lazy mode renders pages on first request while a background build warms the site
+	lazy         bool
+	warm         bool
+	contentFiles int
+	renderCache  map[string]string
+	renderMu     sync.RWMutex
+	
+	// buildMu serializes builds over the output directory; API rebuilds
+	// coalesce into at most one pending rebuild behind the active one
+	buildMu        sync.Mutex
+	rebuildMu      sync.Mutex
+	buildSeq       int64
+	activeRebuild  int64
+	pendingRebuild *queuedRebuild
+	eventClients   map[chan BuildEvent]bool
+	eventsMu       sync.RWMutex
+	
+	// Recent builds for the build log, guarded by statsMu
+	builds []BuildRecord
+	
+	// Optional desktop notifications when a build fails or recovers
+	notify        bool
+	notifyCommand string
+	notifyWarned  sync.Once
+	
+	// Path prefixes forwarded to backends by server.proxies, and the
+	// server's own route patterns they must not overlap
+	proxyPrefixes []string
+	routes        []string
 }
 
 // ServerStats tracks server performance metrics
@@ -43,24 +97,57 @@ type ServerStats struct {
 	FileWatches  int                  `json:"file_watches"`
 	ClientCount  int                  `json:"client_count"`
 	PageViews    map[string]int64     `json:"page_views"`
-	BuildErrors  []string             `json:"build_errors"`
+	TopPages     []PageViewCount      `json:"top_pages"`
+	OtherPageViews int64              `json:"other_page_views"`
+	BytesServed  int64                `json:"bytes_served"`
+	P95LatencyMs float64              `json:"p95_latency_ms"`
+	BuildErrors  []BuildError         `json:"build_errors"`
+	// LastBuildStatus is "ok" or "failed", or "pending" before the first
+	// build finishes; LastBuildError summarizes a failure
+	LastBuildStatus string            `json:"last_build_status"`
+	LastBuildError  string            `json:"last_build_error,omitempty"`
+	WarmPages    int                  `json:"warm_pages"`
+	ColdPages    int                  `json:"cold_pages"`
 }
 
 // New creates a new enhanced development server
 func New(cfg *config.Config, port int) *Server {
-	return &Server{
+	cfg.IsServing = true
+	s := &Server{
 		config:  cfg,
 		builder: builder.New(cfg),
 		port:    port,
 		mux:     http.NewServeMux(),
 		verbose: false,
-		clients: make(map[chan string]bool),
+		configFiles: config.ConfigFiles(nil, true),
+		clients: make(map[chan ReloadMessage]bool),
+		renderCache: make(map[string]string),
+		eventClients: make(map[chan BuildEvent]bool),
 		stats: &ServerStats{
 			StartTime: time.Now(),
 			PageViews: make(map[string]int64),
-			BuildErrors: make([]string, 0),
+			LastBuildStatus: buildPending,
+			BuildErrors: make([]BuildError, 0),
 		},
 	}
+	s.builder.SetProgressFunc(s.onBuildProgress)
+	s.builder.SetPrecompress(false)
+	s.builder.SetTrackChanges(true)
+	return s
+}
+
+// cfg returns the current configuration
+func (s *Server) cfg() *config.Config {
+	s.stateMu.RLock()
+	defer s.stateMu.RUnlock()
+	return s.config
+}
+
+// site returns the builder of the current configuration
+func (s *Server) site() *builder.Builder {
+	s.stateMu.RLock()
+	defer s.stateMu.RUnlock()
+	return s.builder
 }
 
 
@@ -69,12 +156,37 @@ func (s *Server) SetVerbose(verbose bool) {
 	s.verbose = verbose
 }
 
+// SetConfigFiles sets the configuration files reloaded when one changes,
+// usually from config.ConfigFiles
+func (s *Server) SetConfigFiles(files []string) {
+	s.configFiles = files
+}
+
+// SetLazy enables on-demand rendering: the server starts accepting
+// connections immediately and warms the site with a background build
+func (s *Server) SetLazy(lazy bool) {
+	s.lazy = lazy
+}
+
 // Start starts the enhanced development server
 func (s *Server) Start() error {
-	// Build site initially
-	fmt.Println("🏗️  Building site for development server...")
-	if err := s.buildSite(); err != nil {
-		return fmt.Errorf("initial build failed: %w", err)
+	if s.lazy {
+		logging.Infof("💤 Lazy mode: rendering pages on first request")
+		if err := s.site().PrepareTemplates(); err != nil {
+			return fmt.Errorf("failed to prepare templates: %w", err)
+		}
+		s.contentFiles = s.site().ContentFileCount()
+		go func() {
+			if err := s.buildSite(); err != nil {
+				logging.Errorf("❌ Background build failed: %v", err)
+			}
+		}()
+	} else {
+		// Build site initially
+		logging.Infof("🏗️  Building site for development server...")
+		if err := s.buildSite(); err != nil {
+			return fmt.Errorf("initial build failed: %w", err)
+		}
 	}
 
 	// Start file watcher
@@ -82,263 +194,302 @@ func (s *Server) Start() error {
 
 	// Setup routes with enhanced features
 	s.setupEnhancedRoutes()
+	if err := s.setupProxies(); err != nil {
+		return err
+	}
 
 	// Start server
 	addr := fmt.Sprintf(":%d", s.port)
-	fmt.Printf("🚀 Development server running at http://localhost%s\n", addr)
-	fmt.Println("📊 Admin panel: http://localhost" + addr + "/admin")
-	fmt.Println("🔄 Live reload enabled")
-	fmt.Println("📝 Press Ctrl+C to stop")
+	var handler http.Handler = s.basePathMiddleware(s.mux)
+	if s.cfg().Performance.EnableCompression {
+		handler = gzipMiddleware(handler)
+	}
+	handler = s.loggingMiddleware(handler)
+	var certFile, keyFile string
+	if s.tlsEnabled {
+		var err error
+		if certFile, keyFile, err = s.devCertificate(s.cfg().Host); err != nil {
+			return err
+		}
+		if s.cfg().Security.HTTPS.HSTS {
+			handler = hstsMiddleware(handler)
+		}
+	}
+	logging.Infof("🚀 Development server running at %s://localhost%s", s.scheme(), addr)
+	logging.Infof("📊 Admin panel: %s://localhost%s/admin", s.scheme(), addr)
+	logging.Infof("🔄 Live reload enabled")
+	logging.Infof("📝 Press Ctrl+C to stop")
 
 	server := &http.Server{
 		Addr:         addr,
-		Handler:      s.loggingMiddleware(s.mux),
+		Handler:      handler,
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
+	if !s.tlsEnabled {
+		return server.ListenAndServe()
+	}
 
-	return server.ListenAndServe()
+	if s.httpRedirectPort != 0 {
+		logging.Infof("↪️  Redirecting http://localhost:%d to HTTPS", s.httpRedirectPort)
+		go func() {
+			if err := serveHTTPRedirect(s.httpRedirectPort, s.port); err != nil {
+				logging.Errorf("❌ HTTP redirect server failed: %v", err)
+			}
+		}()
+	}
+	server.TLSConfig = tlsConfig()
+	return server.ListenAndServeTLS(certFile, keyFile)
 }
 
 // setupEnhancedRoutes configures enhanced HTTP routes
 func (s *Server) setupEnhancedRoutes() {
-	// Static files with better caching
-	staticDir := filepath.Join(s.config.PublicDir, "static")
-	s.mux.Handle("/static/", s.cacheMiddleware(
-		http.StripPrefix("/static/", http.FileServer(http.Dir(staticDir))),
-	))
+	// Static files with better caching. With staticAtRoot they are served
+	// like any other file at the root.
+	if !s.cfg().StaticAtRoot {
+		staticDir := filepath.Join(s.cfg().PublicDir, "static")
+		s.route("/static/", s.cacheMiddleware(
+			http.StripPrefix("/static/", http.FileServer(http.Dir(staticDir))),
+		))
+	}
 
 	// Theme assets
-	themeDir := filepath.Join(s.config.PublicDir, "theme")
-	s.mux.Handle("/theme/", s.cacheMiddleware(
+	themeDir := filepath.Join(s.cfg().PublicDir, "theme")
+	s.route("/theme/", s.cacheMiddleware(
 		http.StripPrefix("/theme/", http.FileServer(http.Dir(themeDir))),
 	))
 
-	// Live reload WebSocket endpoint
-	s.mux.HandleFunc("/ws/reload", s.handleWebSocket)
+	// Live reload WebSocket endpoint and client
+	s.route("/ws/reload", http.HandlerFunc(s.handleWebSocket))
+	s.route(LiveReloadScriptPath, http.HandlerFunc(s.handleLiveReloadScript))
 
-	// Enhanced API endpoints
-	s.mux.HandleFunc("/api/rebuild", s.handleRebuild)
-	s.mux.HandleFunc("/api/status", s.handleStatus)
-	s.mux.HandleFunc("/api/stats", s.handleStats)
-	s.mux.HandleFunc("/api/pages", s.handlePages)
-	s.mux.HandleFunc("/api/config", s.handleConfig)
-	s.mux.HandleFunc("/api/clear-cache", s.handleClearCache)
-	s.mux.HandleFunc("/api/validate", s.handleValidate)
+	// Enhanced API endpoints, also at /api/ unless a proxy forwards it
+	s.route("/healthz", http.HandlerFunc(s.handleHealthz))
+	for _, endpoint := range []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"rebuild", s.handleRebuild},
+		{"build-events", s.handleBuildEvents},
+		{"status", s.handleStatus},
+		{"stats", s.handleStats},
+		{"build-log", s.handleBuildLog},
+		{"pages", s.handlePages},
+		{"config", s.handleConfig},
+		{"clear-cache", s.handleClearCache},
+		{"validate", s.handleValidate},
+	} {
+		s.route(InternalAPIPrefix+endpoint.name, endpoint.handler)
+		if !s.proxiesCover("/api/" + endpoint.name) {
+			s.route("/api/"+endpoint.name, endpoint.handler)
+		}
+	}
 
 	// Admin panel
-	s.mux.HandleFunc("/admin", s.handleAdmin)
-	s.mux.HandleFunc("/admin/", s.handleAdmin)
+	s.route("/admin", localOnly(http.HandlerFunc(s.handleAdmin)))
+	s.route("/admin/", localOnly(http.HandlerFunc(s.handleAdmin)))
 
 	// Development tools
-	s.mux.HandleFunc("/dev/template-debug", s.handleTemplateDebug)
-	s.mux.HandleFunc("/dev/performance", s.handlePerformance)
+	s.route("/dev/template-debug", localOnly(http.HandlerFunc(s.handleTemplateDebug)))
+	s.route("/dev/performance", localOnly(http.HandlerFunc(s.handlePerformance)))
+	s.route("/dev/files", localOnly(http.HandlerFunc(s.handleFiles)))
+	s.route("/dev/files/", localOnly(http.HandlerFunc(s.handleFiles)))
 
-	// Serve generated pages (with live reload injection)
-	s.mux.HandleFunc("/", s.handlePageWithLiveReload)
+	// Serve generated pages (with live reload injection), honoring the
+	// configured redirects and URL normalization the way the hosting
+	// platform would
+	s.route("/", s.redirectMiddleware(s.routingMiddleware(http.HandlerFunc(s.handlePageWithLiveReload))))
 }
 
 // buildSite builds the site and tracks performance
 func (s *Server) buildSite() error {
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+	if err := s.trackBuild(s.site().Build, nil); err != nil {
+		return err
+	}
+	s.markWarm()
+	return nil
+}
+
+// trackBuild runs build, started by changes to files when they are known,
+// records its statistics and notifies clients. Callers must hold buildMu.
+func (s *Server) trackBuild(build func() error, files []string) error {
 	start := time.Now()
 	
 	s.statsMu.Lock()
 	s.stats.BuildCount++
 	s.statsMu.Unlock()
 	
-	err := s.builder.Build()
+	err := build()
 	
-	s.statsMu.Lock()
-	s.stats.LastBuild = time.Now()
-	s.stats.BuildTime = time.Since(start)
-	if err != nil {
-		s.stats.ErrorCount++
-		s.stats.BuildErrors = append(s.stats.BuildErrors, err.Error())
-		// Keep only last 10 errors
-		if len(s.stats.BuildErrors) > 10 {
-			s.stats.BuildErrors = s.stats.BuildErrors[1:]
-		}
-	}
-	s.statsMu.Unlock()
+	s.recordBuild(time.Since(start), files, err)
 	
 	// Notify clients of rebuild
 	if err == nil {
-		s.notifyClients("reload")
+		s.notifyClients(newReloadMessage(MessageReload, nil))
 	} else {
-		s.notifyClients(fmt.Sprintf("error:%s", err.Error()))
+		s.notifyClients(newReloadMessage(MessageError, err.Error()))
 	}
 	
 	return err
 }
 
-// Enhanced file watcher with better performance
+// watchFiles rebuilds the site as sources change
 func (s *Server) watchFiles() {
-	watcher, err := fsnotify.NewWatcher()
+	w, err := watcher.New(s.cfg(), s.configFiles)
 	if err != nil {
-		log.Printf("Failed to create file watcher: %v", err)
+		logging.Errorf("❌ %v", err)
 		return
 	}
-	defer watcher.Close()
-
-	// Directories to watch
-	watchDirs := []string{s.config.ContentDir, s.config.LayoutDir}
-	
-	// Add theme directory if active
-	if s.config.Theme != "" {
-		themeDir := filepath.Join("themes", s.config.Theme)
-		if _, err := os.Stat(themeDir); err == nil {
-			watchDirs = append(watchDirs, themeDir)
-		}
-	}
-	
-	// Only add static dir if it exists
-	if _, err := os.Stat(s.config.StaticDir); err == nil {
-		watchDirs = append(watchDirs, s.config.StaticDir)
-	}
-
-	// Add config file
-	if _, err := os.Stat("config.toml"); err == nil {
-		watcher.Add("config.toml")
-	}
-
-	// Add directories to watcher recursively
-	for _, dir := range watchDirs {
-		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if info.IsDir() {
-				if s.verbose {
-					log.Printf("👀 Watching directory: %s", path)
-				}
-				return watcher.Add(path)
-			}
-			return nil
-		})
-		
-		if err != nil {
-			log.Printf("Error setting up watcher for %s: %v", dir, err)
-		}
-	}
+	defer w.Close()
+	w.SetVerbose(s.verbose)
 
 	s.statsMu.Lock()
-	s.stats.FileWatches = len(watcher.WatchList())
+	s.stats.FileWatches = w.WatchCount()
 	s.statsMu.Unlock()
 
-	log.Printf("👀 File watcher started (watching %d paths)", len(watcher.WatchList()))
+	logging.Infof("👀 File watcher started (watching %d paths)", w.WatchCount())
 
-	// Debounce rebuilds
-	var lastBuild time.Time
-	const debounceTime = 300 * time.Millisecond
+	w.Run(func(change watcher.Change) {
+		s.buildMu.Lock()
+		defer s.buildMu.Unlock()
 
-	for {
-		select {
-		case event, ok := <-watcher.Events:
-			if !ok {
+		if change.ConfigChanged {
+			logging.Infof("⚙️  Configuration changed - reloading...")
+			if err := s.reloadConfig(); err != nil {
+				logging.Errorf("❌ Failed to reload configuration: %v", err)
 				return
 			}
-			
-			// Ignore hidden files and temporary files
-			if strings.Contains(event.Name, "/.") || 
-			   strings.HasSuffix(event.Name, "~") || 
-			   strings.HasSuffix(event.Name, ".tmp") {
-				continue
+			w.SetConfig(s.cfg())
+			if err := s.trackBuild(s.site().Build, change.Files); err != nil {
+				logging.Errorf("❌ Full rebuild failed: %v", err)
+				s.clearRenderCache()
+			} else {
+				s.markWarm()
 			}
-			
-			// Only rebuild on write events and if enough time has passed
-			if event.Op&fsnotify.Write == fsnotify.Write {
-				now := time.Now()
-				if now.Sub(lastBuild) > debounceTime {
-					lastBuild = now
-					log.Printf("🔄 File changed: %s - rebuilding...", event.Name)
-					
-					// Use incremental build for better performance
-					go func() {
-						if err := s.builder.IncrementalBuild([]string{event.Name}); err != nil {
-							log.Printf("❌ Incremental rebuild failed: %v", err)
-							// Fallback to full rebuild
-							if err := s.buildSite(); err != nil {
-								log.Printf("❌ Full rebuild failed: %v", err)
-							}
-						} else {
-							log.Println("✅ Incremental rebuild completed")
-							s.notifyClients("reload")
-						}
-					}()
-				}
+			return
+		}
+
+		if change.StylesOnly() && s.injectStyles(change.Files) {
+			return
+		}
+
+		logging.Infof("🔄 Files changed: %s - rebuilding...", strings.Join(change.Files, ", "))
+
+		// A cold site never finished a full build, so there's nothing to
+		// update incrementally
+		if s.cold() {
+			if err := s.trackBuild(s.site().Build, change.Files); err != nil {
+				logging.Errorf("❌ Full rebuild failed: %v", err)
+				s.clearRenderCache()
+			} else {
+				s.markWarm()
 			}
-			
-		case err, ok := <-watcher.Errors:
-			if !ok {
-				return
+			return
+		}
+
+		// Use incremental build for better performance
+		start := time.Now()
+		if err := s.site().IncrementalBuild(change.Files); err != nil {
+			logging.Errorf("❌ Incremental rebuild failed: %v", err)
+			// Fallback to full rebuild
+			if err := s.trackBuild(s.site().Build, change.Files); err != nil {
+				logging.Errorf("❌ Full rebuild failed: %v", err)
+			} else {
+				s.markWarm()
 			}
-			log.Printf("⚠️ File watcher error: %v", err)
+		} else {
+			logging.Infof("✅ Incremental rebuild completed")
+			s.recordBuild(time.Since(start), change.Files, nil)
+			s.notifyClients(newReloadMessage(MessageReload, nil))
 		}
-	}
+		s.clearRenderCache()
+	})
 }
 
-// WebSocket handler for live reload
-func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	// Upgrade to WebSocket connection
-	// Implementation would use gorilla/websocket or similar
-	
-	clientChan := make(chan string, 10)
-	
-	s.clientsMu.Lock()
-	s.clients[clientChan] = true
-	s.stats.ClientCount = len(s.clients)
-	s.clientsMu.Unlock()
-	
-	defer func() {
-		s.clientsMu.Lock()
-		delete(s.clients, clientChan)
-		s.stats.ClientCount = len(s.clients)
-		s.clientsMu.Unlock()
-		close(clientChan)
-	}()
-	
-	// Keep connection alive and send messages
-	for message := range clientChan {
-		// Send message to WebSocket client
-		if s.verbose {
-			log.Printf("📤 Sending to client: %s", message)
-		}
+// injectStyles updates the changed stylesheets and tells clients to swap
+// them in place. It reports false when the change needs the regular
+// rebuild instead. Callers must hold buildMu.
+func (s *Server) injectStyles(files []string) bool {
+	logging.Infof("🎨 Stylesheets changed: %s", strings.Join(files, ", "))
+	urls, err := s.site().UpdateStyles(files)
+	if err != nil {
+		logging.Errorf("❌ Stylesheet update failed: %v", err)
+		return false
+	}
+	s.clearRenderCache()
+	if len(urls) == 0 {
+		s.notifyClients(newReloadMessage(MessageReload, nil))
+		return true
 	}
+	for _, url := range urls {
+		s.notifyClients(newReloadMessage(MessageCSS, url))
+	}
+	logging.Infof("✅ Injected %d stylesheets without reloading", len(urls))
+	return true
 }
 
-// Notify all connected clients
-func (s *Server) notifyClients(message string) {
-	s.clientsMu.RLock()
-	defer s.clientsMu.RUnlock()
-	
-	for clientChan := range s.clients {
-		select {
-		case clientChan <- message:
-		default:
-			// Channel is full, skip this client
-		}
+// reloadConfig re-reads the configuration file and replaces the builder,
+// keeping the port, host and profiling chosen on the command line. Callers
+// must hold buildMu.
+func (s *Server) reloadConfig() error {
+	cfg, err := config.Load(s.configFiles...)
+	if err != nil {
+		return err
 	}
+	cfg.Port = s.cfg().Port
+	cfg.Host = s.cfg().Host
+	cfg.IsServing = true
+	s.checkProxiesChanged(cfg)
+	if s.cfg().Features.ProfileMode {
+		cfg.Features.ProfileMode = true
+	}
+
+	site := builder.New(cfg)
+	site.SetProgressFunc(s.onBuildProgress)
+	site.SetPrecompress(false)
+	site.SetTrackChanges(true)
+
+	s.stateMu.Lock()
+	s.config = cfg
+	s.builder = site
+	s.stateMu.Unlock()
+	return nil
 }
 
 // Enhanced page handler with live reload injection
 func (s *Server) handlePageWithLiveReload(w http.ResponseWriter, r *http.Request) {
-	s.statsMu.Lock()
-	s.stats.Requests++
-	s.stats.PageViews[r.URL.Path]++
-	s.statsMu.Unlock()
-	
+	if s.lazy && s.serveLazy(w, r) {
+		return
+	}
+
 	// Clean the path
 	path := strings.TrimPrefix(r.URL.Path, "/")
 	if path == "" {
 		path = "index"
 	}
 
-	// Try to find the page file
-	pagePath := filepath.Join(s.config.PublicDir, path, "index.html")
+	// Files published next to pages, such as bundle resources, are served as they are
+	if filePath := util.OutputPath(s.cfg().PublicDir, path); !strings.EqualFold(filepath.Ext(filePath), ".html") {
+		if info, err := os.Stat(filePath); err == nil && !info.IsDir() {
+			http.ServeFile(w, r, filePath)
+			return
+		}
+	}
+
+	// Try to find the page file, which may be an HTML file published as it
+	// is, such as a verification file from the static directory
+	pagePath := util.OutputPath(s.cfg().PublicDir, path, "index.html")
+	if filePath := util.OutputPath(s.cfg().PublicDir, path); strings.EqualFold(filepath.Ext(filePath), ".html") {
+		if info, err := os.Stat(filePath); err == nil && !info.IsDir() {
+			pagePath = filePath
+		}
+	}
 	
 	if _, err := os.Stat(pagePath); os.IsNotExist(err) {
-		pagePath = filepath.Join(s.config.PublicDir, path+".html")
+		pagePath = util.OutputPath(s.cfg().PublicDir, path+".html")
 	}
 
 	if _, err := os.Stat(pagePath); os.IsNotExist(err) {
@@ -353,116 +504,154 @@ func (s *Server) handlePageWithLiveReload(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Inject live reload script
-	htmlContent := string(content)
+	htmlContent := s.injectLiveReload(string(content))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Write([]byte(htmlContent))
+}
+
+// injectLiveReload adds the live reload client script before </body>
+func (s *Server) injectLiveReload(htmlContent string) string {
 	if strings.Contains(htmlContent, "</body>") {
-		liveReloadScript := `
-<script>
-(function() {
-    const ws = new WebSocket('ws://localhost:` + fmt.Sprintf("%d", s.port) + `/ws/reload');
-    
-    ws.onmessage = function(event) {
-        const message = event.data;
-        if (message === 'reload') {
-            console.log('🔄 Reloading page...');
-            window.location.reload();
-        } else if (message.startsWith('error:')) {
-            console.error('❌ Build error:', message.slice(6));
-            // Show error notification
-            showErrorNotification(message.slice(6));
-        }
-    };
-    
-    ws.onopen = function() {
-        console.log('🔗 Live reload connected');
-    };
-    
-    ws.onclose = function() {
-        console.log('❌ Live reload disconnected');
-        // Try to reconnect after 1 second
-        setTimeout(() => window.location.reload(), 1000);
-    };
-    
-    function showErrorNotification(error) {
-        const notification = document.createElement('div');
-        notification.style.cssText = ` + "`" + `
-            position: fixed;
-            top: 20px;
-            right: 20px;
-            background: #ff4444;
-            color: white;
-            padding: 15px;
-            border-radius: 5px;
-            z-index: 10000;
-            max-width: 400px;
-            font-family: monospace;
-            font-size: 12px;
-        ` + "`" + `;
-        notification.textContent = 'Build Error: ' + error;
-        document.body.appendChild(notification);
-        
-        setTimeout(() => {
-            if (notification.parentNode) {
-                notification.parentNode.removeChild(notification);
-            }
-        }, 5000);
-    }
-})();
-</script>`
-		
-		htmlContent = strings.Replace(htmlContent, "</body>", liveReloadScript+"\n</body>", 1)
+		tag := fmt.Sprintf(`<script src="%s?v=%d"></script>`, LiveReloadScriptPath, LiveReloadProtocol)
+		htmlContent = strings.Replace(htmlContent, "</body>", tag+"\n</body>", 1)
+	}
+	return htmlContent
+}
+
+// serveLazy renders a page on demand while the site is still cold. It returns
+// false when the path isn't a content page so normal handling can continue.
+func (s *Server) serveLazy(w http.ResponseWriter, r *http.Request) bool {
+	s.renderMu.RLock()
+	warm := s.warm
+	html, cached := s.renderCache[r.URL.Path]
+	s.renderMu.RUnlock()
+
+	if warm {
+		return false
+	}
+
+	if !cached {
+		var err error
+		html, err = s.site().RenderOnDemand(r.URL.Path)
+		if errors.Is(err, builder.ErrPageNotFound) {
+			return false
+		}
+		if err != nil {
+			// Most likely cross-page data that isn't available yet; the
+			// placeholder refreshes until the background build catches up
+			logging.Debugf("⏳ Lazy render of %s deferred: %v", r.URL.Path, err)
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(buildingPlaceholder))
+			return true
+		}
+		s.renderMu.Lock()
+		s.renderCache[r.URL.Path] = html
+		s.renderMu.Unlock()
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	w.Write([]byte(htmlContent))
+	w.Write([]byte(s.injectLiveReload(html)))
+	return true
+}
+
+// pageWarmth returns how many pages are rendered (warm) and still pending (cold)
+func (s *Server) pageWarmth() (int, int) {
+	s.renderMu.RLock()
+	defer s.renderMu.RUnlock()
+
+	if !s.lazy || s.warm {
+		return len(s.site().GetPages()), 0
+	}
+	warm := len(s.renderCache)
+	cold := s.contentFiles - warm
+	if cold < 0 {
+		cold = 0
+	}
+	return warm, cold
+}
+
+// clearRenderCache drops lazily rendered pages after a rebuild
+func (s *Server) clearRenderCache() {
+	s.renderMu.Lock()
+	s.renderCache = make(map[string]string)
+	s.renderMu.Unlock()
 }
 
+// markWarm records a successful full build: every page is in the output
+// directory, so lazily rendered ones are dropped and no longer needed
+func (s *Server) markWarm() {
+	s.renderMu.Lock()
+	wasCold := s.lazy && !s.warm
+	s.warm = true
+	s.renderCache = make(map[string]string)
+	s.renderMu.Unlock()
+	if wasCold {
+		logging.Infof("🔥 Build complete, all pages warm")
+	}
+}
+
+// cold reports whether a lazy server is still waiting for its first
+// successful full build
+func (s *Server) cold() bool {
+	s.renderMu.RLock()
+	defer s.renderMu.RUnlock()
+	return s.lazy && !s.warm
+}
+
+// buildingPlaceholder is shown for pages that can't be rendered until the
+// background build completes
+const buildingPlaceholder = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta http-equiv="refresh" content="1">
+    <title>Building…</title>
+    <style>
+        body { font-family: Arial, sans-serif; text-align: center; padding: 50px; color: #666; }
+    </style>
+</head>
+<body>
+    <h1>Building…</h1>
+    <p>This page will appear as soon as the site has finished building.</p>
+</body>
+</html>`
+
 // Enhanced API endpoints
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
-	s.statsMu.RLock()
-	stats := *s.stats
-	s.statsMu.RUnlock()
+	stats := s.statsSnapshot()
+	stats.WarmPages, stats.ColdPages = s.pageWarmth()
 	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
 
-func (s *Server) handlePages(w http.ResponseWriter, r *http.Request) {
-	pages := s.builder.GetPages()
-	
-	type PageInfo struct {
-		Title    string `json:"title"`
-		URL      string `json:"url"`
-		WordCount int   `json:"word_count"`
-		ReadingTime int `json:"reading_time"`
-		LastModified time.Time `json:"last_modified"`
-	}
-	
-	var pageInfos []PageInfo
-	for _, page := range pages {
-		pageInfos = append(pageInfos, PageInfo{
-			Title: page.Title,
-			URL: page.URL,
-			WordCount: page.WordCount,
-			ReadingTime: page.ReadingTime,
-			LastModified: page.ParsedDate,
-		})
-	}
-	
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(pageInfos)
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.cfg())
 }
 
-func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+// writeJSON encodes v as the response body, reporting encoding failures
+// instead of sending a truncated body
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		logging.Errorf("❌ Failed to encode JSON response: %v", err)
+		http.Error(w, "failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data = append(data, '\n')
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(s.config)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
 }
 
 // Admin panel handler
 func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
 	html := `<!DOCTYPE html>
-<html>
+<html lang="en">
 <head>
  <meta charset="UTF-8">
 <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.5.0/css/all.min.css">
@@ -477,6 +666,13 @@ func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
         .stat-label { color: #666; }
         button { background: #007bff; color: white; border: none; padding: 10px 20px; border-radius: 4px; cursor: pointer; }
         button:hover { background: #0056b3; }
+        select { padding: 9px; border-radius: 4px; border: 1px solid #ccc; }
+        .pager { display: flex; gap: 10px; align-items: center; margin-bottom: 10px; }
+        .pager input { flex: 1; padding: 9px; border-radius: 4px; border: 1px solid #ccc; }
+        .pager button:disabled { background: #ccc; cursor: default; }
+        .progress { display: none; height: 8px; background: #e9ecef; border-radius: 4px; margin-top: 15px; overflow: hidden; }
+        .progress-bar { height: 100%; width: 0; background: #007bff; transition: width 0.2s; }
+        .progress-label { color: #666; font-size: 0.9em; margin-top: 5px; }
         .error { background: #fff5f5; border: 1px solid #feb2b2; color: #e53e3e; padding: 10px; border-radius: 4px; margin: 10px 0; }
     </style>
 </head>
@@ -486,9 +682,20 @@ func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
         
         <div class="card">
             <h2>Quick Actions</h2>
+            <select id="rebuild-scope">
+                <option value="all">Everything</option>
+                <option value="content">Content</option>
+                <option value="templates">Templates</option>
+            </select>
             <button onclick="rebuild()"><i class="fa-solid fa-repeat"></i> Rebuild Site</button>
             <button onclick="clearCache()"><i class="fa-solid fa-trash"></i> Clear Cache</button>
             <button onclick="location.reload()"><i class="fa-solid fa-rotate"></i> Refresh Panel</button>
+            <button onclick="location.href='/dev/files/'"><i class="fa-solid fa-folder-open"></i> Browse Output</button>
+            <button onclick="location.href='/__vango/api/build-log'"><i class="fa-solid fa-download"></i> Download Build Log</button>
+            <div class="progress" id="build-progress">
+                <div class="progress-bar" id="build-progress-bar"></div>
+            </div>
+            <div class="progress-label" id="build-progress-label"></div>
         </div>
         
         <div class="card">
@@ -498,6 +705,18 @@ func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
         
         <div class="card">
             <h2><i class="fa-solid fa-file"></i> Pages</h2>
+            <div class="pager">
+                <input type="search" id="page-search" placeholder="Search titles..." oninput="searchPages()">
+                <select id="page-sort" onchange="searchPages()">
+                    <option value="">Content order</option>
+                    <option value="title">By title</option>
+                    <option value="date">By date</option>
+                    <option value="weight">Heaviest first</option>
+                </select>
+                <button onclick="changePage(-1)" id="page-prev"><i class="fa-solid fa-chevron-left"></i></button>
+                <span id="page-range"></span>
+                <button onclick="changePage(1)" id="page-next"><i class="fa-solid fa-chevron-right"></i></button>
+            </div>
             <div id="pages"></div>
         </div>
         
@@ -509,7 +728,7 @@ func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
     
     <script>
         async function loadStats() {
-            const response = await fetch('/api/stats');
+            const response = await fetch('/__vango/api/stats');
             const stats = await response.json();
             
             document.getElementById('stats').innerHTML = ` + "`" + `
@@ -529,12 +748,46 @@ func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
                     <div class="stat-value">${stats.file_watches}</div>
                     <div class="stat-label">Watched Files</div>
                 </div>
+                <div class="stat">
+                    <div class="stat-value">${stats.warm_pages}</div>
+                    <div class="stat-label">Warm Pages</div>
+                </div>
+                <div class="stat">
+                    <div class="stat-value">${stats.cold_pages}</div>
+                    <div class="stat-label">Cold Pages</div>
+                </div>
+                <div class="stat">
+                    <div class="stat-value">${formatBytes(stats.bytes_served)}</div>
+                    <div class="stat-label">Bytes Served</div>
+                </div>
+                <div class="stat">
+                    <div class="stat-value">${stats.p95_latency_ms.toFixed(1)} ms</div>
+                    <div class="stat-label">p95 Latency</div>
+                </div>
             ` + "`" + `;
             
-            if (stats.build_errors && stats.build_errors.length > 0) {
-                const errorsHtml = stats.build_errors.map(error => 
-                    ` + "`" + `<div class="error">${error}</div>` + "`" + `
+            if (stats.top_pages && stats.top_pages.length > 0) {
+                const rows = stats.top_pages.map(page =>
+                    ` + "`" + `<tr><td><a href="${escapeHtml(encodeURI(page.path))}" target="_blank">${escapeHtml(page.path)}</a></td><td>${page.views}</td></tr>` + "`" + `
                 ).join('');
+                const other = stats.other_page_views > 0
+                    ? ` + "`" + `<tr><td><em>other</em></td><td>${stats.other_page_views}</td></tr>` + "`" + `
+                    : '';
+                document.getElementById('stats').innerHTML += ` + "`" + `
+                    <div style="grid-column: 1 / -1;">
+                        <h3>Top Pages</h3>
+                        <table style="width: 100%;">${rows}${other}</table>
+                    </div>
+                ` + "`" + `;
+            }
+            
+            if (stats.build_errors && stats.build_errors.length > 0) {
+                const errorsHtml = stats.build_errors.slice().reverse().map(error => {
+                    const files = error.files && error.files.length > 0
+                        ? ` + "`" + `<br><small>${escapeHtml(error.files.join(', '))}</small>` + "`" + `
+                        : '';
+                    return ` + "`" + `<div class="error"><small>${new Date(error.time).toLocaleString()}</small><br>${escapeHtml(error.message)}${files}</div>` + "`" + `;
+                }).join('');
                 document.getElementById('stats').innerHTML += ` + "`" + `
                     <div style="grid-column: 1 / -1;">
                         <h3>Recent Build Errors</h3>
@@ -544,37 +797,114 @@ func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
             }
         }
         
+        function escapeHtml(text) {
+            const div = document.createElement('div');
+            div.textContent = text;
+            return div.innerHTML;
+        }
+        
+        function formatBytes(bytes) {
+            const units = ['B', 'KB', 'MB', 'GB'];
+            let i = 0;
+            while (bytes >= 1024 && i < units.length - 1) {
+                bytes /= 1024;
+                i++;
+            }
+            return (i === 0 ? bytes : bytes.toFixed(1)) + ' ' + units[i];
+        }
+        
+        const pageLimit = 50;
+        let pageOffset = 0;
+        let searchTimer;
+        
         async function loadPages() {
-            const response = await fetch('/api/pages');
-            const pages = await response.json();
+            const query = new URLSearchParams({ limit: pageLimit, offset: pageOffset });
+            const search = document.getElementById('page-search').value.trim();
+            if (search) {
+                query.set('q', search);
+            }
+            const sort = document.getElementById('page-sort').value;
+            if (sort) {
+                query.set('sort', sort);
+            }
+            const response = await fetch('/__vango/api/pages?' + query);
+            const list = await response.json();
             
-            document.getElementById('pages').innerHTML = pages.map(page => ` + "`" + `
+            const first = list.total === 0 ? 0 : list.offset + 1;
+            document.getElementById('page-range').textContent = ` + "`" + `${first}–${list.offset + list.pages.length} of ${list.total}` + "`" + `;
+            document.getElementById('page-prev').disabled = list.offset === 0;
+            document.getElementById('page-next').disabled = list.offset + list.pages.length >= list.total;
+            document.getElementById('pages').innerHTML = list.pages.map(page => ` + "`" + `
                 <div style="border-bottom: 1px solid #eee; padding: 10px 0;">
-                    <strong>${page.title}</strong><br>
-                    <a href="${page.url}" target="_blank">${page.url}</a><br>
-                    <small>${page.word_count} words • ${page.reading_time} min read</small>
+                    <strong>${escapeHtml(page.title)}</strong>${page.draft ? ' <em>(draft)</em>' : ''}<br>
+                    <a href="${escapeHtml(encodeURI(page.url))}" target="_blank">${escapeHtml(page.url)}</a><br>
+                    <small>${page.section ? escapeHtml(page.section) + ' • ' : ''}${page.word_count} words • ${page.reading_time} min read${page.page_weight ? ' • ' + formatBytes(page.page_weight) : ''}${page.over_budget ? ' <strong style="color: #e53e3e;">over budget</strong>' : ''}${page.tags ? ' • tags: ' + escapeHtml(page.tags.join(', ')) : ''}${page.params ? ' • params: ' + escapeHtml(Object.keys(page.params).join(', ')) : ''}</small>
                 </div>
             ` + "`" + `).join('');
         }
         
+        function changePage(direction) {
+            pageOffset = Math.max(0, pageOffset + direction * pageLimit);
+            loadPages();
+        }
+        
+        function searchPages() {
+            clearTimeout(searchTimer);
+            searchTimer = setTimeout(() => {
+                pageOffset = 0;
+                loadPages();
+            }, 250);
+        }
+        
         async function loadConfig() {
-            const response = await fetch('/api/config');
+            const response = await fetch('/__vango/api/config');
             const config = await response.json();
             document.getElementById('config').textContent = JSON.stringify(config, null, 2);
         }
         
+        let buildId = 0;
+        
         async function rebuild() {
-            const response = await fetch('/api/rebuild', { method: 'POST' });
-            if (response.ok) {
-                alert('✅ Site rebuilt successfully!');
-                loadStats();
-            } else {
-                alert('❌ Rebuild failed!');
+            const scope = document.getElementById('rebuild-scope').value;
+            const response = await fetch('/__vango/api/rebuild', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ scope: scope })
+            });
+            if (!response.ok) {
+                alert('❌ Rebuild failed: ' + await response.text());
+                return;
             }
+            const result = await response.json();
+            buildId = result.build_id;
+            showProgress('queued', 0);
         }
         
+        function showProgress(stage, percent) {
+            document.getElementById('build-progress').style.display = 'block';
+            document.getElementById('build-progress-bar').style.width = percent + '%';
+            document.getElementById('build-progress-label').textContent = ` + "`" + `Build #${buildId}: ${stage} (${percent}%)` + "`" + `;
+        }
+        
+        const buildEvents = new EventSource('/__vango/api/build-events');
+        buildEvents.onmessage = (e) => {
+            const event = JSON.parse(e.data);
+            if (event.id === 0 || event.id < buildId) {
+                return;
+            }
+            buildId = event.id;
+            showProgress(event.stage, event.percent);
+            if (event.done) {
+                document.getElementById('build-progress-label').textContent = event.error
+                    ? ` + "`" + `❌ Build #${event.id} failed: ${event.error}` + "`" + `
+                    : ` + "`" + `✅ Build #${event.id} complete` + "`" + `;
+                loadStats();
+                loadPages();
+            }
+        };
+        
         async function clearCache() {
-            const response = await fetch('/api/clear-cache', { method: 'POST' });
+            const response = await fetch('/__vango/api/clear-cache', { method: 'POST' });
             if (response.ok) {
                 alert('✅ Cache cleared!');
             }
@@ -603,6 +933,72 @@ func (s *Server) cacheMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// Middleware stripping the BaseURL path of subpath deployments, so the
+// site is served at / and under the prefix its links use alike
+func (s *Server) basePathMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := s.cfg().BaseURLPath()
+		if prefix != "" && (r.URL.Path == prefix || strings.HasPrefix(r.URL.Path, prefix+"/")) {
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = new(url.URL)
+			*r2.URL = *r.URL
+			r2.URL.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+			r2.URL.RawPath = ""
+			r = r2
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Middleware applying the [[redirects]] table. Unforced rules only apply
+// when no generated page exists at the requested path, like on Netlify.
+func (s *Server) redirectMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, rule := range s.cfg().Redirects {
+			target, ok := rule.Match(r.URL.Path)
+			if !ok {
+				continue
+			}
+			if !rule.Force && s.pageExists(r.URL.Path) {
+				break
+			}
+
+			switch {
+			case rule.IsRedirect():
+				http.Redirect(w, r, target, rule.Status)
+				return
+			case rule.Status == 200 && strings.HasPrefix(target, "/"):
+				r.URL.Path = target
+			case rule.Status == http.StatusNotFound || rule.Status == http.StatusGone:
+				s.handle404(w, r)
+				return
+			}
+			break
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// pageExists reports whether a generated page exists for the URL path
+func (s *Server) pageExists(urlPath string) bool {
+	path := strings.TrimPrefix(urlPath, "/")
+	if path == "" {
+		path = "index"
+	}
+	candidates := []string{
+		util.OutputPath(s.cfg().PublicDir, path, "index.html"),
+		util.OutputPath(s.cfg().PublicDir, path+".html"),
+		util.OutputPath(s.cfg().PublicDir, path),
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
 // Logging middleware
 func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -612,11 +1008,19 @@ func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 		
 		next.ServeHTTP(wrapped, r)
+		duration := time.Since(start)
 		
-		if s.verbose {
-			duration := time.Since(start)
-			log.Printf("%s %s %d %v", r.Method, r.URL.Path, wrapped.statusCode, duration)
-		}
+		s.statsMu.Lock()
+		s.recordRequest(r, wrapped, duration)
+		s.statsMu.Unlock()
+		s.writeAccessLog(r, wrapped, start, duration)
+		
+		logging.Logger().Debug("request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", wrapped.statusCode,
+			"duration", duration,
+		)
 	})
 }
 
@@ -638,16 +1042,69 @@ func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"status": "valid"}`))
 }
 
+// handleTemplateDebug explains template resolution for ?path= and lists all
+// loaded templates with their source files. Add ?format=json for JSON.
 func (s *Server) handleTemplateDebug(w http.ResponseWriter, r *http.Request) {
-	// Template debugging information
-	w.Header().Set("Content-Type", "text/plain")
-	w.Write([]byte("Template debug information..."))
+	report := templateDebugReport{Templates: s.site().TemplateSources()}
+
+	if path := r.URL.Query().Get("path"); path != "" {
+		page, resolution, err := s.site().ResolveTemplate(path)
+		if errors.Is(err, builder.ErrPageNotFound) {
+			http.Error(w, fmt.Sprintf("No page found for %s", path), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to resolve %s: %v", path, err), http.StatusInternalServerError)
+			return
+		}
+		report.Path = path
+		report.File = page.FilePath
+		report.Resolution = resolution
+		report.DataKeys = tmpl.TemplateDataKeys(page)
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	report.writeText(w)
+}
+
+// performanceReport is the /dev/performance response
+type performanceReport struct {
+	Enabled      bool                  `json:"enabled"`
+	BuildTime    time.Duration         `json:"build_time"`
+	BuildCount   int64                 `json:"build_count"`
+	P95LatencyMs float64               `json:"p95_latency_ms"`
+	Metrics      *builder.BuildMetrics `json:"metrics,omitempty"`
+	Hint         string                `json:"hint,omitempty"`
 }
 
+// handlePerformance reports build timings and, with profiling enabled, the
+// slowest templates and pages. ?n= sets how many of each are listed.
 func (s *Server) handlePerformance(w http.ResponseWriter, r *http.Request) {
-	// Performance metrics and profiling
+	n := 10
+	if v, err := strconv.Atoi(r.URL.Query().Get("n")); err == nil && v > 0 {
+		n = v
+	}
+
+	stats := s.statsSnapshot()
+	report := performanceReport{
+		BuildTime:    stats.BuildTime,
+		BuildCount:   stats.BuildCount,
+		P95LatencyMs: stats.P95LatencyMs,
+		Metrics:      s.site().Metrics(n),
+	}
+	report.Enabled = report.Metrics != nil
+	if !report.Enabled {
+		report.Hint = "start the server with --profile or set profileMode under [features] for template and page timings"
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"performance": "metrics"}`))
+	json.NewEncoder(w).Encode(report)
 }
 
 // handlePage serves individual pages
@@ -659,16 +1116,16 @@ func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Try to find the page file
-	pagePath := filepath.Join(s.config.PublicDir, path, "index.html")
+	pagePath := filepath.Join(s.cfg().PublicDir, path, "index.html")
 	
 	// If not found, try without subdirectory
 	if _, err := os.Stat(pagePath); os.IsNotExist(err) {
-		pagePath = filepath.Join(s.config.PublicDir, path+".html")
+		pagePath = filepath.Join(s.cfg().PublicDir, path+".html")
 	}
 
 	// If still not found, try with index.html
 	if _, err := os.Stat(pagePath); os.IsNotExist(err) {
-		pagePath = filepath.Join(s.config.PublicDir, "index.html")
+		pagePath = filepath.Join(s.cfg().PublicDir, "index.html")
 	}
 
 	// Serve the file if it exists
@@ -681,57 +1138,39 @@ func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
 	s.handle404(w, r)
 }
 
-// handleRebuild rebuilds the site
-func (s *Server) handleRebuild(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	fmt.Println("Rebuilding site...")
-	if err := s.builder.Build(); err != nil {
-		http.Error(w, fmt.Sprintf("Build failed: %v", err), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status": "success", "message": "Site rebuilt successfully"}`))
-}
-
 // handleStatus returns server status
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
-	pages := s.builder.GetPages()
-	
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	
-	response := fmt.Sprintf(`{
+	pages := s.site().GetPages()
+	writeJSON(w, map[string]interface{}{
 		"status": "running",
-		"pages": %d,
-		"config": {
-			"title": "%s",
-			"baseURL": "%s"
-		}
-	}`, len(pages), s.config.Title, s.config.BaseURL)
-	
-	w.Write([]byte(response))
+		"pages":  len(pages),
+		"build":  s.buildStatus(),
+		"config": map[string]string{
+			"title":   s.cfg().Title,
+			"baseURL": s.cfg().BaseURL,
+		},
+	})
 }
 
 // handle404 serves a 404 page
 func (s *Server) handle404(w http.ResponseWriter, r *http.Request) {
-	// Try to serve custom 404 page
-	notFoundPath := filepath.Join(s.config.PublicDir, "404.html")
-	if _, err := os.Stat(notFoundPath); err == nil {
+	suggestions := s.suggestionsHTML(s.suggestURLs(r.URL.Path))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	// Try to serve custom 404 page, with the suggestions where live reload
+	// would be injected
+	notFoundPath := filepath.Join(s.cfg().PublicDir, "404.html")
+	if custom, err := os.ReadFile(notFoundPath); err == nil {
+		html := strings.Replace(string(custom), "</body>", suggestions+"\n</body>", 1)
 		w.WriteHeader(http.StatusNotFound)
-		http.ServeFile(w, r, notFoundPath)
+		w.Write([]byte(html))
 		return
 	}
 
 	// Serve default 404
 	w.WriteHeader(http.StatusNotFound)
 	html := `<!DOCTYPE html>
-<html>
+<html lang="en">
 <head>
     <title>404 - Page Not Found</title>
     <style>
@@ -745,11 +1184,11 @@ func (s *Server) handle404(w http.ResponseWriter, r *http.Request) {
 <body>
     <h1>404 - Page Not Found</h1>
     <p>The page you requested could not be found.</p>
+    ` + suggestions + `
     <p><a href="/">← Back to Home</a></p>
 </body>
 </html>`
 	
-	w.Header().Set("Content-Type", "text/html")
 	w.Write([]byte(html))
 }
 
@@ -764,17 +1203,51 @@ func (s *Server) logRequest(next http.HandlerFunc) http.HandlerFunc {
 		next(wrapped, r)
 		
 		duration := time.Since(start)
-		log.Printf("%s %s %d %v", r.Method, r.URL.Path, wrapped.statusCode, duration)
+		logging.Logger().Info("request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", wrapped.statusCode,
+			"duration", duration,
+		)
 	}
 }
 
-// responseWriter wraps http.ResponseWriter to capture status code
+// responseWriter wraps http.ResponseWriter to capture status code and size
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	bytes      int64
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Flush lets streaming handlers flush through the wrapper
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the live reload endpoint take over the connection
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer can't be hijacked")
+	}
+	rw.statusCode = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,367 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"vango/internal/version"
+)
+
+const (
+	// maxPageViews bounds the PageViews map; once full, the least viewed
+	// paths are folded into the otherPageViews bucket
+	maxPageViews = 200
+	// otherPageViews is the PageViews key for paths that fell out of the top
+	otherPageViews = "other"
+	// topPagesShown is how many pages /api/stats lists under top_pages
+	topPagesShown = 10
+	// latencySamples is the size of the ring buffer used for p95 latency
+	latencySamples = 1000
+	// maxBuildErrors is how many recent build errors are kept
+	maxBuildErrors = 10
+	// maxBuildErrorLength bounds a single kept build error message
+	maxBuildErrorLength = 2000
+	// maxBuildRecords is how many recent builds the build log keeps
+	maxBuildRecords = 20
+)
+
+// Last build statuses reported by /healthz and /api/status
+const (
+	buildPending = "pending"
+	buildOK      = "ok"
+	buildFailed  = "failed"
+)
+
+// BuildStatus is the outcome of the most recent build
+type BuildStatus struct {
+	Status     string    `json:"status"`
+	Time       time.Time `json:"time,omitempty"`
+	DurationMs float64   `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// BuildError is a failed build kept for the admin panel
+type BuildError struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+	Files   []string  `json:"files,omitempty"` // Changed files that started the build
+}
+
+// BuildRecord is a finished build kept for the build log
+type BuildRecord struct {
+	Time       time.Time `json:"time"`
+	DurationMs float64   `json:"duration_ms"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Files      []string  `json:"files,omitempty"` // Changed files that started the build
+}
+
+// PageViewCount is a page path and its number of views
+type PageViewCount struct {
+	Path  string `json:"path"`
+	Views int64  `json:"views"`
+}
+
+// internalPrefixes are dev server endpoints that never count as page views
+var internalPrefixes = []string{"/static/", "/theme/", "/api/", "/ws/", "/admin", "/dev/", "/healthz", "/__vango/"}
+
+// isPageView reports whether a finished request was an HTML page view
+func isPageView(r *http.Request, status int, contentType string) bool {
+	if r.Method != http.MethodGet || status != http.StatusOK {
+		return false
+	}
+	for _, prefix := range internalPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+	return strings.HasPrefix(contentType, "text/html")
+}
+
+// recordRequest updates the request statistics for a finished request.
+// Callers must hold statsMu.
+func (s *Server) recordRequest(r *http.Request, rw *responseWriter, duration time.Duration) {
+	s.stats.Requests++
+	s.stats.BytesServed += rw.bytes
+
+	if len(s.latencies) < latencySamples {
+		s.latencies = append(s.latencies, duration)
+	} else {
+		s.latencies[s.latencyNext] = duration
+	}
+	s.latencyNext = (s.latencyNext + 1) % latencySamples
+
+	if s.isProxied(r.URL.Path) || !isPageView(r, rw.statusCode, rw.Header().Get("Content-Type")) {
+		return
+	}
+	path := r.URL.Path
+	if _, ok := s.stats.PageViews[path]; !ok && len(s.stats.PageViews) >= maxPageViews {
+		s.prunePageViews()
+		if len(s.stats.PageViews) >= maxPageViews {
+			path = otherPageViews
+		}
+	}
+	s.stats.PageViews[path]++
+}
+
+// prunePageViews folds the least viewed half of the tracked paths into the
+// other bucket. Callers must hold statsMu.
+func (s *Server) prunePageViews() {
+	ranked := topPageViews(s.stats.PageViews, 0)
+	for _, pv := range ranked[maxPageViews/2:] {
+		delete(s.stats.PageViews, pv.Path)
+		s.stats.PageViews[otherPageViews] += pv.Views
+	}
+}
+
+// topPageViews returns the n most viewed paths, excluding the other bucket.
+// n <= 0 returns every path.
+func topPageViews(views map[string]int64, n int) []PageViewCount {
+	ranked := make([]PageViewCount, 0, len(views))
+	for path, count := range views {
+		if path != otherPageViews {
+			ranked = append(ranked, PageViewCount{Path: path, Views: count})
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Views != ranked[j].Views {
+			return ranked[i].Views > ranked[j].Views
+		}
+		return ranked[i].Path < ranked[j].Path
+	})
+	if n > 0 && len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// p95Latency returns the 95th percentile of the sampled request latencies.
+// Callers must hold statsMu.
+func (s *Server) p95Latency() time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), s.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(len(sorted)*95-1)/100]
+}
+
+// recordBuild records the outcome of a finished full or incremental build,
+// started by changes to files when they are known
+func (s *Server) recordBuild(duration time.Duration, files []string, err error) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	now := time.Now()
+	previous := s.stats.LastBuildStatus
+	s.stats.LastBuild = now
+	s.stats.BuildTime = duration
+	record := BuildRecord{
+		Time:       now,
+		DurationMs: float64(duration) / float64(time.Millisecond),
+		Status:     buildOK,
+		Files:      append([]string(nil), files...),
+	}
+	defer func() {
+		s.builds = append(s.builds, record)
+		if len(s.builds) > maxBuildRecords {
+			s.builds = append([]BuildRecord(nil), s.builds[len(s.builds)-maxBuildRecords:]...)
+		}
+	}()
+	if err == nil {
+		s.stats.LastBuildStatus = buildOK
+		s.stats.LastBuildError = ""
+		if previous == buildFailed {
+			s.notifyDesktop("VanGo build fixed", "The site builds again")
+		}
+		return
+	}
+
+	message := err.Error()
+	if len(message) > maxBuildErrorLength {
+		message = message[:maxBuildErrorLength] + "…"
+	}
+	record.Status, record.Error = buildFailed, message
+	s.stats.ErrorCount++
+	s.stats.LastBuildStatus = buildFailed
+	s.stats.LastBuildError = message
+	s.stats.BuildErrors = append(s.stats.BuildErrors, BuildError{Time: now, Message: message, Files: record.Files})
+	if len(s.stats.BuildErrors) > maxBuildErrors {
+		s.stats.BuildErrors = append([]BuildError(nil), s.stats.BuildErrors[len(s.stats.BuildErrors)-maxBuildErrors:]...)
+	}
+	s.notifyDesktop("VanGo build failed", firstLine(message))
+}
+
+// buildLog is the build log the admin panel downloads for bug reports
+type buildLog struct {
+	ExportedAt  time.Time     `json:"exported_at"`
+	Version     string        `json:"version"`
+	Site        string        `json:"site"`
+	BuildCount  int64         `json:"build_count"`
+	ErrorCount  int64         `json:"error_count"`
+	BuildErrors []BuildError  `json:"build_errors"`
+	Builds      []BuildRecord `json:"builds"`
+}
+
+// handleBuildLog sends the recent build errors and builds as a JSON file
+func (s *Server) handleBuildLog(w http.ResponseWriter, r *http.Request) {
+	s.statsMu.RLock()
+	log := buildLog{
+		ExportedAt:  time.Now(),
+		Version:     version.Get().Version,
+		Site:        s.cfg().Title,
+		BuildCount:  s.stats.BuildCount,
+		ErrorCount:  s.stats.ErrorCount,
+		BuildErrors: append([]BuildError{}, s.stats.BuildErrors...),
+		Builds:      append([]BuildRecord{}, s.builds...),
+	}
+	s.statsMu.RUnlock()
+
+	filename := "vango-build-log-" + log.ExportedAt.Format("20060102-150405") + ".json"
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, log)
+}
+
+// buildStatus returns the outcome of the most recent build
+func (s *Server) buildStatus() BuildStatus {
+	s.statsMu.RLock()
+	defer s.statsMu.RUnlock()
+
+	status := BuildStatus{Status: s.stats.LastBuildStatus, Error: s.stats.LastBuildError}
+	if status.Status != buildPending {
+		status.Time = s.stats.LastBuild
+		status.DurationMs = float64(s.stats.BuildTime) / float64(time.Millisecond)
+	}
+	return status
+}
+
+// handleHealthz reports that the server is up, with its uptime and the
+// last build's outcome, for supervisors and container health checks. It
+// answers 200 even when the last build failed, since the server itself is
+// still serving; tooling that cares checks build.status.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	s.statsMu.RLock()
+	uptime := time.Since(s.stats.StartTime)
+	s.statsMu.RUnlock()
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, map[string]interface{}{
+		"status":         "ok",
+		"uptime_seconds": int64(uptime / time.Second),
+		"build":          s.buildStatus(),
+	})
+}
+
+// statsSnapshot copies the current statistics so they can be encoded
+// without holding statsMu
+func (s *Server) statsSnapshot() ServerStats {
+	s.statsMu.RLock()
+	defer s.statsMu.RUnlock()
+
+	stats := *s.stats
+	stats.PageViews = make(map[string]int64, len(s.stats.PageViews))
+	for path, count := range s.stats.PageViews {
+		stats.PageViews[path] = count
+	}
+	stats.BuildErrors = append([]BuildError(nil), s.stats.BuildErrors...)
+	stats.TopPages = topPageViews(stats.PageViews, topPagesShown)
+	stats.OtherPageViews = stats.PageViews[otherPageViews]
+	stats.P95LatencyMs = float64(s.p95Latency()) / float64(time.Millisecond)
+
+	s.clientsMu.RLock()
+	stats.ClientCount = len(s.clients)
+	s.clientsMu.RUnlock()
+	return stats
+}
+
+// SetAccessLog writes an access log line for every request to path, in
+// combined log format or, with format "json", as JSON lines
+func (s *Server) SetAccessLog(path, format string) error {
+	switch format {
+	case "", "combined", "json":
+	default:
+		return fmt.Errorf("unknown access log format %q (use combined or json)", format)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open access log: %w", err)
+	}
+	s.accessLog = file
+	s.accessLogJSON = format == "json"
+	return nil
+}
+
+// accessLogEntry is a single JSON lines access log record
+type accessLogEntry struct {
+	Time       time.Time `json:"time"`
+	RemoteAddr string    `json:"remote_addr"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Proto      string    `json:"proto"`
+	Status     int       `json:"status"`
+	Bytes      int64     `json:"bytes"`
+	DurationMs float64   `json:"duration_ms"`
+	Referer    string    `json:"referer,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+}
+
+// writeAccessLog appends a finished request to the access log
+func (s *Server) writeAccessLog(r *http.Request, rw *responseWriter, start time.Time, duration time.Duration) {
+	if s.accessLog == nil {
+		return
+	}
+
+	host := r.RemoteAddr
+	if i := strings.LastIndex(host, ":"); i != -1 {
+		host = host[:i]
+	}
+
+	var line string
+	if s.accessLogJSON {
+		data, err := json.Marshal(accessLogEntry{
+			Time:       start,
+			RemoteAddr: host,
+			Method:     r.Method,
+			Path:       r.URL.RequestURI(),
+			Proto:      r.Proto,
+			Status:     rw.statusCode,
+			Bytes:      rw.bytes,
+			DurationMs: float64(duration) / float64(time.Millisecond),
+			Referer:    r.Referer(),
+			UserAgent:  r.UserAgent(),
+		})
+		if err != nil {
+			return
+		}
+		line = string(data) + "\n"
+	} else {
+		size := "-"
+		if rw.bytes > 0 {
+			size = fmt.Sprintf("%d", rw.bytes)
+		}
+		line = fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %s %q %q\n",
+			host, start.Format("02/Jan/2006:15:04:05 -0700"),
+			r.Method, r.URL.RequestURI(), r.Proto,
+			rw.statusCode, size, logField(r.Referer()), logField(r.UserAgent()))
+	}
+
+	s.accessLogMu.Lock()
+	io.WriteString(s.accessLog, line)
+	s.accessLogMu.Unlock()
+}
+
+// logField renders an empty header as "-" like Apache does
+func logField(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"vango/internal/logging"
+)
+
+// devCertValidity is how long a generated development certificate is valid
+const devCertValidity = 365 * 24 * time.Hour
+
+// SetTLS serves HTTPS with HTTP/2 using the certificate and key files, or a
+// generated localhost certificate when both are empty
+func (s *Server) SetTLS(certFile, keyFile string) {
+	s.tlsEnabled = true
+	s.certFile = certFile
+	s.keyFile = keyFile
+}
+
+// SetHTTPRedirect runs a plain HTTP listener on port that redirects every
+// request to the HTTPS server
+func (s *Server) SetHTTPRedirect(port int) {
+	s.httpRedirectPort = port
+}
+
+// scheme returns the URL scheme the server is reachable at
+func (s *Server) scheme() string {
+	if s.tlsEnabled {
+		return "https"
+	}
+	return "http"
+}
+
+// devCertificate returns the certificate and key files to serve, generating
+// a self-signed certificate for host in the user cache directory when none
+// is configured. The key is kept out of the project, where it could be
+// committed. A cached certificate is reused until it is close to expiring.
+func (s *Server) devCertificate(host string) (certFile, keyFile string, err error) {
+	if s.certFile != "" {
+		return s.certFile, s.keyFile, nil
+	}
+	s.warnProjectDevKey()
+
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		return "", "", fmt.Errorf("no user cache directory for the development certificate (%v); pass --cert and --key", err)
+	}
+	dir := filepath.Join(userCache, "vango", "tls")
+	certFile = filepath.Join(dir, "localhost.pem")
+	keyFile = filepath.Join(dir, "localhost-key.pem")
+
+	if cachedCertValid(certFile, keyFile, host) {
+		logging.Infof("🔐 Using development certificate %s", certFile)
+		return certFile, keyFile, nil
+	}
+	if err := generateDevCert(certFile, keyFile, host); err != nil {
+		return "", "", fmt.Errorf("failed to generate development certificate: %w", err)
+	}
+	logging.Infof("🔐 Generated self-signed development certificate %s", certFile)
+	logging.Infof("   Add it to your system or browser trust store to avoid certificate warnings")
+	return certFile, keyFile, nil
+}
+
+// cachedCertValid reports whether the cached certificate covers host and
+// stays valid for at least another day
+func cachedCertValid(certFile, keyFile, host string) bool {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return false
+	}
+	// Earlier releases generated a CA certificate; never reuse one
+	if cert.IsCA || time.Until(cert.NotAfter) < 24*time.Hour {
+		return false
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		return true
+	}
+	return cert.VerifyHostname(host) == nil
+}
+
+// generateDevCert writes a self-signed certificate for localhost, the
+// loopback addresses and host, with its private key. It is a leaf that can
+// only authenticate servers, so trusting it can't vouch for other sites.
+func generateDevCert(certFile, keyFile, host string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"VanGo development"}, CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(devCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  false,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if host != "" && host != "localhost" {
+		if ip := net.ParseIP(host); ip != nil {
+			if !ip.IsUnspecified() {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(certFile), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600)
+}
+
+// warnProjectDevKey warns about the key of a certificate generated by an
+// earlier release in the project's cache directory, which could sign
+// certificates for any site
+func (s *Server) warnProjectDevKey() {
+	dir := filepath.Join(s.cfg().Performance.CacheDir, "tls")
+	if s.cfg().Performance.CacheDir == "" {
+		dir = filepath.Join(".cache", "tls")
+	}
+	keyFile := filepath.Join(dir, "localhost-key.pem")
+	if _, err := os.Stat(keyFile); err == nil {
+		logging.Warnf("⚠️  %s is the key of a CA certificate generated by an earlier release: delete %s, remove the certificate from your trust store and trust the new one", keyFile, dir)
+	}
+}
+
+// tlsConfig returns the TLS configuration of the HTTPS server, which
+// negotiates HTTP/2 with browsers that support it
+func tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+}
+
+// hstsMiddleware sets Strict-Transport-Security on every response
+func hstsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Strict-Transport-Security", "max-age=31536000")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveHTTPRedirect redirects plain HTTP requests on port to the HTTPS
+// server listening on httpsPort
+func serveHTTPRedirect(port, httpsPort int) error {
+	redirect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		target := "https://" + net.JoinHostPort(host, strconv.Itoa(httpsPort)) + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	})
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           redirect,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.ListenAndServe()
+}
@@ -0,0 +1,80 @@
+// Package svgicon draws SVG icons into images with oksvg and rasterx.
+package svgicon
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+)
+
+// dropped are the elements oksvg parses past without drawing them
+var dropped = map[string]bool{
+	"text": true, "clipPath": true, "mask": true, "filter": true, "pattern": true,
+	"image": true, "foreignObject": true, "marker": true, "symbol": true, "switch": true,
+}
+
+// Rasterize draws an SVG image at size by size pixels, scaled to fit and
+// centered. currentColor is drawn black. Elements that can't be drawn,
+// such as text, clipping, masks and filters, are left out; Unsupported
+// lists the ones a source uses.
+func Rasterize(data []byte, size int) (*image.RGBA, error) {
+	icon, err := oksvg.ReadReplacingCurrentColor(bytes.NewReader(data), "black")
+	if err != nil {
+		return nil, fmt.Errorf("invalid SVG: %w", err)
+	}
+
+	box := icon.ViewBox
+	if box.W <= 0 || box.H <= 0 {
+		box.X, box.Y, box.W, box.H = 0, 0, float64(size), float64(size)
+	}
+	scale := float64(size) / math.Max(box.W, box.H)
+	icon.Transform = rasterx.Identity.
+		Translate((float64(size)-box.W*scale)/2, (float64(size)-box.H*scale)/2).
+		Scale(scale, scale).
+		Translate(-box.X, -box.Y)
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	scanner := rasterx.NewScannerGV(size, size, dst, dst.Bounds())
+	icon.Draw(rasterx.NewDasher(size, size, scanner), 1)
+	return dst, nil
+}
+
+// Unsupported returns the sorted names of the elements in data that
+// Rasterize leaves out
+func Unsupported(data []byte) []string {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	seen := make(map[string]bool)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		if t, ok := token.(xml.StartElement); ok && dropped[t.Name.Local] {
+			seen[t.Name.Local] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseColor parses a solid SVG color: hex, rgb() or a named color
+func ParseColor(value string) (color.Color, bool) {
+	c, err := oksvg.ParseSVGColor(strings.TrimSpace(value))
+	if err != nil || c == nil {
+		return nil, false
+	}
+	return c, true
+}
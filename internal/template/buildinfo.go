@@ -0,0 +1,48 @@
+package template
+
+import (
+	"html/template"
+	"time"
+)
+
+// BuildInfo describes the build for templates as .Site.BuildInfo, e.g. for a
+// "Generated on ... with VanGo v2.0.0 from commit abc123" footer
+type BuildInfo struct {
+	Time        time.Time
+	Version     string // VanGo version
+	Environment string
+	Commit      string // Short git commit hash, empty outside a git checkout
+	Branch      string // Git branch, empty on a detached HEAD
+
+	VangoCommit string // Commit the VanGo binary was built from
+	VangoDate   string // When the VanGo binary was built
+	GoVersion   string // Go version the VanGo binary was built with
+}
+
+// SetBuildInfo sets the build described to templates as .Site.BuildInfo
+func (e *Engine) SetBuildInfo(info BuildInfo) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.buildInfo = info
+}
+
+// GeneratorTag returns the generator meta tag for the build, or "" when
+// seo.metaGenerator is off
+func (e *Engine) GeneratorTag() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return string(e.generatorTag())
+}
+
+// generatorTag is the generator template function. The caller holds e.mu,
+// as Render does while templates execute.
+func (e *Engine) generatorTag() template.HTML {
+	if !e.config.SEO.MetaGenerator {
+		return ""
+	}
+	generator := "VanGo"
+	if e.buildInfo.Version != "" {
+		generator += " " + e.buildInfo.Version
+	}
+	return template.HTML(`<meta name="generator" content="` + template.HTMLEscapeString(generator) + `">`)
+}
@@ -0,0 +1,135 @@
+package template
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"text/template/parse"
+	"time"
+)
+
+// parsedFile holds a template file's parse trees until the file changes
+type parsedFile struct {
+	name    string // Template name the file was parsed as
+	modTime time.Time
+	size    int64
+	trees   map[string]*parse.Tree // The file's template and the blocks it defines
+}
+
+// builtinKey keys built-in templates in the parse cache, which never change
+const builtinKey = "builtin:"
+
+// parseFile returns the parse trees of a template file, reading and parsing
+// it only when it is new or its modification time or size has changed
+func (e *Engine) parseFile(path, name string, info os.FileInfo) (map[string]*parse.Tree, error) {
+	key := cacheKey(path)
+	e.seen[key] = true
+	if cached, ok := e.parsed[key]; ok && cached.name == name && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.trees, nil
+	}
+	delete(e.parsed, key)
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file %s: %w", path, err)
+	}
+	trees, err := e.parseTrees(name, string(source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+	e.parsed[key] = &parsedFile{name: name, modTime: info.ModTime(), size: info.Size(), trees: trees}
+	e.reparsed++
+	return trees, nil
+}
+
+// parseBuiltin returns the parse trees of a built-in template
+func (e *Engine) parseBuiltin(name, source string) (map[string]*parse.Tree, error) {
+	key := builtinKey + name
+	e.seen[key] = true
+	if cached, ok := e.parsed[key]; ok {
+		return cached.trees, nil
+	}
+	trees, err := e.parseTrees(name, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse built-in template %s: %w", name, err)
+	}
+	e.parsed[key] = &parsedFile{name: name, trees: trees}
+	return trees, nil
+}
+
+// parseTrees parses template source in a set of its own, returning the trees
+// of the template and of the blocks it defines
+func (e *Engine) parseTrees(name, source string) (map[string]*parse.Tree, error) {
+	tmpl, err := template.New(name).Funcs(e.funcMap).Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	trees := make(map[string]*parse.Tree)
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			trees[t.Name()] = t.Tree
+		}
+	}
+	return trees, nil
+}
+
+// addTrees adds copies of parsed trees to the template set, so executing the
+// set, which rewrites its trees while escaping, leaves the cache untouched.
+// The file's own template goes in first, as parsing it into the set would.
+func (e *Engine) addTrees(name string, trees map[string]*parse.Tree) error {
+	if tree, ok := trees[name]; ok {
+		if _, err := e.templates.AddParseTree(name, tree.Copy()); err != nil {
+			return err
+		}
+	}
+	for treeName, tree := range trees {
+		if treeName == name {
+			continue
+		}
+		if _, err := e.templates.AddParseTree(treeName, tree.Copy()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneParsed forgets the cached files the last load didn't come across,
+// such as deleted templates
+func (e *Engine) pruneParsed() {
+	for key := range e.parsed {
+		if !e.seen[key] {
+			delete(e.parsed, key)
+		}
+	}
+}
+
+// cacheKey is the absolute form of a template path, so files reported by the
+// watcher match the ones found walking the layout directories
+func cacheKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// Reload rebuilds the template set after the given template files changed.
+// Only those files, and any others whose modification time or size moved,
+// are parsed again; the rest come from the parse cache.
+func (e *Engine) Reload(changedPaths []string) error {
+	e.mu.Lock()
+	for _, path := range changedPaths {
+		delete(e.parsed, cacheKey(path))
+	}
+	themeLayoutDir := e.themeLayoutDir
+	e.mu.Unlock()
+	return e.LoadTemplates(themeLayoutDir)
+}
+
+// ReparsedFiles returns how many template files the last load had to parse
+// rather than take from the cache
+func (e *Engine) ReparsedFiles() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.reparsed
+}
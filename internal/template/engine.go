@@ -5,37 +5,141 @@ import (
 	"html/template"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"text/template/parse"
 	"time"
 
 	"vango/internal/config"
 	"vango/internal/content"
+	"vango/internal/logging"
 	"vango/internal/theme"
+	"vango/internal/util"
 )
 
+// baseTemplate is the template other templates fill in with their blocks
+const baseTemplate = "_default/baseof"
+
 // Engine handles template rendering
 type Engine struct {
 	config    *config.Config
 	templates *template.Template // Use a single template set
 	funcMap   template.FuncMap
+	sources   map[string]string // Template name -> file that defined it
+	blocks    map[string]map[string]*parse.Tree // Template name -> blocks its file defined
+	bound     map[string]*template.Template     // Content template -> base set with its blocks
+	pristine  map[*template.Template]*template.Template // Set -> unexecuted copy, for paginated renders
+	builtins  map[string]string                 // Built-in template name -> source
+	taxonomies map[string]content.Taxonomy
+	sections  map[string]*content.Section
+	stats     *content.SiteStats
+	translations Translations
+	buildInfo BuildInfo
+	missingTranslations sync.Map // "lang/key" -> reported
+	metrics   *metrics          // Template execution times, nil unless enabled
+	parsed    map[string]*parsedFile // Template file -> parse trees, reused while it is unchanged
+	seen      map[string]bool        // Cache keys the current load used
+	reparsed  int                    // Template files the last load parsed
+	themeLayoutDir string            // Theme layouts of the last load, for Reload
+	mu        sync.RWMutex      // Guards templates and sources during reloads
+}
+
+// SiteData is the site-wide data available to templates as .Site
+type SiteData struct {
+	*config.Config
+	// Taxonomies maps each taxonomy's plural name to its terms,
+	// e.g. .Site.Taxonomies.tags
+	Taxonomies map[string]content.Taxonomy
+	// Sections maps each content section to its pages and navigation
+	// tree, e.g. .Site.Sections.docs.Tree
+	Sections map[string]*content.Section
+	// BuildInfo describes the build, e.g. .Site.BuildInfo.Commit
+	BuildInfo BuildInfo
+	// Stats sums up the site's pages, e.g. .Site.Stats.Words
+	Stats *content.SiteStats
 }
 
 // TemplateData represents data passed to templates
 type TemplateData struct {
-	Site   *config.Config
+	Site   *SiteData
 	Page   *content.Page
-	Pages  []*content.Page
+	Pages  content.Pages
 	Params map[string]interface{}
 }
 
-// NewEngine creates a new template engine
+// NewEngine creates a new template engine. The theme manager may be nil, in
+// which case the theme functions behave as they do with no active theme.
 func NewEngine(cfg *config.Config, tm *theme.ThemeManager) *Engine {
+	if tm == nil {
+		tm = theme.NewThemeManager(cfg)
+	}
 	engine := &Engine{
 		config:    cfg,
 		templates: template.New("vango"), // Initialize a single root template set
 		funcMap:   createFuncMap(),
+		sources:   make(map[string]string),
+		blocks:    make(map[string]map[string]*parse.Tree),
+		bound:     make(map[string]*template.Template),
+		parsed:    make(map[string]*parsedFile),
+	}
+
+	engine.translations, _ = LoadTranslations()
+	warnMissingLocales(cfg)
+
+	engine.builtins = make(map[string]string)
+	for path, source := range tm.GetDefaultTheme().Templates {
+		engine.builtins[strings.TrimSuffix(strings.TrimPrefix(path, "layouts/"), ".html")] = source
 	}
 
+	// Stand-ins for the functions a builder provides, until it does
+	for _, name := range builderFuncs {
+		engine.funcMap[name] = builderFuncStandIn
+	}
+	addSandboxFuncs(engine.funcMap)
+	engine.funcMap["paginate"] = paginateStandIn
+	engine.funcMap["partial"] = engine.partial
+
+	// now reports the current time in the site's zone, or the pinned build
+	// time of a reproducible build
+	engine.funcMap["now"] = func() time.Time {
+		return cfg.Now().In(cfg.GetLocation())
+	}
+
+	engine.funcMap["isProduction"] = cfg.IsProduction
+	engine.funcMap["isDevelopment"] = cfg.IsDevelopment
+	engine.funcMap["generator"] = engine.generatorTag
+
+	// Language-aware functions default to the site language, or take a page
+	// or language code as their last argument
+	engine.funcMap["i18n"] = engine.translate
+	engine.funcMap["humanizeDate"] = func(date time.Time, lang ...interface{}) string {
+		language := engine.languageOf(lang)
+		return formatDateLocale(localeFor(language).humanize, date, language)
+	}
+	engine.funcMap["dateFormatLocale"] = func(layout string, date time.Time, lang ...interface{}) string {
+		return formatDateLocale(layout, date, engine.languageOf(lang))
+	}
+	engine.funcMap["timeAgo"] = func(date time.Time, lang ...interface{}) string {
+		return timeAgoLocale(date, engine.languageOf(lang))
+	}
+	engine.funcMap["readingTimeText"] = engine.readingTimeText
+	engine.funcMap["formatNumber"] = func(n interface{}, lang ...interface{}) string {
+		return formatNumber(n, engine.languageOf(lang))
+	}
+
+	// URLs of site paths under the BaseURL, for subpath deployments
+	engine.funcMap["relURL"] = cfg.RelURL
+	engine.funcMap["absURL"] = cfg.AbsURL
+
+	// Dot-path param lookups, page params first, then site params
+	engine.funcMap["param"] = engine.pageParam
+	engine.funcMap["siteParam"] = engine.siteParam
+	engine.funcMap["paramString"] = engine.paramString
+	engine.funcMap["paramBool"] = engine.paramBool
+	engine.funcMap["paramInt"] = engine.paramInt
+
 	// Add theme functions
 	for name, fn := range tm.GetThemeFunctions() {
 		engine.funcMap[name] = fn
@@ -46,8 +150,56 @@ func NewEngine(cfg *config.Config, tm *theme.ThemeManager) *Engine {
 	return engine
 }
 
-// LoadTemplates loads all templates from the given directory and the default layout directory
+// SetFunc adds a template function. It takes effect on the next LoadTemplates.
+func (e *Engine) SetFunc(name string, fn interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.funcMap[name] = fn
+}
+
+// SetTaxonomies sets the taxonomies exposed to templates as .Site.Taxonomies
+func (e *Engine) SetTaxonomies(taxonomies map[string]content.Taxonomy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.taxonomies = taxonomies
+}
+
+// SetSections sets the sections exposed to templates as .Site.Sections
+func (e *Engine) SetSections(sections map[string]*content.Section) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sections = sections
+}
+
+// SetStats sets the statistics exposed to templates as .Site.Stats
+func (e *Engine) SetStats(stats *content.SiteStats) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stats = stats
+}
+
+// siteData returns the .Site value for template execution. The caller
+// holds e.mu.
+func (e *Engine) siteData() *SiteData {
+	return &SiteData{Config: e.config, Taxonomies: e.taxonomies, Sections: e.sections, BuildInfo: e.buildInfo, Stats: e.stats}
+}
+
+// LoadTemplates loads all templates from the given directory and the default layout directory.
+// Each call starts from a fresh template set, since html/template can't re-parse
+// a set that has already been executed; this is what makes template reloading work.
+// Files unchanged since the previous call aren't parsed again.
 func (e *Engine) LoadTemplates(themeLayoutDir string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.templates = template.New("vango").Funcs(e.funcMap)
+	e.sources = make(map[string]string)
+	e.blocks = make(map[string]map[string]*parse.Tree)
+	e.bound = make(map[string]*template.Template)
+	e.themeLayoutDir = themeLayoutDir
+	e.seen = make(map[string]bool)
+	e.reparsed = 0
+
 	// Load theme templates first (higher priority)
 	if themeLayoutDir != "" && themeLayoutDir != e.config.LayoutDir {
 		if err := e.parseAndAddTemplates(themeLayoutDir); err != nil {
@@ -60,6 +212,101 @@ func (e *Engine) LoadTemplates(themeLayoutDir string) error {
 		return fmt.Errorf("failed to parse default templates: %w", err)
 	}
 
+	if err := e.addBuiltinTemplates(); err != nil {
+		return err
+	}
+	e.pruneParsed()
+	logging.Debugf("🎨 Loaded templates, parsed %d changed files", e.reparsed)
+	if err := e.bindContentTemplates(); err != nil {
+		return err
+	}
+	return e.preparePagers()
+}
+
+// addBuiltinTemplates adds the built-in partials the theme and site don't
+// override, and the built-in page templates when neither has a template
+// pages could render with. Sites with their own layouts keep their page
+// templates, so a missing list template doesn't change how their list
+// pages look.
+func (e *Engine) addBuiltinTemplates() error {
+	pages := true
+	for _, name := range []string{"_default/single", baseTemplate} {
+		if tmpl := e.templates.Lookup(name); tmpl != nil && tmpl.Tree != nil {
+			pages = false
+		}
+	}
+	for name, source := range e.builtins {
+		isPartial := strings.HasPrefix(name, "partials/")
+		if !isPartial && !pages {
+			continue
+		}
+		if tmpl := e.templates.Lookup(name); isPartial && tmpl != nil && tmpl.Tree != nil {
+			continue
+		}
+		trees, err := e.parseBuiltin(name, source)
+		if err != nil {
+			return err
+		}
+		if err := e.addTrees(name, trees); err != nil {
+			return fmt.Errorf("failed to add built-in template %s: %w", name, err)
+		}
+	}
+	if pages {
+		logging.Debugf("🎨 No page templates found, using the built-in templates")
+	}
+	return nil
+}
+
+// bindContentTemplates gives each template that only defines blocks, such
+// as a single or list template under a base template, its own copy of the
+// template set in which the base template sees its blocks. In the shared
+// set a block name like "main" holds whichever file was parsed last.
+func (e *Engine) bindContentTemplates() error {
+	base := e.templates.Lookup(baseTemplate)
+	if base == nil || base.Tree == nil {
+		return nil
+	}
+
+	// Blocks some content template defines fall back to the base
+	// template's default, or nothing, when the page's template doesn't
+	noBlock := template.Must(template.New("").Parse(`{{ "" }}`)).Tree
+	defaults := make(map[string]*parse.Tree)
+	for name, blocks := range e.blocks {
+		if name == baseTemplate {
+			continue
+		}
+		for block := range blocks {
+			if tree, ok := e.blocks[baseTemplate][block]; ok {
+				defaults[block] = tree
+			} else {
+				defaults[block] = noBlock
+			}
+		}
+	}
+
+	for name, blocks := range e.blocks {
+		tmpl := e.templates.Lookup(name)
+		if name == baseTemplate || tmpl == nil || tmpl.Tree == nil || !parse.IsEmptyTree(tmpl.Tree.Root) {
+			continue
+		}
+		set, err := e.templates.Clone()
+		if err != nil {
+			return err
+		}
+		for block, tree := range defaults {
+			if _, ok := blocks[block]; !ok {
+				if _, err := set.AddParseTree(block, tree.Copy()); err != nil {
+					return fmt.Errorf("failed to bind block %s for %s: %w", block, name, err)
+				}
+			}
+		}
+		for block, tree := range blocks {
+			if _, err := set.AddParseTree(block, tree.Copy()); err != nil {
+				return fmt.Errorf("failed to bind block %s for %s: %w", block, name, err)
+			}
+		}
+		e.bound[name] = set
+	}
 	return nil
 }
 
@@ -79,30 +326,135 @@ func (e *Engine) parseAndAddTemplatesWithOverride(layoutDir string, allowOverrid
 		if err != nil {
 			return fmt.Errorf("failed to get relative path for template %s: %w", path, err)
 		}
-		templateName := strings.TrimSuffix(relPath, ".html")
-		templateName = filepath.ToSlash(templateName) // Ensure forward slashes on all platforms
+		templateName := util.SlashPath(strings.TrimSuffix(relPath, ".html"))
 
 		// Skip if template already exists and override is not allowed
 		if !allowOverride && e.templates.Lookup(templateName) != nil {
 			return nil
 		}
 
-		// Read file content
-		content, err := os.ReadFile(path)
+		// Parse the template, unless it is cached, and add it to the main
+		// template set
+		trees, err := e.parseFile(path, templateName, info)
 		if err != nil {
-			return fmt.Errorf("failed to read template file %s: %w", path, err)
+			return err
+		}
+		if e.config.Themes.Sandbox && layoutDir == e.themeLayoutDir && layoutDir != e.config.LayoutDir {
+			if trees, err = sandboxTrees(path, trees); err != nil {
+				return err
+			}
+		}
+		before := e.definedTrees()
+		if err := e.addTrees(templateName, trees); err != nil {
+			return fmt.Errorf("failed to add template %s: %w", path, err)
 		}
 
-		// Parse the template and add it to the main template set
-		_, err = e.templates.New(templateName).Parse(string(content))
-		if err != nil {
-			return fmt.Errorf("failed to parse template %s: %w", path, err)
+		// Remember which file defined each template, including its define
+		// blocks, and keep the file's own blocks for bindContentTemplates
+		blocks := make(map[string]*parse.Tree)
+		for _, tmpl := range e.templates.Templates() {
+			if tmpl.Tree != nil && before[tmpl.Name()] != tmpl.Tree {
+				e.sources[tmpl.Name()] = path
+				if tmpl.Name() != templateName {
+					blocks[tmpl.Name()] = tmpl.Tree.Copy()
+				}
+			}
+		}
+		if len(blocks) > 0 {
+			e.blocks[templateName] = blocks
 		}
 
 		return nil
 	})
 }
 
+// definedTrees snapshots the parse tree of every template in the set
+func (e *Engine) definedTrees() map[string]*parse.Tree {
+	trees := make(map[string]*parse.Tree)
+	for _, tmpl := range e.templates.Templates() {
+		trees[tmpl.Name()] = tmpl.Tree
+	}
+	return trees
+}
+
+// TemplateFiles returns the files making up the template chain used to render
+// a page: the resolved template plus everything it includes, transitively
+func (e *Engine) TemplateFiles(page *content.Page) []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	set, templateName, contentName := e.renderSet(page)
+	seen := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		tmpl := set.Lookup(name)
+		if tmpl == nil || tmpl.Tree == nil {
+			return
+		}
+		for _, ref := range templateRefs(tmpl.Tree.Root) {
+			visit(ref)
+		}
+	}
+	visit(templateName)
+
+	files := make(map[string]bool)
+	if contentName != "" {
+		files[e.sources[contentName]] = true
+	}
+	for name := range seen {
+		// Blocks bound from the content template came from its file
+		if _, ok := e.blocks[contentName][name]; ok {
+			continue
+		}
+		if path, ok := e.sources[name]; ok {
+			files[path] = true
+		}
+	}
+	result := make([]string, 0, len(files))
+	for path := range files {
+		result = append(result, path)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// templateRefs collects the names of templates invoked under a parse node,
+// with {{ template }} or a partial call
+func templateRefs(node parse.Node) []string {
+	var refs []string
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			refs = append(refs, templateRefs(child)...)
+		}
+	case *parse.ActionNode:
+		refs = append(refs, partialRefs(n.Pipe)...)
+	case *parse.TemplateNode:
+		refs = append(refs, n.Name)
+		refs = append(refs, partialRefs(n.Pipe)...)
+	case *parse.IfNode:
+		refs = append(refs, partialRefs(n.Pipe)...)
+		refs = append(refs, templateRefs(n.List)...)
+		refs = append(refs, templateRefs(n.ElseList)...)
+	case *parse.RangeNode:
+		refs = append(refs, partialRefs(n.Pipe)...)
+		refs = append(refs, templateRefs(n.List)...)
+		refs = append(refs, templateRefs(n.ElseList)...)
+	case *parse.WithNode:
+		refs = append(refs, partialRefs(n.Pipe)...)
+		refs = append(refs, templateRefs(n.List)...)
+		refs = append(refs, templateRefs(n.ElseList)...)
+	}
+	return refs
+}
+
 // parseAndAddTemplates walks a directory, parses HTML files, and adds them to the template set
 func (e *Engine) parseAndAddTemplates(layoutDir string) error {
 	return e.parseAndAddTemplatesWithOverride(layoutDir, true)
@@ -110,17 +462,36 @@ func (e *Engine) parseAndAddTemplates(layoutDir string) error {
 
 // Render renders a page using the appropriate template
 func (e *Engine) Render(page *content.Page, pages []*content.Page) (string, error) {
-	// Determine which template to use
-	templateName := e.getTemplateName(page)
+	html, _, err := e.RenderPager(page, pages, 1)
+	return html, err
+}
+
+// RenderPager renders pager number of a list page, 1 being the page itself,
+// and returns the paginator its template asked for with paginate, or nil
+// when it didn't call paginate
+func (e *Engine) RenderPager(page *content.Page, pages []*content.Page, number int) (string, *Paginator, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	// Determine which template to use, and the set holding the page's
+	// blocks when it renders through the base template
+	set, templateName, _ := e.renderSet(page)
+	set, pager, err := e.pagerSet(set, page, number)
+	if err != nil {
+		return "", nil, err
+	}
 	
-	tmpl := e.templates.Lookup(templateName) // Use Lookup on the single template set
+	tmpl := set.Lookup(templateName)
 	if tmpl == nil {
-		return "", fmt.Errorf("template not found: %s", templateName)
+		return "", nil, fmt.Errorf("template not found: %s", templateName)
+	}
+	if e.metrics != nil {
+		defer e.metrics.record(templateName, time.Now())
 	}
 	
 	// Prepare template data
 	data := &TemplateData{
-		Site:   e.config,
+		Site:   e.siteData(),
 		Page:   page,
 		Pages:  pages,
 		Params: make(map[string]interface{}),
@@ -130,51 +501,297 @@ func (e *Engine) Render(page *content.Page, pages []*content.Page) (string, erro
 	var buf strings.Builder
 	
 	// Handle template inheritance for base templates
-	if templateName == "_default/baseof" {
-		// For base templates, we need to execute with proper context
-		// The base template will call the appropriate content template
-		err := e.templates.ExecuteTemplate(&buf, "_default/baseof", data)
+	if templateName == baseTemplate {
+		// The base template calls the blocks of the page's content template
+		err := set.ExecuteTemplate(&buf, baseTemplate, data)
 		if err != nil {
-			return "", fmt.Errorf("failed to execute base template: %w", err)
+			return "", nil, fmt.Errorf("failed to execute base template: %w", err)
 		}
 	} else {
 		// For non-base templates, execute directly
 		if err := tmpl.Execute(&buf, data); err != nil {
-			return "", fmt.Errorf("failed to execute template %s: %w", templateName, err)
+			return "", nil, fmt.Errorf("failed to execute template %s: %w", templateName, err)
 		}
 	}
 	
+	return buf.String(), pager.result(), nil
+}
+
+// renderSet returns the template set and template to execute for a page.
+// A page rendered through the base template gets the set bound to its
+// content template, which is also returned.
+func (e *Engine) renderSet(page *content.Page) (*template.Template, string, string) {
+	templateName := e.getTemplateName(page)
+	if templateName != baseTemplate && e.bound[templateName] == nil {
+		return e.templates, templateName, ""
+	}
+	if contentName := e.contentTemplate(page); contentName != "" {
+		return e.bound[contentName], baseTemplate, contentName
+	}
+	return e.templates, templateName, ""
+}
+
+// contentTemplate returns the template whose blocks fill in the base
+// template for a page: the first template of its lookup chain that only
+// defines blocks, then the list template for list pages and the single
+// template
+func (e *Engine) contentTemplate(page *content.Page) string {
+	var names []string
+	for _, candidate := range e.templateChain(page) {
+		if candidate.Step != "base template" && candidate.Step != "default" {
+			names = append(names, candidate.Name)
+		}
+	}
+	if page.Kind != "page" && page.Kind != "" {
+		names = append(names, "_default/list")
+	}
+	names = append(names, "_default/single")
+	for _, name := range names {
+		if e.bound[name] != nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// RenderPartial renders the partial called name, such as "partials/code-copy",
+// for page
+func (e *Engine) RenderPartial(name string, page *content.Page) (string, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	tmpl := e.templates.Lookup(name)
+	if tmpl == nil || tmpl.Tree == nil {
+		return "", fmt.Errorf("template %s not found", name)
+	}
+	if e.metrics != nil {
+		defer e.metrics.record(name, time.Now())
+	}
+	data := &TemplateData{
+		Site:   e.siteData(),
+		Page:   page,
+		Params: make(map[string]interface{}),
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute %s: %w", name, err)
+	}
 	return buf.String(), nil
 }
 
-// getTemplateName determines which template to use for a page
-func (e *Engine) getTemplateName(page *content.Page) string {
-	// Check for page-specific template
-	if tmplName, ok := page.Params["layout"].(string); ok {
-		if e.templates.Lookup(tmplName) != nil { // Use Lookup
-			return tmplName
+// RenderPreviewBanner renders the banner injected into preview builds.
+// A site or theme can override it with the configured preview partial.
+func (e *Engine) RenderPreviewBanner(page *content.Page) (string, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if tmpl := e.templates.Lookup(e.config.Preview.Partial); tmpl != nil {
+		if e.metrics != nil {
+			defer e.metrics.record(e.config.Preview.Partial, time.Now())
+		}
+		data := &TemplateData{
+			Site:   e.siteData(),
+			Page:   page,
+			Params: make(map[string]interface{}),
+		}
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("failed to execute preview banner %s: %w", e.config.Preview.Partial, err)
 		}
+		return buf.String(), nil
 	}
-	
-	// Check for section-specific template
-	if strings.Contains(page.Slug, "/") {
-		section := strings.Split(page.Slug, "/")[0]
-		sectionTemplate := section + "/single"
-		if e.templates.Lookup(sectionTemplate) != nil { // Use Lookup
-			return sectionTemplate
+
+	message := e.config.Preview.Message
+	if message == "" {
+		message = "Preview build"
+	}
+	return fmt.Sprintf(`<div class="vango-preview-banner" role="status" style="position:fixed;bottom:0;left:0;right:0;z-index:10000;padding:6px 12px;background:#f59e0b;color:#1f2937;font:13px/1.4 sans-serif;text-align:center;">%s &middot; %s &middot; %s</div>`,
+		template.HTMLEscapeString(message),
+		template.HTMLEscapeString(e.config.Environment),
+		template.HTMLEscapeString(e.config.BaseURL),
+	), nil
+}
+
+// TemplateCandidate is one step of the lookup chain used to pick a page's template
+type TemplateCandidate struct {
+	Step   string `json:"step"`
+	Name   string `json:"name"`
+	Found  bool   `json:"found"`
+	Source string `json:"source,omitempty"`
+	Origin string `json:"origin,omitempty"`
+}
+
+// TemplateResolution explains which template renders a page and why
+type TemplateResolution struct {
+	Template string              `json:"template"`
+	Blocks   string              `json:"blocks,omitempty"` // Template whose blocks fill in the base template
+	Chain    []TemplateCandidate `json:"chain"`
+	Files    []string            `json:"files"`
+}
+
+// TemplateSource is a loaded template and the file that defined it
+type TemplateSource struct {
+	Name   string `json:"name"`
+	Source string `json:"source,omitempty"`
+	Origin string `json:"origin,omitempty"`
+}
+
+// ResolveTemplate reports the template chosen for a page, every candidate
+// that was tried on the way, and the files the chosen template pulls in
+func (e *Engine) ResolveTemplate(page *content.Page) *TemplateResolution {
+	e.mu.RLock()
+	chain := e.templateChain(page)
+	e.mu.RUnlock()
+
+	resolution := &TemplateResolution{Template: "_default/single", Chain: chain}
+	for _, candidate := range chain {
+		if candidate.Found {
+			resolution.Template = candidate.Name
+			break
 		}
 	}
-	
-	// For themes with base templates, try to use baseof as the main template
-	if e.templates.Lookup("_default/baseof") != nil {
-		fmt.Printf("🎨 Using base template: _default/baseof\n")
-		return "_default/baseof"
+	e.mu.RLock()
+	_, _, resolution.Blocks = e.renderSet(page)
+	e.mu.RUnlock()
+	resolution.Files = e.TemplateFiles(page)
+	return resolution
+}
+
+// TemplateSources lists every loaded template with its source file, sorted by name
+func (e *Engine) TemplateSources() []TemplateSource {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var sources []TemplateSource
+	for _, tmpl := range e.templates.Templates() {
+		if tmpl.Tree == nil {
+			continue
+		}
+		path := e.sources[tmpl.Name()]
+		sources = append(sources, TemplateSource{
+			Name:   tmpl.Name(),
+			Source: path,
+			Origin: e.sourceOrigin(path),
+		})
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Name < sources[j].Name })
+	return sources
+}
+
+// templateChain builds the lookup chain for a page: layout param, the
+// templates for its kind, base template, then the default single template
+func (e *Engine) templateChain(page *content.Page) []TemplateCandidate {
+	var chain []TemplateCandidate
+	if tmplName, ok := page.Params["layout"].(string); ok {
+		chain = append(chain, e.candidate("layout param", tmplName))
+	}
+	if page.Layout != "" {
+		// A layout from front matter or the section config, looked up
+		// under the page type first
+		chain = append(chain,
+			e.candidate("layout", page.Type+"/"+page.Layout),
+			e.candidate("layout", "_default/"+page.Layout),
+		)
+	}
+	switch {
+	case page.Kind == "home":
+		chain = append(chain, e.candidate("home template", "index"))
+	case page.Kind == "section":
+		chain = append(chain, e.candidate("section template", "section/"+page.Section))
+	case page.Kind == "term" || page.Kind == "taxonomy":
+		// Taxonomy pages live under their plural name, e.g. tags/go
+		plural := util.FirstSegment(page.Slug)
+		chain = append(chain,
+			e.candidate(page.Kind+" template", plural+"/"+page.Kind),
+			e.candidate(page.Kind+" template", "_default/"+page.Kind),
+		)
+	case strings.Contains(page.Slug, "/"):
+		section := util.FirstSegment(page.Slug)
+		chain = append(chain, e.candidate("section template", section+"/single"))
+	}
+	if page.Kind != "page" && page.Kind != "" {
+		// A standalone list template renders the listing; under a base
+		// template it only defines blocks, so fall through to baseof
+		if base := e.templates.Lookup(baseTemplate); base == nil || base.Tree == nil {
+			chain = append(chain, e.candidate("list template", "_default/list"))
+		}
+	}
+	chain = append(chain,
+		e.candidate("base template", baseTemplate),
+		e.candidate("default", "_default/single"),
+	)
+	return chain
+}
+
+// candidate looks up a template name for the lookup chain
+func (e *Engine) candidate(step, name string) TemplateCandidate {
+	c := TemplateCandidate{Step: step, Name: name}
+	if tmpl := e.templates.Lookup(name); tmpl != nil && tmpl.Tree != nil {
+		c.Found = true
+		c.Source = e.sources[name]
+		c.Origin = e.sourceOrigin(c.Source)
+	}
+	return c
+}
+
+// sourceOrigin reports whether a template file belongs to the site layouts or the theme
+func (e *Engine) sourceOrigin(path string) string {
+	if path == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(e.config.LayoutDir, path)
+	if err == nil && !strings.HasPrefix(rel, "..") {
+		return "site"
+	}
+	return "theme"
+}
+
+// getTemplateName determines which template to use for a page
+func (e *Engine) getTemplateName(page *content.Page) string {
+	for _, candidate := range e.templateChain(page) {
+		if !candidate.Found {
+			continue
+		}
+		if candidate.Step == "base template" {
+			logging.Debugf("🎨 Using base template: _default/baseof")
+		}
+		return candidate.Name
 	}
 	
 	// Default to single template
 	return "_default/single"
 }
 
+// TemplateDataKeys lists the fields templates can reach from the data passed
+// to a page, including the page's own params
+func TemplateDataKeys(page *content.Page) []string {
+	var keys []string
+	data := reflect.TypeOf(TemplateData{})
+	for i := 0; i < data.NumField(); i++ {
+		keys = append(keys, "."+data.Field(i).Name)
+	}
+	keys = append(keys, exportedFields(".Site", reflect.TypeOf(config.Config{}))...)
+	keys = append(keys, exportedFields(".Page", reflect.TypeOf(content.Page{}))...)
+
+	params := make([]string, 0, len(page.Params))
+	for key := range page.Params {
+		params = append(params, ".Page.Params."+key)
+	}
+	sort.Strings(params)
+	return append(keys, params...)
+}
+
+// exportedFields lists the exported fields of a struct type under prefix
+func exportedFields(prefix string, t reflect.Type) []string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.IsExported() {
+			fields = append(fields, prefix+"."+field.Name)
+		}
+	}
+	return fields
+}
+
 // createFuncMap creates template functions
 func createFuncMap() template.FuncMap {
 	return template.FuncMap{
@@ -196,24 +813,6 @@ func createFuncMap() template.FuncMap {
 		"dateFormat": func(layout string, date time.Time) string {
 			return date.Format(layout)
 		},
-		"humanizeDate": func(date time.Time) string {
-			return date.Format("January 2, 2006")
-		},
-		"timeAgo": func(date time.Time) string {
-			duration := time.Since(date)
-			switch {
-			case duration.Hours() < 24:
-				return fmt.Sprintf("%.0f hours ago", duration.Hours())
-			case duration.Hours() < 24*7:
-				return fmt.Sprintf("%.0f days ago", duration.Hours()/24)
-			case duration.Hours() < 24*30:
-				return fmt.Sprintf("%.0f weeks ago", duration.Hours()/(24*7))
-			case duration.Hours() < 24*365:
-				return fmt.Sprintf("%.0f months ago", duration.Hours()/(24*30))
-			default:
-				return fmt.Sprintf("%.0f years ago", duration.Hours()/(24*365))
-			}
-		},
 		"add": func(a, b int) int { return a + b },
 		"sub": func(a, b int) int { return a - b },
 		"mul": func(a, b int) int { return a * b },
@@ -222,7 +821,7 @@ func createFuncMap() template.FuncMap {
 			return a / b 
 		},
 		"seq": func(n int) []int {
-			seq := make([]int, n)
+			seq := make([]int, max(n, 0))
 			for i := range seq {
 				seq[i] = i + 1
 			}
@@ -248,6 +847,7 @@ func createFuncMap() template.FuncMap {
 			}
 			return value
 		},
+		"headingsBetween": content.HeadingsBetween,
 		"safeHTML": func(s string) template.HTML {
 			return template.HTML(s)
 		},
@@ -262,12 +862,34 @@ func createFuncMap() template.FuncMap {
 
 // GetTemplate returns a template by name
 func (e *Engine) GetTemplate(name string) (*template.Template, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	tmpl := e.templates.Lookup(name)
 	return tmpl, tmpl != nil
 }
 
+// FuncNames returns the names of all template functions, sorted
+func (e *Engine) FuncNames() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	names := make([]string, 0, len(e.funcMap))
+	for name := range e.funcMap {
+		if isSandboxFunc(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // ListTemplates returns all available template names
 func (e *Engine) ListTemplates() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	names := make([]string, 0)
 	for _, tmpl := range e.templates.Templates() {
 		names = append(names, tmpl.Name())
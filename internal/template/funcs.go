@@ -0,0 +1,221 @@
+package template
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+	"strings"
+
+	"vango/internal/content"
+)
+
+// FuncInfo describes a template function for `vango tpl list`
+type FuncInfo struct {
+	Name        string `json:"name"`
+	Signature   string `json:"signature"`
+	Description string `json:"description,omitempty"`
+}
+
+// builderFuncs are the template functions a builder provides with SetFunc,
+// since they need the parsed site. An engine without a builder registers
+// stand-ins for them, so templates using them parse and render either way.
+var builderFuncs = []string{
+	"scss", "resource", "ref", "relref", "socialImage", "openGraph", "twitterCard",
+	"preload", "prefetchNext", "feedHTML", "analyticsSnippet", "faviconTags",
+}
+
+// builderFuncStandIn stands in for a builder function, returning nothing
+func builderFuncStandIn(...interface{}) template.HTML {
+	return ""
+}
+
+// funcDescriptions documents the registered template functions. Every
+// function listed is registered by NewEngine, whether or not a theme is
+// active; the theme functions return neutral values without one.
+var funcDescriptions = map[string]string{
+	// Strings
+	"lower":     "Converts a string to lower case",
+	"upper":     "Converts a string to upper case",
+	"title":     "Capitalizes the first letter of each word",
+	"trim":      "Removes leading and trailing whitespace",
+	"replace":   "Replaces every occurrence of old with new: replace old new s",
+	"split":     "Splits a string around a separator into a slice",
+	"join":      "Joins a slice of strings with a separator: join sep elems",
+	"hasPrefix": "Reports whether a string begins with a prefix",
+	"hasSuffix": "Reports whether a string ends with a suffix",
+	"contains":  "Reports whether a string contains a substring",
+	"slugify":   "Converts text to a URL-safe slug",
+
+	// Dates
+	"now":              "Returns the build time in the site's time zone",
+	"dateFormat":       "Formats a time with a Go layout: dateFormat \"2006-01-02\" .Page.ParsedDate",
+	"dateFormatLocale": "Formats a time with a Go layout using localized month and day names",
+	"humanizeDate":     "Formats a time as a readable date in the site or given language",
+	"timeAgo":          "Describes how long ago a time was, in the site or given language",
+	"readingTimeText":  "Describes a page's reading time in its language, e.g. \"3 min read\"",
+	"formatNumber":     "Groups a number's digits the way the site or given language does, e.g. 240,000",
+	"formatDate":       "Formats a time with a Go layout",
+	"isoDate":          "Formats a time as RFC 3339",
+	"isRecent":         "Reports whether a time is within the given number of days",
+	"timeFromNow":      "Describes how long ago a time was, in English",
+
+	// Math
+	"add":        "Adds two integers",
+	"sub":        "Subtracts the second integer from the first",
+	"mul":        "Multiplies two integers",
+	"div":        "Divides the first integer by the second, 0 when dividing by zero",
+	"seq":        "Returns the integers 1 to n",
+	"percentage": "Returns current as a percentage of total",
+	"round":      "Rounds a number to the given number of decimals",
+	"random":     "Returns a number between min and max; currently always min",
+	"uuid":       "Returns a unique identifier; currently a fixed placeholder",
+
+	// Collections and logic
+	"dict":       "Builds a map from alternating keys and values",
+	"default":    "Returns the value, or the default when the value is empty",
+	"groupBy":    "Groups items by a field; not implemented yet, returns no groups",
+	"sortBy":     "Sorts items by a field; not implemented yet, returns items unchanged",
+	"filterBy":   "Keeps items whose field equals a value; not implemented yet, returns items unchanged",
+	"unique":     "Removes duplicate items",
+	"partial":    "Executes a partial with an optional context and returns its output: partial \"header\" .",
+	"paginate":   "Splits a list page's pages into pagers: paginate [size] .Pages, or slices items: paginate items page perPage",
+	"ifNotEmpty": "Reports whether a value is non-empty",
+	"ifAny":      "Reports whether any value is truthy",
+	"ifAll":      "Reports whether every value is truthy",
+	"switch":     "Returns the result paired with the first case equal to the value",
+
+	// Content
+	"safeHTML":         "Marks a string as safe HTML, skipping escaping",
+	"safeCSS":          "Marks a string as safe CSS",
+	"safeJS":           "Marks a string as safe JavaScript",
+	"markdownify":      "Marks a markdown string as HTML; markdown is not rendered yet",
+	"highlight":        "Wraps code in a pre block for the given language",
+	"sanitizeHTML":     "Marks content as HTML; no sanitizing is done yet",
+	"excerpt":          "Returns the first words of HTML content as plain text",
+	"feedHTML":         "Prepares a page's HTML for feeds and search indexes: feeds.remove elements replaced, URLs made absolute",
+	"analyticsSnippet": "Returns the analytics provider's script tags, or nothing when analytics is off for this build",
+	"faviconTags":      "Returns the link and meta tags of the icons generated from favicon.source, or nothing when it isn't set",
+	"truncateWords":    "Shortens text to a number of words, ending it with the configured ellipsis",
+	"truncate":         "Shortens content to a number of characters as plain text, cutting at a word",
+	"truncateHTML":     "Shortens HTML content to a number of characters of text, closing the tags left open",
+	"readingTime":      "Estimates the reading time of content in minutes",
+	"wordCount":        "Counts the words in content",
+	"tableOfContents":  "Builds a table of contents from the headings in HTML content",
+	"headingsBetween":  "Returns the headings between two levels",
+	"breadcrumbs":      "Returns a page's ancestor chain from the home page, each crumb with .Name and .URL",
+	"relatedPosts":     "Returns pages related to the current page; not implemented yet, returns none",
+
+	// Parameters and translations
+	"param":       "Looks up a page parameter, falling back to the site parameter",
+	"siteParam":   "Looks up a site parameter by dotted key",
+	"paramString": "Looks up a parameter as a string, with a default",
+	"paramBool":   "Looks up a parameter as a boolean, with a default",
+	"paramInt":    "Looks up a parameter as an integer, with a default",
+	"i18n":        "Translates a key into the site or given language",
+
+	// URLs and assets
+	"relURL":           "Prefixes a site path with the BaseURL path",
+	"absURL":           "Makes a site path absolute against the BaseURL",
+	"ref":              "Returns the absolute URL of a content file",
+	"relref":           "Returns the site-relative URL of a content file",
+	"themeAsset":       "Returns the URL of a file in the theme's static directory",
+	"assetFingerprint": "Appends a content hash to an asset URL for cache busting",
+	"preload":          "Returns a preload link tag for a URL and its as type, such as style, script or font",
+	"prefetchNext":     "Returns a prefetch link tag for the next page in a page's section",
+	"scss":             "Returns the URL of a compiled stylesheet",
+	"resource":         "Publishes a file from the assets directory and returns it with .RelPermalink, .Permalink and .MediaType",
+	"imageOptimize":    "Returns the URL of an image resized to width and height; currently the original URL",
+	"responsiveImg":    "Returns an img tag for an image at several sizes; currently without a srcset",
+
+	// Theme
+	"themeConfig": "Looks up a value in the theme's config.json by dotted key",
+	"hasFeature":  "Reports whether the theme enables a feature such as dark_mode",
+	"themeColor":  "Returns a color from the theme's color scheme",
+
+	// SEO and build
+	"metaDescription": "Returns the meta description for a page; not implemented yet, returns \"\"",
+	"metaKeywords":    "Returns the keywords meta tag for a page's front matter keywords, empty when it has none",
+	"jsonLD":          "Returns a JSON-LD script describing a page, including the series it is part of and its keywords",
+	"openGraph":       "Returns Open Graph meta tags for a page, with its social image",
+	"twitterCard":     "Returns Twitter card meta tags for a page, a large image card when it has a social image",
+	"socialImage":     "Returns the absolute URL of a page's image for sharing, from its front matter, content, section or the site default",
+	"generator":       "Returns the generator meta tag, empty when seo.metaGenerator is off",
+	"isProduction":    "Reports whether the site builds for the production environment",
+	"isDevelopment":   "Reports whether the site builds for the development environment",
+}
+
+// Funcs describes every template function, sorted by name
+func (e *Engine) Funcs() []FuncInfo {
+	names := e.FuncNames()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	funcs := make([]FuncInfo, 0, len(names))
+	for _, name := range names {
+		funcs = append(funcs, FuncInfo{
+			Name:        name,
+			Signature:   funcSignature(e.funcMap[name]),
+			Description: funcDescriptions[name],
+		})
+	}
+	return funcs
+}
+
+// funcSignature formats a function's parameter and result types, e.g.
+// "(string, int) string"
+func funcSignature(fn interface{}) string {
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func {
+		return ""
+	}
+	params := make([]string, t.NumIn())
+	for i := range params {
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			params[i] = "..." + t.In(i).Elem().String()
+		} else {
+			params[i] = t.In(i).String()
+		}
+	}
+	results := make([]string, t.NumOut())
+	for i := range results {
+		results[i] = t.Out(i).String()
+	}
+
+	signature := "(" + strings.Join(params, ", ") + ")"
+	switch len(results) {
+	case 0:
+	case 1:
+		signature += " " + results[0]
+	default:
+		signature += " (" + strings.Join(results, ", ") + ")"
+	}
+	return signature
+}
+
+// Execute runs a template snippet against page, with the loaded templates
+// available to it, and returns the output
+func (e *Engine) Execute(snippet string, page *content.Page, pages content.Pages) (string, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	tmpl, err := e.templates.Clone()
+	if err != nil {
+		return "", err
+	}
+	tmpl, err = tmpl.New("snippet").Parse(snippet)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse snippet: %w", err)
+	}
+
+	data := &TemplateData{
+		Site:   e.siteData(),
+		Page:   page,
+		Pages:  pages,
+		Params: make(map[string]interface{}),
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,187 @@
+package template
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"vango/internal/logging"
+
+	"github.com/pelletier/go-toml"
+)
+
+// Translations maps a language code to its translated strings by key
+type Translations map[string]map[string]string
+
+// builtinTranslations cover the strings used by the bundled themes, so
+// sites without i18n files still render them. Plural forms other than
+// "other" are stored as key.one, key.few and so on.
+var builtinTranslations = Translations{
+	"en": {
+		"minRead": "min read", "postedBy": "Posted by",
+		"readMore": "Read more", "recentPosts": "Recent Posts",
+		"skipToContent": "Skip to content", "mainNavigation": "Main navigation",
+		"sectionNavigation": "Section navigation", "tableOfContents": "Table of contents",
+		"copyCode": "Copy", "codeCopied": "Copied",
+		"readingTime": "{{ .Count }} min read", "readingTimeRange": "{{ .Min }}–{{ .Max }} min read",
+		"readingTimeUnderMinute": "Less than a minute",
+	},
+	"fr": {
+		"minRead": "min de lecture", "postedBy": "Publié par",
+		"readMore": "Lire la suite", "recentPosts": "Articles récents",
+		"skipToContent": "Aller au contenu", "mainNavigation": "Navigation principale",
+		"sectionNavigation": "Navigation de la section", "tableOfContents": "Table des matières",
+		"copyCode": "Copier", "codeCopied": "Copié",
+		"readingTime": "{{ .Count }} minutes de lecture", "readingTime.one": "{{ .Count }} minute de lecture",
+		"readingTimeRange": "{{ .Min }} à {{ .Max }} minutes de lecture", "readingTimeUnderMinute": "Moins d'une minute",
+	},
+	"de": {
+		"minRead": "Min. Lesezeit", "postedBy": "Veröffentlicht von",
+		"readMore": "Weiterlesen", "recentPosts": "Neueste Beiträge",
+		"skipToContent": "Zum Inhalt springen", "mainNavigation": "Hauptnavigation",
+		"sectionNavigation": "Bereichsnavigation", "tableOfContents": "Inhaltsverzeichnis",
+		"copyCode": "Kopieren", "codeCopied": "Kopiert",
+		"readingTime": "{{ .Count }} Min. Lesezeit", "readingTimeRange": "{{ .Min }}–{{ .Max }} Min. Lesezeit",
+		"readingTimeUnderMinute": "Weniger als eine Minute",
+	},
+	"es": {
+		"minRead": "min de lectura", "postedBy": "Publicado por",
+		"readMore": "Leer más", "recentPosts": "Entradas recientes",
+		"skipToContent": "Saltar al contenido", "mainNavigation": "Navegación principal",
+		"sectionNavigation": "Navegación de la sección", "tableOfContents": "Tabla de contenidos",
+		"copyCode": "Copiar", "codeCopied": "Copiado",
+		"readingTime": "{{ .Count }} minutos de lectura", "readingTime.one": "{{ .Count }} minuto de lectura",
+		"readingTimeRange": "{{ .Min }}–{{ .Max }} minutos de lectura", "readingTimeUnderMinute": "Menos de un minuto",
+	},
+}
+
+// LoadTranslations reads <lang>.toml files from each directory. Later
+// directories override earlier ones, so pass the theme's directory before
+// the site's. Values are plain strings, or tables of plural forms with at
+// least an "other" entry.
+func LoadTranslations(dirs ...string) (Translations, error) {
+	translations := make(Translations)
+	for lang, strs := range builtinTranslations {
+		translations[lang] = make(map[string]string, len(strs))
+		for key, value := range strs {
+			translations[lang][key] = value
+		}
+	}
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		files, err := filepath.Glob(filepath.Join(dir, "*.toml"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			tree, err := toml.LoadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse translations %s: %w", file, err)
+			}
+
+			lang := strings.ToLower(strings.TrimSuffix(filepath.Base(file), ".toml"))
+			if translations[lang] == nil {
+				translations[lang] = make(map[string]string)
+			}
+			for key, value := range tree.ToMap() {
+				switch v := value.(type) {
+				case string:
+					translations[lang][key] = v
+				case map[string]interface{}:
+					for form, text := range v {
+						text, ok := text.(string)
+						switch {
+						case !ok:
+						case form == "other":
+							translations[lang][key] = text
+						case pluralForms[form]:
+							translations[lang][key+"."+form] = text
+						}
+					}
+				}
+			}
+		}
+	}
+	return translations, nil
+}
+
+// SetTranslations sets the strings used by the i18n template function
+func (e *Engine) SetTranslations(translations Translations) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.translations = translations
+}
+
+// translate is the "i18n" template function. It looks key up in the page or
+// given language, then in the default language, and finally returns the key
+// itself.
+func (e *Engine) translate(key string, args ...interface{}) string {
+	lang := strings.ToLower(e.languageOf(args))
+	if value, ok := e.lookupTranslation(lang, key); ok {
+		return value
+	}
+
+	fallback := strings.ToLower(e.config.DefaultContentLanguage)
+	if fallback == "" {
+		fallback = "en"
+	}
+	value, ok := e.lookupTranslation(fallback, key)
+	if e.config.WarnMissingTranslations {
+		if _, warned := e.missingTranslations.LoadOrStore(lang+"/"+key, true); !warned {
+			logging.Warnf("⚠️  Missing %q translation for %q", lang, key)
+		}
+	}
+	if ok {
+		return value
+	}
+	return key
+}
+
+// pluralForms are the CLDR plural categories a translation table may give
+var pluralForms = map[string]bool{"zero": true, "one": true, "two": true, "few": true, "many": true}
+
+// pluralForm returns the plural category of count in lang. Only the "one"
+// and "other" categories are told apart: French and Portuguese use the
+// singular for 0 and 1, most other languages for 1 alone.
+func pluralForm(lang string, count int) string {
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	switch base {
+	case "fr", "pt":
+		if count == 0 || count == 1 {
+			return "one"
+		}
+	default:
+		if count == 1 {
+			return "one"
+		}
+	}
+	return "other"
+}
+
+// translatePlural translates key in the plural form count takes in the page
+// or given language, falling back to the key's "other" form
+func (e *Engine) translatePlural(key string, count int, args ...interface{}) string {
+	lang := strings.ToLower(e.languageOf(args))
+	if form := pluralForm(lang, count); form != "other" {
+		if value, ok := e.lookupTranslation(lang, key+"."+form); ok {
+			return value
+		}
+	}
+	return e.translate(key, args...)
+}
+
+// lookupTranslation finds key for lang, trying the base language of a
+// regional code such as "pt-br" as well
+func (e *Engine) lookupTranslation(lang, key string) (string, bool) {
+	if value, ok := e.translations[lang][key]; ok {
+		return value, true
+	}
+	if base, _, found := strings.Cut(lang, "-"); found {
+		value, ok := e.translations[base][key]
+		return value, ok
+	}
+	return "", false
+}
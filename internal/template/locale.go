@@ -0,0 +1,245 @@
+package template
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"vango/internal/config"
+	"vango/internal/content"
+	"vango/internal/logging"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// dateLocale holds the month and weekday names and date phrasing for a language
+type dateLocale struct {
+	months   [12]string
+	days     [7]string    // Sunday first, like time.Weekday
+	shortLen int          // Runes kept for abbreviated names
+	humanize string       // Layout used by humanizeDate
+	ago      string       // Format for relative times, e.g. "%s ago"
+	units    [5][2]string // Singular and plural of hour, day, week, month, year
+}
+
+// dateLocales are the languages dates can be formatted in. Other languages
+// fall back to the closest match, or English, with a warning when the site
+// is configured for one of them.
+var dateLocales = map[string]*dateLocale{
+	"en": {
+		months:   [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		days:     [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		shortLen: 3,
+		humanize: "January 2, 2006",
+		ago:      "%s ago",
+		units:    [5][2]string{{"hour", "hours"}, {"day", "days"}, {"week", "weeks"}, {"month", "months"}, {"year", "years"}},
+	},
+	"fr": {
+		months:   [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		days:     [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		shortLen: 4,
+		humanize: "2 January 2006",
+		ago:      "il y a %s",
+		units:    [5][2]string{{"heure", "heures"}, {"jour", "jours"}, {"semaine", "semaines"}, {"mois", "mois"}, {"an", "ans"}},
+	},
+	"de": {
+		months:   [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		days:     [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		shortLen: 3,
+		humanize: "2. January 2006",
+		ago:      "vor %s",
+		units:    [5][2]string{{"Stunde", "Stunden"}, {"Tag", "Tagen"}, {"Woche", "Wochen"}, {"Monat", "Monaten"}, {"Jahr", "Jahren"}},
+	},
+	"es": {
+		months:   [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		days:     [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		shortLen: 3,
+		humanize: "2 de January de 2006",
+		ago:      "hace %s",
+		units:    [5][2]string{{"hora", "horas"}, {"día", "días"}, {"semana", "semanas"}, {"mes", "meses"}, {"año", "años"}},
+	},
+	"it": {
+		months:   [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		days:     [7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+		shortLen: 3,
+		humanize: "2 January 2006",
+		ago:      "%s fa",
+		units:    [5][2]string{{"ora", "ore"}, {"giorno", "giorni"}, {"settimana", "settimane"}, {"mese", "mesi"}, {"anno", "anni"}},
+	},
+	"pt": {
+		months:   [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		days:     [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+		shortLen: 3,
+		humanize: "2 de January de 2006",
+		ago:      "há %s",
+		units:    [5][2]string{{"hora", "horas"}, {"dia", "dias"}, {"semana", "semanas"}, {"mês", "meses"}, {"ano", "anos"}},
+	},
+	"nl": {
+		months:   [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		days:     [7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+		shortLen: 3,
+		humanize: "2 January 2006",
+		ago:      "%s geleden",
+		units:    [5][2]string{{"uur", "uur"}, {"dag", "dagen"}, {"week", "weken"}, {"maand", "maanden"}, {"jaar", "jaar"}},
+	},
+}
+
+// localeTags lists the supported languages for matching, English first so
+// it is the fallback
+var localeTags = []language.Tag{
+	language.English, language.French, language.German, language.Spanish,
+	language.Italian, language.Portuguese, language.Dutch,
+}
+
+var localeMatcher = language.NewMatcher(localeTags)
+
+// localeFor returns the date locale closest to a language code such as
+// "fr" or "pt-BR"
+func localeFor(lang string) *dateLocale {
+	base, _ := matchLocale(lang)
+	return dateLocales[base]
+}
+
+// matchLocale returns the language of the date locale closest to a
+// language code, and whether it is the code's own language rather than a
+// neighbour or the English fallback
+func matchLocale(lang string) (string, bool) {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return "en", false
+	}
+	_, index, confidence := localeMatcher.Match(tag)
+	if confidence == language.No {
+		return "en", false
+	}
+	matched, _ := localeTags[index].Base()
+	want, _ := tag.Base()
+	return matched.String(), matched == want
+}
+
+// warnMissingLocales warns about each configured language whose dates are
+// spelled in another language for want of locale data
+func warnMissingLocales(cfg *config.Config) {
+	codes := []string{cfg.Language, cfg.DefaultContentLanguage}
+	for code := range cfg.Languages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		if base, ok := matchLocale(code); !ok {
+			logging.Warnf("⚠️  No date locale for language %q; humanizeDate, dateFormatLocale and timeAgo spell dates in %q", code, base)
+		}
+	}
+}
+
+// languageOf resolves the language for a date or translation function from
+// its optional argument: a page, a language code, or nothing for the site
+// language
+func (e *Engine) languageOf(args []interface{}) string {
+	if len(args) > 0 {
+		switch v := args[0].(type) {
+		case *content.Page:
+			if v != nil && v.Language != "" {
+				return v.Language
+			}
+		case string:
+			if v != "" {
+				return v
+			}
+		}
+	}
+	if e.config.Language != "" {
+		return e.config.Language
+	}
+	return e.config.DefaultContentLanguage
+}
+
+// formatDateLocale formats date with a Go reference layout, spelling month
+// and weekday names in the given language
+func formatDateLocale(layout string, date time.Time, lang string) string {
+	locale := localeFor(lang)
+
+	// Name tokens, longest first so "January" wins over "Jan"
+	names := []struct {
+		token string
+		value string
+	}{
+		{"January", locale.months[date.Month()-1]},
+		{"Monday", locale.days[date.Weekday()]},
+		{"Jan", abbreviate(locale.months[date.Month()-1], locale.shortLen)},
+		{"Mon", abbreviate(locale.days[date.Weekday()], locale.shortLen)},
+	}
+
+	var out strings.Builder
+	for layout != "" {
+		next, token := len(layout), -1
+		for i, name := range names {
+			if at := strings.Index(layout, name.token); at >= 0 && at < next {
+				next, token = at, i
+			}
+		}
+		if next > 0 {
+			out.WriteString(date.Format(layout[:next]))
+		}
+		if token < 0 {
+			break
+		}
+		out.WriteString(names[token].value)
+		layout = layout[next+len(names[token].token):]
+	}
+	return out.String()
+}
+
+// abbreviate shortens a month or weekday name to n runes
+func abbreviate(name string, n int) string {
+	runes := []rune(name)
+	if len(runes) <= n {
+		return name
+	}
+	return string(runes[:n])
+}
+
+// timeAgoLocale describes how long ago date was, in the given language
+func timeAgoLocale(date time.Time, lang string) string {
+	locale := localeFor(lang)
+	hours := time.Since(date).Hours()
+
+	var amount float64
+	var unit int
+	switch {
+	case hours < 24:
+		amount, unit = hours, 0
+	case hours < 24*7:
+		amount, unit = hours/24, 1
+	case hours < 24*30:
+		amount, unit = hours/(24*7), 2
+	case hours < 24*365:
+		amount, unit = hours/(24*30), 3
+	default:
+		amount, unit = hours/(24*365), 4
+	}
+
+	count := fmt.Sprintf("%.0f", amount)
+	name := locale.units[unit][1]
+	if count == "1" {
+		name = locale.units[unit][0]
+	}
+	return fmt.Sprintf(locale.ago, count+" "+name)
+}
+
+// formatNumber writes n with the digit grouping and decimal mark of the
+// given language, e.g. 240,000 in English and 240.000 in German
+func formatNumber(n interface{}, lang string) string {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		tag = language.English
+	}
+	return message.NewPrinter(tag).Sprint(n)
+}
@@ -0,0 +1,88 @@
+package template
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// TemplateTiming is the cumulative execution time of a template. Templates
+// included with {{template}} count towards the template including them.
+type TemplateTiming struct {
+	Name    string        `json:"name"`
+	Count   int64         `json:"count"`
+	Total   time.Duration `json:"total"`
+	Average time.Duration `json:"average"`
+}
+
+// metrics accumulates template execution times
+type metrics struct {
+	mu      sync.Mutex
+	timings map[string]*TemplateTiming
+}
+
+// record adds the execution of name that started at start
+func (m *metrics) record(name string, start time.Time) {
+	elapsed := time.Since(start)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	timing, ok := m.timings[name]
+	if !ok {
+		timing = &TemplateTiming{Name: name}
+		m.timings[name] = timing
+	}
+	timing.Count++
+	timing.Total += elapsed
+}
+
+// SetMetrics enables or disables recording template execution times.
+// Disabled, rendering skips the bookkeeping entirely.
+func (e *Engine) SetMetrics(enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !enabled {
+		e.metrics = nil
+	} else if e.metrics == nil {
+		e.metrics = &metrics{timings: make(map[string]*TemplateTiming)}
+	}
+}
+
+// ResetMetrics discards the template execution times recorded so far
+func (e *Engine) ResetMetrics() {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.metrics == nil {
+		return
+	}
+	e.metrics.mu.Lock()
+	e.metrics.timings = make(map[string]*TemplateTiming)
+	e.metrics.mu.Unlock()
+}
+
+// TemplateTimings returns the recorded template execution times, slowest
+// in total first, or nil when metrics are disabled
+func (e *Engine) TemplateTimings() []TemplateTiming {
+	e.mu.RLock()
+	m := e.metrics
+	e.mu.RUnlock()
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	timings := make([]TemplateTiming, 0, len(m.timings))
+	for _, timing := range m.timings {
+		t := *timing
+		t.Average = t.Total / time.Duration(t.Count)
+		timings = append(timings, t)
+	}
+	m.mu.Unlock()
+
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].Total != timings[j].Total {
+			return timings[i].Total > timings[j].Total
+		}
+		return timings[i].Name < timings[j].Name
+	})
+	return timings
+}
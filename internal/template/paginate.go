@@ -0,0 +1,249 @@
+package template
+
+import (
+	"fmt"
+	"html/template"
+	"text/template/parse"
+
+	"vango/internal/content"
+)
+
+// Paginator is one pager of a paginated list page, as paginate returns it
+type Paginator struct {
+	Pages      content.Pages // The pages on this pager
+	PageNumber int           // 1 for the list page itself
+	PageSize   int           // Pages per pager
+	TotalPages int           // Number of pagers, at least 1
+	TotalItems int           // Pages on all pagers
+	URL        string        // This pager
+	First      string
+	Last       string
+	Prev       string // "" on the first pager
+	Next       string // "" on the last pager
+	Pagers     []PagerLink
+}
+
+// PagerLink is an entry of a paginator's numbered navigation
+type PagerLink struct {
+	Number    int
+	URL       string
+	IsCurrent bool
+}
+
+// HasPrev reports whether there is a pager before this one
+func (p *Paginator) HasPrev() bool { return p.Prev != "" }
+
+// HasNext reports whether there is a pager after this one
+func (p *Paginator) HasNext() bool { return p.Next != "" }
+
+// pagerContext is the paginate function of one render of a list page. It
+// records the page size the template asked for, so the builder knows how
+// many pagers to write.
+type pagerContext struct {
+	engine *Engine
+	page   *content.Page
+	number int
+	size   int
+	pager  *Paginator
+}
+
+// isListKind reports whether pages of a kind can be paginated
+func isListKind(kind string) bool {
+	return kind == "home" || kind == "section" || kind == "taxonomy" || kind == "term"
+}
+
+// paginateStandIn is paginate outside list pages, where only the slicing
+// form works
+func paginateStandIn(args ...interface{}) (interface{}, error) {
+	if len(args) == 3 {
+		return paginateSlice(args)
+	}
+	return nil, fmt.Errorf("paginate: only home, section, taxonomy and term pages can be paginated")
+}
+
+// paginateSlice is the older paginate items page perPage, which returns
+// page of items, perPage to a page, and nothing for pages out of range
+func paginateSlice(args []interface{}) (interface{}, error) {
+	items, ok := args[0].([]interface{})
+	page, okPage := args[1].(int)
+	perPage, okPer := args[2].(int)
+	if !ok || !okPage || !okPer {
+		return nil, fmt.Errorf("paginate: want paginate items page perPage, got %T, %T and %T", args[0], args[1], args[2])
+	}
+	if page < 1 || perPage < 1 || (page-1)*perPage >= len(items) {
+		return []interface{}{}, nil
+	}
+	start := (page - 1) * perPage
+	return items[start:min(start+perPage, len(items))], nil
+}
+
+// paginate is the "paginate" template function of a list page render:
+// paginate .Pages, with the configured size for the page's kind, or
+// paginate 20 .Pages. A size below 1 puts every page on one pager.
+func (c *pagerContext) paginate(args ...interface{}) (interface{}, error) {
+	size := c.engine.config.PagerSize(c.page.Kind)
+	var list interface{}
+	switch len(args) {
+	case 1:
+		list = args[0]
+	case 2:
+		n, ok := args[0].(int)
+		if !ok {
+			return nil, fmt.Errorf("paginate: size must be a number, not %T", args[0])
+		}
+		size, list = max(n, 0), args[1]
+	case 3:
+		return paginateSlice(args)
+	default:
+		return nil, fmt.Errorf("paginate: want paginate [size] pages, got %d arguments", len(args))
+	}
+	var pages content.Pages
+	switch v := list.(type) {
+	case content.Pages:
+		pages = v
+	case []*content.Page:
+		pages = v
+	default:
+		return nil, fmt.Errorf("paginate: can't paginate %T", list)
+	}
+
+	if c.pager != nil {
+		if size != c.size {
+			return nil, fmt.Errorf("paginate: %s calls paginate with %d and %d pages per pager; a page can only be paginated one way", c.page.URL, c.size, size)
+		}
+		return c.pager, nil
+	}
+	c.size = size
+	c.pager = c.engine.paginator(c.page, pages, size, c.number)
+	return c.pager, nil
+}
+
+// paginator builds pager number of page's pages, size to a pager
+func (e *Engine) paginator(page *content.Page, pages content.Pages, size, number int) *Paginator {
+	perPager := size
+	if perPager < 1 {
+		perPager = max(len(pages), 1)
+	}
+	total := max((len(pages)+perPager-1)/perPager, 1)
+	number = min(max(number, 1), total)
+
+	p := &Paginator{
+		PageNumber: number,
+		PageSize:   perPager,
+		TotalPages: total,
+		TotalItems: len(pages),
+		URL:        e.config.PagerURL(page.URL, number),
+		First:      e.config.PagerURL(page.URL, 1),
+		Last:       e.config.PagerURL(page.URL, total),
+	}
+	start := min((number-1)*perPager, len(pages))
+	p.Pages = pages[start:min(start+perPager, len(pages))]
+	if number > 1 {
+		p.Prev = e.config.PagerURL(page.URL, number-1)
+	}
+	if number < total {
+		p.Next = e.config.PagerURL(page.URL, number+1)
+	}
+	for n := 1; n <= total; n++ {
+		p.Pagers = append(p.Pagers, PagerLink{Number: n, URL: e.config.PagerURL(page.URL, n), IsCurrent: n == number})
+	}
+	return p
+}
+
+// result returns the paginator the render asked for, or nil
+func (c *pagerContext) result() *Paginator {
+	if c == nil {
+		return nil
+	}
+	return c.pager
+}
+
+// pagerSet returns the template set to render pager number of page with.
+// Template functions can't tell which page they render for, and renders
+// run in parallel, so a list page gets its own copy of the set with a
+// paginate bound to the render. Copies are made from sets no page has
+// executed yet, since html/template can't copy executed ones.
+func (e *Engine) pagerSet(set *template.Template, page *content.Page, number int) (*template.Template, *pagerContext, error) {
+	pristine := e.pristine[set]
+	if pristine == nil || !isListKind(page.Kind) {
+		return set, nil, nil
+	}
+	clone, err := pristine.Clone()
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx := &pagerContext{engine: e, page: page, number: number}
+	clone.Funcs(template.FuncMap{"paginate": ctx.paginate})
+	return clone, ctx, nil
+}
+
+// preparePagers keeps an unexecuted copy of every template set for
+// pagerSet, when any template calls paginate. The caller holds e.mu.
+func (e *Engine) preparePagers() error {
+	e.pristine = nil
+	calls := false
+	for _, tmpl := range e.templates.Templates() {
+		if tmpl.Tree != nil && callsFunc(tmpl.Tree.Root, "paginate") {
+			calls = true
+			break
+		}
+	}
+	if !calls {
+		return nil
+	}
+
+	e.pristine = make(map[*template.Template]*template.Template)
+	sets := []*template.Template{e.templates}
+	for _, set := range e.bound {
+		sets = append(sets, set)
+	}
+	for _, set := range sets {
+		clone, err := set.Clone()
+		if err != nil {
+			return err
+		}
+		e.pristine[set] = clone
+	}
+	return nil
+}
+
+// callsFunc reports whether the template under node calls the function name
+func callsFunc(node parse.Node, name string) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if callsFunc(child, name) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return callsFunc(n.Pipe, name)
+	case *parse.TemplateNode:
+		return callsFunc(n.Pipe, name)
+	case *parse.IfNode:
+		return callsFunc(n.Pipe, name) || callsFunc(n.List, name) || callsFunc(n.ElseList, name)
+	case *parse.RangeNode:
+		return callsFunc(n.Pipe, name) || callsFunc(n.List, name) || callsFunc(n.ElseList, name)
+	case *parse.WithNode:
+		return callsFunc(n.Pipe, name) || callsFunc(n.List, name) || callsFunc(n.ElseList, name)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if callsFunc(arg, name) {
+					return true
+				}
+			}
+		}
+	case *parse.ChainNode:
+		return callsFunc(n.Node, name)
+	case *parse.IdentifierNode:
+		return n.Ident == name
+	}
+	return false
+}
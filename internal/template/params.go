@@ -0,0 +1,124 @@
+package template
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"vango/internal/content"
+)
+
+// lookupPath walks a dot-separated path such as "hero.image" through nested
+// maps. Keys match exactly or, failing that, case-insensitively. Missing
+// levels report false rather than an error.
+func lookupPath(value interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return value, value != nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		var ok bool
+		if value, ok = mapValue(value, key); !ok {
+			return nil, false
+		}
+	}
+	return value, value != nil
+}
+
+// mapValue returns m[key] for the map shapes front matter decodes to: TOML
+// and JSON give string keys, YAML gives interface{} keys
+func mapValue(m interface{}, key string) (interface{}, bool) {
+	switch m := m.(type) {
+	case map[string]interface{}:
+		if v, ok := m[key]; ok {
+			return v, true
+		}
+		for k, v := range m {
+			if strings.EqualFold(k, key) {
+				return v, true
+			}
+		}
+	case map[interface{}]interface{}:
+		if v, ok := m[key]; ok {
+			return v, true
+		}
+		for k, v := range m {
+			if strings.EqualFold(fmt.Sprint(k), key) {
+				return v, true
+			}
+		}
+	case map[string]string:
+		if v, ok := m[key]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// pageParam looks a dot path up in the page's params, then its other front
+// matter fields, then the site params
+func (e *Engine) pageParam(page *content.Page, path string) interface{} {
+	if page != nil {
+		if v, ok := lookupPath(page.Params, path); ok {
+			return v
+		}
+		if v, ok := lookupPath(page.FrontMatter, path); ok {
+			return v
+		}
+	}
+	return e.siteParam(path)
+}
+
+// siteParam looks a dot path up in the site params
+func (e *Engine) siteParam(path string) interface{} {
+	if v, ok := lookupPath(e.config.Params, path); ok {
+		return v
+	}
+	return nil
+}
+
+// paramString returns a param as a string, or def when it is missing or empty
+func (e *Engine) paramString(page *content.Page, path string, def string) string {
+	switch v := e.pageParam(page, path).(type) {
+	case nil:
+		return def
+	case string:
+		if v == "" {
+			return def
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// paramBool returns a param as a bool, or def when it is missing or not a boolean
+func (e *Engine) paramBool(page *content.Page, path string, def bool) bool {
+	switch v := e.pageParam(page, path).(type) {
+	case bool:
+		return v
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+// paramInt returns a param as an int, or def when it is missing or not a number
+func (e *Engine) paramInt(page *content.Page, path string, def int) int {
+	switch v := e.pageParam(page, path).(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case uint64:
+		return int(v)
+	case float64:
+		return int(v)
+	case string:
+		if i, err := strconv.Atoi(v); err == nil {
+			return i
+		}
+	}
+	return def
+}
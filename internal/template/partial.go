@@ -0,0 +1,68 @@
+package template
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"text/template/parse"
+)
+
+// partialName returns the template a partial call executes: partial
+// "header" and partial "header.html" both run partials/header
+func partialName(name string) string {
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "/"), ".html")
+	if !strings.HasPrefix(name, "partials/") {
+		name = "partials/" + name
+	}
+	return name
+}
+
+// partial is the "partial" template function: partial "name" [context]
+// executes a partial with context as its dot and returns its output. It
+// runs inside a render, which already holds e.mu.
+func (e *Engine) partial(name string, context ...interface{}) (template.HTML, error) {
+	if len(context) > 1 {
+		return "", fmt.Errorf("partial %q: want partial name [context], got %d arguments", name, len(context)+1)
+	}
+	tmpl := e.templates.Lookup(partialName(name))
+	if tmpl == nil || tmpl.Tree == nil {
+		return "", fmt.Errorf("partial %q: template %s not found", name, partialName(name))
+	}
+	var data interface{}
+	if len(context) == 1 {
+		data = context[0]
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("partial %q: %w", name, err)
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// partialRefs collects the partials a pipeline calls with a literal name,
+// such as partial "header" ., including in parenthesized arguments. Names
+// computed at render time can't be known here.
+func partialRefs(node parse.Node) []string {
+	var refs []string
+	switch n := node.(type) {
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			refs = append(refs, partialRefs(cmd)...)
+		}
+	case *parse.CommandNode:
+		if len(n.Args) > 1 {
+			if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "partial" {
+				if name, ok := n.Args[1].(*parse.StringNode); ok {
+					refs = append(refs, partialName(name.Text))
+				}
+			}
+		}
+		for _, arg := range n.Args {
+			refs = append(refs, partialRefs(arg)...)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,52 @@
+package template
+
+import (
+	"strconv"
+	"strings"
+
+	"vango/internal/content"
+	"vango/internal/util"
+)
+
+// readingTimeText is the "readingTimeText" template function. It describes
+// a page's reading time in its language, e.g. "3 min read", rounded as
+// readingTime.rounding asks and with "less than a minute" below
+// readingTime.underMinute seconds.
+func (e *Engine) readingTimeText(page *content.Page) string {
+	if page == nil {
+		return ""
+	}
+	words := page.WordCount
+	if limit := e.config.ReadingTime.UnderMinute; limit > 0 && words*60/util.WordsPerMinute < limit {
+		return e.translate("readingTimeUnderMinute", page)
+	}
+
+	ceil := util.ReadingTime(words)
+	floor := words / util.WordsPerMinute
+	if floor < 1 {
+		floor = 1
+	}
+	minutes := ceil
+	switch e.config.ReadingTime.Rounding {
+	case "nearest":
+		minutes = (words + util.WordsPerMinute/2) / util.WordsPerMinute
+		if minutes < 1 {
+			minutes = 1
+		}
+	case "range":
+		if floor != ceil {
+			return fillCounts(e.translate("readingTimeRange", page), map[string]int{"Min": floor, "Max": ceil})
+		}
+	}
+	return fillCounts(e.translatePlural("readingTime", minutes, page), map[string]int{"Count": minutes})
+}
+
+// fillCounts replaces {{ .Name }} placeholders in a translation with numbers
+func fillCounts(text string, counts map[string]int) string {
+	for name, n := range counts {
+		value := strconv.Itoa(n)
+		text = strings.ReplaceAll(text, "{{ ."+name+" }}", value)
+		text = strings.ReplaceAll(text, "{{."+name+"}}", value)
+	}
+	return text
+}
@@ -0,0 +1,145 @@
+package template
+
+import (
+	"fmt"
+	"text/template/parse"
+)
+
+// With themes.sandbox, the active theme's templates are untrusted: they may
+// come from anyone, while their data, such as page params, may come from
+// content the site doesn't vet either. Their parse trees are rewritten as
+// they load so that
+//
+//   - safeHTML, safeCSS and safeJS only mark string constants as safe; on
+//     anything else they return the plain string, which html/template
+//     escapes like any other value.
+//   - functions in sandboxDisabled fail the template load.
+//
+// Files stay inside their directories whoever calls: resource only reads
+// the assets directories and themeAsset and assetFingerprint the static
+// ones, refusing paths that climb out. The site's own layouts and the
+// built-in templates keep every function.
+
+// sandboxSafe maps the functions that mark strings as safe to the
+// escaping versions sandboxed templates call instead
+var sandboxSafe = map[string]string{
+	"safeHTML": "sandboxedSafeHTML",
+	"safeCSS":  "sandboxedSafeCSS",
+	"safeJS":   "sandboxedSafeJS",
+}
+
+// sandboxDisabled lists the functions sandboxed templates may not call at
+// all: any that run commands or reach outside the site, such as hooks or
+// plugins exposed to templates
+var sandboxDisabled = map[string]bool{}
+
+// addSandboxFuncs registers the escaping stand-ins for sandboxSafe
+func addSandboxFuncs(funcs map[string]interface{}) {
+	for _, name := range sandboxSafe {
+		funcs[name] = func(s string) string {
+			return s
+		}
+	}
+}
+
+// isSandboxFunc reports whether name is one of the stand-ins, which only
+// rewritten templates call
+func isSandboxFunc(name string) bool {
+	for _, stand := range sandboxSafe {
+		if stand == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sandboxTrees returns copies of a theme file's parse trees rewritten for
+// the sandbox
+func sandboxTrees(path string, trees map[string]*parse.Tree) (map[string]*parse.Tree, error) {
+	sandboxed := make(map[string]*parse.Tree, len(trees))
+	for name, tree := range trees {
+		tree = tree.Copy()
+		if err := sandboxNode(tree.Root); err != nil {
+			return nil, fmt.Errorf("themes.sandbox: %s %w", path, err)
+		}
+		sandboxed[name] = tree
+	}
+	return sandboxed, nil
+}
+
+// sandboxNode rewrites the function calls under node
+func sandboxNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := sandboxNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return sandboxNode(n.Pipe)
+	case *parse.TemplateNode:
+		return sandboxNode(n.Pipe)
+	case *parse.IfNode:
+		return sandboxBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		return sandboxBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return sandboxBranch(&n.BranchNode)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for i, cmd := range n.Cmds {
+			if err := sandboxCommand(cmd, i == 0); err != nil {
+				return err
+			}
+		}
+	case *parse.ChainNode:
+		return sandboxNode(n.Node)
+	case *parse.IdentifierNode:
+		if sandboxDisabled[n.Ident] {
+			return fmt.Errorf("calls %s, which sandboxed themes may not use", n.Ident)
+		}
+		if stand, ok := sandboxSafe[n.Ident]; ok {
+			n.Ident = stand
+		}
+	}
+	return nil
+}
+
+func sandboxBranch(n *parse.BranchNode) error {
+	for _, child := range []parse.Node{n.Pipe, n.List, n.ElseList} {
+		if err := sandboxNode(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sandboxCommand rewrites a command of a pipeline. A safe function called
+// first in its pipeline with only string constants is left alone, since the
+// theme author wrote the markup it trusts.
+func sandboxCommand(cmd *parse.CommandNode, first bool) error {
+	args := cmd.Args
+	if ident, ok := args[0].(*parse.IdentifierNode); ok && first && len(args) > 1 && sandboxSafe[ident.Ident] != "" {
+		constant := true
+		for _, arg := range args[1:] {
+			if _, ok := arg.(*parse.StringNode); !ok {
+				constant = false
+			}
+		}
+		if constant {
+			return nil
+		}
+	}
+	for _, arg := range args {
+		if err := sandboxNode(arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
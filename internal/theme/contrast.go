@@ -0,0 +1,100 @@
+package theme
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// minContrastRatio is the WCAG AA minimum contrast for normal text
+const minContrastRatio = 4.5
+
+// contrastPairs are the theme colors drawn as text over a background
+var contrastPairs = []struct {
+	foreground, background string
+}{
+	{"text", "background"},
+	{"text_muted", "background"},
+	{"primary", "background"},
+	{"text", "surface"},
+	{"text_muted", "surface"},
+}
+
+// CheckContrast warns about color pairs in the active theme's config.json
+// whose contrast ratio is below the WCAG AA minimum for normal text. Themes
+// without a config.json declare no colors and aren't checked, nor are
+// colors that aren't hex values.
+func (tm *ThemeManager) CheckContrast() ([]ValidationIssue, error) {
+	if tm.activeTheme == nil {
+		return nil, nil
+	}
+	file := filepath.Join(tm.activeTheme.Path, "config.json")
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return nil, nil
+	}
+	cfg, err := tm.readThemeConfig()
+	if err != nil {
+		return nil, err
+	}
+	colors := map[string]string{
+		"background": cfg.Colors.Background,
+		"surface":    cfg.Colors.Surface,
+		"text":       cfg.Colors.Text,
+		"text_muted": cfg.Colors.TextMuted,
+		"primary":    cfg.Colors.Primary,
+	}
+
+	report := &ValidationReport{}
+	for _, pair := range contrastPairs {
+		fg, bg := colors[pair.foreground], colors[pair.background]
+		ratio, ok := ContrastRatio(fg, bg)
+		if ok && ratio < minContrastRatio {
+			report.addWarning(file, "colors.%s %s on colors.%s %s has contrast %.2f:1, below %.1f:1",
+				pair.foreground, fg, pair.background, bg, ratio, minContrastRatio)
+		}
+	}
+	return report.Warnings, nil
+}
+
+// ContrastRatio returns the WCAG contrast ratio of two hex colors such as
+// "#333" or "#ffffff", and false when either can't be parsed
+func ContrastRatio(a, b string) (float64, bool) {
+	la, ok := relativeLuminance(a)
+	if !ok {
+		return 0, false
+	}
+	lb, ok := relativeLuminance(b)
+	if !ok {
+		return 0, false
+	}
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05), true
+}
+
+// relativeLuminance returns the WCAG relative luminance of a hex color
+func relativeLuminance(color string) (float64, bool) {
+	hex := strings.TrimPrefix(strings.TrimSpace(color), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	channel := func(shift uint) float64 {
+		c := float64((value>>shift)&0xff) / 255
+		if c <= 0.03928 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(16) + 0.7152*channel(8) + 0.0722*channel(0), true
+}
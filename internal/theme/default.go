@@ -3,25 +3,38 @@ package theme
 
 import _ "embed"
 
+// The built-in templates render sites that have neither a theme nor their
+// own layouts
 
+//go:embed defaults/single.html
 var defaultSingleTemplate string
 
-
+//go:embed defaults/list.html
 var defaultListTemplate string
 
+//go:embed defaults/partials/menu.html
+var defaultMenuPartial string
+
+//go:embed defaults/partials/breadcrumbs.html
+var defaultBreadcrumbsPartial string
 
-var defaultCSS string
+//go:embed defaults/partials/code-copy.html
+var defaultCodeCopyPartial string
 
+// GetDefaultTheme returns the built-in theme, whose templates the engine
+// falls back to for the layouts no theme or site provides
 func (tm *ThemeManager) GetDefaultTheme() *Theme {
-    return &Theme{
-        Name: "default",
-        Version: "1.0.0",
-        Description: "Built-in default theme for Vango",
-        Author: "Vango Team",
-        Templates: map[string]string{
-            "layouts/_default/single.html": defaultSingleTemplate,
-            "layouts/_default/list.html": defaultListTemplate,
-        },
-        CSS: defaultCSS,
-    }
-}
\ No newline at end of file
+	return &Theme{
+		Name:        "default",
+		Version:     "1.0.0",
+		Description: "Built-in default theme for Vango",
+		Author:      "Vango Team",
+		Templates: map[string]string{
+			"layouts/_default/single.html":      defaultSingleTemplate,
+			"layouts/_default/list.html":        defaultListTemplate,
+			"layouts/partials/menu.html":        defaultMenuPartial,
+			"layouts/partials/breadcrumbs.html": defaultBreadcrumbsPartial,
+			"layouts/partials/code-copy.html":   defaultCodeCopyPartial,
+		},
+	}
+}
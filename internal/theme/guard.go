@@ -0,0 +1,70 @@
+package theme
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+	"strings"
+)
+
+// isComparable reports whether v can be compared with == or used as a map
+// key without panicking
+func isComparable(v interface{}) bool {
+	return v == nil || reflect.TypeOf(v).Comparable()
+}
+
+// valuesEqual compares template values, falling back to a deep comparison
+// for slices, maps and other kinds == panics on
+func valuesEqual(a, b interface{}) bool {
+	if isComparable(a) && isComparable(b) {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// guardFuncs wraps each function so a panic inside it names the function
+// and its arguments. Templates turn the panic into an error for the page
+// being rendered.
+func guardFuncs(funcs template.FuncMap) template.FuncMap {
+	for name, fn := range funcs {
+		funcs[name] = guardFunc(name, fn)
+	}
+	return funcs
+}
+
+// guardFunc wraps fn, which must be a function, as described by guardFuncs
+func guardFunc(name string, fn interface{}) interface{} {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	return reflect.MakeFunc(t, func(args []reflect.Value) (results []reflect.Value) {
+		defer func() {
+			if r := recover(); r != nil {
+				panic(fmt.Errorf("%s(%s) panicked: %v", name, formatArgs(args, t.IsVariadic()), r))
+			}
+		}()
+		if t.IsVariadic() {
+			return v.CallSlice(args)
+		}
+		return v.Call(args)
+	}).Interface()
+}
+
+// formatArgs prints call arguments for an error message, spreading the
+// variadic ones
+func formatArgs(args []reflect.Value, variadic bool) string {
+	if variadic && len(args) > 0 {
+		last := args[len(args)-1]
+		args = args[: len(args)-1 : len(args)-1]
+		for i := 0; i < last.Len(); i++ {
+			args = append(args, last.Index(i))
+		}
+	}
+	formatted := make([]string, len(args))
+	for i, arg := range args {
+		formatted[i] = fmt.Sprintf("%#v", arg.Interface())
+		if len(formatted[i]) > 80 {
+			formatted[i] = formatted[i][:77] + "..."
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
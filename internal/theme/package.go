@@ -0,0 +1,306 @@
+package theme
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"vango/internal/util"
+)
+
+// packageJunk lists development files and directories left out of theme
+// archives
+var packageJunk = map[string]bool{
+	".git":         true,
+	".gitignore":   true,
+	".DS_Store":    true,
+	"Thumbs.db":    true,
+	"node_modules": true,
+	".idea":        true,
+	".vscode":      true,
+}
+
+// CheckPackageMetadata reports the theme.json fields a distributable theme
+// must fill in: description, license, a valid version and a screenshot
+// image that exists in the theme
+func (tm *ThemeManager) CheckPackageMetadata(name string) ([]ValidationIssue, error) {
+	theme, err := readThemeFile(filepath.Join(tm.themesDir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ValidationReport{}
+	if strings.TrimSpace(theme.Description) == "" {
+		report.addError("theme.json", "description is required")
+	}
+	if strings.TrimSpace(theme.License) == "" {
+		report.addError("theme.json", "license is required")
+	}
+	if theme.Version == "" {
+		report.addError("theme.json", "version is required")
+	} else if _, err := parseSemver(theme.Version); err != nil {
+		report.addError("theme.json", "version %q is not a valid version: %v", theme.Version, err)
+	}
+	switch {
+	case theme.Screenshot == "":
+		report.addError("theme.json", "screenshot is required: add %s to the theme, or set \"screenshot\" to an image in it", ScreenshotFile)
+	case !fileExists(filepath.Join(tm.themesDir, name, filepath.FromSlash(theme.Screenshot))):
+		report.addError("theme.json", "screenshot %q does not exist in the theme", theme.Screenshot)
+	default:
+		if err := checkScreenshot(filepath.Join(tm.themesDir, name, filepath.FromSlash(theme.Screenshot))); err != nil {
+			report.addError(theme.Screenshot, "screenshot %v", err)
+		}
+	}
+	return report.Errors, nil
+}
+
+// checkScreenshot reports a screenshot that isn't a PNG or JPEG image
+func checkScreenshot(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return fmt.Errorf("is not a PNG or JPEG image: %v", err)
+	}
+	if cfg.Width == 0 || cfg.Height == 0 {
+		return fmt.Errorf("is empty")
+	}
+	return nil
+}
+
+// PackageTheme writes <name>-<version>.tar.gz of the theme to outDir, without
+// development files, and a .sha256 checksum file next to it. It returns the
+// archive's path.
+func (tm *ThemeManager) PackageTheme(name, outDir string) (string, error) {
+	themePath := filepath.Join(tm.themesDir, name)
+	theme, err := readThemeFile(themePath)
+	if err != nil {
+		return "", err
+	}
+
+	archivePath := filepath.Join(outDir, fmt.Sprintf("%s-%s.tar.gz", name, theme.Version))
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return "", err
+	}
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(f, hash))
+	tw := tar.NewWriter(gz)
+	err = filepath.Walk(themePath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if packageJunk[info.Name()] {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := util.RelSlashPath(themePath, filePath)
+		if err != nil || rel == "." {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(name, rel)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		src, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	if err != nil {
+		os.Remove(archivePath)
+		return "", fmt.Errorf("failed to write %s: %w", archivePath, err)
+	}
+
+	checksum := fmt.Sprintf("%s  %s\n", hex.EncodeToString(hash.Sum(nil)), filepath.Base(archivePath))
+	if err := os.WriteFile(archivePath+".sha256", []byte(checksum), 0644); err != nil {
+		return "", err
+	}
+	return archivePath, nil
+}
+
+// InstallThemeArchive installs a theme from a .tar.gz made by PackageTheme.
+// When a .sha256 file sits next to the archive, the archive must match it.
+// It returns the installed theme's name.
+func (tm *ThemeManager) InstallThemeArchive(archivePath string) (string, error) {
+	if err := verifyChecksum(archivePath); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.MkdirTemp("", "vango-theme-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	if err := extractTarGz(archivePath, tmp); err != nil {
+		return "", fmt.Errorf("failed to extract %s: %w", archivePath, err)
+	}
+
+	// The theme is the archive's top directory, or the archive itself
+	root := tmp
+	if entries, err := os.ReadDir(tmp); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		root = filepath.Join(tmp, entries[0].Name())
+	}
+	theme, err := tm.loadTheme(root)
+	if err != nil {
+		return "", err
+	}
+	if strings.ContainsAny(theme.Name, `/\`) || theme.Name == "." || theme.Name == ".." {
+		return "", fmt.Errorf("invalid theme name %q", theme.Name)
+	}
+	if err := tm.InstallThemeFromPath(root, theme.Name); err != nil {
+		return "", err
+	}
+	return theme.Name, nil
+}
+
+// verifyChecksum compares an archive with the checksum in its .sha256 file,
+// if there is one
+func verifyChecksum(archivePath string) error {
+	data, err := os.ReadFile(archivePath + ".sha256")
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return fmt.Errorf("%s.sha256 is empty", archivePath)
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, fields[0]) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", archivePath, fields[0], sum)
+	}
+	return nil
+}
+
+// extractTarGz extracts the regular files and directories of a .tar.gz into
+// dir, refusing entries that would land outside it
+func extractTarGz(archivePath, dir string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if name == "." {
+			continue
+		}
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("entry %q points outside the archive", header.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tr)
+			out.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readThemeFile reads the theme.json of the theme in themePath. A theme
+// whose theme.json names no screenshot uses screenshot.png at its root.
+func readThemeFile(themePath string) (*Theme, error) {
+	data, err := os.ReadFile(filepath.Join(themePath, "theme.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme.json: %w", err)
+	}
+	var theme Theme
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return nil, fmt.Errorf("failed to parse theme.json: %w", err)
+	}
+	if theme.Screenshot == "" && fileExists(filepath.Join(themePath, ScreenshotFile)) {
+		theme.Screenshot = ScreenshotFile
+	}
+	return &theme, nil
+}
+
+// fileExists reports whether path is an existing regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -9,12 +9,12 @@ package theme
 func (tm *ThemeManager) getBasicCSS() string {
 	return `/* VanGo Basic Theme */
 :root {
-    --color-primary: #007bff;
+    --color-primary: #0066cc;
     --color-secondary: #6c757d;
     --color-background: #ffffff;
     --color-surface: #f8f9fa;
     --color-text: #333333;
-    --color-text-muted: #6c757d;
+    --color-text-muted: #5c636a;
     --color-border: #e9ecef;
     --font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
     --max-width: 800px;
@@ -24,6 +24,18 @@ func (tm *ThemeManager) getBasicCSS() string {
     padding: 0;
     box-sizing: border-box;
 }
+.skip-link {
+    position: absolute;
+    top: -3rem;
+    left: 1rem;
+    z-index: 1000;
+    padding: 0.5rem 1rem;
+    background: #000000;
+    color: #ffffff;
+}
+.skip-link:focus {
+    top: 1rem;
+}
 body {
     font-family: var(--font-family);
     line-height: 1.6;
@@ -167,6 +179,18 @@ func (tm *ThemeManager) getBlogCSS() string {
     padding: 0;
     box-sizing: border-box;
 }
+.skip-link {
+    position: absolute;
+    top: -3rem;
+    left: 1rem;
+    z-index: 1000;
+    padding: 0.5rem 1rem;
+    background: #000000;
+    color: #ffffff;
+}
+.skip-link:focus {
+    top: 1rem;
+}
 body {
     font-family: var(--font-family);
     line-height: 1.6;
@@ -313,6 +337,50 @@ body {
     margin: 1.5rem 0;
     border: 1px solid var(--color-border);
 }
+.post-content .code-block {
+    margin: 1.5rem 0;
+}
+.post-content .code-block pre {
+    margin-top: 0;
+}
+.post-content .code-filename {
+    font-family: 'Courier New', monospace;
+    font-size: 0.85rem;
+    color: var(--color-text-muted);
+    padding: 0.25rem 0;
+}
+.series-nav {
+    margin: 0 3rem 2rem;
+    padding: 1.5rem;
+    background-color: var(--color-surface);
+    border: 1px solid var(--color-border);
+    border-radius: 8px;
+}
+.series-title {
+    font-weight: 600;
+    margin-bottom: 0.75rem;
+}
+.series-title a,
+.series-parts a,
+.series-links a {
+    color: var(--color-primary);
+    text-decoration: none;
+}
+.series-parts {
+    padding-left: 1.5rem;
+    margin-bottom: 1rem;
+}
+.series-parts [aria-current] {
+    font-weight: 600;
+}
+.series-links {
+    display: flex;
+    justify-content: space-between;
+    gap: 1rem;
+}
+.series-next {
+    margin-left: auto;
+}
 .post-share {
     padding: 2rem 3rem;
     border-top: 1px solid var(--color-border);
@@ -449,6 +517,18 @@ func (tm *ThemeManager) getPortfolioCSS() string {
     padding: 0;
     box-sizing: border-box;
 }
+.skip-link {
+    position: absolute;
+    top: -3rem;
+    left: 1rem;
+    z-index: 1000;
+    padding: 0.5rem 1rem;
+    background: #000000;
+    color: #ffffff;
+}
+.skip-link:focus {
+    top: 1rem;
+}
 body {
     font-family: var(--font-family);
     line-height: 1.6;
@@ -637,6 +717,26 @@ body {
     line-height: 1.8;
     margin-bottom: 3rem;
 }
+.project-gallery {
+    display: grid;
+    grid-template-columns: repeat(auto-fill, minmax(240px, 1fr));
+    gap: 1.5rem;
+    margin-bottom: 3rem;
+}
+.gallery-item {
+    margin: 0;
+}
+.gallery-item img {
+    width: 100%;
+    border-radius: 8px;
+    display: block;
+}
+.gallery-item figcaption {
+    color: var(--color-text-muted);
+    font-size: 0.9rem;
+    margin-top: 0.5rem;
+    text-align: center;
+}
 .project-links {
     display: flex;
     gap: 1rem;
@@ -690,7 +790,7 @@ body {
 func (tm *ThemeManager) getDocsCSS() string {
 	return `/* VanGo Docs Theme */
 :root {
-    --color-primary: #059669;
+    --color-primary: #047857;
     --color-secondary: #6b7280;
     --color-accent: #3b82f6;
     --color-background: #ffffff;
@@ -707,6 +807,18 @@ func (tm *ThemeManager) getDocsCSS() string {
     padding: 0;
     box-sizing: border-box;
 }
+.skip-link {
+    position: absolute;
+    top: -3rem;
+    left: 1rem;
+    z-index: 1000;
+    padding: 0.5rem 1rem;
+    background: #000000;
+    color: #ffffff;
+}
+.skip-link:focus {
+    top: 1rem;
+}
 body {
     font-family: var(--font-family);
     line-height: 1.6;
@@ -791,6 +903,28 @@ body {
     background-color: var(--color-primary);
     color: white;
 }
+.sidebar-nav h3 a {
+    color: inherit;
+    padding: 0;
+}
+.sidebar-nav h3 a:hover {
+    background-color: transparent;
+    color: var(--color-primary);
+}
+.sidebar-tree .sidebar-tree {
+    margin-top: 0.5rem;
+    padding-left: 1rem;
+    border-left: 1px solid var(--color-border);
+}
+.sidebar-tree summary {
+    cursor: pointer;
+    color: var(--color-text-muted);
+    font-size: 0.9rem;
+    padding: 0.5rem 1rem 0.5rem 0;
+}
+.sidebar-tree summary a {
+    display: inline-block;
+}
 .docs-main {
     flex: 1;
     margin-left: var(--sidebar-width);
@@ -829,6 +963,16 @@ body {
     color: var(--color-text);
     font-size: 1rem;
 }
+.docs-toc ul {
+    list-style: none;
+    margin: 0;
+    padding: 0;
+}
+.docs-toc li { margin: 0.25rem 0; }
+.docs-toc .toc-level-3 { padding-left: 1rem; }
+.docs-toc .toc-level-4 { padding-left: 2rem; }
+.docs-toc .toc-level-5,
+.docs-toc .toc-level-6 { padding-left: 3rem; }
 .docs-content {
     font-size: 1rem;
     line-height: 1.8;
@@ -889,6 +1033,18 @@ body {
     padding: 0;
     color: var(--color-text);
 }
+.docs-content .code-block {
+    margin: 1.5rem 0;
+}
+.docs-content .code-block pre {
+    margin-top: 0;
+}
+.docs-content .code-filename {
+    font-family: monospace;
+    font-size: 0.85rem;
+    color: var(--color-text-muted);
+    padding: 0.25rem 0;
+}
 .docs-content table {
     width: 100%;
     border-collapse: collapse;
@@ -4,16 +4,23 @@ package theme
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"html/template"
-	"regexp"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"vango/internal/content"
+	"vango/internal/util"
 )
 
-// GetThemeFunctions returns enhanced template functions for themes
+// GetThemeFunctions returns enhanced template functions for themes. A panic
+// inside one fails only the page being rendered, with an error naming the
+// function and its arguments.
 func (tm *ThemeManager) GetThemeFunctions() template.FuncMap {
-	return template.FuncMap{
+	return guardFuncs(template.FuncMap{
 		// Theme-specific functions
 		"themeAsset": tm.getThemeAssetURL,
 		"themeConfig": tm.getThemeConfigValue,
@@ -26,12 +33,12 @@ func (tm *ThemeManager) GetThemeFunctions() template.FuncMap {
 		"wordCount":      tm.countWords,
 		"tableOfContents": tm.generateTOC,
 		"relatedPosts":   tm.getRelatedPosts,
+		"breadcrumbs":    tm.breadcrumbs,
 		
 		// SEO and social functions
 		"metaDescription": tm.generateMetaDescription,
+		"metaKeywords":   tm.metaKeywords,
 		"jsonLD":         tm.generateJSONLD,
-		"openGraph":      tm.generateOpenGraph,
-		"twitterCard":    tm.generateTwitterCard,
 		
 		// Media and asset functions
 		"imageOptimize":  tm.optimizeImage,
@@ -49,7 +56,9 @@ func (tm *ThemeManager) GetThemeFunctions() template.FuncMap {
 		"highlight":      tm.syntaxHighlight,
 		"sanitizeHTML":   tm.sanitizeHTML,
 		"truncateWords":  tm.truncateWords,
-		"slugify":        tm.slugify,
+		"truncate":       tm.truncate,
+		"truncateHTML":   tm.truncateHTML,
+		"slugify":        util.Slugify,
 		
 		// Math and utilities
 		"percentage":     tm.percentage,
@@ -62,22 +71,21 @@ func (tm *ThemeManager) GetThemeFunctions() template.FuncMap {
 		"sortBy":         tm.sortBy,
 		"filterBy":       tm.filterBy,
 		"unique":         tm.unique,
-		"paginate":       tm.paginate,
 		
 		// Conditional helpers
 		"ifNotEmpty":     tm.ifNotEmpty,
 		"ifAny":          tm.ifAny,
 		"ifAll":          tm.ifAll,
 		"switch":         tm.switchCase,
-	}
+	})
 }
 
 // Theme-specific functions
 func (tm *ThemeManager) getThemeAssetURL(path string) string {
 	if tm.activeTheme == nil {
-		return "/static/" + path
+		return tm.config.RelURL(tm.config.StaticPath(path))
 	}
-	return "/theme/" + path
+	return tm.config.RelURL("/theme/" + path)
 }
 
 func (tm *ThemeManager) getThemeConfigValue(key string) interface{} {
@@ -102,7 +110,17 @@ func (tm *ThemeManager) getThemeConfigValue(key string) interface{} {
 	return current
 }
 
+// HasFeature reports whether the active theme enables a feature, as the
+// hasFeature template function does
+func (tm *ThemeManager) HasFeature(feature string) bool {
+	return tm.hasFeature(feature)
+}
+
 func (tm *ThemeManager) hasFeature(feature string) bool {
+	if tm.activeTheme == nil {
+		// Without a theme only the site's own settings turn features on
+		return feature == "analytics" && tm.config.AnalyticsEnabled()
+	}
 	config, err := tm.GetThemeConfig()
 	if err != nil {
 		return false
@@ -124,7 +142,9 @@ func (tm *ThemeManager) hasFeature(feature string) bool {
 	case "related_posts":
 		return config.Features.RelatedPosts
 	case "analytics":
-		return config.Features.Analytics
+		return config.Features.Analytics || tm.config.AnalyticsEnabled()
+	case "copy_code":
+		return config.Features.CopyCode
 	default:
 		return false
 	}
@@ -153,39 +173,32 @@ func (tm *ThemeManager) getThemeColor(name string) string {
 }
 
 // Content functions
-func (tm *ThemeManager) createExcerpt(content string, maxWords int) string {
-	words := strings.Fields(stripHTML(content))
-	if len(words) <= maxWords {
-		return strings.Join(words, " ")
-	}
-	return strings.Join(words[:maxWords], " ") + "..."
+
+// createExcerpt returns the first maxWords words of HTML content as plain
+// text
+func (tm *ThemeManager) createExcerpt(content interface{}, maxWords int) string {
+	text := strings.Join(strings.Fields(util.StripHTML(textOf(content))), " ")
+	return util.TruncateWords(text, maxWords, tm.config.Ellipsis)
 }
 
 func (tm *ThemeManager) calculateReadingTime(content string) int {
-	words := len(strings.Fields(stripHTML(content)))
-	// Average reading speed: 200 words per minute
-	minutes := (words + 199) / 200
-	if minutes < 1 {
-		return 1
-	}
-	return minutes
+	return util.ReadingTime(util.WordCount(content))
 }
 
 func (tm *ThemeManager) countWords(content string) int {
-	return len(strings.Fields(stripHTML(content)))
+	return util.WordCount(content)
 }
 
 func (tm *ThemeManager) generateTOC(content string) template.HTML {
 	// Extract headings and generate table of contents
-	re := regexp.MustCompile(`<h([1-6])(?:\s+id="([^"]*)")?[^>]*>([^<]+)</h[1-6]>`)
-	matches := re.FindAllStringSubmatch(content, -1)
+	matches := util.HeadingPattern.FindAllStringSubmatch(content, -1)
 	
 	if len(matches) == 0 {
 		return ""
 	}
 	
 	var toc strings.Builder
-	toc.WriteString(`<nav class="table-of-contents"><ul>`)
+	toc.WriteString(`<nav class="table-of-contents" aria-label="Table of contents"><ul>`)
 	
 	for i, match := range matches {
 		level := match[1]
@@ -193,7 +206,7 @@ func (tm *ThemeManager) generateTOC(content string) template.HTML {
 		text := match[3]
 		
 		if id == "" {
-			id = tm.slugify(text)
+			id = util.Slugify(text)
 		}
 		
 		toc.WriteString(fmt.Sprintf(
@@ -212,19 +225,102 @@ func (tm *ThemeManager) generateMetaDescription(page interface{}) string {
 	return ""
 }
 
-func (tm *ThemeManager) generateJSONLD(page interface{}) template.HTML {
-	// Generate JSON-LD structured data
-	return template.HTML(`<script type="application/ld+json">{}</script>`)
+// metaKeywords returns the keywords meta tag for a page's front matter
+// keywords, or nothing when it has none
+func (tm *ThemeManager) metaKeywords(page interface{}) template.HTML {
+	p, ok := page.(*content.Page)
+	if !ok || p == nil || len(p.Keywords) == 0 {
+		return ""
+	}
+	keywords := template.HTMLEscapeString(strings.Join(p.Keywords, ", "))
+	return template.HTML(`<meta name="keywords" content="` + keywords + `">`)
 }
 
-func (tm *ThemeManager) generateOpenGraph(page interface{}) template.HTML {
-	// Generate Open Graph meta tags
-	return ""
+// breadcrumbs returns a page's ancestor chain, from the home page down to
+// the page itself
+func (tm *ThemeManager) breadcrumbs(page interface{}) []content.Breadcrumb {
+	if p, ok := page.(*content.Page); ok && p != nil {
+		return p.Breadcrumbs
+	}
+	return nil
 }
 
-func (tm *ThemeManager) generateTwitterCard(page interface{}) template.HTML {
-	// Generate Twitter Card meta tags
-	return ""
+// generateJSONLD returns schema.org data for a page: an Article when it is
+// dated, a WebPage otherwise, part of its series when it has one. Pages
+// below the home page also get a BreadcrumbList.
+func (tm *ThemeManager) generateJSONLD(page interface{}) template.HTML {
+	p, ok := page.(*content.Page)
+	if !ok || p == nil {
+		return template.HTML(`<script type="application/ld+json">{}</script>`)
+	}
+	data := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "WebPage",
+		"headline": p.Title,
+		"url":      p.Permalink,
+	}
+	if p.DateSource != "" {
+		data["@type"] = "Article"
+		data["datePublished"] = p.ParsedDate.Format(time.RFC3339)
+	}
+	if !p.LastMod.IsZero() {
+		data["dateModified"] = p.LastMod.Format(time.RFC3339)
+	}
+	if p.Description != "" {
+		data["description"] = p.Description
+	}
+	if p.Author != "" {
+		data["author"] = map[string]string{"@type": "Person", "name": p.Author}
+	}
+	if len(p.Keywords) > 0 {
+		data["keywords"] = strings.Join(p.Keywords, ", ")
+	}
+	if p.SeriesName != "" {
+		series := map[string]interface{}{"@type": "CreativeWorkSeries", "name": p.SeriesName}
+		if p.SeriesURL != "" {
+			series["url"] = tm.config.AbsURL(p.SeriesURL)
+		}
+		data["isPartOf"] = series
+		data["position"] = p.SeriesPosition
+	}
+	// json.Marshal escapes <, > and &, so the data can't close the script
+	out, err := json.Marshal(data)
+	if err != nil {
+		return template.HTML(`<script type="application/ld+json">{}</script>`)
+	}
+	scripts := `<script type="application/ld+json">` + string(out) + `</script>`
+	if list := tm.breadcrumbList(p); list != nil {
+		if out, err := json.Marshal(list); err == nil {
+			scripts += "\n" + `<script type="application/ld+json">` + string(out) + `</script>`
+		}
+	}
+	return template.HTML(scripts)
+}
+
+// breadcrumbList returns a page's breadcrumbs as a schema.org
+// BreadcrumbList, leaving out directories without a page to link to, or
+// nil when there is no trail to show
+func (tm *ThemeManager) breadcrumbList(p *content.Page) map[string]interface{} {
+	var items []map[string]interface{}
+	for _, crumb := range p.Breadcrumbs {
+		if crumb.URL == "" || crumb.Page == nil {
+			continue
+		}
+		items = append(items, map[string]interface{}{
+			"@type":    "ListItem",
+			"position": len(items) + 1,
+			"name":     crumb.Name,
+			"item":     crumb.Page.Permalink,
+		})
+	}
+	if len(items) < 2 {
+		return nil
+	}
+	return map[string]interface{}{
+		"@context":        "https://schema.org",
+		"@type":           "BreadcrumbList",
+		"itemListElement": items,
+	}
 }
 
 // Media functions
@@ -238,15 +334,42 @@ func (tm *ThemeManager) responsiveImage(src string, sizes []int) template.HTML {
 	return template.HTML(fmt.Sprintf(`<img src="%s" alt="">`, src))
 }
 
+// assetFingerprint appends a cache-busting hash of the asset's content to
+// its URL, so the URL changes exactly when the file does. Assets that can't
+// be read are fingerprinted by their URL.
 func (tm *ThemeManager) assetFingerprint(path string) string {
-	// Generate fingerprinted asset URL for cache busting
-	hash := md5.Sum([]byte(path + time.Now().String()))
+	data, err := os.ReadFile(tm.assetSource(path))
+	if err != nil {
+		data = []byte(path)
+	}
+	hash := md5.Sum(data)
 	return path + "?v=" + hex.EncodeToString(hash[:4])
 }
 
+// assetSource maps a static or /theme/ asset URL, which may carry the
+// BaseURL path, to the file it is copied from. Paths leading out of the
+// static directory map to no file.
+func (tm *ThemeManager) assetSource(path string) string {
+	path = strings.TrimPrefix(path, tm.config.BaseURLPath())
+	path = strings.TrimPrefix(path, "/")
+	dir, rel := "", ""
+	if r, ok := strings.CutPrefix(path, "theme/"); ok {
+		dir, rel = tm.GetThemeStaticPath(), r
+	} else if r, ok := tm.config.CutStaticPrefix(path); ok {
+		dir, rel = tm.config.StaticDir, r
+	} else {
+		return ""
+	}
+	src := filepath.Join(dir, filepath.FromSlash(rel))
+	if !util.IsWithin(src, dir) {
+		return ""
+	}
+	return src
+}
+
 // Date functions
 func (tm *ThemeManager) isRecent(date time.Time, days int) bool {
-	return time.Since(date).Hours() < float64(days*24)
+	return tm.config.Now().Sub(date).Hours() < float64(days*24)
 }
 
 func (tm *ThemeManager) formatDate(format string, date time.Time) string {
@@ -254,7 +377,7 @@ func (tm *ThemeManager) formatDate(format string, date time.Time) string {
 }
 
 func (tm *ThemeManager) timeFromNow(date time.Time) string {
-	duration := time.Since(date)
+	duration := tm.config.Now().Sub(date)
 	
 	switch {
 	case duration.Minutes() < 1:
@@ -297,22 +420,37 @@ func (tm *ThemeManager) sanitizeHTML(content string) template.HTML {
 	return template.HTML(content)
 }
 
-func (tm *ThemeManager) truncateWords(content string, maxWords int) string {
-	words := strings.Fields(content)
-	if len(words) <= maxWords {
-		return content
-	}
-	return strings.Join(words[:maxWords], " ") + "..."
+// truncateWords shortens plain text to maxWords words
+func (tm *ThemeManager) truncateWords(content interface{}, maxWords int) string {
+	return util.TruncateWords(textOf(content), maxWords, tm.config.Ellipsis)
 }
 
-func (tm *ThemeManager) slugify(text string) string {
-	// Convert text to URL-friendly slug
-	re := regexp.MustCompile(`[^a-zA-Z0-9\s-]`)
-	text = re.ReplaceAllString(text, "")
-	text = strings.ToLower(text)
-	text = regexp.MustCompile(`\s+`).ReplaceAllString(text, "-")
-	text = regexp.MustCompile(`-+`).ReplaceAllString(text, "-")
-	return strings.Trim(text, "-")
+// truncate shortens content to length characters of plain text, dropping
+// any HTML tags
+func (tm *ThemeManager) truncate(content interface{}, length int) string {
+	text := strings.Join(strings.Fields(util.StripHTML(textOf(content))), " ")
+	return util.Truncate(text, length, tm.config.Ellipsis)
+}
+
+// truncateHTML shortens HTML content to length characters of text, closing
+// the tags left open
+func (tm *ThemeManager) truncateHTML(content interface{}, length int) template.HTML {
+	return template.HTML(util.TruncateHTML(textOf(content), length, tm.config.Ellipsis))
+}
+
+// textOf returns the text of a string or template.HTML argument, such as
+// .Page.Content or .Page.Summary
+func textOf(content interface{}) string {
+	switch v := content.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case template.HTML:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
 }
 
 // Math functions
@@ -363,8 +501,13 @@ func (tm *ThemeManager) unique(items []interface{}) []interface{} {
 	var result []interface{}
 	
 	for _, item := range items {
-		if !seen[item] {
-			seen[item] = true
+		// Slices and maps can't be map keys; key them by their printed form
+		key := item
+		if !isComparable(item) {
+			key = fmt.Sprintf("%T %v", item, item)
+		}
+		if !seen[key] {
+			seen[key] = true
 			result = append(result, item)
 		}
 	}
@@ -372,21 +515,6 @@ func (tm *ThemeManager) unique(items []interface{}) []interface{} {
 	return result
 }
 
-func (tm *ThemeManager) paginate(items []interface{}, page, perPage int) []interface{} {
-	start := (page - 1) * perPage
-	end := start + perPage
-	
-	if start >= len(items) {
-		return []interface{}{}
-	}
-	
-	if end > len(items) {
-		end = len(items)
-	}
-	
-	return items[start:end]
-}
-
 // Conditional helpers
 func (tm *ThemeManager) ifNotEmpty(value interface{}) bool {
 	switch v := value.(type) {
@@ -429,7 +557,7 @@ func (tm *ThemeManager) switchCase(value interface{}, cases ...interface{}) inte
 		cases = cases[:len(cases)-1]
 		
 		for i := 0; i < len(cases); i += 2 {
-			if cases[i] == value {
+			if valuesEqual(cases[i], value) {
 				return cases[i+1]
 			}
 		}
@@ -438,7 +566,7 @@ func (tm *ThemeManager) switchCase(value interface{}, cases ...interface{}) inte
 	}
 	
 	for i := 0; i < len(cases); i += 2 {
-		if cases[i] == value {
+		if valuesEqual(cases[i], value) {
 			return cases[i+1]
 		}
 	}
@@ -451,11 +579,6 @@ func (tm *ThemeManager) getRelatedPosts(currentPage interface{}, allPages []inte
 	return []interface{}{}
 }
 
-// Helper function to strip HTML tags
-func stripHTML(content string) string {
-	re := regexp.MustCompile(`<[^>]*>`)
-	return re.ReplaceAllString(content, "")
-}
 // getBasicTemplates returns basic theme templates
 func (tm *ThemeManager) getBasicTemplates() map[string]string {
 	return map[string]string{
@@ -465,26 +588,30 @@ func (tm *ThemeManager) getBasicTemplates() map[string]string {
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>{{ .Page.Title }} | {{ .Site.Title }}</title>
+    {{ faviconTags }}
     <meta name="description" content="{{ default .Site.Description .Page.Description }}">
+    {{ metaKeywords .Page }}
+    {{ with .Page.Robots }}<meta name="robots" content="{{ . }}">{{ end }}
     <link rel="stylesheet" href="{{ themeAsset "css/style.css" }}">
 </head>
 <body>
+    <a class="skip-link" href="#main-content">{{ i18n "skipToContent" }}</a>
     <header class="site-header">
-        <nav class="nav-container">
-            <a href="/" class="site-title">{{ .Site.Title }}</a>
+        <nav class="nav-container" aria-label="{{ i18n "mainNavigation" }}">
+            <a href="{{ relURL "/" }}" class="site-title">{{ .Site.Title }}</a>
             <ul class="nav-links">
-                <li><a href="/">Home</a></li>
-                <li><a href="/about/">About</a></li>
+                <li><a href="{{ relURL "/" }}">Home</a></li>
+                <li><a href="{{ relURL "/about/" }}">About</a></li>
             </ul>
         </nav>
     </header>
-    <main class="main-content">
+    <main id="main-content" class="main-content">
         <article class="post">
             <header class="post-header">
                 <h1 class="post-title">{{ .Page.Title }}</h1>
                 {{ if hasFeature "reading_time" }}
                 <div class="post-meta">
-                    {{ if .Page.ReadingTime }}{{ .Page.ReadingTime }} min read{{ end }}
+                    {{ if .Page.ReadingTime }}{{ readingTimeText .Page }}{{ end }}
                 </div>
                 {{ end }}
             </header>
@@ -506,32 +633,34 @@ func (tm *ThemeManager) getBasicTemplates() map[string]string {
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>{{ .Site.Title }}</title>
+    {{ faviconTags }}
     <meta name="description" content="{{ .Site.Description }}">
     <link rel="stylesheet" href="{{ themeAsset "css/style.css" }}">
 </head>
 <body>
+    <a class="skip-link" href="#main-content">{{ i18n "skipToContent" }}</a>
     <header class="site-header">
-        <nav class="nav-container">
-            <a href="/" class="site-title">{{ .Site.Title }}</a>
+        <nav class="nav-container" aria-label="{{ i18n "mainNavigation" }}">
+            <a href="{{ relURL "/" }}" class="site-title">{{ .Site.Title }}</a>
             <ul class="nav-links">
-                <li><a href="/">Home</a></li>
-                <li><a href="/about/">About</a></li>
+                <li><a href="{{ relURL "/" }}">Home</a></li>
+                <li><a href="{{ relURL "/about/" }}">About</a></li>
             </ul>
         </nav>
     </header>
-    <main class="main-content">
+    <main id="main-content" class="main-content">
         <div class="home-hero">
             <h1 class="hero-title">{{ .Site.Title }}</h1>
             <p class="hero-description">{{ .Site.Description }}</p>
         </div>
         <section class="posts-list">
-            <h2>Recent Posts</h2>
+            <h2>{{ i18n "recentPosts" }}</h2>
             {{ range .Pages }}
             <article class="post-summary">
-                <h3><a href="{{ .URL }}">{{ .Title }}</a></h3>
+                <h3><a href="{{ .RelPermalink }}">{{ .Title }}</a></h3>
                 <div class="post-meta">
                     <time datetime="{{ dateFormat "2006-01-02" .ParsedDate }}">
-                        {{ humanizeDate .ParsedDate }}
+                        {{ humanizeDate .ParsedDate . }}
                     </time>
                 </div>
                 <p class="post-excerpt">{{ .Summary }}</p>
@@ -558,38 +687,43 @@ func (tm *ThemeManager) getBlogTemplates() map[string]string {
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>{{ .Page.Title }} | {{ .Site.Title }}</title>
+    {{ faviconTags }}
     <meta name="description" content="{{ default .Site.Description .Page.Description }}">
+    {{ metaKeywords .Page }}
+    {{ with .Page.Robots }}<meta name="robots" content="{{ . }}">{{ end }}
     <link rel="stylesheet" href="{{ themeAsset "css/style.css" }}">
     {{ if hasFeature "syntax" }}
     <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/github.min.css">
     <script src="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/highlight.min.js"></script>
     <script>hljs.highlightAll();</script>
     {{ end }}
+    {{ jsonLD .Page }}
 </head>
 <body>
+    <a class="skip-link" href="#main-content">{{ i18n "skipToContent" }}</a>
     <header class="site-header">
-        <nav class="nav-container">
-            <a href="/" class="site-title">{{ .Site.Title }}</a>
+        <nav class="nav-container" aria-label="{{ i18n "mainNavigation" }}">
+            <a href="{{ relURL "/" }}" class="site-title">{{ .Site.Title }}</a>
             <ul class="nav-links">
-                <li><a href="/">Home</a></li>
-                <li><a href="/about/">About</a></li>
-                <li><a href="/posts/">Posts</a></li>
+                <li><a href="{{ relURL "/" }}">Home</a></li>
+                <li><a href="{{ relURL "/about/" }}">About</a></li>
+                <li><a href="{{ relURL "/posts/" }}">Posts</a></li>
             </ul>
         </nav>
     </header>
-    <main class="main-content">
+    <main id="main-content" class="main-content">
         <article class="post">
             <header class="post-header">
                 <h1 class="post-title">{{ .Page.Title }}</h1>
                 <div class="post-meta">
                     <time datetime="{{ dateFormat "2006-01-02" .Page.ParsedDate }}">
-                        {{ humanizeDate .Page.ParsedDate }}
+                        {{ humanizeDate .Page.ParsedDate .Page }}
                     </time>
                     {{ if .Page.Author }}
                         by <span class="author">{{ .Page.Author }}</span>
                     {{ end }}
                     {{ if hasFeature "reading_time" }}
-                        â€¢ {{ .Page.ReadingTime }} min read
+                        â€¢ {{ readingTimeText .Page }}
                     {{ end }}
                 </div>
                 {{ if .Page.Tags }}
@@ -600,14 +734,15 @@ func (tm *ThemeManager) getBlogTemplates() map[string]string {
                 </div>
                 {{ end }}
             </header>
+            {{ template "partials/series-nav" . }}
             <div class="post-content">
                 {{ .Page.Content }}
             </div>
             {{ if hasFeature "share" }}
             <div class="post-share">
                 <h4>Share this post</h4>
-                <a href="https://twitter.com/intent/tweet?text={{ .Page.Title }}&url={{ .Site.BaseURL }}{{ .Page.URL }}" target="_blank">Twitter</a>
-                <a href="https://www.facebook.com/sharer/sharer.php?u={{ .Site.BaseURL }}{{ .Page.URL }}" target="_blank">Facebook</a>
+                <a href="https://twitter.com/intent/tweet?text={{ .Page.Title }}&url={{ .Page.Permalink }}" target="_blank">Twitter</a>
+                <a href="https://www.facebook.com/sharer/sharer.php?u={{ .Page.Permalink }}" target="_blank">Facebook</a>
             </div>
             {{ end }}
         </article>
@@ -625,21 +760,23 @@ func (tm *ThemeManager) getBlogTemplates() map[string]string {
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>{{ .Site.Title }}</title>
+    {{ faviconTags }}
     <meta name="description" content="{{ .Site.Description }}">
     <link rel="stylesheet" href="{{ themeAsset "css/style.css" }}">
 </head>
 <body>
+    <a class="skip-link" href="#main-content">{{ i18n "skipToContent" }}</a>
     <header class="site-header">
-        <nav class="nav-container">
-            <a href="/" class="site-title">{{ .Site.Title }}</a>
+        <nav class="nav-container" aria-label="{{ i18n "mainNavigation" }}">
+            <a href="{{ relURL "/" }}" class="site-title">{{ .Site.Title }}</a>
             <ul class="nav-links">
-                <li><a href="/">Home</a></li>
-                <li><a href="/about/">About</a></li>
-                <li><a href="/posts/">Posts</a></li>
+                <li><a href="{{ relURL "/" }}">Home</a></li>
+                <li><a href="{{ relURL "/about/" }}">About</a></li>
+                <li><a href="{{ relURL "/posts/" }}">Posts</a></li>
             </ul>
         </nav>
     </header>
-    <main class="main-content">
+    <main id="main-content" class="main-content">
         <div class="blog-hero">
             <h1 class="hero-title">{{ .Site.Title }}</h1>
             <p class="hero-description">{{ .Site.Description }}</p>
@@ -647,13 +784,13 @@ func (tm *ThemeManager) getBlogTemplates() map[string]string {
         <section class="posts-grid">
             {{ range .Pages }}
             <article class="post-card">
-                <h2><a href="{{ .URL }}">{{ .Title }}</a></h2>
+                <h2><a href="{{ .RelPermalink }}">{{ .Title }}</a></h2>
                 <div class="post-meta">
                     <time datetime="{{ dateFormat "2006-01-02" .ParsedDate }}">
-                        {{ humanizeDate .ParsedDate }}
+                        {{ humanizeDate .ParsedDate . }}
                     </time>
                     {{ if hasFeature "reading_time" }}
-                        â€¢ {{ .ReadingTime }} min read
+                        â€¢ {{ readingTimeText . }}
                     {{ end }}
                 </div>
                 <p class="post-excerpt">{{ .Summary }}</p>
@@ -675,6 +812,24 @@ func (tm *ThemeManager) getBlogTemplates() map[string]string {
     </footer>
 </body>
 </html>`,
+		"layouts/partials/series-nav.html": `{{ with .Page }}{{ if .SeriesName }}
+<nav class="series-nav" aria-label="Series">
+    <p class="series-title">
+        Part {{ .SeriesPosition }} of {{ .SeriesCount }} in
+        {{ if .SeriesURL }}<a href="{{ relURL .SeriesURL }}">{{ .SeriesName }}</a>{{ else }}{{ .SeriesName }}{{ end }}
+    </p>
+    {{ $current := . }}
+    <ol class="series-parts">
+        {{ range .Series }}
+        <li>{{ if eq .FilePath $current.FilePath }}<span aria-current="page">{{ .Title }}</span>{{ else }}<a href="{{ .RelPermalink }}">{{ .Title }}</a>{{ end }}</li>
+        {{ end }}
+    </ol>
+    <div class="series-links">
+        {{ with .PrevInSeries }}<a class="series-prev" href="{{ .RelPermalink }}">&larr; {{ .Title }}</a>{{ end }}
+        {{ with .NextInSeries }}<a class="series-next" href="{{ .RelPermalink }}">{{ .Title }} &rarr;</a>{{ end }}
+    </div>
+</nav>
+{{ end }}{{ end }}`,
 	}
 }
 
@@ -687,20 +842,24 @@ func (tm *ThemeManager) getPortfolioTemplates() map[string]string {
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>{{ .Page.Title }} | {{ .Site.Title }}</title>
+    {{ faviconTags }}
     <meta name="description" content="{{ default .Site.Description .Page.Description }}">
+    {{ metaKeywords .Page }}
+    {{ with .Page.Robots }}<meta name="robots" content="{{ . }}">{{ end }}
     <link rel="stylesheet" href="{{ themeAsset "css/style.css" }}">
 </head>
 <body>
-    <nav class="portfolio-nav">
-        <a href="/" class="nav-logo">{{ .Site.Title }}</a>
+    <a class="skip-link" href="#main-content">{{ i18n "skipToContent" }}</a>
+    <nav class="portfolio-nav" aria-label="{{ i18n "mainNavigation" }}">
+        <a href="{{ relURL "/" }}" class="nav-logo">{{ .Site.Title }}</a>
         <ul class="nav-menu">
-            <li><a href="/">Home</a></li>
-            <li><a href="/projects/">Projects</a></li>
-            <li><a href="/about/">About</a></li>
-            <li><a href="/contact/">Contact</a></li>
+            <li><a href="{{ relURL "/" }}">Home</a></li>
+            <li><a href="{{ relURL "/projects/" }}">Projects</a></li>
+            <li><a href="{{ relURL "/about/" }}">About</a></li>
+            <li><a href="{{ relURL "/contact/" }}">Contact</a></li>
         </ul>
     </nav>
-    <main class="portfolio-main">
+    <main id="main-content" class="portfolio-main">
         <article class="project-detail">
             <header class="project-header">
                 <h1 class="project-title">{{ .Page.Title }}</h1>
@@ -715,6 +874,7 @@ func (tm *ThemeManager) getPortfolioTemplates() map[string]string {
             <div class="project-content">
                 {{ .Page.Content }}
             </div>
+            {{ template "partials/gallery" . }}
             {{ if .Page.Params.demo_url }}
             <div class="project-links">
                 <a href="{{ .Page.Params.demo_url }}" target="_blank" class="btn btn-primary">View Demo</a>
@@ -727,26 +887,38 @@ func (tm *ThemeManager) getPortfolioTemplates() map[string]string {
     </main>
 </body>
 </html>`,
+		"layouts/partials/gallery.html": `{{ with .Page.Resources.ByType "image" }}
+<section class="project-gallery">
+    {{ range . }}
+    <figure class="gallery-item">
+        <img src="{{ .RelPermalink }}" alt="{{ .Title }}" loading="lazy">
+        {{ with .Params.caption }}<figcaption>{{ . }}</figcaption>{{ end }}
+    </figure>
+    {{ end }}
+</section>
+{{ end }}`,
 		"layouts/_default/list.html": `<!DOCTYPE html>
 <html lang="{{ .Site.Language }}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>{{ .Site.Title }}</title>
+    {{ faviconTags }}
     <meta name="description" content="{{ .Site.Description }}">
     <link rel="stylesheet" href="{{ themeAsset "css/style.css" }}">
 </head>
 <body>
-    <nav class="portfolio-nav">
-        <a href="/" class="nav-logo">{{ .Site.Title }}</a>
+    <a class="skip-link" href="#main-content">{{ i18n "skipToContent" }}</a>
+    <nav class="portfolio-nav" aria-label="{{ i18n "mainNavigation" }}">
+        <a href="{{ relURL "/" }}" class="nav-logo">{{ .Site.Title }}</a>
         <ul class="nav-menu">
-            <li><a href="/">Home</a></li>
-            <li><a href="/projects/">Projects</a></li>
-            <li><a href="/about/">About</a></li>
-            <li><a href="/contact/">Contact</a></li>
+            <li><a href="{{ relURL "/" }}">Home</a></li>
+            <li><a href="{{ relURL "/projects/" }}">Projects</a></li>
+            <li><a href="{{ relURL "/about/" }}">About</a></li>
+            <li><a href="{{ relURL "/contact/" }}">Contact</a></li>
         </ul>
     </nav>
-    <main class="portfolio-main">
+    <main id="main-content" class="portfolio-main">
         <section class="hero-section">
             <div class="hero-content">
                 <h1 class="hero-title">{{ .Site.Title }}</h1>
@@ -760,13 +932,13 @@ func (tm *ThemeManager) getPortfolioTemplates() map[string]string {
                 <article class="project-card">
                     <div class="project-image">
                         {{ if .Params.image }}
-                        <img src="{{ .Params.image }}" alt="{{ .Title }}">
+                        <img src="{{ relURL .Params.image }}" alt="{{ .Title }}">
                         {{ else }}
                         <div class="project-placeholder">{{ substr .Title 0 1 }}</div>
                         {{ end }}
                     </div>
                     <div class="project-info">
-                        <h3><a href="{{ .URL }}">{{ .Title }}</a></h3>
+                        <h3><a href="{{ .RelPermalink }}">{{ .Title }}</a></h3>
                         <p class="project-description">{{ .Summary }}</p>
                         {{ if .Params.technologies }}
                         <div class="project-tech">
@@ -795,7 +967,10 @@ func (tm *ThemeManager) getDocsTemplates() map[string]string {
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>{{ .Page.Title }} | {{ .Site.Title }}</title>
+    {{ faviconTags }}
     <meta name="description" content="{{ default .Site.Description .Page.Description }}">
+    {{ metaKeywords .Page }}
+    {{ with .Page.Robots }}<meta name="robots" content="{{ . }}">{{ end }}
     <link rel="stylesheet" href="{{ themeAsset "css/style.css" }}">
     {{ if hasFeature "syntax" }}
     <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/github.min.css">
@@ -804,40 +979,49 @@ func (tm *ThemeManager) getDocsTemplates() map[string]string {
     {{ end }}
 </head>
 <body class="docs-layout">
-    <nav class="docs-nav">
+    <a class="skip-link" href="#main-content">{{ i18n "skipToContent" }}</a>
+    <nav class="docs-nav" aria-label="{{ i18n "mainNavigation" }}">
         <div class="nav-brand">
-            <a href="/">{{ .Site.Title }}</a>
+            <a href="{{ relURL "/" }}">{{ .Site.Title }}</a>
         </div>
         <div class="nav-search">
-            <input type="search" placeholder="Search docs...">
+            <input type="search" placeholder="Search docs..." aria-label="Search docs">
         </div>
     </nav>
     <div class="docs-container">
         <aside class="docs-sidebar">
-            <nav class="sidebar-nav">
+            <nav class="sidebar-nav" aria-label="{{ i18n "sectionNavigation" }}">
                 <h3>Navigation</h3>
                 <ul>
-                    <li><a href="/">Home</a></li>
-                    <li><a href="/getting-started/">Getting Started</a></li>
-                    <li><a href="/api/">API Reference</a></li>
-                    <li><a href="/examples/">Examples</a></li>
+                    <li><a href="{{ relURL "/" }}">Home</a></li>
                 </ul>
+                {{ with index .Site.Sections .Page.Section }}
+                <h3>{{ if .Tree.URL }}<a href="{{ .Tree.URL }}">{{ .Tree.Title }}</a>{{ else }}{{ .Tree.Title }}{{ end }}</h3>
+                {{ template "partials/docs-tree" (dict "node" .Tree "page" $.Page) }}
+                {{ end }}
             </nav>
         </aside>
-        <main class="docs-main">
+        <main id="main-content" class="docs-main">
             <article class="docs-article">
                 <header class="docs-header">
+                    {{ template "partials/breadcrumbs" .Page }}
                     <h1>{{ .Page.Title }}</h1>
                     {{ if .Page.Description }}
                     <p class="docs-description">{{ .Page.Description }}</p>
                     {{ end }}
                 </header>
                 {{ if hasFeature "toc" }}
-                <nav class="docs-toc">
+                {{ with headingsBetween .Site.Markup.TableOfContents.StartLevel .Site.Markup.TableOfContents.EndLevel .Page.Headings }}
+                <nav class="docs-toc" aria-label="{{ i18n "tableOfContents" }}">
                     <h4>Table of Contents</h4>
-                    <!-- TOC would be generated here -->
+                    <ul>
+                        {{ range . }}
+                        <li class="toc-level-{{ .Level }}" data-order="{{ .Order }}"><a href="{{ .Anchor }}">{{ .Text }}</a></li>
+                        {{ end }}
+                    </ul>
                 </nav>
                 {{ end }}
+                {{ end }}
                 <div class="docs-content">
                     {{ .Page.Content }}
                 </div>
@@ -857,30 +1041,37 @@ func (tm *ThemeManager) getDocsTemplates() map[string]string {
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>{{ .Site.Title }}</title>
+    {{ faviconTags }}
     <meta name="description" content="{{ .Site.Description }}">
     <link rel="stylesheet" href="{{ themeAsset "css/style.css" }}">
 </head>
 <body class="docs-layout">
-    <nav class="docs-nav">
+    <a class="skip-link" href="#main-content">{{ i18n "skipToContent" }}</a>
+    <nav class="docs-nav" aria-label="{{ i18n "mainNavigation" }}">
         <div class="nav-brand">
-            <a href="/">{{ .Site.Title }}</a>
+            <a href="{{ relURL "/" }}">{{ .Site.Title }}</a>
         </div>
         <div class="nav-search">
-            <input type="search" placeholder="Search docs...">
+            <input type="search" placeholder="Search docs..." aria-label="Search docs">
         </div>
     </nav>
     <div class="docs-container">
         <aside class="docs-sidebar">
-            <nav class="sidebar-nav">
+            <nav class="sidebar-nav" aria-label="{{ i18n "sectionNavigation" }}">
+                {{ range .Site.Sections }}
+                <h3>{{ if .Tree.URL }}<a href="{{ .Tree.URL }}">{{ .Tree.Title }}</a>{{ else }}{{ .Tree.Title }}{{ end }}</h3>
+                {{ template "partials/docs-tree" (dict "node" .Tree "page" $.Page) }}
+                {{ else }}
                 <h3>Documentation</h3>
                 <ul>
                     {{ range .Pages }}
-                    <li><a href="{{ .URL }}">{{ .Title }}</a></li>
+                    <li><a href="{{ .RelPermalink }}">{{ .Title }}</a></li>
                     {{ end }}
                 </ul>
+                {{ end }}
             </nav>
         </aside>
-        <main class="docs-main">
+        <main id="main-content" class="docs-main">
             <div class="docs-home">
                 <header class="docs-hero">
                     <h1>{{ .Site.Title }}</h1>
@@ -889,7 +1080,7 @@ func (tm *ThemeManager) getDocsTemplates() map[string]string {
                 <section class="docs-sections">
                     {{ range .Pages }}
                     <article class="docs-card">
-                        <h2><a href="{{ .URL }}">{{ .Title }}</a></h2>
+                        <h2><a href="{{ .RelPermalink }}">{{ .Title }}</a></h2>
                         <p>{{ .Summary }}</p>
                     </article>
                     {{ end }}
@@ -899,6 +1090,21 @@ func (tm *ThemeManager) getDocsTemplates() map[string]string {
     </div>
 </body>
 </html>`,
+		"layouts/partials/docs-tree.html": `{{ $page := .page }}
+<ul class="sidebar-tree">
+    {{ range .node.Children }}
+    <li>
+        {{ if .HasChildren }}
+        <details{{ if .IsActive $page }} open{{ end }}>
+            <summary>{{ if .URL }}<a href="{{ .URL }}"{{ if .IsCurrent $page }} class="active" aria-current="page"{{ end }}>{{ .Title }}</a>{{ else }}{{ .Title }}{{ end }}</summary>
+            {{ template "partials/docs-tree" (dict "node" . "page" $page) }}
+        </details>
+        {{ else }}
+        <a href="{{ .URL }}"{{ if .IsCurrent $page }} class="active" aria-current="page"{{ end }}>{{ .Title }}</a>
+        {{ end }}
+    </li>
+    {{ end }}
+</ul>`,
 	}
 
 }
\ No newline at end of file
@@ -6,10 +6,16 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"vango/internal/config"
+	"vango/internal/logging"
 )
 
+// ScreenshotFile is the screenshot a theme has when its theme.json names none
+const ScreenshotFile = "screenshot.png"
+
 // Theme represents a VanGo theme
 type Theme struct {
 	Name        string                 `json:"name"`
@@ -18,6 +24,7 @@ type Theme struct {
 	Author      string                 `json:"author"`
 	Homepage    string                 `json:"homepage"`
 	License     string                 `json:"license"`
+	Screenshot  string                 `json:"screenshot"` // Preview image, relative to the theme directory
 	MinVersion  string                 `json:"min_vango_version"`
 	Tags        []string               `json:"tags"`
 	Features    []string               `json:"features"`
@@ -39,6 +46,12 @@ type ThemeManager struct {
 	themes      map[string]*Theme
 	themesDir   string
 	defaultTheme string
+	
+	// The active theme's config.json, read once and shared by template
+	// functions running in parallel page workers
+	themeConfig    *ThemeConfig
+	themeConfigErr error
+	themeConfigMu  sync.RWMutex
 }
 
 // ThemeConfig represents theme-specific configuration
@@ -97,6 +110,7 @@ type FeatureConfig struct {
 	ReadingTime     bool `json:"reading_time"`
 	RelatedPosts    bool `json:"related_posts"`
 	Analytics       bool `json:"analytics"`
+	CopyCode        bool `json:"copy_code"`
 }
 
 // NewThemeManager creates a new theme manager
@@ -138,7 +152,7 @@ func (tm *ThemeManager) LoadThemes() error {
 		// Load the theme
 		theme, err := tm.loadTheme(path)
 		if err != nil {
-			fmt.Printf("Warning: failed to load theme from %s: %v\n", path, err)
+			logging.Warnf("Warning: failed to load theme from %s: %v", path, err)
 			return nil // Continue loading other themes
 		}
 		tm.themes[theme.Name] = theme
@@ -148,14 +162,9 @@ func (tm *ThemeManager) LoadThemes() error {
 
 // loadTheme loads a single theme from a directory
 func (tm *ThemeManager) loadTheme(themePath string) (*Theme, error) {
-	themeFile := filepath.Join(themePath, "theme.json")
-	data, err := os.ReadFile(themeFile)
+	theme, err := readThemeFile(themePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read theme.json: %w", err)
-	}
-	var theme Theme
-	if err := json.Unmarshal(data, &theme); err != nil {
-		return nil, fmt.Errorf("failed to parse theme.json: %w", err)
+		return nil, err
 	}
 	theme.Path = themePath
 	// Set default paths if not specified
@@ -169,10 +178,10 @@ func (tm *ThemeManager) loadTheme(themePath string) (*Theme, error) {
 		theme.AssetsDir = "assets"
 	}
 	// Validate theme structure
-	if err := tm.validateTheme(&theme); err != nil {
+	if err := tm.validateTheme(theme); err != nil {
 		return nil, fmt.Errorf("invalid theme structure: %w", err)
 	}
-	return &theme, nil
+	return theme, nil
 }
 
 // InstallTheme installs a theme from a remote source or local path
@@ -238,6 +247,7 @@ func (tm *ThemeManager) SetActiveTheme(themeName string) error {
 	}
 	tm.activeTheme = theme
 	tm.config.Theme = themeName
+	tm.InvalidateThemeConfig()
 	return nil
 }
 
@@ -262,6 +272,50 @@ func (tm *ThemeManager) ListThemes() map[string]*Theme {
 	return tm.themes
 }
 
+// ThemeSorts are the orders SortedThemes lists themes in
+var ThemeSorts = []string{"name", "author"}
+
+// SortedThemes returns the loaded themes having every one of tags, ignoring
+// case, sorted by name or by author and then name
+func (tm *ThemeManager) SortedThemes(sortBy string, tags []string) ([]*Theme, error) {
+	if sortBy != "name" && sortBy != "author" {
+		return nil, fmt.Errorf("unknown sort %q (use name or author)", sortBy)
+	}
+	var themes []*Theme
+	for _, theme := range tm.themes {
+		if theme.HasTags(tags) {
+			themes = append(themes, theme)
+		}
+	}
+	sort.Slice(themes, func(i, j int) bool {
+		if sortBy == "author" {
+			a, b := strings.ToLower(themes[i].Author), strings.ToLower(themes[j].Author)
+			if a != b {
+				return a < b
+			}
+		}
+		return themes[i].Name < themes[j].Name
+	})
+	return themes, nil
+}
+
+// HasTags reports whether the theme has every one of tags, ignoring case
+func (t *Theme) HasTags(tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, tag := range t.Tags {
+			if strings.EqualFold(tag, strings.TrimSpace(want)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 // GetThemeTemplatesPath returns the templates path for the active theme
 func (tm *ThemeManager) GetThemeTemplatesPath() string {
 	if tm.activeTheme == nil {
@@ -286,8 +340,54 @@ func (tm *ThemeManager) GetThemeAssetsPath() string {
 	return filepath.Join(tm.activeTheme.Path, tm.activeTheme.AssetsDir)
 }
 
-// GetThemeConfig returns the theme configuration
+// GetThemeI18nPath returns the translations path for the active theme
+func (tm *ThemeManager) GetThemeI18nPath() string {
+	if tm.activeTheme == nil {
+		return ""
+	}
+	return filepath.Join(tm.activeTheme.Path, "i18n")
+}
+
+// GetThemeConfig returns the theme configuration, reading the active
+// theme's config.json on first use. The result is shared between callers
+// and must not be modified.
 func (tm *ThemeManager) GetThemeConfig() (*ThemeConfig, error) {
+	tm.themeConfigMu.RLock()
+	config, err := tm.themeConfig, tm.themeConfigErr
+	tm.themeConfigMu.RUnlock()
+	if config != nil || err != nil {
+		return config, err
+	}
+
+	tm.themeConfigMu.Lock()
+	defer tm.themeConfigMu.Unlock()
+	if tm.themeConfig == nil && tm.themeConfigErr == nil {
+		tm.themeConfig, tm.themeConfigErr = tm.readThemeConfig()
+	}
+	return tm.themeConfig, tm.themeConfigErr
+}
+
+// InvalidateThemeConfig drops the cached theme configuration, so the next
+// GetThemeConfig re-reads config.json after it changed
+func (tm *ThemeManager) InvalidateThemeConfig() {
+	tm.themeConfigMu.Lock()
+	defer tm.themeConfigMu.Unlock()
+	tm.themeConfig, tm.themeConfigErr = nil, nil
+}
+
+// IsThemeConfig reports whether path is the active theme's config.json
+func (tm *ThemeManager) IsThemeConfig(path string) bool {
+	if tm.activeTheme == nil {
+		return false
+	}
+	configPath, err1 := filepath.Abs(filepath.Join(tm.activeTheme.Path, "config.json"))
+	changed, err2 := filepath.Abs(path)
+	return err1 == nil && err2 == nil && configPath == changed
+}
+
+// readThemeConfig reads the active theme's config.json, or returns the
+// default configuration when there is none
+func (tm *ThemeManager) readThemeConfig() (*ThemeConfig, error) {
 	if tm.activeTheme == nil {
 		return tm.getDefaultThemeConfig(), nil
 	}
@@ -310,13 +410,13 @@ func (tm *ThemeManager) GetThemeConfig() (*ThemeConfig, error) {
 func (tm *ThemeManager) getDefaultThemeConfig() *ThemeConfig {
 	return &ThemeConfig{
 		Colors: ColorScheme{
-			Primary:    "#007bff",
+			Primary:    "#0066cc",
 			Secondary:  "#6c757d",
 			Accent:     "#28a745",
 			Background: "#ffffff",
 			Surface:    "#f8f9fa",
 			Text:       "#333333",
-			TextMuted:  "#6c757d",
+			TextMuted:  "#5c636a",
 			Border:     "#e9ecef",
 			Success:    "#28a745",
 			Warning:    "#ffc107",
@@ -352,19 +452,6 @@ func (tm *ThemeManager) getDefaultThemeConfig() *ThemeConfig {
 	}
 }
 
-// CopyThemeAssets copies theme assets to the public directory
-func (tm *ThemeManager) CopyThemeAssets(publicDir string) error {
-	if tm.activeTheme == nil {
-		return nil
-	}
-	staticPath := tm.GetThemeStaticPath()
-	if _, err := os.Stat(staticPath); os.IsNotExist(err) {
-		return nil // No static assets to copy
-	}
-	destPath := filepath.Join(publicDir, "theme")
-	return tm.copyDir(staticPath, destPath)
-}
-
 // copyDir recursively copies a directory
 func (tm *ThemeManager) copyDir(src, dst string) error {
 	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
@@ -509,8 +596,14 @@ func (tm *ThemeManager) createThemeTemplates(themePath, template string) error {
 		templates = tm.getBasicTemplates()
 		css = tm.getBasicCSS()
 	}
-	// Write templates
-	for path, content := range templates {
+	// Write templates in a stable order
+	paths := make([]string, 0, len(templates))
+	for path := range templates {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		content := templates[path]
 		fullPath := filepath.Join(themePath, path)
 		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
 			return err
@@ -0,0 +1,425 @@
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+
+	"vango/internal/util"
+)
+
+// ValidationIssue is a single problem found while validating a theme
+type ValidationIssue struct {
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationReport is the result of ValidateTheme. Errors make the theme
+// unusable; warnings point at likely mistakes.
+type ValidationReport struct {
+	Theme     string            `json:"theme"`
+	Path      string            `json:"path"`
+	Functions []string          `json:"functions"`
+	Features  []string          `json:"features"`
+	Templates []string          `json:"templates"`
+	Assets    []string          `json:"assets"`
+	Errors    []ValidationIssue `json:"errors"`
+	Warnings  []ValidationIssue `json:"warnings"`
+}
+
+// Valid reports whether the theme has no errors
+func (r *ValidationReport) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+func (r *ValidationReport) addError(file, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, ValidationIssue{File: file, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *ValidationReport) addWarning(file, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, ValidationIssue{File: file, Message: fmt.Sprintf(format, args...)})
+}
+
+// builtinFuncs are the functions text/template and html/template always provide
+var builtinFuncs = []string{
+	"and", "call", "eq", "ge", "gt", "html", "index", "js", "le", "len", "lt",
+	"ne", "not", "or", "print", "printf", "println", "slice", "urlquery",
+}
+
+// featureAliases maps hasFeature names to the other names a theme.json may
+// declare them under
+var featureAliases = map[string][]string{
+	"syntax": {"syntax_highlighting"},
+	"toc":    {"table_of_contents"},
+}
+
+// themeAssetURL matches literal references to theme static files in template text
+var themeAssetURL = regexp.MustCompile(`/theme/([A-Za-z0-9_./-]+)`)
+
+// templateRefs collects what a theme's templates reference
+type templateRefs struct {
+	functions map[string][]string // Function -> templates calling it
+	features  map[string][]string
+	assets    map[string][]string
+	templates map[string][]string
+}
+
+// ValidateTheme checks a theme beyond its file layout: templates must parse,
+// referenced assets and templates must exist, features used in templates
+// should be declared in theme.json, and min_vango_version must be satisfied
+// by vangoVersion. When knownFuncs is non-empty, calls to any other function
+// are errors.
+func (tm *ThemeManager) ValidateTheme(name, vangoVersion string, knownFuncs []string) (*ValidationReport, error) {
+	themePath := filepath.Join(tm.themesDir, name)
+	data, err := os.ReadFile(filepath.Join(themePath, "theme.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme.json: %w", err)
+	}
+
+	report := &ValidationReport{Theme: name, Path: themePath}
+	var theme Theme
+	if err := json.Unmarshal(data, &theme); err != nil {
+		report.addError("theme.json", "failed to parse: %v", err)
+		return report, nil
+	}
+	if theme.Name == "" {
+		report.addError("theme.json", "theme name cannot be empty")
+	}
+	if theme.LayoutsDir == "" {
+		theme.LayoutsDir = "layouts"
+	}
+	if theme.StaticDir == "" {
+		theme.StaticDir = "static"
+	}
+
+	for _, required := range []string{"_default/single.html", "_default/list.html"} {
+		if _, err := os.Stat(filepath.Join(themePath, theme.LayoutsDir, required)); os.IsNotExist(err) {
+			report.addError(util.SlashPath(filepath.Join(theme.LayoutsDir, required)), "required template missing")
+		}
+	}
+
+	checkMinVersion(report, theme.MinVersion, vangoVersion)
+
+	unknown, err := tm.UnknownConfigKeys(name)
+	if err != nil {
+		report.addError("config.json", "failed to parse: %v", err)
+	}
+	for _, key := range unknown {
+		file, message, _ := strings.Cut(key, ": ")
+		report.addWarning(file, "%s", message)
+	}
+
+	refs, defined := parseThemeTemplates(report, filepath.Join(themePath, theme.LayoutsDir))
+	report.Functions = sortedKeys(refs.functions)
+	report.Features = sortedKeys(refs.features)
+	report.Templates = sortedKeys(refs.templates)
+	report.Assets = sortedKeys(refs.assets)
+
+	if len(knownFuncs) > 0 {
+		known := make(map[string]bool)
+		for _, fn := range append(knownFuncs, builtinFuncs...) {
+			known[fn] = true
+		}
+		for _, fn := range report.Functions {
+			if !known[fn] {
+				report.addError(strings.Join(refs.functions[fn], ", "), "unknown template function %q", fn)
+			}
+		}
+	}
+
+	declared := make(map[string]bool)
+	for _, feature := range theme.Features {
+		declared[normalizeFeature(feature)] = true
+	}
+	for _, feature := range report.Features {
+		if !featureDeclared(feature, declared) {
+			report.addWarning(strings.Join(refs.features[feature], ", "), "feature %q is used but not declared in theme.json features", feature)
+		}
+	}
+
+	for _, name := range report.Templates {
+		if !defined[name] {
+			report.addWarning(strings.Join(refs.templates[name], ", "), "template %q is not defined by the theme and must come from the site", name)
+		}
+	}
+
+	staticDir := filepath.Join(themePath, theme.StaticDir)
+	for _, asset := range report.Assets {
+		if _, err := os.Stat(filepath.Join(staticDir, filepath.FromSlash(asset))); err != nil {
+			report.addError(strings.Join(refs.assets[asset], ", "), "referenced asset %q does not exist in %s", asset, theme.StaticDir)
+		}
+	}
+	filepath.Walk(staticDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(staticDir, path)
+		if err != nil {
+			return nil
+		}
+		if _, ok := refs.assets[util.SlashPath(rel)]; !ok {
+			report.addWarning(util.SlashPath(filepath.Join(theme.StaticDir, rel)), "asset is not referenced by any template")
+		}
+		return nil
+	})
+
+	return report, nil
+}
+
+// UnknownConfigKeys reports the keys of a theme's theme.json and config.json
+// that match no setting, each prefixed with its file name
+func (tm *ThemeManager) UnknownConfigKeys(name string) ([]string, error) {
+	files := []struct {
+		name   string
+		target interface{}
+	}{
+		{"theme.json", Theme{}},
+		{"config.json", ThemeConfig{}},
+	}
+
+	var unknown []string
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(tm.themesDir, name, file.name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return unknown, err
+		}
+		var raw map[string]interface{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return unknown, fmt.Errorf("%s: %w", file.name, err)
+		}
+		for _, key := range util.UnknownKeys(raw, file.target, "json") {
+			unknown = append(unknown, fmt.Sprintf("%s: %s", file.name, key))
+		}
+	}
+	return unknown, nil
+}
+
+// parseThemeTemplates parses every template under layoutsDir, recording
+// parse errors, what the templates reference and which names they define
+func parseThemeTemplates(report *ValidationReport, layoutsDir string) (*templateRefs, map[string]bool) {
+	refs := &templateRefs{
+		functions: make(map[string][]string),
+		features:  make(map[string][]string),
+		assets:    make(map[string][]string),
+		templates: make(map[string][]string),
+	}
+	defined := make(map[string]bool)
+
+	filepath.Walk(layoutsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".html") {
+			return nil
+		}
+		rel, _ := filepath.Rel(layoutsDir, path)
+		rel = util.SlashPath(rel)
+		file := util.SlashPath(filepath.Join(filepath.Base(layoutsDir), rel))
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			report.addError(file, "failed to read: %v", err)
+			return nil
+		}
+
+		name := strings.TrimSuffix(rel, ".html")
+		tree := parse.New(name)
+		tree.Mode = parse.SkipFuncCheck
+		trees := make(map[string]*parse.Tree)
+		if _, err := tree.Parse(string(data), "", "", trees); err != nil {
+			report.addError(file, "failed to parse: %v", err)
+			return nil
+		}
+
+		defined[name] = true
+		for treeName, t := range trees {
+			defined[treeName] = true
+			walkTemplate(t.Root, file, refs)
+		}
+		return nil
+	})
+
+	return refs, defined
+}
+
+// walkTemplate records the functions, features, assets and templates
+// referenced under node
+func walkTemplate(node parse.Node, file string, refs *templateRefs) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkTemplate(child, file, refs)
+		}
+	case *parse.TextNode:
+		for _, match := range themeAssetURL.FindAllStringSubmatch(string(n.Text), -1) {
+			addRef(refs.assets, match[1], file)
+		}
+	case *parse.ActionNode:
+		walkTemplate(n.Pipe, file, refs)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkTemplate(cmd, file, refs)
+		}
+	case *parse.CommandNode:
+		if len(n.Args) > 0 {
+			if ident, ok := n.Args[0].(*parse.IdentifierNode); ok {
+				addRef(refs.functions, ident.Ident, file)
+				if len(n.Args) > 1 {
+					if arg, ok := n.Args[1].(*parse.StringNode); ok {
+						switch ident.Ident {
+						case "hasFeature":
+							addRef(refs.features, arg.Text, file)
+						case "themeAsset":
+							addRef(refs.assets, strings.TrimPrefix(arg.Text, "/"), file)
+						}
+					}
+				}
+			}
+		}
+		for _, arg := range n.Args {
+			walkTemplate(arg, file, refs)
+		}
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, file, refs)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, file, refs)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, file, refs)
+	case *parse.TemplateNode:
+		addRef(refs.templates, n.Name, file)
+		walkTemplate(n.Pipe, file, refs)
+	}
+}
+
+func walkBranch(n *parse.BranchNode, file string, refs *templateRefs) {
+	walkTemplate(n.Pipe, file, refs)
+	walkTemplate(n.List, file, refs)
+	walkTemplate(n.ElseList, file, refs)
+}
+
+// addRef records that file references key, once per file
+func addRef(refs map[string][]string, key, file string) {
+	for _, existing := range refs[key] {
+		if existing == file {
+			return
+		}
+	}
+	refs[key] = append(refs[key], file)
+}
+
+// normalizeFeature makes "dark-mode" and "dark_mode" compare equal
+func normalizeFeature(feature string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(feature)), "-", "_")
+}
+
+// featureDeclared reports whether a hasFeature name, or one of its aliases, is declared
+func featureDeclared(feature string, declared map[string]bool) bool {
+	feature = normalizeFeature(feature)
+	if declared[feature] {
+		return true
+	}
+	for _, alias := range featureAliases[feature] {
+		if declared[alias] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMinVersion reports an error when the running version is older than
+// the theme's min_vango_version
+func checkMinVersion(report *ValidationReport, minVersion, vangoVersion string) {
+	if minVersion == "" {
+		return
+	}
+	required, err := parseSemver(minVersion)
+	if err != nil {
+		report.addWarning("theme.json", "min_vango_version %q is not a valid version: %v", minVersion, err)
+		return
+	}
+	running, err := parseSemver(vangoVersion)
+	if err != nil {
+		report.addWarning("theme.json", "cannot compare against running version %q: %v", vangoVersion, err)
+		return
+	}
+	if compareSemver(running, required) < 0 {
+		report.addError("theme.json", "theme requires VanGo %s or newer, running %s", minVersion, vangoVersion)
+	}
+}
+
+// semver is a parsed major.minor.patch version with an optional pre-release
+type semver struct {
+	parts      [3]int
+	prerelease string
+}
+
+// parseSemver parses versions such as "1.2", "v1.2.3" and "2.0.0-beta.1"
+func parseSemver(version string) (semver, error) {
+	var v semver
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexByte(version, '+'); i != -1 {
+		version = version[:i]
+	}
+	if i := strings.IndexByte(version, '-'); i != -1 {
+		version, v.prerelease = version[:i], version[i+1:]
+	}
+
+	fields := strings.Split(version, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return v, fmt.Errorf("expected major.minor.patch")
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid number %q", field)
+		}
+		v.parts[i] = n
+	}
+	return v, nil
+}
+
+// compareSemver returns -1, 0 or 1 as a is older than, equal to or newer
+// than b. A pre-release sorts before its release.
+func compareSemver(a, b semver) int {
+	for i := range a.parts {
+		if a.parts[i] != b.parts[i] {
+			if a.parts[i] < b.parts[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case a.prerelease == b.prerelease:
+		return 0
+	case a.prerelease == "":
+		return 1
+	case b.prerelease == "":
+		return -1
+	case a.prerelease < b.prerelease:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,206 @@
+package util
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UnknownKey is a key in decoded configuration data that matches no field
+// of the struct it is decoded into, and is therefore silently ignored
+type UnknownKey struct {
+	Path       string // Full dotted path, e.g. "markup.goldmark.rendrer"
+	Suggestion string // Closest known key at the same level, if any
+}
+
+func (k UnknownKey) String() string {
+	if k.Suggestion == "" {
+		return fmt.Sprintf("unknown key %q", k.Path)
+	}
+	return fmt.Sprintf("unknown key %q (did you mean %q?)", k.Path, k.Suggestion)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// UnknownKeys reports the keys of data, a decoded TOML, YAML or JSON
+// document normalized with NormalizeMap, that match no field of target's
+// struct type by the given struct tag ("toml", "yaml" or "json"). Keys match
+// the way each decoder matches them: YAML exactly, TOML also by lower-cased
+// field name and JSON ignoring case. Free-form maps such as params are not
+// descended into.
+func UnknownKeys(data map[string]interface{}, target interface{}, tag string) []UnknownKey {
+	var unknown []UnknownKey
+	checkKeys(data, reflect.TypeOf(target), tag, "", &unknown)
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Path < unknown[j].Path })
+	return unknown
+}
+
+// checkKeys checks value, decoded into a value of type t, below prefix
+func checkKeys(value interface{}, t reflect.Type, tag, prefix string, unknown *[]UnknownKey) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok || t == timeType {
+			return
+		}
+		fields := structFields(t, tag)
+		for key, item := range m {
+			path := joinKey(prefix, key)
+			field, ok := lookupField(fields, key, tag)
+			if !ok {
+				*unknown = append(*unknown, UnknownKey{Path: path, Suggestion: Suggest(key, fieldNames(fields))})
+				continue
+			}
+			checkKeys(item, field.Type, tag, path, unknown)
+		}
+	case reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for key, item := range m {
+			checkKeys(item, t.Elem(), tag, joinKey(prefix, key), unknown)
+		}
+	case reflect.Slice, reflect.Array:
+		var items []interface{}
+		switch v := value.(type) {
+		case []interface{}:
+			items = v
+		case []map[string]interface{}:
+			for _, item := range v {
+				items = append(items, item)
+			}
+		}
+		for i, item := range items {
+			checkKeys(item, t.Elem(), tag, fmt.Sprintf("%s[%d]", prefix, i+1), unknown)
+		}
+	}
+}
+
+// namedField is a struct field with the key it is decoded from
+type namedField struct {
+	Name string
+	Type reflect.Type
+}
+
+// lookupField finds the field key decodes into
+func lookupField(fields map[string]namedField, key, tag string) (namedField, bool) {
+	if field, ok := fields[key]; ok {
+		return field, true
+	}
+	if tag == "json" {
+		for name, field := range fields {
+			if strings.EqualFold(name, key) {
+				return field, true
+			}
+		}
+	}
+	return namedField{}, false
+}
+
+// structFields maps the keys of t's decodable fields to the fields,
+// including those of embedded structs
+func structFields(t reflect.Type, tag string) map[string]namedField {
+	fields := make(map[string]namedField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+		if (field.Anonymous && name == "") || strings.Contains(opts, "inline") {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for key, f := range structFields(embedded, tag) {
+					fields[key] = f
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+			if tag == "yaml" {
+				name = strings.ToLower(name)
+			}
+		}
+		fields[name] = namedField{Name: name, Type: field.Type}
+		if tag == "toml" {
+			if _, ok := fields[strings.ToLower(field.Name)]; !ok {
+				fields[strings.ToLower(field.Name)] = namedField{Name: name, Type: field.Type}
+			}
+		}
+	}
+	return fields
+}
+
+// fieldNames returns the keys fields are decoded from
+func fieldNames(fields map[string]namedField) []string {
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+	sort.Strings(names)
+	names = slices.Compact(names)
+	return names
+}
+
+// joinKey appends key to a dotted path
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// Suggest returns the candidate closest to name by edit distance, ignoring
+// case, or "" when none is close enough to be a likely typo
+func Suggest(name string, candidates []string) string {
+	best, bestDistance := "", -1
+	lower := strings.ToLower(name)
+	for _, candidate := range candidates {
+		d := Levenshtein(lower, strings.ToLower(candidate))
+		if bestDistance < 0 || d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if bestDistance < 0 || bestDistance > 2 && bestDistance > len(name)/3 {
+		return ""
+	}
+	return best
+}
+
+// Levenshtein returns the number of single-character insertions, deletions
+// and substitutions needed to turn a into b
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
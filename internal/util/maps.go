@@ -0,0 +1,58 @@
+package util
+
+import "fmt"
+
+// NormalizeMap converts the nested maps YAML decodes to
+// (map[interface{}]interface{}) into map[string]interface{}, recursively and
+// in place, so YAML, TOML and JSON data have the same shape. It returns m.
+func NormalizeMap(m map[string]interface{}) map[string]interface{} {
+	for key, value := range m {
+		m[key] = NormalizeValue(value)
+	}
+	return m
+}
+
+// NormalizeValue normalizes a decoded value: maps get string keys and lists
+// are normalized element by element. Other values are returned unchanged.
+func NormalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = NormalizeValue(item)
+		}
+		return m
+	case map[string]interface{}:
+		return NormalizeMap(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = NormalizeValue(item)
+		}
+		return v
+	case []map[string]interface{}:
+		for _, item := range v {
+			NormalizeMap(item)
+		}
+		return v
+	}
+	return value
+}
+
+// MergeMaps deep-merges src into dst and returns dst: nested maps are merged
+// key by key, any other value in src replaces the one in dst. Nil values in
+// src leave dst unchanged.
+func MergeMaps(dst, src map[string]interface{}) map[string]interface{} {
+	for key, value := range src {
+		if value == nil {
+			continue
+		}
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			MergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+	return dst
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SlashPath converts a file path to a slash-separated path for use in URLs,
+// slugs and template names. Backslashes are converted too, not only the host
+// separator, so a path written on Windows gives the same result everywhere.
+func SlashPath(p string) string {
+	return strings.ReplaceAll(filepath.ToSlash(p), `\`, "/")
+}
+
+// RelSlashPath returns target relative to base as a slash-separated path
+func RelSlashPath(base, target string) (string, error) {
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return "", err
+	}
+	return SlashPath(rel), nil
+}
+
+// ResolvePath returns p as an absolute path with symlinks resolved, as far
+// as p exists
+func ResolvePath(p string) string {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return filepath.Clean(p)
+	}
+	rest := ""
+	for dir := abs; ; {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(resolved, rest)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return abs
+		}
+		rest = filepath.Join(filepath.Base(dir), rest)
+		dir = parent
+	}
+}
+
+// IsWithin reports whether p is dir or inside it, following symlinks
+func IsWithin(p, dir string) bool {
+	rel, err := filepath.Rel(ResolvePath(dir), ResolvePath(p))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// SlugURL returns the site-relative URL of the page at slug, e.g.
+// "/posts/my-post/" for "posts/my-post"
+func SlugURL(slug string) string {
+	slug = strings.Trim(SlashPath(slug), "/")
+	if slug == "" {
+		return "/"
+	}
+	return "/" + slug + "/"
+}
+
+// FirstSegment returns the first element of a slash-separated path, e.g.
+// "posts" for "posts/my-post"
+func FirstSegment(p string) string {
+	first, _, _ := strings.Cut(strings.TrimPrefix(SlashPath(p), "/"), "/")
+	return first
+}
+
+// OutputPath joins slash-separated path elements, such as a slug or URL path,
+// onto the file system directory dir
+func OutputPath(dir string, elem ...string) string {
+	parts := make([]string, len(elem))
+	for i, e := range elem {
+		parts[i] = SlashPath(e)
+	}
+	return filepath.Join(dir, filepath.FromSlash(path.Join(parts...)))
+}
@@ -0,0 +1,65 @@
+// Package util holds small text helpers shared by the content parser, the
+// theme template functions, configuration loading and the CLI.
+package util
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// WordsPerMinute is the reading speed used for reading time estimates
+const WordsPerMinute = 200
+
+var (
+	tagPattern        = regexp.MustCompile(`<[^>]*>`)
+	nonSlugPattern    = regexp.MustCompile(`[^a-zA-Z0-9\s-]`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+	dashesPattern     = regexp.MustCompile(`-+`)
+
+	// HeadingPattern matches a rendered heading, capturing its level, id
+	// and text
+	HeadingPattern = regexp.MustCompile(`<h([1-6])(?:\s+id="([^"]*)")?[^>]*>([^<]+)</h[1-6]>`)
+)
+
+// ParseInt parses a base-10 integer with an optional sign, ignoring
+// surrounding whitespace. Malformed or out-of-range input yields fallback.
+func ParseInt(s string, fallback int) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+// Slugify converts text to a URL- and filename-safe slug. HTML entities are
+// decoded first so "Tips &amp; Tricks" and "Tips & Tricks" agree.
+func Slugify(text string) string {
+	text = html.UnescapeString(text)
+	text = nonSlugPattern.ReplaceAllString(text, "")
+	text = strings.ToLower(text)
+	text = whitespacePattern.ReplaceAllString(text, "-")
+	text = dashesPattern.ReplaceAllString(text, "-")
+	return strings.Trim(text, "-")
+}
+
+// StripHTML removes tags from content and decodes HTML entities. Tags are
+// replaced by a space so adjacent block elements don't merge into one word.
+func StripHTML(content string) string {
+	return html.UnescapeString(tagPattern.ReplaceAllString(content, " "))
+}
+
+// WordCount counts the words in HTML content
+func WordCount(content string) int {
+	return len(strings.Fields(StripHTML(content)))
+}
+
+// ReadingTime returns the estimated reading time in minutes, at least one
+func ReadingTime(wordCount int) int {
+	minutes := (wordCount + WordsPerMinute - 1) / WordsPerMinute
+	if minutes < 1 {
+		return 1
+	}
+	return minutes
+}
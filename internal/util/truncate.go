@@ -0,0 +1,263 @@
+package util
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultEllipsis marks truncated text unless the site configures another
+const DefaultEllipsis = "…"
+
+// sentenceSlack is how far before the limit a summary may end at a sentence
+// rather than mid-sentence with an ellipsis
+const sentenceSlack = 50
+
+var entityPattern = regexp.MustCompile(`^&(?:#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z][a-zA-Z0-9]*);`)
+
+// voidElements never have a closing tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// IsVoidElement reports whether an HTML element never has a closing tag
+func IsVoidElement(name string) bool {
+	return voidElements[strings.ToLower(name)]
+}
+
+// rawTextElements hold text that isn't shown, such as scripts
+var rawTextElements = map[string]bool{"script": true, "style": true, "template": true}
+
+// Truncate shortens plain text to at most limit characters, cutting at the
+// end of a word when it can, and appends ellipsis when anything was cut
+func Truncate(text string, limit int, ellipsis string) string {
+	return truncate(text, limit, ellipsis, truncation{})
+}
+
+// TruncateHTML is Truncate for HTML: tags are kept and the ones left open
+// are closed, entities count as one character and are never split, and
+// scripts and styles don't count
+func TruncateHTML(content string, limit int, ellipsis string) string {
+	return truncate(content, limit, ellipsis, truncation{markup: true})
+}
+
+// TruncateWords shortens plain text to at most limit words, appending
+// ellipsis when anything was cut. Each Chinese or Japanese character counts
+// as a word, as those scripts don't separate words with spaces.
+func TruncateWords(text string, limit int, ellipsis string) string {
+	return truncate(text, limit, ellipsis, truncation{words: true})
+}
+
+// SummarizeHTML is TruncateHTML for page summaries: it ends at the last
+// sentence instead, without an ellipsis, when one ends close to the limit
+func SummarizeHTML(content string, limit int, ellipsis string) string {
+	return truncate(content, limit, ellipsis, truncation{markup: true, sentence: true})
+}
+
+// truncation selects how truncate measures and cuts text
+type truncation struct {
+	markup   bool // The text is HTML
+	words    bool // The limit counts words rather than characters
+	sentence bool // Prefer ending at a sentence near the limit
+}
+
+// truncate implements the exported truncation functions. Lengths are
+// counted in runes of visible text, so multibyte characters are never
+// split and the same text has the same length however it's encoded.
+func truncate(s string, limit int, ellipsis string, opts truncation) string {
+	limit = max(limit, 0)
+	count := 0
+	inWord, prevCJK := false, false
+	wordEnd := -1      // End of the last complete word
+	visibleEnd := 0    // End of the last visible, non-space character
+	sentenceEnd := -1  // End of the last sentence
+	sentenceCount := 0 // Characters up to sentenceEnd
+
+	for i := 0; i < len(s); {
+		end, r, visible := nextToken(s, i, opts.markup)
+		if !visible {
+			i = end
+			continue
+		}
+		if unicode.IsSpace(r) {
+			if inWord {
+				wordEnd = visibleEnd
+			}
+			inWord = false
+			if !opts.words && count < limit {
+				count++
+			}
+			i = end
+			continue
+		}
+
+		cjk := isCJK(r)
+		startsWord := !inWord || cjk || prevCJK
+		if startsWord && inWord {
+			wordEnd = visibleEnd
+		}
+		if opts.words {
+			if startsWord {
+				if count == limit {
+					return finishTruncation(s, visibleEnd, ellipsis, opts.markup)
+				}
+				count++
+			}
+		} else {
+			if count == limit {
+				if opts.sentence && sentenceEnd > 0 && sentenceCount > limit-sentenceSlack {
+					return finishTruncation(s, sentenceEnd, "", opts.markup)
+				}
+				cut := visibleEnd
+				if !startsWord && wordEnd > 0 {
+					cut = wordEnd
+				}
+				return finishTruncation(s, cut, ellipsis, opts.markup)
+			}
+			count++
+		}
+
+		inWord, prevCJK = true, cjk
+		visibleEnd = end
+		if strings.ContainsRune(".!?。！？", r) {
+			sentenceEnd, sentenceCount = end, count
+		}
+		i = end
+	}
+	return s
+}
+
+// finishTruncation cuts s at cut, appends ellipsis and closes the elements
+// left open
+func finishTruncation(s string, cut int, ellipsis string, markup bool) string {
+	var out strings.Builder
+	out.WriteString(strings.TrimRightFunc(s[:cut], unicode.IsSpace))
+	out.WriteString(ellipsis)
+	if markup {
+		open := openElements(s[:cut])
+		for i := len(open) - 1; i >= 0; i-- {
+			out.WriteString("</" + open[i] + ">")
+		}
+	}
+	return out.String()
+}
+
+// openElements returns the elements still open at the end of an HTML
+// fragment, outermost first
+func openElements(s string) []string {
+	var open []string
+	for i := 0; i < len(s); {
+		end, _, visible := nextToken(s, i, true)
+		if !visible {
+			name, closing := tagName(s[i:end])
+			switch {
+			case name == "" || voidElements[name] || rawTextElements[name] || strings.HasSuffix(s[i:end], "/>"):
+			case closing:
+				for j := len(open) - 1; j >= 0; j-- {
+					if open[j] == name {
+						open = open[:j]
+						break
+					}
+				}
+			default:
+				open = append(open, name)
+			}
+		}
+		i = end
+	}
+	return open
+}
+
+// nextToken returns the end of the token starting at i and, for visible
+// text, its character. In markup, tags, comments and raw text elements are
+// invisible tokens and an entity is a single character.
+func nextToken(s string, i int, markup bool) (int, rune, bool) {
+	if markup {
+		switch s[i] {
+		case '<':
+			if end := tagEnd(s, i); end > 0 {
+				if name, closing := tagName(s[i:end]); rawTextElements[name] && !closing {
+					if close := IndexFold(s[end:], "</"+name); close >= 0 {
+						if after := strings.IndexByte(s[end+close:], '>'); after >= 0 {
+							return end + close + after + 1, 0, false
+						}
+					}
+					return len(s), 0, false
+				}
+				return end, 0, false
+			}
+		case '&':
+			if entity := entityPattern.FindString(s[i:]); entity != "" {
+				r, _ := utf8.DecodeRuneInString(html.UnescapeString(entity))
+				return i + len(entity), r, true
+			}
+		}
+	}
+	r, size := utf8.DecodeRuneInString(s[i:])
+	return i + size, r, true
+}
+
+// tagEnd returns the end of the tag or comment starting at s[i], or 0 when
+// the "<" doesn't start one
+func tagEnd(s string, i int) int {
+	if strings.HasPrefix(s[i:], "<!--") {
+		if end := strings.Index(s[i+4:], "-->"); end >= 0 {
+			return i + 4 + end + 3
+		}
+		return len(s)
+	}
+	if i+1 >= len(s) {
+		return 0
+	}
+	if c := s[i+1]; !(c == '/' || c == '!' || c == '?' || c < utf8.RuneSelf && unicode.IsLetter(rune(c))) {
+		return 0
+	}
+	var quote byte
+	for j := i + 1; j < len(s); j++ {
+		switch c := s[j]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return j + 1
+		}
+	}
+	return 0
+}
+
+// tagName returns the lowercased element name of a start or end tag, and
+// whether it is an end tag. Comments and declarations have no name.
+func tagName(tag string) (string, bool) {
+	tag = strings.TrimPrefix(tag, "<")
+	closing := strings.HasPrefix(tag, "/")
+	tag = strings.TrimPrefix(tag, "/")
+	end := strings.IndexFunc(tag, func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-')
+	})
+	if end < 0 {
+		end = len(tag)
+	}
+	return strings.ToLower(tag[:end]), closing
+}
+
+// IndexFold is strings.Index ignoring the case of ASCII letters
+func IndexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isCJK reports whether r is Chinese or Japanese, written without spaces
+// between words
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
@@ -0,0 +1,174 @@
+// Package version describes the running VanGo binary and checks for newer
+// releases
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Set at build time, e.g.
+//
+//	go build -ldflags "-X vango/internal/version.Version=2.1.0 \
+//	  -X vango/internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X vango/internal/version.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Commit and Date fall back to what the Go toolchain recorded about the
+// checkout the binary was built from.
+var (
+	Version = "2.0.0"
+	Commit  = ""
+	Date    = ""
+)
+
+// ReleasesURL is the GitHub API endpoint for the latest release
+const ReleasesURL = "https://api.github.com/repos/vango/vango/releases/latest"
+
+// pseudoVersion matches the timestamp and commit of a Go pseudo-version
+var pseudoVersion = regexp.MustCompile(`\d{14}-[0-9a-f]{12}`)
+
+// NoUpdateCheckEnv turns off update checks when set to anything but ""
+const NoUpdateCheckEnv = "VANGO_NO_UPDATE_CHECK"
+
+// Info describes the binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // Built from a checkout with uncommitted changes
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get returns the version info from the build flags, filling gaps from
+// the build info the Go toolchain embeds
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	// Installed with go install module@version; local builds get "(devel)"
+	// or a pseudo-version made up from the commit
+	if v := build.Main.Version; v != "" && v != "(devel)" && !pseudoVersion.MatchString(v) {
+		info.Version = strings.TrimPrefix(v, "v")
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+				if len(info.Commit) > 7 {
+					info.Commit = info.Commit[:7]
+				}
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// Release is a published VanGo release
+type Release struct {
+	Tag string `json:"tag_name"`
+	URL string `json:"html_url"`
+}
+
+// Latest asks url, a GitHub latest-release endpoint, for the newest
+// release, giving up after timeout
+func Latest(url string, timeout time.Duration) (*Release, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "vango/"+Version)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %s", url, resp.Status)
+	}
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("bad release data: %w", err)
+	}
+	if release.Tag == "" {
+		return nil, fmt.Errorf("release has no tag")
+	}
+	return &release, nil
+}
+
+// Compare compares two semantic versions, with or without a leading "v",
+// returning -1, 0 or 1. A pre-release sorts before its release; versions
+// that don't parse compare equal to everything.
+func Compare(a, b string) int {
+	va, preA, okA := parse(a)
+	vb, preB, okB := parse(b)
+	if !okA || !okB {
+		return 0
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	case preA < preB:
+		return -1
+	}
+	return 1
+}
+
+// parse splits "v1.2.3-rc.1+meta" into its numbers and pre-release
+func parse(v string) ([3]int, string, bool) {
+	var nums [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, _, _ = strings.Cut(v, "+")
+	v, pre, _ := strings.Cut(v, "-")
+	parts := strings.Split(v, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return nums, "", false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nums, "", false
+		}
+		nums[i] = n
+	}
+	return nums, pre, true
+}
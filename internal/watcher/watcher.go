@@ -0,0 +1,273 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vango/internal/config"
+	"vango/internal/logging"
+	"vango/internal/util"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is how long the watcher waits for further changes before
+// reporting a batch
+const DefaultDebounce = 300 * time.Millisecond
+
+// Change is a debounced batch of file changes
+type Change struct {
+	Files []string
+	// ConfigChanged is set when the site configuration file changed; the
+	// configuration should be reloaded and the site fully rebuilt
+	ConfigChanged bool
+}
+
+// StylesOnly reports whether every changed file is a stylesheet (CSS, SCSS
+// or Sass), which open pages can pick up without a full reload
+func (c Change) StylesOnly() bool {
+	if c.ConfigChanged || len(c.Files) == 0 {
+		return false
+	}
+	for _, file := range c.Files {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".css", ".scss", ".sass":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Handler is called with each batch of changes. Calls never overlap: the
+// changes made while one runs are collected into a single next batch.
+type Handler func(change Change)
+
+// Watcher watches the site's source directories and configuration files
+type Watcher struct {
+	config      *config.Config
+	configMu    sync.RWMutex // Handlers may call SetConfig while Run reads it
+	configFiles []string
+	debounce    time.Duration
+	verbose     bool
+	watcher     *fsnotify.Watcher
+}
+
+// New creates a watcher over the content, layout, theme, static, assets,
+// i18n and data directories of cfg. configFiles are the configuration files to watch
+// for reloads, usually from config.ConfigFiles.
+func New(cfg *config.Config, configFiles []string) (*Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	w := &Watcher{
+		config:      cfg,
+		configFiles: configFiles,
+		debounce:    DefaultDebounce,
+		watcher:     fw,
+	}
+	w.addPaths()
+	return w, nil
+}
+
+// SetVerbose logs every watched directory
+func (w *Watcher) SetVerbose(verbose bool) {
+	w.verbose = verbose
+}
+
+// SetDebounce changes how long changes are collected before being reported
+func (w *Watcher) SetDebounce(d time.Duration) {
+	w.debounce = d
+}
+
+// SetConfig switches the watcher to a reloaded configuration, watching any
+// directories it adds
+func (w *Watcher) SetConfig(cfg *config.Config) {
+	w.configMu.Lock()
+	w.config = cfg
+	w.configMu.Unlock()
+	w.addPaths()
+}
+
+// currentConfig returns the configuration being watched
+func (w *Watcher) currentConfig() *config.Config {
+	w.configMu.RLock()
+	defer w.configMu.RUnlock()
+	return w.config
+}
+
+// WatchCount returns the number of watched paths
+func (w *Watcher) WatchCount() int {
+	return len(w.watcher.WatchList())
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.watcher.Close()
+}
+
+// dirs returns the source directories that exist for the current configuration
+func (w *Watcher) dirs() []string {
+	cfg := w.currentConfig()
+	dirs := []string{cfg.ContentDir, cfg.LayoutDir}
+
+	// Add theme directory if active
+	if cfg.Theme != "" {
+		themesDir := cfg.ThemesDir
+		if themesDir == "" {
+			themesDir = "themes"
+		}
+		dirs = append(dirs, filepath.Join(themesDir, cfg.Theme))
+	}
+	dirs = append(dirs, cfg.StaticDir, cfg.AssetsDir, cfg.I18nDir, cfg.DataDir)
+
+	var existing []string
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			existing = append(existing, dir)
+		}
+	}
+	return existing
+}
+
+// addPaths watches the configuration files and every source directory recursively
+func (w *Watcher) addPaths() {
+	for _, file := range w.configFiles {
+		if _, err := os.Stat(file); err == nil {
+			w.watcher.Add(file)
+		}
+	}
+
+	for _, dir := range w.dirs() {
+		if err := w.addTree(dir); err != nil {
+			logging.Errorf("Error setting up watcher for %s: %v", dir, err)
+		}
+	}
+}
+
+// addTree watches dir and all of its subdirectories
+func (w *Watcher) addTree(dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != dir && Ignored(path) || w.isOutput(path) {
+			return filepath.SkipDir
+		}
+		logging.Debugf("👀 Watching directory: %s", path)
+		return w.watcher.Add(path)
+	})
+}
+
+// Ignored reports whether changes to path should be ignored: hidden files
+// and editor backup or temporary files
+func Ignored(path string) bool {
+	name := filepath.Base(path)
+	return strings.HasPrefix(name, ".") ||
+		strings.HasSuffix(name, "~") ||
+		strings.HasSuffix(name, ".tmp") ||
+		strings.HasSuffix(name, ".swp")
+}
+
+// Run reports batches of changes to handler until the watcher is closed.
+// Changes arriving while handler runs are collected into the next batch,
+// so a burst of changes queues at most one more call however long it lasts.
+func (w *Watcher) Run(handler Handler) {
+	pending := make(map[string]bool)
+	configChanged := false
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	running := false
+	done := make(chan struct{}, 1)
+
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if Ignored(event.Name) || event.Op == fsnotify.Chmod || w.isOutput(event.Name) {
+				continue
+			}
+
+			// Pick up directories created after the watcher started
+			if event.Op&fsnotify.Create == fsnotify.Create {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					w.addTree(event.Name)
+				}
+			}
+
+			if w.isConfigFile(event.Name) {
+				// Editors that save by rename drop the watch on the old file
+				w.watcher.Add(event.Name)
+				configChanged = true
+			} else {
+				pending[event.Name] = true
+			}
+			timer.Reset(w.debounce)
+
+		case <-timer.C:
+			if running || len(pending) == 0 && !configChanged {
+				// The batch waits for the running handler
+				continue
+			}
+			change := Change{ConfigChanged: configChanged}
+			for path := range pending {
+				change.Files = append(change.Files, path)
+			}
+			sort.Strings(change.Files)
+			pending = make(map[string]bool)
+			configChanged = false
+
+			running = true
+			go func() {
+				handler(change)
+				done <- struct{}{}
+			}()
+
+		case <-done:
+			running = false
+			if len(pending) > 0 || configChanged {
+				timer.Reset(w.debounce)
+			}
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			logging.Warnf("⚠️ File watcher error: %v", err)
+		}
+	}
+}
+
+// isOutput reports whether path is in the public or cache directory, which
+// builds write to: watching them would make every build trigger the next
+func (w *Watcher) isOutput(path string) bool {
+	cfg := w.currentConfig()
+	for _, dir := range []string{cfg.PublicDir, cfg.Performance.CacheDir} {
+		if dir != "" && util.IsWithin(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// isConfigFile reports whether path is one of the watched configuration files
+func (w *Watcher) isConfigFile(path string) bool {
+	for _, file := range w.configFiles {
+		if filepath.Clean(path) == filepath.Clean(file) {
+			return true
+		}
+	}
+	return false
+}
@@ -60,6 +60,2249 @@ else
     echo "   ✗ Site build failed or timed out"
 fi
 
+# Test 6: Reproducible builds
+echo ""
+echo "6. Testing reproducible builds..."
+first_build=$(mktemp -d)
+if timeout 30s go run main.go build --clean --reproducible >/dev/null 2>&1 && cp -r public/. "$first_build" &&
+    sleep 1 && timeout 30s go run main.go build --clean --reproducible >/dev/null 2>&1; then
+    if diff -r "$first_build" public >/dev/null; then
+        echo "   ✓ Two builds produced identical output"
+    else
+        echo "   ✗ Builds differ:"
+        diff -rq "$first_build" public | head -10
+    fi
+else
+    echo "   ✗ Reproducible build failed"
+fi
+rm -rf "$first_build"
+
+# Test 7: Concurrent rendering under the race detector
+echo ""
+echo "7. Testing concurrent rendering for data races..."
+race_site=$(mktemp -d)
+cp -r config.toml layouts static themes "$race_site"
+mkdir -p "$race_site/content/posts"
+cp content/*.md "$race_site/content"
+for i in $(seq 1 300); do
+    printf '+++\ntitle = "Post %d"\ndate = "2024-01-01"\ntags = ["tag-%d"]\n+++\n\n## Heading %d\n\nSome **text** and a [link](/about/).\n' \
+        "$i" "$((i % 7))" "$i" > "$race_site/content/posts/post-$i.md"
+done
+if go build -race -o "$race_site/vango-race" main.go 2>/dev/null; then
+    race_log=$(cd "$race_site" && timeout 300s ./vango-race build --clean --workers 8 2>&1)
+    if echo "$race_log" | grep -q "DATA RACE"; then
+        echo "   ✗ Data race detected:"
+        echo "$race_log" | grep -A20 "DATA RACE" | head -40
+    else
+        echo "   ✓ Rendered 300 pages concurrently without data races"
+    fi
+else
+    echo "   - Race detector unavailable, skipped"
+fi
+rm -rf "$race_site"
+
+# Test 8: Template helpers with slices, maps, nil and mixed values
+echo ""
+echo "8. Testing template helpers with non-comparable values..."
+mixed_page=content/test-mixed-values.md
+cat > "$mixed_page" <<'PAGE'
+---
+title: Mixed values
+draft: true
+params:
+  items: [[1, 2], [1, 2], {a: 1}, {a: 1}, null, "x", 3, 3]
+  pair: [1, 2]
+---
+PAGE
+helpers_ok=true
+while IFS= read -r snippet; do
+    if ! output=$(go run main.go tpl exec "$snippet" --page "$mixed_page" 2>&1); then
+        echo "   ✗ $snippet"
+        echo "$output" | tail -3
+        helpers_ok=false
+    fi
+done <<'SNIPPETS'
+{{ unique .Page.Params.items }}
+{{ switch .Page.Params.pair .Page.Params.items "all" (index .Page.Params.items 0) "first" "none" }}
+{{ switch (index .Page.Params.items 2) (index .Page.Params.items 3) "map" nil "nil" }}
+{{ switch nil nil "nil" "none" }}
+{{ ifAll .Page.Params.items nil }} {{ ifAny nil "" .Page.Params.pair }} {{ ifNotEmpty .Page.Params.items }}
+{{ paginate .Page.Params.items 0 2 }} {{ paginate .Page.Params.items 2 -1 }} {{ seq -3 }}
+{{ truncateWords "a b c" -1 }} {{ excerpt "a b" -2 }} {{ default "d" .Page.Params.items }}
+SNIPPETS
+rm -f "$mixed_page"
+if $helpers_ok; then
+    echo "   ✓ Helpers handled every value without failing"
+fi
+
+# Test 9: Layered configuration files
+echo ""
+echo "9. Testing layered configuration files..."
+layer_site=$(mktemp -d)
+mkdir -p "$layer_site/content" "$layer_site/layouts"
+cat > "$layer_site/config.toml" <<'CONFIG'
+title = "Base"
+baseURL = "https://example.com/"
+[params]
+  keep = "base"
+  version = "1"
+  [params.social]
+    twitter = "@base"
+    github = "base"
+CONFIG
+cat > "$layer_site/extra.yaml" <<'CONFIG'
+title: Extra
+params:
+  version: "2"
+  social:
+    github: extra
+CONFIG
+cat > "$layer_site/config.local.toml" <<'CONFIG'
+baseURL = "http://localhost:9000/"
+[params]
+  version = "3"
+CONFIG
+go build -o "$layer_site/vango" main.go
+layers_ok=true
+check_layers() {
+    local expected=$1; shift
+    local output
+    output=$(cd "$layer_site" && ./vango "$@" tpl exec \
+        '{{ .Site.Title }} {{ .Site.BaseURL }} {{ .Site.Params.keep }} {{ .Site.Params.version }} {{ .Site.Params.social.twitter }} {{ .Site.Params.social.github }}' 2>/dev/null | tail -1)
+    if [ "$output" != "$expected" ]; then
+        echo "   ✗ vango $*: got \"$output\", want \"$expected\""
+        layers_ok=false
+    fi
+}
+check_layers "Extra http://localhost:9000/ base 3 @base extra" -c config.toml -c extra.yaml
+check_layers "Extra http://localhost:9000/ base 3 @base extra" -c config.toml,extra.yaml
+check_layers "Extra https://example.com/ base 2 @base extra" -c config.toml -c extra.yaml --no-local-config
+check_layers "Base https://example.com/ base 1 @base base" --no-local-config
+check_layers "Base https://example.com/ base 1 @base base" -c config.local.toml -c config.toml --no-local-config
+files=$(cd "$layer_site" && ./vango -c config.toml -c extra.yaml config show 2>/dev/null | sed -n '/^Files/,$p' | tail -n +2 | tr -d ' ' | tr '\n' ' ')
+if [ "$files" != "config.toml extra.yaml config.local.toml " ]; then
+    echo "   ✗ config show listed \"$files\""
+    layers_ok=false
+fi
+rm -rf "$layer_site"
+if $layers_ok; then
+    echo "   ✓ Configuration files merged in order"
+fi
+
+# Test 10: Accessibility audit in validate
+echo ""
+echo "10. Testing the accessibility audit..."
+a11y_site=$(mktemp -d)
+go build -o "$a11y_site/vango" main.go
+(cd "$a11y_site" && ./vango new site s -q >/dev/null 2>&1)
+a11y_ok=true
+if ! (cd "$a11y_site/s" && ../vango validate -q --strict >/dev/null 2>&1); then
+    echo "   ✗ Scaffolded site has accessibility issues"
+    a11y_ok=false
+fi
+cat > "$a11y_site/s/content/broken.md" <<'CONTENT'
+---
+title: "Broken"
+date: 2024-05-01
+---
+
+<img src="/photo.jpg">
+
+[](/nowhere/)
+CONTENT
+rules=$(cd "$a11y_site/s" && ../vango validate -q --format json 2>/dev/null | grep -o '"check": "a11y/[a-z-]*"' | sort -u | tr '\n' ' ')
+if [ "$rules" != '"check": "a11y/empty-link" "check": "a11y/image-alt" ' ]; then
+    echo "   ✗ validate reported \"$rules\""
+    a11y_ok=false
+fi
+if ! (cd "$a11y_site/s" && ../vango validate -q --format json 2>/dev/null | grep -q '"file": "content/broken.md"'); then
+    echo "   ✗ Issues not attributed to content/broken.md"
+    a11y_ok=false
+fi
+rm -rf "$a11y_site"
+if $a11y_ok; then
+    echo "   ✓ Accessibility issues reported with their source files"
+fi
+
+# Test 11: Custom top-level front matter keys land in Params
+echo ""
+echo "11. Testing custom front matter keys in params..."
+params_site=$(mktemp -d)
+mkdir -p "$params_site/content" "$params_site/layouts"
+go build -o "$params_site/vango" main.go
+cat > "$params_site/content/toml.md" <<'PAGE'
++++
+title = "TOML"
+technologies = ["go", "toml"]
+Client = "Upper"
+client = "lower"
+Role = "top"
+[params]
+Role = "params"
++++
+PAGE
+cat > "$params_site/content/yaml.md" <<'PAGE'
+---
+title: YAML
+Technologies: [go, yaml]
+client: lower
+role: params
+---
+PAGE
+cat > "$params_site/content/json.md" <<'PAGE'
+{
+  "title": "JSON",
+  "technologies": ["go", "json"],
+  "params": {"client": "lower", "role": "params"}
+}
+PAGE
+params_ok=true
+for page in toml yaml json; do
+    output=$(cd "$params_site" && ./vango tpl exec --page "content/$page.md" \
+        '{{ range .Page.Params.technologies }}{{ . }} {{ end }}{{ .Page.Params.client }} {{ .Page.Params.role }} {{ .Page.Params.title }}' 2>/dev/null | tail -1)
+    if [ "$output" != "go $page lower params " ]; then
+        echo "   ✗ $page front matter: got \"$output\", want \"go $page lower params \""
+        params_ok=false
+    fi
+done
+rm -rf "$params_site"
+if $params_ok; then
+    echo "   ✓ Custom keys merged into params, lowercased, [params] first"
+fi
+
+# Test 12: Truncation with multibyte text and entities
+echo ""
+echo "12. Testing truncation of emoji, CJK and entity-heavy text..."
+trunc_site=$(mktemp -d)
+mkdir -p "$trunc_site/content" "$trunc_site/layouts"
+go build -o "$trunc_site/vango" main.go
+trunc_ok=true
+while IFS='|' read -r snippet expected; do
+    output=$(cd "$trunc_site" && ./vango tpl exec "$snippet" 2>/dev/null | tail -1)
+    if [ "$output" != "$expected" ]; then
+        echo "   ✗ $snippet: got \"$output\", want \"$expected\""
+        trunc_ok=false
+    fi
+done <<'SNIPPETS'
+{{ truncate "😀😀😀😀" 2 }}|😀😀…
+{{ truncate "Tom &amp; Jerry" 5 }}|Tom &amp;…
+{{ truncate "<p>Tom</p><p>and Jerry</p>" 8 }}|Tom and…
+{{ truncateHTML "<p>Tom &amp; Jerry</p>" 5 }}|<p>Tom &amp;…</p>
+{{ truncateHTML "<p>ab&amp;cd</p>" 3 }}|<p>ab&amp;…</p>
+{{ truncateHTML "<em>very <strong>good friends</strong></em>" 10 }}|<em>very <strong>good…</strong></em>
+{{ truncateHTML "<p>日本語の文章</p>" 3 }}|<p>日本語…</p>
+{{ truncateWords "日本語の文章 and more" 4 }}|日本語の…
+{{ truncateWords "naïve café crème" 2 }}|naïve café…
+{{ excerpt "<p>Tom &amp; Jerry</p> <p>again</p>" 3 }}|Tom &amp; Jerry…
+SNIPPETS
+rm -rf "$trunc_site"
+if $trunc_ok; then
+    echo "   ✓ Text cut at whole characters and words, tags closed"
+fi
+
+# Test 13: templates.trimWhitespace against the bundled themes
+echo ""
+echo "13. Testing whitespace trimming of the bundled themes..."
+tidy_site=$(mktemp -d)
+go build -o "$tidy_site/vango" main.go
+(cd "$tidy_site" && ./vango new site s -q >/dev/null 2>&1)
+printf -- '---\ntitle: "Code"\ndate: 2024-05-01\n---\n\n```go\nfunc main() {   \n\n\n\tprintln("hi")\t\n}\n```\n\n<textarea>keep   \n\n\n  this</textarea>\n\n<script>var a = 1;   \n\n\n</script>\n' > "$tidy_site/s/content/code.md"
+printf '[templates]\ntrimWhitespace = true\n' > "$tidy_site/s/tidy.toml"
+# raw_regions prints the pre, textarea, script and style elements of a page
+raw_regions() {
+    awk 'tolower($0) ~ /<(pre|textarea|script|style)[ >]/ { raw = 1 }
+         raw { print }
+         tolower($0) ~ /<\/(pre|textarea|script|style)>/ { raw = 0 }' "$1"
+}
+tidy_ok=true
+for theme in modern-app basic blog docs; do
+    if [ "$theme" != "modern-app" ]; then
+        (cd "$tidy_site/s" && ../vango theme create "$theme" -t "$theme" -q >/dev/null 2>&1)
+    fi
+    printf 'theme = "%s"\n' "$theme" > "$tidy_site/s/theme.toml"
+    (cd "$tidy_site/s" && ../vango build -q -c config.toml -c theme.toml >/dev/null 2>&1 && mv public ../raw-$theme)
+    (cd "$tidy_site/s" && ../vango build -q -c config.toml -c theme.toml -c tidy.toml >/dev/null 2>&1 && mv public ../tidy-$theme)
+    if [ ! -f "$tidy_site/tidy-$theme/code/index.html" ]; then
+        echo "   ✗ $theme: build failed"
+        tidy_ok=false
+        continue
+    fi
+    for page in $(cd "$tidy_site/raw-$theme" && find . -name '*.html'); do
+        raw="$tidy_site/raw-$theme/$page"
+        tidy="$tidy_site/tidy-$theme/$page"
+        if ! cmp -s <(raw_regions "$raw") <(raw_regions "$tidy"); then
+            echo "   ✗ $theme $page: preformatted content changed"
+            tidy_ok=false
+        fi
+        if ! cmp -s <(sed 's/[[:space:]]*$//' "$raw" | cat -s) <(sed 's/[[:space:]]*$//' "$tidy" | cat -s); then
+            echo "   ✗ $theme $page: more than whitespace changed"
+            tidy_ok=false
+        fi
+    done
+    if [ "$(grep -c -e 'main() {   $' -e 'keep   $' -e 'a = 1;   $' "$tidy_site/tidy-$theme/code/index.html")" != 3 ]; then
+        echo "   ✗ $theme: trailing whitespace inside pre, textarea or script was trimmed"
+        tidy_ok=false
+    fi
+    leftover=$(find "$tidy_site/tidy-$theme" -name '*.html' -exec awk '
+        FNR == 1 { raw = 0 }
+        tolower($0) ~ /<(pre|textarea|script|style)[ >]/ { raw = 1 }
+        !raw && /[ \t]$/ { print FILENAME ":" FNR }
+        tolower($0) ~ /<\/(pre|textarea|script|style)>/ { raw = 0 }' {} + | head -1)
+    if [ -n "$leftover" ]; then
+        echo "   ✗ $theme: trailing whitespace left at $leftover"
+        tidy_ok=false
+    fi
+done
+rm -rf "$tidy_site"
+if $tidy_ok; then
+    echo "   ✓ Themes trimmed, preformatted content kept byte for byte"
+fi
+
+# Test 14: Page kinds and their templates
+echo ""
+echo "14. Testing home and section pages..."
+kind_site=$(mktemp -d)
+mkdir -p "$kind_site/content/posts" "$kind_site/layouts/_default" "$kind_site/layouts/section"
+go build -o "$kind_site/vango" main.go
+printf -- '---\ntitle: "Home"\n---\n' > "$kind_site/content/_index.md"
+printf -- '---\ntitle: "Posts"\n---\n' > "$kind_site/content/posts/_index.md"
+printf -- '---\ntitle: "First"\ndate: 2024-05-01\n---\n' > "$kind_site/content/posts/first.md"
+echo '{{ .Page.Kind }} {{ .Page.IsPage }}' > "$kind_site/layouts/_default/single.html"
+echo '{{ .Page.Kind }} {{ .Page.IsHome }}' > "$kind_site/layouts/index.html"
+echo '{{ .Page.Kind }} {{ .Page.IsSection }}:{{ range .Pages }} {{ .Title }}{{ end }}' > "$kind_site/layouts/section/posts.html"
+(cd "$kind_site" && ./vango build -q >/dev/null 2>&1)
+kind_ok=true
+while IFS='|' read -r file expected; do
+    output=$(cat "$kind_site/public/$file" 2>/dev/null)
+    if [ "$output" != "$expected" ]; then
+        echo "   ✗ $file: got \"$output\", want \"$expected\""
+        kind_ok=false
+    fi
+done <<'PAGES'
+index.html|home true
+posts/index.html|section true: First
+posts/first/index.html|page true
+PAGES
+rm -rf "$kind_site"
+if $kind_ok; then
+    echo "   ✓ _index.md pages rendered as home and section with their templates"
+fi
+
+# Test 15: Watcher feedback loops and event storms
+echo ""
+echo "15. Testing the watcher under an event storm..."
+storm_site=$(mktemp -d)
+go build -o "$storm_site/vango" main.go
+(cd "$storm_site" && ./vango new site s -q >/dev/null 2>&1)
+storm_ok=true
+printf 'publicDir = "content/out"\n' > "$storm_site/s/inside.toml"
+if ! (cd "$storm_site/s" && ../vango build -q -c config.toml -c inside.toml 2>&1 | grep -q 'is inside contentDir'); then
+    echo "   ✗ publicDir inside contentDir not rejected"
+    storm_ok=false
+fi
+(cd "$storm_site/s" && ../vango build >/dev/null 2>&1)
+(cd "$storm_site/s" && exec ../vango build --watch >"$storm_site/watch.log" 2>&1) &
+watch_pid=$!
+sleep 2
+for i in $(seq 1 200); do
+    echo "Edit $i" >> "$storm_site/s/content/welcome.md"
+    echo "$i" > "$storm_site/s/public/storm-$i.html"
+    sleep 0.01
+done
+sleep 2
+mkdir -p "$storm_site/s/.cache"
+for i in $(seq 1 50); do
+    echo "$i" > "$storm_site/s/public/quiet-$i.html"
+    echo "$i" > "$storm_site/s/.cache/quiet-$i"
+done
+sleep 2
+kill "$watch_pid" 2>/dev/null
+wait "$watch_pid" 2>/dev/null
+rebuilds=$(grep -c "changed" "$storm_site/watch.log")
+if [ "$rebuilds" -lt 1 ] || [ "$rebuilds" -gt 3 ]; then
+    echo "   ✗ 200 edits caused $rebuilds rebuilds, want 1 to 3"
+    storm_ok=false
+fi
+if grep "changed" "$storm_site/watch.log" | grep -q "public/\|\.cache/"; then
+    echo "   ✗ Writes to the output or cache directory triggered a rebuild"
+    storm_ok=false
+fi
+rm -rf "$storm_site"
+if $storm_ok; then
+    echo "   ✓ Edits coalesced, output and cache writes ignored, publicDir inside content rejected"
+fi
+
+# Test 16: Content graph export
+echo ""
+echo "16. Testing the content graph export..."
+graph_site=$(mktemp -d)
+mkdir -p "$graph_site/content/posts"
+go build -o "$graph_site/vango" main.go
+printf -- '---\ntitle: "Home"\n---\n[First](/posts/first/) and [gone](/posts/gone/)\n' > "$graph_site/content/_index.md"
+printf -- '---\ntitle: "First"\ntags: [go]\n---\n[Second](../second/) twice: [again]({{< ref "second.md" >}})\n' > "$graph_site/content/posts/first.md"
+printf -- '---\ntitle: "Second"\ntags: [go]\n---\nNo links\n' > "$graph_site/content/posts/second.md"
+(cd "$graph_site" && ./vango graph --tags -o graph.json >/dev/null 2>&1 && ./vango graph -o graph.dot >/dev/null 2>&1)
+graph_ok=true
+if ! python3 - "$graph_site/graph.json" <<'PY'
+import json, sys
+g = json.load(open(sys.argv[1]))
+edges = {(e["from"], e["to"], e["type"]): e for e in g["edges"]}
+nodes = {n["url"]: n for n in g["nodes"]}
+assert nodes["/posts/first/"]["title"] == "First" and nodes["/posts/first/"]["word_count"] > 0
+assert nodes["/posts/second/"]["inbound"] == 1
+assert edges[("/posts/first/", "/posts/second/", "link")]["count"] == 2
+assert edges[("/", "/posts/gone/", "link")]["broken"]
+assert ("/posts/first/", "/posts/second/", "tag") in edges or ("/posts/second/", "/posts/first/", "tag") in edges
+PY
+then
+    echo "   ✗ graph.json is missing pages, links, broken links or tag edges"
+    graph_ok=false
+fi
+if ! grep -q '"/" -> "/posts/gone/" \[color=red' "$graph_site/graph.dot" 2>/dev/null; then
+    echo "   ✗ graph.dot does not mark the broken link"
+    graph_ok=false
+fi
+if [ -d "$graph_site/public" ]; then
+    echo "   ✗ Exporting the graph rendered the site"
+    graph_ok=false
+fi
+rm -rf "$graph_site"
+if $graph_ok; then
+    echo "   ✓ Pages, links, tag edges and broken links exported as JSON and DOT"
+fi
+
+# Test 17: Feed content sanitization
+echo ""
+echo "17. Testing feed content sanitization..."
+feed_site=$(mktemp -d)
+mkdir -p "$feed_site/content/posts/bundle" "$feed_site/layouts/_default" "$feed_site/static"
+go build -o "$feed_site/vango" main.go
+cat > "$feed_site/config.toml" <<'TOML'
+title = "Feeds"
+baseURL = "https://example.org/blog/"
+[sections.posts]
+outputs = ["html", "rss"]
+[feeds]
+fullContent = true
+replacement = '<p class="embed">[embed]</p>'
+TOML
+echo '{{ .Page.Content }}' > "$feed_site/layouts/_default/single.html"
+echo '{{ .Page.Content }}' > "$feed_site/layouts/_default/list.html"
+printf -- '---\ntitle: "Bundle"\ndate: 2024-01-01\n---\n![Photo](photo.png) and [about](../../about/)\n\n<iframe src="/player"></iframe>\n\n```html\n<iframe src="/player"></iframe>\n<img src="photo.png">\n```\n' > "$feed_site/content/posts/bundle/index.md"
+touch "$feed_site/content/posts/bundle/photo.png"
+(cd "$feed_site" && ./vango build -q >/dev/null 2>&1)
+feed_ok=true
+if ! python3 - "$feed_site/public" <<'PY'
+import re, sys, xml.etree.ElementTree as ET
+public = sys.argv[1]
+item = ET.parse(public + "/posts/feed.xml").find("channel/item")
+full = item.find("{http://purl.org/rss/1.0/modules/content/}encoded").text
+page = open(public + "/posts/bundle/index.html").read()
+code = re.compile(r"<pre>.*?</pre>", re.S)
+assert 'src="https://example.org/blog/posts/bundle/photo.png"' in full, "bundle image not absolute"
+assert 'href="https://example.org/blog/about/"' in full, "relative link not absolute"
+assert "<iframe" not in full and '<p class="embed">[embed]</p>' in full, "iframe not replaced"
+assert code.findall(full) == code.findall(page), "code block changed"
+PY
+then
+    echo "   ✗ Feed content not sanitized as configured"
+    feed_ok=false
+fi
+rm -rf "$feed_site"
+if $feed_ok; then
+    echo "   ✓ Bundle images and links made absolute, embeds replaced, code blocks intact"
+fi
+
+# Test 18: Configuration checks and fixes
+echo ""
+echo "18. Testing config validate checks and --fix..."
+check_site=$(mktemp -d)
+go build -o "$check_site/vango" main.go
+cat > "$check_site/config.toml" <<'TOML'
+title = "Checks"
+baseURL = "https://example.org/blog"
+theme = "missing-theme"
+TOML
+printf 'title = "Checks"\nbaseURL = "https://example.org/"\ntyop = 1\n' > "$check_site/warn.toml"
+check_ok=true
+(cd "$check_site" && ./vango config validate >"$check_site/out.log" 2>&1)
+status=$?
+if [ "$status" != 2 ] || ! grep -q 'theme "missing-theme" is not installed' "$check_site/out.log" || ! grep -q 'directory "content" does not exist' "$check_site/out.log"; then
+    echo "   ✗ Missing theme and directories not reported as errors (exit $status)"
+    check_ok=false
+fi
+(cd "$check_site" && ./vango config validate --fix >/dev/null 2>&1)
+if [ ! -d "$check_site/content" ] || [ ! -d "$check_site/layouts" ] || [ ! -d "$check_site/static" ] || [ ! -d "$check_site/data" ]; then
+    echo "   ✗ --fix did not create the missing directories"
+    check_ok=false
+fi
+if ! grep -q '^baseURL = "https://example.org/blog/"$' "$check_site/config.toml"; then
+    echo "   ✗ --fix did not add the baseURL trailing slash"
+    check_ok=false
+fi
+(cd "$check_site" && mkdir -p themes/missing-theme && ./vango config validate >/dev/null 2>&1)
+status=$?
+(cd "$check_site" && ./vango config validate -c warn.toml >/dev/null 2>&1)
+warn_status=$?
+(cd "$check_site" && ./vango config validate -c warn.toml --strict >/dev/null 2>&1)
+strict_status=$?
+if [ "$status" != 0 ] || [ "$warn_status" != 0 ] || [ "$strict_status" != 1 ]; then
+    echo "   ✗ Exit codes: clean $status, warnings $warn_status, warnings with --strict $strict_status; want 0, 0, 1"
+    check_ok=false
+fi
+rm -rf "$check_site"
+if $check_ok; then
+    echo "   ✓ Semantic checks reported by severity, --fix applied, exit codes 0/1/2"
+fi
+
+# Test 19: Bulk front matter edits
+echo ""
+echo "19. Testing content set and unset..."
+fm_site=$(mktemp -d)
+go build -o "$fm_site/vango" main.go
+mkdir -p "$fm_site/content/posts" "$fm_site/layouts"
+printf 'title = "Edits"\n' > "$fm_site/config.toml"
+cat > "$fm_site/content/posts/toml.md" <<'MD'
++++
+# Kept comment
+title = "TOML # post"
+tags = [
+  "a",
+  "b",
+]
+draft = true # still a draft
+[params]
+color = "red"
++++
+
+Body with +++ inside.
+MD
+cat > "$fm_site/content/posts/yaml.md" <<'MD'
+---
+title: YAML post
+# Kept comment
+tags:
+  - a
+  - b
+draft: true
+---
+Body.
+MD
+fm_ok=true
+cp "$fm_site/content/posts/toml.md" "$fm_site/before.md"
+(cd "$fm_site" && ./vango content set 'posts/*.md' draft=false --dry-run >"$fm_site/diff.txt" 2>/dev/null)
+if ! cmp -s "$fm_site/before.md" "$fm_site/content/posts/toml.md" || ! grep -q '^+draft = false # still a draft$' "$fm_site/diff.txt" || ! grep -q '^-draft: true$' "$fm_site/diff.txt"; then
+    echo "   ✗ --dry-run wrote files or printed no diff"
+    fm_ok=false
+fi
+(cd "$fm_site" && ./vango content set 'posts/*.md' categories=[archive] draft=false weight=3 >/dev/null 2>&1)
+(cd "$fm_site" && ./vango content unset content/posts/*.md tags >/dev/null 2>&1)
+if ! python3 - "$fm_site/content/posts" <<'PY'
+import sys, os, tomllib, yaml
+d = sys.argv[1]
+toml_text = open(os.path.join(d, "toml.md")).read()
+yaml_text = open(os.path.join(d, "yaml.md")).read()
+t = tomllib.loads(toml_text.split("+++\n")[1])
+y = yaml.safe_load(yaml_text.split("---\n")[1])
+want = {"categories": ["archive"], "draft": False, "weight": 3}
+for fm in (t, y):
+    assert {k: fm[k] for k in want} == want, fm
+    assert "tags" not in fm, fm
+assert t["params"] == {"color": "red"} and t["title"] == "TOML # post"
+assert toml_text.index("title") < toml_text.index("draft") < toml_text.index("categories")
+assert "# Kept comment" in toml_text and "# still a draft" in toml_text and "# Kept comment" in yaml_text
+assert toml_text.endswith("+++\n\nBody with +++ inside.\n") and yaml_text.endswith("---\nBody.\n")
+PY
+then
+    echo "   ✗ Edited front matter lost types, order, comments or body"
+    fm_ok=false
+fi
+if ls -a "$fm_site/content/posts" | grep -q '^\.vango-edit'; then
+    echo "   ✗ Temporary files left behind"
+    fm_ok=false
+fi
+rm -rf "$fm_site"
+if $fm_ok; then
+    echo "   ✓ Keys set and unset in TOML and YAML with types, order and comments kept"
+fi
+
+# Test 20: Analytics snippet
+echo ""
+echo "20. Testing analytics injection..."
+an_site=$(mktemp -d)
+go build -o "$an_site/vango" main.go
+mkdir -p "$an_site/content" "$an_site/layouts/_default"
+printf '<html><body>{{ .Page.Content }}</body></html>\n' > "$an_site/layouts/_default/single.html"
+printf '+++\ntitle = "Tracked"\n+++\nHello\n' > "$an_site/content/tracked.md"
+cat > "$an_site/config.toml" <<'TOML'
+title = "Analytics"
+baseURL = "https://example.org/"
+[analytics]
+provider = "custom"
+siteID = "site-42"
+template = '<script data-site="{{ .SiteID }}" src="/count.js"></script>'
+respectDoNotTrack = true
+TOML
+an_ok=true
+(cd "$an_site" && ./vango build >/dev/null 2>&1)
+if grep -q 'data-site' "$an_site/public/tracked/index.html"; then
+    echo "   ✗ Snippet injected outside production"
+    an_ok=false
+fi
+(cd "$an_site" && ./vango build -e production >/dev/null 2>&1)
+if ! grep -q '<template data-vango-analytics><script data-site="site-42" src="/count.js"></script></template>' "$an_site/public/tracked/index.html" || ! grep -q 'doNotTrack' "$an_site/public/tracked/index.html"; then
+    echo "   ✗ Custom snippet not injected with the Do Not Track guard in production"
+    an_ok=false
+fi
+printf '<html><body>{{ .Page.Content }}{{ analyticsSnippet }}</body></html>\n' > "$an_site/layouts/_default/single.html"
+(cd "$an_site" && ./vango build -e production >/dev/null 2>&1)
+if [ "$(grep -o 'data-site=' "$an_site/public/tracked/index.html" | wc -l)" != 1 ]; then
+    echo "   ✗ Snippet injected again on a page whose template calls analyticsSnippet"
+    an_ok=false
+fi
+rm -rf "$an_site"
+if $an_ok; then
+    echo "   ✓ Snippet only in production, guarded by Do Not Track, not duplicated"
+fi
+
+# Test 21: Base template blocks per page kind
+echo ""
+echo "21. Testing baseof blocks for single and list pages..."
+base_site=$(mktemp -d)
+go build -o "$base_site/vango" main.go
+mkdir -p "$base_site/content/posts" "$base_site/themes/blocks/layouts/_default" "$base_site/themes/blocks/layouts/section" "$base_site/layouts"
+printf 'title = "Blocks"\nbaseURL = "https://example.org/"\ntheme = "blocks"\n' > "$base_site/config.toml"
+printf '{"name": "blocks", "layouts_dir": "layouts", "static_dir": "static"}\n' > "$base_site/themes/blocks/theme.json"
+printf '<html><head><title>{{ block "title" . }}BASE-TITLE{{ end }}</title></head><body>{{ block "main" . }}{{ end }}</body></html>\n' > "$base_site/themes/blocks/layouts/_default/baseof.html"
+printf '{{ define "title" }}SINGLE-TITLE{{ end }}{{ define "main" }}SINGLE-BODY {{ .Page.Title }}{{ end }}\n' > "$base_site/themes/blocks/layouts/_default/single.html"
+printf '{{ define "main" }}LIST-BODY{{ end }}\n' > "$base_site/themes/blocks/layouts/_default/list.html"
+printf '{{ define "main" }}SECTION-BODY{{ end }}\n' > "$base_site/themes/blocks/layouts/section/posts.html"
+printf '+++\ntitle = "Home"\n+++\n' > "$base_site/content/_index.md"
+printf '+++\ntitle = "Posts"\n+++\n' > "$base_site/content/posts/_index.md"
+printf '+++\ntitle = "First"\n+++\nHello\n' > "$base_site/content/posts/first.md"
+(cd "$base_site" && ./vango build >/dev/null 2>&1)
+base_ok=true
+if ! grep -q 'SINGLE-TITLE.*SINGLE-BODY First' "$base_site/public/posts/first/index.html"; then
+    echo "   ✗ Post not rendered with the single template's blocks"
+    base_ok=false
+fi
+if ! grep -q 'BASE-TITLE.*LIST-BODY' "$base_site/public/index.html"; then
+    echo "   ✗ Home page not rendered with the list template's blocks and the base title"
+    base_ok=false
+fi
+if ! grep -q 'SECTION-BODY' "$base_site/public/posts/index.html"; then
+    echo "   ✗ Section page not rendered with its section template's blocks"
+    base_ok=false
+fi
+rm -rf "$base_site"
+if $base_ok; then
+    echo "   ✓ Single, list and section blocks each fill in baseof for their own pages"
+fi
+
+# Test 22: Build progress output
+echo ""
+echo "22. Testing build progress output..."
+prog_site=$(mktemp -d)
+go build -o "$prog_site/vango" main.go
+mkdir -p "$prog_site/content" "$prog_site/layouts/_default"
+printf '{{ .Page.Content }}\n' > "$prog_site/layouts/_default/single.html"
+printf 'title = "Progress"\nbaseURL = "https://example.org/"\n' > "$prog_site/config.toml"
+for i in 1 2 3; do printf '+++\ntitle = "Page %s"\n+++\nHi\n' "$i" > "$prog_site/content/page$i.md"; done
+prog_ok=true
+(cd "$prog_site" && ./vango build >"$prog_site/pipe.log" 2>&1)
+if grep -q $'\033\[K' "$prog_site/pipe.log" || grep -q 'Build summary' "$prog_site/pipe.log"; then
+    echo "   ✗ Progress bar drawn when not writing to a terminal"
+    prog_ok=false
+fi
+if command -v script >/dev/null 2>&1; then
+    (cd "$prog_site" && script -qc "./vango build" /dev/null >"$prog_site/tty.log" 2>&1)
+    if ! grep -q 'Build summary' "$prog_site/tty.log" || ! grep -q '\[█' "$prog_site/tty.log"; then
+        echo "   ✗ No progress bar or summary on a terminal"
+        prog_ok=false
+    fi
+    (cd "$prog_site" && script -qc "./vango build --no-progress" /dev/null >"$prog_site/tty.log" 2>&1)
+    if grep -q 'Build summary' "$prog_site/tty.log"; then
+        echo "   ✗ --no-progress still drew the progress bar"
+        prog_ok=false
+    fi
+fi
+rm -rf "$prog_site"
+if $prog_ok; then
+    echo "   ✓ Progress bar and summary only on a terminal"
+fi
+
+echo ""
+echo "23. Testing a site without a theme or layouts..."
+bare_site=$(mktemp -d)
+go build -o "$bare_site/vango" main.go
+mkdir -p "$bare_site/content/posts" "$bare_site/layouts" "$bare_site/static"
+printf 'title = "Bare"\nbaseURL = "https://example.org/"\n' > "$bare_site/config.toml"
+printf '+++\ntitle = "Welcome"\n+++\nIntro\n' > "$bare_site/content/_index.md"
+printf '+++\ntitle = "Hello"\n+++\nHello **world**\n' > "$bare_site/content/posts/hello.md"
+if (cd "$bare_site" && ./vango build >/dev/null 2>&1) \
+    && grep -q '<strong>world</strong>' "$bare_site/public/posts/hello/index.html" \
+    && grep -q 'name="generator"' "$bare_site/public/posts/hello/index.html" \
+    && grep -q 'href="/posts/hello/"' "$bare_site/public/index.html"; then
+    echo "   ✓ Built-in templates render pages without a theme"
+else
+    echo "   ✗ Site without a theme or layouts did not render"
+fi
+rm -rf "$bare_site"
+
+echo ""
+echo "24. Testing static files at the site root..."
+root_site=$(mktemp -d)
+go build -o "$root_site/vango" main.go
+mkdir -p "$root_site/content" "$root_site/layouts/_default" "$root_site/static/img"
+printf '{{ .Page.Content }}\n' > "$root_site/layouts/_default/single.html"
+printf 'title = "Root"\nbaseURL = "https://example.org/"\n' > "$root_site/config.toml"
+printf '+++\ntitle = "About"\n+++\nHi\n' > "$root_site/content/about.md"
+echo "icon" > "$root_site/static/favicon.ico"
+echo "image" > "$root_site/static/img/logo.png"
+root_ok=true
+(cd "$root_site" && ./vango build >/dev/null 2>&1)
+if [ ! -f "$root_site/public/favicon.ico" ] || [ ! -f "$root_site/public/static/img/logo.png" ]; then
+    echo "   ✗ rootFiles not published at the root"
+    root_ok=false
+fi
+printf 'staticAtRoot = true\n' >> "$root_site/config.toml"
+(cd "$root_site" && ./vango build >/dev/null 2>&1)
+if [ ! -f "$root_site/public/img/logo.png" ] || [ -e "$root_site/public/static" ]; then
+    echo "   ✗ staticAtRoot did not publish static files at the root"
+    root_ok=false
+fi
+mkdir -p "$root_site/static/about"
+echo "clash" > "$root_site/static/about/index.html"
+if (cd "$root_site" && ./vango build >/dev/null 2>&1); then
+    echo "   ✗ Static file overwriting a page did not fail the build"
+    root_ok=false
+fi
+rm -rf "$root_site"
+if $root_ok; then
+    echo "   ✓ Static files published at the site root"
+fi
+
+echo ""
+echo "25. Testing HTML validation..."
+html_site=$(mktemp -d)
+go build -o "$html_site/vango" main.go
+mkdir -p "$html_site/content" "$html_site/layouts/_default"
+printf '<html lang="en"><body><main>{{ .Page.Content }}</main></body></html>\n' > "$html_site/layouts/_default/single.html"
+printf 'title = "HTML"\nbaseURL = "https://example.org/"\n[htmlValidation]\nenable = true\n' > "$html_site/config.toml"
+printf '+++\ntitle = "Broken"\n+++\n## Setup\n\n<div class="note">\n\nNever closed\n' > "$html_site/content/broken.md"
+printf '+++\ntitle = "Fine"\n+++\n<ul><li>one<li>two</ul>\n' > "$html_site/content/fine.md"
+html_log=$(cd "$html_site" && ./vango build 2>&1)
+if echo "$html_log" | grep -q '/broken/ line .*(under \\"Setup\\"): <div> is not closed' \
+    && ! echo "$html_log" | grep -q 'HTML: /fine/'; then
+    echo "   ✓ Unclosed elements reported with the page and heading"
+else
+    echo "   ✗ HTML validation did not report the unclosed div"
+fi
+rm -rf "$html_site"
+
+echo ""
+echo "26. Testing the content schedule..."
+sched_site=$(mktemp -d)
+go build -o "$sched_site/vango" main.go
+mkdir -p "$sched_site/content" "$sched_site/layouts/_default"
+printf '{{ .Page.Content }}\n' > "$sched_site/layouts/_default/single.html"
+printf 'title = "Schedule"\nbaseURL = "https://example.org/"\n[metrics]\nenable = true\nnextPublishAt = true\n' > "$sched_site/config.toml"
+printf '+++\ntitle = "Soon"\ndate = "2099-01-02T09:00:00Z"\n+++\nSoon\n' > "$sched_site/content/soon.md"
+printf '+++\ntitle = "Old draft"\ndate = "2001-01-01"\ndraft = true\n+++\nDraft\n' > "$sched_site/content/draft.md"
+printf '+++\ntitle = "Live"\n+++\nLive\n' > "$sched_site/content/live.md"
+sched_json=$(cd "$sched_site" && ./vango schedule --format json 2>/dev/null)
+(cd "$sched_site" && ./vango build >/dev/null 2>&1)
+if echo "$sched_json" | grep -q '"next_publish_at": "2099-01-02T09:00:00Z"' \
+    && echo "$sched_json" | grep -q '"file": "content/draft.md"' \
+    && grep -q '"next_publish_at": "2099-01-02T09:00:00Z"' "$sched_site/public/metrics.json"; then
+    echo "   ✓ Scheduled pages and stale drafts listed, next publish time in metrics"
+else
+    echo "   ✗ Content schedule missing pages or next publish time"
+fi
+rm -rf "$sched_site"
+
+echo ""
+echo "27. Testing menus and breadcrumbs..."
+crumb_site=$(mktemp -d)
+go build -o "$crumb_site/vango" main.go
+mkdir -p "$crumb_site/content/docs/guides" "$crumb_site/layouts"
+printf 'title = "Crumbs"\nbaseURL = "https://example.org/"\n[[menus.main]]\nname = "Docs"\nurl = "/docs/"\n[[menus.main.children]]\nname = "Install"\nurl = "/docs/guides/install/"\n' > "$crumb_site/config.toml"
+printf -- '---\ntitle: Documentation\n---\nDocs\n' > "$crumb_site/content/docs/_index.md"
+printf -- '---\ntitle: Install\n---\nInstall\n' > "$crumb_site/content/docs/guides/install.md"
+(cd "$crumb_site" && ./vango build >/dev/null 2>&1)
+crumb_page="$crumb_site/public/docs/guides/install/index.html"
+if grep -q '<a href="/docs/">Documentation</a>' "$crumb_page" \
+    && grep -q '<li>Guides</li>' "$crumb_page" \
+    && grep -q 'href="/docs/guides/install/" aria-current="page">Install</a>' "$crumb_page" \
+    && grep -q '"@type":"BreadcrumbList"' "$crumb_page"; then
+    echo "   ✓ Nested menu, breadcrumbs and BreadcrumbList rendered"
+else
+    echo "   ✗ Menus or breadcrumbs missing from the page"
+fi
+rm -rf "$crumb_site"
+
+echo ""
+echo "28. Testing code block attributes..."
+code_site=$(mktemp -d)
+go build -o "$code_site/vango" main.go
+mkdir -p "$code_site/content" "$code_site/layouts/_default"
+printf '<html><body>{{ .Page.Content }}</body></html>\n' > "$code_site/layouts/_default/single.html"
+printf 'title = "Code"\nbaseURL = "https://example.org/"\n' > "$code_site/config.toml"
+printf -- '---\ntitle: Code\n---\n```go {filename="main.go" hl_lines="2-3"}\npackage main\n```\n\n```go\nplain\n```\n' > "$code_site/content/code.md"
+(cd "$code_site" && ./vango build >/dev/null 2>&1)
+code_page="$code_site/public/code/index.html"
+if grep -q '<figure class="code-block" data-lang="go" data-filename="main.go" data-hl-lines="2,3">' "$code_page" \
+    && grep -q '<figcaption class="code-filename">main.go</figcaption>' "$code_page" \
+    && grep -q '^<pre><code class="language-go">plain' "$code_page"; then
+    echo "   ✓ Fence attributes rendered, plain fences unchanged"
+else
+    echo "   ✗ Code block attributes not rendered"
+fi
+rm -rf "$code_site"
+
+echo ""
+echo "29. Testing front matter templates..."
+fmt_site=$(mktemp -d)
+go build -o "$fmt_site/vango" main.go
+mkdir -p "$fmt_site/content" "$fmt_site/layouts/_default"
+printf '{{ .Page.Description }}|{{ range .Page.Tags }}{{ . }},{{ end }}\n' > "$fmt_site/layouts/_default/single.html"
+printf 'title = "Docs"\nbaseURL = "https://example.org/"\nfrontMatterTemplates = true\n[params]\nversion = "2.1"\n' > "$fmt_site/config.toml"
+printf '+++\ntitle = "Install"\ndescription = "Docs for {{ .Site.Title }} v{{ .Site.Params.version }}"\ntags = ["{{ lower .Site.Title }}"]\n+++\nBody\n' > "$fmt_site/content/install.md"
+(cd "$fmt_site" && ./vango build >/dev/null 2>&1)
+printf -- '---\ntitle: Bad\ndescription: "{{ .Site.Params.missing }}"\n---\nBody\n' > "$fmt_site/content/bad.md"
+fmt_log=$(cd "$fmt_site" && ./vango build 2>&1)
+if grep -q '^Docs for Docs v2.1|docs,$' "$fmt_site/public/install/index.html" \
+    && echo "$fmt_log" | grep -q 'content/bad.md: front matter description'; then
+    echo "   ✓ Front matter evaluated with the site config, errors name file and key"
+else
+    echo "   ✗ Front matter templates not evaluated or errors unclear"
+fi
+rm -rf "$fmt_site"
+
+echo ""
+echo "30. Testing derived titles..."
+title_site=$(mktemp -d)
+go build -o "$title_site/vango" main.go
+mkdir -p "$title_site/content" "$title_site/layouts/_default"
+printf '{{ .Page.Title }}|{{ .Page.Params.titleSource }}\n' > "$title_site/layouts/_default/single.html"
+printf 'title = "Titles"\nbaseURL = "https://example.org/"\ntitleCaseStyle = "chicago"\n' > "$title_site/config.toml"
+printf -- '---\ndate: 2024-01-01\n---\n# Release *Notes*\n\nText\n' > "$title_site/content/notes.md"
+printf -- '---\ndate: 2024-01-01\n---\nText\n' > "$title_site/content/state-of-the-art.md"
+(cd "$title_site" && ./vango build >/dev/null 2>&1)
+title_log=$(cd "$title_site" && ./vango validate 2>&1)
+if grep -q '^Release Notes|heading$' "$title_site/public/notes/index.html" \
+    && grep -q '^State of the Art|filename$' "$title_site/public/state-of-the-art/index.html" \
+    && echo "$title_log" | grep -q 'state-of-the-art.md: no title in front matter'; then
+    echo "   ✓ Titles derived from the first heading, then the file name, with warnings"
+else
+    echo "   ✗ Derived titles wrong or untitled pages not reported"
+fi
+rm -rf "$title_site"
+
+echo ""
+echo "31. Testing version output..."
+version_json=$(go run -ldflags "-X vango/internal/version.Commit=abc1234" main.go version --format json 2>/dev/null)
+version_skip=$(VANGO_NO_UPDATE_CHECK=1 go run main.go version --check-update 2>&1)
+if echo "$version_json" | grep -q '"commit": "abc1234"' \
+    && echo "$version_json" | grep -q '"go_version": "go' \
+    && echo "$version_skip" | grep -q 'Update check skipped'; then
+    echo "   ✓ Version reports build metadata and honours VANGO_NO_UPDATE_CHECK"
+else
+    echo "   ✗ Version metadata missing or update check not skipped"
+fi
+
+echo ""
+echo "32. Testing front matter delimiters..."
+fm_site=$(mktemp -d)
+go build -o "$fm_site/vango" main.go
+mkdir -p "$fm_site/content" "$fm_site/layouts/_default"
+printf '{{ .Page.Title }}|{{ .Page.Content }}\n' > "$fm_site/layouts/_default/single.html"
+printf 'title = "Delimiters"\nbaseURL = "https://example.org/"\n' > "$fm_site/config.toml"
+printf -- '---\n\nIntro\n\n---\n\nOutro\n' > "$fm_site/content/rule.md"
+printf -- '---\n---\n# Empty\n' > "$fm_site/content/empty.md"
+printf -- '+++\ntitle = "Later"\n+++\nA\n\n+++\n\nB\n' > "$fm_site/content/later.md"
+(cd "$fm_site" && ./vango build >/dev/null 2>&1)
+ok=true
+grep -q 'Intro' "$fm_site/public/rule/index.html" && grep -q 'Outro' "$fm_site/public/rule/index.html" || ok=false
+grep -q '^Empty|' "$fm_site/public/empty/index.html" || ok=false
+grep -q '^Later|' "$fm_site/public/later/index.html" && grep -q '+++' "$fm_site/public/later/index.html" || ok=false
+printf -- '---\ntitle: Open\n\nNever closed\n' > "$fm_site/content/open.md"
+fm_log=$(cd "$fm_site" && ./vango build 2>&1)
+echo "$fm_log" | grep -q 'open.md: unclosed front matter starting at line 1' || ok=false
+if $ok; then
+    echo "   ✓ Horizontal rules, empty and unclosed front matter handled"
+else
+    echo "   ✗ Front matter delimiters mishandled"
+fi
+rm -rf "$fm_site"
+
+echo ""
+echo "33. Testing very long content lines..."
+long_site=$(mktemp -d)
+go build -o "$long_site/vango" main.go
+mkdir -p "$long_site/content" "$long_site/layouts/_default"
+printf '{{ .Page.Title }}|{{ len .Page.Content }}\n' > "$long_site/layouts/_default/single.html"
+printf 'title = "Long"\nbaseURL = "https://example.org/"\n' > "$long_site/config.toml"
+{ printf -- '---\ntitle: Long\n---\n'; head -c 5000000 /dev/zero | tr '\0' 'x'; echo; } > "$long_site/content/long.md"
+(cd "$long_site" && ./vango build >/dev/null 2>&1)
+if grep -q '^Long|5000008$' "$long_site/public/long/index.html"; then
+    echo "   ✓ A 5MB single-line page parses in full"
+else
+    echo "   ✗ Long content line failed to parse or was truncated"
+fi
+rm -rf "$long_site"
+
+echo ""
+echo "34. Testing keywords and noindex pages..."
+robots_site=$(mktemp -d)
+go build -o "$robots_site/vango" main.go
+mkdir -p "$robots_site/content/blog" "$robots_site/layouts/_default"
+printf '<html lang="en"><head>{{ metaKeywords .Page }}{{ with .Page.Robots }}<meta name="robots" content="{{ . }}">{{ end }}{{ if .Page.Params.hide }}<meta name="robots" content="noindex">{{ end }}</head><body>{{ .Page.Content }}</body></html>\n' > "$robots_site/layouts/_default/single.html"
+cp "$robots_site/layouts/_default/single.html" "$robots_site/layouts/_default/list.html"
+printf 'title = "Robots"\nbaseURL = "https://example.org/"\n[sections.blog]\noutputs = ["html", "rss"]\n' > "$robots_site/config.toml"
+printf -- '---\ntitle: Public\ndate: "2024-01-01"\nkeywords: [go, sites]\n---\nText\n' > "$robots_site/content/blog/public.md"
+printf -- '---\ntitle: Private\ndate: "2024-01-02"\nprivate: true\n---\nText\n' > "$robots_site/content/blog/private.md"
+printf -- '---\ntitle: Hidden\ndate: "2024-01-03"\nparams:\n  hide: true\n---\nText\n' > "$robots_site/content/blog/hidden.md"
+(cd "$robots_site" && ./vango build >/dev/null 2>&1)
+robots_log=$(cd "$robots_site" && ./vango validate 2>&1)
+if grep -q '<meta name="keywords" content="go, sites">' "$robots_site/public/blog/public/index.html" \
+    && grep -q '<meta name="robots" content="noindex, nofollow">' "$robots_site/public/blog/private/index.html" \
+    && ! grep -q 'blog/private/' "$robots_site/public/sitemap.xml" "$robots_site/public/blog/feed.xml" \
+    && echo "$robots_log" | grep -q 'blog/hidden/: listed in sitemap.xml but marked noindex'; then
+    echo "   ✓ Keywords and robots meta tags, noindex pages kept out of sitemap and feeds"
+else
+    echo "   ✗ Keywords, robots or noindex handling wrong"
+fi
+rm -rf "$robots_site"
+
+echo ""
+echo "35. Testing a parallel build under the race detector..."
+race_site=$(mktemp -d)
+if go build -race -o "$race_site/vango" main.go 2>/dev/null; then
+    mkdir -p "$race_site/content/docs/guide" "$race_site/content/blog" "$race_site/layouts/_default"
+    printf '{{ .Page.Title }} {{ range breadcrumbs .Page }}{{ .Name }}/{{ end }}{{ with .Page.PrevInSeries }}{{ .Title }}{{ end }}{{ range .Site.Sections.docs.Tree.Children }}{{ .Title }}{{ end }}{{ range .Site.Menus.main }}{{ .Name }}{{ end }}{{ jsonLD .Page }}{{ metaKeywords .Page }}{{ openGraph .Page }}{{ .Page.Content }}\n' > "$race_site/layouts/_default/single.html"
+    printf '{{ .Page.Title }} {{ range .Pages }}{{ .Title }} {{ .Permalink }}{{ end }}{{ range breadcrumbs .Page }}{{ .Name }}{{ end }}\n' > "$race_site/layouts/_default/list.html"
+    printf 'title = "Race"\nbaseURL = "https://example.org/"\nfrontMatterTemplates = true\nworkers = 8\n[params]\nversion = "1.0"\n[sections.blog]\noutputs = ["html", "rss"]\n[taxonomyOptions]\nrss = true\nsitemap = true\n[[menus.main]]\nname = "Docs"\nurl = "/docs/"\n[social.openGraph]\nenable = true\n' > "$race_site/config.toml"
+    printf -- '---\ntitle: Docs\n---\nDocs\n' > "$race_site/content/docs/_index.md"
+    printf -- '---\ntitle: Guide\n---\nGuide\n' > "$race_site/content/docs/guide/_index.md"
+    for i in $(seq 1 30); do
+        printf -- '---\ntitle: "Post %s v{{ .Site.Params.version }}"\ndate: "2024-01-%02d"\ntags: [a, b%s]\nseries: [s%s]\nkeywords: [k]\n---\nSee {{< ref "docs/guide/g1.md" >}} here.\n\n```go {filename="x.go"}\nx\n```\n' $i $(( (i % 28) + 1 )) $((i % 5)) $((i % 4)) > "$race_site/content/blog/p$i.md"
+        printf -- '---\ndate: "2024-02-%02d"\ntags: [a]\n---\n# Guide Page %s\n\nText\n' $(( (i % 28) + 1 )) $i > "$race_site/content/docs/guide/g$i.md"
+    done
+    race_log=$(cd "$race_site" && ./vango build 2>&1)
+    if echo "$race_log" | grep -q 'Generated' && ! echo "$race_log" | grep -q 'DATA RACE'; then
+        echo "   ✓ Full build with every enrichment feature is race-free"
+    else
+        echo "   ✗ Race detected or build failed"
+    fi
+else
+    echo "   - Race detector unavailable, skipped"
+fi
+rm -rf "$race_site"
+
+echo ""
+echo "36. Testing build failure notifications and the build log..."
+notify_site=$(mktemp -d)
+go build -o "$notify_site/vango" main.go
+mkdir -p "$notify_site/content" "$notify_site/layouts/_default"
+printf '{{ .Page.Content }}\n' > "$notify_site/layouts/_default/single.html"
+cp "$notify_site/layouts/_default/single.html" "$notify_site/layouts/_default/list.html"
+printf '#!/bin/sh\necho "$1|$2" >> "%s/notified"\n' "$notify_site" > "$notify_site/notify.sh"
+chmod +x "$notify_site/notify.sh"
+printf 'title = "Notify"\nnotifyCommand = "%s/notify.sh"\n' "$notify_site" > "$notify_site/config.toml"
+printf -- '---\ntitle: A\n---\nA\n' > "$notify_site/content/a.md"
+notify_port=$((20000 + RANDOM % 10000))
+(cd "$notify_site" && exec ./vango serve -p "$notify_port" --notify >serve.log 2>&1) &
+notify_pid=$!
+sleep 3
+printf '{{ .Page.Content \n' > "$notify_site/layouts/_default/single.html"
+sleep 3
+printf '{{ .Page.Content }}\n' > "$notify_site/layouts/_default/single.html"
+sleep 3
+notify_log=$(curl -s -D - "http://localhost:$notify_port/api/build-log")
+kill "$notify_pid" 2>/dev/null
+wait "$notify_pid" 2>/dev/null
+if grep -q 'VanGo build failed|.*unclosed action' "$notify_site/notified" 2>/dev/null \
+    && grep -q 'VanGo build fixed|' "$notify_site/notified" \
+    && echo "$notify_log" | grep -q 'Content-Disposition: attachment; filename="vango-build-log-' \
+    && echo "$notify_log" | grep -q '"build_errors":\[{"time":"[^"]*","message":"[^"]*unclosed action[^"]*","files":\["layouts/_default/single.html"\]'; then
+    echo "   ✓ Failed and recovered builds notify, build log lists timestamped errors"
+else
+    echo "   ✗ Build notifications or build log wrong"
+fi
+rm -rf "$notify_site"
+
+echo ""
+echo "37. Testing conflicting URL detection..."
+urls_site=$(mktemp -d)
+go build -o "$urls_site/vango" main.go
+mkdir -p "$urls_site/content/blog" "$urls_site/layouts/_default"
+printf '{{ .Page.Content }}\n' > "$urls_site/layouts/_default/single.html"
+cp "$urls_site/layouts/_default/single.html" "$urls_site/layouts/_default/list.html"
+printf 'title = "URLs"\n' > "$urls_site/config.toml"
+printf -- '---\ntitle: About\n---\nA\n' > "$urls_site/content/About.md"
+printf -- '---\ntitle: about\n---\na\n' > "$urls_site/content/about.md"
+printf -- '---\ntitle: Blog\naliases: [/about/]\n---\nB\n' > "$urls_site/content/blog.md"
+printf -- '---\ntitle: Blog index\n---\nB\n' > "$urls_site/content/blog/_index.md"
+urls_build=$(cd "$urls_site" && ./vango build 2>&1)
+urls_validate=$(cd "$urls_site" && ./vango validate --skip-a11y 2>&1)
+urls_built=$(ls "$urls_site/public" 2>/dev/null)
+rm "$urls_site/content/blog.md"
+printf 'caseCollisionLevel = "warning"\n' >> "$urls_site/config.toml"
+urls_warn=$(cd "$urls_site" && ./vango build 2>&1)
+if echo "$urls_build" | grep -q 'content/blog.md and content/blog/_index.md publish the same URL /blog/' \
+    && echo "$urls_build" | grep -q 'content/About.md and content/about.md publish /About/ and /about/' \
+    && echo "$urls_build" | grep -q 'alias /about/ in content/blog.md is also the URL of content/about.md' \
+    && [ -z "$urls_built" ] \
+    && echo "$urls_validate" | grep -q 'publish the same URL /blog/' \
+    && echo "$urls_warn" | grep -q 'differ only in case' && [ -e "$urls_site/public/blog/index.html" ]; then
+    echo "   ✓ Same URLs, case collisions and alias overlaps reported by build and validate"
+else
+    echo "   ✗ Conflicting URLs not reported"
+fi
+rm -rf "$urls_site"
+
+echo ""
+echo "38. Testing incremental builds by changed directory..."
+inc_site=$(mktemp -d)
+go build -o "$inc_site/vango" main.go
+mkdir -p "$inc_site/content" "$inc_site/layouts" "$inc_site/static" "$inc_site/data" "$inc_site/assets"
+printf 'title = "Incremental"\ntheme = "t"\n' > "$inc_site/config.toml"
+(cd "$inc_site" && ./vango theme create t -t basic -q >/dev/null 2>&1)
+printf -- '---\ntitle: A\n---\nA\n' > "$inc_site/content/a.md"
+printf 'one\n' > "$inc_site/static/page.html"
+inc_port=$((20000 + RANDOM % 10000))
+(cd "$inc_site" && exec ./vango serve -v -p "$inc_port" >serve.log 2>&1) &
+inc_pid=$!
+sleep 3
+printf 'two\n' > "$inc_site/static/page.html"
+sleep 2
+printf 'console.log(1)\n' > "$inc_site/themes/t/static/js/app.js"
+sleep 2
+printf '{{ .Page.Title }} themed\n' > "$inc_site/themes/t/layouts/_default/single.html"
+sleep 2
+printf '{"a": 1}\n' > "$inc_site/data/site.json"
+sleep 2
+printf 'notes\n' > "$inc_site/assets/notes.txt"
+sleep 2
+kill "$inc_pid" 2>/dev/null
+wait "$inc_pid" 2>/dev/null
+inc_log=$(grep -v 'GET ' "$inc_site/serve.log")
+if grep -q two "$inc_site/public/static/page.html" \
+    && [ -f "$inc_site/public/theme/js/app.js" ] \
+    && grep -q 'A themed' "$inc_site/public/a/index.html" \
+    && echo "$inc_log" | grep -q 'Reparsed 1 template files' \
+    && echo "$inc_log" | grep -A3 'Files changed: data/site.json' | grep -q 'Generated: public/a/index.html' \
+    && echo "$inc_log" | grep -q 'Ignoring change to assets/notes.txt' \
+    && [ "$(echo "$inc_log" | grep -c 'Building site with')" = 1 ]; then
+    echo "   ✓ Static, theme static, theme layout, data and unknown changes handled without full rebuilds"
+else
+    echo "   ✗ Incremental build misclassified a change"
+fi
+rm -rf "$inc_site"
+
+echo ""
+echo "39. Testing .markdown, .mdown and mixed-case content extensions..."
+ext_site=$(mktemp -d)
+go build -o "$ext_site/vango" main.go
+mkdir -p "$ext_site/content/trip" "$ext_site/layouts/_default"
+printf '{{ .Page.Title }}: {{ .Page.Content }}{{ range .Page.Resources }}[{{ .Name }}]{{ end }}\n' > "$ext_site/layouts/_default/single.html"
+cp "$ext_site/layouts/_default/single.html" "$ext_site/layouts/_default/list.html"
+printf 'title = "Extensions"\n' > "$ext_site/config.toml"
+printf -- '---\ntitle: Upper\n---\nupper one\n' > "$ext_site/content/POST.MD"
+printf -- '---\ntitle: Jekyll\n---\njekyll one\n' > "$ext_site/content/old.markdown"
+printf -- '---\ntitle: Mdown\n---\nmdown\n' > "$ext_site/content/notes.mdown"
+printf -- '---\ntitle: Trip\n---\ntrip\n' > "$ext_site/content/trip/index.Markdown"
+printf 'photo' > "$ext_site/content/trip/photo.jpg"
+(cd "$ext_site" && ./vango build >/dev/null 2>&1)
+ext_built=$(cat "$ext_site/public/POST/index.html" "$ext_site/public/old/index.html" "$ext_site/public/notes/index.html" "$ext_site/public/trip/index.html" 2>/dev/null)
+ext_port=$((20000 + RANDOM % 10000))
+(cd "$ext_site" && exec ./vango serve -p "$ext_port" >serve.log 2>&1) &
+ext_pid=$!
+sleep 3
+printf -- '---\ntitle: Upper\n---\nupper two\n' > "$ext_site/content/POST.MD"
+printf -- '---\ntitle: Jekyll\n---\njekyll two\n' > "$ext_site/content/old.markdown"
+sleep 2
+kill "$ext_pid" 2>/dev/null
+wait "$ext_pid" 2>/dev/null
+ext_rebuilt=$(cat "$ext_site/public/POST/index.html" "$ext_site/public/old/index.html" 2>/dev/null)
+printf 'contentExtensions = [".md"]\n' >> "$ext_site/config.toml"
+rm -rf "$ext_site/public"
+(cd "$ext_site" && ./vango build >/dev/null 2>&1)
+if echo "$ext_built" | grep -q 'Upper: <p>upper one' \
+    && echo "$ext_built" | grep -q 'Jekyll: <p>jekyll one' \
+    && echo "$ext_built" | grep -q 'Mdown: <p>mdown' \
+    && echo "$ext_built" | grep -q 'Trip: <p>trip' && echo "$ext_built" | grep -q '\[photo.jpg\]' \
+    && echo "$ext_rebuilt" | grep -q 'upper two' && echo "$ext_rebuilt" | grep -q 'jekyll two' \
+    && grep -q 'Incremental build completed' "$ext_site/serve.log" \
+    && ! grep -q 'Full rebuild' "$ext_site/serve.log" \
+    && [ -d "$ext_site/public/POST" ] && [ ! -e "$ext_site/public/old" ]; then
+    echo "   ✓ Content extensions match ignoring case, on full and incremental builds"
+else
+    echo "   ✗ Content extensions not matched consistently"
+fi
+rm -rf "$ext_site"
+
+echo ""
+echo "40. Testing localized reading time text..."
+rt_site=$(mktemp -d)
+go build -o "$rt_site/vango" main.go
+mkdir -p "$rt_site/content" "$rt_site/layouts/_default" "$rt_site/i18n"
+printf '[{{ readingTimeText .Page }}|{{ .Page.ReadingTime }}]\n' > "$rt_site/layouts/_default/single.html"
+cp "$rt_site/layouts/_default/single.html" "$rt_site/layouts/_default/list.html"
+printf 'title = "Reading"\n[readingTime]\nunderMinute = 30\nrounding = "range"\n' > "$rt_site/config.toml"
+printf '[readingTime]\none = "{{ .Count }} Minute"\nother = "{{ .Count }} Minuten"\n' > "$rt_site/i18n/de.toml"
+printf -- '---\ntitle: Short\n---\nA few words.\n' > "$rt_site/content/short.md"
+printf -- '---\ntitle: Long\n---\n%s\n' "$(yes word | head -500 | tr '\n' ' ')" > "$rt_site/content/long.md"
+printf -- '---\ntitle: Un\nlanguage: fr\n---\n%s\n' "$(yes mot | head -150 | tr '\n' ' ')" > "$rt_site/content/un.md"
+printf -- '---\ntitle: Eins\nlanguage: de\n---\n%s\n' "$(yes Wort | head -400 | tr '\n' ' ')" > "$rt_site/content/eins.md"
+(cd "$rt_site" && ./vango build >/dev/null 2>&1)
+if grep -q '\[Less than a minute|1\]' "$rt_site/public/short/index.html" \
+    && grep -q '\[2–3 min read|3\]' "$rt_site/public/long/index.html" \
+    && grep -q '\[1 minute de lecture|1\]' "$rt_site/public/un/index.html" \
+    && grep -q '\[2 Minuten|2\]' "$rt_site/public/eins/index.html"; then
+    echo "   ✓ Reading time text pluralized, localized, ranged and under a minute"
+else
+    echo "   ✗ Reading time text wrong"
+fi
+rm -rf "$rt_site"
+
+echo ""
+echo "41. Testing baseURL validation and normalization..."
+url_site=$(mktemp -d)
+go build -o "$url_site/vango" main.go
+mkdir -p "$url_site/content" "$url_site/layouts"
+urls_ok=true
+check_base_url() {
+    local input=$1 expected=$2 output
+    printf 'title = "URLs"\nbaseURL = "%s"\n' "$input" > "$url_site/config.toml"
+    output=$(cd "$url_site" && ./vango tpl exec '{{ .Site.BaseURL }}' 2>&1 | tail -1)
+    if [ -n "$expected" ] && [ "$output" != "$expected" ]; then
+        echo "   ✗ $input: got \"$output\", want \"$expected\""
+        urls_ok=false
+    elif [ -z "$expected" ] && ! echo "$output" | grep -q 'invalid baseURL.*parsed scheme'; then
+        echo "   ✗ $input accepted: $output"
+        urls_ok=false
+    fi
+}
+check_base_url "http://192.168.1.10:8080/" "http://192.168.1.10:8080/"
+check_base_url "https://example.com:8443/blog" "https://example.com:8443/blog/"
+check_base_url "http://localhost:3000" "http://localhost:3000/"
+check_base_url "http://[::1]:1313/" "http://[::1]:1313/"
+check_base_url "HTTPS://Example.COM" "https://example.com/"
+check_base_url "https://bücher.example/docs/" "https://xn--bcher-kva.example/docs/"
+check_base_url "https://例え.テスト" "https://xn--r8jz45g.xn--zckzah/"
+check_base_url "ftp://example.com/" ""
+check_base_url "example.com" ""
+check_base_url "http://" ""
+check_base_url "https://example.com/?page=1" ""
+check_base_url "http://a..b/" ""
+if $urls_ok; then
+    echo "   ✓ Base URLs with ports, paths and IP or unicode hosts normalized, malformed ones rejected"
+fi
+rm -rf "$url_site"
+
+echo ""
+echo "42. Testing content statistics..."
+stats_site=$(mktemp -d)
+go build -o "$stats_site/vango" main.go
+mkdir -p "$stats_site/content/posts" "$stats_site/layouts/_default"
+printf 'title = "Stats"\nbaseURL = "https://example.com/"\n' > "$stats_site/config.toml"
+printf -- '---\ntitle: Long\ndate: 2024-03-01\ntags: [Go, Web]\n---\n%s\n' "$(yes word | head -1200 | tr '\n' ' ')" > "$stats_site/content/posts/long.md"
+printf -- '---\ntitle: Short\ndate: 2025-01-05\ntags: [go]\n---\nOnly three words\n' > "$stats_site/content/posts/short.md"
+printf -- '---\ntitle: About\ndate: 2025-02-01\n---\nAbout me here\n' > "$stats_site/content/about.md"
+printf '[{{ formatNumber .Site.Stats.Words }} words across {{ .Site.Stats.Pages }} pages|{{ formatNumber 240000 "de" }}]' > "$stats_site/layouts/_default/single.html"
+stats_json=$(cd "$stats_site" && ./vango stats --section posts --format json 2>/dev/null)
+stats_text=$(cd "$stats_site" && ./vango stats 2>/dev/null)
+(cd "$stats_site" && ./vango build >/dev/null 2>&1)
+if echo "$stats_json" | grep -q '"pages": 2' \
+    && echo "$stats_json" | grep -q '"words": 1203' \
+    && echo "$stats_json" | grep -A2 '"period": "2024"' | grep -q '"words": 1200' \
+    && echo "$stats_json" | grep -A3 '"slug": "go"' | grep -q '"pages": 2' \
+    && echo "$stats_text" | grep -q 'Words *1206' \
+    && grep -q '\[1,206 words across 3 pages|240.000\]' "$stats_site/public/about/index.html"; then
+    echo "   ✓ Stats command and .Site.Stats sum up words, periods and tags"
+else
+    echo "   ✗ Content statistics wrong"
+fi
+rm -rf "$stats_site"
+
+echo ""
+echo "43. Testing sandboxed theme templates..."
+box_site=$(mktemp -d)
+go build -o "$box_site/vango" main.go
+mkdir -p "$box_site/content" "$box_site/layouts/partials"
+printf 'title = "Sandbox"\ntheme = "t"\n[themes]\nsandbox = true\n' > "$box_site/config.toml"
+(cd "$box_site" && ./vango theme create t -t basic -q >/dev/null 2>&1)
+printf -- '---\ntitle: A\nuserInput: "<script>alert(1)</script>"\n---\nA\n' > "$box_site/content/a.md"
+printf '{{ safeHTML .Page.Params.userinput }}' > "$box_site/layouts/partials/own.html"
+printf '1[{{ safeHTML .Page.Params.userinput }}] 2[{{ .Page.Params.userinput | safeHTML }}] 3[{{ safeHTML "<b>ok</b>" }}] 4[{{ template "partials/own" . }}]\n' > "$box_site/themes/t/layouts/_default/single.html"
+(cd "$box_site" && ./vango build >/dev/null 2>&1)
+sandboxed=$(cat "$box_site/public/a/index.html" 2>/dev/null)
+sed -i 's/sandbox = true/sandbox = false/' "$box_site/config.toml"
+(cd "$box_site" && ./vango build >/dev/null 2>&1)
+if echo "$sandboxed" | grep -qF '1[&lt;script&gt;alert(1)&lt;/script&gt;] 2[&lt;script&gt;alert(1)&lt;/script&gt;] 3[<b>ok</b>] 4[<script>alert(1)</script>]' \
+    && grep -qF '1[<script>alert(1)</script>]' "$box_site/public/a/index.html"; then
+    echo "   ✓ Sandboxed theme templates escape safeHTML on page data, site layouts keep it"
+else
+    echo "   ✗ Theme sandbox not applied: $sandboxed"
+fi
+rm -rf "$box_site"
+
+echo ""
+echo "44. Testing per-file static copying on incremental builds..."
+sf_site=$(mktemp -d)
+go build -o "$sf_site/vango" main.go
+mkdir -p "$sf_site/content" "$sf_site/layouts/_default" "$sf_site/static/img/deep/er"
+printf 'title = "Static"\ntheme = "t"\n' > "$sf_site/config.toml"
+(cd "$sf_site" && ./vango theme create t -t basic -q >/dev/null 2>&1)
+printf '{{ .Page.Title }}\n' > "$sf_site/layouts/_default/single.html"
+printf -- '---\ntitle: A\n---\nA\n' > "$sf_site/content/a.md"
+printf 'old\n' > "$sf_site/static/img/deep/er/my photo.txt"
+printf 'gone\n' > "$sf_site/static/img/deep/doomed.txt"
+sf_port=$((20000 + RANDOM % 10000))
+(cd "$sf_site" && exec ./vango serve -v -p "$sf_port" >serve.log 2>&1) &
+sf_pid=$!
+sleep 3
+printf 'new\n' > "$sf_site/static/img/deep/er/my photo.txt"
+printf 'ünï\n' > "$sf_site/static/img/deep/er/café über.txt"
+rm "$sf_site/static/img/deep/doomed.txt"
+sleep 2
+mkdir -p "$sf_site/static/fresh dir/sub"
+printf 'x\n' > "$sf_site/static/fresh dir/sub/x.txt"
+printf 'theme\n' > "$sf_site/themes/t/static/js/naïve.js"
+sleep 3
+kill "$sf_pid" 2>/dev/null
+wait "$sf_pid" 2>/dev/null
+if grep -q new "$sf_site/public/static/img/deep/er/my photo.txt" \
+    && grep -q 'ünï' "$sf_site/public/static/img/deep/er/café über.txt" \
+    && [ ! -e "$sf_site/public/static/img/deep/doomed.txt" ] \
+    && [ -f "$sf_site/public/static/fresh dir/sub/x.txt" ] \
+    && grep -q theme "$sf_site/public/theme/js/naïve.js" \
+    && [ "$(grep -c 'Building site with' "$sf_site/serve.log")" = 1 ]; then
+    echo "   ✓ Changed, new and deleted static files copied one by one, with spaces and unicode names"
+else
+    echo "   ✗ Static files not copied incrementally"
+    grep -v 'GET ' "$sf_site/serve.log" | tail -20
+fi
+rm -rf "$sf_site"
+
+echo ""
+echo "45. Testing pagination of list pages..."
+pg_site=$(mktemp -d)
+go build -o "$pg_site/vango" main.go
+mkdir -p "$pg_site/content/posts" "$pg_site/layouts/_default"
+printf 'title = "Pages"\nbaseURL = "https://example.com/"\n[pagination]\nhome = 2\nterm = 1\n' > "$pg_site/config.toml"
+printf -- '---\ntitle: Home\n---\n' > "$pg_site/content/_index.md"
+printf -- '---\ntitle: Posts\n---\n' > "$pg_site/content/posts/_index.md"
+for i in 1 2 3; do
+    printf -- '---\ntitle: P%s\ntags: [go]\n---\nP\n' "$i" > "$pg_site/content/posts/p$i.md"
+done
+printf '{{ $p := "" }}{{ if eq .Page.Kind "section" }}{{ $p = paginate 0 .Pages }}{{ else }}{{ $p = paginate .Pages }}{{ end }}[{{ $p.PageNumber }}/{{ $p.TotalPages }} n={{ len $p.Pages }} prev={{ $p.Prev }} next={{ $p.Next }}]' > "$pg_site/layouts/_default/list.html"
+printf '{{ .Page.Title }}' > "$pg_site/layouts/_default/single.html"
+(cd "$pg_site" && ./vango build >/dev/null 2>&1)
+pg_ok=false
+if grep -qF '[1/3 n=2 prev= next=/page/2/]' "$pg_site/public/index.html" \
+    && grep -qF '[2/3 n=2 prev=/ next=/page/3/]' "$pg_site/public/page/2/index.html" \
+    && grep -qF '[3/3 n=1 prev=/tags/go/page/2/ next=]' "$pg_site/public/tags/go/page/3/index.html" \
+    && grep -qF '[1/1 n=3 prev= next=]' "$pg_site/public/posts/index.html" \
+    && [ ! -e "$pg_site/public/posts/page/2" ]; then
+    pg_ok=true
+else
+    find "$pg_site/public" -name index.html | sort
+fi
+printf '{{ $a := paginate 2 .Pages }}{{ $b := paginate 3 .Pages }}' > "$pg_site/layouts/_default/list.html"
+pg_conflict=$(cd "$pg_site" && ./vango build 2>&1)
+if $pg_ok && echo "$pg_conflict" | grep -q 'a page can only be paginated one way'; then
+    echo "   ✓ List pages paginated with per-kind and template sizes, conflicting sizes rejected"
+else
+    echo "   ✗ Pagination wrong"
+fi
+rm -rf "$pg_site"
+
+echo ""
+echo "46. Testing favicon generation..."
+fav_site=$(mktemp -d)
+go build -o "$fav_site/vango" main.go
+mkdir -p "$fav_site/content" "$fav_site/layouts" "$fav_site/assets"
+printf 'title = "Icons"\nbaseURL = "https://example.com/"\ntheme = "t"\n[favicon]\nsource = "assets/logo.svg"\nthemeColor = "#336699"\n' > "$fav_site/config.toml"
+(cd "$fav_site" && ./vango theme create t -t basic -q >/dev/null 2>&1)
+printf -- '---\ntitle: A\n---\nA\n' > "$fav_site/content/a.md"
+printf '<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 32 32"><rect width="32" height="32" rx="6" fill="#336699"/><path d="M8 8h16v16H8z" fill="white"/></svg>\n' > "$fav_site/assets/logo.svg"
+fav_first=$(cd "$fav_site" && ./vango build 2>&1)
+fav_second=$(cd "$fav_site" && ./vango build 2>&1)
+png_size() { od -An -tu1 -j16 -N8 "$1" | awk '{print $3*256+$4 "x" $7*256+$8}'; }
+if echo "$fav_first" | grep -q 'Generated favicons' \
+    && ! echo "$fav_second" | grep -q 'Generated favicons' \
+    && [ "$(od -An -tx1 -N6 "$fav_site/public/favicon.ico" | tr -d ' ')" = "000001000300" ] \
+    && [ "$(png_size "$fav_site/public/apple-touch-icon.png")" = "180x180" ] \
+    && [ "$(png_size "$fav_site/public/android-chrome-512x512.png")" = "512x512" ] \
+    && [ -f "$fav_site/public/favicon-16x16.png" ] && [ -f "$fav_site/public/favicon.svg" ] \
+    && grep -q '"theme_color": "#336699"' "$fav_site/public/site.webmanifest" \
+    && grep -q '<link rel="apple-touch-icon" sizes="180x180" href="/apple-touch-icon.png">' "$fav_site/public/a/index.html" \
+    && grep -q '<meta name="theme-color" content="#336699">' "$fav_site/public/a/index.html"; then
+    sed -i '/^\[favicon\]/,$d' "$fav_site/config.toml"
+    (cd "$fav_site" && ./vango build >/dev/null 2>&1)
+    if [ ! -e "$fav_site/public/favicon.ico" ] && ! grep -q 'rel="icon"' "$fav_site/public/a/index.html"; then
+        echo "   ✓ Icons, manifest and tags generated from an SVG, cached, and skipped without a source"
+    else
+        echo "   ✗ Favicons generated without favicon.source"
+    fi
+else
+    echo "   ✗ Favicons not generated"
+    echo "$fav_first" | tail -3
+fi
+rm -rf "$fav_site"
+
+echo ""
+echo "47. Testing detection of hand-edited output files..."
+mo_site=$(mktemp -d)
+go build -o "$mo_site/vango" main.go
+mkdir -p "$mo_site/content" "$mo_site/layouts/_default"
+printf 'title = "Edits"\nbaseURL = "https://example.com/"\n' > "$mo_site/config.toml"
+printf -- '---\ntitle: A\n---\nA\n' > "$mo_site/content/a.md"
+printf '{{ .Page.Title }}' > "$mo_site/layouts/_default/single.html"
+(cd "$mo_site" && ./vango build >/dev/null 2>&1)
+touch "$mo_site/public/sitemap.xml"
+echo hotfix >> "$mo_site/public/a/index.html"
+mo_warn=$(cd "$mo_site" && ./vango build 2>&1)
+echo hotfix >> "$mo_site/public/a/index.html"
+mo_force=$(cd "$mo_site" && ./vango build --force 2>&1)
+echo hotfix >> "$mo_site/public/a/index.html"
+printf 'modifiedOutputLevel = "error"\n' >> "$mo_site/config.toml"
+if (cd "$mo_site" && ./vango build >/dev/null 2>&1); then mo_failed=false; else mo_failed=true; fi
+if echo "$mo_warn" | grep -q '1 files in public were edited' \
+    && echo "$mo_warn" | grep -q -- '- a/index.html' \
+    && ! echo "$mo_warn" | grep -q 'sitemap.xml' \
+    && ! echo "$mo_force" | grep -q 'edited' \
+    && $mo_failed && grep -q hotfix "$mo_site/public/a/index.html"; then
+    echo "   ✓ Edited output files listed before overwriting, skipped with --force, fatal as an error level"
+else
+    echo "   ✗ Edited output files not detected"
+    echo "$mo_warn" | tail -5
+fi
+rm -rf "$mo_site"
+
+echo "48. Testing theme listing and metadata..."
+tm_site=$(mktemp -d)
+go build -o "$tm_site/vango" main.go
+mkdir -p "$tm_site/content" "$tm_site/layouts" "$tm_site/static"
+printf 'title = "Themes"\nbaseURL = "https://example.com/"\n' > "$tm_site/config.toml"
+for t in alpha beta gamma; do
+    mkdir -p "$tm_site/themes/$t/layouts/_default"
+    for f in baseof single list; do printf '{{ .Page.Title }}' > "$tm_site/themes/$t/layouts/_default/$f.html"; done
+done
+printf '{"name":"alpha","version":"1.0.0","author":"Zoe","tags":["dark-mode","blog"],"features":["search"]}' > "$tm_site/themes/alpha/theme.json"
+printf '{"name":"beta","version":"1.0.0","author":"Ann","tags":["Dark-Mode"]}' > "$tm_site/themes/beta/theme.json"
+printf '{"name":"gamma","version":"1.0.0","author":"Max","description":"G","license":"MIT"}' > "$tm_site/themes/gamma/theme.json"
+printf 'iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mP8z8DwHwAFBQIAX8jx0gAAAABJRU5ErkJggg==' | base64 -d > "$tm_site/themes/alpha/screenshot.png"
+echo "not an image" > "$tm_site/themes/gamma/screenshot.png"
+tm_names=$(cd "$tm_site" && ./vango theme list 2>/dev/null | awk 'NR>4 {print $1}' | tr '\n' ' ')
+tm_authors=$(cd "$tm_site" && ./vango theme list --sort author 2>/dev/null | awk 'NR>4 {print $1}' | tr '\n' ' ')
+tm_tagged=$(cd "$tm_site" && ./vango theme list --tag dark-mode --detailed 2>/dev/null)
+tm_json=$(cd "$tm_site" && ./vango theme list --format json --tag blog 2>/dev/null)
+tm_info=$(cd "$tm_site" && ./vango theme info alpha 2>/dev/null)
+tm_package=$(cd "$tm_site" && ./vango theme package gamma 2>&1)
+if [ "$tm_names" = "alpha beta gamma " ] && [ "$tm_authors" = "beta gamma alpha " ] \
+    && echo "$tm_tagged" | grep -q 'FEATURES' && echo "$tm_tagged" | grep -q 'beta' && ! echo "$tm_tagged" | grep -q 'gamma' \
+    && echo "$tm_json" | grep -q '"screenshot": "screenshot.png"' && ! echo "$tm_json" | grep -q '"beta"' \
+    && echo "$tm_info" | grep -q 'Screenshot:.*screenshot.png' \
+    && echo "$tm_package" | grep -q 'not a PNG or JPEG image'; then
+    echo "   ✓ Themes sorted, filtered by tag, detailed and printed as JSON; screenshot.png found and checked"
+else
+    echo "   ✗ Theme listing or metadata wrong"
+    echo "$tm_names / $tm_authors"
+    echo "$tm_package" | tail -3
+fi
+rm -rf "$tm_site"
+
+echo "49. Testing the performance budget..."
+pb_site=$(mktemp -d)
+go build -o "$pb_site/vango" main.go
+mkdir -p "$pb_site/content" "$pb_site/layouts/_default" "$pb_site/static/img"
+printf 'title = "Budget"\nbaseURL = "https://example.com/"\n[budget]\nhtml = 2\npageWeight = 20\nimage = 15\n' > "$pb_site/config.toml"
+printf -- '---\ntitle: Big\n---\n%s\n' "$(head -c 3000 /dev/zero | tr '\0' a)" > "$pb_site/content/big.md"
+printf -- '---\ntitle: Small\n---\nSmall\n' > "$pb_site/content/small.md"
+head -c 20000 /dev/urandom > "$pb_site/static/img/hero.png"
+printf '<html><body>{{ .Page.Content }}{{ if eq .Page.Title "Big" }}<img src="/static/img/hero.png" alt=""><img src="../static/img/hero.png" alt="">{{ end }}</body></html>' > "$pb_site/layouts/_default/single.html"
+pb_warn=$(cd "$pb_site" && ./vango build 2>&1)
+pb_built=$?
+if (cd "$pb_site" && ./vango build --strict-budget >/dev/null 2>&1); then pb_failed=false; else pb_failed=true; fi
+if [ $pb_built -eq 0 ] && $pb_failed \
+    && echo "$pb_warn" | grep -q 'big/index.html is 3.[0-9] KB, over the 2.0 KB html budget' \
+    && echo "$pb_warn" | grep -q '/big/ weighs 2[0-9].[0-9] KB with its stylesheets, scripts and images' \
+    && echo "$pb_warn" | grep -q 'static/img/hero.png is 19.5 KB, over the 15.0 KB image budget' \
+    && ! echo "$pb_warn" | grep -q 'small/'; then
+    echo "   ✓ Oversized files reported against the budget, fatal with --strict-budget"
+else
+    echo "   ✗ Performance budget not checked"
+    echo "$pb_warn" | grep -i budget
+fi
+rm -rf "$pb_site"
+
+echo ""
+echo "50. Testing the live reload client and protocol..."
+lr_site=$(mktemp -d)
+go build -o "$lr_site/vango" main.go
+mkdir -p "$lr_site/content" "$lr_site/layouts/_default" "$lr_site/static"
+printf 'title = "Reload"\n' > "$lr_site/config.toml"
+printf '<html><body>{{ .Page.Title }}</body></html>\n' > "$lr_site/layouts/_default/single.html"
+printf -- '---\ntitle: A\n---\nA\n' > "$lr_site/content/a.md"
+lr_port=$((20000 + RANDOM % 10000))
+lr_url="http://localhost:$lr_port"
+(cd "$lr_site" && exec ./vango serve -p "$lr_port" >serve.log 2>&1) &
+lr_pid=$!
+sleep 3
+lr_ws="-H Connection:Upgrade -H Upgrade:websocket -H Sec-WebSocket-Version:13 -H Sec-WebSocket-Key:dGhlIHNhbXBsZSBub25jZQ=="
+curl -s -i -N --max-time 6 $lr_ws "$lr_url/ws/reload?v=1" > "$lr_site/v1.log" 2>&1 &
+curl -s -i -N --max-time 6 $lr_ws "$lr_url/ws/reload" > "$lr_site/legacy.log" 2>&1 &
+sleep 1
+printf -- '---\ntitle: B\n---\nB\n' > "$lr_site/content/a.md"
+curl -s "$lr_url/a/" > "$lr_site/page.html"
+lr_etag=$(curl -s -D - -o "$lr_site/client.js" "$lr_url/__vango/livereload.js" | tr -d '\r' | sed -n 's/^[Ee][Tt]ag: //p')
+lr_cached=$(curl -s -o /dev/null -w '%{http_code}' -H "If-None-Match: $lr_etag" "$lr_url/__vango/livereload.js")
+lr_bad=$(curl -s -o /dev/null -w '%{http_code}' $lr_ws "$lr_url/ws/reload?v=x")
+sleep 6
+kill "$lr_pid" 2>/dev/null
+wait "$lr_pid" 2>/dev/null
+if grep -q '<script src="/__vango/livereload.js?v=1"></script>' "$lr_site/page.html" \
+    && grep -q 'PROTOCOL = 1' "$lr_site/client.js" \
+    && [ -n "$lr_etag" ] && [ "$lr_cached" = 304 ] && [ "$lr_bad" = 400 ] \
+    && grep -aq '{"v":1,"type":"progress","data":{"stage":' "$lr_site/v1.log" \
+    && grep -aq '{"v":1,"type":"reload"}' "$lr_site/v1.log" \
+    && grep -aq 'reload' "$lr_site/legacy.log" && ! grep -aq '"type"' "$lr_site/legacy.log"; then
+    echo "   ✓ Client script served and cached, versioned JSON messages, plain ones for legacy clients"
+else
+    echo "   ✗ Live reload client or protocol broken"
+    echo "   page: $(grep -o '<script[^>]*>' "$lr_site/page.html"), etag: $lr_etag, cached: $lr_cached, bad: $lr_bad"
+    cat -v "$lr_site/v1.log" "$lr_site/legacy.log" | tail -20
+fi
+rm -rf "$lr_site"
+echo ""
+echo "51. Testing dev server proxies..."
+px_site=$(mktemp -d)
+go build -o "$px_site/vango" main.go
+mkdir -p "$px_site/content" "$px_site/layouts/_default" "$px_site/static" "$px_site/backend/content" "$px_site/backend/layouts/_default" "$px_site/backend/static"
+px_port=$((20000 + RANDOM % 10000))
+px_api=$((px_port + 1))
+px_ws=$((px_port + 2))
+px_down=$((px_port + 3))
+printf '{{ .Page.Title }}\n' > "$px_site/layouts/_default/single.html"
+printf -- '---\ntitle: Shadowed\n---\nS\n' > "$px_site/content/api.md"
+cat > "$px_site/config.toml" <<TOML
+title = "Proxy"
+[server.proxies]
+"/down/" = "http://localhost:$px_down"
+"/live/" = { target = "http://localhost:$px_ws", rewrite = "/" }
+[server.proxies."/api/"]
+target = "http://localhost:$px_api/v1"
+rewrite = "/"
+[server.proxies."/api/".headers]
+X-Api-Key = "secret"
+TOML
+printf 'title = "Backend"\n' > "$px_site/backend/config.toml"
+printf '{{ .Page.Title }}\n' > "$px_site/backend/layouts/_default/single.html"
+printf -- '---\ntitle: Upstream\n---\nU\n' > "$px_site/backend/content/up.md"
+cat > "$px_site/api.py" <<'PY'
+import json, sys
+from http.server import BaseHTTPRequestHandler, HTTPServer
+class Echo(BaseHTTPRequestHandler):
+    def do_GET(self):
+        status = 500 if self.path.endswith("/fail") else 200
+        body = json.dumps({"path": self.path, "key": self.headers.get("X-Api-Key"), "proto": self.headers.get("X-Forwarded-Proto")}).encode()
+        self.send_response(status)
+        self.send_header("Content-Type", "application/json")
+        self.send_header("Content-Length", str(len(body)))
+        self.end_headers()
+        self.wfile.write(body)
+    def log_message(self, *args):
+        pass
+HTTPServer(("localhost", int(sys.argv[1])), Echo).serve_forever()
+PY
+python3 "$px_site/api.py" "$px_api" &
+px_api_pid=$!
+(cd "$px_site/backend" && exec ../vango serve -p "$px_ws" >serve.log 2>&1) &
+px_ws_pid=$!
+(cd "$px_site" && exec ./vango serve -p "$px_port" >serve.log 2>&1) &
+px_pid=$!
+sleep 4
+px_url="http://localhost:$px_port"
+px_json=$(curl -s "$px_url/api/users?id=1")
+px_shadow=$(curl -s "$px_url/api/")
+px_fail=$(curl -s -o /dev/null -w '%{http_code}' "$px_url/api/fail")
+px_bad=$(curl -s -o /dev/null -w '%{http_code}' "$px_url/down/x")
+px_status=$(curl -s "$px_url/api/status")
+px_own=$(curl -s "$px_url/__vango/api/status")
+px_page=$(curl -s "$px_url/live/up/")
+curl -s -i -N --max-time 3 -H Connection:Upgrade -H Upgrade:websocket -H Sec-WebSocket-Version:13 \
+    -H Sec-WebSocket-Key:dGhlIHNhbXBsZSBub25jZQ== "$px_url/live/ws/reload?v=1" > "$px_site/ws.log" 2>&1
+kill "$px_pid" "$px_ws_pid" "$px_api_pid" 2>/dev/null
+wait "$px_pid" "$px_ws_pid" "$px_api_pid" 2>/dev/null
+if echo "$px_json" | grep -q '"path": "/v1/users?id=1", "key": "secret", "proto": "http"' \
+    && echo "$px_shadow" | grep -q '"path": "/v1/"' \
+    && [ "$px_fail" = 500 ] && [ "$px_bad" = 502 ] \
+    && echo "$px_status" | grep -q '"path": "/v1/status", "key": "secret"' \
+    && echo "$px_own" | grep -q '"status":"running"' \
+    && echo "$px_page" | grep -q Upstream \
+    && grep -aq '101 Switching Protocols' "$px_site/ws.log" \
+    && grep -q "Proxying /api/ to http://localhost:$px_api/v1" "$px_site/serve.log" \
+    && grep -q "Proxy: GET http://localhost:$px_api/v1/fail answered 500" "$px_site/serve.log" \
+    && grep -q "Proxy: GET http://localhost:$px_down/down/x failed" "$px_site/serve.log" \
+    && [ ! -e "$px_site/public/down" ] && ! grep -rq "localhost:$px_api" "$px_site/public"; then
+    echo "   ✓ Prefixes proxied with rewrites, headers and WebSockets, /api/status included, upstream errors logged"
+else
+    echo "   ✗ Dev server proxies broken"
+    echo "   api: $px_json, fail: $px_fail, down: $px_bad, page: $px_page"
+    echo "   status: $px_status, own: $(echo "$px_own" | head -c 200)"
+    head -5 "$px_site/ws.log"
+    grep -v 'GET ' "$px_site/serve.log" | tail -20
+fi
+printf 'title = "Proxy"\n[server.proxies]\n"/api/" = "localhost:4000"\n' > "$px_site/config.toml"
+px_invalid=$(cd "$px_site" && ./vango build 2>&1)
+printf 'title = "Proxy"\n[server.proxies]\n"/admin" = "http://localhost:4000"\n' > "$px_site/config.toml"
+px_clash=$(cd "$px_site" && timeout 10 ./vango serve -p "$px_port" 2>&1)
+if echo "$px_invalid" | grep -q 'invalid server.proxies .*/api/.* target .*localhost:4000.*must be an http or https URL' \
+    && echo "$px_clash" | grep -q '/admin.* overlaps /admin, served by the development server itself'; then
+    echo "   ✓ Invalid proxy targets and prefixes of the dev server's own routes rejected"
+else
+    echo "   ✗ Bad proxy config not rejected"
+    echo "$px_invalid" | tail -3
+    echo "$px_clash" | tail -3
+fi
+rm -rf "$px_site"
+echo ""
+echo "52. Testing YAML, TOML and JSON front matter and shortcode bodies..."
+fmt_site=$(mktemp -d)
+go build -o "$fmt_site/vango" main.go
+mkdir -p "$fmt_site/content" "$fmt_site/layouts/_default" "$fmt_site/static"
+printf '{{ .Page.Title }}|{{ .Page.Params.kind }}|{{ .Page.Content }}\n' > "$fmt_site/layouts/_default/single.html"
+printf 'title = "Formats"\nbaseURL = "https://example.org/"\n' > "$fmt_site/config.toml"
+printf -- '---\ntitle: Yaml\nkind: yaml\n---\nYAML body\n' > "$fmt_site/content/yaml.md"
+printf -- '+++\ntitle = "Toml"\nkind = "toml"\n+++\nTOML body\n' > "$fmt_site/content/toml.md"
+printf -- '{\n  "title": "Json",\n  "kind": "json"\n}\nJSON body\n' > "$fmt_site/content/json.md"
+printf -- '{"title": "Inline", "kind": "inline"}\nInline body\n' > "$fmt_site/content/inline.md"
+printf -- '{{< relref "yaml" >}} is the YAML page\n' > "$fmt_site/content/shortcode.md"
+printf -- '{ not json, just braces }\n' > "$fmt_site/content/braces.md"
+fmt_log=$(cd "$fmt_site" && ./vango build 2>&1)
+ok=true
+grep -q '^Yaml|yaml|.*YAML body' "$fmt_site/public/yaml/index.html" || ok=false
+grep -q '^Toml|toml|.*TOML body' "$fmt_site/public/toml/index.html" || ok=false
+grep -q '^Json|json|.*JSON body' "$fmt_site/public/json/index.html" || ok=false
+grep -q '^Inline|inline|.*Inline body' "$fmt_site/public/inline/index.html" || ok=false
+grep -q '/yaml/ is the YAML page' "$fmt_site/public/shortcode/index.html" || ok=false
+grep -q '{ not json, just braces }' "$fmt_site/public/braces/index.html" || ok=false
+if $ok; then
+    echo "   ✓ Front matter in every format parsed; shortcode and brace bodies kept as content"
+else
+    echo "   ✗ Front matter formats or shortcode bodies mishandled"
+    echo "$fmt_log" | grep -i 'error\|❌' | head -5
+    head -3 "$fmt_site"/public/*/index.html
+fi
+rm -rf "$fmt_site"
+echo ""
+echo "53. Testing term _index pages across incremental builds..."
+ti_site=$(mktemp -d)
+go build -o "$ti_site/vango" main.go
+mkdir -p "$ti_site/content/tags/go" "$ti_site/layouts/_default" "$ti_site/static"
+printf 'title = "Terms"\n' > "$ti_site/config.toml"
+printf '{{ .Page.Title }}|{{ .Page.Content }}\n' > "$ti_site/layouts/_default/single.html"
+printf 'LIST {{ .Page.Title }}|{{ .Page.Content }}\n' > "$ti_site/layouts/_default/list.html"
+printf -- '---\ntitle: A\ntags: [go]\n---\nA\n' > "$ti_site/content/a.md"
+printf -- '---\ntitle: Gophers\n---\nAll about Go\n' > "$ti_site/content/tags/go/_index.md"
+printf -- '---\ntitle: Every tag\n---\nTag index\n' > "$ti_site/content/tags/_index.md"
+ti_port=$((20000 + RANDOM % 10000))
+(cd "$ti_site" && exec ./vango serve -p "$ti_port" >serve.log 2>&1) &
+ti_pid=$!
+sleep 3
+printf -- '---\ntitle: A2\ntags: [go]\n---\nA edited\n' > "$ti_site/content/a.md"
+sleep 2
+printf -- '---\ntitle: B\ntags: [go]\n---\nB\n' > "$ti_site/content/b.md"
+sleep 2
+ti_after_edits=$(cat "$ti_site/public/tags/go/index.html" "$ti_site/public/tags/index.html")
+printf -- '---\ntitle: Gopher fans\n---\nStill about Go\n' > "$ti_site/content/tags/go/_index.md"
+sleep 2
+kill "$ti_pid" 2>/dev/null
+wait "$ti_pid" 2>/dev/null
+if echo "$ti_after_edits" | grep -q 'LIST Gophers|<p>All about Go</p>' \
+    && echo "$ti_after_edits" | grep -q 'LIST Every tag|<p>Tag index</p>' \
+    && grep -q 'LIST Gopher fans|<p>Still about Go</p>' "$ti_site/public/tags/go/index.html" \
+    && grep -q 'A edited' "$ti_site/public/a/index.html"; then
+    echo "   ✓ Term and taxonomy pages keep their _index content when other content changes"
+else
+    echo "   ✗ Term _index content lost on incremental builds"
+    echo "$ti_after_edits"
+    cat "$ti_site/public/tags/go/index.html"
+    grep -v 'GET ' "$ti_site/serve.log" | tail -10
+fi
+rm -rf "$ti_site"
+echo ""
+echo "54. Testing the generated development certificate..."
+dc_site=$(mktemp -d)
+go build -o "$dc_site/vango" main.go
+mkdir -p "$dc_site/content" "$dc_site/layouts/_default" "$dc_site/static" "$dc_site/home"
+printf 'title = "TLS"\n' > "$dc_site/config.toml"
+printf '{{ .Page.Title }}\n' > "$dc_site/layouts/_default/single.html"
+printf -- '---\ntitle: Secure\n---\nS\n' > "$dc_site/content/s.md"
+dc_port=$((20000 + RANDOM % 10000))
+(cd "$dc_site" && HOME="$dc_site/home" XDG_CACHE_HOME="$dc_site/home/.cache" exec ./vango serve --tls -p "$dc_port" >serve.log 2>&1) &
+dc_pid=$!
+sleep 3
+dc_page=$(curl -sk "https://localhost:$dc_port/s/")
+kill "$dc_pid" 2>/dev/null
+wait "$dc_pid" 2>/dev/null
+dc_cert="$dc_site/home/.cache/vango/tls/localhost.pem"
+dc_key="$dc_site/home/.cache/vango/tls/localhost-key.pem"
+dc_text=$(openssl x509 -in "$dc_cert" -noout -text 2>/dev/null)
+if [ "$dc_page" = Secure ] && [ ! -e "$dc_site/.cache/tls" ] \
+    && [ "$(stat -c %a "$dc_key" 2>/dev/null)" = 600 ] \
+    && echo "$dc_text" | grep -q 'CA:FALSE' \
+    && echo "$dc_text" | grep -q 'TLS Web Server Authentication' \
+    && ! echo "$dc_text" | grep -q 'Certificate Sign'; then
+    echo "   ✓ Leaf certificate for servers only, key kept private in the user cache directory"
+else
+    echo "   ✗ Development certificate unsafe or misplaced"
+    echo "   page: $dc_page"
+    echo "$dc_text" | grep -A1 'Basic Constraints\|Key Usage'
+    ls -la "$dc_site/home/.cache/vango/tls" "$dc_site/.cache" 2>&1
+fi
+rm -rf "$dc_site"
+echo ""
+echo "55. Testing stylesheets without Dart Sass..."
+ns_site=$(mktemp -d)
+go build -o "$ns_site/vango" main.go
+mkdir -p "$ns_site/content" "$ns_site/layouts/_default" "$ns_site/static" "$ns_site/assets/css" "$ns_site/nobin"
+printf 'title = "Sass"\n' > "$ns_site/config.toml"
+printf '{{ .Page.Title }}\n' > "$ns_site/layouts/_default/single.html"
+printf -- '---\ntitle: A\n---\nA\n' > "$ns_site/content/a.md"
+printf '$c: red;\nbody { color: $c; }\n' > "$ns_site/assets/css/main.scss"
+printf '$c: blue;\n' > "$ns_site/assets/css/_vars.scss"
+ns_log=$(cd "$ns_site" && PATH="$ns_site/nobin" ./vango build 2>&1)
+ns_status=$?
+if [ "$ns_status" != 0 ] \
+    && echo "$ns_log" | grep -q 'compiling assets/css/main.scss requires Dart Sass' \
+    && echo "$ns_log" | grep -q 'npm install -g sass' \
+    && ! echo "$ns_log" | grep -q '_vars.scss'; then
+    echo "   ✓ Missing sass executable reported with the stylesheets needing it"
+else
+    echo "   ✗ Missing sass executable not reported clearly"
+    echo "$ns_log" | tail -3
+fi
+rm -rf "$ns_site"
+echo ""
+echo "56. Testing config reloads in the dev server for data races..."
+rr_site=$(mktemp -d)
+mkdir -p "$rr_site/content" "$rr_site/layouts/_default" "$rr_site/static"
+printf 'title = "Reload 0"\n' > "$rr_site/config.toml"
+printf '{{ .Page.Title }}\n' > "$rr_site/layouts/_default/single.html"
+for i in $(seq 1 20); do
+    printf -- '---\ntitle: P%d\n---\nP\n' "$i" > "$rr_site/content/p$i.md"
+done
+if go build -race -o "$rr_site/vango-race" main.go 2>/dev/null; then
+    rr_port=$((20000 + RANDOM % 10000))
+    (cd "$rr_site" && exec ./vango-race serve -p "$rr_port" >serve.log 2>&1) &
+    rr_pid=$!
+    sleep 5
+    (
+        for i in $(seq 1 150); do
+            for path in /p1/ /p1 /P2/ /missing/ /__vango/api/status /__vango/api/pages /__vango/api/config /healthz; do
+                curl -s -o /dev/null "http://localhost:$rr_port$path"
+            done
+        done
+    ) &
+    rr_load=$!
+    for i in 1 2 3 4; do
+        printf 'title = "Reload %d"\n' "$i" > "$rr_site/config.toml"
+        sleep 2
+    done
+    wait "$rr_load"
+    kill "$rr_pid" 2>/dev/null
+    wait "$rr_pid" 2>/dev/null
+    if grep -q "DATA RACE" "$rr_site/serve.log"; then
+        echo "   ✗ Data race detected:"
+        grep -A20 "DATA RACE" "$rr_site/serve.log" | head -40
+    elif ! grep -q 'Configuration changed' "$rr_site/serve.log"; then
+        echo "   ✗ Config was not reloaded"
+        tail -5 "$rr_site/serve.log"
+    else
+        echo "   ✓ Config reloaded while serving requests without data races"
+    fi
+else
+    echo "   - Race detector unavailable, skipped"
+fi
+rm -rf "$rr_site"
+echo ""
+echo "57. Testing lazy renders during the background build..."
+lr_site=$(mktemp -d)
+mkdir -p "$lr_site/content/posts" "$lr_site/layouts/_default" "$lr_site/static"
+printf 'title = "Lazy"\n[taxonomies]\ntag = "tags"\n' > "$lr_site/config.toml"
+printf '{{ .Page.Title }} {{ with .Page.Resources.GetMatch "data.txt" }}{{ .RelPermalink }}{{ end }} {{ range .Pages }}{{ .Title }} {{ end }}\n' > "$lr_site/layouts/_default/single.html"
+printf '{{ .Page.Title }} {{ range .Pages }}{{ .Title }} {{ end }}\n' > "$lr_site/layouts/_default/list.html"
+for i in $(seq 1 400); do
+    mkdir -p "$lr_site/content/posts/p$i"
+    printf -- '---\ntitle: P%d\ntags: [t%d]\n---\nSee [the first]({{< relref "posts/p1" >}}).\n' "$i" "$((i % 7))" > "$lr_site/content/posts/p$i/index.md"
+    printf 'data %d\n' "$i" > "$lr_site/content/posts/p$i/data.txt"
+done
+if go build -race -o "$lr_site/vango-race" main.go 2>/dev/null; then
+    lr_port=$((20000 + RANDOM % 10000))
+    (cd "$lr_site" && exec ./vango-race serve --lazy -p "$lr_port" >serve.log 2>&1) &
+    lr_pid=$!
+    for i in $(seq 1 100); do
+        curl -s -o /dev/null "http://localhost:$lr_port/" && break
+        sleep 0.1
+    done
+    lr_load=""
+    for worker in 1 2 3 4; do
+        (
+            for i in $(seq "$worker" 4 400); do
+                curl -s -o /dev/null -w '%{http_code}\n' "http://localhost:$lr_port/posts/p$i/"
+            done
+        ) >>"$lr_site/codes.log" &
+        lr_load="$lr_load $!"
+    done
+    # Edit a page while requests are in flight, so rebuilds run alongside them
+    for i in 1 2 3 4 5; do
+        printf -- '---\ntitle: P2 edit %d\ntags: [t2]\n---\nEdited.\n' "$i" > "$lr_site/content/posts/p2/index.md"
+        sleep 0.3
+    done
+    wait $lr_load
+    for i in $(seq 1 60); do
+        grep -q "all pages warm" "$lr_site/serve.log" && break
+        sleep 1
+    done
+    for i in $(seq 1 30); do
+        curl -s "http://localhost:$lr_port/posts/p2/" | grep -q 'P2 edit 5' && break
+        sleep 0.5
+    done
+    lr_edited=$(curl -s "http://localhost:$lr_port/posts/p2/")
+    kill "$lr_pid" 2>/dev/null
+    wait "$lr_pid" 2>/dev/null
+    if grep -q "DATA RACE" "$lr_site/serve.log"; then
+        echo "   ✗ Data race detected:"
+        grep -A30 "DATA RACE" "$lr_site/serve.log" | head -60
+    elif grep -qv '^200$' "$lr_site/codes.log"; then
+        echo "   ✗ Lazy renders failed against a half-built site:"
+        sort "$lr_site/codes.log" | uniq -c
+    elif ! grep -q "all pages warm" "$lr_site/serve.log"; then
+        echo "   ✗ Background build did not finish"
+        tail -5 "$lr_site/serve.log"
+    elif ! echo "$lr_edited" | grep -q 'P2 edit 5'; then
+        echo "   ✗ Page edited during the requests not rebuilt: $lr_edited"
+    else
+        echo "   ✓ Pages rendered on demand during the background build and an edit without failures or data races"
+    fi
+else
+    echo "   - Race detector unavailable, skipped"
+fi
+rm -rf "$lr_site"
+echo ""
+echo "58. Testing partial changes re-render the pages calling them..."
+pc_site=$(mktemp -d)
+go build -o "$pc_site/vango" main.go
+mkdir -p "$pc_site/content/docs" "$pc_site/layouts/_default" "$pc_site/layouts/docs" "$pc_site/layouts/partials" "$pc_site/static"
+printf 'title = "Partials"\n' > "$pc_site/config.toml"
+printf 'PLAIN {{ template "partials/note" .Page }}\n' > "$pc_site/layouts/_default/single.html"
+printf 'DOCS {{ with partial "note.html" .Page }}<b>{{ . }}</b>{{ end }}\n' > "$pc_site/layouts/docs/single.html"
+printf 'NOTE v1 {{ .Title }}' > "$pc_site/layouts/partials/note.html"
+printf -- '---\ntitle: Plain\n---\nPlain\n' > "$pc_site/content/plain.md"
+printf -- '---\ntitle: Guide\n---\nGuide\n' > "$pc_site/content/docs/guide.md"
+pc_port=$((20000 + RANDOM % 10000))
+(cd "$pc_site" && exec ./vango serve -p "$pc_port" >serve.log 2>&1) &
+pc_pid=$!
+sleep 3
+pc_before=$(cat "$pc_site/public/docs/guide/index.html")
+printf 'NOTE v2 {{ .Title }}' > "$pc_site/layouts/partials/note.html"
+sleep 2
+kill "$pc_pid" 2>/dev/null
+wait "$pc_pid" 2>/dev/null
+if echo "$pc_before" | grep -q 'DOCS <b>NOTE v1 Guide</b>' \
+    && grep -q 'DOCS <b>NOTE v2 Guide</b>' "$pc_site/public/docs/guide/index.html" \
+    && grep -q 'PLAIN NOTE v2 Plain' "$pc_site/public/plain/index.html"; then
+    echo "   ✓ Pages calling a partial are re-rendered when it changes"
+else
+    echo "   ✗ Page calling the changed partial was not re-rendered"
+    echo "$pc_before"
+    cat "$pc_site/public/docs/guide/index.html" "$pc_site/public/plain/index.html"
+    grep -v 'GET ' "$pc_site/serve.log" | tail -10
+fi
+rm -rf "$pc_site"
+echo ""
+echo "59. Testing front matter, _index cascade and section config precedence..."
+sp_site=$(mktemp -d)
+go build -o "$sp_site/vango" main.go
+mkdir -p "$sp_site/content/projects/web" "$sp_site/content/notes" "$sp_site/layouts/_default" "$sp_site/static"
+cat > "$sp_site/config.toml" <<'TOML'
+title = "Precedence"
+[sections.projects]
+defaultLayout = "cfg"
+defaultType = "cfgtype"
+params = { a = "config", b = "config", c = "config" }
+[sections.notes]
+defaultLayout = "cfg"
+params = { a = "config" }
+TOML
+for layout in single cfg casc own; do
+    printf '%s type={{ .Page.Type }} a={{ .Page.Params.a }} b={{ .Page.Params.b }} c={{ .Page.Params.c }}\n' "$layout" > "$sp_site/layouts/_default/$layout.html"
+done
+printf '+++\ntitle = "Projects"\n[cascade]\nlayout = "casc"\nb = "cascade"\n[cascade.params]\nc = "cascade"\n+++\n' > "$sp_site/content/projects/_index.md"
+printf -- '---\ntitle: Web\ncascade:\n  params:\n    c: nearest\n---\n' > "$sp_site/content/projects/web/_index.md"
+printf -- '---\ntitle: Plain\n---\nPlain\n' > "$sp_site/content/projects/plain.md"
+printf -- '---\ntitle: Own\nlayout: own\ntype: mine\nc: page\n---\nOwn\n' > "$sp_site/content/projects/own.md"
+printf -- '---\ntitle: Site\n---\nSite\n' > "$sp_site/content/projects/web/site.md"
+printf -- '---\ntitle: Note\n---\nNote\n' > "$sp_site/content/notes/note.md"
+(cd "$sp_site" && ./vango build >build.log 2>&1)
+sp_ok=true
+for expected in "projects/plain:casc type=cfgtype a=config b=cascade c=cascade" \
+    "projects/own:own type=mine a=config b=cascade c=page" \
+    "projects/web/site:casc type=cfgtype a=config b=cascade c=nearest" \
+    "notes/note:cfg type=notes a=config b= c="; do
+    sp_page=${expected%%:*}
+    if ! grep -qx "${expected#*:}" "$sp_site/public/$sp_page/index.html" 2>/dev/null; then
+        sp_ok=false
+        echo "   $sp_page: want '${expected#*:}', got '$(cat "$sp_site/public/$sp_page/index.html" 2>/dev/null)'"
+    fi
+done
+if [ "$sp_ok" = true ]; then
+    sp_port=$((20000 + RANDOM % 10000))
+    (cd "$sp_site" && exec ./vango serve -p "$sp_port" >serve.log 2>&1) &
+    sp_pid=$!
+    sleep 3
+    printf -- '---\ntitle: Web\ncascade:\n  layout: own\n  params:\n    c: edited\n---\n' > "$sp_site/content/projects/web/_index.md"
+    sleep 2
+    kill "$sp_pid" 2>/dev/null
+    wait "$sp_pid" 2>/dev/null
+    if ! grep -qx "own type=cfgtype a=config b=cascade c=edited" "$sp_site/public/projects/web/site/index.html"; then
+        sp_ok=false
+        echo "   Cascade edit not applied: $(cat "$sp_site/public/projects/web/site/index.html")"
+    fi
+fi
+if [ "$sp_ok" = true ]; then
+    echo "   ✓ Front matter beats the nearest _index cascade, which beats outer cascades and section config"
+else
+    echo "   ✗ Section default precedence is wrong"
+    tail -5 "$sp_site/build.log"
+fi
+rm -rf "$sp_site"
+echo ""
+echo "60. Testing warnings for languages without date locale data..."
+dl_site=$(mktemp -d)
+go build -o "$dl_site/vango" main.go
+mkdir -p "$dl_site/content" "$dl_site/layouts/_default" "$dl_site/static"
+printf '{{ .Page.Title }} {{ humanizeDate .Page.ParsedDate }}\n' > "$dl_site/layouts/_default/single.html"
+printf -- '---\ntitle: Post\ndate: 2024-03-05\n---\nPost\n' > "$dl_site/content/post.md"
+cat > "$dl_site/config.toml" <<'TOML'
+title = "Locales"
+language = "pl"
+defaultContentLanguage = "pl"
+[languages.ja]
+languageName = "日本語"
+[languages.pt-BR]
+languageName = "Português"
+[languages.fr]
+languageName = "Français"
+TOML
+(cd "$dl_site" && ./vango build >build.log 2>&1)
+if [ "$(grep -c 'No date locale for language \\"pl\\"' "$dl_site/build.log")" = 1 ] \
+    && grep -q 'No date locale for language \\"ja\\"' "$dl_site/build.log" \
+    && ! grep -q 'No date locale for language \\"pt-BR\\"\|No date locale for language \\"fr\\"' "$dl_site/build.log" \
+    && grep -q 'Post March 5, 2024' "$dl_site/public/post/index.html"; then
+    echo "   ✓ Configured languages without date names are warned about once each"
+else
+    echo "   ✗ Missing date locales not reported"
+    grep -i "locale\|error" "$dl_site/build.log"
+    cat "$dl_site/public/post/index.html"
+fi
+rm -rf "$dl_site"
+echo ""
+echo "61. Testing front matter dates against the site zone, machine zone and DST..."
+tz_site=$(mktemp -d)
+go build -o "$tz_site/vango" main.go
+mkdir -p "$tz_site/content" "$tz_site/layouts/_default" "$tz_site/static"
+printf 'title = "Zones"\ntimeZone = "America/New_York"\nreproducible = true\n' > "$tz_site/config.toml"
+printf '{{ .Page.ParsedDate.Format "2006-01-02T15:04:05-07:00" }}\n' > "$tz_site/layouts/_default/single.html"
+# US daylight saving time starts at 02:00 on 2024-03-10; the build runs at
+# 12:00 EDT that day (16:00 UTC), 21 hours ahead in Tokyo
+printf -- '---\ndate: "2024-03-09 23:30:00"\n---\n' > "$tz_site/content/before-dst.md"
+printf -- '---\ndate: "2024-03-10 03:30:00"\n---\n' > "$tz_site/content/after-dst.md"
+printf -- '---\ndate: "2024-03-10 11:59:00"\n---\n' > "$tz_site/content/just-past.md"
+printf -- '---\ndate: "2024-03-10 12:01:00"\n---\n' > "$tz_site/content/just-future.md"
+printf -- '---\ndate: "2024-03-11"\n---\n' > "$tz_site/content/tomorrow.md"
+printf -- '---\ndate: "2024-03-01"\npublish_date: "2024-03-10T11:30:00-05:00"\n---\n' > "$tz_site/content/offset.md"
+tz_results=""
+for zone in Asia/Tokyo UTC; do
+    rm -rf "$tz_site/public"
+    (cd "$tz_site" && TZ="$zone" SOURCE_DATE_EPOCH=1710086400 ./vango build >build.log 2>&1)
+    tz_result=""
+    for page in before-dst after-dst just-past just-future tomorrow offset; do
+        tz_result="$tz_result $page=$(cat "$tz_site/public/$page/index.html" 2>/dev/null || echo future)"
+    done
+    tz_results="$tz_results$zone:$tz_result
+"
+done
+tz_expected=" before-dst=2024-03-09T23:30:00-05:00 after-dst=2024-03-10T03:30:00-04:00 just-past=2024-03-10T11:59:00-04:00 just-future=future tomorrow=future offset=future"
+if [ "$tz_results" = "Asia/Tokyo:$tz_expected
+UTC:$tz_expected
+" ]; then
+    echo "   ✓ Dates are read in the site zone across DST, whatever the machine's TZ"
+else
+    echo "   ✗ Dates depend on the machine zone or DST:"
+    echo "$tz_results"
+    echo "   want:$tz_expected"
+fi
+rm -rf "$tz_site"
+echo ""
+echo "62. Testing nested params are the same from YAML, TOML and JSON front matter..."
+np_site=$(mktemp -d)
+go build -o "$np_site/vango" main.go
+mkdir -p "$np_site/content" "$np_site/layouts/_default" "$np_site/static"
+printf 'title = "Params"\n' > "$np_site/config.toml"
+printf '{{ .Page.Title }}\n' > "$np_site/layouts/_default/list.html"
+cat > "$np_site/layouts/_default/single.html" <<'TPL'
+{{ printf "%v" .Page.Params }}
+{{ printf "%T %T %T" .Page.Params.author .Page.Params.author.Social (index .Page.Params.links 0) }}
+{{ .Page.Params.author.Name }} {{ param .Page "author.social.github" }} {{ param .Page "MixedCase" }} {{ (index .Page.Params.links 0).URL }}
+TPL
+cat > "$np_site/content/toml.md" <<'MD'
++++
+title = "Same"
+MixedCase = "top"
+[params]
+Count = 3
+[params.Author]
+Name = "Ann"
+[params.Author.Social]
+GitHub = "ann"
+[[params.Links]]
+Name = "Home"
+URL = "/"
++++
+MD
+cat > "$np_site/content/yaml.md" <<'MD'
+---
+title: Same
+MixedCase: top
+params:
+  Count: 3
+  Author:
+    Name: Ann
+    Social:
+      GitHub: ann
+  Links:
+    - Name: Home
+      URL: /
+---
+MD
+cat > "$np_site/content/json.md" <<'MD'
+{
+  "title": "Same",
+  "MixedCase": "top",
+  "params": {
+    "Count": 3,
+    "Author": {"Name": "Ann", "Social": {"GitHub": "ann"}},
+    "Links": [{"Name": "Home", "URL": "/"}]
+  }
+}
+MD
+(cd "$np_site" && ./vango build >build.log 2>&1)
+np_toml=$(cat "$np_site/public/toml/index.html" 2>/dev/null)
+if [ -n "$np_toml" ] && [ "$np_toml" = "$(cat "$np_site/public/yaml/index.html")" ] \
+    && [ "$np_toml" = "$(cat "$np_site/public/json/index.html")" ] \
+    && echo "$np_toml" | grep -q 'map\[string\]interface {} map\[string\]interface {} map\[string\]interface {}' \
+    && echo "$np_toml" | grep -q 'author:map\[Name:Ann Social:map\[GitHub:ann\]\]' \
+    && echo "$np_toml" | grep -q 'mixedcase:top' \
+    && echo "$np_toml" | grep -q '^Ann ann top /$'; then
+    echo "   ✓ Nested maps, lists of maps and mixed-case keys match across formats"
+else
+    echo "   ✗ Params differ between front matter formats"
+    for format in toml yaml json; do
+        echo "   $format:"
+        cat "$np_site/public/$format/index.html" 2>/dev/null
+    done
+    tail -3 "$np_site/build.log"
+fi
+rm -rf "$np_site"
+echo ""
+echo "63. Testing Windows-style content paths are slash-normalized..."
+wp_site=$(mktemp -d)
+go build -o "$wp_site/vango" main.go
+mkdir -p "$wp_site/content/posts" "$wp_site/layouts/_default" "$wp_site/static"
+printf 'title = "Paths"\n' > "$wp_site/config.toml"
+printf '{{ .Page.URL }}|{{ .Page.Slug }}|{{ .Page.Section }}\n' > "$wp_site/layouts/_default/single.html"
+printf 'LIST {{ .Page.Title }} {{ .Page.Kind }}\n' > "$wp_site/layouts/_default/list.html"
+# Backslashes are ordinary file name characters here, so these names reach
+# the path helpers exactly as relative paths on Windows would
+printf -- '---\ntitle: C\n---\nC\n' > "$wp_site/content/a\\b\\c.md"
+printf -- '---\ntitle: Intro\n---\nIntro\n' > "$wp_site/content/posts/guide\\intro.md"
+printf -- '---\ntitle: Docs\n---\nDocs\n' > "$wp_site/content/docs\\_index.md"
+(cd "$wp_site" && ./vango build >build.log 2>&1)
+if grep -qx '/a/b/c/|a/b/c|a' "$wp_site/public/a/b/c/index.html" 2>/dev/null \
+    && grep -qx '/posts/guide/intro/|posts/guide/intro|posts' "$wp_site/public/posts/guide/intro/index.html" 2>/dev/null \
+    && grep -qx 'LIST Docs section' "$wp_site/public/docs/index.html" 2>/dev/null \
+    && ! find "$wp_site/public" -name '*\\*' | grep -q .; then
+    echo "   ✓ Backslash paths give slash URLs, slugs, sections, section pages and output directories"
+else
+    echo "   ✗ Backslash paths not normalized"
+    find "$wp_site/public" -name 'index.html' | sed "s|$wp_site/||"
+    tail -3 "$wp_site/build.log"
+fi
+rm -rf "$wp_site"
+echo ""
+echo "64. Testing a baseURL with a subpath, with and without a trailing slash..."
+bu_site=$(mktemp -d)
+go build -o "$bu_site/vango" main.go
+mkdir -p "$bu_site/content/posts" "$bu_site/layouts/_default" "$bu_site/static"
+printf '{{ .Page.Permalink }}|{{ .Page.RelPermalink }}|{{ absURL "/css/site.css" }}|{{ absURL "img/a.png" }}|{{ relURL "/posts/" }}\n' > "$bu_site/layouts/_default/single.html"
+printf '{{ .Page.Title }}\n' > "$bu_site/layouts/_default/list.html"
+printf -- '---\ntitle: Hello\n---\nHello\n' > "$bu_site/content/posts/hello.md"
+printf -- '---\ntitle: Home\n---\n' > "$bu_site/content/_index.md"
+bu_results=""
+for base in "https://example.com/sub" "https://example.com/sub/"; do
+    printf 'title = "Sub"\nbaseURL = "%s"\n' "$base" > "$bu_site/config.toml"
+    rm -rf "$bu_site/public"
+    (cd "$bu_site" && ./vango build >build.log 2>&1)
+    bu_results="$bu_results$base $(cat "$bu_site/public/posts/hello/index.html" 2>/dev/null) $(grep -o '<loc>[^<]*</loc>' "$bu_site/public/sitemap.xml" 2>/dev/null | sort | tr '\n' ' ')
+"
+done
+bu_expected='https://example.com/sub/posts/hello/|/sub/posts/hello/|https://example.com/sub/css/site.css|https://example.com/sub/img/a.png|/sub/posts/'
+if [ "$(echo "$bu_results" | grep -c "$bu_expected")" = 2 ] \
+    && [ "$(echo "$bu_results" | grep -c '<loc>https://example.com/sub/posts/hello/</loc>')" = 2 ] \
+    && [ "$(echo "$bu_results" | grep -c '<loc>https://example.com/sub/</loc>')" = 2 ] \
+    && ! echo "$bu_results" | grep -q 'sub//\|example.com/posts\|<loc>/'; then
+    echo "   ✓ Permalinks, absURL, relURL and the sitemap keep the subpath either way"
+else
+    echo "   ✗ Subpath baseURL handled inconsistently"
+    echo "$bu_results"
+    echo "   want: $bu_expected"
+fi
+rm -rf "$bu_site"
+echo ""
+echo "65. Testing text helpers with multibyte text, HTML and word boundaries..."
+tx_site=$(mktemp -d)
+go build -o "$tx_site/vango" main.go
+mkdir -p "$tx_site/content" "$tx_site/layouts" "$tx_site/static"
+printf 'title = "Text"\n' > "$tx_site/config.toml"
+tx_failed=0
+while IFS='|' read -r tx_expr tx_want; do
+    tx_got=$(cd "$tx_site" && ./vango tpl exec "$tx_expr" 2>&1 | tail -1)
+    if [ "$tx_got" != "$tx_want" ]; then
+        echo "   $tx_expr: want '$tx_want', got '$tx_got'"
+        tx_failed=$((tx_failed + 1))
+    fi
+done <<'CASES'
+{{ truncate "Grüße aus München und Köln" 16 }}|Grüße aus…
+{{ truncate "😀😀😀 😀😀😀" 4 }}|😀😀😀…
+{{ truncate "Supercalifragilistic" 5 }}|Super…
+{{ truncate "short" 10 }}|short
+{{ truncate "<p>Hello <b>wonderful</b> world</p>" 15 }}|Hello wonderful…
+{{ truncateHTML "<p><b>Hello wonderful</b> world</p>" 8 }}|<p><b>Hello…</b></p>
+{{ truncateHTML "<p>Fish &amp; chips are great</p>" 12 }}|<p>Fish &amp; chips…</p>
+{{ truncateHTML "<p>Zoë <script>var x = 1;</script>and Chloë went</p>" 12 }}|<p>Zoë <script>var x = 1;</script>and…</p>
+{{ truncateWords "one two  three four" 2 }}|one two…
+{{ truncateWords "日本語のテキスト" 3 }}|日本語…
+{{ excerpt "<p>Héllo   <em>wörld</em></p><p>again</p>" 2 }}|Héllo wörld…
+{{ wordCount "<p>one</p><p>two</p> three&nbsp;four" }}|4
+{{ readingTime "<p>word</p>" }}|1
+CASES
+if [ "$tx_failed" = 0 ]; then
+    echo "   ✓ Truncation keeps runes, entities and tags whole and cuts at word ends"
+else
+    echo "   ✗ $tx_failed text helper cases failed"
+fi
+rm -rf "$tx_site"
+echo ""
+echo "66. Testing Vercel output reports the 404 and 410 rules it can't express..."
+vr_site=$(mktemp -d)
+go build -o "$vr_site/vango" main.go
+mkdir -p "$vr_site/content" "$vr_site/layouts/_default" "$vr_site/static"
+printf '{{ .Page.Title }}\n' > "$vr_site/layouts/_default/single.html"
+printf -- '---\ntitle: New\n---\nNew\n' > "$vr_site/content/new.md"
+cat > "$vr_site/config.toml" <<'TOML'
+title = "Redirects"
+[redirectOptions]
+targets = ["vercel", "netlify"]
+[[redirects]]
+from = "/old"
+to = "/new/"
+status = 301
+[[redirects]]
+from = "/gone"
+to = "/404.html"
+status = 410
+[[redirects]]
+from = "/missing/*"
+to = "/404.html"
+status = 404
+[[redirects]]
+from = "/app/*"
+to = "/index.html"
+status = 200
+TOML
+(cd "$vr_site" && ./vango build >build.log 2>&1)
+if grep -q "can't express the 410 rule /gone  /404.html" "$vr_site/build.log" \
+    && grep -q "can't express the 404 rule /missing/\* *  /404.html" "$vr_site/build.log" \
+    && [ "$(grep -c "vercel.json can't express" "$vr_site/build.log")" = 2 ] \
+    && grep -q '"source": "/old"' "$vr_site/public/vercel.json" \
+    && grep -q '"source": "/app/:splat\*"' "$vr_site/public/vercel.json" \
+    && ! grep -q '/gone\|/missing' "$vr_site/public/vercel.json" \
+    && grep -q '^/gone  /404.html  410$' "$vr_site/public/_redirects"; then
+    echo "   ✓ Dropped Vercel rules are named in build warnings; _redirects keeps them"
+else
+    echo "   ✗ Vercel rules dropped silently"
+    grep -i "vercel\|error" "$vr_site/build.log"
+    cat "$vr_site/public/vercel.json"
+fi
+rm -rf "$vr_site"
+echo ""
+echo ""
+echo "67. Testing favicons from stroked and gradient SVGs..."
+svgi_site=$(mktemp -d)
+go build -o "$svgi_site/vango" main.go
+mkdir -p "$svgi_site/content" "$svgi_site/layouts" "$svgi_site/assets"
+printf 'title = "Icons"\nbaseURL = "https://example.com/"\ntheme = "t"\n[favicon]\nsource = "assets/logo.svg"\n' > "$svgi_site/config.toml"
+(cd "$svgi_site" && ./vango theme create t -t basic -q >/dev/null 2>&1)
+printf -- '---\ntitle: A\n---\nA\n' > "$svgi_site/content/a.md"
+# Colors of a few pixels of a 192x192 icon, as x,y=rrggbbaa
+svgi_pixels() {
+    python3 - "$1" <<'PY'
+import struct, sys, zlib
+data = open(sys.argv[1], "rb").read()
+pos, idat = 8, b""
+while pos < len(data):
+    n, kind = struct.unpack(">I4s", data[pos:pos + 8])
+    body = data[pos + 8:pos + 8 + n]
+    if kind == b"IHDR":
+        w, h, depth, ctype = struct.unpack(">IIBB", body[:10])
+    elif kind == b"IDAT":
+        idat += body
+    pos += n + 12
+bpp = {2: 3, 6: 4}[ctype]
+raw, stride, rows, prev = zlib.decompress(idat), w * bpp, [], bytearray(w * bpp)
+for y in range(h):
+    f, line = raw[y * (stride + 1)], bytearray(raw[y * (stride + 1) + 1:(y + 1) * (stride + 1)])
+    for i in range(stride):
+        a = line[i - bpp] if i >= bpp else 0
+        b, c = prev[i], prev[i - bpp] if i >= bpp else 0
+        p = a + b - c
+        pred = [0, a, b, (a + b) // 2, a if abs(p - a) <= min(abs(p - b), abs(p - c)) else b if abs(p - b) <= abs(p - c) else c][f]
+        line[i] = (line[i] + pred) & 255
+    rows.append(line)
+    prev = line
+for x, y in [(96, 96), (96, 8), (20, 96), (172, 96)]:
+    px = rows[y][x * bpp:(x + 1) * bpp] + (b"\xff" if bpp == 3 else b"")
+    print("%d,%d=%s" % (x, y, px.hex()))
+PY
+}
+# A Feather-style icon: a ring stroked in red, with nothing filled
+printf '<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24" fill="none" stroke="#ff0000" stroke-width="2"><circle cx="12" cy="12" r="11"/></svg>\n' > "$svgi_site/assets/logo.svg"
+svgi_stroke_out=$(cd "$svgi_site" && ./vango build 2>&1)
+svgi_stroke=$(svgi_pixels "$svgi_site/public/android-chrome-192x192.png" | tr '\n' ' ')
+# A square filled with a left-to-right gradient from green to blue, and a caption
+printf '<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24"><defs><linearGradient id="g" x1="0" y1="0" x2="1" y2="0"><stop offset="0" stop-color="#00ff00"/><stop offset="1" stop-color="#0000ff"/></linearGradient></defs><rect width="24" height="24" fill="url(#g)"/><text x="2" y="20">V</text></svg>\n' > "$svgi_site/assets/logo.svg"
+svgi_grad_out=$(cd "$svgi_site" && ./vango build 2>&1)
+svgi_grad=$(svgi_pixels "$svgi_site/public/android-chrome-192x192.png" | tr '\n' ' ')
+svgi_ok=true
+# The ring is red at its edge and empty in the middle
+echo "$svgi_stroke" | grep -q '96,8=ff0000' || svgi_ok=false
+echo "$svgi_stroke" | grep -q '96,96=00000000' || svgi_ok=false
+# The gradient runs from mostly green on the left to mostly blue on the right
+svgi_left=$(echo "$svgi_grad" | grep -o '20,96=[0-9a-f]*' | cut -d= -f2)
+svgi_right=$(echo "$svgi_grad" | grep -o '172,96=[0-9a-f]*' | cut -d= -f2)
+[ $((16#${svgi_left:2:2})) -gt 192 ] && [ $((16#${svgi_left:4:2})) -lt 64 ] || svgi_ok=false
+[ $((16#${svgi_right:2:2})) -lt 64 ] && [ $((16#${svgi_right:4:2})) -gt 192 ] || svgi_ok=false
+if $svgi_ok && echo "$svgi_grad_out" | grep -q "source uses <text>, which can't be rasterized" \
+    && ! echo "$svgi_stroke_out" | grep -q "can't be rasterized"; then
+    echo "   ✓ Strokes and gradients drawn, and text reported as left out"
+else
+    echo "   ✗ Stroked or gradient SVG favicons drawn wrong"
+    echo "   stroke: $svgi_stroke"
+    echo "   gradient: $svgi_grad"
+    echo "$svgi_grad_out" | grep -i 'warn' | head -3
+fi
+rm -rf "$svgi_site"
+echo ""
+echo ""
+echo "68. Testing a lazy site warms after a failed background build is fixed..."
+lw_site=$(mktemp -d)
+go build -o "$lw_site/vango" main.go
+mkdir -p "$lw_site/content" "$lw_site/layouts/_default" "$lw_site/static"
+printf 'title = "Warm"\n' > "$lw_site/config.toml"
+# Pages titled B fail to render, which fails the whole build
+printf '{{ .Page.Title }}{{ if eq .Page.Title "B" }}{{ index .Page.Params.nope 3 }}{{ end }}\n' > "$lw_site/layouts/_default/single.html"
+printf '{{ .Page.Title }}\n' > "$lw_site/layouts/_default/list.html"
+printf -- '---\ntitle: A\n---\nA\n' > "$lw_site/content/a.md"
+printf -- '---\ntitle: B\n---\nB\n' > "$lw_site/content/b.md"
+lw_port=$((20000 + RANDOM % 10000))
+(cd "$lw_site" && exec ./vango serve --lazy -p "$lw_port" >serve.log 2>&1) &
+lw_pid=$!
+for i in $(seq 1 50); do
+    grep -q "Background build failed" "$lw_site/serve.log" && break
+    sleep 0.2
+done
+lw_cold=$(curl -s "http://localhost:$lw_port/a/")
+printf -- '---\ntitle: C\n---\nC\n' > "$lw_site/content/b.md"
+for i in $(seq 1 50); do
+    grep -q "all pages warm" "$lw_site/serve.log" && break
+    sleep 0.2
+done
+lw_fixed=$(curl -s "http://localhost:$lw_port/b/")
+kill "$lw_pid" 2>/dev/null
+wait "$lw_pid" 2>/dev/null
+if echo "$lw_cold" | grep -q '^A' && echo "$lw_fixed" | grep -q '^C' \
+    && grep -q "all pages warm" "$lw_site/serve.log" && [ -f "$lw_site/public/b/index.html" ]; then
+    echo "   ✓ Site marked warm by the first successful rebuild"
+else
+    echo "   ✗ Site stayed cold after the failing page was fixed"
+    echo "   a: $lw_cold"
+    echo "   b: $lw_fixed"
+    tail -5 "$lw_site/serve.log"
+fi
+rm -rf "$lw_site"
+echo ""
+echo ""
+echo "69. Testing trailing slash, letter case and near-miss URLs in the dev server..."
+rt_site=$(mktemp -d)
+go build -o "$rt_site/vango" main.go
+mkdir -p "$rt_site/content/posts" "$rt_site/layouts/_default" "$rt_site/static"
+printf '{{ .Page.Title }}\n' > "$rt_site/layouts/_default/single.html"
+printf '{{ .Page.Title }}\n' > "$rt_site/layouts/_default/list.html"
+printf -- '---\ntitle: Posts\n---\n' > "$rt_site/content/posts/_index.md"
+printf -- '---\ntitle: Hello\n---\nHello\n' > "$rt_site/content/posts/hello.md"
+# Prints the status code and redirect target of each URL path in turn
+rt_serve() {
+    rt_port=$((20000 + RANDOM % 10000))
+    (cd "$rt_site" && exec ./vango serve -p "$rt_port" >serve.log 2>&1) &
+    rt_pid=$!
+    for i in $(seq 1 50); do
+        curl -s -o /dev/null "http://localhost:$rt_port/posts/" && break
+        sleep 0.2
+    done
+    for p in "$@"; do
+        curl -s -o /dev/null -w '%{http_code} %{redirect_url}\n' "http://localhost:$rt_port$p"
+    done
+    curl -s "http://localhost:$rt_port/posts/helo/" > "$rt_site/missing.html"
+    kill "$rt_pid" 2>/dev/null
+    wait "$rt_pid" 2>/dev/null
+}
+printf 'title = "Routes"\nbaseURL = "http://localhost/"\n[routing]\ncaseInsensitive = true\n' > "$rt_site/config.toml"
+rt_redirect=$(rt_serve /posts /posts/ /Posts/HELLO/ /posts/helo/)
+rt_redirect_log=$(cat "$rt_site/serve.log")
+printf 'title = "Routes"\nbaseURL = "http://localhost/"\n[routing]\ntrailingSlash = "strict"\n' > "$rt_site/config.toml"
+rt_strict=$(rt_serve /posts /posts/ /Posts/HELLO/)
+rt_ok=true
+[ "$(echo "$rt_redirect" | sed -n 1p | cut -d' ' -f1)" = "301" ] && echo "$rt_redirect" | sed -n 1p | grep -q '/posts/$' || rt_ok=false
+[ "$(echo "$rt_redirect" | sed -n 2p)" = "200 " ] || rt_ok=false
+[ "$(echo "$rt_redirect" | sed -n 3p | cut -d' ' -f1)" = "301" ] && echo "$rt_redirect" | sed -n 3p | grep -q '/posts/hello/$' || rt_ok=false
+[ "$(echo "$rt_redirect" | sed -n 4p)" = "404 " ] || rt_ok=false
+echo "$rt_redirect_log" | grep -q '/Posts/HELLO/ only matches /posts/hello/ in a different case' || rt_ok=false
+[ "$(echo "$rt_strict" | tr '\n' '|')" = "404 |200 |404 |" ] || rt_ok=false
+if $rt_ok && grep -q 'Did you mean' "$rt_site/missing.html" && grep -q 'href="/posts/hello/"' "$rt_site/missing.html"; then
+    echo "   ✓ Slashless URLs redirected or refused, other-case URLs redirected, and near misses suggested"
+else
+    echo "   ✗ Dev server routing wrong"
+    echo "   redirect mode: $(echo "$rt_redirect" | tr '\n' '|')"
+    echo "   strict mode: $(echo "$rt_strict" | tr '\n' '|')"
+    grep -o 'Did you mean.*' "$rt_site/missing.html" | head -3
+fi
+rm -rf "$rt_site"
 echo ""
 echo "=== Test Summary ==="
 echo "VanGo static site generator setup complete!"